@@ -0,0 +1,489 @@
+package geojson
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+)
+
+// This file simplifies the features of a collection together. Their lines
+// and rings are cut into arcs wherever they meet, so that a border shared by
+// two polygons becomes a single arc that is simplified once. Arcs that would
+// make the result invalid are simplified again with a smaller tolerance.
+
+// SimplifyTopology returns a copy of fc with the lines and polygon rings of
+// its features simplified, like Simplify, but without changing how they
+// meet. Where features share a border, the border is simplified once, so
+// neighbours still meet exactly, without slivers or gaps between them.
+//
+// Paths are cut into arcs at the positions where they meet or branch and at
+// the ends of lines, and those positions are kept. The remaining vertices of
+// each arc are simplified with the given tolerance, which is reduced for any
+// arc that would otherwise cross or touch another arc or itself, pass over
+// a vertex of another arc, or leave a ring with fewer than 4 positions. The
+// input must be valid in the same sense: paths may only meet at shared
+// vertices, and arcs where they do not are not simplified. Rings may start
+// at a different position in the result. Places are simplified
+// independently, as they may use another reference system.
+func SimplifyTopology(fc *FeatureCollection, tolerance float64, opts *SimplifyOptions) *FeatureCollection {
+	if opts == nil {
+		opts = &SimplifyOptions{}
+	}
+	s := &simplifier{tolerance: tolerance, method: opts.Method}
+	var paths []*path
+	collect := func(ring bool) func(ps []Point) []Point {
+		return func(ps []Point) []Point {
+			paths = append(paths, newPath(ps, ring))
+			return ps
+		}
+	}
+	for _, f := range fc.Features {
+		if f.Geometry != nil {
+			mapLines(f.Geometry, collect(false), collect(true))
+		}
+	}
+	as := newArcSet(paths)
+	as.simplify(s)
+
+	// Visit the paths in the same order to replace them.
+	next := 0
+	rebuild := func(ps []Point) []Point {
+		p := paths[next]
+		next++
+		return as.rebuild(p)
+	}
+	r := *fc
+	r.Features = make([]Feature, len(fc.Features))
+	for i, f := range fc.Features {
+		if f.Geometry != nil {
+			f.Geometry = mapLines(f.Geometry, rebuild, rebuild)
+		}
+		if f.Place != nil {
+			f.Place = s.geometry(f.Place)
+		}
+		r.Features[i] = f
+	}
+	return &r
+}
+
+// pos returns the planar position of p.
+func pos(p Point) xy {
+	return xy{p.X, p.Y}
+}
+
+// A path is a line or polygon ring that is cut into arcs.
+type path struct {
+	original []Point
+	// Positions of the path without consecutive repeats or, for rings, the
+	// closing position. Nil if the path cannot be simplified.
+	points []Point
+	ring   bool
+	arcs   []arcRef
+}
+
+func newPath(ps []Point, ring bool) *path {
+	p := &path{original: ps, ring: ring}
+	for _, q := range ps {
+		if len(p.points) == 0 || pos(q) != pos(p.points[len(p.points)-1]) {
+			p.points = append(p.points, q)
+		}
+	}
+	if !ring {
+		if len(p.points) < 2 {
+			p.points = nil
+		}
+		return p
+	}
+	if len(ps) == 0 || ps[0] != ps[len(ps)-1] {
+		p.points = nil
+		return p
+	}
+	if n := len(p.points); n > 1 && pos(p.points[0]) == pos(p.points[n-1]) {
+		p.points = p.points[:n-1]
+	}
+	if len(p.points) < 3 {
+		p.points = nil
+	}
+	return p
+}
+
+// An arc is a part of one or more paths between two junctions, or a whole
+// ring without junctions.
+type arc struct {
+	// Positions of the arc. The last position of a closed arc repeats the
+	// first.
+	points []Point
+	closed bool
+	// Tolerance for the arc, and whether it is kept at full resolution
+	// because no tolerance is small enough.
+	tolerance float64
+	full      bool
+	// Indices of the positions kept by simplification. For closed arcs, the
+	// last index is the first repeated, so it is smaller than the one
+	// before it.
+	kept []int
+}
+
+// An arcRef refers to an arc of an arcSet, which a path follows forwards
+// or backwards.
+type arcRef struct {
+	arc      int
+	reversed bool
+}
+
+// An arcSet is the set of arcs that make up some paths.
+type arcSet struct {
+	arcs  []*arc
+	paths []*path
+	// Arcs by the key of their positions.
+	index map[string]int
+}
+
+func newArcSet(paths []*path) *arcSet {
+	as := &arcSet{paths: paths, index: map[string]int{}}
+	junctions := findJunctions(paths)
+	for _, p := range paths {
+		if p.points != nil {
+			as.cut(p, junctions)
+		}
+	}
+	return as
+}
+
+// findJunctions returns the positions where paths end, meet, or branch:
+// those that are not always between the same two neighbours.
+func findJunctions(paths []*path) map[xy]bool {
+	type neighbours struct{ a, b xy }
+	seen := map[xy]neighbours{}
+	junctions := map[xy]bool{}
+	visit := func(p, a, b xy) {
+		if b.less(a) {
+			a, b = b, a
+		}
+		if n, ok := seen[p]; !ok {
+			seen[p] = neighbours{a, b}
+		} else if n != (neighbours{a, b}) {
+			junctions[p] = true
+		}
+	}
+	for _, p := range paths {
+		ps := p.points
+		n := len(ps)
+		switch {
+		case ps == nil:
+		case p.ring:
+			for i, q := range ps {
+				visit(pos(q), pos(ps[(i+n-1)%n]), pos(ps[(i+1)%n]))
+			}
+		default:
+			junctions[pos(ps[0])] = true
+			junctions[pos(ps[n-1])] = true
+			for i := 1; i < n-1; i++ {
+				visit(pos(ps[i]), pos(ps[i-1]), pos(ps[i+1]))
+			}
+		}
+	}
+	return junctions
+}
+
+// cut divides p into arcs at junctions.
+func (as *arcSet) cut(p *path, junctions map[xy]bool) {
+	ps := p.points
+	if p.ring {
+		start := -1
+		for i, q := range ps {
+			if junctions[pos(q)] {
+				start = i
+				break
+			}
+		}
+		closed := start < 0
+		if closed {
+			// Start at the least position, so that rings shared by several
+			// polygons all start at the same place.
+			start = 0
+			for i, q := range ps {
+				if pos(q).less(pos(ps[start])) {
+					start = i
+				}
+			}
+		}
+		rotated := make([]Point, 0, len(ps)+1)
+		rotated = append(append(rotated, ps[start:]...), ps[:start]...)
+		ps = append(rotated, rotated[0])
+		if closed {
+			p.arcs = []arcRef{as.add(ps, true)}
+			return
+		}
+	}
+	begin := 0
+	for i := 1; i < len(ps); i++ {
+		if i == len(ps)-1 || junctions[pos(ps[i])] {
+			p.arcs = append(p.arcs, as.add(ps[begin:i+1], false))
+			begin = i
+		}
+	}
+}
+
+// add returns a reference to the arc with the positions ps, in either
+// direction, adding it if it is new.
+func (as *arcSet) add(ps []Point, closed bool) arcRef {
+	rev := reversedPoints(ps)
+	reversed := lessPoints(rev, ps)
+	if reversed {
+		ps = rev
+	}
+	key := pointsKey(ps)
+	if i, ok := as.index[key]; ok {
+		return arcRef{i, reversed}
+	}
+	as.index[key] = len(as.arcs)
+	as.arcs = append(as.arcs, &arc{points: ps, closed: closed})
+	return arcRef{len(as.arcs) - 1, reversed}
+}
+
+func reversedPoints(ps []Point) []Point {
+	r := make([]Point, len(ps))
+	for i, p := range ps {
+		r[len(ps)-1-i] = p
+	}
+	return r
+}
+
+// lessPoints reports whether the positions of a sort before those of b.
+func lessPoints(a, b []Point) bool {
+	for i := range a {
+		if p, q := pos(a[i]), pos(b[i]); p != q {
+			return p.less(q)
+		}
+	}
+	return false
+}
+
+// pointsKey returns a map key for the positions of ps.
+func pointsKey(ps []Point) string {
+	b := make([]byte, 16*len(ps))
+	for i, p := range ps {
+		// Adding 0 turns -0 into 0, which compares equal.
+		binary.LittleEndian.PutUint64(b[16*i:], math.Float64bits(p.X+0))
+		binary.LittleEndian.PutUint64(b[16*i+8:], math.Float64bits(p.Y+0))
+	}
+	return string(b)
+}
+
+// simplify simplifies the arcs, reducing the tolerance of those that make
+// the paths invalid until none do.
+func (as *arcSet) simplify(s *simplifier) {
+	for _, a := range as.arcs {
+		a.tolerance = s.tolerance
+		a.simplify(s.method)
+	}
+	for {
+		refined := false
+		for i := range as.violations() {
+			a := as.arcs[i]
+			if a.full {
+				continue
+			}
+			a.tolerance /= 2
+			a.full = a.tolerance <= s.tolerance*1e-6
+			a.simplify(s.method)
+			refined = true
+		}
+		if !refined {
+			return
+		}
+	}
+}
+
+// simplify sets the positions kept by a.
+func (a *arc) simplify(method SimplifyMethod) {
+	n := len(a.points)
+	var keep []bool
+	s := &simplifier{tolerance: a.tolerance, method: method}
+	switch {
+	case a.full || a.closed && n <= 4 || n <= 2:
+		keep = make([]bool, n)
+		for i := range keep {
+			keep[i] = true
+		}
+	case a.closed:
+		keep = s.keepRing(a.points)
+	default:
+		keep = s.keepLine(a.points)
+	}
+	a.kept = a.kept[:0]
+	for i, k := range keep {
+		if k && !(a.closed && i == n-1) {
+			a.kept = append(a.kept, i)
+		}
+	}
+	if a.closed {
+		a.kept = append(a.kept, a.kept[0])
+	}
+}
+
+// simple returns the kept positions of a.
+func (a *arc) simple() []Point {
+	r := make([]Point, len(a.kept))
+	for i, k := range a.kept {
+		r[i] = a.points[k]
+	}
+	return r
+}
+
+// span returns the positions of a from the kth kept position to the next.
+func (a *arc) span(k int) []Point {
+	i, j := a.kept[k], a.kept[k+1]
+	if j > i {
+		return a.points[i : j+1]
+	}
+	// The span wraps around a closed arc.
+	r := append([]Point(nil), a.points[i:]...)
+	return append(r, a.points[1:j+1]...)
+}
+
+// violations returns the arcs that make the paths invalid.
+func (as *arcSet) violations() map[int]bool {
+	bad := map[int]bool{}
+	// Arcs that start and end at the same junction must not collapse.
+	for i, a := range as.arcs {
+		if !a.closed && pos(a.points[0]) == pos(a.points[len(a.points)-1]) && len(a.kept) < 4 {
+			bad[i] = true
+		}
+	}
+	// Rings need at least 3 distinct vertices.
+	for _, p := range as.paths {
+		if !p.ring || p.arcs == nil {
+			continue
+		}
+		n := 0
+		for _, ref := range p.arcs {
+			n += len(as.arcs[ref.arc].kept) - 1
+		}
+		if n < 3 {
+			for _, ref := range p.arcs {
+				bad[ref.arc] = true
+			}
+		}
+	}
+	as.crossings(bad)
+	as.jumps(bad)
+	return bad
+}
+
+// An arcSegment is a segment of a simplified arc.
+type arcSegment struct {
+	segment
+	arc int
+}
+
+// crossings marks the arcs with segments that meet other than at shared
+// ends, sweeping from left to right like arrangement.node.
+func (as *arcSet) crossings(bad map[int]bool) {
+	var segments []arcSegment
+	for i, a := range as.arcs {
+		ps := a.simple()
+		for k := 1; k < len(ps); k++ {
+			segments = append(segments, arcSegment{segment{a: pos(ps[k-1]), b: pos(ps[k])}, i})
+		}
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].minX() < segments[j].minX()
+	})
+	for i := range segments {
+		s := &segments[i]
+		for j := i + 1; j < len(segments); j++ {
+			t := &segments[j]
+			if t.minX() > s.maxX() {
+				break
+			}
+			if t.minY() > s.maxY() || t.maxY() < s.minY() {
+				continue
+			}
+			if improperlyMeet(&s.segment, &t.segment) {
+				bad[s.arc], bad[t.arc] = true, true
+			}
+		}
+	}
+}
+
+// improperlyMeet reports whether s and t coincide or meet other than at an
+// end of both.
+func improperlyMeet(s, t *segment) bool {
+	if s.a == t.a && s.b == t.b || s.a == t.b && s.b == t.a {
+		return true
+	}
+	for _, p := range intersect(s, t) {
+		if p != s.a && p != s.b || p != t.a && p != t.b {
+			return true
+		}
+	}
+	return false
+}
+
+// jumps marks the arcs with segments that pass over kept vertices: those
+// that lie inside the area between the segment and the part of the arc it
+// replaces. Such vertices would move to the other side of the arc, taking
+// a hole out of its polygon, for example, without any segments crossing.
+func (as *arcSet) jumps(bad map[int]bool) {
+	var vertices []xy
+	for _, a := range as.arcs {
+		for _, k := range a.kept {
+			vertices = append(vertices, pos(a.points[k]))
+		}
+	}
+	sort.Slice(vertices, func(i, j int) bool {
+		return vertices[i].x < vertices[j].x
+	})
+	for i, a := range as.arcs {
+	spans:
+		for k := 0; k+1 < len(a.kept); k++ {
+			span := a.span(k)
+			if len(span) <= 2 {
+				continue
+			}
+			minX, minY := math.Inf(1), math.Inf(1)
+			maxX, maxY := math.Inf(-1), math.Inf(-1)
+			for _, p := range span {
+				minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+				minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+			}
+			lo := sort.Search(len(vertices), func(i int) bool {
+				return vertices[i].x >= minX
+			})
+			for _, v := range vertices[lo:] {
+				if v.x > maxX {
+					break
+				}
+				if v.y < minY || v.y > maxY {
+					continue
+				}
+				var l locator
+				if !l.addRing(Point{X: v.x, Y: v.y}, span) && l.location() == Interior {
+					bad[i] = true
+					break spans
+				}
+			}
+		}
+	}
+}
+
+// rebuild returns the simplified positions of p.
+func (as *arcSet) rebuild(p *path) []Point {
+	if p.arcs == nil {
+		return append([]Point(nil), p.original...)
+	}
+	var r []Point
+	for _, ref := range p.arcs {
+		ps := as.arcs[ref.arc].simple()
+		if ref.reversed {
+			ps = reversedPoints(ps)
+		}
+		if len(r) > 0 {
+			ps = ps[1:]
+		}
+		r = append(r, ps...)
+	}
+	return r
+}
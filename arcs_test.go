@@ -0,0 +1,159 @@
+package geojson
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestSimplifyTopology_Grid(t *testing.T) {
+	// A 4 by 4 grid of counties with noisy borders. Each border is shared by
+	// the counties on either side of it.
+	r := rand.New(rand.NewSource(1))
+	const n, steps = 4, 10
+	noise := func() [][]float64 {
+		rows := make([][]float64, n+1)
+		for i := range rows {
+			rows[i] = make([]float64, n*steps+1)
+			for m := range rows[i] {
+				if m%steps != 0 {
+					rows[i][m] = (r.Float64() - 0.5) / 10
+				}
+			}
+		}
+		return rows
+	}
+	hn, vn := noise(), noise()
+	h := func(j, m int) Point { return Point{X: float64(m) / steps, Y: float64(j) + hn[j][m]} }
+	v := func(i, m int) Point { return Point{X: float64(i) + vn[i][m], Y: float64(m) / steps} }
+	fc := &FeatureCollection{}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			var ring []Point
+			for m := steps * i; m <= steps*i+steps; m++ {
+				ring = append(ring, h(j, m))
+			}
+			for m := steps*j + 1; m <= steps*j+steps; m++ {
+				ring = append(ring, v(i+1, m))
+			}
+			for m := steps*i + steps - 1; m >= steps*i; m-- {
+				ring = append(ring, h(j+1, m))
+			}
+			for m := steps*j + steps - 1; m >= steps*j; m-- {
+				ring = append(ring, v(i, m))
+			}
+			fc.Features = append(fc.Features, Feature{Geometry: &Polygon{Rings: []LineString{{Points: ring}}}})
+		}
+	}
+
+	for _, method := range []SimplifyMethod{DouglasPeucker, VisvalingamWhyatt} {
+		for _, tolerance := range []float64{0.01, 0.05, 0.2, 1, 100} {
+			result := SimplifyTopology(fc, tolerance, &SimplifyOptions{Method: method})
+			var sum, vertices float64
+			var pieces []Geometry
+			for _, f := range result.Features {
+				p := f.Geometry.(*Polygon)
+				points := p.Rings[0].Points
+				if len(points) < 4 || points[0] != points[len(points)-1] {
+					t.Fatalf("method %d, tolerance %v: invalid ring %v", method, tolerance, points)
+				}
+				vertices += float64(len(points))
+				sum += planarArea(&MultiPolygon{Polygons: []Polygon{*p}})
+				pieces = append(pieces, p)
+			}
+			// The counties neither overlap nor leave gaps.
+			union := unionAll(pieces)
+			if area := planarArea(union); math.Abs(area-sum) > 1e-9 {
+				t.Errorf("method %d, tolerance %v: expected union area %v, got %v", method, tolerance, sum, area)
+			}
+			if len(union.Polygons) != 1 || len(union.Polygons[0].Rings) != 1 {
+				t.Errorf("method %d, tolerance %v: expected a union without holes, got %v", method, tolerance, union)
+			}
+			if tolerance >= 0.2 && vertices > float64(n*n*4*steps)/2 {
+				t.Errorf("method %d, tolerance %v: expected fewer vertices, got %v", method, tolerance, vertices)
+			}
+		}
+	}
+}
+
+func TestSimplifyTopology_Holes(t *testing.T) {
+	// Removing the bump would leave the hole outside the polygon.
+	shell := []Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 6, Y: 10}, {X: 5, Y: 12}, {X: 4, Y: 10}, {X: 0, Y: 10}, {X: 0, Y: 0}}
+	hole := []Point{{X: 4.9, Y: 10.5}, {X: 4.9, Y: 10.7}, {X: 5.1, Y: 10.7}, {X: 5.1, Y: 10.5}, {X: 4.9, Y: 10.5}}
+	fc := &FeatureCollection{Features: []Feature{
+		{Geometry: &Polygon{Rings: []LineString{{Points: shell}, {Points: hole}}}, Properties: map[string]interface{}{"name": "a"}},
+	}}
+	inside := func(p *Polygon) bool {
+		outer := &Polygon{Rings: p.Rings[:1]}
+		for _, q := range p.Rings[1].Points {
+			if !outer.Contains(q) {
+				return false
+			}
+		}
+		return true
+	}
+	if inside(Simplify(fc.Features[0].Geometry, 3, nil).(*Polygon)) {
+		t.Fatal("expected Simplify to move the hole out of the polygon")
+	}
+	result := SimplifyTopology(fc, 3, nil)
+	p := result.Features[0].Geometry.(*Polygon)
+	if !inside(p) {
+		t.Errorf("expected the hole to stay inside the polygon, got %v", p)
+	}
+	if len(p.Rings[0].Points) >= len(shell) {
+		t.Errorf("expected the shell to be simplified, got %v", p.Rings[0].Points)
+	}
+	if !reflect.DeepEqual(result.Features[0].Properties, fc.Features[0].Properties) {
+		t.Errorf("expected properties %v, got %v", fc.Features[0].Properties, result.Features[0].Properties)
+	}
+
+	// An island that fills a hole is simplified in the same way as the hole.
+	var ring []Point
+	for i := 0; i < 32; i++ {
+		a := 2 * math.Pi * float64(i) / 32
+		ring = append(ring, Point{X: 5 + 2*math.Cos(a) + 0.1*math.Cos(7*a), Y: 5 + 2*math.Sin(a)})
+	}
+	ring = append(ring, ring[0])
+	square := []Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}, {X: 0, Y: 0}}
+	fc = &FeatureCollection{Features: []Feature{
+		{Geometry: &Polygon{Rings: []LineString{{Points: square}, {Points: reversedPoints(ring)}}}},
+		{Geometry: &MultiPolygon{Polygons: []Polygon{{Rings: []LineString{{Points: ring}}}}}},
+	}}
+	for _, tolerance := range []float64{0.1, 0.5, 10} {
+		result = SimplifyTopology(fc, tolerance, nil)
+		h := result.Features[0].Geometry.(*Polygon).Rings[1].Points
+		island := result.Features[1].Geometry.(*MultiPolygon).Polygons[0].Rings[0].Points
+		if !reflect.DeepEqual(reversedPoints(h), island) {
+			t.Errorf("tolerance %v: expected the hole %v to match the island %v", tolerance, h, island)
+		}
+		if len(island) < 4 || len(island) >= len(ring) {
+			t.Errorf("tolerance %v: expected a simplified island, got %v", tolerance, island)
+		}
+	}
+}
+
+func TestSimplifyTopology_Lines(t *testing.T) {
+	// A road along the border of two parcels, and one that leaves it.
+	border := []Point{{X: 0, Y: 0}, {X: 1, Y: 0.01}, {X: 2, Y: -0.01}, {X: 3, Y: 0}}
+	fc := &FeatureCollection{Features: []Feature{
+		{Geometry: &Polygon{Rings: []LineString{{Points: append(append([]Point(nil), border...), Point{X: 3, Y: 1}, Point{X: 0, Y: 1}, Point{X: 0, Y: 0})}}}},
+		{Geometry: &Polygon{Rings: []LineString{{Points: append(reversedPoints(border), Point{X: 0, Y: -1}, Point{X: 3, Y: -1}, Point{X: 3, Y: 0})}}}},
+		{Geometry: &LineString{Points: border}},
+		{Geometry: &LineString{Points: []Point{{X: 1, Y: 0.01}, {X: 1.35, Y: 0.5}, {X: 1.6, Y: 0.9}}}},
+		{Geometry: &Point{X: 7, Y: 7}},
+	}}
+	result := SimplifyTopology(fc, 0.1, nil)
+	expected := []Geometry{
+		&Polygon{Rings: []LineString{{Points: []Point{border[0], border[1], border[3], {X: 3, Y: 1}, {X: 0, Y: 1}, border[0]}}}},
+		&Polygon{Rings: []LineString{{Points: []Point{border[3], border[1], border[0], {X: 0, Y: -1}, {X: 3, Y: -1}, border[3]}}}},
+		&LineString{Points: []Point{border[0], border[1], border[3]}},
+		&LineString{Points: []Point{{X: 1, Y: 0.01}, {X: 1.6, Y: 0.9}}},
+		&Point{X: 7, Y: 7},
+	}
+	for i, f := range result.Features {
+		if !reflect.DeepEqual(f.Geometry, expected[i]) {
+			t.Errorf("feature %d: expected %#v, got %#v", i, expected[i], f.Geometry)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package geojson
+
+import (
+	"math"
+
+	"github.com/bsidhom/geojson/internal/geodesic"
+)
+
+// Styles for the ends of buffered lines.
+const (
+	// A semicircle around the end.
+	CapRound CapStyle = iota
+	// A straight edge through the end.
+	CapFlat
+	// Half of a square centered on the end.
+	CapSquare
+)
+
+// A CapStyle determines the shape of the buffer around the ends of lines.
+type CapStyle int
+
+// Styles for the corners of buffered lines and polygons.
+const (
+	// An arc around the corner.
+	JoinRound JoinStyle = iota
+	// The offset edges are extended until they meet.
+	JoinMitre
+	// The offset edges are joined by a straight edge.
+	JoinBevel
+)
+
+// A JoinStyle determines the shape of the buffer around the outside of
+// corners.
+type JoinStyle int
+
+// BufferOptions controls the shape of a buffer. The zero value approximates
+// circles with 32 segments and uses round caps and joins.
+type BufferOptions struct {
+	// Number of segments used to approximate a quarter circle. Defaults to 8
+	// if it is not positive.
+	QuadrantSegments int
+	Cap              CapStyle
+	Join             JoinStyle
+	// Maximum distance of a mitre from its corner, as a multiple of the
+	// buffer distance. Longer mitres, at sharp corners, are bevelled
+	// instead. Defaults to 5 if it is not positive.
+	MitreLimit float64
+}
+
+// Buffer returns the polygons covering the positions within distance metres
+// of g. A negative distance shrinks polygons instead, and leaves nothing of
+// points and lines. opts may be nil.
+//
+// The buffer is computed in an azimuthal equidistant projection of the
+// WGS84 ellipsoid centered on the centroid of g, and then projected back.
+// Distances from the center are exact, and other distances are distorted by
+// less than 0.05% within 300 km of the center, so Buffer is suited to
+// geometries of up to a few hundred kilometres across. Longitudes in the
+// result are continuous, so buffers that cross the antimeridian have
+// longitudes beyond 180 or -180. Circles are approximated by polygons
+// inscribed in them, as are round caps and joins.
+func Buffer(g Geometry, distance float64, opts *BufferOptions) *MultiPolygon {
+	if opts == nil {
+		opts = &BufferOptions{}
+	}
+	b := &bufferer{
+		distance: distance,
+		segments: opts.QuadrantSegments,
+		cap:      opts.Cap,
+		join:     opts.Join,
+		limit:    opts.MitreLimit,
+	}
+	if b.segments <= 0 {
+		b.segments = 8
+	}
+	if b.limit <= 0 {
+		b.limit = 5
+	}
+	center, ok := Centroid(g)
+	if !ok {
+		return &MultiPolygon{}
+	}
+	proj := newAzimuthalEquidistant(center)
+	m := b.buffer(mapPositions(g, proj.forward))
+	return mapPositions(m, proj.inverse).(*MultiPolygon)
+}
+
+// An azimuthalEquidistant is a projection of the WGS84 ellipsoid that
+// preserves distances and directions from its center along geodesics.
+type azimuthalEquidistant struct {
+	lat0, lon0 float64
+}
+
+func newAzimuthalEquidistant(center Point) *azimuthalEquidistant {
+	return &azimuthalEquidistant{lat0: center.Y, lon0: center.X}
+}
+
+func (p *azimuthalEquidistant) forward(q Point) Point {
+	s12, azi1, _ := geodesic.WGS84.Inverse(p.lat0, p.lon0, q.Y, q.X)
+	sin, cos := math.Sincos(azi1 * math.Pi / 180)
+	return Point{X: s12 * sin, Y: s12 * cos, Elevation: q.Elevation, HasElevation: q.HasElevation}
+}
+
+func (p *azimuthalEquidistant) inverse(q Point) Point {
+	azi1 := math.Atan2(q.X, q.Y) * 180 / math.Pi
+	lat, lon, _ := geodesic.WGS84.Direct(p.lat0, p.lon0, azi1, math.Hypot(q.X, q.Y))
+	// Keep longitudes continuous across the antimeridian.
+	lon = p.lon0 + geodesic.AngNormalize(lon-p.lon0)
+	return Point{X: lon, Y: lat, Elevation: q.Elevation, HasElevation: q.HasElevation}
+}
+
+// mapPositions returns a copy of g with f applied to each position. The
+// JSON-FG geometry types are returned unchanged.
+func mapPositions(g Geometry, f func(p Point) Point) Geometry {
+	points := func(ps []Point) []Point {
+		r := make([]Point, len(ps))
+		for i, p := range ps {
+			r[i] = f(p)
+		}
+		return r
+	}
+	lines := func(ls []LineString) []LineString {
+		r := make([]LineString, len(ls))
+		for i, l := range ls {
+			r[i] = LineString{Points: points(l.Points)}
+		}
+		return r
+	}
+	switch t := g.(type) {
+	case *Point:
+		p := f(*t)
+		return &p
+	case *MultiPoint:
+		return &MultiPoint{Points: points(t.Points)}
+	case *LineString:
+		return &LineString{Points: points(t.Points)}
+	case *MultiLineString:
+		return &MultiLineString{Lines: lines(t.Lines)}
+	case *Polygon:
+		return &Polygon{Rings: lines(t.Rings)}
+	case *MultiPolygon:
+		r := &MultiPolygon{Polygons: make([]Polygon, len(t.Polygons))}
+		for i, p := range t.Polygons {
+			r.Polygons[i] = Polygon{Rings: lines(p.Rings)}
+		}
+		return r
+	case *GeometryCollection:
+		r := &GeometryCollection{Geometries: make([]Geometry, len(t.Geometries))}
+		for i, child := range t.Geometries {
+			r.Geometries[i] = mapPositions(child, f)
+		}
+		return r
+	}
+	return g
+}
+
+// A bufferer buffers planar geometries.
+type bufferer struct {
+	distance float64
+	segments int
+	cap      CapStyle
+	join     JoinStyle
+	limit    float64
+}
+
+func (b *bufferer) buffer(g Geometry) *MultiPolygon {
+	var pieces []Geometry
+	switch t := g.(type) {
+	case *Point:
+		pieces = b.point(xy{t.X, t.Y})
+	case *MultiPoint:
+		for _, p := range t.Points {
+			pieces = append(pieces, b.point(xy{p.X, p.Y})...)
+		}
+	case *LineString:
+		pieces = b.line(distinct(t.Points), false)
+	case *MultiLineString:
+		for _, ls := range t.Lines {
+			pieces = append(pieces, b.line(distinct(ls.Points), false)...)
+		}
+	case *Polygon:
+		return b.polygon(t)
+	case *MultiPolygon:
+		for i := range t.Polygons {
+			pieces = append(pieces, b.polygon(&t.Polygons[i]))
+		}
+	case *GeometryCollection:
+		for _, child := range t.Geometries {
+			pieces = append(pieces, b.buffer(child))
+		}
+	}
+	return unionAll(pieces)
+}
+
+// unionAll returns the union of pieces, merging them in pairs so that the
+// pieces being merged stay similar in size.
+func unionAll(pieces []Geometry) *MultiPolygon {
+	switch len(pieces) {
+	case 0:
+		return &MultiPolygon{}
+	case 1:
+		return Union(pieces[0], &MultiPolygon{})
+	}
+	n := len(pieces) / 2
+	return Union(unionAll(pieces[:n]), unionAll(pieces[n:]))
+}
+
+func (b *bufferer) polygon(p *Polygon) *MultiPolygon {
+	if b.distance == 0 {
+		return Union(p, &MultiPolygon{})
+	}
+	// The buffer of the boundary is added to or removed from the polygon.
+	outer := &bufferer{b.abs(), b.segments, b.cap, b.join, b.limit}
+	var pieces []Geometry
+	for _, ring := range p.Rings {
+		pieces = append(pieces, outer.line(distinct(ring.Points), true)...)
+	}
+	boundary := unionAll(pieces)
+	if b.distance > 0 {
+		return Union(p, boundary)
+	}
+	return Difference(p, boundary)
+}
+
+func (b *bufferer) abs() float64 {
+	return math.Abs(b.distance)
+}
+
+func (b *bufferer) point(p xy) []Geometry {
+	if b.distance <= 0 {
+		return nil
+	}
+	switch b.cap {
+	case CapRound:
+		return []Geometry{b.circle(p)}
+	case CapSquare:
+		d := b.distance
+		return []Geometry{polygonOf(xy{p.x - d, p.y - d}, xy{p.x + d, p.y - d}, xy{p.x + d, p.y + d}, xy{p.x - d, p.y + d})}
+	}
+	return nil
+}
+
+// line returns the pieces of the buffer of the line through ps, which is
+// closed if ring is set.
+func (b *bufferer) line(ps []xy, ring bool) []Geometry {
+	if ring && len(ps) > 1 && ps[0] == ps[len(ps)-1] {
+		ps = ps[:len(ps)-1]
+	}
+	if b.distance <= 0 || len(ps) == 0 {
+		return nil
+	}
+	if len(ps) == 1 {
+		return b.point(ps[0])
+	}
+	if ring && len(ps) == 2 {
+		ring = false
+	}
+	d := b.distance
+	var pieces []Geometry
+	n := len(ps)
+	segments := n - 1
+	if ring {
+		segments = n
+	}
+	for i := 0; i < segments; i++ {
+		p, q := ps[i], ps[(i+1)%n]
+		nx, ny := normal(p, q)
+		pieces = append(pieces, polygonOf(
+			xy{p.x - d*nx, p.y - d*ny}, xy{q.x - d*nx, q.y - d*ny},
+			xy{q.x + d*nx, q.y + d*ny}, xy{p.x + d*nx, p.y + d*ny},
+		))
+	}
+	for i := 0; i < n; i++ {
+		if !ring && (i == 0 || i == n-1) {
+			continue
+		}
+		if join := b.joinAt(ps[(i+n-1)%n], ps[i], ps[(i+1)%n]); join != nil {
+			pieces = append(pieces, join)
+		}
+	}
+	if !ring {
+		pieces = append(pieces, b.capAt(ps[1], ps[0])...)
+		pieces = append(pieces, b.capAt(ps[n-2], ps[n-1])...)
+	}
+	return pieces
+}
+
+// normal returns the unit normal to the left of the segment from p to q.
+func normal(p, q xy) (float64, float64) {
+	dx, dy := q.x-p.x, q.y-p.y
+	l := math.Hypot(dx, dy)
+	return -dy / l, dx / l
+}
+
+// joinAt returns the piece that fills the outside of the corner at q
+// between the segments from p to q and from q to r, if any.
+func (b *bufferer) joinAt(p, q, r xy) Geometry {
+	turn := orient(p, q, r)
+	if b.join == JoinRound {
+		if turn == 0 && (q.x-p.x)*(r.x-q.x)+(q.y-p.y)*(r.y-q.y) > 0 {
+			// The line continues straight on.
+			return nil
+		}
+		return b.circle(q)
+	}
+	if turn == 0 {
+		return nil
+	}
+	// The outside of the corner is to the right of a left turn.
+	d := b.distance
+	if turn > 0 {
+		d = -d
+	}
+	n1x, n1y := normal(p, q)
+	n2x, n2y := normal(q, r)
+	a := xy{q.x + d*n1x, q.y + d*n1y}
+	c := xy{q.x + d*n2x, q.y + d*n2y}
+	if b.join == JoinMitre {
+		// The offset edges meet at q + d (n1 + n2) / (1 + n1 . n2).
+		k := 1 + n1x*n2x + n1y*n2y
+		if k > 0 && math.Sqrt(2/k) <= b.limit {
+			m := xy{q.x + d*(n1x+n2x)/k, q.y + d*(n1y+n2y)/k}
+			return polygonOf(q, a, m, c)
+		}
+	}
+	return polygonOf(q, a, c)
+}
+
+// capAt returns the pieces that cap the end q of the segment from p to q.
+func (b *bufferer) capAt(p, q xy) []Geometry {
+	switch b.cap {
+	case CapRound:
+		return []Geometry{b.circle(q)}
+	case CapSquare:
+		d := b.distance
+		nx, ny := normal(p, q)
+		// The tangent points away from the line.
+		tx, ty := ny, -nx
+		return []Geometry{polygonOf(
+			xy{q.x - d*nx, q.y - d*ny}, xy{q.x - d*nx + d*tx, q.y - d*ny + d*ty},
+			xy{q.x + d*nx + d*tx, q.y + d*ny + d*ty}, xy{q.x + d*nx, q.y + d*ny},
+		)}
+	}
+	return nil
+}
+
+// circle returns a polygon inscribed in the circle of radius b.distance
+// around p.
+func (b *bufferer) circle(p xy) Geometry {
+	n := 4 * b.segments
+	ps := make([]xy, n)
+	for i := range ps {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+		ps[i] = xy{p.x + b.distance*cos, p.y + b.distance*sin}
+	}
+	return polygonOf(ps...)
+}
+
+// polygonOf returns the polygon with the given ring, which is closed
+// implicitly.
+func polygonOf(ring ...xy) *Polygon {
+	return &Polygon{Rings: []LineString{closedRing(ring)}}
+}
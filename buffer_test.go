@@ -0,0 +1,174 @@
+package geojson
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestBuffer_Planar(t *testing.T) {
+	square := func(x0, y0, x1, y1 float64) LineString {
+		return LineString{Points: []Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}, {X: x0, Y: y0}}}
+	}
+	line := &LineString{Points: []Point{{X: 0, Y: 0}, {X: 10, Y: 0}}}
+	corner := &LineString{Points: []Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}}}
+	sharp := &LineString{Points: []Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 0, Y: 1}}}
+	box := &Polygon{Rings: []LineString{square(0, 0, 10, 10)}}
+	holed := &Polygon{Rings: []LineString{square(0, 0, 10, 10), square(3, 3, 7, 7)}}
+	// The area of a circle of radius 1 approximated by 32 segments.
+	circle := 16 * math.Sin(math.Pi/16)
+	cases := []struct {
+		g        Geometry
+		distance float64
+		b        bufferer
+		area     float64
+	}{
+		{&Point{}, 1, bufferer{cap: CapRound}, circle},
+		{&Point{}, 1, bufferer{cap: CapSquare}, 4},
+		{&Point{}, 1, bufferer{cap: CapFlat}, 0},
+		{line, 1, bufferer{cap: CapRound}, 20 + circle},
+		{line, 1, bufferer{cap: CapFlat}, 20},
+		{line, 1, bufferer{cap: CapSquare}, 24},
+		{line, -1, bufferer{cap: CapRound}, 0},
+		{corner, 1, bufferer{cap: CapFlat, join: JoinRound}, 39 + circle/4},
+		{corner, 1, bufferer{cap: CapFlat, join: JoinBevel}, 39.5},
+		{corner, 1, bufferer{cap: CapFlat, join: JoinMitre}, 40},
+		// Sharp corners are bevelled instead of mitred.
+		{sharp, 1, bufferer{cap: CapFlat, join: JoinMitre}, Area(sharp, nil)},
+		{box, 1, bufferer{join: JoinMitre}, 144},
+		{box, 1, bufferer{join: JoinRound}, 140 + circle},
+		{box, -1, bufferer{join: JoinRound}, 64},
+		{box, -1, bufferer{join: JoinMitre}, 64},
+		{box, -6, bufferer{}, 0},
+		{box, 0, bufferer{}, 100},
+		{holed, 1, bufferer{join: JoinMitre}, 140},
+		{holed, -1, bufferer{join: JoinMitre}, 100 - 36 - 36},
+		{&GeometryCollection{Geometries: []Geometry{&Point{}, &Point{X: 10}}}, 1, bufferer{}, 2 * circle},
+	}
+	for i, c := range cases {
+		c.b.distance = c.distance
+		c.b.segments = 8
+		c.b.limit = 5
+		m := c.b.buffer(c.g)
+		expected := c.area
+		if i == 10 {
+			bevel := bufferer{distance: 1, segments: 8, cap: CapFlat, join: JoinBevel}
+			expected = planarArea(bevel.buffer(sharp))
+		}
+		if area := planarArea(m); math.Abs(area-expected) > 1e-9 {
+			t.Errorf("case %d: expected area %v, got %v", i, expected, area)
+		}
+	}
+}
+
+func TestBuffer_RandomLines(t *testing.T) {
+	// Random walks, half of which turn only slightly at each vertex, so that
+	// the pieces of the buffer meet along nearly collinear edges.
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 6; i++ {
+		turn := math.Pi
+		if i%2 == 1 {
+			turn = 0.01
+		}
+		var points []Point
+		var x, y, heading, length, longest float64
+		for j := 0; j < 60; j++ {
+			points = append(points, Point{X: x, Y: y})
+			step := 1 + 9*r.Float64()
+			heading += turn * (2*r.Float64() - 1)
+			x += step * math.Cos(heading)
+			y += step * math.Sin(heading)
+			if j < 59 {
+				length += step
+				longest = math.Max(longest, step)
+			}
+		}
+		line := &LineString{Points: points}
+		d := 0.5 + 5*r.Float64()
+		for _, join := range []JoinStyle{JoinRound, JoinMitre, JoinBevel} {
+			for _, cap := range []CapStyle{CapRound, CapFlat, CapSquare} {
+				b := bufferer{distance: d, segments: 8, cap: cap, join: join, limit: 5}
+				m := b.buffer(line)
+				for j, p := range points {
+					if cap == CapFlat && (j == 0 || j == len(points)-1) {
+						// Flat caps pass through the ends.
+						continue
+					}
+					if l := m.Locate(p); l != Interior {
+						t.Errorf("case %d, join %d, cap %d: expected %v to be in the interior, got %v", i, join, cap, p, l)
+						break
+					}
+				}
+				// The buffer holds the rectangle around the longest segment,
+				// and no more than the rectangles around every segment, two
+				// square caps, and a mitre or circle at each vertex.
+				lower := 2 * d * longest
+				upper := 2*d*length + 4*d*d + 58*math.Max(math.Pi, b.limit)*d*d
+				if area := planarArea(m); area < lower || area > upper {
+					t.Errorf("case %d, join %d, cap %d: expected area in [%v, %v], got %v", i, join, cap, lower, upper, area)
+				}
+			}
+		}
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	// Each vertex of the buffer of a point is exactly the distance away.
+	p := &Point{X: 151.2, Y: -33.9}
+	m := Buffer(p, 100, nil)
+	if len(m.Polygons) != 1 || len(m.Polygons[0].Rings[0].Points) != 33 {
+		t.Fatalf("expected a polygon with 32 sides, got %#v", m)
+	}
+	for _, q := range m.Polygons[0].Rings[0].Points {
+		if d := p.Distance(q); math.Abs(d-100) > 1e-6 {
+			t.Errorf("expected %#v to be 100 m away, got %v", q, d)
+		}
+	}
+
+	// The area of the buffer of a line is close to that of a capsule.
+	line := &LineString{Points: []Point{{X: 10, Y: 45}, {X: 10.1, Y: 45.05}}}
+	m = Buffer(line, 50, &BufferOptions{QuadrantSegments: 64})
+	expected := 2*50*Length(line, nil) + math.Pi*50*50
+	if area := Area(m, nil); math.Abs(area-expected) > 1e-3*expected {
+		t.Errorf("expected area near %v, got %v", expected, area)
+	}
+
+	// The result follows the right hand rule.
+	for _, ring := range m.Polygons[0].Rings {
+		if a, _, _ := ringCentroid(ring.Points); a <= 0 {
+			t.Errorf("expected a counter-clockwise ring, got %v", ring.Points)
+		}
+	}
+
+	// Nearly straight lines, whose joins meet the other pieces along nearly
+	// collinear edges.
+	line = &LineString{Points: []Point{{X: 10, Y: 50}, {X: 10.001, Y: 50}, {X: 10.002, Y: 50.0001}, {X: 10.003, Y: 49.9998}}}
+	for _, d := range []float64{5, 20, 50} {
+		for _, join := range []JoinStyle{JoinRound, JoinMitre, JoinBevel} {
+			for _, cap := range []CapStyle{CapRound, CapFlat, CapSquare} {
+				m := Buffer(line, d, &BufferOptions{Cap: cap, Join: join})
+				lower := 0.99 * 2 * d * Length(line, nil)
+				upper := 1.01 * (2*d*Length(line, nil) + 4*d*d)
+				if area := Area(m, nil); area < lower || area > upper {
+					t.Errorf("distance %v, join %d, cap %d: expected area in [%v, %v], got %v", d, join, cap, lower, upper, area)
+				}
+			}
+		}
+	}
+
+	if m := Buffer(&GeometryCollection{}, 10, nil); len(m.Polygons) != 0 {
+		t.Errorf("expected an empty buffer, got %#v", m)
+	}
+}
+
+// planarArea returns the area of m, treating coordinates as planar.
+func planarArea(m *MultiPolygon) float64 {
+	var area float64
+	for _, p := range m.Polygons {
+		for _, ring := range p.Rings {
+			a, _, _ := ringCentroid(ring.Points)
+			area += a
+		}
+	}
+	return area
+}
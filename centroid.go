@@ -0,0 +1,244 @@
+package geojson
+
+import (
+	"math"
+	"sort"
+)
+
+// The functions in this file treat coordinates as planar, which is the usual
+// convention for placing labels on a map. Geometries that cross the
+// antimeridian should be split or shifted first.
+
+// Centroid returns the centroid of g. Only the components of g with the
+// highest dimension contribute: polygons are weighted by area, lines by
+// length, and points equally, so the centroid of a GeometryCollection
+// containing a Polygon ignores its Points and LineStrings. Components that
+// are degenerate, such as a Polygon with zero area, count as lower
+// dimensional ones. It returns false if g has no positions.
+func Centroid(g Geometry) (Point, bool) {
+	var c centroid
+	c.add(g)
+	return c.result()
+}
+
+// centroid accumulates the moments and weights of each dimension.
+type centroid struct {
+	areaX, areaY, area   float64
+	lineX, lineY, length float64
+	pointX, pointY       float64
+	points               int
+}
+
+func (c *centroid) add(g Geometry) {
+	switch t := g.(type) {
+	case *Point:
+		c.addPoint(*t)
+	case *MultiPoint:
+		for _, p := range t.Points {
+			c.addPoint(p)
+		}
+	case *LineString:
+		c.addLine(t.Points)
+	case *MultiLineString:
+		for _, ls := range t.Lines {
+			c.addLine(ls.Points)
+		}
+	case *Polygon:
+		c.addPolygon(t)
+	case *MultiPolygon:
+		for i := range t.Polygons {
+			c.addPolygon(&t.Polygons[i])
+		}
+	case *GeometryCollection:
+		for _, child := range t.Geometries {
+			c.add(child)
+		}
+	}
+}
+
+func (c *centroid) addPoint(p Point) {
+	c.pointX += p.X
+	c.pointY += p.Y
+	c.points++
+}
+
+func (c *centroid) addLine(points []Point) {
+	for i, p := range points {
+		c.addPoint(p)
+		if i == 0 {
+			continue
+		}
+		q := points[i-1]
+		l := math.Hypot(p.X-q.X, p.Y-q.Y)
+		c.lineX += l * (p.X + q.X) / 2
+		c.lineY += l * (p.Y + q.Y) / 2
+		c.length += l
+	}
+}
+
+func (c *centroid) addPolygon(p *Polygon) {
+	for i, ring := range p.Rings {
+		c.addLine(ring.Points)
+		a, x, y := ringCentroid(ring.Points)
+		// Holes are subtracted regardless of their winding.
+		a = math.Abs(a)
+		if i > 0 {
+			a = -a
+		}
+		c.areaX += a * x
+		c.areaY += a * y
+		c.area += a
+	}
+}
+
+func (c *centroid) result() (Point, bool) {
+	switch {
+	case c.area != 0:
+		return Point{X: c.areaX / c.area, Y: c.areaY / c.area}, true
+	case c.length != 0:
+		return Point{X: c.lineX / c.length, Y: c.lineY / c.length}, true
+	case c.points != 0:
+		n := float64(c.points)
+		return Point{X: c.pointX / n, Y: c.pointY / n}, true
+	}
+	return Point{}, false
+}
+
+// ringCentroid returns the signed area and centroid of ring, which is closed
+// implicitly. The area is positive for counter-clockwise rings.
+func ringCentroid(ring []Point) (area, x, y float64) {
+	if len(ring) < 3 {
+		return 0, 0, 0
+	}
+	// Measure relative to the first vertex to limit rounding error.
+	o := ring[0]
+	var sx, sy float64
+	for i := 1; i+1 < len(ring); i++ {
+		ax, ay := ring[i].X-o.X, ring[i].Y-o.Y
+		bx, by := ring[i+1].X-o.X, ring[i+1].Y-o.Y
+		cross := ax*by - bx*ay
+		area += cross
+		sx += (ax + bx) * cross
+		sy += (ay + by) * cross
+	}
+	if area == 0 {
+		return 0, 0, 0
+	}
+	return area / 2, o.X + sx/(3*area), o.Y + sy/(3*area)
+}
+
+// InteriorPoint returns a point that is guaranteed to lie in the interior of
+// the polygons of g, which makes it a good place for a label. Unlike the
+// centroid, it cannot fall in a hole or outside a concave polygon, although
+// it is not necessarily far from the boundary; see Polylabel for that.
+//
+// The point lies midway across the widest interior span of a horizontal
+// line through the middle of one of the polygons. If g has no polygons with
+// area, it returns the vertex of g nearest to the centroid instead. It
+// returns false if g has no positions.
+func InteriorPoint(g Geometry) (Point, bool) {
+	var best Point
+	width := -1.0
+	walkPolygons(g, func(p *Polygon) {
+		if q, w, ok := interiorPoint(p); ok && w > width {
+			best, width = q, w
+		}
+	})
+	if width >= 0 {
+		return best, true
+	}
+
+	c, ok := Centroid(g)
+	if !ok {
+		return Point{}, false
+	}
+	d := math.Inf(1)
+	walkPositions(g, func(p Point) {
+		if dp := math.Hypot(p.X-c.X, p.Y-c.Y); dp < d {
+			best, d = Point{X: p.X, Y: p.Y}, dp
+		}
+	})
+	return best, true
+}
+
+// interiorPoint returns the midpoint of the widest span of p along a scan
+// line, along with the width of that span. It returns false if p has no
+// area.
+func interiorPoint(p *Polygon) (Point, float64, bool) {
+	if len(p.Rings) == 0 || len(p.Rings[0].Points) == 0 {
+		return Point{}, 0, false
+	}
+	// Choose a scan line halfway between the vertices nearest to the middle
+	// of the shell, so that it does not pass through any vertex.
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, q := range p.Rings[0].Points {
+		lo = math.Min(lo, q.Y)
+		hi = math.Max(hi, q.Y)
+	}
+	mid := (lo + hi) / 2
+	for _, ring := range p.Rings {
+		for _, q := range ring.Points {
+			if q.Y <= mid && q.Y > lo {
+				lo = q.Y
+			} else if q.Y > mid && q.Y < hi {
+				hi = q.Y
+			}
+		}
+	}
+	y := (lo + hi) / 2
+
+	var xs []float64
+	for _, ring := range p.Rings {
+		points := ring.Points
+		for i, j := 0, len(points)-1; i < len(points); j, i = i, i+1 {
+			a, b := points[j], points[i]
+			if (a.Y > y) != (b.Y > y) {
+				xs = append(xs, a.X+(y-a.Y)*(b.X-a.X)/(b.Y-a.Y))
+			}
+		}
+	}
+	sort.Float64s(xs)
+	var best Point
+	width := -1.0
+	for i := 0; i+1 < len(xs); i += 2 {
+		if w := xs[i+1] - xs[i]; w > width {
+			best, width = Point{X: (xs[i] + xs[i+1]) / 2, Y: y}, w
+		}
+	}
+	if width <= 0 {
+		return Point{}, 0, false
+	}
+	return best, width, true
+}
+
+// walkPositions calls f for each position within g.
+func walkPositions(g Geometry, f func(p Point)) {
+	switch t := g.(type) {
+	case *Point:
+		f(*t)
+	case *MultiPoint:
+		for _, p := range t.Points {
+			f(p)
+		}
+	case *LineString:
+		for _, p := range t.Points {
+			f(p)
+		}
+	case *MultiLineString:
+		for _, ls := range t.Lines {
+			walkPositions(&ls, f)
+		}
+	case *Polygon:
+		for _, ring := range t.Rings {
+			walkPositions(&ring, f)
+		}
+	case *MultiPolygon:
+		for i := range t.Polygons {
+			walkPositions(&t.Polygons[i], f)
+		}
+	case *GeometryCollection:
+		for _, child := range t.Geometries {
+			walkPositions(child, f)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCentroid(t *testing.T) {
+	square := func(x0, y0, x1, y1 float64) LineString {
+		return LineString{Points: []Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}, {X: x0, Y: y0}}}
+	}
+	clockwise := LineString{Points: []Point{{X: 0, Y: 0}, {X: 0, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 0}, {X: 0, Y: 0}}}
+	ell := &Polygon{Rings: []LineString{{Points: []Point{
+		{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 2}, {X: 0, Y: 2}, {X: 0, Y: 0},
+	}}}}
+	cases := []struct {
+		g        Geometry
+		expected Point
+		ok       bool
+	}{
+		{&Polygon{Rings: []LineString{square(0, 0, 2, 2)}}, Point{X: 1, Y: 1}, true},
+		{&Polygon{Rings: []LineString{clockwise}}, Point{X: 1, Y: 1}, true},
+		{ell, Point{X: 5.0 / 6, Y: 5.0 / 6}, true},
+		{&Polygon{Rings: []LineString{square(0, 0, 4, 4), square(1, 1, 2, 2)}}, Point{X: 30.5 / 15, Y: 30.5 / 15}, true},
+		{&MultiPolygon{Polygons: []Polygon{{Rings: []LineString{square(0, 0, 1, 1)}}, {Rings: []LineString{square(2, 0, 4, 1)}}}}, Point{X: 6.5 / 3, Y: 0.5}, true},
+		// Lower dimensional components are ignored.
+		{&GeometryCollection{Geometries: []Geometry{&Point{X: 100, Y: 100}, &Polygon{Rings: []LineString{square(0, 0, 2, 2)}}}}, Point{X: 1, Y: 1}, true},
+		{&LineString{Points: []Point{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 1}}}, Point{X: 4.0 / 3, Y: 1.0 / 6}, true},
+		// A polygon without area is treated as a line.
+		{&Polygon{Rings: []LineString{{Points: []Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 0, Y: 0}}}}}, Point{X: 1, Y: 0}, true},
+		{&MultiPoint{Points: []Point{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 1, Y: 3}}}, Point{X: 1, Y: 1}, true},
+		{&Point{X: 3, Y: 4, Elevation: 5, HasElevation: true}, Point{X: 3, Y: 4}, true},
+		{&GeometryCollection{}, Point{}, false},
+	}
+	for i, c := range cases {
+		p, ok := Centroid(c.g)
+		if ok != c.ok || math.Abs(p.X-c.expected.X) > 1e-12 || math.Abs(p.Y-c.expected.Y) > 1e-12 || p.HasElevation {
+			t.Errorf("case %d: expected (%#v, %v), got (%#v, %v)", i, c.expected, c.ok, p, ok)
+		}
+	}
+}
+
+func TestInteriorPoint(t *testing.T) {
+	square := func(x0, y0, x1, y1 float64) LineString {
+		return LineString{Points: []Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}, {X: x0, Y: y0}}}
+	}
+	// The centroid of this U shape lies in its notch.
+	u := &Polygon{Rings: []LineString{{Points: []Point{
+		{X: 0, Y: 0}, {X: 3, Y: 0}, {X: 3, Y: 3}, {X: 2, Y: 3}, {X: 2, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 3}, {X: 0, Y: 3}, {X: 0, Y: 0},
+	}}}}
+	cases := []struct {
+		g        Geometry
+		expected Point
+		ok       bool
+	}{
+		{&Polygon{Rings: []LineString{square(0, 0, 2, 2)}}, Point{X: 1, Y: 1}, true},
+		{u, Point{X: 0.5, Y: 2}, true},
+		{&Polygon{Rings: []LineString{square(0, 0, 4, 4), square(1, 1, 3, 3)}}, Point{X: 0.5, Y: 2}, true},
+		// The widest span wins.
+		{&MultiPolygon{Polygons: []Polygon{{Rings: []LineString{square(0, 0, 1, 1)}}, {Rings: []LineString{square(2, 0, 5, 1)}}}}, Point{X: 3.5, Y: 0.5}, true},
+		// Without polygons, the vertex nearest to the centroid is used.
+		{&LineString{Points: []Point{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 1}}}, Point{X: 2, Y: 0}, true},
+		{&GeometryCollection{}, Point{}, false},
+	}
+	for i, c := range cases {
+		p, ok := InteriorPoint(c.g)
+		if ok != c.ok || p != c.expected {
+			t.Errorf("case %d: expected (%#v, %v), got (%#v, %v)", i, c.expected, c.ok, p, ok)
+		}
+	}
+	if c, _ := Centroid(u); boundaryDistance(c, u) >= 0 {
+		t.Errorf("expected centroid %#v to lie outside", c)
+	}
+}
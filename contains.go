@@ -0,0 +1,229 @@
+package geojson
+
+import "math"
+
+// A Location is the position of a point relative to a geometry, using
+// planar coordinates.
+type Location int
+
+const (
+	Exterior Location = iota
+	Boundary
+	Interior
+)
+
+func (l Location) String() string {
+	switch l {
+	case Exterior:
+		return "exterior"
+	case Boundary:
+		return "boundary"
+	case Interior:
+		return "interior"
+	}
+	return "unknown location"
+}
+
+// Locate returns the location of pt relative to p. Points in holes are in
+// the exterior of p, and points on any ring, including a hole, are on its
+// boundary. Boundary tests are exact, so a point is only on the boundary if
+// it lies exactly on a ring.
+func (p *Polygon) Locate(pt Point) Location {
+	var l locator
+	for _, ring := range p.Rings {
+		if l.addRing(pt, ring.Points) {
+			return Boundary
+		}
+	}
+	return l.location()
+}
+
+// Contains reports whether pt lies in the interior of p, excluding its
+// boundary.
+func (p *Polygon) Contains(pt Point) bool {
+	return p.Locate(pt) == Interior
+}
+
+// Covers reports whether pt lies in the interior or on the boundary of p.
+func (p *Polygon) Covers(pt Point) bool {
+	return p.Locate(pt) != Exterior
+}
+
+// Locate returns the location of pt relative to m, as with Polygon.Locate.
+// The polygons of m must not overlap.
+func (m *MultiPolygon) Locate(pt Point) Location {
+	var l locator
+	for _, p := range m.Polygons {
+		for _, ring := range p.Rings {
+			if l.addRing(pt, ring.Points) {
+				return Boundary
+			}
+		}
+	}
+	return l.location()
+}
+
+// Contains reports whether pt lies in the interior of m, excluding its
+// boundary.
+func (m *MultiPolygon) Contains(pt Point) bool {
+	return m.Locate(pt) == Interior
+}
+
+// Covers reports whether pt lies in the interior or on the boundary of m.
+func (m *MultiPolygon) Covers(pt Point) bool {
+	return m.Locate(pt) != Exterior
+}
+
+// A locator locates a point using the even-odd rule.
+type locator struct {
+	inside bool
+}
+
+// addRing adds the edges of ring, which is closed implicitly, and reports
+// whether pt lies on one of them.
+func (l *locator) addRing(pt Point, ring []Point) bool {
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		if l.addEdge(pt, ring[j], ring[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// addEdge adds the edge from a to b and reports whether pt lies on it.
+func (l *locator) addEdge(pt, a, b Point) bool {
+	if (a.Y > pt.Y) != (b.Y > pt.Y) {
+		// The edge straddles the horizontal line through pt. Compare the
+		// orientation instead of the intersection with that line, which
+		// would not be exact.
+		cross := (a.X-pt.X)*(b.Y-pt.Y) - (b.X-pt.X)*(a.Y-pt.Y)
+		if cross == 0 {
+			return true
+		}
+		if (cross > 0) == (b.Y > a.Y) {
+			l.inside = !l.inside
+		}
+		return false
+	}
+	// Otherwise pt can only be on the edge if the edge is horizontal or pt
+	// is one of its ends.
+	if pt.Y == a.Y && pt.Y == b.Y {
+		return pt.X >= math.Min(a.X, b.X) && pt.X <= math.Max(a.X, b.X)
+	}
+	return pt.X == a.X && pt.Y == a.Y || pt.X == b.X && pt.Y == b.Y
+}
+
+func (l *locator) location() Location {
+	if l.inside {
+		return Interior
+	}
+	return Exterior
+}
+
+// A PreparedPolygon answers repeated location queries against the same
+// polygons faster than Polygon.Locate. It indexes the edges of the polygons
+// in bands of Y (latitude), so that each query only tests the edges in the
+// band of the point. For typical polygons, that takes roughly constant time
+// rather than time proportional to the number of edges.
+//
+// A PreparedPolygon is safe for concurrent use.
+type PreparedPolygon struct {
+	minX, minY, maxX, maxY float64
+	// Height of each band.
+	height float64
+	// Edges that overlap each band.
+	bands [][]edge
+}
+
+type edge struct {
+	a, b Point
+}
+
+// NewPreparedPolygon prepares the polygons of g, which may be a Polygon, a
+// MultiPolygon, or a GeometryCollection containing them, for location
+// queries. The polygons must not overlap. Later changes to g do not affect
+// the PreparedPolygon.
+func NewPreparedPolygon(g Geometry) *PreparedPolygon {
+	pp := &PreparedPolygon{
+		minX: math.Inf(1), minY: math.Inf(1),
+		maxX: math.Inf(-1), maxY: math.Inf(-1),
+	}
+	var edges []edge
+	walkPolygons(g, func(p *Polygon) {
+		for _, ring := range p.Rings {
+			points := ring.Points
+			for i, j := 0, len(points)-1; i < len(points); j, i = i, i+1 {
+				a, b := points[j], points[i]
+				edges = append(edges, edge{Point{X: a.X, Y: a.Y}, Point{X: b.X, Y: b.Y}})
+				pp.minX = math.Min(pp.minX, a.X)
+				pp.minY = math.Min(pp.minY, a.Y)
+				pp.maxX = math.Max(pp.maxX, a.X)
+				pp.maxY = math.Max(pp.maxY, a.Y)
+			}
+		}
+	})
+	if len(edges) == 0 {
+		return pp
+	}
+	// Use about one band per edge, so that each band holds few edges, unless
+	// the edges are so tall that they would be copied into too many bands.
+	var total float64
+	for _, e := range edges {
+		total += math.Abs(e.b.Y - e.a.Y)
+	}
+	n := float64(len(edges))
+	pp.height = math.Max((pp.maxY-pp.minY)/n, total/(2*n))
+	bands := 1
+	if pp.height > 0 {
+		bands = int(math.Ceil((pp.maxY - pp.minY) / pp.height))
+	}
+	pp.bands = make([][]edge, bands)
+	for _, e := range edges {
+		lo := pp.band(math.Min(e.a.Y, e.b.Y))
+		hi := pp.band(math.Max(e.a.Y, e.b.Y))
+		for i := lo; i <= hi; i++ {
+			pp.bands[i] = append(pp.bands[i], e)
+		}
+	}
+	return pp
+}
+
+// band returns the index of the band containing y, which must lie within
+// the bounds of pp.
+func (pp *PreparedPolygon) band(y float64) int {
+	if pp.height == 0 {
+		return 0
+	}
+	i := int((y - pp.minY) / pp.height)
+	if i >= len(pp.bands) {
+		i = len(pp.bands) - 1
+	}
+	return i
+}
+
+// Locate returns the location of pt relative to the prepared polygons, as
+// with Polygon.Locate.
+func (pp *PreparedPolygon) Locate(pt Point) Location {
+	if !(pt.X >= pp.minX && pt.X <= pp.maxX && pt.Y >= pp.minY && pt.Y <= pp.maxY) {
+		return Exterior
+	}
+	var l locator
+	for _, e := range pp.bands[pp.band(pt.Y)] {
+		if l.addEdge(pt, e.a, e.b) {
+			return Boundary
+		}
+	}
+	return l.location()
+}
+
+// Contains reports whether pt lies in the interior of the prepared
+// polygons, excluding their boundaries.
+func (pp *PreparedPolygon) Contains(pt Point) bool {
+	return pp.Locate(pt) == Interior
+}
+
+// Covers reports whether pt lies in the interior or on the boundary of the
+// prepared polygons.
+func (pp *PreparedPolygon) Covers(pt Point) bool {
+	return pp.Locate(pt) != Exterior
+}
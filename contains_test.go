@@ -0,0 +1,94 @@
+package geojson
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestLocate(t *testing.T) {
+	square := func(x0, y0, x1, y1 float64) LineString {
+		return LineString{Points: []Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}, {X: x0, Y: y0}}}
+	}
+	holed := &Polygon{Rings: []LineString{square(0, 0, 4, 4), square(1, 1, 2, 2)}}
+	// A diamond, whose edges are not axis-aligned, wound clockwise.
+	diamond := &Polygon{Rings: []LineString{{Points: []Point{{X: 10, Y: 0}, {X: 9, Y: 1}, {X: 10, Y: 2}, {X: 11, Y: 1}, {X: 10, Y: 0}}}}}
+	multi := &MultiPolygon{Polygons: []Polygon{*holed, *diamond}}
+	cases := []struct {
+		pt       Point
+		expected Location
+	}{
+		{Point{X: 3, Y: 3}, Interior},
+		{Point{X: 0.5, Y: 1.5}, Interior},
+		{Point{X: 1.5, Y: 1.5}, Exterior},
+		{Point{X: 5, Y: 2}, Exterior},
+		{Point{X: -1, Y: 1}, Exterior},
+		// Vertices and edges of the shell and the hole.
+		{Point{X: 0, Y: 0}, Boundary},
+		{Point{X: 4, Y: 2.5}, Boundary},
+		{Point{X: 2, Y: 4}, Boundary},
+		{Point{X: 1, Y: 1}, Boundary},
+		{Point{X: 1.5, Y: 2}, Boundary},
+		// Rays through vertices must not be counted twice.
+		{Point{X: 0.5, Y: 1}, Interior},
+		{Point{X: -1, Y: 4}, Exterior},
+		{Point{X: 10, Y: 1}, Interior},
+		{Point{X: 8, Y: 1}, Exterior},
+		{Point{X: 9.5, Y: 0.5}, Boundary},
+		{Point{X: 10, Y: 2}, Boundary},
+		{Point{X: 10, Y: 2.1}, Exterior},
+	}
+	prepared := NewPreparedPolygon(multi)
+	for i, c := range cases {
+		if l := multi.Locate(c.pt); l != c.expected {
+			t.Errorf("case %d: expected %v, got %v", i, c.expected, l)
+		}
+		if l := prepared.Locate(c.pt); l != c.expected {
+			t.Errorf("case %d: expected %v from prepared polygon, got %v", i, c.expected, l)
+		}
+		p := holed
+		if c.pt.X > 5 {
+			p = diamond
+		}
+		if l := p.Locate(c.pt); l != c.expected {
+			t.Errorf("case %d: expected %v from polygon, got %v", i, c.expected, l)
+		}
+		if p.Contains(c.pt) != (c.expected == Interior) || p.Covers(c.pt) != (c.expected != Exterior) {
+			t.Errorf("case %d: inconsistent Contains and Covers", i)
+		}
+	}
+
+	if l := NewPreparedPolygon(&GeometryCollection{}).Locate(Point{}); l != Exterior {
+		t.Errorf("expected empty prepared polygon to be exterior, got %v", l)
+	}
+}
+
+func TestPreparedPolygon(t *testing.T) {
+	// A star, whose edges all span most of its height, and a polygon with
+	// many short edges.
+	var star, circle []Point
+	for i := 0; i <= 200; i++ {
+		a := float64(i) * math.Pi / 100
+		r := 1.0
+		if i%2 == 1 {
+			r = 0.1
+		}
+		star = append(star, Point{X: r * math.Cos(a), Y: r * math.Sin(a)})
+		circle = append(circle, Point{X: math.Cos(a), Y: math.Sin(a)})
+	}
+	r := rand.New(rand.NewSource(1))
+	for _, ring := range [][]Point{star, circle} {
+		p := &Polygon{Rings: []LineString{{Points: ring}}}
+		prepared := NewPreparedPolygon(p)
+		for i := 0; i < 10000; i++ {
+			pt := Point{X: r.Float64()*2.2 - 1.1, Y: r.Float64()*2.2 - 1.1}
+			if i%10 == 0 {
+				// Exercise the boundary.
+				pt = ring[r.Intn(len(ring))]
+			}
+			if l, expected := prepared.Locate(pt), p.Locate(pt); l != expected {
+				t.Fatalf("%#v: expected %v, got %v", pt, expected, l)
+			}
+		}
+	}
+}
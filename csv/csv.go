@@ -0,0 +1,63 @@
+// Package csv converts between delimited text tables and GeoJSON
+// FeatureCollections.
+//
+// Each row of a table is one Feature. Geometry is taken either from a pair of
+// coordinate columns, which yields Points, or from a single column holding
+// Well-Known Text. Every other column becomes a Feature property.
+package csv
+
+import "strings"
+
+// Options controls how tables are mapped to features. The zero value is
+// valid: when reading, geometry columns are detected from the header, and
+// when writing, geometries are written as WKT in a column named "WKT".
+type Options struct {
+	// Name of the column holding X coordinates (usually longitude). Must be
+	// set together with YColumn.
+	XColumn string
+	// Name of the column holding Y coordinates (usually latitude).
+	YColumn string
+	// Name of the column holding elevations. Optional; only used together
+	// with XColumn and YColumn.
+	ZColumn string
+	// Name of the column holding WKT geometries. Ignored if XColumn and
+	// YColumn are set.
+	WKTColumn string
+	// Name of the column holding Feature IDs. Optional. If unset, IDs are
+	// dropped when writing, and when reading every column other than the
+	// geometry columns becomes a property.
+	IDColumn string
+	// Field delimiter. Defaults to ','.
+	Comma rune
+	// Whether to convert property values that look like numbers or booleans
+	// into float64 and bool values, and empty values into nil. If false, all
+	// properties are strings. Values with a leading "+" or a leading zero,
+	// such as phone numbers and postcodes, and integers too large for a
+	// float64 to hold exactly stay strings. Values are never converted when
+	// writing.
+	InferTypes bool
+}
+
+// Column names recognized when reading without explicit geometry columns.
+// Matching is case-insensitive.
+var (
+	xColumnNames   = []string{"lon", "lng", "long", "longitude", "x"}
+	yColumnNames   = []string{"lat", "latitude", "y"}
+	zColumnNames   = []string{"alt", "altitude", "elevation", "ele", "z"}
+	wktColumnNames = []string{"wkt", "geometry", "geom", "the_geom"}
+)
+
+const defaultWKTColumn = "WKT"
+
+// findColumn returns the index of the first header matching one of names,
+// ignoring case, or -1.
+func findColumn(header []string, names ...string) int {
+	for _, name := range names {
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,229 @@
+package csv
+
+import (
+	stdcsv "encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/wkt"
+)
+
+// Read parses a table with a header row and returns one Feature per
+// remaining row. If opts is nil, the zero Options are used.
+//
+// If no geometry columns are configured, Read looks for conventional
+// longitude/latitude column names (e.g., "lon" and "lat") and then for a WKT
+// column (e.g., "wkt" or "geometry"). Rows with empty geometry fields produce
+// Features without geometry.
+func Read(r io.Reader, opts *Options) (*geojson.FeatureCollection, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	cr := stdcsv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("csv: missing header")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("csv: %v", err)
+	}
+	// Spreadsheets commonly write a byte order mark before UTF-8 text.
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	l, err := newLayout(header, opts)
+	if err != nil {
+		return nil, fmt.Errorf("csv: %v", err)
+	}
+
+	fc := &geojson.FeatureCollection{}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv: %v", err)
+		}
+		f, err := l.feature(record, opts.InferTypes)
+		if err != nil {
+			// Account for the header row and 1-based line numbers.
+			return nil, fmt.Errorf("csv: row %d: %v", len(fc.Features)+2, err)
+		}
+		fc.Features = append(fc.Features, *f)
+	}
+	return fc, nil
+}
+
+// layout records which column indices hold what. Unused indices are -1.
+type layout struct {
+	header []string
+	x      int
+	y      int
+	z      int
+	wkt    int
+	id     int
+}
+
+func newLayout(header []string, opts *Options) (*layout, error) {
+	l := &layout{header: header, x: -1, y: -1, z: -1, wkt: -1, id: -1}
+	required := func(name string) (int, error) {
+		i := findColumn(header, name)
+		if i < 0 {
+			return -1, fmt.Errorf("missing column %q", name)
+		}
+		return i, nil
+	}
+
+	var err error
+	switch {
+	case opts.XColumn != "" || opts.YColumn != "":
+		if opts.XColumn == "" || opts.YColumn == "" {
+			return nil, fmt.Errorf("XColumn and YColumn must be set together")
+		}
+		if l.x, err = required(opts.XColumn); err != nil {
+			return nil, err
+		}
+		if l.y, err = required(opts.YColumn); err != nil {
+			return nil, err
+		}
+		if opts.ZColumn != "" {
+			if l.z, err = required(opts.ZColumn); err != nil {
+				return nil, err
+			}
+		}
+	case opts.WKTColumn != "":
+		if l.wkt, err = required(opts.WKTColumn); err != nil {
+			return nil, err
+		}
+	default:
+		l.x = findColumn(header, xColumnNames...)
+		l.y = findColumn(header, yColumnNames...)
+		if l.x >= 0 && l.y >= 0 {
+			l.z = findColumn(header, zColumnNames...)
+		} else {
+			l.x, l.y = -1, -1
+			l.wkt = findColumn(header, wktColumnNames...)
+		}
+		if l.x < 0 && l.wkt < 0 {
+			return nil, fmt.Errorf("no geometry columns found in header %q", header)
+		}
+	}
+	if opts.IDColumn != "" {
+		if l.id, err = required(opts.IDColumn); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (l *layout) feature(record []string, inferTypes bool) (*geojson.Feature, error) {
+	f := &geojson.Feature{Properties: map[string]interface{}{}}
+	for i, value := range record {
+		switch i {
+		case l.x, l.y, l.z, l.wkt:
+			continue
+		case l.id:
+			f.ID = value
+			continue
+		}
+		name := fmt.Sprintf("column%d", i)
+		if i < len(l.header) {
+			name = l.header[i]
+		}
+		if inferTypes {
+			f.Properties[name] = inferType(value)
+		} else {
+			f.Properties[name] = value
+		}
+	}
+
+	if l.wkt >= 0 {
+		s := strings.TrimSpace(record[l.wkt])
+		if s == "" {
+			return f, nil
+		}
+		g, err := wkt.Unmarshal(s)
+		if err != nil {
+			return nil, err
+		}
+		f.Geometry = g
+		return f, nil
+	}
+
+	xs := strings.TrimSpace(record[l.x])
+	ys := strings.TrimSpace(record[l.y])
+	if xs == "" && ys == "" {
+		return f, nil
+	}
+	p := &geojson.Point{}
+	var err error
+	if p.X, err = strconv.ParseFloat(xs, 64); err != nil {
+		return nil, fmt.Errorf("column %q: %v", l.header[l.x], err)
+	}
+	if p.Y, err = strconv.ParseFloat(ys, 64); err != nil {
+		return nil, fmt.Errorf("column %q: %v", l.header[l.y], err)
+	}
+	if l.z >= 0 {
+		if zs := strings.TrimSpace(record[l.z]); zs != "" {
+			if p.Elevation, err = strconv.ParseFloat(zs, 64); err != nil {
+				return nil, fmt.Errorf("column %q: %v", l.header[l.z], err)
+			}
+			p.HasElevation = true
+		}
+	}
+	f.Geometry = p
+	return f, nil
+}
+
+// inferType converts s to the JSON-compatible value it most plausibly
+// represents: nil for empty strings, bool, float64, or otherwise the original
+// string.
+func inferType(s string) interface{} {
+	t := strings.TrimSpace(s)
+	if t == "" {
+		return nil
+	}
+	switch strings.ToLower(t) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if !looksNumeric(t) {
+		return s
+	}
+	f, err := strconv.ParseFloat(t, 64)
+	if err != nil {
+		return s
+	}
+	if !strings.ContainsAny(t, ".eE") {
+		// Integers beyond 2^53 do not survive the conversion, which would
+		// silently change IDs and account numbers.
+		n, err := strconv.ParseInt(t, 10, 64)
+		if err != nil || n > 1<<53 || n < -1<<53 {
+			return s
+		}
+	}
+	return f
+}
+
+// looksNumeric reports whether t is written the way a spreadsheet would
+// write a number. A leading "+" or a leading zero other than in "0" or
+// "0.5" marks codes such as phone numbers and postcodes, which must stay
+// text. "NaN", "Inf" and hex floats, which ParseFloat accepts, are rejected
+// too.
+func looksNumeric(t string) bool {
+	if strings.ContainsAny(strings.ToLower(t), "nix") {
+		return false
+	}
+	digits := strings.TrimPrefix(t, "-")
+	if digits == "" || digits[0] == '+' {
+		return false
+	}
+	return !(len(digits) > 1 && digits[0] == '0' && digits[1] != '.')
+}
@@ -0,0 +1,159 @@
+package csv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestRead(t *testing.T) {
+	cases := []struct {
+		s        string
+		opts     *Options
+		expected *geojson.FeatureCollection
+	}{
+		{
+			s: "name,Lat,Lon\nA,1.5,2.5\nB,,\n",
+			expected: &geojson.FeatureCollection{
+				Features: []geojson.Feature{
+					{
+						Geometry:   &geojson.Point{X: 2.5, Y: 1.5},
+						Properties: map[string]interface{}{"name": "A"},
+					},
+					{
+						Properties: map[string]interface{}{"name": "B"},
+					},
+				},
+			},
+		},
+		{
+			s:    "id;e;n;h;count;ok;note\n7;10;20;30;3;true;\n",
+			opts: &Options{XColumn: "e", YColumn: "n", ZColumn: "h", IDColumn: "id", Comma: ';', InferTypes: true},
+			expected: &geojson.FeatureCollection{
+				Features: []geojson.Feature{
+					{
+						ID:       "7",
+						Geometry: &geojson.Point{X: 10, Y: 20, Elevation: 30, HasElevation: true},
+						Properties: map[string]interface{}{
+							"count": float64(3),
+							"ok":    true,
+							"note":  nil,
+						},
+					},
+				},
+			},
+		},
+		{
+			s:    "WKT,code\n\"LINESTRING (0 0, 1 1)\",NaN\n",
+			opts: &Options{InferTypes: true},
+			expected: &geojson.FeatureCollection{
+				Features: []geojson.Feature{
+					{
+						Geometry: &geojson.LineString{
+							Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}},
+						},
+						Properties: map[string]interface{}{"code": "NaN"},
+					},
+				},
+			},
+		},
+		{
+			s: "\ufefflat,lon,name\n1,2,A\n",
+			expected: &geojson.FeatureCollection{
+				Features: []geojson.Feature{
+					{
+						Geometry:   &geojson.Point{X: 2, Y: 1},
+						Properties: map[string]interface{}{"name": "A"},
+					},
+				},
+			},
+		},
+		{
+			s:    "shape,n\n\"POINT (1 2)\",5\n",
+			opts: &Options{WKTColumn: "shape"},
+			expected: &geojson.FeatureCollection{
+				Features: []geojson.Feature{
+					{
+						Geometry:   &geojson.Point{X: 1, Y: 2},
+						Properties: map[string]interface{}{"n": "5"},
+					},
+				},
+			},
+		},
+	}
+	for i, c := range cases {
+		fc, err := Read(strings.NewReader(c.s), c.opts)
+		if err != nil {
+			t.Errorf("error reading case %d: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(fc, c.expected) {
+			t.Errorf("case %d failed: expected %#v, got %#v", i, c.expected, fc)
+		}
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	cases := []struct {
+		s    string
+		opts *Options
+	}{
+		{s: ""},
+		{s: "a,b\n1,2\n"},
+		{s: "lat,lon\nx,1\n"},
+		{s: "wkt\nPOINT (1)\n"},
+		{s: "a,b\n1,2\n", opts: &Options{XColumn: "a"}},
+		{s: "a,b\n1,2\n", opts: &Options{XColumn: "a", YColumn: "c"}},
+		{s: "lat,lon\n1,2,3\n"},
+	}
+	for i, c := range cases {
+		_, err := Read(strings.NewReader(c.s), c.opts)
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestInferType(t *testing.T) {
+	cases := []struct {
+		s        string
+		expected interface{}
+	}{
+		{"", nil},
+		{" ", nil},
+		{"TRUE", true},
+		{"false", false},
+		{"3", float64(3)},
+		{" 3 ", float64(3)},
+		{"-2.5", -2.5},
+		{"1e3", float64(1000)},
+		{"0", float64(0)},
+		{"0.5", 0.5},
+		{"-0.5", -0.5},
+		{"9007199254740992", float64(1 << 53)},
+		{"-9007199254740992", float64(-1 << 53)},
+		{"1e20", 1e20},
+		// Values that are meant as text.
+		{"NaN", "NaN"},
+		{"Inf", "Inf"},
+		{"0x10", "0x10"},
+		{"007", "007"},
+		{"-07", "-07"},
+		{"00.5", "00.5"},
+		{"02134", "02134"},
+		{"+5", "+5"},
+		{"+441234567890", "+441234567890"},
+		{"-", "-"},
+		// Integers that do not fit in a float64.
+		{"9007199254740993", "9007199254740993"},
+		{"-9007199254740993", "-9007199254740993"},
+		{"12345678901234567890", "12345678901234567890"},
+	}
+	for _, c := range cases {
+		if actual := inferType(c.s); actual != c.expected {
+			t.Errorf("%q: expected %#v, got %#v", c.s, c.expected, actual)
+		}
+	}
+}
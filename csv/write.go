@@ -0,0 +1,156 @@
+package csv
+
+import (
+	stdcsv "encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/wkt"
+)
+
+// Write writes fc as a table with a header row. If opts is nil, the zero
+// Options are used.
+//
+// If XColumn and YColumn are set, every geometry must be a Point; otherwise
+// geometries are written as WKT to WKTColumn (or "WKT" if unset). Feature IDs
+// are written to IDColumn, or dropped if it is unset. Properties become the
+// remaining columns, sorted by name. String properties are written
+// verbatim, nil and missing properties as empty fields, and any other values
+// as JSON.
+func Write(w io.Writer, fc *geojson.FeatureCollection, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	var header []string
+	points := opts.XColumn != "" || opts.YColumn != ""
+	if points {
+		if opts.XColumn == "" || opts.YColumn == "" {
+			return fmt.Errorf("csv: XColumn and YColumn must be set together")
+		}
+		header = append(header, opts.XColumn, opts.YColumn)
+		if opts.ZColumn != "" {
+			header = append(header, opts.ZColumn)
+		}
+	} else {
+		name := opts.WKTColumn
+		if name == "" {
+			name = defaultWKTColumn
+		}
+		header = append(header, name)
+	}
+	if opts.IDColumn != "" {
+		header = append(header, opts.IDColumn)
+	}
+	reserved := map[string]bool{}
+	for _, h := range header {
+		reserved[h] = true
+	}
+	keySet := map[string]bool{}
+	for _, f := range fc.Features {
+		for k := range f.Properties {
+			if reserved[k] {
+				return fmt.Errorf("csv: property %q conflicts with a geometry or ID column", k)
+			}
+			keySet[k] = true
+		}
+	}
+	keys := make([]string, 0, len(keySet))
+	for k := range keySet {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	header = append(header, keys...)
+
+	cw := stdcsv.NewWriter(w)
+	if opts.Comma != 0 {
+		cw.Comma = opts.Comma
+	}
+	err := cw.Write(header)
+	if err != nil {
+		return fmt.Errorf("csv: %v", err)
+	}
+	for i := range fc.Features {
+		record, err := row(&fc.Features[i], opts, points, keys)
+		if err != nil {
+			return fmt.Errorf("csv: feature %d: %v", i, err)
+		}
+		err = cw.Write(record)
+		if err != nil {
+			return fmt.Errorf("csv: %v", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("csv: %v", err)
+	}
+	return nil
+}
+
+func row(f *geojson.Feature, opts *Options, points bool, keys []string) ([]string, error) {
+	var record []string
+	if points {
+		var x, y, z string
+		switch t := f.Geometry.(type) {
+		case nil:
+		case *geojson.Point:
+			x = formatFloat(t.X)
+			y = formatFloat(t.Y)
+			if t.HasElevation {
+				z = formatFloat(t.Elevation)
+			}
+		default:
+			return nil, fmt.Errorf("coordinate columns require Point geometry, got %T", t)
+		}
+		record = append(record, x, y)
+		if opts.ZColumn != "" {
+			record = append(record, z)
+		}
+	} else {
+		s := ""
+		if f.Geometry != nil {
+			var err error
+			s, err = wkt.Marshal(f.Geometry)
+			if err != nil {
+				return nil, err
+			}
+		}
+		record = append(record, s)
+	}
+	if opts.IDColumn != "" {
+		record = append(record, f.ID)
+	}
+	for _, k := range keys {
+		s, err := formatValue(f.Properties[k])
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %v", k, err)
+		}
+		record = append(record, s)
+	}
+	return record, nil
+}
+
+func formatValue(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	case float64:
+		return formatFloat(t), nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,97 @@
+package csv
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestWrite(t *testing.T) {
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				ID:       "1",
+				Geometry: &geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+				Properties: map[string]interface{}{
+					"name":  "a, b",
+					"count": float64(2),
+				},
+			},
+			{
+				Properties: map[string]interface{}{
+					"tags": []interface{}{"x"},
+				},
+			},
+		},
+	}
+	cases := []struct {
+		opts     *Options
+		expected string
+	}{
+		{
+			expected: "WKT,count,name,tags\n" +
+				"POINT Z (1 2 3),2,\"a, b\",\n" +
+				",,,\"[\"\"x\"\"]\"\n",
+		},
+		{
+			opts: &Options{XColumn: "lon", YColumn: "lat", ZColumn: "alt", IDColumn: "id"},
+			expected: "lon,lat,alt,id,count,name,tags\n" +
+				"1,2,3,1,2,\"a, b\",\n" +
+				",,,,,,\"[\"\"x\"\"]\"\n",
+		},
+	}
+	for i, c := range cases {
+		var buf bytes.Buffer
+		err := Write(&buf, fc, c.opts)
+		if err != nil {
+			t.Errorf("error writing case %d: %v", i, err)
+			continue
+		}
+		if buf.String() != c.expected {
+			t.Errorf("case %d failed: expected %q, got %q", i, c.expected, buf.String())
+		}
+	}
+}
+
+func TestRoundTrip_Write(t *testing.T) {
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				Geometry: &geojson.Polygon{
+					Rings: []geojson.LineString{
+						{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0}}},
+					},
+				},
+				Properties: map[string]interface{}{"area": 0.5, "valid": true},
+			},
+		},
+	}
+	opts := &Options{InferTypes: true}
+	var buf bytes.Buffer
+	err := Write(&buf, fc, opts)
+	if err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	read, err := Read(&buf, opts)
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if !reflect.DeepEqual(read, fc) {
+		t.Errorf("round trip failed: expected %#v, got %#v", fc, read)
+	}
+}
+
+func TestWrite_NonPoint(t *testing.T) {
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{Geometry: &geojson.MultiPoint{}},
+		},
+	}
+	var buf bytes.Buffer
+	err := Write(&buf, fc, &Options{XColumn: "x", YColumn: "y"})
+	if err == nil {
+		t.Errorf("expected error")
+	}
+}
@@ -0,0 +1,182 @@
+// Package esrijson converts between GeoJSON and Esri JSON, the format used by
+// ArcGIS REST services such as FeatureServer query responses.
+//
+// The types in this package mirror Esri JSON and can be used directly with
+// encoding/json. Conversions to the geojson types produce WGS84 coordinates:
+// input in Web Mercator (WKID 3857 or its older aliases) is unprojected, and
+// other spatial references are rejected. Ask the service for WGS84 with
+// outSR=4326 when possible.
+//
+// Esri polygons are lists of rings in which outer rings are clockwise and
+// holes are counter-clockwise. Holes are assigned to the outer rings that
+// contain them, and all rings are rewound to match RFC 7946.
+//
+// See https://developers.arcgis.com/documentation/common-data-types/geometry-objects.htm
+// for the format definition.
+package esrijson
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Geometry types as named in feature sets.
+const (
+	GeometryPoint      = "esriGeometryPoint"
+	GeometryMultipoint = "esriGeometryMultipoint"
+	GeometryPolyline   = "esriGeometryPolyline"
+	GeometryPolygon    = "esriGeometryPolygon"
+	GeometryEnvelope   = "esriGeometryEnvelope"
+)
+
+// Field types used when writing feature sets.
+const (
+	FieldTypeOID     = "esriFieldTypeOID"
+	FieldTypeString  = "esriFieldTypeString"
+	FieldTypeInteger = "esriFieldTypeInteger"
+	FieldTypeDouble  = "esriFieldTypeDouble"
+)
+
+// WKID of WGS84, which is the spatial reference of all GeoJSON coordinates.
+const WGS84 = 4326
+
+// A SpatialReference identifies a coordinate system by well-known ID or
+// well-known text.
+type SpatialReference struct {
+	WKID       int    `json:"wkid,omitempty"`
+	LatestWKID int    `json:"latestWkid,omitempty"`
+	WKT        string `json:"wkt,omitempty"`
+}
+
+// A Geometry is any Esri JSON geometry. Which fields are set determines its
+// type: X and Y for points, Points for multipoints, Paths for polylines,
+// Rings for polygons, and the bounds for envelopes.
+type Geometry struct {
+	X *float64 `json:"x,omitempty"`
+	Y *float64 `json:"y,omitempty"`
+	Z *float64 `json:"z,omitempty"`
+	M *float64 `json:"m,omitempty"`
+
+	Points [][]float64   `json:"points,omitempty"`
+	Paths  [][][]float64 `json:"paths,omitempty"`
+	Rings  [][][]float64 `json:"rings,omitempty"`
+
+	XMin *float64 `json:"xmin,omitempty"`
+	YMin *float64 `json:"ymin,omitempty"`
+	XMax *float64 `json:"xmax,omitempty"`
+	YMax *float64 `json:"ymax,omitempty"`
+
+	// Whether the positions in Points, Paths, or Rings have Z or M values,
+	// which follow X and Y in that order.
+	HasZ bool `json:"hasZ,omitempty"`
+	HasM bool `json:"hasM,omitempty"`
+
+	SpatialReference *SpatialReference `json:"spatialReference,omitempty"`
+}
+
+// UnmarshalJSON decodes g, reading the coordinates of a point that are the
+// string "NaN" as null. ArcGIS servers write empty points as
+// {"x":"NaN","y":null}.
+func (g *Geometry) UnmarshalJSON(b []byte) error {
+	type geometry Geometry
+	v := struct {
+		*geometry
+		X nanNumber `json:"x"`
+		Y nanNumber `json:"y"`
+		Z nanNumber `json:"z"`
+		M nanNumber `json:"m"`
+	}{geometry: (*geometry)(g)}
+	err := json.Unmarshal(b, &v)
+	if err != nil {
+		return err
+	}
+	g.X, g.Y, g.Z, g.M = v.X.v, v.Y.v, v.Z.v, v.M.v
+	return nil
+}
+
+// A nanNumber is a number that may also be null or the string "NaN", both
+// of which leave v nil.
+type nanNumber struct {
+	v *float64
+}
+
+func (n *nanNumber) UnmarshalJSON(b []byte) error {
+	switch string(b) {
+	case "null", `"NaN"`:
+		n.v = nil
+		return nil
+	}
+	var f float64
+	err := json.Unmarshal(b, &f)
+	if err != nil {
+		return err
+	}
+	n.v = &f
+	return nil
+}
+
+// A Feature is a geometry with attributes.
+type Feature struct {
+	Geometry   *Geometry              `json:"geometry,omitempty"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// A Field describes an attribute of the features in a FeatureSet.
+type Field struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Alias  string `json:"alias,omitempty"`
+	Length int    `json:"length,omitempty"`
+}
+
+// A FeatureSet is a collection of features of the same geometry type, such
+// as the response to a FeatureServer query.
+type FeatureSet struct {
+	ObjectIDFieldName     string            `json:"objectIdFieldName,omitempty"`
+	GeometryType          string            `json:"geometryType,omitempty"`
+	SpatialReference      *SpatialReference `json:"spatialReference,omitempty"`
+	Fields                []Field           `json:"fields,omitempty"`
+	Features              []Feature         `json:"features"`
+	ExceededTransferLimit bool              `json:"exceededTransferLimit,omitempty"`
+}
+
+// A projection converts coordinates in some spatial reference to WGS84.
+type projection func(x, y float64) (float64, float64)
+
+// projectionFor returns the conversion from sr to WGS84. A nil spatial
+// reference is assumed to be WGS84.
+func projectionFor(sr *SpatialReference) (projection, error) {
+	if sr == nil {
+		return identity, nil
+	}
+	wkid := sr.LatestWKID
+	if wkid == 0 {
+		wkid = sr.WKID
+	}
+	switch wkid {
+	case WGS84:
+		return identity, nil
+	case 3857, 102100, 102113, 900913:
+		return unprojectWebMercator, nil
+	case 0:
+		if sr.WKT == "" {
+			return identity, nil
+		}
+		return nil, fmt.Errorf("unsupported spatial reference: %s", sr.WKT)
+	}
+	return nil, fmt.Errorf("unsupported spatial reference: WKID %d", wkid)
+}
+
+func identity(x, y float64) (float64, float64) {
+	return x, y
+}
+
+// Radius of the sphere used by Web Mercator.
+const earthRadius = 6378137
+
+func unprojectWebMercator(x, y float64) (float64, float64) {
+	lon := x / earthRadius * 180 / math.Pi
+	lat := (2*math.Atan(math.Exp(y/earthRadius)) - math.Pi/2) * 180 / math.Pi
+	return lon, lat
+}
@@ -0,0 +1,277 @@
+package esrijson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/bsidhom/geojson"
+)
+
+// objectIDField is the name of the object ID field written by
+// FromFeatureCollection.
+const objectIDField = "OBJECTID"
+
+// Decode reads a feature set, such as the response to a FeatureServer query
+// with f=json, and converts it with ToFeatureCollection. ArcGIS error
+// responses are reported as errors.
+func Decode(r io.Reader) (*geojson.FeatureCollection, error) {
+	var resp struct {
+		FeatureSet
+		Error *struct {
+			Code    int      `json:"code"`
+			Message string   `json:"message"`
+			Details []string `json:"details"`
+		} `json:"error"`
+	}
+	err := json.NewDecoder(r).Decode(&resp)
+	if err != nil {
+		return nil, fmt.Errorf("esrijson: %v", err)
+	}
+	if e := resp.Error; e != nil {
+		return nil, fmt.Errorf("esrijson: service error %d: %s %v", e.Code, e.Message, e.Details)
+	}
+	return ToFeatureCollection(&resp.FeatureSet)
+}
+
+// Encode converts fc with FromFeatureCollection and writes it as JSON.
+func Encode(w io.Writer, fc *geojson.FeatureCollection) error {
+	fs, err := FromFeatureCollection(fc)
+	if err != nil {
+		return err
+	}
+	err = json.NewEncoder(w).Encode(fs)
+	if err != nil {
+		return fmt.Errorf("esrijson: %v", err)
+	}
+	return nil
+}
+
+// ToFeature converts f, using its attributes as properties.
+func ToFeature(f *Feature) (*geojson.Feature, error) {
+	result, err := toFeature(f, "", identity, "")
+	if err != nil {
+		return nil, fmt.Errorf("esrijson: %v", err)
+	}
+	return result, nil
+}
+
+// toFeature converts f, moving the object ID attribute, if any, to the
+// feature ID.
+func toFeature(f *Feature, geometryType string, proj projection, oidField string) (*geojson.Feature, error) {
+	result := &geojson.Feature{Properties: map[string]interface{}{}}
+	if f.Geometry != nil {
+		g, err := toGeometry(f.Geometry, geometryType, proj)
+		if err != nil {
+			return nil, err
+		}
+		result.Geometry = g
+	}
+	for k, v := range f.Attributes {
+		if k == oidField && v != nil {
+			result.ID = formatID(v)
+			continue
+		}
+		result.Properties[k] = v
+	}
+	return result, nil
+}
+
+func formatID(v interface{}) string {
+	switch t := v.(type) {
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case string:
+		return t
+	}
+	return fmt.Sprint(v)
+}
+
+// ToFeatureCollection converts fs. The object ID attribute, named by
+// ObjectIDFieldName or by the field of type esriFieldTypeOID, becomes the
+// feature ID rather than a property.
+func ToFeatureCollection(fs *FeatureSet) (*geojson.FeatureCollection, error) {
+	proj, err := projectionFor(fs.SpatialReference)
+	if err != nil {
+		return nil, fmt.Errorf("esrijson: %v", err)
+	}
+	oidField := fs.ObjectIDFieldName
+	if oidField == "" {
+		for _, field := range fs.Fields {
+			if field.Type == FieldTypeOID {
+				oidField = field.Name
+				break
+			}
+		}
+	}
+	fc := &geojson.FeatureCollection{Features: make([]geojson.Feature, len(fs.Features))}
+	for i := range fs.Features {
+		f, err := toFeature(&fs.Features[i], fs.GeometryType, proj, oidField)
+		if err != nil {
+			return nil, fmt.Errorf("esrijson: feature %d: %v", i, err)
+		}
+		fc.Features[i] = *f
+	}
+	return fc, nil
+}
+
+// FromFeature converts f. The feature ID is not written, since Esri features
+// carry IDs only as attributes described by a feature set. Property values
+// that are neither strings, numbers, nor booleans are written as JSON
+// strings.
+func FromFeature(f *geojson.Feature) (*Feature, error) {
+	result, err := fromFeature(f)
+	if err != nil {
+		return nil, fmt.Errorf("esrijson: %v", err)
+	}
+	if result.Geometry != nil {
+		result.Geometry.SpatialReference = &SpatialReference{WKID: WGS84}
+	}
+	return result, nil
+}
+
+func fromFeature(f *geojson.Feature) (*Feature, error) {
+	result := &Feature{Attributes: map[string]interface{}{}}
+	if f.Geometry != nil {
+		g, err := fromGeometry(f.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		result.Geometry = g
+	}
+	for k, v := range f.Properties {
+		switch v.(type) {
+		case nil, string, float64, bool:
+			result.Attributes[k] = v
+		default:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("property %q: %v", k, err)
+			}
+			result.Attributes[k] = string(b)
+		}
+	}
+	return result, nil
+}
+
+// FromFeatureCollection converts fc to a feature set in WGS84. All features
+// must have the same geometry type, counting LineStrings with
+// MultiLineStrings and Polygons with MultiPolygons.
+//
+// If every feature has an integer ID and no feature has an OBJECTID
+// property, the IDs are written as an OBJECTID attribute and declared as the
+// object ID field. Otherwise IDs are dropped. Fields are declared for
+// properties whose values are all strings, all integers, or all numbers.
+func FromFeatureCollection(fc *geojson.FeatureCollection) (*FeatureSet, error) {
+	fs := &FeatureSet{
+		SpatialReference: &SpatialReference{WKID: WGS84},
+		Features:         make([]Feature, len(fc.Features)),
+	}
+	for i := range fc.Features {
+		f, err := fromFeature(&fc.Features[i])
+		if err != nil {
+			return nil, fmt.Errorf("esrijson: feature %d: %v", i, err)
+		}
+		if f.Geometry != nil {
+			t := geometryType(f.Geometry)
+			if fs.GeometryType != "" && fs.GeometryType != t {
+				return nil, fmt.Errorf("esrijson: feature %d: mixed geometry types %s and %s", i, fs.GeometryType, t)
+			}
+			fs.GeometryType = t
+		}
+		fs.Features[i] = *f
+	}
+
+	ids, ok := objectIDs(fc)
+	if ok {
+		fs.ObjectIDFieldName = objectIDField
+		fs.Fields = append(fs.Fields, Field{Name: objectIDField, Type: FieldTypeOID})
+		for i := range fs.Features {
+			fs.Features[i].Attributes[objectIDField] = ids[i]
+		}
+	}
+	fs.Fields = append(fs.Fields, inferFields(fc)...)
+	return fs, nil
+}
+
+// objectIDs returns the feature IDs as integers if they can all be written
+// as object IDs.
+func objectIDs(fc *geojson.FeatureCollection) ([]int64, bool) {
+	if len(fc.Features) == 0 {
+		return nil, false
+	}
+	ids := make([]int64, len(fc.Features))
+	for i, f := range fc.Features {
+		if _, ok := f.Properties[objectIDField]; ok {
+			return nil, false
+		}
+		id, err := strconv.ParseInt(f.ID, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		ids[i] = id
+	}
+	return ids, true
+}
+
+func inferFields(fc *geojson.FeatureCollection) []Field {
+	types := map[string]string{}
+	lengths := map[string]int{}
+	for _, f := range fc.Features {
+		for k, v := range f.Properties {
+			var t string
+			switch v := v.(type) {
+			case nil:
+				continue
+			case string:
+				t = FieldTypeString
+				if n := utf8.RuneCountInString(v); n > lengths[k] {
+					lengths[k] = n
+				}
+			case float64:
+				t = FieldTypeDouble
+				if v == math.Trunc(v) && math.Abs(v) < 1<<31 {
+					t = FieldTypeInteger
+				}
+			default:
+				// Not describable by a single field type.
+				t = "-"
+			}
+			prev, seen := types[k]
+			switch {
+			case !seen || prev == t:
+				types[k] = t
+			case isNumeric(prev) && isNumeric(t):
+				types[k] = FieldTypeDouble
+			default:
+				types[k] = "-"
+			}
+		}
+	}
+	names := make([]string, 0, len(types))
+	for k, t := range types {
+		if t != "-" {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	fields := make([]Field, len(names))
+	for i, k := range names {
+		fields[i] = Field{Name: k, Type: types[k]}
+		if types[k] == FieldTypeString {
+			fields[i].Length = lengths[k]
+			if fields[i].Length == 0 {
+				fields[i].Length = 1
+			}
+		}
+	}
+	return fields
+}
+
+func isNumeric(fieldType string) bool {
+	return fieldType == FieldTypeInteger || fieldType == FieldTypeDouble
+}
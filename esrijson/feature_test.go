@@ -0,0 +1,153 @@
+package esrijson
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestDecode(t *testing.T) {
+	s := `{
+  "objectIdFieldName": "FID",
+  "geometryType": "esriGeometryPolygon",
+  "spatialReference": {"wkid": 4326, "latestWkid": 4326},
+  "fields": [
+    {"name": "FID", "type": "esriFieldTypeOID", "alias": "FID"},
+    {"name": "NAME", "type": "esriFieldTypeString", "alias": "Name", "length": 50}
+  ],
+  "features": [
+    {
+      "attributes": {"FID": 1, "NAME": "square"},
+      "geometry": {"rings": [[[0,0],[0,1],[1,1],[1,0],[0,0]]]}
+    },
+    {
+      "attributes": {"FID": 2, "NAME": null},
+      "geometry": {}
+    },
+    {
+      "attributes": {"FID": 3, "NAME": "none"}
+    }
+  ],
+  "exceededTransferLimit": true
+}`
+	got, err := Decode(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	expected := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				Geometry: &geojson.Polygon{Rings: []geojson.LineString{
+					{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}, {X: 0, Y: 0}}},
+				}},
+				Properties: map[string]interface{}{"NAME": "square"},
+				ID:         "1",
+			},
+			{
+				Geometry:   &geojson.MultiPolygon{},
+				Properties: map[string]interface{}{"NAME": nil},
+				ID:         "2",
+			},
+			{
+				Properties: map[string]interface{}{"NAME": "none"},
+				ID:         "3",
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %#v, got %#v", expected, got)
+	}
+}
+
+func TestDecode_Error(t *testing.T) {
+	s := `{"error":{"code":400,"message":"Invalid query parameters.","details":["'where' parameter is invalid"]}}`
+	_, err := Decode(strings.NewReader(s))
+	if err == nil || !strings.Contains(err.Error(), "Invalid query parameters.") {
+		t.Errorf("expected service error, got %v", err)
+	}
+}
+
+func TestRoundTrip_Encode(t *testing.T) {
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				Geometry:   &geojson.LineString{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+				Properties: map[string]interface{}{"name": "a", "lanes": float64(2), "speed": 50.5},
+				ID:         "10",
+			},
+			{
+				Geometry: &geojson.MultiLineString{Lines: []geojson.LineString{
+					{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+					{Points: []geojson.Point{{X: 2, Y: 2}, {X: 3, Y: 3}}},
+				}},
+				Properties: map[string]interface{}{"name": "bb", "lanes": float64(4), "speed": float64(30)},
+				ID:         "11",
+			},
+		},
+	}
+	var buf bytes.Buffer
+	err := Encode(&buf, fc)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	got, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if !reflect.DeepEqual(got, fc) {
+		t.Errorf("expected %#v, got %#v", fc, got)
+	}
+}
+
+func TestFromFeatureCollection(t *testing.T) {
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				Geometry:   &geojson.Point{X: 1, Y: 2},
+				Properties: map[string]interface{}{"name": "a", "count": float64(1), "tags": []interface{}{"x"}},
+				ID:         "a",
+			},
+			{
+				Geometry:   &geojson.Point{X: 3, Y: 4},
+				Properties: map[string]interface{}{"name": "bcd", "count": 1.5, "ok": true},
+			},
+		},
+	}
+	fs, err := FromFeatureCollection(fc)
+	if err != nil {
+		t.Fatalf("failed to convert: %v", err)
+	}
+	if fs.GeometryType != GeometryPoint || fs.ObjectIDFieldName != "" {
+		t.Errorf("unexpected feature set header %#v", fs)
+	}
+	expectedFields := []Field{
+		{Name: "count", Type: FieldTypeDouble},
+		{Name: "name", Type: FieldTypeString, Length: 3},
+	}
+	if !reflect.DeepEqual(fs.Fields, expectedFields) {
+		t.Errorf("expected fields %#v, got %#v", expectedFields, fs.Fields)
+	}
+	if tags := fs.Features[0].Attributes["tags"]; tags != `["x"]` {
+		t.Errorf("expected tags as JSON, got %#v", tags)
+	}
+
+	fc.Features[1].Geometry = &geojson.MultiPoint{Points: []geojson.Point{{X: 3, Y: 4}}}
+	_, err = FromFeatureCollection(fc)
+	if err == nil {
+		t.Errorf("expected error for mixed geometry types")
+	}
+}
+
+func TestDecode_EmptyPoint(t *testing.T) {
+	s := `{"geometryType":"esriGeometryPoint","features":[{"attributes":{},"geometry":{"x":"NaN","y":null}}]}`
+	got, err := Decode(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if g := got.Features[0].Geometry; g != nil {
+		t.Errorf("expected no geometry, got %#v", g)
+	}
+}
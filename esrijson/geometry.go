@@ -0,0 +1,231 @@
+package esrijson
+
+import (
+	"fmt"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/ring"
+)
+
+// ToGeometry converts g to a GeoJSON geometry in WGS84. Points become Points,
+// multipoints become MultiPoints, polylines become LineStrings or
+// MultiLineStrings, and polygons and envelopes become Polygons or
+// MultiPolygons. A geometry without coordinates, such as an empty point
+// whose X is null, converts to nil.
+func ToGeometry(g *Geometry) (geojson.Geometry, error) {
+	proj, err := projectionFor(g.SpatialReference)
+	if err != nil {
+		return nil, fmt.Errorf("esrijson: %v", err)
+	}
+	result, err := toGeometry(g, "", proj)
+	if err != nil {
+		return nil, fmt.Errorf("esrijson: %v", err)
+	}
+	return result, nil
+}
+
+// toGeometry converts g using proj unless g has its own spatial reference.
+// The geometry type, if known, resolves geometries whose lists are empty and
+// therefore omitted.
+func toGeometry(g *Geometry, geometryType string, proj projection) (geojson.Geometry, error) {
+	if g.SpatialReference != nil {
+		var err error
+		proj, err = projectionFor(g.SpatialReference)
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case g.Points != nil:
+		geometryType = GeometryMultipoint
+	case g.Paths != nil:
+		geometryType = GeometryPolyline
+	case g.Rings != nil:
+		geometryType = GeometryPolygon
+	case g.XMin != nil:
+		geometryType = GeometryEnvelope
+	case g.X != nil || g.Y != nil:
+		geometryType = GeometryPoint
+	}
+
+	c := &converter{proj: proj, hasZ: g.HasZ, hasM: g.HasM}
+	switch geometryType {
+	case GeometryPoint, "":
+		if g.X == nil || g.Y == nil {
+			return nil, nil
+		}
+		x, y := proj(*g.X, *g.Y)
+		p := &geojson.Point{X: x, Y: y}
+		if g.Z != nil {
+			p.Elevation = *g.Z
+			p.HasElevation = true
+		}
+		return p, nil
+	case GeometryMultipoint:
+		points, err := c.points(g.Points)
+		if err != nil {
+			return nil, err
+		}
+		return &geojson.MultiPoint{Points: points}, nil
+	case GeometryPolyline:
+		lines := make([]geojson.LineString, len(g.Paths))
+		for i, path := range g.Paths {
+			points, err := c.points(path)
+			if err != nil {
+				return nil, fmt.Errorf("path %d: %v", i, err)
+			}
+			if len(points) < 2 {
+				return nil, fmt.Errorf("path %d requires at least 2 points, got %d", i, len(points))
+			}
+			lines[i].Points = points
+		}
+		if len(lines) == 1 {
+			return &lines[0], nil
+		}
+		return &geojson.MultiLineString{Lines: lines}, nil
+	case GeometryPolygon:
+		rings := make([][]geojson.Point, len(g.Rings))
+		for i, r := range g.Rings {
+			points, err := c.points(r)
+			if err != nil {
+				return nil, fmt.Errorf("ring %d: %v", i, err)
+			}
+			rings[i] = points
+		}
+		return ring.Assemble(rings)
+	case GeometryEnvelope:
+		if g.XMin == nil || g.YMin == nil || g.XMax == nil || g.YMax == nil {
+			return nil, fmt.Errorf("incomplete envelope")
+		}
+		x0, y0 := proj(*g.XMin, *g.YMin)
+		x1, y1 := proj(*g.XMax, *g.YMax)
+		return &geojson.Polygon{
+			Rings: []geojson.LineString{{Points: []geojson.Point{
+				{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}, {X: x0, Y: y0},
+			}}},
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported geometry type %q", geometryType)
+}
+
+// A converter converts Esri positions to points.
+type converter struct {
+	proj       projection
+	hasZ, hasM bool
+}
+
+func (c *converter) points(positions [][]float64) ([]geojson.Point, error) {
+	points := make([]geojson.Point, len(positions))
+	for i, pos := range positions {
+		if len(pos) < 2 {
+			return nil, fmt.Errorf("position %d has %d coordinates", i, len(pos))
+		}
+		x, y := c.proj(pos[0], pos[1])
+		points[i] = geojson.Point{X: x, Y: y}
+		// Writers do not always set hasZ, so a third value is taken as Z
+		// unless the positions are known to hold M values instead.
+		if len(pos) >= 3 && (c.hasZ || !c.hasM) {
+			points[i].Elevation = pos[2]
+			points[i].HasElevation = true
+		}
+	}
+	return points, nil
+}
+
+// FromGeometry converts g to Esri JSON in WGS84. LineStrings and
+// MultiLineStrings become polylines, and Polygons and MultiPolygons become
+// polygons with outer rings wound clockwise. Z values are written only if
+// every point has an elevation. GeometryCollections have no Esri equivalent
+// and are rejected.
+func FromGeometry(g geojson.Geometry) (*Geometry, error) {
+	result, err := fromGeometry(g)
+	if err != nil {
+		return nil, fmt.Errorf("esrijson: %v", err)
+	}
+	result.SpatialReference = &SpatialReference{WKID: WGS84}
+	return result, nil
+}
+
+func fromGeometry(g geojson.Geometry) (*Geometry, error) {
+	switch t := g.(type) {
+	case *geojson.Point:
+		x, y := t.X, t.Y
+		result := &Geometry{X: &x, Y: &y}
+		if t.HasElevation {
+			z := t.Elevation
+			result.Z = &z
+		}
+		return result, nil
+	case *geojson.MultiPoint:
+		hasZ := allElevated(t.Points)
+		return &Geometry{Points: positions(t.Points, hasZ), HasZ: hasZ}, nil
+	case *geojson.LineString:
+		return polyline([]geojson.LineString{*t}), nil
+	case *geojson.MultiLineString:
+		return polyline(t.Lines), nil
+	case *geojson.Polygon:
+		return polygon([]geojson.Polygon{*t}), nil
+	case *geojson.MultiPolygon:
+		return polygon(t.Polygons), nil
+	}
+	return nil, fmt.Errorf("unsupported geometry type: %T", g)
+}
+
+func polyline(lines []geojson.LineString) *Geometry {
+	hasZ := len(lines) > 0
+	for _, ls := range lines {
+		hasZ = hasZ && allElevated(ls.Points)
+	}
+	paths := make([][][]float64, len(lines))
+	for i, ls := range lines {
+		paths[i] = positions(ls.Points, hasZ)
+	}
+	return &Geometry{Paths: paths, HasZ: hasZ}
+}
+
+func polygon(polygons []geojson.Polygon) *Geometry {
+	parts := ring.Flatten(polygons)
+	hasZ := len(parts) > 0
+	for _, part := range parts {
+		hasZ = hasZ && allElevated(part)
+	}
+	rings := make([][][]float64, len(parts))
+	for i, part := range parts {
+		rings[i] = positions(part, hasZ)
+	}
+	return &Geometry{Rings: rings, HasZ: hasZ}
+}
+
+func positions(points []geojson.Point, hasZ bool) [][]float64 {
+	result := make([][]float64, len(points))
+	for i, p := range points {
+		if hasZ {
+			result[i] = []float64{p.X, p.Y, p.Elevation}
+		} else {
+			result[i] = []float64{p.X, p.Y}
+		}
+	}
+	return result
+}
+
+func allElevated(points []geojson.Point) bool {
+	for _, p := range points {
+		if !p.HasElevation {
+			return false
+		}
+	}
+	return len(points) > 0
+}
+
+// geometryType returns the feature set geometry type of g.
+func geometryType(g *Geometry) string {
+	switch {
+	case g.Points != nil:
+		return GeometryMultipoint
+	case g.Paths != nil:
+		return GeometryPolyline
+	case g.Rings != nil:
+		return GeometryPolygon
+	}
+	return GeometryPoint
+}
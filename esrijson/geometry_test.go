@@ -0,0 +1,218 @@
+package esrijson
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestToGeometry(t *testing.T) {
+	cases := []struct {
+		s        string
+		expected geojson.Geometry
+	}{
+		{
+			s:        `{"x":1,"y":2}`,
+			expected: &geojson.Point{X: 1, Y: 2},
+		},
+		{
+			s:        `{"x":1,"y":2,"z":3,"m":4,"spatialReference":{"wkid":4326}}`,
+			expected: &geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+		},
+		{
+			s:        `{"x":null,"spatialReference":{"wkid":4326}}`,
+			expected: nil,
+		},
+		{
+			s:        `{"x":"NaN","y":null}`,
+			expected: nil,
+		},
+		{
+			s: `{"hasM":true,"points":[[1,2,5],[3,4,6]]}`,
+			expected: &geojson.MultiPoint{Points: []geojson.Point{
+				{X: 1, Y: 2}, {X: 3, Y: 4},
+			}},
+		},
+		{
+			s: `{"hasZ":true,"hasM":true,"points":[[1,2,5,7]]}`,
+			expected: &geojson.MultiPoint{Points: []geojson.Point{
+				{X: 1, Y: 2, Elevation: 5, HasElevation: true},
+			}},
+		},
+		{
+			s: `{"paths":[[[0,0],[1,1]]]}`,
+			expected: &geojson.LineString{Points: []geojson.Point{
+				{X: 0, Y: 0}, {X: 1, Y: 1},
+			}},
+		},
+		{
+			s: `{"paths":[[[0,0],[1,1]],[[2,2],[3,3]]]}`,
+			expected: &geojson.MultiLineString{Lines: []geojson.LineString{
+				{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+				{Points: []geojson.Point{{X: 2, Y: 2}, {X: 3, Y: 3}}},
+			}},
+		},
+		{
+			// A clockwise outer ring with a counter-clockwise hole.
+			s: `{"rings":[[[0,0],[0,10],[10,10],[10,0],[0,0]],[[2,2],[4,2],[4,4],[2,4],[2,2]]]}`,
+			expected: &geojson.Polygon{Rings: []geojson.LineString{
+				{Points: []geojson.Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}, {X: 0, Y: 0}}},
+				{Points: []geojson.Point{{X: 2, Y: 2}, {X: 2, Y: 4}, {X: 4, Y: 4}, {X: 4, Y: 2}, {X: 2, Y: 2}}},
+			}},
+		},
+		{
+			// Two outer rings, with the hole listed first and belonging to
+			// the second.
+			s: `{"rings":[[[22,2],[24,2],[24,4],[22,4],[22,2]],[[0,0],[0,1],[1,1],[1,0],[0,0]],[[20,0],[20,10],[30,10],[30,0],[20,0]]]}`,
+			expected: &geojson.MultiPolygon{Polygons: []geojson.Polygon{
+				{Rings: []geojson.LineString{
+					{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}, {X: 0, Y: 0}}},
+				}},
+				{Rings: []geojson.LineString{
+					{Points: []geojson.Point{{X: 20, Y: 0}, {X: 30, Y: 0}, {X: 30, Y: 10}, {X: 20, Y: 10}, {X: 20, Y: 0}}},
+					{Points: []geojson.Point{{X: 22, Y: 2}, {X: 22, Y: 4}, {X: 24, Y: 4}, {X: 24, Y: 2}, {X: 22, Y: 2}}},
+				}},
+			}},
+		},
+		{
+			// An island in a lake, with a pond that belongs to the island
+			// rather than the lake's outer ring.
+			s: `{"rings":[[[0,0],[0,10],[10,10],[10,0],[0,0]],[[1,1],[9,1],[9,9],[1,9],[1,1]],[[2,2],[2,8],[8,8],[8,2],[2,2]],[[3,3],[7,3],[7,7],[3,7],[3,3]]]}`,
+			expected: &geojson.MultiPolygon{Polygons: []geojson.Polygon{
+				{Rings: []geojson.LineString{
+					{Points: []geojson.Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}, {X: 0, Y: 0}}},
+					{Points: []geojson.Point{{X: 1, Y: 1}, {X: 1, Y: 9}, {X: 9, Y: 9}, {X: 9, Y: 1}, {X: 1, Y: 1}}},
+				}},
+				{Rings: []geojson.LineString{
+					{Points: []geojson.Point{{X: 2, Y: 2}, {X: 8, Y: 2}, {X: 8, Y: 8}, {X: 2, Y: 8}, {X: 2, Y: 2}}},
+					{Points: []geojson.Point{{X: 3, Y: 3}, {X: 3, Y: 7}, {X: 7, Y: 7}, {X: 7, Y: 3}, {X: 3, Y: 3}}},
+				}},
+			}},
+		},
+		{
+			s: `{"xmin":0,"ymin":1,"xmax":2,"ymax":3}`,
+			expected: &geojson.Polygon{Rings: []geojson.LineString{
+				{Points: []geojson.Point{{X: 0, Y: 1}, {X: 2, Y: 1}, {X: 2, Y: 3}, {X: 0, Y: 3}, {X: 0, Y: 1}}},
+			}},
+		},
+	}
+	for i, c := range cases {
+		var g Geometry
+		err := json.Unmarshal([]byte(c.s), &g)
+		if err != nil {
+			t.Fatalf("case %d: invalid test input: %v", i, err)
+		}
+		got, err := ToGeometry(&g)
+		if err != nil {
+			t.Errorf("case %d: failed to convert: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("case %d: expected %#v, got %#v", i, c.expected, got)
+		}
+	}
+}
+
+func TestToGeometry_WebMercator(t *testing.T) {
+	var g Geometry
+	s := `{"x":-13627665.27,"y":4547675.35,"spatialReference":{"wkid":102100,"latestWkid":3857}}`
+	err := json.Unmarshal([]byte(s), &g)
+	if err != nil {
+		t.Fatalf("invalid test input: %v", err)
+	}
+	got, err := ToGeometry(&g)
+	if err != nil {
+		t.Fatalf("failed to convert: %v", err)
+	}
+	p, ok := got.(*geojson.Point)
+	if !ok {
+		t.Fatalf("expected Point, got %#v", got)
+	}
+	if math.Abs(p.X-(-122.4194)) > 1e-4 || math.Abs(p.Y-37.7749) > 1e-4 {
+		t.Errorf("expected approximately (-122.4194, 37.7749), got (%v, %v)", p.X, p.Y)
+	}
+}
+
+func TestToGeometry_Invalid(t *testing.T) {
+	cases := []string{
+		`{"x":1,"y":2,"spatialReference":{"wkid":27700}}`,
+		`{"paths":[[[0,0]]]}`,
+		`{"points":[[0]]}`,
+		`{"rings":[[[0,0],[1,1],[0,0]]]}`,
+		`{"xmin":0,"ymin":1}`,
+	}
+	for i, c := range cases {
+		var g Geometry
+		err := json.Unmarshal([]byte(c), &g)
+		if err != nil {
+			t.Fatalf("case %d: invalid test input: %v", i, err)
+		}
+		_, err = ToGeometry(&g)
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestRoundTrip_FromGeometry(t *testing.T) {
+	cases := []geojson.Geometry{
+		&geojson.Point{X: 1, Y: 2},
+		&geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+		&geojson.MultiPoint{Points: []geojson.Point{
+			{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+			{X: 4, Y: 5, Elevation: 6, HasElevation: true},
+		}},
+		&geojson.LineString{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+		&geojson.MultiLineString{Lines: []geojson.LineString{
+			{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+			{Points: []geojson.Point{{X: 2, Y: 2}, {X: 3, Y: 3}}},
+		}},
+		&geojson.Polygon{Rings: []geojson.LineString{
+			{Points: []geojson.Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}, {X: 0, Y: 0}}},
+			{Points: []geojson.Point{{X: 2, Y: 2}, {X: 2, Y: 4}, {X: 4, Y: 4}, {X: 4, Y: 2}, {X: 2, Y: 2}}},
+		}},
+		&geojson.MultiPolygon{Polygons: []geojson.Polygon{
+			{Rings: []geojson.LineString{
+				{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}, {X: 0, Y: 0}}},
+			}},
+			{Rings: []geojson.LineString{
+				{Points: []geojson.Point{{X: 20, Y: 0}, {X: 30, Y: 0}, {X: 30, Y: 10}, {X: 20, Y: 10}, {X: 20, Y: 0}}},
+			}},
+		}},
+	}
+	for i, c := range cases {
+		g, err := FromGeometry(c)
+		if err != nil {
+			t.Errorf("case %d: failed to convert: %v", i, err)
+			continue
+		}
+		// Go through JSON to make sure the geometry type survives.
+		b, err := json.Marshal(g)
+		if err != nil {
+			t.Errorf("case %d: failed to marshal: %v", i, err)
+			continue
+		}
+		var decoded Geometry
+		err = json.Unmarshal(b, &decoded)
+		if err != nil {
+			t.Errorf("case %d: failed to unmarshal: %v", i, err)
+			continue
+		}
+		got, err := ToGeometry(&decoded)
+		if err != nil {
+			t.Errorf("case %d: failed to convert back: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c) {
+			t.Errorf("case %d: expected %#v, got %#v", i, c, got)
+		}
+	}
+
+	_, err := FromGeometry(&geojson.GeometryCollection{})
+	if err == nil {
+		t.Errorf("expected error for GeometryCollection")
+	}
+}
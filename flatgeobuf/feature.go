@@ -0,0 +1,502 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/flatbuf"
+)
+
+// Field indices of the Feature table.
+const (
+	featureGeometry = iota
+	featureProperties
+	featureColumns
+)
+
+// Field indices of the Geometry table.
+const (
+	geometryEnds = iota
+	geometryXY
+	geometryZ
+	geometryM
+	geometryT
+	geometryTM
+	geometryType
+	geometryParts
+)
+
+func decodeFeature(b []byte, h *Header) (*geojson.Feature, error) {
+	r := flatbuf.NewReader(b)
+	t := r.Root()
+	f := &geojson.Feature{Properties: map[string]interface{}{}}
+	if g, ok := t.Table(featureGeometry); ok {
+		geometry, err := decodeGeometry(g, h.GeometryType)
+		if err != nil {
+			return nil, err
+		}
+		f.Geometry = geometry
+	}
+	columns := h.Columns
+	if c := t.Tables(featureColumns); len(c) > 0 {
+		// Per-feature schemas override the header schema.
+		columns = make([]Column, len(c))
+		for i := range c {
+			columns[i] = decodeColumn(c[i])
+		}
+	}
+	err := decodeProperties(t.Bytes(featureProperties), columns, f.Properties)
+	if err != nil {
+		return nil, err
+	}
+	if r.Err() != nil {
+		return nil, r.Err()
+	}
+	return f, nil
+}
+
+func decodeGeometry(t flatbuf.TableRef, headerType GeometryType) (geojson.Geometry, error) {
+	gt := GeometryType(t.Uint8(geometryType, uint8(headerType)))
+	if gt == Unknown {
+		gt = headerType
+	}
+	xy := t.Float64s(geometryXY)
+	z := t.Float64s(geometryZ)
+	if t.Err() != nil {
+		return nil, t.Err()
+	}
+	if len(z) != 0 && 2*len(z) != len(xy) {
+		return nil, fmt.Errorf("%v: %d z values for %d positions", gt, len(z), len(xy)/2)
+	}
+	points := make([]geojson.Point, len(xy)/2)
+	for i := range points {
+		points[i].X = xy[2*i]
+		points[i].Y = xy[2*i+1]
+		if len(z) > 0 {
+			points[i].Elevation = z[i]
+			points[i].HasElevation = true
+		}
+	}
+
+	switch gt {
+	case Point:
+		if len(points) != 1 {
+			return nil, fmt.Errorf("Point: must have exactly 1 position, got %d", len(points))
+		}
+		return &points[0], nil
+	case MultiPoint:
+		return &geojson.MultiPoint{Points: points}, nil
+	case LineString:
+		if len(points) < 2 {
+			return nil, fmt.Errorf("LineString: must have at least 2 points, got %d", len(points))
+		}
+		return &geojson.LineString{Points: points}, nil
+	case MultiLineString:
+		parts, err := split(points, t.Uint32s(geometryEnds))
+		if err != nil {
+			return nil, fmt.Errorf("MultiLineString: %v", err)
+		}
+		m := &geojson.MultiLineString{}
+		for i, part := range parts {
+			if len(part) < 2 {
+				return nil, fmt.Errorf("MultiLineString: line %d must have at least 2 points, got %d", i, len(part))
+			}
+			m.Lines = append(m.Lines, geojson.LineString{Points: part})
+		}
+		return m, nil
+	case Polygon:
+		return decodePolygon(points, t.Uint32s(geometryEnds))
+	case MultiPolygon:
+		m := &geojson.MultiPolygon{}
+		for i, part := range t.Tables(geometryParts) {
+			g, err := decodeGeometry(part, Polygon)
+			if err != nil {
+				return nil, fmt.Errorf("MultiPolygon: part %d: %v", i, err)
+			}
+			p, ok := g.(*geojson.Polygon)
+			if !ok {
+				return nil, fmt.Errorf("MultiPolygon: part %d: expected Polygon, got %T", i, g)
+			}
+			m.Polygons = append(m.Polygons, *p)
+		}
+		return m, nil
+	case GeometryCollection:
+		gc := &geojson.GeometryCollection{}
+		for i, part := range t.Tables(geometryParts) {
+			g, err := decodeGeometry(part, Unknown)
+			if err != nil {
+				return nil, fmt.Errorf("GeometryCollection: part %d: %v", i, err)
+			}
+			gc.Geometries = append(gc.Geometries, g)
+		}
+		return gc, nil
+	}
+	return nil, fmt.Errorf("unsupported geometry type %v", gt)
+}
+
+func decodePolygon(points []geojson.Point, ends []uint32) (*geojson.Polygon, error) {
+	rings, err := split(points, ends)
+	if err != nil {
+		return nil, fmt.Errorf("Polygon: %v", err)
+	}
+	if len(rings) == 0 {
+		return nil, fmt.Errorf("Polygon: must have at least 1 linear ring")
+	}
+	p := &geojson.Polygon{}
+	for i, ring := range rings {
+		if len(ring) < 4 {
+			return nil, fmt.Errorf("Polygon: each linear ring requires at least 4 points, ring %d has %d", i, len(ring))
+		}
+		if ring[0] != ring[len(ring)-1] {
+			return nil, fmt.Errorf("Polygon: linear ring %d is not closed", i)
+		}
+		p.Rings = append(p.Rings, geojson.LineString{Points: ring})
+	}
+	return p, nil
+}
+
+// split divides points at the given end indices. Without ends, all points
+// form a single part.
+func split(points []geojson.Point, ends []uint32) ([][]geojson.Point, error) {
+	if len(ends) == 0 {
+		if len(points) == 0 {
+			return nil, nil
+		}
+		return [][]geojson.Point{points}, nil
+	}
+	parts := make([][]geojson.Point, len(ends))
+	start := 0
+	for i, end := range ends {
+		if int(end) < start || int(end) > len(points) {
+			return nil, fmt.Errorf("invalid end %d for part %d", end, i)
+		}
+		parts[i] = points[start:end]
+		start = int(end)
+	}
+	return parts, nil
+}
+
+func decodeProperties(b []byte, columns []Column, properties map[string]interface{}) error {
+	pos := 0
+	need := func(n int) error {
+		if pos+n > len(b) {
+			return fmt.Errorf("properties truncated at byte %d", pos)
+		}
+		return nil
+	}
+	for pos < len(b) {
+		if err := need(2); err != nil {
+			return err
+		}
+		i := int(binary.LittleEndian.Uint16(b[pos:]))
+		pos += 2
+		if i >= len(columns) {
+			return fmt.Errorf("property references column %d of %d", i, len(columns))
+		}
+		c := &columns[i]
+		size := scalarSize(c.Type)
+		if size == 0 {
+			if err := need(4); err != nil {
+				return err
+			}
+			size = int(binary.LittleEndian.Uint32(b[pos:]))
+			pos += 4
+		}
+		if err := need(size); err != nil {
+			return err
+		}
+		v, err := decodeValue(c.Type, b[pos:pos+size])
+		if err != nil {
+			return fmt.Errorf("column %q: %v", c.Name, err)
+		}
+		properties[c.Name] = v
+		pos += size
+	}
+	return nil
+}
+
+// scalarSize returns the encoded size of fixed-width column types, or 0 for
+// length-prefixed types.
+func scalarSize(t ColumnType) int {
+	switch t {
+	case Byte, UByte, Bool:
+		return 1
+	case Short, UShort:
+		return 2
+	case Int, UInt, Float:
+		return 4
+	case Long, ULong, Double:
+		return 8
+	}
+	return 0
+}
+
+// decodeValue converts an encoded value to the type encoding/json would use
+// for it: numbers become float64.
+func decodeValue(t ColumnType, b []byte) (interface{}, error) {
+	switch t {
+	case Byte:
+		return float64(int8(b[0])), nil
+	case UByte:
+		return float64(b[0]), nil
+	case Bool:
+		return b[0] != 0, nil
+	case Short:
+		return float64(int16(binary.LittleEndian.Uint16(b))), nil
+	case UShort:
+		return float64(binary.LittleEndian.Uint16(b)), nil
+	case Int:
+		return float64(int32(binary.LittleEndian.Uint32(b))), nil
+	case UInt:
+		return float64(binary.LittleEndian.Uint32(b)), nil
+	case Long:
+		return float64(int64(binary.LittleEndian.Uint64(b))), nil
+	case ULong:
+		return float64(binary.LittleEndian.Uint64(b)), nil
+	case Float:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), nil
+	case Double:
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case String, DateTime:
+		return string(b), nil
+	case JSON:
+		var v interface{}
+		err := json.Unmarshal(b, &v)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	case Binary:
+		return append([]byte(nil), b...), nil
+	}
+	return nil, fmt.Errorf("unsupported column type %d", t)
+}
+
+// inferColumns derives a column for each property name used by features.
+// Numbers become Double columns, booleans Bool columns, and strings String
+// columns. Properties with mixed or structured values become JSON columns.
+func inferColumns(features []geojson.Feature) []Column {
+	types := map[string]ColumnType{}
+	for _, f := range features {
+		for k, v := range f.Properties {
+			var t ColumnType
+			switch v.(type) {
+			case nil:
+				if _, ok := types[k]; !ok {
+					types[k] = Binary
+				}
+				continue
+			case float64:
+				t = Double
+			case bool:
+				t = Bool
+			case string:
+				t = String
+			default:
+				t = JSON
+			}
+			// Binary marks a column that has only seen nil so far.
+			if prev, ok := types[k]; ok && prev != Binary && prev != t {
+				t = JSON
+			}
+			types[k] = t
+		}
+	}
+	names := make([]string, 0, len(types))
+	for k := range types {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	columns := make([]Column, len(names))
+	for i, k := range names {
+		t := types[k]
+		if t == Binary {
+			t = JSON
+		}
+		columns[i] = Column{Name: k, Type: t, Width: -1, Precision: -1, Scale: -1, Nullable: true}
+	}
+	return columns
+}
+
+func encodeProperties(properties map[string]interface{}, columns []Column) ([]byte, error) {
+	var b []byte
+	for i := range columns {
+		c := &columns[i]
+		v, ok := properties[c.Name]
+		if !ok || v == nil {
+			continue
+		}
+		var idx [2]byte
+		binary.LittleEndian.PutUint16(idx[:], uint16(i))
+		b = append(b, idx[:]...)
+		var err error
+		b, err = appendValue(b, c.Type, v)
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %v", c.Name, err)
+		}
+	}
+	return b, nil
+}
+
+func appendValue(b []byte, t ColumnType, v interface{}) ([]byte, error) {
+	var buf [8]byte
+	if size := scalarSize(t); size > 0 {
+		if t == Bool {
+			bv, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("expected bool, got %T", v)
+			}
+			if bv {
+				buf[0] = 1
+			}
+			return append(b, buf[0]), nil
+		}
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected number, got %T", v)
+		}
+		switch t {
+		case Byte, UByte:
+			buf[0] = byte(int64(f))
+		case Short, UShort:
+			binary.LittleEndian.PutUint16(buf[:], uint16(int64(f)))
+		case Int, UInt:
+			binary.LittleEndian.PutUint32(buf[:], uint32(int64(f)))
+		case Long:
+			binary.LittleEndian.PutUint64(buf[:], uint64(int64(f)))
+		case ULong:
+			binary.LittleEndian.PutUint64(buf[:], uint64(f))
+		case Float:
+			binary.LittleEndian.PutUint32(buf[:], math.Float32bits(float32(f)))
+		case Double:
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+		}
+		return append(b, buf[:size]...), nil
+	}
+
+	var data []byte
+	switch t {
+	case String, DateTime:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %T", v)
+		}
+		data = []byte(s)
+	case Binary:
+		d, ok := v.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("expected []byte, got %T", v)
+		}
+		data = d
+	case JSON:
+		var err error
+		data, err = json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported column type %d", t)
+	}
+	binary.LittleEndian.PutUint32(buf[:], uint32(len(data)))
+	b = append(b, buf[:4]...)
+	return append(b, data...), nil
+}
+
+// encodeGeometry builds the Geometry table for g. Z values are written for
+// every position if hasZ is set.
+func encodeGeometry(g geojson.Geometry, hasZ bool) (*flatbuf.Table, error) {
+	t := flatbuf.NewTable()
+	var points []geojson.Point
+	var ends []uint32
+	switch v := g.(type) {
+	case *geojson.Point:
+		t.Uint8(geometryType, uint8(Point))
+		points = []geojson.Point{*v}
+	case *geojson.MultiPoint:
+		t.Uint8(geometryType, uint8(MultiPoint))
+		points = v.Points
+	case *geojson.LineString:
+		t.Uint8(geometryType, uint8(LineString))
+		points = v.Points
+	case *geojson.MultiLineString:
+		t.Uint8(geometryType, uint8(MultiLineString))
+		for _, ls := range v.Lines {
+			points = append(points, ls.Points...)
+			ends = append(ends, uint32(len(points)))
+		}
+	case *geojson.Polygon:
+		t.Uint8(geometryType, uint8(Polygon))
+		for _, ring := range v.Rings {
+			points = append(points, ring.Points...)
+			ends = append(ends, uint32(len(points)))
+		}
+	case *geojson.MultiPolygon:
+		t.Uint8(geometryType, uint8(MultiPolygon))
+		parts := make(flatbuf.Tables, len(v.Polygons))
+		for i := range v.Polygons {
+			part, err := encodeGeometry(&v.Polygons[i], hasZ)
+			if err != nil {
+				return nil, err
+			}
+			parts[i] = part
+		}
+		t.Ref(geometryParts, parts)
+		return t, nil
+	case *geojson.GeometryCollection:
+		t.Uint8(geometryType, uint8(GeometryCollection))
+		parts := make(flatbuf.Tables, len(v.Geometries))
+		for i, child := range v.Geometries {
+			part, err := encodeGeometry(child, hasZ)
+			if err != nil {
+				return nil, fmt.Errorf("GeometryCollection: geometry %d: %v", i, err)
+			}
+			parts[i] = part
+		}
+		t.Ref(geometryParts, parts)
+		return t, nil
+	default:
+		return nil, fmt.Errorf("unsupported geometry type: %T", g)
+	}
+
+	// A single part needs no ends.
+	if len(ends) > 1 {
+		t.Ref(geometryEnds, flatbuf.Uint32Vector(ends))
+	}
+	xy := make([]float64, 2*len(points))
+	for i, p := range points {
+		xy[2*i] = p.X
+		xy[2*i+1] = p.Y
+	}
+	t.Ref(geometryXY, flatbuf.Float64Vector(xy))
+	if hasZ {
+		z := make([]float64, len(points))
+		for i, p := range points {
+			z[i] = p.Elevation
+		}
+		t.Ref(geometryZ, flatbuf.Float64Vector(z))
+	}
+	return t, nil
+}
+
+func encodeFeature(f *geojson.Feature, columns []Column, hasZ bool) ([]byte, error) {
+	t := flatbuf.NewTable()
+	if f.Geometry != nil {
+		g, err := encodeGeometry(f.Geometry, hasZ)
+		if err != nil {
+			return nil, err
+		}
+		t.Ref(featureGeometry, g)
+	}
+	props, err := encodeProperties(f.Properties, columns)
+	if err != nil {
+		return nil, err
+	}
+	if len(props) > 0 {
+		t.Ref(featureProperties, flatbuf.ByteVector(props))
+	}
+	return flatbuf.Build(t), nil
+}
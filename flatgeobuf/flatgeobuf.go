@@ -0,0 +1,129 @@
+// Package flatgeobuf reads and writes FlatGeobuf files as GeoJSON features.
+//
+// FlatGeobuf is a binary encoding of simple features built on FlatBuffers.
+// A file consists of a magic number, a header describing the geometry type
+// and attribute columns, an optional packed Hilbert R-tree spatial index, and
+// a sequence of size-prefixed features. Features can be streamed in file
+// order with Reader.Next, or selected by bounding box through the index with
+// Reader.Search.
+//
+// See https://flatgeobuf.org/ for the specification.
+package flatgeobuf
+
+import "fmt"
+
+// magic identifies FlatGeobuf files. The fourth byte is the major version and
+// the last byte is the patch version.
+var magic = [8]byte{'f', 'g', 'b', 3, 'f', 'g', 'b', 0}
+
+// A GeometryType is a FlatGeobuf geometry type code. Only the RFC 7946
+// types are supported; curves and surfaces are rejected when reading.
+type GeometryType uint8
+
+// Geometry types supported by this package.
+const (
+	Unknown            GeometryType = 0
+	Point              GeometryType = 1
+	LineString         GeometryType = 2
+	Polygon            GeometryType = 3
+	MultiPoint         GeometryType = 4
+	MultiLineString    GeometryType = 5
+	MultiPolygon       GeometryType = 6
+	GeometryCollection GeometryType = 7
+)
+
+var geometryTypeNames = map[GeometryType]string{
+	Unknown:            "Unknown",
+	Point:              "Point",
+	LineString:         "LineString",
+	Polygon:            "Polygon",
+	MultiPoint:         "MultiPoint",
+	MultiLineString:    "MultiLineString",
+	MultiPolygon:       "MultiPolygon",
+	GeometryCollection: "GeometryCollection",
+}
+
+func (t GeometryType) String() string {
+	if name, ok := geometryTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("GeometryType(%d)", uint8(t))
+}
+
+// A ColumnType is the type of an attribute column.
+type ColumnType uint8
+
+// Column types defined by the FlatGeobuf schema.
+const (
+	Byte ColumnType = iota
+	UByte
+	Bool
+	Short
+	UShort
+	Int
+	UInt
+	Long
+	ULong
+	Float
+	Double
+	String
+	JSON
+	DateTime
+	Binary
+)
+
+// A Header describes the contents of a FlatGeobuf file.
+type Header struct {
+	// Dataset name.
+	Name string
+	// Bounding box of all features as [minX, minY, maxX, maxY]. Optional.
+	Envelope []float64
+	// Geometry type shared by all features, or Unknown if they vary.
+	GeometryType GeometryType
+	// Whether geometries carry Z values.
+	HasZ bool
+	// Whether geometries carry M values. M values are ignored when reading.
+	HasM bool
+	// Attribute columns.
+	Columns []Column
+	// Number of features, or 0 if unknown.
+	FeaturesCount uint64
+	// Branching factor of the spatial index, or 0 if there is no index.
+	IndexNodeSize uint16
+	// Coordinate reference system. Optional.
+	CRS *CRS
+	// Dataset title, description, and free-form metadata.
+	Title       string
+	Description string
+	Metadata    string
+}
+
+// A Column describes a feature attribute.
+type Column struct {
+	Name        string
+	Type        ColumnType
+	Title       string
+	Description string
+	// Width, Precision, and Scale are -1 when unspecified.
+	Width      int32
+	Precision  int32
+	Scale      int32
+	Nullable   bool
+	Unique     bool
+	PrimaryKey bool
+	Metadata   string
+}
+
+// A CRS identifies a coordinate reference system, typically by EPSG code.
+type CRS struct {
+	// Defining organization, e.g., "EPSG".
+	Org string
+	// Numeric code within the organization, e.g., 4326.
+	Code        int32
+	Name        string
+	Description string
+	// Well-known text definition.
+	WKT string
+	// Non-numeric code within the organization.
+	CodeString string
+}
@@ -0,0 +1,148 @@
+package flatgeobuf
+
+import "github.com/bsidhom/geojson/internal/flatbuf"
+
+// Field indices of the Header table.
+const (
+	headerName = iota
+	headerEnvelope
+	headerGeometryType
+	headerHasZ
+	headerHasM
+	headerHasT
+	headerHasTM
+	headerColumns
+	headerFeaturesCount
+	headerIndexNodeSize
+	headerCRS
+	headerTitle
+	headerDescription
+	headerMetadata
+)
+
+// Field indices of the Column table.
+const (
+	columnName = iota
+	columnType
+	columnTitle
+	columnDescription
+	columnWidth
+	columnPrecision
+	columnScale
+	columnNullable
+	columnUnique
+	columnPrimaryKey
+	columnMetadata
+)
+
+// Field indices of the Crs table.
+const (
+	crsOrg = iota
+	crsCode
+	crsName
+	crsDescription
+	crsWKT
+	crsCodeString
+)
+
+const defaultIndexNodeSize = 16
+
+func decodeHeader(b []byte) (*Header, error) {
+	r := flatbuf.NewReader(b)
+	t := r.Root()
+	h := &Header{
+		Name:          t.String(headerName),
+		Envelope:      t.Float64s(headerEnvelope),
+		GeometryType:  GeometryType(t.Uint8(headerGeometryType, 0)),
+		HasZ:          t.Bool(headerHasZ, false),
+		HasM:          t.Bool(headerHasM, false),
+		FeaturesCount: t.Uint64(headerFeaturesCount, 0),
+		IndexNodeSize: t.Uint16(headerIndexNodeSize, defaultIndexNodeSize),
+		Title:         t.String(headerTitle),
+		Description:   t.String(headerDescription),
+		Metadata:      t.String(headerMetadata),
+	}
+	for _, c := range t.Tables(headerColumns) {
+		h.Columns = append(h.Columns, decodeColumn(c))
+	}
+	if c, ok := t.Table(headerCRS); ok {
+		h.CRS = &CRS{
+			Org:         c.String(crsOrg),
+			Code:        c.Int32(crsCode, 0),
+			Name:        c.String(crsName),
+			Description: c.String(crsDescription),
+			WKT:         c.String(crsWKT),
+			CodeString:  c.String(crsCodeString),
+		}
+	}
+	if r.Err() != nil {
+		return nil, r.Err()
+	}
+	return h, nil
+}
+
+func decodeColumn(t flatbuf.TableRef) Column {
+	return Column{
+		Name:        t.String(columnName),
+		Type:        ColumnType(t.Uint8(columnType, 0)),
+		Title:       t.String(columnTitle),
+		Description: t.String(columnDescription),
+		Width:       t.Int32(columnWidth, -1),
+		Precision:   t.Int32(columnPrecision, -1),
+		Scale:       t.Int32(columnScale, -1),
+		Nullable:    t.Bool(columnNullable, true),
+		Unique:      t.Bool(columnUnique, false),
+		PrimaryKey:  t.Bool(columnPrimaryKey, false),
+		Metadata:    t.String(columnMetadata),
+	}
+}
+
+func encodeHeader(h *Header) []byte {
+	t := flatbuf.NewTable()
+	t.String(headerName, h.Name)
+	if len(h.Envelope) > 0 {
+		t.Ref(headerEnvelope, flatbuf.Float64Vector(h.Envelope))
+	}
+	t.Uint8(headerGeometryType, uint8(h.GeometryType))
+	t.Bool(headerHasZ, h.HasZ)
+	t.Bool(headerHasM, h.HasM)
+	if len(h.Columns) > 0 {
+		columns := make(flatbuf.Tables, len(h.Columns))
+		for i := range h.Columns {
+			columns[i] = encodeColumn(&h.Columns[i])
+		}
+		t.Ref(headerColumns, columns)
+	}
+	t.Uint64(headerFeaturesCount, h.FeaturesCount)
+	t.Uint16(headerIndexNodeSize, h.IndexNodeSize)
+	if h.CRS != nil {
+		c := flatbuf.NewTable()
+		c.String(crsOrg, h.CRS.Org)
+		c.Int32(crsCode, h.CRS.Code)
+		c.String(crsName, h.CRS.Name)
+		c.String(crsDescription, h.CRS.Description)
+		c.String(crsWKT, h.CRS.WKT)
+		c.String(crsCodeString, h.CRS.CodeString)
+		t.Ref(headerCRS, c)
+	}
+	t.String(headerTitle, h.Title)
+	t.String(headerDescription, h.Description)
+	t.String(headerMetadata, h.Metadata)
+	return flatbuf.Build(t)
+}
+
+func encodeColumn(c *Column) *flatbuf.Table {
+	t := flatbuf.NewTable()
+	t.Ref(columnName, flatbuf.String(c.Name))
+	t.Uint8(columnType, uint8(c.Type))
+	t.String(columnTitle, c.Title)
+	t.String(columnDescription, c.Description)
+	t.Int32(columnWidth, c.Width)
+	t.Int32(columnPrecision, c.Precision)
+	t.Int32(columnScale, c.Scale)
+	t.Bool(columnNullable, c.Nullable)
+	t.Bool(columnUnique, c.Unique)
+	t.Bool(columnPrimaryKey, c.PrimaryKey)
+	t.String(columnMetadata, c.Metadata)
+	return t
+}
@@ -0,0 +1,223 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+)
+
+// This file implements the packed Hilbert R-tree used as the FlatGeobuf
+// spatial index. The tree is stored as a flat array of nodes, root first and
+// leaves last. Each leaf holds the bounding box of a feature and the byte
+// offset of that feature relative to the start of the feature data. Each
+// interior node holds the bounding box of its children and the array index
+// of its first child.
+
+const nodeItemSize = 40
+
+// A BBox is an axis-aligned bounding box.
+type BBox struct {
+	MinX, MinY, MaxX, MaxY float64
+}
+
+// emptyBBox is the identity for expand: it intersects nothing.
+var emptyBBox = BBox{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+
+func (b *BBox) expand(o BBox) {
+	b.MinX = math.Min(b.MinX, o.MinX)
+	b.MinY = math.Min(b.MinY, o.MinY)
+	b.MaxX = math.Max(b.MaxX, o.MaxX)
+	b.MaxY = math.Max(b.MaxY, o.MaxY)
+}
+
+func (b BBox) intersects(o BBox) bool {
+	return b.MinX <= o.MaxX && b.MinY <= o.MaxY && b.MaxX >= o.MinX && b.MaxY >= o.MinY
+}
+
+func (b BBox) empty() bool {
+	return b.MinX > b.MaxX || b.MinY > b.MaxY
+}
+
+type nodeItem struct {
+	bbox   BBox
+	offset uint64
+}
+
+// levelBounds returns the [start, end) node indices of each tree level,
+// leaves first.
+func levelBounds(numItems, nodeSize int) [][2]int {
+	n := numItems
+	numNodes := n
+	levelNumNodes := []int{n}
+	// Even a single leaf has a root above it.
+	for len(levelNumNodes) == 1 || n > 1 {
+		n = (n + nodeSize - 1) / nodeSize
+		numNodes += n
+		levelNumNodes = append(levelNumNodes, n)
+	}
+	bounds := make([][2]int, len(levelNumNodes))
+	n = numNodes
+	for i, size := range levelNumNodes {
+		bounds[i] = [2]int{n - size, n}
+		n -= size
+	}
+	return bounds
+}
+
+// indexSize returns the size in bytes of an index over numItems features.
+func indexSize(numItems, nodeSize int) int {
+	if numItems == 0 || nodeSize < 2 {
+		return 0
+	}
+	bounds := levelBounds(numItems, nodeSize)
+	return bounds[0][1] * nodeItemSize
+}
+
+// buildIndex builds the tree over leaves, which must already be in Hilbert
+// order, and returns its encoding.
+func buildIndex(leaves []nodeItem, nodeSize int) []byte {
+	bounds := levelBounds(len(leaves), nodeSize)
+	nodes := make([]nodeItem, bounds[0][1])
+	copy(nodes[bounds[0][0]:], leaves)
+	for i := 0; i < len(bounds)-1; i++ {
+		pos, end := bounds[i][0], bounds[i][1]
+		parent := bounds[i+1][0]
+		for pos < end {
+			node := nodeItem{bbox: emptyBBox, offset: uint64(pos)}
+			for j := 0; j < nodeSize && pos < end; j++ {
+				node.bbox.expand(nodes[pos].bbox)
+				pos++
+			}
+			nodes[parent] = node
+			parent++
+		}
+	}
+
+	b := make([]byte, len(nodes)*nodeItemSize)
+	for i, n := range nodes {
+		p := b[i*nodeItemSize:]
+		binary.LittleEndian.PutUint64(p[0:], math.Float64bits(n.bbox.MinX))
+		binary.LittleEndian.PutUint64(p[8:], math.Float64bits(n.bbox.MinY))
+		binary.LittleEndian.PutUint64(p[16:], math.Float64bits(n.bbox.MaxX))
+		binary.LittleEndian.PutUint64(p[24:], math.Float64bits(n.bbox.MaxY))
+		binary.LittleEndian.PutUint64(p[32:], n.offset)
+	}
+	return b
+}
+
+func readNode(index []byte, i int) nodeItem {
+	p := index[i*nodeItemSize:]
+	return nodeItem{
+		bbox: BBox{
+			MinX: math.Float64frombits(binary.LittleEndian.Uint64(p[0:])),
+			MinY: math.Float64frombits(binary.LittleEndian.Uint64(p[8:])),
+			MaxX: math.Float64frombits(binary.LittleEndian.Uint64(p[16:])),
+			MaxY: math.Float64frombits(binary.LittleEndian.Uint64(p[24:])),
+		},
+		offset: binary.LittleEndian.Uint64(p[32:]),
+	}
+}
+
+// searchIndex returns the feature data offsets of all leaves intersecting
+// query, in ascending order.
+func searchIndex(index []byte, numItems, nodeSize int, query BBox) []uint64 {
+	bounds := levelBounds(numItems, nodeSize)
+	leafStart := bounds[0][0]
+	numNodes := bounds[0][1]
+	type entry struct {
+		node  int
+		level int
+	}
+	queue := []entry{{0, len(bounds) - 1}}
+	var results []uint64
+	for len(queue) > 0 {
+		e := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		end := e.node + nodeSize
+		if levelEnd := bounds[e.level][1]; end > levelEnd {
+			end = levelEnd
+		}
+		for pos := e.node; pos < end; pos++ {
+			if pos >= numNodes {
+				break
+			}
+			n := readNode(index, pos)
+			if !query.intersects(n.bbox) {
+				continue
+			}
+			if pos >= leafStart {
+				results = append(results, n.offset)
+			} else if e.level > 0 {
+				queue = append(queue, entry{int(n.offset), e.level - 1})
+			}
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i] < results[j] })
+	return results
+}
+
+const hilbertMax = (1 << 16) - 1
+
+// hilbertValue maps the center of b onto a Hilbert curve covering extent.
+func hilbertValue(b, extent BBox) uint32 {
+	if b.empty() {
+		return 0
+	}
+	scale := func(v, min, max float64) uint32 {
+		if max <= min {
+			return 0
+		}
+		return uint32(math.Floor(hilbertMax * (v - min) / (max - min)))
+	}
+	x := scale((b.MinX+b.MaxX)/2, extent.MinX, extent.MaxX)
+	y := scale((b.MinY+b.MaxY)/2, extent.MinY, extent.MaxY)
+	return hilbert(x, y)
+}
+
+// hilbert returns the index of (x, y) along a 16-bit Hilbert curve. This is
+// the branch-free algorithm used by the FlatGeobuf reference implementations.
+func hilbert(x, y uint32) uint32 {
+	a := x ^ y
+	b := 0xFFFF ^ a
+	c := 0xFFFF ^ (x | y)
+	d := x & (y ^ 0xFFFF)
+
+	A := a | (b >> 1)
+	B := (a >> 1) ^ a
+	C := ((c >> 1) ^ (b & (d >> 1))) ^ c
+	D := ((a & (c >> 1)) ^ (d >> 1)) ^ d
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 2)) ^ (b & (b >> 2))
+	B = (a & (b >> 2)) ^ (b & ((a ^ b) >> 2))
+	C ^= (a & (c >> 2)) ^ (b & (d >> 2))
+	D ^= (b & (c >> 2)) ^ ((a ^ b) & (d >> 2))
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 4)) ^ (b & (b >> 4))
+	B = (a & (b >> 4)) ^ (b & ((a ^ b) >> 4))
+	C ^= (a & (c >> 4)) ^ (b & (d >> 4))
+	D ^= (b & (c >> 4)) ^ ((a ^ b) & (d >> 4))
+
+	a, b, c, d = A, B, C, D
+	C ^= (a & (c >> 8)) ^ (b & (d >> 8))
+	D ^= (b & (c >> 8)) ^ ((a ^ b) & (d >> 8))
+
+	a = C ^ (C >> 1)
+	b = D ^ (D >> 1)
+
+	i0 := x ^ y
+	i1 := b | (0xFFFF ^ (i0 | a))
+
+	i0 = (i0 | (i0 << 8)) & 0x00FF00FF
+	i0 = (i0 | (i0 << 4)) & 0x0F0F0F0F
+	i0 = (i0 | (i0 << 2)) & 0x33333333
+	i0 = (i0 | (i0 << 1)) & 0x55555555
+
+	i1 = (i1 | (i1 << 8)) & 0x00FF00FF
+	i1 = (i1 | (i1 << 4)) & 0x0F0F0F0F
+	i1 = (i1 | (i1 << 2)) & 0x33333333
+	i1 = (i1 | (i1 << 1)) & 0x55555555
+
+	return (i1 << 1) | i0
+}
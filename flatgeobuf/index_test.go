@@ -0,0 +1,61 @@
+package flatgeobuf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLevelBounds(t *testing.T) {
+	cases := []struct {
+		numItems int
+		nodeSize int
+		expected [][2]int
+	}{
+		{1, 16, [][2]int{{1, 2}, {0, 1}}},
+		{16, 16, [][2]int{{1, 17}, {0, 1}}},
+		{17, 16, [][2]int{{3, 20}, {1, 3}, {0, 1}}},
+		{10, 2, [][2]int{{11, 21}, {6, 11}, {3, 6}, {1, 3}, {0, 1}}},
+	}
+	for i, c := range cases {
+		got := levelBounds(c.numItems, c.nodeSize)
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("case %d: expected %v, got %v", i, c.expected, got)
+		}
+	}
+}
+
+func TestHilbert(t *testing.T) {
+	// The first cells of a Hilbert curve visit the 2x2 corner in a U shape.
+	cases := []struct {
+		x, y     uint32
+		expected uint32
+	}{
+		{0, 0, 0},
+		{1, 0, 1},
+		{1, 1, 2},
+		{0, 1, 3},
+	}
+	for i, c := range cases {
+		got := hilbert(c.x, c.y)
+		if got != c.expected {
+			t.Errorf("case %d: hilbert(%d, %d): expected %d, got %d", i, c.x, c.y, c.expected, got)
+		}
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	var leaves []nodeItem
+	for i := 0; i < 37; i++ {
+		x := float64(i)
+		leaves = append(leaves, nodeItem{bbox: BBox{x, 0, x + 0.5, 1}, offset: uint64(i * 10)})
+	}
+	index := buildIndex(leaves, 3)
+	if len(index) != indexSize(len(leaves), 3) {
+		t.Fatalf("expected index of %d bytes, got %d", indexSize(len(leaves), 3), len(index))
+	}
+	got := searchIndex(index, len(leaves), 3, BBox{10.6, 0, 13.2, 1})
+	expected := []uint64{110, 120, 130}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,233 @@
+package flatgeobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/bsidhom/geojson"
+)
+
+// maxBufferSize bounds the size of a single header or feature to guard
+// against corrupt size prefixes.
+const maxBufferSize = 1 << 30
+
+// maxFeatures bounds the features count of a header so that the size of its
+// index fits in an int. An index has fewer than twice as many nodes as it
+// has leaves.
+const maxFeatures = int(^uint(0)>>1) / (2 * nodeItemSize)
+
+// A Reader streams features from a FlatGeobuf file.
+type Reader struct {
+	r      io.Reader
+	header *Header
+	// Offset of the start of the file within r, if r is an io.Seeker.
+	base int64
+	// File offsets of the index and of the first feature.
+	indexStart int64
+	dataStart  int64
+	// File offset of the next feature returned by Next.
+	next int64
+}
+
+// NewReader reads the file header from r and positions the reader at the
+// first feature. If r also implements io.Seeker, the spatial index is
+// skipped by seeking and Search is available.
+func NewReader(r io.Reader) (*Reader, error) {
+	var base int64
+	if s, ok := r.(io.Seeker); ok {
+		var err error
+		base, err = s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("flatgeobuf: %v", err)
+		}
+	}
+	var m [8]byte
+	_, err := io.ReadFull(r, m[:])
+	if err != nil {
+		return nil, fmt.Errorf("flatgeobuf: %v", err)
+	}
+	if !bytes.Equal(m[:3], magic[:3]) || m[3] != magic[3] || !bytes.Equal(m[4:7], magic[4:7]) {
+		return nil, fmt.Errorf("flatgeobuf: invalid magic number %q", m[:])
+	}
+	b, err := readSizePrefixed(r)
+	if err != nil {
+		return nil, fmt.Errorf("flatgeobuf: header: %v", err)
+	}
+	h, err := decodeHeader(b)
+	if err != nil {
+		return nil, fmt.Errorf("flatgeobuf: header: %v", err)
+	}
+	if h.FeaturesCount > uint64(maxFeatures) {
+		return nil, fmt.Errorf("flatgeobuf: header: features count %d exceeds limit", h.FeaturesCount)
+	}
+	// An index node size of 0 means that there is no index.
+	if h.IndexNodeSize == 1 {
+		return nil, fmt.Errorf("flatgeobuf: header: index node size must be at least 2")
+	}
+
+	fr := &Reader{
+		r:          r,
+		header:     h,
+		base:       base,
+		indexStart: int64(len(m) + 4 + len(b)),
+	}
+	fr.dataStart = fr.indexStart + int64(fr.indexSize())
+	fr.next = fr.dataStart
+	if s, ok := r.(io.Seeker); ok {
+		err = fr.checkSize(s)
+		if err != nil {
+			return nil, fmt.Errorf("flatgeobuf: header: %v", err)
+		}
+		_, err = s.Seek(base+fr.dataStart, io.SeekStart)
+	} else {
+		_, err = io.CopyN(ioutil.Discard, r, int64(fr.indexSize()))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("flatgeobuf: index: %v", err)
+	}
+	return fr, nil
+}
+
+// Header returns the file header.
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+func (r *Reader) indexSize() int {
+	return indexSize(int(r.header.FeaturesCount), int(r.header.IndexNodeSize))
+}
+
+// checkSize reports an error if the index and features described by the
+// header cannot fit in what remains of s after the header. Search relies on
+// this to bound the index it reads.
+func (r *Reader) checkSize(s io.Seeker) error {
+	end, err := s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	remaining := end - r.base - r.indexStart
+	if int64(r.indexSize()) > remaining {
+		return fmt.Errorf("index of %d bytes exceeds the %d bytes left", r.indexSize(), remaining)
+	}
+	// Every feature takes at least its size prefix.
+	if r.header.FeaturesCount > uint64(remaining-int64(r.indexSize()))/4 {
+		return fmt.Errorf("%d features exceed the %d bytes left", r.header.FeaturesCount, remaining)
+	}
+	return nil
+}
+
+// Next returns the next feature in file order, or io.EOF after the last one.
+func (r *Reader) Next() (*geojson.Feature, error) {
+	b, err := readSizePrefixed(r.r)
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("flatgeobuf: feature: %v", err)
+	}
+	r.next += int64(4 + len(b))
+	f, err := decodeFeature(b, r.header)
+	if err != nil {
+		return nil, fmt.Errorf("flatgeobuf: feature: %v", err)
+	}
+	return f, nil
+}
+
+// Search returns the features whose bounding boxes intersect b, in file
+// order. It requires a spatial index and an underlying io.ReadSeeker. The
+// position of Next is not affected.
+func (r *Reader) Search(b BBox) ([]geojson.Feature, error) {
+	rs, ok := r.r.(io.ReadSeeker)
+	if !ok {
+		return nil, fmt.Errorf("flatgeobuf: search requires an io.ReadSeeker")
+	}
+	size := r.indexSize()
+	if size == 0 {
+		return nil, fmt.Errorf("flatgeobuf: file has no spatial index")
+	}
+	// The input may have shrunk since NewReader checked it.
+	err := r.checkSize(rs)
+	if err != nil {
+		return nil, fmt.Errorf("flatgeobuf: %v", err)
+	}
+	_, err = rs.Seek(r.base+r.indexStart, io.SeekStart)
+	if err != nil {
+		return nil, fmt.Errorf("flatgeobuf: %v", err)
+	}
+	index := make([]byte, size)
+	_, err = io.ReadFull(rs, index)
+	if err != nil {
+		return nil, fmt.Errorf("flatgeobuf: index: %v", err)
+	}
+
+	var features []geojson.Feature
+	for _, offset := range searchIndex(index, int(r.header.FeaturesCount), int(r.header.IndexNodeSize), b) {
+		_, err := rs.Seek(r.base+r.dataStart+int64(offset), io.SeekStart)
+		if err != nil {
+			return nil, fmt.Errorf("flatgeobuf: %v", err)
+		}
+		buf, err := readSizePrefixed(rs)
+		if err != nil {
+			return nil, fmt.Errorf("flatgeobuf: feature at offset %d: %v", offset, err)
+		}
+		f, err := decodeFeature(buf, r.header)
+		if err != nil {
+			return nil, fmt.Errorf("flatgeobuf: feature at offset %d: %v", offset, err)
+		}
+		features = append(features, *f)
+	}
+	_, err = rs.Seek(r.base+r.next, io.SeekStart)
+	if err != nil {
+		return nil, fmt.Errorf("flatgeobuf: %v", err)
+	}
+	return features, nil
+}
+
+// ReadAll reads the header and all features from r.
+func ReadAll(r io.Reader) (*geojson.FeatureCollection, *Header, error) {
+	fr, err := NewReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	fc := &geojson.FeatureCollection{}
+	for {
+		f, err := fr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		fc.Features = append(fc.Features, *f)
+	}
+	return fc, fr.Header(), nil
+}
+
+// readSizePrefixed reads a little-endian uint32 length followed by that
+// many bytes. It returns io.EOF only if r is exhausted before the prefix.
+func readSizePrefixed(r io.Reader) ([]byte, error) {
+	var prefix [4]byte
+	_, err := io.ReadFull(r, prefix[:])
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("truncated size prefix")
+		}
+		return nil, err
+	}
+	size := binary.LittleEndian.Uint32(prefix[:])
+	if size > maxBufferSize {
+		return nil, fmt.Errorf("size %d exceeds limit", size)
+	}
+	b := make([]byte, size)
+	_, err = io.ReadFull(r, b)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
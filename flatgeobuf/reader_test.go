@@ -0,0 +1,299 @@
+package flatgeobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func testFeatures() *geojson.FeatureCollection {
+	square := geojson.LineString{
+		Points: []geojson.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}, {X: 0, Y: 0}},
+	}
+	hole := geojson.LineString{
+		Points: []geojson.Point{{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 1, Y: 1}},
+	}
+	return &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				Geometry: &geojson.Point{X: 1, Y: 2},
+				Properties: map[string]interface{}{
+					"name":  "a",
+					"count": float64(1),
+					"ok":    true,
+					"tags":  []interface{}{"x", float64(2)},
+				},
+			},
+			{
+				Geometry: &geojson.Polygon{Rings: []geojson.LineString{square, hole}},
+				Properties: map[string]interface{}{
+					"name": "b",
+				},
+			},
+			{
+				Geometry: &geojson.MultiPolygon{
+					Polygons: []geojson.Polygon{
+						{Rings: []geojson.LineString{square}},
+						{Rings: []geojson.LineString{square, hole}},
+					},
+				},
+				Properties: map[string]interface{}{},
+			},
+			{
+				Geometry: &geojson.MultiLineString{
+					Lines: []geojson.LineString{
+						{Points: []geojson.Point{{X: 10, Y: 10}, {X: 11, Y: 11}}},
+						{Points: []geojson.Point{{X: 12, Y: 12}, {X: 13, Y: 13}, {X: 14, Y: 12}}},
+					},
+				},
+				Properties: map[string]interface{}{"count": 2.5},
+			},
+			{
+				Geometry: &geojson.GeometryCollection{
+					Geometries: []geojson.Geometry{
+						&geojson.MultiPoint{Points: []geojson.Point{{X: -5, Y: -5}, {X: -6, Y: -6}}},
+						&geojson.LineString{Points: []geojson.Point{{X: -1, Y: -1}, {X: -2, Y: -2}}},
+					},
+				},
+				Properties: map[string]interface{}{},
+			},
+			{
+				Properties: map[string]interface{}{"name": "no geometry"},
+			},
+		},
+	}
+}
+
+// sortFeatures orders features by their names and geometry types so that
+// collections can be compared independently of Hilbert ordering.
+func sortFeatures(fc *geojson.FeatureCollection) {
+	key := func(f *geojson.Feature) string {
+		name, _ := f.Properties["name"].(string)
+		return name + geometryTypeOf(f.Geometry).String()
+	}
+	sort.SliceStable(fc.Features, func(i, j int) bool {
+		return key(&fc.Features[i]) < key(&fc.Features[j])
+	})
+}
+
+func TestRoundTrip_Write(t *testing.T) {
+	cases := []*WriteOptions{
+		nil,
+		{NoIndex: true},
+		{IndexNodeSize: 2, Name: "test", CRS: &CRS{Org: "EPSG", Code: 4326}},
+	}
+	for i, opts := range cases {
+		fc := testFeatures()
+		var buf bytes.Buffer
+		err := Write(&buf, fc, opts)
+		if err != nil {
+			t.Errorf("failed to write case %d: %v", i, err)
+			continue
+		}
+		got, h, err := ReadAll(&buf)
+		if err != nil {
+			t.Errorf("failed to read case %d: %v", i, err)
+			continue
+		}
+		if h.FeaturesCount != uint64(len(fc.Features)) {
+			t.Errorf("case %d: expected %d features in header, got %d", i, len(fc.Features), h.FeaturesCount)
+		}
+		if opts != nil && !reflect.DeepEqual(h.CRS, opts.CRS) {
+			t.Errorf("case %d: expected CRS %#v, got %#v", i, opts.CRS, h.CRS)
+		}
+		sortFeatures(fc)
+		sortFeatures(got)
+		if !reflect.DeepEqual(got, fc) {
+			t.Errorf("round trip %d failed: expected %#v, got %#v", i, fc, got)
+		}
+	}
+}
+
+func TestRoundTrip_WriteElevation(t *testing.T) {
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				Geometry: &geojson.LineString{
+					Points: []geojson.Point{
+						{X: 0, Y: 0, Elevation: 5, HasElevation: true},
+						{X: 1, Y: 1, Elevation: -5, HasElevation: true},
+					},
+				},
+				Properties: map[string]interface{}{},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	err := Write(&buf, fc, nil)
+	if err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	got, h, err := ReadAll(&buf)
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if !h.HasZ || h.GeometryType != LineString {
+		t.Errorf("unexpected header %#v", h)
+	}
+	if !reflect.DeepEqual(got, fc) {
+		t.Errorf("expected %#v, got %#v", fc, got)
+	}
+}
+
+func TestReader_Search(t *testing.T) {
+	// A grid of points, one per integer coordinate.
+	fc := &geojson.FeatureCollection{}
+	for x := 0; x < 20; x++ {
+		for y := 0; y < 20; y++ {
+			fc.Features = append(fc.Features, geojson.Feature{
+				Geometry:   &geojson.Point{X: float64(x), Y: float64(y)},
+				Properties: map[string]interface{}{"id": float64(x*100 + y)},
+			})
+		}
+	}
+	var buf bytes.Buffer
+	err := Write(&buf, fc, &WriteOptions{IndexNodeSize: 4})
+	if err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+
+	cases := []struct {
+		bbox     BBox
+		expected []float64
+	}{
+		{BBox{2.5, 3.5, 4.5, 5}, []float64{304, 305, 404, 405}},
+		{BBox{7, 7, 7, 7}, []float64{707}},
+		{BBox{-10, -10, -1, -1}, nil},
+		{BBox{19, 18.5, 30, 30}, []float64{1919}},
+	}
+	for i, c := range cases {
+		features, err := r.Search(c.bbox)
+		if err != nil {
+			t.Errorf("case %d: search failed: %v", i, err)
+			continue
+		}
+		var ids []float64
+		for _, f := range features {
+			ids = append(ids, f.Properties["id"].(float64))
+		}
+		sort.Float64s(ids)
+		if !reflect.DeepEqual(ids, c.expected) {
+			t.Errorf("case %d: expected %v, got %v", i, c.expected, ids)
+		}
+	}
+
+	// Searching must not disturb sequential reads.
+	n := 0
+	for {
+		_, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read feature %d: %v", n, err)
+		}
+		n++
+	}
+	if n != len(fc.Features) {
+		t.Errorf("expected %d features, got %d", len(fc.Features), n)
+	}
+}
+
+func TestReader_SearchWithoutIndex(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, testFeatures(), &WriteOptions{NoIndex: true})
+	if err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	_, err = r.Search(BBox{0, 0, 1, 1})
+	if err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestNewReader_Invalid(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, testFeatures(), nil)
+	if err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	valid := buf.Bytes()
+	cases := [][]byte{
+		nil,
+		[]byte("not a flatgeobuf file"),
+		valid[:10],
+		valid[:20],
+	}
+	for i, c := range cases {
+		_, err := NewReader(bytes.NewReader(c))
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+
+	// Truncating the last feature must surface an error rather than EOF.
+	_, _, err = ReadAll(bytes.NewReader(valid[:len(valid)-3]))
+	if err == nil || err == io.EOF {
+		t.Errorf("expected truncation error, got %v", err)
+	}
+}
+
+func TestNewReader_CorruptHeader(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, testFeatures(), nil)
+	if err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	valid := buf.Bytes()
+	size := binary.LittleEndian.Uint32(valid[8:])
+	h, err := decodeHeader(valid[12 : 12+size])
+	if err != nil {
+		t.Fatalf("failed to decode header: %v", err)
+	}
+	// withHeader returns the file with its header changed by f.
+	withHeader := func(f func(h *Header)) []byte {
+		c := *h
+		f(&c)
+		var b bytes.Buffer
+		b.Write(valid[:8])
+		if err := writeSizePrefixed(&b, encodeHeader(&c)); err != nil {
+			t.Fatalf("failed to encode header: %v", err)
+		}
+		b.Write(valid[12+size:])
+		return b.Bytes()
+	}
+	cases := []struct {
+		b []byte
+		// Whether the error is found when reading without seeking, which
+		// relies on skipping the index.
+		streamed bool
+	}{
+		{withHeader(func(h *Header) { h.FeaturesCount = 1 << 63 }), true},
+		{withHeader(func(h *Header) { h.FeaturesCount = 1 << 40 }), true},
+		{withHeader(func(h *Header) { h.FeaturesCount, h.IndexNodeSize = 1<<40, 0 }), false},
+		{withHeader(func(h *Header) { h.IndexNodeSize = 1 }), true},
+	}
+	for i, c := range cases {
+		_, err := NewReader(bytes.NewReader(c.b))
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+		_, err = NewReader(struct{ io.Reader }{bytes.NewReader(c.b)})
+		if c.streamed && err == nil {
+			t.Errorf("case %d: expected error without seeking", i)
+		}
+	}
+}
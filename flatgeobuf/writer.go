@@ -0,0 +1,166 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/coords"
+)
+
+// WriteOptions controls the dataset-level information written to a file.
+type WriteOptions struct {
+	// Dataset name, title, and description.
+	Name        string
+	Title       string
+	Description string
+	// Coordinate reference system. Optional; RFC 7946 data is EPSG:4326.
+	CRS *CRS
+	// Branching factor of the spatial index. Defaults to 16 if zero.
+	IndexNodeSize uint16
+	// Whether to omit the spatial index.
+	NoIndex bool
+}
+
+// Write encodes fc as a FlatGeobuf file. If opts is nil, the zero
+// WriteOptions are used.
+//
+// Attribute columns are inferred from the features' properties: numbers
+// become Double columns, booleans Bool columns, strings String columns, and
+// anything else JSON columns. Z values are written if any point has an
+// elevation. Feature IDs are not preserved.
+//
+// Unless the index is disabled, features are written in Hilbert order of
+// their bounding box centers rather than in their original order, as
+// required by the spatial index.
+func Write(w io.Writer, fc *geojson.FeatureCollection, opts *WriteOptions) error {
+	if opts == nil {
+		opts = &WriteOptions{}
+	}
+	nodeSize := int(opts.IndexNodeSize)
+	if nodeSize == 0 {
+		nodeSize = defaultIndexNodeSize
+	}
+	if opts.NoIndex || len(fc.Features) == 0 {
+		nodeSize = 0
+	} else if nodeSize < 2 {
+		return fmt.Errorf("flatgeobuf: index node size must be at least 2")
+	}
+
+	h := &Header{
+		Name:          opts.Name,
+		Title:         opts.Title,
+		Description:   opts.Description,
+		CRS:           opts.CRS,
+		Columns:       inferColumns(fc.Features),
+		FeaturesCount: uint64(len(fc.Features)),
+		IndexNodeSize: uint16(nodeSize),
+	}
+	extent := emptyBBox
+	type item struct {
+		data    []byte
+		bbox    BBox
+		hilbert uint32
+	}
+	items := make([]item, len(fc.Features))
+	for i := range fc.Features {
+		g := fc.Features[i].Geometry
+		coords.Each(g, func(p *geojson.Point) {
+			h.HasZ = h.HasZ || p.HasElevation
+		})
+		gt := geometryTypeOf(g)
+		if i == 0 {
+			h.GeometryType = gt
+		} else if h.GeometryType != gt {
+			h.GeometryType = Unknown
+		}
+		items[i].bbox = bboxOf(g)
+		extent.expand(items[i].bbox)
+	}
+	for i := range fc.Features {
+		data, err := encodeFeature(&fc.Features[i], h.Columns, h.HasZ)
+		if err != nil {
+			return fmt.Errorf("flatgeobuf: feature %d: %v", i, err)
+		}
+		items[i].data = data
+	}
+	if !extent.empty() {
+		h.Envelope = []float64{extent.MinX, extent.MinY, extent.MaxX, extent.MaxY}
+	}
+
+	var index []byte
+	if nodeSize > 0 {
+		for i := range items {
+			items[i].hilbert = hilbertValue(items[i].bbox, extent)
+		}
+		sort.SliceStable(items, func(i, j int) bool { return items[i].hilbert < items[j].hilbert })
+		leaves := make([]nodeItem, len(items))
+		var offset uint64
+		for i, it := range items {
+			leaves[i] = nodeItem{bbox: it.bbox, offset: offset}
+			offset += uint64(4 + len(it.data))
+		}
+		index = buildIndex(leaves, nodeSize)
+	}
+
+	_, err := w.Write(magic[:])
+	if err != nil {
+		return err
+	}
+	err = writeSizePrefixed(w, encodeHeader(h))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(index)
+	if err != nil {
+		return err
+	}
+	for _, it := range items {
+		err := writeSizePrefixed(w, it.data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeSizePrefixed(w io.Writer, b []byte) error {
+	var prefix [4]byte
+	binary.LittleEndian.PutUint32(prefix[:], uint32(len(b)))
+	_, err := w.Write(prefix[:])
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func geometryTypeOf(g geojson.Geometry) GeometryType {
+	switch g.(type) {
+	case *geojson.Point:
+		return Point
+	case *geojson.MultiPoint:
+		return MultiPoint
+	case *geojson.LineString:
+		return LineString
+	case *geojson.MultiLineString:
+		return MultiLineString
+	case *geojson.Polygon:
+		return Polygon
+	case *geojson.MultiPolygon:
+		return MultiPolygon
+	case *geojson.GeometryCollection:
+		return GeometryCollection
+	}
+	return Unknown
+}
+
+func bboxOf(g geojson.Geometry) BBox {
+	b := emptyBBox
+	coords.Each(g, func(p *geojson.Point) {
+		b.expand(BBox{p.X, p.Y, p.X, p.Y})
+	})
+	return b
+}
@@ -0,0 +1,364 @@
+package geoarrow
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/coords"
+)
+
+// FromFeatureCollection converts fc to columnar form.
+//
+// The geometry type is taken from the features, promoting single geometries
+// to their multi variant if both occur. Elevations are kept only if every
+// position has one. Numeric properties become Float64 columns, booleans
+// Bool columns, and strings String columns; properties with mixed or
+// structured values become JSON columns. Missing and null properties are
+// stored as nulls.
+func FromFeatureCollection(fc *geojson.FeatureCollection) (*FeatureCollection, error) {
+	t, err := geometryType(fc.Features)
+	if err != nil {
+		return nil, fmt.Errorf("geoarrow: %v", err)
+	}
+	geometries := make([]geojson.Geometry, len(fc.Features))
+	for i := range fc.Features {
+		geometries[i] = fc.Features[i].Geometry
+	}
+	c := &FeatureCollection{
+		Len: len(fc.Features),
+		Geometry: GeometryColumn{
+			Type:  t,
+			HasZ:  coords.HasElevation(geometries...),
+			Valid: make([]bool, 0, len(fc.Features)),
+		},
+	}
+	levels := make([][]int32, len(layouts[t].children))
+	for i := range levels {
+		levels[i] = []int32{0}
+	}
+	c.Geometry.setLevels(levels)
+	for i := range fc.Features {
+		f := &fc.Features[i]
+		if f.ID != "" && c.IDs == nil {
+			c.IDs = make([]string, len(fc.Features))
+		}
+		if c.IDs != nil {
+			c.IDs[i] = f.ID
+		}
+		c.Geometry.append(f.Geometry)
+	}
+	if len(c.Geometry.X) > math.MaxInt32 {
+		return nil, fmt.Errorf("geoarrow: too many vertices: %d", len(c.Geometry.X))
+	}
+	c.Columns, err = fromProperties(fc.Features)
+	if err != nil {
+		return nil, fmt.Errorf("geoarrow: %v", err)
+	}
+	return c, nil
+}
+
+// ToFeatureCollection converts c back to GeoJSON features. Null property
+// values are omitted from the feature properties.
+func ToFeatureCollection(c *FeatureCollection) (*geojson.FeatureCollection, error) {
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("geoarrow: %v", err)
+	}
+	fc := &geojson.FeatureCollection{Features: make([]geojson.Feature, c.Len)}
+	for i := range fc.Features {
+		f := &fc.Features[i]
+		if c.IDs != nil {
+			f.ID = c.IDs[i]
+		}
+		f.Geometry = c.Geometry.geometry(i)
+		f.Properties = map[string]interface{}{}
+		for j := range c.Columns {
+			col := &c.Columns[j]
+			if col.Valid != nil && !col.Valid[i] {
+				continue
+			}
+			v, err := col.value(i)
+			if err != nil {
+				return nil, fmt.Errorf("geoarrow: row %d: column %q: %v", i, col.Name, err)
+			}
+			f.Properties[col.Name] = v
+		}
+	}
+	return fc, nil
+}
+
+// geometryType returns the type of a column that can hold every geometry
+// in features. Columns without any geometry are Point columns.
+func geometryType(features []geojson.Feature) (GeometryType, error) {
+	var t GeometryType
+	for i, f := range features {
+		if f.Geometry == nil {
+			continue
+		}
+		ft, ok := typeOf(f.Geometry)
+		if !ok {
+			return 0, fmt.Errorf("feature %d: unsupported geometry type: %T", i, f.Geometry)
+		}
+		switch {
+		case t == 0 || t == ft:
+			t = ft
+		case multi(t) == multi(ft):
+			t = multi(t)
+		default:
+			return 0, fmt.Errorf("feature %d: cannot store %v in a %v column", i, ft, t)
+		}
+	}
+	if t == 0 {
+		t = Point
+	}
+	return t, nil
+}
+
+func typeOf(g geojson.Geometry) (GeometryType, bool) {
+	switch g.(type) {
+	case *geojson.Point:
+		return Point, true
+	case *geojson.LineString:
+		return LineString, true
+	case *geojson.Polygon:
+		return Polygon, true
+	case *geojson.MultiPoint:
+		return MultiPoint, true
+	case *geojson.MultiLineString:
+		return MultiLineString, true
+	case *geojson.MultiPolygon:
+		return MultiPolygon, true
+	}
+	return 0, false
+}
+
+func multi(t GeometryType) GeometryType {
+	switch t {
+	case Point:
+		return MultiPoint
+	case LineString:
+		return MultiLineString
+	case Polygon:
+		return MultiPolygon
+	}
+	return t
+}
+
+// append adds g as a new row. g must be nil or have a type that the column
+// can hold.
+func (c *GeometryColumn) append(g geojson.Geometry) {
+	c.Valid = append(c.Valid, g != nil)
+	switch c.Type {
+	case Point:
+		if p, ok := g.(*geojson.Point); ok {
+			c.vertices([]geojson.Point{*p})
+		} else {
+			c.vertices([]geojson.Point{{X: math.NaN(), Y: math.NaN(), Elevation: math.NaN()}})
+		}
+	case LineString:
+		if ls, ok := g.(*geojson.LineString); ok {
+			c.vertices(ls.Points)
+		}
+		c.GeomOffsets = append(c.GeomOffsets, int32(len(c.X)))
+	case MultiPoint:
+		switch t := g.(type) {
+		case *geojson.Point:
+			c.vertices([]geojson.Point{*t})
+		case *geojson.MultiPoint:
+			c.vertices(t.Points)
+		}
+		c.GeomOffsets = append(c.GeomOffsets, int32(len(c.X)))
+	case Polygon:
+		if p, ok := g.(*geojson.Polygon); ok {
+			c.rings(p.Rings)
+		}
+		c.GeomOffsets = append(c.GeomOffsets, int32(len(c.RingOffsets)-1))
+	case MultiLineString:
+		var lines []geojson.LineString
+		switch t := g.(type) {
+		case *geojson.LineString:
+			lines = []geojson.LineString{*t}
+		case *geojson.MultiLineString:
+			lines = t.Lines
+		}
+		for _, ls := range lines {
+			c.vertices(ls.Points)
+			c.PartOffsets = append(c.PartOffsets, int32(len(c.X)))
+		}
+		c.GeomOffsets = append(c.GeomOffsets, int32(len(c.PartOffsets)-1))
+	case MultiPolygon:
+		var polygons []geojson.Polygon
+		switch t := g.(type) {
+		case *geojson.Polygon:
+			polygons = []geojson.Polygon{*t}
+		case *geojson.MultiPolygon:
+			polygons = t.Polygons
+		}
+		for _, p := range polygons {
+			c.rings(p.Rings)
+			c.PartOffsets = append(c.PartOffsets, int32(len(c.RingOffsets)-1))
+		}
+		c.GeomOffsets = append(c.GeomOffsets, int32(len(c.PartOffsets)-1))
+	}
+}
+
+func (c *GeometryColumn) vertices(points []geojson.Point) {
+	for _, p := range points {
+		c.X = append(c.X, p.X)
+		c.Y = append(c.Y, p.Y)
+		if c.HasZ {
+			c.Z = append(c.Z, p.Elevation)
+		}
+	}
+}
+
+func (c *GeometryColumn) rings(rings []geojson.LineString) {
+	for _, ring := range rings {
+		c.vertices(ring.Points)
+		c.RingOffsets = append(c.RingOffsets, int32(len(c.X)))
+	}
+}
+
+// geometry returns row i, which must be in range.
+func (c *GeometryColumn) geometry(i int) geojson.Geometry {
+	if c.Valid != nil && !c.Valid[i] {
+		return nil
+	}
+	switch c.Type {
+	case Point:
+		p := c.points(int32(i), int32(i+1))[0]
+		return &p
+	case LineString:
+		return &geojson.LineString{Points: c.points(c.GeomOffsets[i], c.GeomOffsets[i+1])}
+	case MultiPoint:
+		return &geojson.MultiPoint{Points: c.points(c.GeomOffsets[i], c.GeomOffsets[i+1])}
+	case Polygon:
+		return &geojson.Polygon{Rings: c.lines(c.RingOffsets, c.GeomOffsets[i], c.GeomOffsets[i+1])}
+	case MultiLineString:
+		return &geojson.MultiLineString{Lines: c.lines(c.PartOffsets, c.GeomOffsets[i], c.GeomOffsets[i+1])}
+	case MultiPolygon:
+		mp := &geojson.MultiPolygon{}
+		for j := c.GeomOffsets[i]; j < c.GeomOffsets[i+1]; j++ {
+			rings := c.lines(c.RingOffsets, c.PartOffsets[j], c.PartOffsets[j+1])
+			mp.Polygons = append(mp.Polygons, geojson.Polygon{Rings: rings})
+		}
+		return mp
+	}
+	return nil
+}
+
+func (c *GeometryColumn) points(start, end int32) []geojson.Point {
+	points := make([]geojson.Point, 0, end-start)
+	for j := start; j < end; j++ {
+		p := geojson.Point{X: c.X[j], Y: c.Y[j]}
+		if c.HasZ {
+			p.Elevation = c.Z[j]
+			p.HasElevation = true
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+// lines returns the lines from start to end, whose vertices are indexed by
+// offsets.
+func (c *GeometryColumn) lines(offsets []int32, start, end int32) []geojson.LineString {
+	lines := make([]geojson.LineString, 0, end-start)
+	for j := start; j < end; j++ {
+		lines = append(lines, geojson.LineString{Points: c.points(offsets[j], offsets[j+1])})
+	}
+	return lines
+}
+
+// nullColumn marks a property that has only been seen with null values.
+const nullColumn ColumnType = -1
+
+func fromProperties(features []geojson.Feature) ([]Column, error) {
+	types := map[string]ColumnType{}
+	for _, f := range features {
+		for k, v := range f.Properties {
+			t := JSON
+			switch v.(type) {
+			case nil:
+				t = nullColumn
+			case float64:
+				t = Float64
+			case bool:
+				t = Bool
+			case string:
+				t = String
+			}
+			prev, seen := types[k]
+			switch {
+			case !seen || prev == nullColumn:
+				types[k] = t
+			case t != nullColumn && t != prev:
+				types[k] = JSON
+			}
+		}
+	}
+	names := make([]string, 0, len(types))
+	for k := range types {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	columns := make([]Column, len(names))
+	for i, name := range names {
+		col := &columns[i]
+		col.Name = name
+		col.Type = types[name]
+		if col.Type == nullColumn {
+			col.Type = JSON
+		}
+		col.Valid = make([]bool, len(features))
+		switch col.Type {
+		case Float64:
+			col.Float64s = make([]float64, len(features))
+		case Bool:
+			col.Bools = make([]bool, len(features))
+		default:
+			col.Strings = make([]string, len(features))
+		}
+		for j, f := range features {
+			v := f.Properties[name]
+			if v == nil {
+				continue
+			}
+			col.Valid[j] = true
+			switch col.Type {
+			case Float64:
+				col.Float64s[j] = v.(float64)
+			case Bool:
+				col.Bools[j] = v.(bool)
+			case String:
+				col.Strings[j] = v.(string)
+			case JSON:
+				b, err := json.Marshal(v)
+				if err != nil {
+					return nil, fmt.Errorf("feature %d: property %q: %v", j, name, err)
+				}
+				col.Strings[j] = string(b)
+			}
+		}
+	}
+	return columns, nil
+}
+
+// value returns row i, which must be in range.
+func (c *Column) value(i int) (interface{}, error) {
+	switch c.Type {
+	case Float64:
+		return c.Float64s[i], nil
+	case Bool:
+		return c.Bools[i], nil
+	case String:
+		return c.Strings[i], nil
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(c.Strings[i]), &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
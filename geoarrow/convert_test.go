@@ -0,0 +1,228 @@
+package geoarrow
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestFromFeatureCollection(t *testing.T) {
+	square := geojson.LineString{
+		Points: []geojson.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 0}},
+	}
+	hole := geojson.LineString{
+		Points: []geojson.Point{{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 1}, {X: 1, Y: 1}},
+	}
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				ID:       "a",
+				Geometry: &geojson.Polygon{Rings: []geojson.LineString{square, hole}},
+				Properties: map[string]interface{}{
+					"name":  "first",
+					"count": float64(1),
+					"mixed": float64(2),
+					"empty": nil,
+				},
+			},
+			{
+				Properties: map[string]interface{}{
+					"ok":    true,
+					"mixed": "two",
+				},
+			},
+			{
+				Geometry: &geojson.MultiPolygon{Polygons: []geojson.Polygon{
+					{Rings: []geojson.LineString{square}},
+					{Rings: []geojson.LineString{hole}},
+				}},
+				Properties: map[string]interface{}{
+					"tags": []interface{}{"x"},
+				},
+			},
+		},
+	}
+	c, err := FromFeatureCollection(fc)
+	if err != nil {
+		t.Fatalf("failed to convert: %v", err)
+	}
+	expected := &FeatureCollection{
+		Len: 3,
+		IDs: []string{"a", "", ""},
+		Geometry: GeometryColumn{
+			Type:        MultiPolygon,
+			X:           []float64{0, 4, 4, 0, 1, 1, 2, 1, 0, 4, 4, 0, 1, 1, 2, 1},
+			Y:           []float64{0, 0, 4, 0, 1, 2, 1, 1, 0, 0, 4, 0, 1, 2, 1, 1},
+			GeomOffsets: []int32{0, 1, 1, 3},
+			PartOffsets: []int32{0, 2, 3, 4},
+			RingOffsets: []int32{0, 4, 8, 12, 16},
+			Valid:       []bool{true, false, true},
+		},
+		Columns: []Column{
+			{Name: "count", Type: Float64, Float64s: []float64{1, 0, 0}, Valid: []bool{true, false, false}},
+			{Name: "empty", Type: JSON, Strings: []string{"", "", ""}, Valid: []bool{false, false, false}},
+			{Name: "mixed", Type: JSON, Strings: []string{"2", `"two"`, ""}, Valid: []bool{true, true, false}},
+			{Name: "name", Type: String, Strings: []string{"first", "", ""}, Valid: []bool{true, false, false}},
+			{Name: "ok", Type: Bool, Bools: []bool{false, true, false}, Valid: []bool{false, true, false}},
+			{Name: "tags", Type: JSON, Strings: []string{"", "", `["x"]`}, Valid: []bool{false, false, true}},
+		},
+	}
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("expected %#v, got %#v", expected, c)
+	}
+
+	back, err := ToFeatureCollection(c)
+	if err != nil {
+		t.Fatalf("failed to convert back: %v", err)
+	}
+	fc.Features[0].Geometry = &geojson.MultiPolygon{Polygons: []geojson.Polygon{
+		{Rings: []geojson.LineString{square, hole}},
+	}}
+	delete(fc.Features[0].Properties, "empty")
+	if !reflect.DeepEqual(back, fc) {
+		t.Errorf("expected %#v, got %#v", fc, back)
+	}
+}
+
+func TestFromFeatureCollection_Types(t *testing.T) {
+	line := func(points ...geojson.Point) geojson.LineString {
+		return geojson.LineString{Points: points}
+	}
+	cases := []struct {
+		geometries []geojson.Geometry
+		expected   GeometryColumn
+	}{
+		{
+			[]geojson.Geometry{
+				&geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+				&geojson.Point{X: 4, Y: 5, Elevation: 6, HasElevation: true},
+			},
+			GeometryColumn{
+				Type: Point, HasZ: true,
+				X: []float64{1, 4}, Y: []float64{2, 5}, Z: []float64{3, 6},
+				Valid: []bool{true, true},
+			},
+		},
+		{
+			[]geojson.Geometry{
+				&geojson.LineString{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+				nil,
+				&geojson.LineString{Points: []geojson.Point{{X: 2, Y: 2}, {X: 3, Y: 3}, {X: 4, Y: 4}}},
+			},
+			GeometryColumn{
+				Type:        LineString,
+				X:           []float64{0, 1, 2, 3, 4},
+				Y:           []float64{0, 1, 2, 3, 4},
+				GeomOffsets: []int32{0, 2, 2, 5},
+				Valid:       []bool{true, false, true},
+			},
+		},
+		{
+			// Single points are promoted, and elevations are dropped
+			// unless every position has one.
+			[]geojson.Geometry{
+				&geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+				&geojson.MultiPoint{Points: []geojson.Point{{X: 3, Y: 4}, {X: 5, Y: 6}}},
+			},
+			GeometryColumn{
+				Type:        MultiPoint,
+				X:           []float64{1, 3, 5},
+				Y:           []float64{2, 4, 6},
+				GeomOffsets: []int32{0, 1, 3},
+				Valid:       []bool{true, true},
+			},
+		},
+		{
+			[]geojson.Geometry{
+				&geojson.MultiLineString{Lines: []geojson.LineString{
+					line(geojson.Point{X: 0, Y: 0}, geojson.Point{X: 1, Y: 1}),
+					line(geojson.Point{X: 2, Y: 2}, geojson.Point{X: 3, Y: 3}),
+				}},
+				&geojson.LineString{Points: []geojson.Point{{X: 4, Y: 4}, {X: 5, Y: 5}}},
+			},
+			GeometryColumn{
+				Type:        MultiLineString,
+				X:           []float64{0, 1, 2, 3, 4, 5},
+				Y:           []float64{0, 1, 2, 3, 4, 5},
+				GeomOffsets: []int32{0, 2, 3},
+				PartOffsets: []int32{0, 2, 4, 6},
+				Valid:       []bool{true, true},
+			},
+		},
+		{
+			[]geojson.Geometry{nil},
+			GeometryColumn{Type: Point, X: []float64{math.NaN()}, Y: []float64{math.NaN()}, Valid: []bool{false}},
+		},
+	}
+	for i, c := range cases {
+		fc := &geojson.FeatureCollection{}
+		for _, g := range c.geometries {
+			fc.Features = append(fc.Features, geojson.Feature{Geometry: g})
+		}
+		got, err := FromFeatureCollection(fc)
+		if err != nil {
+			t.Errorf("case %d: failed to convert: %v", i, err)
+			continue
+		}
+		// NaN never compares equal, so compare null points by pattern.
+		if c.expected.Type == Point && len(got.Geometry.X) == 1 && math.IsNaN(got.Geometry.X[0]) {
+			c.expected.X, c.expected.Y = got.Geometry.X, got.Geometry.Y
+		}
+		if !reflect.DeepEqual(got.Geometry, c.expected) {
+			t.Errorf("case %d: expected %#v, got %#v", i, c.expected, got.Geometry)
+		}
+		back, err := ToFeatureCollection(got)
+		if err != nil {
+			t.Errorf("case %d: failed to convert back: %v", i, err)
+			continue
+		}
+		if len(back.Features) != len(c.geometries) {
+			t.Errorf("case %d: expected %d features, got %d", i, len(c.geometries), len(back.Features))
+		}
+	}
+}
+
+func TestFromFeatureCollection_Invalid(t *testing.T) {
+	cases := [][]geojson.Geometry{
+		{&geojson.Point{}, &geojson.LineString{}},
+		{&geojson.Polygon{}, &geojson.MultiLineString{}},
+		{&geojson.GeometryCollection{}},
+	}
+	for i, c := range cases {
+		fc := &geojson.FeatureCollection{}
+		for _, g := range c {
+			fc.Features = append(fc.Features, geojson.Feature{Geometry: g})
+		}
+		if _, err := FromFeatureCollection(fc); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestToFeatureCollection_Invalid(t *testing.T) {
+	cases := []*FeatureCollection{
+		{Len: 1, Geometry: GeometryColumn{Type: Point}},
+		{Len: 1, Geometry: GeometryColumn{Type: LineString, X: []float64{0}, Y: []float64{0}, GeomOffsets: []int32{0, 2}}},
+		{Len: 1, Geometry: GeometryColumn{Type: LineString, X: []float64{0}, Y: []float64{0}, GeomOffsets: []int32{1, 1}}},
+		{Len: 2, Geometry: GeometryColumn{Type: LineString, GeomOffsets: []int32{0, 1, 0}, X: []float64{0}, Y: []float64{0}}},
+		{Len: 1, Geometry: GeometryColumn{Type: Point, X: []float64{0}, Y: []float64{0}, HasZ: true}},
+		{Len: 1, IDs: []string{}, Geometry: GeometryColumn{Type: Point, X: []float64{0}, Y: []float64{0}}},
+		{
+			Len:      1,
+			Geometry: GeometryColumn{Type: Point, X: []float64{0}, Y: []float64{0}},
+			Columns:  []Column{{Name: "a", Type: Float64}},
+		},
+		{
+			Len:      1,
+			Geometry: GeometryColumn{Type: Point, X: []float64{0}, Y: []float64{0}},
+			Columns:  []Column{{Name: "a", Type: JSON, Strings: []string{"{"}}},
+		},
+	}
+	for i, c := range cases {
+		if _, err := ToFeatureCollection(c); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
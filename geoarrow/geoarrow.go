@@ -0,0 +1,245 @@
+// Package geoarrow stores GeoJSON features in columnar form, following the
+// GeoArrow native encodings, and reads and writes them as Arrow IPC streams.
+//
+// A FeatureCollection holds a single geometry column plus one typed column
+// per property. Coordinates are kept in flat X, Y and optionally Z buffers,
+// and each level of nesting (geometries to parts, parts to rings, rings to
+// vertices) is described by an array of int32 offsets into the level below,
+// as in Arrow list arrays. Row i of a level spans offsets[i] to
+// offsets[i+1].
+//
+// Every geometry in a column has the same type. Collections mixing a type
+// with its multi variant are stored as the multi type; other mixtures, and
+// GeometryCollections, are not supported.
+//
+// Write produces an Arrow IPC stream that Arrow libraries can read directly,
+// with the geometry column tagged with its geoarrow extension name. No Arrow
+// library is needed to produce or consume it here.
+//
+// See https://geoarrow.org/format.html for the encodings and
+// https://arrow.apache.org/docs/format/Columnar.html for the IPC format.
+package geoarrow
+
+import (
+	"fmt"
+)
+
+// A GeometryType is the type of every geometry in a GeometryColumn.
+type GeometryType int
+
+// Geometry types with a GeoArrow native encoding.
+const (
+	Point GeometryType = iota + 1
+	LineString
+	Polygon
+	MultiPoint
+	MultiLineString
+	MultiPolygon
+)
+
+// layout describes how a geometry type is encoded: its extension name and
+// the names of the nested list fields, outermost first. The last name is
+// that of the coordinate struct.
+type layout struct {
+	extension string
+	children  []string
+}
+
+var layouts = map[GeometryType]layout{
+	Point:           {"geoarrow.point", nil},
+	LineString:      {"geoarrow.linestring", []string{"vertices"}},
+	Polygon:         {"geoarrow.polygon", []string{"rings", "vertices"}},
+	MultiPoint:      {"geoarrow.multipoint", []string{"points"}},
+	MultiLineString: {"geoarrow.multilinestring", []string{"linestrings", "vertices"}},
+	MultiPolygon:    {"geoarrow.multipolygon", []string{"polygons", "rings", "vertices"}},
+}
+
+var geometryTypeNames = map[GeometryType]string{
+	Point:           "Point",
+	LineString:      "LineString",
+	Polygon:         "Polygon",
+	MultiPoint:      "MultiPoint",
+	MultiLineString: "MultiLineString",
+	MultiPolygon:    "MultiPolygon",
+}
+
+func (t GeometryType) String() string {
+	if name, ok := geometryTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("GeometryType(%d)", int(t))
+}
+
+// A FeatureCollection is a columnar collection of Len features.
+type FeatureCollection struct {
+	Len int
+	// IDs holds the ID of each feature, or is nil if no feature has one.
+	// Empty IDs are stored as nulls.
+	IDs      []string
+	Geometry GeometryColumn
+	Columns  []Column
+}
+
+// A GeometryColumn holds one geometry per row, all of the same Type.
+//
+// GeomOffsets indexes the children of each row: vertices for LineString and
+// MultiPoint, rings for Polygon, linestrings for MultiLineString, and
+// polygons for MultiPolygon. PartOffsets indexes the vertices of each
+// linestring of a MultiLineString, or the rings of each polygon of a
+// MultiPolygon. RingOffsets indexes the vertices of each ring of a Polygon
+// or MultiPolygon. Unused offset arrays are nil; Point columns use none and
+// have one vertex per row.
+type GeometryColumn struct {
+	Type GeometryType
+	// HasZ reports whether Z holds elevations.
+	HasZ    bool
+	X, Y, Z []float64
+
+	GeomOffsets []int32
+	PartOffsets []int32
+	RingOffsets []int32
+
+	// Valid reports which rows have a geometry. If nil, all rows do. Null
+	// rows still occupy a vertex in Point columns.
+	Valid []bool
+}
+
+// A ColumnType is the type of a property column.
+type ColumnType int
+
+// Property column types. JSON columns hold properties with mixed or
+// structured values, encoded as JSON text.
+const (
+	Float64 ColumnType = iota
+	Bool
+	String
+	JSON
+)
+
+var columnTypeNames = map[ColumnType]string{
+	Float64: "Float64",
+	Bool:    "Bool",
+	String:  "String",
+	JSON:    "JSON",
+}
+
+func (t ColumnType) String() string {
+	if name, ok := columnTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("ColumnType(%d)", int(t))
+}
+
+// A Column holds one property for every row. Only the slice matching Type
+// is used: Float64s, Bools, or Strings for both String and JSON columns.
+type Column struct {
+	Name     string
+	Type     ColumnType
+	Float64s []float64
+	Bools    []bool
+	Strings  []string
+	// Valid reports which rows have a value. If nil, all rows do.
+	Valid []bool
+}
+
+// levels returns the offset arrays used by the geometry type, outermost
+// first.
+func (g *GeometryColumn) levels() [][]int32 {
+	switch g.Type {
+	case LineString, MultiPoint:
+		return [][]int32{g.GeomOffsets}
+	case Polygon:
+		return [][]int32{g.GeomOffsets, g.RingOffsets}
+	case MultiLineString:
+		return [][]int32{g.GeomOffsets, g.PartOffsets}
+	case MultiPolygon:
+		return [][]int32{g.GeomOffsets, g.PartOffsets, g.RingOffsets}
+	}
+	return nil
+}
+
+// setLevels is the inverse of levels.
+func (g *GeometryColumn) setLevels(levels [][]int32) {
+	switch g.Type {
+	case LineString, MultiPoint:
+		g.GeomOffsets = levels[0]
+	case Polygon:
+		g.GeomOffsets, g.RingOffsets = levels[0], levels[1]
+	case MultiLineString:
+		g.GeomOffsets, g.PartOffsets = levels[0], levels[1]
+	case MultiPolygon:
+		g.GeomOffsets, g.PartOffsets, g.RingOffsets = levels[0], levels[1], levels[2]
+	}
+}
+
+// validate checks that the lengths and offsets of c are consistent.
+func (c *FeatureCollection) validate() error {
+	if c.Len < 0 {
+		return fmt.Errorf("negative length %d", c.Len)
+	}
+	if c.IDs != nil && len(c.IDs) != c.Len {
+		return fmt.Errorf("%d IDs for %d rows", len(c.IDs), c.Len)
+	}
+	if err := c.Geometry.validate(c.Len); err != nil {
+		return fmt.Errorf("geometry: %v", err)
+	}
+	for i := range c.Columns {
+		col := &c.Columns[i]
+		var n int
+		switch col.Type {
+		case Float64:
+			n = len(col.Float64s)
+		case Bool:
+			n = len(col.Bools)
+		case String, JSON:
+			n = len(col.Strings)
+		default:
+			return fmt.Errorf("column %q: unknown type %v", col.Name, col.Type)
+		}
+		if n != c.Len {
+			return fmt.Errorf("column %q: %d values for %d rows", col.Name, n, c.Len)
+		}
+		if col.Valid != nil && len(col.Valid) != c.Len {
+			return fmt.Errorf("column %q: %d validity flags for %d rows", col.Name, len(col.Valid), c.Len)
+		}
+	}
+	return nil
+}
+
+func (g *GeometryColumn) validate(n int) error {
+	l, ok := layouts[g.Type]
+	if !ok {
+		return fmt.Errorf("unknown type %v", g.Type)
+	}
+	if len(g.Y) != len(g.X) {
+		return fmt.Errorf("%d X coordinates but %d Y coordinates", len(g.X), len(g.Y))
+	}
+	if g.HasZ && len(g.Z) != len(g.X) {
+		return fmt.Errorf("%d X coordinates but %d Z coordinates", len(g.X), len(g.Z))
+	}
+	if g.Valid != nil && len(g.Valid) != n {
+		return fmt.Errorf("%d validity flags for %d rows", len(g.Valid), n)
+	}
+	levels := g.levels()
+	if len(levels) != len(l.children) {
+		return fmt.Errorf("%v column has %d offset levels", g.Type, len(levels))
+	}
+	for i, offsets := range levels {
+		if len(offsets) != n+1 {
+			return fmt.Errorf("offset level %d: %d offsets for %d rows", i, len(offsets), n)
+		}
+		if offsets[0] != 0 {
+			return fmt.Errorf("offset level %d: first offset is %d", i, offsets[0])
+		}
+		for j := 1; j < len(offsets); j++ {
+			if offsets[j] < offsets[j-1] {
+				return fmt.Errorf("offset level %d: offsets decrease at %d", i, j)
+			}
+		}
+		n = int(offsets[len(offsets)-1])
+	}
+	if n != len(g.X) {
+		return fmt.Errorf("%d vertices referenced but %d present", n, len(g.X))
+	}
+	return nil
+}
@@ -0,0 +1,528 @@
+package geoarrow
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strings"
+
+	"github.com/bsidhom/geojson/internal/flatbuf"
+)
+
+// Read reads an Arrow IPC stream, such as one produced by Write, into a
+// FeatureCollection. Record batches are concatenated.
+//
+// The stream must have exactly one field with a geoarrow native extension
+// type. A string field named "id" supplies the feature IDs, and double,
+// boolean, and string fields become property columns. Other field types,
+// dictionary encoding, and compressed batches are not supported.
+func Read(r io.Reader) (*FeatureCollection, error) {
+	s, err := readSchema(r)
+	if err != nil {
+		return nil, fmt.Errorf("geoarrow: %v", err)
+	}
+	for {
+		headerType, header, body, err := readMessage(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("geoarrow: %v", err)
+		}
+		switch headerType {
+		case headerRecordBatch:
+			if err := s.readBatch(header, body); err != nil {
+				return nil, fmt.Errorf("geoarrow: record batch: %v", err)
+			}
+		case headerDictionary:
+			return nil, fmt.Errorf("geoarrow: dictionary batches are not supported")
+		default:
+			return nil, fmt.Errorf("geoarrow: unexpected message type %d", headerType)
+		}
+	}
+	if err := s.c.validate(); err != nil {
+		return nil, fmt.Errorf("geoarrow: %v", err)
+	}
+	return s.c, nil
+}
+
+// readMessage reads an encapsulated message, returning io.EOF at the end of
+// the stream. Streams from before Arrow 0.15, which lack the continuation
+// marker, are also accepted.
+func readMessage(r io.Reader) (uint8, flatbuf.TableRef, []byte, error) {
+	var none flatbuf.TableRef
+	var prefix [4]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return 0, none, nil, fmt.Errorf("truncated message length")
+		}
+		return 0, none, nil, err
+	}
+	n := binary.LittleEndian.Uint32(prefix[:])
+	if n == continuation {
+		if _, err := io.ReadFull(r, prefix[:]); err != nil {
+			return 0, none, nil, fmt.Errorf("truncated message length")
+		}
+		n = binary.LittleEndian.Uint32(prefix[:])
+	}
+	if n == 0 {
+		return 0, none, nil, io.EOF
+	}
+	if n > math.MaxInt32 {
+		return 0, none, nil, fmt.Errorf("message length %d too large", n)
+	}
+	metadata, err := readFull(r, int64(n))
+	if err != nil {
+		return 0, none, nil, fmt.Errorf("truncated message: %v", err)
+	}
+	m := flatbuf.NewReader(metadata).Root()
+	headerType := m.Uint8(messageHeaderType, 0)
+	header, ok := m.Table(messageHeader)
+	bodyLength := m.Uint64(messageBodyLength, 0)
+	if err := m.Err(); err != nil {
+		return 0, none, nil, err
+	}
+	if !ok {
+		return 0, none, nil, fmt.Errorf("message has no header")
+	}
+	if bodyLength > math.MaxInt32 {
+		return 0, none, nil, fmt.Errorf("message body length %d too large", bodyLength)
+	}
+	body, err := readFull(r, int64(bodyLength))
+	if err != nil {
+		return 0, none, nil, fmt.Errorf("truncated message body: %v", err)
+	}
+	return headerType, header, body, nil
+}
+
+// readFull reads exactly n bytes from r. The lengths of messages come from
+// the stream itself, so rather than allocating n bytes up front, the buffer
+// grows as data arrives.
+func readFull(r io.Reader, n int64) ([]byte, error) {
+	b, err := ioutil.ReadAll(io.LimitReader(r, n))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) < n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return b, nil
+}
+
+// A schemaField is a decoded Field table.
+type schemaField struct {
+	name     string
+	typeType uint8
+	typ      flatbuf.TableRef
+	children []schemaField
+	metadata map[string]string
+	// dictionary reports whether the field is dictionary encoded.
+	dictionary bool
+}
+
+func decodeField(t flatbuf.TableRef) schemaField {
+	f := schemaField{
+		name:     t.String(fieldName),
+		typeType: t.Uint8(fieldTypeType, 0),
+		metadata: map[string]string{},
+	}
+	typ, ok := t.Table(fieldType)
+	if !ok {
+		f.typeType = 0
+	}
+	f.typ = typ
+	for _, c := range t.Tables(fieldChildren) {
+		f.children = append(f.children, decodeField(c))
+	}
+	for _, kv := range t.Tables(fieldMetadata) {
+		f.metadata[kv.String(keyValueKey)] = kv.String(keyValueValue)
+	}
+	_, f.dictionary = t.Table(fieldDictionary)
+	return f
+}
+
+// isDouble reports whether f is a 64-bit floating point field.
+func (f *schemaField) isDouble() bool {
+	return f.typeType == typeFloatingPoint && f.typ.Uint16(0, 0) == precisionDouble
+}
+
+// Kinds of top-level fields.
+const (
+	kindID = iota
+	kindGeometry
+	kindColumn
+)
+
+// A schema maps the top-level fields of a stream to the collection being
+// read.
+type schema struct {
+	names []string
+	kinds []int
+	// columns holds the index into c.Columns of each property field.
+	columns []int
+	c       *FeatureCollection
+}
+
+func readSchema(r io.Reader) (*schema, error) {
+	headerType, header, _, err := readMessage(r)
+	if err == io.EOF {
+		return nil, fmt.Errorf("missing schema")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if headerType != headerSchema {
+		return nil, fmt.Errorf("stream starts with message type %d, not a schema", headerType)
+	}
+	if header.Uint16(schemaEndianness, 0) != 0 {
+		return nil, fmt.Errorf("big-endian streams are not supported")
+	}
+	var fields []schemaField
+	for _, t := range header.Tables(schemaFields) {
+		fields = append(fields, decodeField(t))
+	}
+	if err := header.Err(); err != nil {
+		return nil, err
+	}
+	s := &schema{c: &FeatureCollection{}}
+	hasGeometry := false
+	for i := range fields {
+		f := &fields[i]
+		if f.dictionary {
+			return nil, fmt.Errorf("field %q: dictionary encoding is not supported", f.name)
+		}
+		kind := kindColumn
+		ext := f.metadata[extensionName]
+		switch {
+		case strings.HasPrefix(ext, "geoarrow."):
+			if hasGeometry {
+				return nil, fmt.Errorf("field %q: multiple geometry fields", f.name)
+			}
+			g, err := geometryLayout(f)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %v", f.name, err)
+			}
+			s.c.Geometry = *g
+			hasGeometry = true
+			kind = kindGeometry
+		case f.name == idField && f.typeType == typeUtf8 && ext == "" && s.c.IDs == nil:
+			s.c.IDs = []string{}
+			kind = kindID
+		}
+		s.names = append(s.names, f.name)
+		s.kinds = append(s.kinds, kind)
+		s.columns = append(s.columns, len(s.c.Columns))
+		if kind != kindColumn {
+			continue
+		}
+		col := Column{Name: f.name}
+		switch {
+		case f.isDouble():
+			col.Type = Float64
+		case f.typeType == typeBool:
+			col.Type = Bool
+		case f.typeType == typeUtf8 && ext == jsonExtension:
+			col.Type = JSON
+		case f.typeType == typeUtf8:
+			col.Type = String
+		default:
+			return nil, fmt.Errorf("field %q: unsupported type %d", f.name, f.typeType)
+		}
+		s.c.Columns = append(s.c.Columns, col)
+	}
+	if !hasGeometry {
+		return nil, fmt.Errorf("no geoarrow geometry field")
+	}
+	return s, nil
+}
+
+// geometryLayout returns an empty geometry column matching f, after
+// checking that f is nested as its extension type requires.
+func geometryLayout(f *schemaField) (*GeometryColumn, error) {
+	ext := f.metadata[extensionName]
+	g := &GeometryColumn{}
+	for t, l := range layouts {
+		if l.extension == ext {
+			g.Type = t
+		}
+	}
+	if g.Type == 0 {
+		return nil, fmt.Errorf("unsupported extension type %q", ext)
+	}
+	levels := make([][]int32, len(layouts[g.Type].children))
+	for i := range levels {
+		if f.typeType != typeList || len(f.children) != 1 {
+			return nil, fmt.Errorf("%s is not a list at depth %d", ext, i)
+		}
+		levels[i] = []int32{0}
+		f = &f.children[0]
+	}
+	if f.typeType != typeStruct {
+		return nil, fmt.Errorf("%s coordinates are not a struct", ext)
+	}
+	for _, c := range f.children {
+		if !c.isDouble() {
+			return nil, fmt.Errorf("%s coordinate %q is not a double", ext, c.name)
+		}
+	}
+	switch len(f.children) {
+	case 2:
+	case 3:
+		g.HasZ = f.children[2].name == "z"
+		if !g.HasZ {
+			return nil, fmt.Errorf("%s coordinate %q is not supported", ext, f.children[2].name)
+		}
+	default:
+		return nil, fmt.Errorf("%s has %d coordinate dimensions", ext, len(f.children))
+	}
+	g.setLevels(levels)
+	return g, nil
+}
+
+// A batchDecoder walks the field nodes and buffers of a record batch in
+// the order they were written.
+type batchDecoder struct {
+	nodes   [][]byte
+	buffers [][]byte
+	body    []byte
+}
+
+func (d *batchDecoder) node() (int, int, error) {
+	if len(d.nodes) == 0 {
+		return 0, 0, fmt.Errorf("too few field nodes")
+	}
+	n := d.nodes[0]
+	d.nodes = d.nodes[1:]
+	length := binary.LittleEndian.Uint64(n)
+	nulls := binary.LittleEndian.Uint64(n[8:])
+	if length > math.MaxInt32 || nulls > length {
+		return 0, 0, fmt.Errorf("invalid field node length %d with %d nulls", length, nulls)
+	}
+	return int(length), int(nulls), nil
+}
+
+func (d *batchDecoder) buffer() ([]byte, error) {
+	if len(d.buffers) == 0 {
+		return nil, fmt.Errorf("too few buffers")
+	}
+	b := d.buffers[0]
+	d.buffers = d.buffers[1:]
+	offset := binary.LittleEndian.Uint64(b)
+	length := binary.LittleEndian.Uint64(b[8:])
+	if offset > uint64(len(d.body)) || length > uint64(len(d.body))-offset {
+		return nil, fmt.Errorf("buffer at %d (+%d) out of range", offset, length)
+	}
+	return d.body[offset : offset+length], nil
+}
+
+// validity reads a node and its validity bitmap, checking that the node
+// has rows start to start+n. If valid is not nil, the flags of those rows
+// are appended to it.
+func (d *batchDecoder) validity(start, n int, valid *[]bool) error {
+	length, nulls, err := d.node()
+	if err != nil {
+		return err
+	}
+	if length < start+n {
+		return fmt.Errorf("field node has length %d, expected at least %d", length, start+n)
+	}
+	b, err := d.buffer()
+	if err != nil {
+		return err
+	}
+	if nulls > 0 && len(b) < (start+n+7)/8 {
+		return fmt.Errorf("validity bitmap too short")
+	}
+	if valid != nil {
+		for i := start; i < start+n; i++ {
+			*valid = append(*valid, nulls == 0 || b[i/8]&(1<<uint(i%8)) != 0)
+		}
+	}
+	return nil
+}
+
+// offsets reads n+1 offsets starting at start and appends the last n to
+// dst, rebased to continue from its last offset. It returns the range of
+// child rows referenced.
+func (d *batchDecoder) offsets(dst *[]int32, start, n int) (int, int, error) {
+	b, err := d.buffer()
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(b) < 4*(start+n+1) {
+		return 0, 0, fmt.Errorf("offsets buffer too short")
+	}
+	at := func(i int) int32 {
+		return int32(binary.LittleEndian.Uint32(b[4*(start+i):]))
+	}
+	first := at(0)
+	if first < 0 {
+		return 0, 0, fmt.Errorf("negative offset %d", first)
+	}
+	base := (*dst)[len(*dst)-1]
+	for i := 1; i <= n; i++ {
+		o := at(i)
+		if o < at(i-1) {
+			return 0, 0, fmt.Errorf("offsets decrease at %d", start+i)
+		}
+		if int64(base)+int64(o-first) > math.MaxInt32 {
+			return 0, 0, fmt.Errorf("too many values")
+		}
+		*dst = append(*dst, base+o-first)
+	}
+	return int(first), int(at(n) - first), nil
+}
+
+// float64s reads a double field and appends rows start to start+n.
+func (d *batchDecoder) float64s(dst *[]float64, valid *[]bool, start, n int) error {
+	if err := d.validity(start, n, valid); err != nil {
+		return err
+	}
+	b, err := d.buffer()
+	if err != nil {
+		return err
+	}
+	if len(b) < 8*(start+n) {
+		return fmt.Errorf("double buffer too short")
+	}
+	for i := start; i < start+n; i++ {
+		*dst = append(*dst, math.Float64frombits(binary.LittleEndian.Uint64(b[8*i:])))
+	}
+	return nil
+}
+
+// bools reads a boolean field and appends its first n rows.
+func (d *batchDecoder) bools(dst *[]bool, valid *[]bool, n int) error {
+	if err := d.validity(0, n, valid); err != nil {
+		return err
+	}
+	b, err := d.buffer()
+	if err != nil {
+		return err
+	}
+	if len(b) < (n+7)/8 {
+		return fmt.Errorf("boolean buffer too short")
+	}
+	for i := 0; i < n; i++ {
+		*dst = append(*dst, b[i/8]&(1<<uint(i%8)) != 0)
+	}
+	return nil
+}
+
+// strings reads a string field and appends its first n rows.
+func (d *batchDecoder) strings(dst *[]string, valid *[]bool, n int) error {
+	if err := d.validity(0, n, valid); err != nil {
+		return err
+	}
+	offsets, err := d.buffer()
+	if err != nil {
+		return err
+	}
+	data, err := d.buffer()
+	if err != nil {
+		return err
+	}
+	if len(offsets) < 4*(n+1) {
+		return fmt.Errorf("offsets buffer too short")
+	}
+	for i := 0; i < n; i++ {
+		start := int64(int32(binary.LittleEndian.Uint32(offsets[4*i:])))
+		end := int64(int32(binary.LittleEndian.Uint32(offsets[4*i+4:])))
+		if start < 0 || end < start || end > int64(len(data)) {
+			return fmt.Errorf("string %d at %d to %d out of range", i, start, end)
+		}
+		*dst = append(*dst, string(data[start:end]))
+	}
+	return nil
+}
+
+// geometry reads a geometry field and appends its first n rows.
+func (d *batchDecoder) geometry(g *GeometryColumn, n int) error {
+	if err := d.validity(0, n, &g.Valid); err != nil {
+		return err
+	}
+	levels := g.levels()
+	start := 0
+	for i := range levels {
+		if i > 0 {
+			if err := d.validity(start, n, nil); err != nil {
+				return err
+			}
+		}
+		var err error
+		start, n, err = d.offsets(&levels[i], start, n)
+		if err != nil {
+			return err
+		}
+	}
+	g.setLevels(levels)
+	if len(levels) > 0 {
+		if err := d.validity(start, n, nil); err != nil {
+			return err
+		}
+	}
+	coords := []*[]float64{&g.X, &g.Y}
+	if g.HasZ {
+		coords = append(coords, &g.Z)
+	}
+	for _, dst := range coords {
+		if err := d.float64s(dst, nil, start, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *schema) readBatch(t flatbuf.TableRef, body []byte) error {
+	length := t.Uint64(recordBatchLength, 0)
+	_, compressed := t.Table(recordBatchCompression)
+	d := &batchDecoder{
+		nodes:   t.Structs(recordBatchNodes, 16),
+		buffers: t.Structs(recordBatchBuffers, 16),
+		body:    body,
+	}
+	if err := t.Err(); err != nil {
+		return err
+	}
+	if compressed {
+		return fmt.Errorf("compression is not supported")
+	}
+	if length > math.MaxInt32-uint64(s.c.Len) {
+		return fmt.Errorf("too many rows")
+	}
+	n := int(length)
+	c := s.c
+	for i, kind := range s.kinds {
+		var err error
+		switch kind {
+		case kindID:
+			var valid []bool
+			start := len(c.IDs)
+			err = d.strings(&c.IDs, &valid, n)
+			for j, v := range valid {
+				if !v {
+					c.IDs[start+j] = ""
+				}
+			}
+		case kindGeometry:
+			err = d.geometry(&c.Geometry, n)
+		case kindColumn:
+			col := &c.Columns[s.columns[i]]
+			switch col.Type {
+			case Float64:
+				err = d.float64s(&col.Float64s, &col.Valid, 0, n)
+			case Bool:
+				err = d.bools(&col.Bools, &col.Valid, n)
+			default:
+				err = d.strings(&col.Strings, &col.Valid, n)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("field %q: %v", s.names[i], err)
+		}
+	}
+	c.Len += n
+	return nil
+}
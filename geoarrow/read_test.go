@@ -0,0 +1,104 @@
+package geoarrow
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestRead_Batches(t *testing.T) {
+	fc := testFeatures()
+	first := &geojson.FeatureCollection{Features: fc.Features[:2]}
+	second := &geojson.FeatureCollection{Features: fc.Features[2:]}
+	// Both halves need the same schema, so give the second half the
+	// properties that only the first has.
+	second.Features = append(second.Features, geojson.Feature{
+		Geometry:   fc.Features[0].Geometry,
+		Properties: map[string]interface{}{"name": "last", "tags": []interface{}{}},
+	})
+	var stream []byte
+	for i, part := range []*geojson.FeatureCollection{first, second} {
+		c, err := FromFeatureCollection(part)
+		if err != nil {
+			t.Fatalf("failed to convert: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := Write(&buf, c); err != nil {
+			t.Fatalf("failed to write: %v", err)
+		}
+		messages := splitMessages(t, buf.Bytes())
+		if i == 0 {
+			stream = append(stream, messages[0]...)
+		}
+		stream = append(stream, messages[1]...)
+	}
+	// Streams may also end without an end-of-stream marker.
+	c, err := Read(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	got, err := ToFeatureCollection(c)
+	if err != nil {
+		t.Fatalf("failed to convert back: %v", err)
+	}
+	expected := append(fc.Features, second.Features[1])
+	if !reflect.DeepEqual(got.Features, expected) {
+		t.Errorf("expected %#v, got %#v", expected, got.Features)
+	}
+}
+
+func TestRead_Legacy(t *testing.T) {
+	c, err := FromFeatureCollection(testFeatures())
+	if err != nil {
+		t.Fatalf("failed to convert: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, c); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	// Drop the continuation markers, as written before Arrow 0.15.
+	var stream []byte
+	for _, m := range splitMessages(t, buf.Bytes()) {
+		stream = append(stream, m[4:]...)
+	}
+	stream = append(stream, 0, 0, 0, 0)
+	got, err := Read(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if !reflect.DeepEqual(got, c) {
+		t.Errorf("expected %#v, got %#v", c, got)
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	c, err := FromFeatureCollection(testFeatures())
+	if err != nil {
+		t.Fatalf("failed to convert: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, c); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	messages := splitMessages(t, buf.Bytes())
+	schema, batch := messages[0], messages[1]
+	truncated := append(append([]byte{}, schema...), batch[:len(batch)-8]...)
+	cases := [][]byte{
+		nil,
+		{1, 2},
+		{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0},
+		// A length near the limit must not be allocated before the
+		// message arrives.
+		{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f, 1, 2, 3},
+		batch,
+		truncated,
+		append(append([]byte{}, schema...), schema...),
+	}
+	for i, c := range cases {
+		if _, err := Read(bytes.NewReader(c)); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package geoarrow
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/bsidhom/geojson/internal/flatbuf"
+)
+
+// Arrow IPC format constants, from Schema.fbs and Message.fbs.
+const (
+	metadataV5 = 4
+
+	headerSchema      = 1
+	headerDictionary  = 2
+	headerRecordBatch = 3
+
+	typeFloatingPoint = 3
+	typeUtf8          = 5
+	typeBool          = 6
+	typeList          = 12
+	typeStruct        = 13
+
+	precisionDouble = 2
+
+	// continuation precedes the length of each message.
+	continuation = 0xFFFFFFFF
+)
+
+// Field indices of the Message table.
+const (
+	messageVersion = iota
+	messageHeaderType
+	messageHeader
+	messageBodyLength
+)
+
+// Field indices of the Schema table.
+const (
+	schemaEndianness = iota
+	schemaFields
+)
+
+// Field indices of the Field table.
+const (
+	fieldName = iota
+	fieldNullable
+	fieldTypeType
+	fieldType
+	fieldDictionary
+	fieldChildren
+	fieldMetadata
+)
+
+// Field indices of the KeyValue table.
+const (
+	keyValueKey = iota
+	keyValueValue
+)
+
+// Field indices of the RecordBatch table.
+const (
+	recordBatchLength = iota
+	recordBatchNodes
+	recordBatchBuffers
+	recordBatchCompression
+)
+
+// Keys and values of Arrow extension type metadata.
+const (
+	extensionName     = "ARROW:extension:name"
+	extensionMetadata = "ARROW:extension:metadata"
+	jsonExtension     = "arrow.json"
+)
+
+// Names of the top-level fields that are not properties.
+const (
+	geometryField = "geometry"
+	idField       = "id"
+)
+
+// Write writes c to w as an Arrow IPC stream with a single record batch.
+//
+// Feature IDs are written as a string column named "id" and geometries as a
+// column named "geometry", so properties cannot use these names. JSON
+// columns are tagged with the arrow.json extension type.
+func Write(w io.Writer, c *FeatureCollection) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("geoarrow: %v", err)
+	}
+	for _, col := range c.Columns {
+		if col.Name == geometryField || (col.Name == idField && c.IDs != nil) {
+			return fmt.Errorf("geoarrow: column name %q is reserved", col.Name)
+		}
+	}
+	err := writeMessage(w, headerSchema, encodeSchema(c), nil)
+	if err != nil {
+		return fmt.Errorf("geoarrow: %v", err)
+	}
+	batch, body := encodeBatch(c)
+	if err := writeMessage(w, headerRecordBatch, batch, body); err != nil {
+		return fmt.Errorf("geoarrow: %v", err)
+	}
+	var eos [8]byte
+	binary.LittleEndian.PutUint32(eos[:], continuation)
+	if _, err := w.Write(eos[:]); err != nil {
+		return fmt.Errorf("geoarrow: %v", err)
+	}
+	return nil
+}
+
+// writeMessage writes an encapsulated message: the continuation marker,
+// the length of the padded metadata, the metadata, and the body.
+func writeMessage(w io.Writer, headerType uint8, header *flatbuf.Table, body []byte) error {
+	m := flatbuf.NewTable()
+	m.Uint16(messageVersion, metadataV5)
+	m.Uint8(messageHeaderType, headerType)
+	m.Ref(messageHeader, header)
+	m.Uint64(messageBodyLength, uint64(len(body)))
+	metadata := flatbuf.Build(m)
+	for len(metadata)%8 != 0 {
+		metadata = append(metadata, 0)
+	}
+	var prefix [8]byte
+	binary.LittleEndian.PutUint32(prefix[:], continuation)
+	binary.LittleEndian.PutUint32(prefix[4:], uint32(len(metadata)))
+	for _, b := range [][]byte{prefix[:], metadata, body} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeSchema(c *FeatureCollection) *flatbuf.Table {
+	var fields flatbuf.Tables
+	if c.IDs != nil {
+		fields = append(fields, field(idField, true, typeUtf8, flatbuf.NewTable(), nil, nil))
+	}
+	fields = append(fields, encodeGeometryField(&c.Geometry))
+	for _, col := range c.Columns {
+		var f *flatbuf.Table
+		switch col.Type {
+		case Float64:
+			t := flatbuf.NewTable()
+			t.Uint16(0, precisionDouble)
+			f = field(col.Name, true, typeFloatingPoint, t, nil, nil)
+		case Bool:
+			f = field(col.Name, true, typeBool, flatbuf.NewTable(), nil, nil)
+		case String:
+			f = field(col.Name, true, typeUtf8, flatbuf.NewTable(), nil, nil)
+		case JSON:
+			metadata := [][2]string{{extensionName, jsonExtension}, {extensionMetadata, ""}}
+			f = field(col.Name, true, typeUtf8, flatbuf.NewTable(), nil, metadata)
+		}
+		fields = append(fields, f)
+	}
+	s := flatbuf.NewTable()
+	s.Ref(schemaFields, fields)
+	return s
+}
+
+// encodeGeometryField returns the nested list and struct fields of the
+// geometry column, named as in the GeoArrow specification.
+func encodeGeometryField(g *GeometryColumn) *flatbuf.Table {
+	l := layouts[g.Type]
+	names := append([]string{geometryField}, l.children...)
+	double := flatbuf.NewTable()
+	double.Uint16(0, precisionDouble)
+	dims := []string{"x", "y"}
+	if g.HasZ {
+		dims = append(dims, "z")
+	}
+	var coords flatbuf.Tables
+	for _, dim := range dims {
+		coords = append(coords, field(dim, false, typeFloatingPoint, double, nil, nil))
+	}
+	f := field(names[len(names)-1], len(names) == 1, typeStruct, flatbuf.NewTable(), coords, nil)
+	for i := len(names) - 2; i >= 0; i-- {
+		f = field(names[i], i == 0, typeList, flatbuf.NewTable(), flatbuf.Tables{f}, nil)
+	}
+	f.Ref(fieldMetadata, keyValues([][2]string{{extensionName, l.extension}, {extensionMetadata, "{}"}}))
+	return f
+}
+
+func field(name string, nullable bool, typeType uint8, typ *flatbuf.Table, children flatbuf.Tables, metadata [][2]string) *flatbuf.Table {
+	f := flatbuf.NewTable()
+	f.String(fieldName, name)
+	f.Bool(fieldNullable, nullable)
+	f.Uint8(fieldTypeType, typeType)
+	f.Ref(fieldType, typ)
+	if children == nil {
+		children = flatbuf.Tables{}
+	}
+	f.Ref(fieldChildren, children)
+	if metadata != nil {
+		f.Ref(fieldMetadata, keyValues(metadata))
+	}
+	return f
+}
+
+func keyValues(pairs [][2]string) flatbuf.Tables {
+	kvs := make(flatbuf.Tables, len(pairs))
+	for i, kv := range pairs {
+		t := flatbuf.NewTable()
+		t.Ref(keyValueKey, flatbuf.String(kv[0]))
+		t.Ref(keyValueValue, flatbuf.String(kv[1]))
+		kvs[i] = t
+	}
+	return kvs
+}
+
+// batchEncoder accumulates the field nodes, buffer locations, and body of
+// a record batch. Fields are visited depth first, as in the schema.
+type batchEncoder struct {
+	nodes   []byte
+	buffers []byte
+	body    []byte
+}
+
+func (e *batchEncoder) node(length, nullCount int) {
+	var b [16]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(length))
+	binary.LittleEndian.PutUint64(b[8:], uint64(nullCount))
+	e.nodes = append(e.nodes, b[:]...)
+}
+
+// buffer appends data to the body, padded to 8 bytes.
+func (e *batchEncoder) buffer(data []byte) {
+	var b [16]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(len(e.body)))
+	binary.LittleEndian.PutUint64(b[8:], uint64(len(data)))
+	e.buffers = append(e.buffers, b[:]...)
+	e.body = append(e.body, data...)
+	for len(e.body)%8 != 0 {
+		e.body = append(e.body, 0)
+	}
+}
+
+// validity appends a node of the given length and its validity bitmap,
+// which is omitted if every row is valid.
+func (e *batchEncoder) validity(length int, valid []bool) {
+	nulls := 0
+	for _, v := range valid {
+		if !v {
+			nulls++
+		}
+	}
+	e.node(length, nulls)
+	if nulls == 0 {
+		e.buffer(nil)
+		return
+	}
+	e.buffer(bitmap(valid))
+}
+
+func (e *batchEncoder) offsets(offsets []int32) {
+	data := make([]byte, 4*len(offsets))
+	for i, o := range offsets {
+		binary.LittleEndian.PutUint32(data[4*i:], uint32(o))
+	}
+	e.buffer(data)
+}
+
+func (e *batchEncoder) float64s(values []float64, valid []bool) {
+	e.validity(len(values), valid)
+	data := make([]byte, 8*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(data[8*i:], math.Float64bits(v))
+	}
+	e.buffer(data)
+}
+
+func (e *batchEncoder) strings(values []string, valid []bool) {
+	e.validity(len(values), valid)
+	offsets := make([]int32, 0, len(values)+1)
+	var data []byte
+	offsets = append(offsets, 0)
+	for _, s := range values {
+		data = append(data, s...)
+		offsets = append(offsets, int32(len(data)))
+	}
+	e.offsets(offsets)
+	e.buffer(data)
+}
+
+func bitmap(bits []bool) []byte {
+	b := make([]byte, (len(bits)+7)/8)
+	for i, v := range bits {
+		if v {
+			b[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return b
+}
+
+func encodeBatch(c *FeatureCollection) (*flatbuf.Table, []byte) {
+	e := &batchEncoder{}
+	if c.IDs != nil {
+		valid := make([]bool, len(c.IDs))
+		for i, id := range c.IDs {
+			valid[i] = id != ""
+		}
+		e.strings(c.IDs, valid)
+	}
+	g := &c.Geometry
+	levels := g.levels()
+	if len(levels) == 0 {
+		e.validity(len(g.X), g.Valid)
+	} else {
+		e.validity(c.Len, g.Valid)
+		e.offsets(levels[0])
+		for _, offsets := range levels[1:] {
+			e.validity(len(offsets)-1, nil)
+			e.offsets(offsets)
+		}
+		e.validity(len(g.X), nil)
+	}
+	e.float64s(g.X, nil)
+	e.float64s(g.Y, nil)
+	if g.HasZ {
+		e.float64s(g.Z, nil)
+	}
+	for _, col := range c.Columns {
+		switch col.Type {
+		case Float64:
+			e.float64s(col.Float64s, col.Valid)
+		case Bool:
+			e.validity(c.Len, col.Valid)
+			e.buffer(bitmap(col.Bools))
+		case String, JSON:
+			e.strings(col.Strings, col.Valid)
+		}
+	}
+	t := flatbuf.NewTable()
+	t.Uint64(recordBatchLength, uint64(c.Len))
+	t.Ref(recordBatchNodes, flatbuf.StructVector(16, e.nodes))
+	t.Ref(recordBatchBuffers, flatbuf.StructVector(16, e.buffers))
+	return t, e.body
+}
@@ -0,0 +1,149 @@
+package geoarrow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/flatbuf"
+)
+
+func testFeatures() *geojson.FeatureCollection {
+	square := geojson.LineString{
+		Points: []geojson.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 0}},
+	}
+	return &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				ID:       "a",
+				Geometry: &geojson.Polygon{Rings: []geojson.LineString{square}},
+				Properties: map[string]interface{}{
+					"name":  "first",
+					"count": float64(1),
+					"ok":    true,
+					"tags":  []interface{}{"x", float64(2)},
+				},
+			},
+			{
+				Properties: map[string]interface{}{"name": "no geometry"},
+			},
+			{
+				ID:         "c",
+				Geometry:   &geojson.Polygon{Rings: []geojson.LineString{square, square}},
+				Properties: map[string]interface{}{"count": 2.5, "ok": false},
+			},
+		},
+	}
+}
+
+// splitMessages returns the encapsulated messages of an IPC stream,
+// excluding the end-of-stream marker.
+func splitMessages(t *testing.T, b []byte) [][]byte {
+	var messages [][]byte
+	for {
+		if len(b) < 8 || binary.LittleEndian.Uint32(b) != continuation {
+			t.Fatalf("missing continuation marker")
+		}
+		n := int(binary.LittleEndian.Uint32(b[4:]))
+		if n == 0 {
+			if len(b) != 8 {
+				t.Fatalf("%d bytes after end of stream", len(b)-8)
+			}
+			return messages
+		}
+		if n%8 != 0 {
+			t.Fatalf("metadata length %d is not a multiple of 8", n)
+		}
+		m := flatbuf.NewReader(b[8 : 8+n]).Root()
+		end := 8 + n + int(m.Uint64(messageBodyLength, 0))
+		messages = append(messages, b[:end])
+		b = b[end:]
+	}
+}
+
+func TestWrite(t *testing.T) {
+	c, err := FromFeatureCollection(testFeatures())
+	if err != nil {
+		t.Fatalf("failed to convert: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, c); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	messages := splitMessages(t, buf.Bytes())
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+
+	// Check the schema layout directly, since other Arrow readers rely on
+	// the field names and extension metadata.
+	schema := messages[0]
+	m := flatbuf.NewReader(schema[8:]).Root()
+	if v := m.Uint16(messageVersion, 0); v != metadataV5 {
+		t.Errorf("expected version %d, got %d", metadataV5, v)
+	}
+	if h := m.Uint8(messageHeaderType, 0); h != headerSchema {
+		t.Errorf("expected schema header, got %d", h)
+	}
+	s, _ := m.Table(messageHeader)
+	var names []string
+	var describe func(f flatbuf.TableRef) string
+	describe = func(f flatbuf.TableRef) string {
+		d := f.String(fieldName)
+		for _, c := range f.Tables(fieldChildren) {
+			d += "<" + describe(c) + ">"
+		}
+		return d
+	}
+	fields := s.Tables(schemaFields)
+	for _, f := range fields {
+		names = append(names, describe(f))
+	}
+	expected := []string{
+		"id",
+		"geometry<rings<vertices<x><y>>>",
+		"count", "name", "ok", "tags",
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected fields %v, got %v", expected, names)
+	}
+	metadata := map[string]string{}
+	for _, kv := range fields[1].Tables(fieldMetadata) {
+		metadata[kv.String(keyValueKey)] = kv.String(keyValueValue)
+	}
+	expectedMetadata := map[string]string{extensionName: "geoarrow.polygon", extensionMetadata: "{}"}
+	if !reflect.DeepEqual(metadata, expectedMetadata) {
+		t.Errorf("expected metadata %v, got %v", expectedMetadata, metadata)
+	}
+	if err := m.Err(); err != nil {
+		t.Errorf("failed to decode schema: %v", err)
+	}
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if !reflect.DeepEqual(got, c) {
+		t.Errorf("expected %#v, got %#v", c, got)
+	}
+}
+
+func TestWrite_Reserved(t *testing.T) {
+	cases := []*geojson.FeatureCollection{
+		{Features: []geojson.Feature{{Properties: map[string]interface{}{"geometry": "x"}}}},
+		{Features: []geojson.Feature{{ID: "1", Properties: map[string]interface{}{"id": "x"}}}},
+	}
+	for i, fc := range cases {
+		c, err := FromFeatureCollection(fc)
+		if err != nil {
+			t.Errorf("case %d: failed to convert: %v", i, err)
+			continue
+		}
+		var buf bytes.Buffer
+		if err := Write(&buf, c); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,413 @@
+package geobuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/bsidhom/geojson/internal/pb"
+	"github.com/bsidhom/geojson/wire"
+)
+
+// Decode decodes a Geobuf message.
+//
+// Numeric property values are decoded as float64, matching encoding/json.
+// Integer feature IDs are formatted as decimal strings. A "bbox" custom
+// property is decoded into the BBox field; all other custom properties
+// become foreign members.
+func Decode(b []byte) (wire.Object, error) {
+	d := &decoder{dim: 2, precision: DefaultPrecision}
+	var dataField int
+	var data []byte
+	msg := pb.NewDecoder(b)
+	for msg.Next() {
+		switch msg.Field() {
+		case dataKeys:
+			d.keys = append(d.keys, msg.String())
+		case dataDimensions:
+			d.dim = int(msg.Uint64())
+		case dataPrecision:
+			d.precision = int(msg.Uint64())
+		case dataFeatureCollection, dataFeature, dataGeometry:
+			dataField = msg.Field()
+			data = msg.Embedded()
+		}
+	}
+	if err := msg.Err(); err != nil {
+		return nil, fmt.Errorf("geobuf: %v", err)
+	}
+	if d.dim < 1 {
+		return nil, fmt.Errorf("geobuf: invalid dimensions %d", d.dim)
+	}
+	if d.precision > 2*maxPrecision {
+		return nil, fmt.Errorf("geobuf: invalid precision %d", d.precision)
+	}
+	d.scale = math.Pow10(d.precision)
+
+	var obj wire.Object
+	var err error
+	switch dataField {
+	case dataFeatureCollection:
+		obj, err = d.featureCollection(data)
+	case dataFeature:
+		obj, err = d.feature(data)
+	case dataGeometry:
+		var g wire.Geometry
+		g, err = d.geometry(data)
+		// All wire geometries are also wire objects.
+		obj, _ = g.(wire.Object)
+	default:
+		return nil, fmt.Errorf("geobuf: no data")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("geobuf: %v", err)
+	}
+	return obj, nil
+}
+
+type decoder struct {
+	keys      []string
+	dim       int
+	precision int
+	scale     float64
+}
+
+// properties resolves packed key and value index pairs.
+func (d *decoder) properties(pairs []uint64, values []interface{}) (map[string]interface{}, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("odd number of property indexes")
+	}
+	result := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		k, v := pairs[i], pairs[i+1]
+		if k >= uint64(len(d.keys)) {
+			return nil, fmt.Errorf("key index %d out of range", k)
+		}
+		if v >= uint64(len(values)) {
+			return nil, fmt.Errorf("value index %d out of range", v)
+		}
+		result[d.keys[k]] = values[v]
+	}
+	return result, nil
+}
+
+// custom resolves custom properties, separating out the bounding box.
+func (d *decoder) custom(pairs []uint64, values []interface{}) ([]float64, map[string]interface{}, error) {
+	foreign, err := d.properties(pairs, values)
+	if err != nil {
+		return nil, nil, err
+	}
+	raw, ok := foreign["bbox"]
+	if !ok {
+		return nil, foreign, nil
+	}
+	delete(foreign, "bbox")
+	if len(foreign) == 0 {
+		foreign = nil
+	}
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid bbox: %v", raw)
+	}
+	bbox := make([]float64, len(items))
+	for i, item := range items {
+		v, ok := item.(float64)
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid bbox: %v", raw)
+		}
+		bbox[i] = v
+	}
+	return bbox, foreign, nil
+}
+
+func decodeValue(b []byte) (interface{}, error) {
+	var v interface{}
+	msg := pb.NewDecoder(b)
+	for msg.Next() {
+		switch msg.Field() {
+		case valueString:
+			v = msg.String()
+		case valueDouble:
+			v = msg.Double()
+		case valuePosInt:
+			v = float64(msg.Uint64())
+		case valueNegInt:
+			v = -float64(msg.Uint64())
+		case valueBool:
+			v = msg.Bool()
+		case valueJSON:
+			v = nil
+			err := json.Unmarshal(msg.Embedded(), &v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSON value: %v", err)
+			}
+		}
+	}
+	return v, msg.Err()
+}
+
+func (d *decoder) featureCollection(b []byte) (*wire.FeatureCollection, error) {
+	fc := &wire.FeatureCollection{}
+	var values []interface{}
+	var custom []uint64
+	msg := pb.NewDecoder(b)
+	for msg.Next() {
+		switch msg.Field() {
+		case featureCollectionFeatures:
+			f, err := d.feature(msg.Embedded())
+			if err != nil {
+				return nil, fmt.Errorf("feature %d: %v", len(fc.Features), err)
+			}
+			fc.Features = append(fc.Features, *f)
+		case fieldValues:
+			v, err := decodeValue(msg.Embedded())
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		case fieldCustomProperties:
+			custom = msg.Uint64s(custom)
+		}
+	}
+	if err := msg.Err(); err != nil {
+		return nil, err
+	}
+	var err error
+	fc.BBox, fc.ForeignMembers, err = d.custom(custom, values)
+	if err != nil {
+		return nil, err
+	}
+	return fc, nil
+}
+
+func (d *decoder) feature(b []byte) (*wire.Feature, error) {
+	f := &wire.Feature{}
+	var values []interface{}
+	var properties, custom []uint64
+	msg := pb.NewDecoder(b)
+	for msg.Next() {
+		switch msg.Field() {
+		case featureGeometry:
+			g, err := d.geometry(msg.Embedded())
+			if err != nil {
+				return nil, err
+			}
+			f.Geometry = g
+		case featureID:
+			f.ID = msg.String()
+		case featureIntID:
+			f.ID = strconv.FormatInt(msg.Sint64(), 10)
+		case fieldValues:
+			v, err := decodeValue(msg.Embedded())
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		case fieldProperties:
+			properties = msg.Uint64s(properties)
+		case fieldCustomProperties:
+			custom = msg.Uint64s(custom)
+		}
+	}
+	if err := msg.Err(); err != nil {
+		return nil, err
+	}
+	var err error
+	f.Properties, err = d.properties(properties, values)
+	if err != nil {
+		return nil, err
+	}
+	f.BBox, f.ForeignMembers, err = d.custom(custom, values)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (d *decoder) geometry(b []byte) (wire.Geometry, error) {
+	var geomType uint64
+	var lengths []uint64
+	var hasLengths bool
+	var coords []int64
+	var children []wire.Geometry
+	var values []interface{}
+	var custom []uint64
+	msg := pb.NewDecoder(b)
+	for msg.Next() {
+		switch msg.Field() {
+		case geometryType:
+			geomType = msg.Uint64()
+		case geometryLengths:
+			lengths = msg.Uint64s(lengths)
+			hasLengths = true
+		case geometryCoords:
+			coords = msg.Sint64s(coords)
+		case geometryGeometries:
+			g, err := d.geometry(msg.Embedded())
+			if err != nil {
+				return nil, fmt.Errorf("geometry %d: %v", len(children), err)
+			}
+			children = append(children, g)
+		case fieldValues:
+			v, err := decodeValue(msg.Embedded())
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		case fieldCustomProperties:
+			custom = msg.Uint64s(custom)
+		}
+	}
+	if err := msg.Err(); err != nil {
+		return nil, err
+	}
+	if len(coords)%d.dim != 0 {
+		return nil, fmt.Errorf("%d coordinates do not divide into %d dimensions", len(coords), d.dim)
+	}
+	bbox, foreign, err := d.custom(custom, values)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &coordReader{dim: d.dim, scale: d.scale, coords: coords, lengths: lengths}
+	switch geomType {
+	case typePoint:
+		var p []float64
+		if len(coords) > 0 {
+			p = r.point()
+		}
+		return r.finish(&wire.Point{BBox: bbox, Coordinates: p, ForeignMembers: foreign})
+	case typeMultiPoint:
+		return r.finish(&wire.MultiPoint{BBox: bbox, Coordinates: r.rest(false), ForeignMembers: foreign})
+	case typeLineString:
+		return r.finish(&wire.LineString{BBox: bbox, Coordinates: r.rest(false), ForeignMembers: foreign})
+	case typeMultiLineString:
+		return r.finish(&wire.MultiLineString{BBox: bbox, Coordinates: r.lines(hasLengths, false), ForeignMembers: foreign})
+	case typePolygon:
+		return r.finish(&wire.Polygon{BBox: bbox, Coordinates: r.lines(hasLengths, true), ForeignMembers: foreign})
+	case typeMultiPolygon:
+		var polygons [][][][]float64
+		if !hasLengths {
+			if len(coords) > 0 {
+				polygons = [][][][]float64{{r.rest(true)}}
+			}
+		} else {
+			polygons = [][][][]float64{}
+			n := r.length()
+			for i := 0; i < n && r.err == nil; i++ {
+				rings := make([][][]float64, r.length())
+				for j := range rings {
+					rings[j] = r.line(r.length(), true)
+				}
+				polygons = append(polygons, rings)
+			}
+		}
+		return r.finish(&wire.MultiPolygon{BBox: bbox, Coordinates: polygons, ForeignMembers: foreign})
+	case typeGeometryCollection:
+		return &wire.GeometryCollection{BBox: bbox, Geometries: children, ForeignMembers: foreign}, nil
+	}
+	return nil, fmt.Errorf("unknown geometry type %d", geomType)
+}
+
+// A coordReader consumes the lengths and scaled coordinates of a geometry.
+type coordReader struct {
+	dim     int
+	scale   float64
+	coords  []int64
+	lengths []uint64
+	err     error
+}
+
+// finish returns g unless the coordinates were malformed or not fully
+// consumed.
+func (r *coordReader) finish(g wire.Geometry) (wire.Geometry, error) {
+	if r.err == nil && (len(r.coords) > 0 || len(r.lengths) > 0) {
+		r.err = fmt.Errorf("lengths do not match coordinates")
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return g, nil
+}
+
+func (r *coordReader) length() int {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.lengths) == 0 {
+		r.err = fmt.Errorf("missing lengths")
+		return 0
+	}
+	n := r.lengths[0]
+	r.lengths = r.lengths[1:]
+	if n > uint64(len(r.coords)/r.dim) && n > uint64(len(r.lengths)) {
+		// Too large to describe either positions or further lengths.
+		r.err = fmt.Errorf("length %d out of range", n)
+		return 0
+	}
+	return int(n)
+}
+
+func (r *coordReader) point() []float64 {
+	p := make([]float64, r.dim)
+	for j := range p {
+		p[j] = float64(r.coords[j]) / r.scale
+	}
+	r.coords = r.coords[r.dim:]
+	return p
+}
+
+// line reads n delta-encoded positions, repeating the first position at the
+// end if the line is closed.
+func (r *coordReader) line(n int, closed bool) [][]float64 {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.coords)/r.dim {
+		r.err = fmt.Errorf("line of %d positions exceeds coordinates", n)
+		return nil
+	}
+	line := make([][]float64, 0, n+1)
+	sum := make([]int64, r.dim)
+	for i := 0; i < n; i++ {
+		p := make([]float64, r.dim)
+		for j := range p {
+			sum[j] += r.coords[j]
+			p[j] = float64(sum[j]) / r.scale
+		}
+		r.coords = r.coords[r.dim:]
+		line = append(line, p)
+	}
+	if closed && n > 0 {
+		line = append(line, append([]float64(nil), line[0]...))
+	}
+	return line
+}
+
+// rest reads all remaining positions as one line.
+func (r *coordReader) rest(closed bool) [][]float64 {
+	if len(r.coords) == 0 {
+		return nil
+	}
+	return r.line(len(r.coords)/r.dim, closed)
+}
+
+// lines reads a list of lines, which is a single line if there are no
+// lengths.
+func (r *coordReader) lines(hasLengths, closed bool) [][][]float64 {
+	if !hasLengths {
+		if len(r.coords) == 0 {
+			return nil
+		}
+		return [][][]float64{r.rest(closed)}
+	}
+	lines := [][][]float64{}
+	for len(r.lengths) > 0 && r.err == nil {
+		lines = append(lines, r.line(r.length(), closed))
+	}
+	return lines
+}
@@ -0,0 +1,63 @@
+package geobuf
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson/internal/pb"
+	"github.com/bsidhom/geojson/wire"
+)
+
+func TestDecode_IntID(t *testing.T) {
+	var f pb.Encoder
+	f.Sint64(featureIntID, -12)
+	var data pb.Encoder
+	data.Embedded(dataFeature, f.Bytes())
+	got, err := Decode(data.Bytes())
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	expected := &wire.Feature{ID: "-12"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %#v, got %#v", expected, got)
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	geometry := func(geomType uint64, lengths []uint64, coords []int64) []byte {
+		var g pb.Encoder
+		g.Uint64(geometryType, geomType)
+		g.PackedUint64(geometryLengths, lengths)
+		g.PackedSint64(geometryCoords, coords)
+		var data pb.Encoder
+		data.Embedded(dataGeometry, g.Bytes())
+		return data.Bytes()
+	}
+	feature := func(properties []uint32) []byte {
+		var f pb.Encoder
+		f.PackedUint32(fieldProperties, properties)
+		var data pb.Encoder
+		data.String(dataKeys, "k")
+		data.Embedded(dataFeature, f.Bytes())
+		return data.Bytes()
+	}
+	cases := [][]byte{
+		nil,
+		{0x32, 0x05},
+		geometry(9, nil, nil),
+		geometry(typePoint, nil, []int64{1, 2, 3}),
+		geometry(typeLineString, nil, []int64{1}),
+		geometry(typeMultiLineString, []uint64{3}, []int64{1, 2, 3, 4}),
+		geometry(typeMultiLineString, []uint64{1}, []int64{1, 2, 3, 4}),
+		geometry(typeMultiPolygon, []uint64{1 << 40}, []int64{1, 2}),
+		feature([]uint32{0}),
+		feature([]uint32{1, 0}),
+		feature([]uint32{0, 0}),
+	}
+	for i, c := range cases {
+		_, err := Decode(c)
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,426 @@
+package geobuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/bsidhom/geojson/internal/pb"
+	"github.com/bsidhom/geojson/wire"
+)
+
+// maxPrecision bounds the precision so that scaled coordinates of ordinary
+// magnitude remain exactly representable.
+const maxPrecision = 15
+
+// EncodeOptions controls encoding.
+type EncodeOptions struct {
+	// Maximum number of digits after the decimal point kept for coordinates.
+	// The encoder uses the fewest digits that represent every coordinate
+	// exactly, up to this limit; coordinates needing more are rounded. If
+	// zero, DefaultPrecision is used. At most 15.
+	MaxPrecision int
+}
+
+// Encode encodes obj as a Geobuf message. If opts is nil, the zero
+// EncodeOptions are used.
+//
+// Feature IDs are always stored as strings. Property values that are
+// strings, booleans, or float64s use the corresponding Geobuf value types;
+// all other values, including nil, are stored as JSON.
+func Encode(obj wire.Object, opts *EncodeOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &EncodeOptions{}
+	}
+	limit := opts.MaxPrecision
+	if limit == 0 {
+		limit = DefaultPrecision
+	}
+	if limit < 0 || limit > maxPrecision {
+		return nil, fmt.Errorf("geobuf: MaxPrecision %d out of range", opts.MaxPrecision)
+	}
+
+	e := &encoder{dim: 2, keyIdx: map[string]uint32{}}
+	e.measureObject(obj, limit)
+	e.scale = math.Pow10(e.precision)
+
+	var data pb.Encoder
+	var err error
+	switch t := obj.(type) {
+	case *wire.FeatureCollection:
+		var b []byte
+		b, err = e.featureCollection(t)
+		data.Embedded(dataFeatureCollection, b)
+	case *wire.Feature:
+		var b []byte
+		b, err = e.feature(t)
+		data.Embedded(dataFeature, b)
+	case wire.Geometry:
+		var b []byte
+		b, err = e.geometry(t)
+		data.Embedded(dataGeometry, b)
+	default:
+		return nil, fmt.Errorf("geobuf: unsupported object type: %T", obj)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("geobuf: %v", err)
+	}
+
+	var msg pb.Encoder
+	for _, k := range e.keys {
+		msg.String(dataKeys, k)
+	}
+	if e.dim != 2 {
+		msg.Uint64(dataDimensions, uint64(e.dim))
+	}
+	if e.precision != DefaultPrecision {
+		msg.Uint64(dataPrecision, uint64(e.precision))
+	}
+	return append(msg.Bytes(), data.Bytes()...), nil
+}
+
+type encoder struct {
+	dim       int
+	precision int
+	scale     float64
+	keys      []string
+	keyIdx    map[string]uint32
+}
+
+// measureObject finds the number of dimensions and the precision needed for
+// all positions in obj.
+func (e *encoder) measureObject(obj wire.Object, limit int) {
+	switch t := obj.(type) {
+	case *wire.FeatureCollection:
+		for i := range t.Features {
+			e.measureGeometry(t.Features[i].Geometry, limit)
+		}
+	case *wire.Feature:
+		e.measureGeometry(t.Geometry, limit)
+	case wire.Geometry:
+		e.measureGeometry(t, limit)
+	}
+}
+
+func (e *encoder) measureGeometry(g wire.Geometry, limit int) {
+	switch t := g.(type) {
+	case *wire.GeometryCollection:
+		for _, child := range t.Geometries {
+			e.measureGeometry(child, limit)
+		}
+	case *wire.MultiPolygon:
+		for _, polygon := range t.Coordinates {
+			for _, ring := range polygon {
+				e.measurePositions(ring, limit)
+			}
+		}
+	case *wire.Polygon:
+		for _, ring := range t.Coordinates {
+			e.measurePositions(ring, limit)
+		}
+	case *wire.MultiLineString:
+		for _, line := range t.Coordinates {
+			e.measurePositions(line, limit)
+		}
+	case *wire.LineString:
+		e.measurePositions(t.Coordinates, limit)
+	case *wire.MultiPoint:
+		e.measurePositions(t.Coordinates, limit)
+	case *wire.Point:
+		e.measurePositions([][]float64{t.Coordinates}, limit)
+	}
+}
+
+func (e *encoder) measurePositions(positions [][]float64, limit int) {
+	for _, p := range positions {
+		if len(p) > e.dim {
+			e.dim = len(p)
+		}
+		for _, c := range p {
+			scale := math.Pow10(e.precision)
+			for e.precision < limit && math.Round(c*scale)/scale != c {
+				e.precision++
+				scale *= 10
+			}
+		}
+	}
+}
+
+func (e *encoder) key(k string) uint32 {
+	i, ok := e.keyIdx[k]
+	if !ok {
+		i = uint32(len(e.keys))
+		e.keyIdx[k] = i
+		e.keys = append(e.keys, k)
+	}
+	return i
+}
+
+// properties writes values to msg, keyed by the shared key table, as a
+// packed list of key and value index pairs in field. Values are numbered
+// from *next, which is advanced past them.
+func (e *encoder) properties(msg *pb.Encoder, field int, values map[string]interface{}, next *uint32) error {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var pairs []uint32
+	for _, k := range keys {
+		b, err := encodeValue(values[k])
+		if err != nil {
+			return fmt.Errorf("property %q: %v", k, err)
+		}
+		msg.Embedded(fieldValues, b)
+		pairs = append(pairs, e.key(k), *next)
+		*next++
+	}
+	msg.PackedUint32(field, pairs)
+	return nil
+}
+
+// custom writes the bounding box and foreign members of an object as custom
+// properties, skipping foreign members that shadow standard members.
+func (e *encoder) custom(msg *pb.Encoder, bbox []float64, foreign map[string]interface{}, members []string, next *uint32) error {
+	values := map[string]interface{}{}
+	for k, v := range foreign {
+		if !isMember(k, members) {
+			values[k] = v
+		}
+	}
+	if bbox != nil {
+		values["bbox"] = bbox
+	}
+	return e.properties(msg, fieldCustomProperties, values, next)
+}
+
+func encodeValue(v interface{}) ([]byte, error) {
+	var msg pb.Encoder
+	switch t := v.(type) {
+	case string:
+		msg.String(valueString, t)
+	case bool:
+		msg.Bool(valueBool, t)
+	case float64:
+		switch {
+		case t != math.Trunc(t) || math.Abs(t) >= 1<<63:
+			// Also covers NaN and infinities.
+			msg.Double(valueDouble, t)
+		case t >= 0:
+			msg.Uint64(valuePosInt, uint64(t))
+		default:
+			msg.Uint64(valueNegInt, uint64(-t))
+		}
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		msg.String(valueJSON, string(b))
+	}
+	return msg.Bytes(), nil
+}
+
+func (e *encoder) featureCollection(fc *wire.FeatureCollection) ([]byte, error) {
+	var msg pb.Encoder
+	for i := range fc.Features {
+		b, err := e.feature(&fc.Features[i])
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %v", i, err)
+		}
+		msg.Embedded(featureCollectionFeatures, b)
+	}
+	var next uint32
+	err := e.custom(&msg, fc.BBox, fc.ForeignMembers, featureCollectionMembers, &next)
+	if err != nil {
+		return nil, err
+	}
+	return msg.Bytes(), nil
+}
+
+func (e *encoder) feature(f *wire.Feature) ([]byte, error) {
+	var msg pb.Encoder
+	if f.Geometry != nil {
+		b, err := e.geometry(f.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		msg.Embedded(featureGeometry, b)
+	}
+	if f.ID != "" {
+		msg.String(featureID, f.ID)
+	}
+	var next uint32
+	err := e.properties(&msg, fieldProperties, f.Properties, &next)
+	if err != nil {
+		return nil, err
+	}
+	err = e.custom(&msg, f.BBox, f.ForeignMembers, featureMembers, &next)
+	if err != nil {
+		return nil, err
+	}
+	return msg.Bytes(), nil
+}
+
+func (e *encoder) geometry(g wire.Geometry) ([]byte, error) {
+	var msg pb.Encoder
+	c := &coordWriter{dim: e.dim, scale: e.scale}
+	var bbox []float64
+	var foreign map[string]interface{}
+	switch t := g.(type) {
+	case *wire.GeometryCollection:
+		msg.Uint64(geometryType, typeGeometryCollection)
+		for i, child := range t.Geometries {
+			b, err := e.geometry(child)
+			if err != nil {
+				return nil, fmt.Errorf("geometry %d: %v", i, err)
+			}
+			msg.Embedded(geometryGeometries, b)
+		}
+		bbox, foreign = t.BBox, t.ForeignMembers
+	case *wire.MultiPolygon:
+		msg.Uint64(geometryType, typeMultiPolygon)
+		if len(t.Coordinates) != 1 || len(t.Coordinates[0]) != 1 {
+			c.length(len(t.Coordinates))
+			for _, polygon := range t.Coordinates {
+				c.length(len(polygon))
+				for _, ring := range polygon {
+					c.length(closedLength(ring))
+				}
+			}
+		}
+		for _, polygon := range t.Coordinates {
+			for _, ring := range polygon {
+				c.line(ring, true)
+			}
+		}
+		bbox, foreign = t.BBox, t.ForeignMembers
+	case *wire.Polygon:
+		msg.Uint64(geometryType, typePolygon)
+		c.lines(t.Coordinates, true)
+		bbox, foreign = t.BBox, t.ForeignMembers
+	case *wire.MultiLineString:
+		msg.Uint64(geometryType, typeMultiLineString)
+		c.lines(t.Coordinates, false)
+		bbox, foreign = t.BBox, t.ForeignMembers
+	case *wire.LineString:
+		msg.Uint64(geometryType, typeLineString)
+		c.line(t.Coordinates, false)
+		bbox, foreign = t.BBox, t.ForeignMembers
+	case *wire.MultiPoint:
+		msg.Uint64(geometryType, typeMultiPoint)
+		c.line(t.Coordinates, false)
+		bbox, foreign = t.BBox, t.ForeignMembers
+	case *wire.Point:
+		msg.Uint64(geometryType, typePoint)
+		c.point(t.Coordinates)
+		bbox, foreign = t.BBox, t.ForeignMembers
+	default:
+		return nil, fmt.Errorf("unsupported geometry type: %T", g)
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.hasLengths {
+		// Written even when empty, so that decoding can tell an empty list
+		// of lines from a single line.
+		var b []byte
+		for _, n := range c.lengths {
+			b = pb.AppendVarint(b, uint64(n))
+		}
+		msg.Embedded(geometryLengths, b)
+	}
+	msg.PackedSint64(geometryCoords, c.coords)
+	var next uint32
+	err := e.custom(&msg, bbox, foreign, geometryMembers, &next)
+	if err != nil {
+		return nil, err
+	}
+	return msg.Bytes(), nil
+}
+
+// closedLength returns the number of positions stored for a ring, whose
+// closing position is implied.
+func closedLength(ring [][]float64) int {
+	if len(ring) == 0 {
+		return 0
+	}
+	return len(ring) - 1
+}
+
+// A coordWriter accumulates the lengths and scaled coordinates of a
+// geometry.
+type coordWriter struct {
+	dim        int
+	scale      float64
+	lengths    []int
+	hasLengths bool
+	coords     []int64
+	err        error
+}
+
+func (c *coordWriter) length(n int) {
+	c.lengths = append(c.lengths, n)
+	c.hasLengths = true
+}
+
+// scaled converts a coordinate to an integer.
+func (c *coordWriter) scaled(v float64) int64 {
+	s := math.Round(v * c.scale)
+	if math.IsNaN(s) || math.Abs(s) >= 1<<63 {
+		if c.err == nil {
+			c.err = fmt.Errorf("coordinate %v out of range", v)
+		}
+		return 0
+	}
+	return int64(s)
+}
+
+func (c *coordWriter) point(p []float64) {
+	for j := 0; j < c.dim; j++ {
+		var v float64
+		if j < len(p) {
+			v = p[j]
+		}
+		c.coords = append(c.coords, c.scaled(v))
+	}
+}
+
+// lines writes a list of lines, with lengths unless there is exactly one.
+func (c *coordWriter) lines(lines [][][]float64, closed bool) {
+	if len(lines) != 1 {
+		c.hasLengths = true
+		for _, line := range lines {
+			n := len(line)
+			if closed {
+				n = closedLength(line)
+			}
+			c.length(n)
+		}
+	}
+	for _, line := range lines {
+		c.line(line, closed)
+	}
+}
+
+// line delta-encodes the positions of a line, omitting the last position of
+// a closed ring.
+func (c *coordWriter) line(line [][]float64, closed bool) {
+	n := len(line)
+	if closed {
+		n = closedLength(line)
+	}
+	sum := make([]int64, c.dim)
+	for _, p := range line[:n] {
+		for j := 0; j < c.dim; j++ {
+			var v float64
+			if j < len(p) {
+				v = p[j]
+			}
+			s := c.scaled(v)
+			c.coords = append(c.coords, s-sum[j])
+			sum[j] = s
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package geobuf
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/wire"
+)
+
+func TestEncode(t *testing.T) {
+	b, err := Encode(&wire.Point{Coordinates: []float64{1.5, 2}}, nil)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	// Precision 1, then a Point geometry with coordinates 15 and 20.
+	expected := []byte{0x18, 0x01, 0x32, 0x06, 0x08, 0x00, 0x1a, 0x02, 0x1e, 0x28}
+	if !reflect.DeepEqual(b, expected) {
+		t.Errorf("expected % x, got % x", expected, b)
+	}
+}
+
+func TestRoundTrip_Encode(t *testing.T) {
+	cases := []string{
+		`{"type":"Point","coordinates":[0,1]}`,
+		`{"type":"Point","coordinates":[-122.419416,37.774929,12.5],"bbox":[-123,37,-122,38]}`,
+		`{"type":"MultiPoint","coordinates":[[100,0],[101,1]]}`,
+		`{"type":"LineString","coordinates":[[100,0],[101.25,1],[99,-3]],"name":"path"}`,
+		`{"type":"MultiLineString","coordinates":[[[100,0],[101,1]],[[102,2],[103,3],[104,5]]]}`,
+		`{"type":"MultiLineString","coordinates":[[[100,0],[101,1]]]}`,
+		`{"type":"MultiLineString","coordinates":[]}`,
+		`{"type":"Polygon","coordinates":[[[100,0],[101,0],[101,1],[100,1],[100,0]],[[100.8,0.8],[100.8,0.2],[100.2,0.2],[100.2,0.8],[100.8,0.8]]]}`,
+		`{"type":"Polygon","coordinates":[[[100,0],[101,0],[101,1],[100,1],[100,0]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[[102,2],[103,2],[103,3],[102,3],[102,2]]],[[[100,0],[101,0],[101,1],[100,1],[100,0]],[[100.2,0.2],[100.2,0.8],[100.8,0.8],[100.8,0.2],[100.2,0.2]]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[[102,2],[103,2],[103,3],[102,3],[102,2]]]]}`,
+		`{"type":"MultiPolygon","coordinates":[]}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[100,0]},{"type":"LineString","coordinates":[[101,0],[102,1]]}]}`,
+		`{"type":"Feature","id":"f1","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"a","count":3,"delta":-4,"ratio":0.5,"ok":true,"none":null,"tags":["x","y"],"nested":{"k":1}}}`,
+		`{"type":"FeatureCollection","bbox":[0,0,5,5],"title":"collection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"a"},"links":[{"href":"x"}]},{"type":"Feature","geometry":{"type":"Point","coordinates":[3,4]},"properties":{"name":"b"}}]}`,
+	}
+	for i, c := range cases {
+		var expected wire.Wrapper
+		err := json.Unmarshal([]byte(c), &expected)
+		if err != nil {
+			t.Fatalf("case %d: invalid test input: %v", i, err)
+		}
+		b, err := Encode(expected.Value, nil)
+		if err != nil {
+			t.Errorf("case %d: failed to encode: %v", i, err)
+			continue
+		}
+		got, err := Decode(b)
+		if err != nil {
+			t.Errorf("case %d: failed to decode: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, expected.Value) {
+			t.Errorf("case %d: expected %#v, got %#v", i, expected.Value, got)
+		}
+	}
+}
+
+func TestEncode_Precision(t *testing.T) {
+	cases := []struct {
+		coords   []float64
+		opts     *EncodeOptions
+		expected []float64
+	}{
+		{
+			coords:   []float64{1.123456789, 2},
+			expected: []float64{1.123457, 2},
+		},
+		{
+			coords:   []float64{1.123456789, 2},
+			opts:     &EncodeOptions{MaxPrecision: 9},
+			expected: []float64{1.123456789, 2},
+		},
+		{
+			coords:   []float64{1.25, 2},
+			opts:     &EncodeOptions{MaxPrecision: 1},
+			expected: []float64{1.3, 2},
+		},
+	}
+	for i, c := range cases {
+		b, err := Encode(&wire.Point{Coordinates: c.coords}, c.opts)
+		if err != nil {
+			t.Errorf("case %d: failed to encode: %v", i, err)
+			continue
+		}
+		got, err := Decode(b)
+		if err != nil {
+			t.Errorf("case %d: failed to decode: %v", i, err)
+			continue
+		}
+		expected := &wire.Point{Coordinates: c.expected}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("case %d: expected %v, got %v", i, expected, got)
+		}
+	}
+
+	_, err := Encode(&wire.Point{Coordinates: []float64{0, 0}}, &EncodeOptions{MaxPrecision: 16})
+	if err == nil {
+		t.Errorf("expected error for out of range precision")
+	}
+}
+
+func TestEncode_MixedDimensions(t *testing.T) {
+	ls := &wire.LineString{Coordinates: [][]float64{{0, 1, 2}, {3, 4}}}
+	b, err := Encode(ls, nil)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	got, err := Decode(b)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	expected := &wire.LineString{Coordinates: [][]float64{{0, 1, 2}, {3, 4, 0}}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestRoundTrip_EncodeObject(t *testing.T) {
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				Geometry: &geojson.Polygon{
+					Rings: []geojson.LineString{
+						{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0}}},
+					},
+				},
+				Properties: map[string]interface{}{"name": "a"},
+				ID:         "7",
+			},
+		},
+	}
+	b, err := EncodeObject(fc, nil)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	got, err := DecodeObject(b)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if !reflect.DeepEqual(got, fc) {
+		t.Errorf("expected %#v, got %#v", fc, got)
+	}
+}
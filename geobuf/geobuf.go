@@ -0,0 +1,110 @@
+// Package geobuf encodes and decodes Geobuf, a compact protocol buffers
+// representation of GeoJSON.
+//
+// Coordinates are stored as delta-encoded integers scaled by a power of 10,
+// so they survive a round trip exactly as long as they have no more decimal
+// digits than the chosen precision. Property and foreign member names are
+// stored once in a shared key table. Bounding boxes and foreign members are
+// stored as Geobuf custom properties.
+//
+// All geometries in a message share the same number of dimensions: the
+// largest found while encoding. Positions with fewer coordinates are padded
+// with zeros.
+//
+// See https://github.com/mapbox/geobuf for the format definition.
+package geobuf
+
+import (
+	"fmt"
+
+	"github.com/bsidhom/geojson"
+)
+
+// DefaultPrecision is the number of digits after the decimal point that
+// Geobuf assumes when a message does not declare its precision.
+const DefaultPrecision = 6
+
+// Field numbers of the Data message and its nested messages.
+const (
+	dataKeys              = 1
+	dataDimensions        = 2
+	dataPrecision         = 3
+	dataFeatureCollection = 4
+	dataFeature           = 5
+	dataGeometry          = 6
+
+	featureGeometry = 1
+	featureID       = 11
+	featureIntID    = 12
+
+	geometryType       = 1
+	geometryLengths    = 2
+	geometryCoords     = 3
+	geometryGeometries = 4
+
+	featureCollectionFeatures = 1
+
+	// Shared by features, geometries, and feature collections.
+	fieldValues           = 13
+	fieldProperties       = 14
+	fieldCustomProperties = 15
+
+	valueString = 1
+	valueDouble = 2
+	valuePosInt = 3
+	valueNegInt = 4
+	valueBool   = 5
+	valueJSON   = 6
+)
+
+// Geometry types.
+const (
+	typePoint = iota
+	typeMultiPoint
+	typeLineString
+	typeMultiLineString
+	typePolygon
+	typeMultiPolygon
+	typeGeometryCollection
+)
+
+// Members that are stored in dedicated fields rather than as custom
+// properties.
+var (
+	featureCollectionMembers = []string{"type", "bbox", "features"}
+	featureMembers           = []string{"type", "bbox", "geometry", "properties", "id"}
+	geometryMembers          = []string{"type", "bbox", "coordinates", "geometries"}
+)
+
+// EncodeObject encodes a high-level object. It is equivalent to encoding
+// geojson.ToWire(obj).
+func EncodeObject(obj geojson.Object, opts *EncodeOptions) ([]byte, error) {
+	w := geojson.ToWire(obj)
+	if w == nil {
+		return nil, fmt.Errorf("geobuf: unsupported object type: %T", obj)
+	}
+	return Encode(w, opts)
+}
+
+// DecodeObject decodes b into a high-level object, validating it as
+// geojson.FromWire does.
+func DecodeObject(b []byte) (geojson.Object, error) {
+	w, err := Decode(b)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := geojson.FromWire(w)
+	if err != nil {
+		return nil, fmt.Errorf("geobuf: %v", err)
+	}
+	return obj, nil
+}
+
+func isMember(name string, members []string) bool {
+	for _, m := range members {
+		if name == m {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package geojson
+
+import (
+	"errors"
+	"math"
+
+	"github.com/bsidhom/geojson/internal/geodesic"
+)
+
+// The methods below solve geodesic problems between points on the WGS84
+// ellipsoid. Elevations are ignored. Unless noted otherwise, they use the
+// algorithms of C. F. F. Karney ("Algorithms for geodesics", 2013), which
+// are accurate to about 15 nanometres in distance and 1e-9 degrees in
+// bearing and always converge, even for antipodal points.
+
+// ErrNoConvergence is returned by VincentyDistance when Vincenty's iteration
+// fails to converge, which happens for nearly antipodal points.
+var ErrNoConvergence = errors.New("vincenty: failed to converge for nearly antipodal points")
+
+// Distance returns the length in metres of the shortest geodesic from p to q.
+func (p Point) Distance(q Point) float64 {
+	s12, _, _ := geodesic.WGS84.Inverse(p.Y, p.X, q.Y, q.X)
+	return s12
+}
+
+// HaversineDistance returns the great-circle distance in metres from p to q
+// on a sphere of radius MeanRadius. It is fast but errs by up to about 0.5%
+// compared to Distance.
+func (p Point) HaversineDistance(q Point) float64 {
+	return geodesic.Sphere{R: MeanRadius}.Distance(p.Y, p.X, q.Y, q.X)
+}
+
+// VincentyDistance returns the distance in metres from p to q using
+// Vincenty's inverse formula, which is accurate to about 0.5 millimetres.
+// For nearly antipodal points, where the iteration does not converge, it
+// returns ErrNoConvergence; Distance has no such limitation.
+func (p Point) VincentyDistance(q Point) (float64, error) {
+	const (
+		a       = 6378137
+		f       = 1 / 298.257223563
+		b       = a * (1 - f)
+		maxIter = 200
+	)
+	toRadians := math.Pi / 180
+	l := (q.X - p.X) * toRadians
+	u1 := math.Atan((1 - f) * math.Tan(p.Y*toRadians))
+	u2 := math.Atan((1 - f) * math.Tan(q.Y*toRadians))
+	sinU1, cosU1 := math.Sincos(u1)
+	sinU2, cosU2 := math.Sincos(u2)
+
+	lambda := l
+	var sinSigma, cosSigma, sigma, cos2Alpha, cos2SigmaM float64
+	converged := false
+	for i := 0; i < maxIter; i++ {
+		sinLambda, cosLambda := math.Sincos(lambda)
+		sinSigma = math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			// Coincident points.
+			return 0, nil
+		}
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha = 1 - sinAlpha*sinAlpha
+		cos2SigmaM = 0
+		if cos2Alpha != 0 {
+			// Otherwise, the line is equatorial.
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha
+		}
+		c := f / 16 * cos2Alpha * (4 + f*(4-3*cos2Alpha))
+		prev := lambda
+		lambda = l + (1-c)*f*sinAlpha*(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda) > math.Pi {
+			break
+		}
+		if math.Abs(lambda-prev) < 1e-12 {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return 0, ErrNoConvergence
+	}
+
+	u2sq := cos2Alpha * (a*a - b*b) / (b * b)
+	bigA := 1 + u2sq/16384*(4096+u2sq*(-768+u2sq*(320-175*u2sq)))
+	bigB := u2sq / 1024 * (256 + u2sq*(-128+u2sq*(74-47*u2sq)))
+	deltaSigma := bigB * sinSigma * (cos2SigmaM + bigB/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+		bigB/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+	return b * bigA * (sigma - deltaSigma), nil
+}
+
+// InitialBearing returns the bearing at p of the shortest geodesic from p to
+// q, in degrees clockwise from north in [0, 360). It returns 0 if p and q
+// coincide.
+func (p Point) InitialBearing(q Point) float64 {
+	if p.X == q.X && p.Y == q.Y {
+		return 0
+	}
+	_, azi1, _ := geodesic.WGS84.Inverse(p.Y, p.X, q.Y, q.X)
+	return bearing(azi1)
+}
+
+// FinalBearing returns the bearing at q of the shortest geodesic from p to
+// q, in degrees clockwise from north in [0, 360). It returns 0 if p and q
+// coincide.
+func (p Point) FinalBearing(q Point) float64 {
+	if p.X == q.X && p.Y == q.Y {
+		return 0
+	}
+	_, _, azi2 := geodesic.WGS84.Inverse(p.Y, p.X, q.Y, q.X)
+	return bearing(azi2)
+}
+
+// Destination returns the point reached by travelling distance metres from p
+// along the geodesic with the given initial bearing in degrees clockwise
+// from north. The longitude of the result lies in (-180, 180].
+func (p Point) Destination(bearing, distance float64) Point {
+	lat, lon, _ := geodesic.WGS84.Direct(p.Y, p.X, bearing, distance)
+	return Point{X: lon, Y: lat}
+}
+
+// Midpoint returns the point halfway along the shortest geodesic from p to
+// q, which is the ellipsoidal equivalent of the great-circle midpoint. The
+// longitude of the result lies in (-180, 180].
+func (p Point) Midpoint(q Point) Point {
+	s12, azi1, _ := geodesic.WGS84.Inverse(p.Y, p.X, q.Y, q.X)
+	if s12 == 0 {
+		return Point{X: geodesic.AngNormalize(p.X), Y: p.Y}
+	}
+	return p.Destination(azi1, s12/2)
+}
+
+// bearing converts an azimuth in [-180, 180] to a bearing in [0, 360).
+func bearing(azi float64) float64 {
+	b := math.Mod(azi+360, 360)
+	if b == 360 {
+		return 0
+	}
+	return b
+}
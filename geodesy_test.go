@@ -0,0 +1,84 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPoint_Distance(t *testing.T) {
+	jfk := Point{X: -73.8, Y: 40.6}
+	lhr := Point{X: -0.5, Y: 51.6}
+	if d := jfk.Distance(lhr); math.Abs(d-5551759.400319) > 1e-6 {
+		t.Errorf("expected Karney distance 5551759.400319, got %v", d)
+	}
+	d, err := jfk.VincentyDistance(lhr)
+	if err != nil || math.Abs(d-5551759.400319) > 1e-3 {
+		t.Errorf("expected Vincenty distance 5551759.400319, got (%v, %v)", d, err)
+	}
+	if d := jfk.HaversineDistance(lhr); math.Abs(d-5551759.400319) > 0.005*5551759.400319 {
+		t.Errorf("expected haversine distance within 0.5%% of 5551759.400319, got %v", d)
+	}
+	if d, err := jfk.VincentyDistance(jfk); d != 0 || err != nil {
+		t.Errorf("expected zero distance for coincident points, got (%v, %v)", d, err)
+	}
+
+	// Vincenty's formula fails for nearly antipodal points, but Distance
+	// does not.
+	p := Point{X: 0, Y: 0}
+	q := Point{X: 179.7, Y: 0.5}
+	if _, err := p.VincentyDistance(q); err != ErrNoConvergence {
+		t.Errorf("expected ErrNoConvergence, got %v", err)
+	}
+	if d := p.Distance(q); math.Abs(d-19944127.420750) > 1e-6 {
+		t.Errorf("expected distance 19944127.420750, got %v", d)
+	}
+}
+
+func TestPoint_Bearing(t *testing.T) {
+	cases := []struct {
+		p, q           Point
+		initial, final float64
+	}{
+		{Point{X: -73.8, Y: 40.6}, Point{X: -0.5, Y: 51.6}, 51.198882845580, 107.821776735514},
+		// Due west, which has an azimuth of -90.
+		{Point{X: 10, Y: 0}, Point{X: 0, Y: 0}, 270, 270},
+		{Point{X: 10, Y: 0}, Point{X: 10, Y: 0}, 0, 0},
+	}
+	for i, c := range cases {
+		initial := c.p.InitialBearing(c.q)
+		final := c.p.FinalBearing(c.q)
+		if math.Abs(initial-c.initial) > 1e-9 || math.Abs(final-c.final) > 1e-9 {
+			t.Errorf("case %d: expected (%v, %v), got (%v, %v)", i, c.initial, c.final, initial, final)
+		}
+	}
+}
+
+func TestPoint_Destination(t *testing.T) {
+	p := Point{X: 115.74, Y: -32.06, Elevation: 10, HasElevation: true}
+	q := p.Destination(225, 20000e3)
+	if math.Abs(q.X+63.95925278) > 1e-8 || math.Abs(q.Y-32.11195529) > 1e-8 || q.HasElevation {
+		t.Errorf("expected (-63.95925278, 32.11195529), got %#v", q)
+	}
+}
+
+func TestPoint_Midpoint(t *testing.T) {
+	cases := []struct {
+		p, q     Point
+		expected Point
+	}{
+		{Point{X: 0, Y: 0}, Point{X: 90, Y: 0}, Point{X: 45, Y: 0}},
+		{Point{X: 170, Y: 0}, Point{X: -170, Y: 0}, Point{X: 180, Y: 0}},
+		{Point{X: 0, Y: -10}, Point{X: 0, Y: 10}, Point{X: 0, Y: 0}},
+		{Point{X: 5, Y: 5}, Point{X: 5, Y: 5}, Point{X: 5, Y: 5}},
+	}
+	for i, c := range cases {
+		m := c.p.Midpoint(c.q)
+		if math.Abs(m.X-c.expected.X) > 1e-9 || math.Abs(m.Y-c.expected.Y) > 1e-9 {
+			t.Errorf("case %d: expected %#v, got %#v", i, c.expected, m)
+		}
+		// The midpoint is equidistant from both ends.
+		if d1, d2 := c.p.Distance(m), m.Distance(c.q); math.Abs(d1-d2) > 1e-6 {
+			t.Errorf("case %d: expected equal distances, got %v and %v", i, d1, d2)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+// Package georss converts between RSS and Atom feeds with GeoRSS locations
+// and GeoJSON FeatureCollections.
+//
+// Both GeoRSS encodings are read: GeoRSS Simple (point, line, polygon, and
+// box) and GeoRSS GML (a GML Point, LineString, Polygon, or Envelope inside
+// a where element). The W3C Basic Geo lat and long elements used by some
+// older feeds are read as well. GeoRSS coordinates are always latitude
+// first; they are swapped to longitude first on input and back on output.
+// GeoRSS does not fix the winding of polygons, so they are rewound to wind
+// counter-clockwise, as RFC 7946 requires.
+package georss
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/gml"
+	"github.com/bsidhom/geojson/internal/ring"
+	"github.com/bsidhom/geojson/internal/xmltree"
+)
+
+// XML namespaces used by GeoRSS feeds.
+const (
+	AtomNamespace   = "http://www.w3.org/2005/Atom"
+	Namespace       = "http://www.georss.org/georss"
+	GMLNamespace    = "http://www.opengis.net/gml"
+	W3CGeoNamespace = "http://www.w3.org/2003/01/geo/wgs84_pos#"
+)
+
+// Decode reads an RSS or Atom feed and returns its items or entries as a
+// FeatureCollection, in document order. Items without a location have a nil
+// Geometry.
+//
+// Simple child elements of each entry, such as title, summary, description,
+// updated, and pubDate, are stored as string properties by local name. The
+// href of an Atom link (preferring rel="alternate"), the name of an Atom
+// author, and the first category are stored under "link", "author", and
+// "category". The Atom id or RSS guid becomes the Feature ID. Other GeoRSS
+// elements such as featurename and radius are stored as properties, except
+// elev, which becomes the elevation of a Point.
+func Decode(r io.Reader) (*geojson.FeatureCollection, error) {
+	d := xml.NewDecoder(r)
+	fc := &geojson.FeatureCollection{}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("georss: %v", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || (start.Name.Local != "item" && start.Name.Local != "entry") {
+			continue
+		}
+		var n xmltree.Node
+		err = d.DecodeElement(&n, &start)
+		if err != nil {
+			return nil, fmt.Errorf("georss: %v", err)
+		}
+		f, err := decodeEntry(&n)
+		if err != nil {
+			return nil, fmt.Errorf("georss: entry %d: %v", len(fc.Features), err)
+		}
+		fc.Features = append(fc.Features, *f)
+	}
+	return fc, nil
+}
+
+func decodeEntry(n *xmltree.Node) (*geojson.Feature, error) {
+	f := &geojson.Feature{Properties: map[string]interface{}{}}
+	var elev *float64
+	var lat, long *xmltree.Node
+	for i := range n.Nodes {
+		child := &n.Nodes[i]
+		name := child.XMLName.Local
+		switch child.XMLName.Space {
+		case Namespace:
+			switch name {
+			case "point", "line", "polygon", "box", "where":
+				if f.Geometry != nil {
+					return nil, fmt.Errorf("multiple locations")
+				}
+				g, err := decodeGeometry(child)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %v", name, err)
+				}
+				f.Geometry = g
+			case "elev":
+				v, err := strconv.ParseFloat(child.Text(), 64)
+				if err != nil {
+					return nil, fmt.Errorf("elev: %v", err)
+				}
+				elev = &v
+			default:
+				f.Properties[name] = child.Text()
+			}
+			continue
+		case W3CGeoNamespace:
+			switch name {
+			case "lat":
+				lat = child
+			case "long":
+				long = child
+			case "Point":
+				lat, long = child.Child("lat"), child.Child("long")
+			}
+			continue
+		}
+		switch name {
+		case "id", "guid":
+			f.ID = child.Text()
+		case "link":
+			if href, ok := child.Attr("href"); ok {
+				rel, _ := child.Attr("rel")
+				if _, seen := f.Properties["link"]; !seen || rel == "" || rel == "alternate" {
+					f.Properties["link"] = href
+				}
+			} else if len(child.Nodes) == 0 {
+				f.Properties["link"] = child.Text()
+			}
+		case "author":
+			if author := child.Child("name"); author != nil {
+				f.Properties["author"] = author.Text()
+			} else {
+				f.Properties["author"] = child.Text()
+			}
+		case "category":
+			if _, seen := f.Properties["category"]; seen {
+				continue
+			}
+			if term, ok := child.Attr("term"); ok {
+				f.Properties["category"] = term
+			} else {
+				f.Properties["category"] = child.Text()
+			}
+		default:
+			if len(child.Nodes) == 0 {
+				f.Properties[name] = child.Text()
+			}
+		}
+	}
+	if f.Geometry == nil && lat != nil && long != nil {
+		p, err := parsePoints(lat.Text() + " " + long.Text())
+		if err != nil {
+			return nil, fmt.Errorf("geo: %v", err)
+		}
+		f.Geometry = &p[0]
+	}
+	if p, ok := f.Geometry.(*geojson.Point); ok && elev != nil {
+		p.Elevation = *elev
+		p.HasElevation = true
+	}
+	return f, nil
+}
+
+func decodeGeometry(n *xmltree.Node) (geojson.Geometry, error) {
+	g, err := decodeShape(n)
+	if p, ok := g.(*geojson.Polygon); ok {
+		return ring.Rewind(p), err
+	}
+	return g, err
+}
+
+func decodeShape(n *xmltree.Node) (geojson.Geometry, error) {
+	if n.XMLName.Local == "where" {
+		return decodeWhere(n)
+	}
+	points, err := parsePoints(n.Text())
+	if err != nil {
+		return nil, err
+	}
+	switch n.XMLName.Local {
+	case "point":
+		if len(points) != 1 {
+			return nil, fmt.Errorf("must have exactly 1 position, got %d", len(points))
+		}
+		return &points[0], nil
+	case "line":
+		if len(points) < 2 {
+			return nil, fmt.Errorf("must have at least 2 positions, got %d", len(points))
+		}
+		return &geojson.LineString{Points: points}, nil
+	case "polygon":
+		if len(points) < 4 {
+			return nil, fmt.Errorf("must have at least 4 positions, got %d", len(points))
+		}
+		if points[0] != points[len(points)-1] {
+			return nil, fmt.Errorf("ring is not closed")
+		}
+		return &geojson.Polygon{Rings: []geojson.LineString{{Points: points}}}, nil
+	case "box":
+		if len(points) != 2 {
+			return nil, fmt.Errorf("must have exactly 2 positions, got %d", len(points))
+		}
+		lo, hi := points[0], points[1]
+		return &geojson.Polygon{Rings: []geojson.LineString{{Points: []geojson.Point{
+			lo, {X: hi.X, Y: lo.Y}, hi, {X: lo.X, Y: hi.Y}, lo,
+		}}}}, nil
+	}
+	return nil, fmt.Errorf("unsupported element")
+}
+
+// decodeWhere decodes the GML geometry of a where element. GeoRSS GML is
+// latitude first unless stated otherwise, as the EPSG URN for WGS84
+// declares, so that srsName is supplied when the geometry has none.
+func decodeWhere(n *xmltree.Node) (geojson.Geometry, error) {
+	if len(n.Nodes) != 1 {
+		return nil, fmt.Errorf("must have exactly 1 geometry, got %d", len(n.Nodes))
+	}
+	g := n.Nodes[0]
+	if _, ok := g.Attr("srsName"); !ok {
+		g.Attrs = append(g.Attrs[:len(g.Attrs):len(g.Attrs)], xml.Attr{
+			Name:  xml.Name{Local: "srsName"},
+			Value: "urn:ogc:def:crs:EPSG::4326",
+		})
+	}
+	b, err := xml.Marshal(&g)
+	if err != nil {
+		return nil, err
+	}
+	return gml.Unmarshal(b)
+}
+
+// parsePoints parses whitespace-separated latitude and longitude pairs.
+func parsePoints(s string) ([]geojson.Point, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields)%2 != 0 {
+		return nil, fmt.Errorf("expected latitude and longitude pairs, got %d values", len(fields))
+	}
+	points := make([]geojson.Point, len(fields)/2)
+	for i := range points {
+		lat, err := strconv.ParseFloat(fields[2*i], 64)
+		if err != nil {
+			return nil, err
+		}
+		lon, err := strconv.ParseFloat(fields[2*i+1], 64)
+		if err != nil {
+			return nil, err
+		}
+		points[i] = geojson.Point{X: lon, Y: lat}
+	}
+	return points, nil
+}
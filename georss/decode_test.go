@@ -0,0 +1,202 @@
+package georss
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestDecode_Atom(t *testing.T) {
+	s := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom"
+      xmlns:georss="http://www.georss.org/georss"
+      xmlns:gml="http://www.opengis.net/gml">
+  <title>Earthquakes</title>
+  <id>urn:example:quakes</id>
+  <updated>2024-01-02T03:04:05Z</updated>
+  <entry>
+    <id>urn:example:quake:1</id>
+    <title>M 4.2 - 10 km N of Somewhere</title>
+    <updated>2024-01-02T03:00:00Z</updated>
+    <link rel="related" href="https://example.com/related"/>
+    <link rel="alternate" type="text/html" href="https://example.com/quake/1"/>
+    <summary type="html">&lt;p&gt;Depth 10 km&lt;/p&gt;</summary>
+    <author><name>Survey</name></author>
+    <category term="earthquake"/>
+    <category term="alert"/>
+    <georss:point>37.7749 -122.4194</georss:point>
+    <georss:elev>-10000</georss:elev>
+  </entry>
+  <entry>
+    <id>urn:example:warning:2</id>
+    <title>Flood warning</title>
+    <georss:where>
+      <gml:Polygon>
+        <gml:exterior>
+          <gml:LinearRing>
+            <gml:posList>45 -110 45 -109 46 -109 45 -110</gml:posList>
+          </gml:LinearRing>
+        </gml:exterior>
+      </gml:Polygon>
+    </georss:where>
+    <georss:featurename>River basin</georss:featurename>
+  </entry>
+  <entry>
+    <id>urn:example:note:3</id>
+    <title>No location</title>
+  </entry>
+</feed>`
+	fc, err := Decode(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	expected := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				ID:       "urn:example:quake:1",
+				Geometry: &geojson.Point{X: -122.4194, Y: 37.7749, Elevation: -10000, HasElevation: true},
+				Properties: map[string]interface{}{
+					"title":    "M 4.2 - 10 km N of Somewhere",
+					"updated":  "2024-01-02T03:00:00Z",
+					"link":     "https://example.com/quake/1",
+					"summary":  "<p>Depth 10 km</p>",
+					"author":   "Survey",
+					"category": "earthquake",
+				},
+			},
+			{
+				ID: "urn:example:warning:2",
+				Geometry: &geojson.Polygon{Rings: []geojson.LineString{{Points: []geojson.Point{
+					{X: -110, Y: 45}, {X: -109, Y: 45}, {X: -109, Y: 46}, {X: -110, Y: 45},
+				}}}},
+				Properties: map[string]interface{}{
+					"title":       "Flood warning",
+					"featurename": "River basin",
+				},
+			},
+			{
+				ID:         "urn:example:note:3",
+				Properties: map[string]interface{}{"title": "No location"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(fc, expected) {
+		t.Errorf("expected %#v, got %#v", expected, fc)
+	}
+}
+
+func TestDecode_RSS(t *testing.T) {
+	s := `<?xml version="1.0"?>
+<rss version="2.0" xmlns:georss="http://www.georss.org/georss" xmlns:geo="http://www.w3.org/2003/01/geo/wgs84_pos#">
+  <channel>
+    <title>Warnings</title>
+    <item>
+      <title>Wind</title>
+      <link>https://example.com/1</link>
+      <guid>w1</guid>
+      <pubDate>Tue, 02 Jan 2024 03:04:05 GMT</pubDate>
+      <georss:line>45.256 -110.45 46.46 -109.48 43.84 -109.86</georss:line>
+    </item>
+    <item>
+      <title>Fog</title>
+      <georss:box>42.943 -71.032 43.039 -69.856</georss:box>
+    </item>
+    <item>
+      <title>Legacy</title>
+      <geo:lat>55.701</geo:lat>
+      <geo:long>12.552</geo:long>
+    </item>
+  </channel>
+</rss>`
+	fc, err := Decode(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	expected := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				ID: "w1",
+				Geometry: &geojson.LineString{Points: []geojson.Point{
+					{X: -110.45, Y: 45.256}, {X: -109.48, Y: 46.46}, {X: -109.86, Y: 43.84},
+				}},
+				Properties: map[string]interface{}{
+					"title":   "Wind",
+					"link":    "https://example.com/1",
+					"pubDate": "Tue, 02 Jan 2024 03:04:05 GMT",
+				},
+			},
+			{
+				Geometry: &geojson.Polygon{Rings: []geojson.LineString{{Points: []geojson.Point{
+					{X: -71.032, Y: 42.943}, {X: -69.856, Y: 42.943}, {X: -69.856, Y: 43.039},
+					{X: -71.032, Y: 43.039}, {X: -71.032, Y: 42.943},
+				}}}},
+				Properties: map[string]interface{}{"title": "Fog"},
+			},
+			{
+				Geometry:   &geojson.Point{X: 12.552, Y: 55.701},
+				Properties: map[string]interface{}{"title": "Legacy"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(fc, expected) {
+		t.Errorf("expected %#v, got %#v", expected, fc)
+	}
+}
+
+func TestDecode_Winding(t *testing.T) {
+	// Clockwise polygons are rewound counter-clockwise.
+	ccw := []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}, {X: 0, Y: 0}}
+	cases := []struct {
+		item     string
+		expected []geojson.Point
+	}{
+		{`<georss:polygon>0 0 1 0 1 1 0 1 0 0</georss:polygon>`, ccw},
+		{`<georss:polygon>0 0 0 1 1 1 1 0 0 0</georss:polygon>`, ccw},
+		{
+			`<georss:box>0 1 1 0</georss:box>`,
+			[]geojson.Point{{X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}, {X: 0, Y: 0}, {X: 1, Y: 0}},
+		},
+		{
+			`<georss:where><gml:Polygon><gml:exterior><gml:LinearRing>
+  <gml:posList>0 0 1 0 1 1 0 1 0 0</gml:posList>
+</gml:LinearRing></gml:exterior></gml:Polygon></georss:where>`,
+			ccw,
+		},
+	}
+	for i, c := range cases {
+		s := `<rss xmlns:georss="http://www.georss.org/georss" xmlns:gml="http://www.opengis.net/gml"><channel><item>` +
+			c.item + `</item></channel></rss>`
+		fc, err := Decode(strings.NewReader(s))
+		if err != nil {
+			t.Errorf("case %d: failed to decode: %v", i, err)
+			continue
+		}
+		expected := &geojson.Polygon{Rings: []geojson.LineString{{Points: c.expected}}}
+		if g := fc.Features[0].Geometry; !reflect.DeepEqual(g, expected) {
+			t.Errorf("case %d: expected %v, got %v", i, expected, g)
+		}
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	items := []string{
+		`<georss:point>1</georss:point>`,
+		`<georss:point>1 2 3 4</georss:point>`,
+		`<georss:line>1 2</georss:line>`,
+		`<georss:polygon>0 0 1 1 0 1 0 2</georss:polygon>`,
+		`<georss:box>0 0 1</georss:box>`,
+		`<georss:point>1 2</georss:point><georss:point>3 4</georss:point>`,
+		`<georss:point>1 2</georss:point><georss:elev>high</georss:elev>`,
+		`<georss:where><gml:Point><gml:pos>1</gml:pos></gml:Point></georss:where>`,
+	}
+	for i, item := range items {
+		s := `<rss xmlns:georss="http://www.georss.org/georss" xmlns:gml="http://www.opengis.net/gml"><channel><item>` +
+			item + `</item></channel></rss>`
+		_, err := Decode(strings.NewReader(s))
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
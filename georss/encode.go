@@ -0,0 +1,161 @@
+package georss
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/xmltree"
+)
+
+// DefaultFeedID is the feed id written when EncodeOptions.ID is empty.
+const DefaultFeedID = "urn:x-geojson:feed"
+
+// EncodeOptions controls the feed-level elements of written feeds. The zero
+// value is valid.
+type EncodeOptions struct {
+	// Title is the feed title.
+	Title string
+	// ID is the feed id. If empty, DefaultFeedID is used.
+	ID string
+	// Updated is the time the feed was last updated. If zero, the current
+	// time is used.
+	Updated time.Time
+}
+
+// Encode writes fc to w as an Atom feed with one entry per Feature and
+// locations in GeoRSS Simple.
+//
+// The Feature ID becomes the entry id; Features without one are given the
+// feed id followed by "#" and their index. String "title", "summary",
+// "content", "updated", "published", "author", "category", and "link"
+// properties become the corresponding Atom elements, with "updated"
+// defaulting to the feed's. Other properties cannot be represented in Atom
+// and are not written.
+//
+// Points, LineStrings, and Polygons without holes are written as point,
+// line, and polygon elements. Point elevations are written as elev. Other
+// geometries cannot be represented in GeoRSS Simple and are rejected.
+func Encode(w io.Writer, fc *geojson.FeatureCollection, opts *EncodeOptions) error {
+	if opts == nil {
+		opts = &EncodeOptions{}
+	}
+	feedID := opts.ID
+	if feedID == "" {
+		feedID = DefaultFeedID
+	}
+	updated := opts.Updated
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	feedUpdated := updated.UTC().Format(time.RFC3339)
+
+	feed := xmltree.Element("feed",
+		xmltree.TextElement("title", opts.Title),
+		xmltree.TextElement("id", feedID),
+		xmltree.TextElement("updated", feedUpdated),
+	)
+	feed.Attrs = []xml.Attr{
+		{Name: xml.Name{Local: "xmlns"}, Value: AtomNamespace},
+		{Name: xml.Name{Local: "xmlns:georss"}, Value: Namespace},
+	}
+	for i := range fc.Features {
+		f := &fc.Features[i]
+		id := f.ID
+		if id == "" {
+			id = feedID + "#" + strconv.Itoa(i)
+		}
+		entry, err := encodeEntry(f, id, feedUpdated)
+		if err != nil {
+			return fmt.Errorf("georss: feature %d: %v", i, err)
+		}
+		feed.Nodes = append(feed.Nodes, *entry)
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	err = e.Encode(&feed)
+	if err != nil {
+		return fmt.Errorf("georss: %v", err)
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+func encodeEntry(f *geojson.Feature, id, feedUpdated string) (*xmltree.Node, error) {
+	property := func(k string) string {
+		s, _ := f.Properties[k].(string)
+		return s
+	}
+	updated := property("updated")
+	if updated == "" {
+		updated = feedUpdated
+	}
+	entry := xmltree.Element("entry",
+		xmltree.TextElement("title", property("title")),
+		xmltree.TextElement("id", id),
+		xmltree.TextElement("updated", updated),
+	)
+	if s := property("published"); s != "" {
+		entry.Nodes = append(entry.Nodes, xmltree.TextElement("published", s))
+	}
+	if s := property("author"); s != "" {
+		entry.Nodes = append(entry.Nodes, xmltree.Element("author", xmltree.TextElement("name", s)))
+	}
+	if s := property("link"); s != "" {
+		link := xmltree.Element("link")
+		link.Attrs = []xml.Attr{{Name: xml.Name{Local: "href"}, Value: s}}
+		entry.Nodes = append(entry.Nodes, link)
+	}
+	if s := property("category"); s != "" {
+		category := xmltree.Element("category")
+		category.Attrs = []xml.Attr{{Name: xml.Name{Local: "term"}, Value: s}}
+		entry.Nodes = append(entry.Nodes, category)
+	}
+	for _, k := range []string{"summary", "content"} {
+		if s := property(k); s != "" {
+			entry.Nodes = append(entry.Nodes, xmltree.TextElement(k, s))
+		}
+	}
+
+	if f.Geometry == nil {
+		return &entry, nil
+	}
+	switch t := f.Geometry.(type) {
+	case *geojson.Point:
+		entry.Nodes = append(entry.Nodes, xmltree.TextElement("georss:point", formatPoints([]geojson.Point{*t})))
+		if t.HasElevation {
+			entry.Nodes = append(entry.Nodes, xmltree.TextElement("georss:elev", formatFloat(t.Elevation)))
+		}
+	case *geojson.LineString:
+		entry.Nodes = append(entry.Nodes, xmltree.TextElement("georss:line", formatPoints(t.Points)))
+	case *geojson.Polygon:
+		if len(t.Rings) != 1 {
+			return nil, fmt.Errorf("polygons with holes are not supported")
+		}
+		entry.Nodes = append(entry.Nodes, xmltree.TextElement("georss:polygon", formatPoints(t.Rings[0].Points)))
+	default:
+		return nil, fmt.Errorf("unsupported geometry type: %T", t)
+	}
+	return &entry, nil
+}
+
+func formatPoints(points []geojson.Point) string {
+	values := make([]string, 0, 2*len(points))
+	for _, p := range points {
+		values = append(values, formatFloat(p.Y), formatFloat(p.X))
+	}
+	return strings.Join(values, " ")
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,105 @@
+package georss
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestRoundTrip_Encode(t *testing.T) {
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				ID:       "urn:example:1",
+				Geometry: &geojson.Point{X: -122.4194, Y: 37.7749, Elevation: 12.5, HasElevation: true},
+				Properties: map[string]interface{}{
+					"title":     "Quake",
+					"updated":   "2024-01-02T03:00:00Z",
+					"published": "2024-01-02T02:00:00Z",
+					"summary":   "<b>M 4.2</b>",
+					"author":    "Survey",
+					"category":  "earthquake",
+					"link":      "https://example.com/1",
+				},
+			},
+			{
+				ID: "urn:example:2",
+				Geometry: &geojson.LineString{Points: []geojson.Point{
+					{X: 0, Y: 0}, {X: 1.5, Y: -2},
+				}},
+				Properties: map[string]interface{}{
+					"title":   "Route",
+					"updated": "2024-01-01T00:00:00Z",
+				},
+			},
+			{
+				ID: "urn:example:3",
+				Geometry: &geojson.Polygon{Rings: []geojson.LineString{{Points: []geojson.Point{
+					{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0},
+				}}}},
+				Properties: map[string]interface{}{
+					"title":   "Zone",
+					"updated": "2024-01-01T00:00:00Z",
+				},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	err := Encode(&buf, fc, &EncodeOptions{Title: "Test", ID: "urn:example:feed"})
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	result, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if !reflect.DeepEqual(result, fc) {
+		t.Errorf("expected %#v, got %#v", fc, result)
+	}
+}
+
+func TestEncode_Defaults(t *testing.T) {
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{Geometry: &geojson.Point{X: 1, Y: 2}, Properties: map[string]interface{}{"kind": "ignored"}},
+		},
+	}
+	var buf bytes.Buffer
+	updated := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", 3600))
+	err := Encode(&buf, fc, &EncodeOptions{Updated: updated})
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	s := buf.String()
+	for _, expected := range []string{
+		`<id>urn:x-geojson:feed#0</id>`,
+		`<updated>2024-01-02T02:04:05Z</updated>`,
+		`<georss:point>2 1</georss:point>`,
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("expected output to contain %s, got %s", expected, s)
+		}
+	}
+	if strings.Contains(s, "ignored") {
+		t.Errorf("expected unknown properties to be dropped, got %s", s)
+	}
+}
+
+func TestEncode_Invalid(t *testing.T) {
+	square := geojson.LineString{Points: []geojson.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 0}}}
+	geometries := []geojson.Geometry{
+		&geojson.MultiPoint{Points: []geojson.Point{{X: 1, Y: 2}}},
+		&geojson.Polygon{Rings: []geojson.LineString{square, square}},
+	}
+	for i, g := range geometries {
+		fc := &geojson.FeatureCollection{Features: []geojson.Feature{{Geometry: g}}}
+		err := Encode(&bytes.Buffer{}, fc, nil)
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
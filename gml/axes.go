@@ -0,0 +1,179 @@
+package gml
+
+import "sort"
+
+// A codeRange is an inclusive range of EPSG codes.
+type codeRange struct {
+	lo, hi int
+}
+
+// northingFirstCodes lists the EPSG coordinate reference systems whose first
+// axis is latitude or northing, in order. It covers the geographic 2D and 3D
+// systems and the projected systems whose axes are declared north before
+// east, such as the Gauss–Krüger and Nordic national grids and the
+// pan-European ETRS89 grids. Geocentric systems, whose axes are X, Y, and Z,
+// and projected systems in the geographic range are left out.
+var northingFirstCodes = []codeRange{
+	{2166, 2168}, // Pulkovo 1942(83) / Gauss-Kruger zones 3-5
+	{2172, 2180}, // Pulkovo 1942(58) and ETRS89 / Poland
+	{2193, 2193}, // NZGD2000 / New Zealand Transverse Mercator 2000
+	{2391, 2394}, // KKJ / Finland zones 1-4
+	{2443, 2461}, // JGD2000 / Japan Plane Rectangular CS
+	{2463, 2758}, // Pulkovo 1942 and 1995 / Gauss-Kruger
+	{3006, 3030}, // SWEREF99 and RT90 / Sweden
+	{3034, 3035}, // ETRS89-extended / LCC Europe and LAEA Europe
+	{3038, 3051}, // ETRS89 / TM26-TM39
+	{3059, 3059}, // LKS92 / Latvia TM
+	{3120, 3120}, // Pulkovo 1942(58) / Poland zone I
+	{3301, 3301}, // Estonian Coordinate System of 1997
+	{3328, 3335}, // Pulkovo 1942(58) / GUGiK-80 and Gauss-Kruger
+	{3346, 3346}, // LKS94 / Lithuania TM
+	{3386, 3387}, // KKJ / Finland zones 0 and 5
+	{3396, 3399}, // PD/83 and RD/83 / 3-degree Gauss-Kruger
+	{3416, 3416}, // ETRS89 / Austria Lambert
+	{3824, 3824}, // TWD97 (geographic)
+	{3844, 3844}, // Pulkovo 1942(58) / Stereo70
+	{3873, 3885}, // ETRS89 / GK19FIN-GK31FIN
+	{3889, 3889}, // IGRS (geographic)
+	{3906, 3906}, // MGI 1901 (geographic)
+	{4001, 4025}, // Geographic
+	{4027, 4036}, // Geographic
+	{4039, 4047}, // Geographic
+	{4052, 4055}, // Geographic
+	{4064, 4070}, // Geographic
+	{4072, 4081}, // Geographic
+	{4084, 4086}, // Geographic
+	{4089, 4092}, // Geographic
+	{4101, 4216}, // Geographic
+	{4218, 4327}, // Geographic
+	{4329, 4329}, // WGS 84 (geographic 3D)
+	{4339, 4339}, // Australian Antarctic (geographic 3D)
+	// Geographic 3D, alternating with geocentric.
+	{4341, 4341}, {4343, 4343}, {4345, 4345}, {4347, 4347}, {4349, 4349},
+	{4351, 4351}, {4353, 4353}, {4355, 4355}, {4357, 4357}, {4359, 4359},
+	{4361, 4361}, {4363, 4363}, {4365, 4365}, {4367, 4367}, {4369, 4369},
+	{4371, 4371}, {4373, 4373}, {4375, 4375}, {4377, 4377}, {4379, 4379},
+	{4381, 4381}, {4383, 4383}, {4385, 4385}, {4387, 4387}, {4389, 4389},
+	{4416, 4417}, // Geographic; Pulkovo 1942(83) / 3-degree Gauss-Kruger zone 7
+	{4434, 4434}, // Pulkovo 1942(83) / 3-degree Gauss-Kruger zone 8
+	{4435, 4436}, // Geographic
+	{4440, 4454}, // Geographic
+	{4458, 4461}, // Geographic
+	{4463, 4464}, // Geographic
+	{4466, 4466}, // Geographic 3D
+	{4469, 4470}, // Geographic
+	{4472, 4472}, // Geographic
+	{4475, 4478}, // Geographic
+	{4480, 4480}, // Geographic 3D
+	{4482, 4483}, // Geographic
+	{4490, 4555}, // CGCS2000 (geographic) and CGCS2000 / Gauss-Kruger
+	{4557, 4558}, // Geographic
+	{4560, 4646}, // Geographic; New Beijing / Gauss-Kruger
+	{4648, 4765}, // Geographic
+	{4766, 4811}, // New Beijing / 3-degree Gauss-Kruger; geographic
+	{4813, 4821}, // Geographic
+	{4823, 4825}, // Geographic
+	{4827, 4838}, // Geographic
+	{4839, 4839}, // ETRS89 / LCC Germany (N-E)
+	{4840, 4854}, // Geographic
+	{4855, 4881}, // ETRS89 / NTM zones 5-30; geographic
+	// Geographic 3D, alternating with geocentric.
+	{4883, 4883}, {4885, 4885}, {4887, 4887}, {4889, 4889}, {4891, 4891},
+	{4893, 4893}, {4895, 4895},
+	{4898, 4905}, // Geographic 2D and 3D
+	{4907, 4907}, // RGNC91-93 (geographic 3D)
+	{4909, 4909}, // NAD83(CSRS) (geographic 3D)
+	// Geographic 3D, alternating with geocentric.
+	{4921, 4921}, {4923, 4923}, {4925, 4925}, {4927, 4927}, {4929, 4929},
+	{4931, 4931}, {4933, 4933}, {4935, 4935}, {4937, 4937}, {4939, 4939},
+	{4941, 4941}, {4943, 4943}, {4945, 4945}, {4947, 4947}, {4949, 4949},
+	{4951, 4951}, {4953, 4953}, {4955, 4955}, {4957, 4957}, {4959, 4959},
+	{4961, 4961}, {4963, 4963}, {4965, 4965}, {4967, 4967}, {4969, 4969},
+	{4971, 4971}, {4973, 4973}, {4975, 4975}, {4977, 4977}, {4979, 4979},
+	{4981, 4981}, {4983, 4983}, {4985, 4985}, {4987, 4987}, {4989, 4989},
+	{4991, 4991}, {4993, 4993}, {4995, 4995}, {4997, 4997}, {4999, 4999},
+	{5013, 5013},   // PTRA08 (geographic)
+	{5105, 5129},   // ETRS89 / NTM zones 5-29
+	{5132, 5132},   // Tokyo 1892 (geographic)
+	{5173, 5188},   // Korean 1985 and Korea 2000 grids
+	{5228, 5229},   // S-JTSK/05 (geographic)
+	{5233, 5233},   // SLD99 (geographic)
+	{5246, 5246},   // GDBD2009 (geographic)
+	{5252, 5252},   // TUREF (geographic)
+	{5264, 5264},   // DRUKREF 03 (geographic)
+	{5324, 5324},   // ISN2004 (geographic)
+	{5340, 5340},   // POSGAR 2007 (geographic)
+	{5354, 5354},   // MARGEN (geographic)
+	{5360, 5360},   // SIRGAS-Chile (geographic)
+	{5365, 5365},   // CR05 (geographic)
+	{5371, 5371},   // MACARIO SOLIS (geographic)
+	{5373, 5373},   // Peru96 (geographic)
+	{5381, 5381},   // SIRGAS-ROU98 (geographic)
+	{5393, 5393},   // SIRGAS_ES2007.8 (geographic)
+	{5451, 5451},   // Ocotepeque 1935 (geographic)
+	{5464, 5464},   // Sibun Gorge 1922 (geographic)
+	{5467, 5467},   // Panama-Colon 1911 (geographic)
+	{5489, 5489},   // RGAF09 (geographic)
+	{5520, 5520},   // DHDN / 3-degree Gauss-Kruger zone 1
+	{5524, 5524},   // Corrego Alegre 1961 (geographic)
+	{5527, 5527},   // SAD69(96) (geographic)
+	{5546, 5546},   // PNG94 (geographic)
+	{5561, 5561},   // UCS-2000 (geographic)
+	{5593, 5593},   // FEH2010 (geographic)
+	{5681, 5681},   // DB_REF (geographic)
+	{5886, 5886},   // TGD2005 (geographic)
+	{6135, 6135},   // CIGD11 (geographic)
+	{6207, 6207},   // Nepal 1981 (geographic)
+	{6311, 6311},   // CGRS93 (geographic)
+	{6318, 6319},   // NAD83(2011) (geographic 2D and 3D)
+	{6322, 6322},   // NAD83(PA11) (geographic)
+	{6325, 6325},   // NAD83(MA11) (geographic)
+	{6365, 6365},   // Mexico ITRF92 (geographic)
+	{6668, 6668},   // JGD2011 (geographic)
+	{6669, 6687},   // JGD2011 / Japan Plane Rectangular CS
+	{6706, 6706},   // RDN2008 (geographic)
+	{6783, 6783},   // NAD83(CORS96) (geographic)
+	{7073, 7073},   // RGTAAF07 (geographic)
+	{7136, 7136},   // IGD05 (geographic)
+	{7139, 7139},   // IGD05/12 (geographic)
+	{7373, 7373},   // ONGD14 (geographic)
+	{7683, 7683},   // GSK-2011 (geographic)
+	{7686, 7686},   // Kyrg-06 (geographic)
+	{7798, 7798},   // BGS2005 (geographic)
+	{7843, 7844},   // GDA2020 (geographic 3D and 2D)
+	{7881, 7881},   // St. Helena Tritan (geographic)
+	{7886, 7886},   // SHGD2015 (geographic)
+	{8042, 8043},   // Gusterberg and St. Stephen (Ferro) (geographic)
+	{8086, 8086},   // ISN2016 (geographic)
+	{8232, 8232},   // NAD83(CSRS96) (geographic)
+	{8237, 8237},   // NAD83(CSRS)v2 (geographic)
+	{8240, 8240},   // NAD83(CSRS)v3 (geographic)
+	{8246, 8246},   // NAD83(CSRS)v4 (geographic)
+	{8249, 8249},   // NAD83(CSRS)v5 (geographic)
+	{8252, 8252},   // NAD83(CSRS)v6 (geographic)
+	{8255, 8255},   // NAD83(CSRS)v7 (geographic)
+	{8351, 8351},   // S-JTSK [JTSK03] (geographic)
+	{8427, 8427},   // Hong Kong Geodetic CS (geographic)
+	{8431, 8431},   // Macao 2008 (geographic)
+	{8545, 8545},   // NAD83(HARN Corrected) (geographic)
+	{8694, 8694},   // Camacupa 2015 (geographic)
+	{8699, 8699},   // RSAO13 (geographic)
+	{8818, 8818},   // MTRF-2000 (geographic)
+	{8860, 8860},   // NAD83(FBN) (geographic)
+	{8888, 8888},   // WGS 84 (Transit) (geographic)
+	{8900, 8900},   // RGWF96 (geographic)
+	{20004, 20032}, // Pulkovo 1995 / Gauss-Kruger zones 4-32
+	{28402, 28432}, // Pulkovo 1942 / Gauss-Kruger zones 2-32
+	{31254, 31259}, // MGI / Austria GK
+	{31287, 31287}, // MGI / Austria Lambert
+	{31461, 31469}, // DHDN / Gauss-Kruger and 3-degree Gauss-Kruger
+}
+
+// northingFirstCode reports whether an EPSG code is listed in
+// northingFirstCodes.
+func northingFirstCode(code int) bool {
+	i := sort.Search(len(northingFirstCodes), func(i int) bool {
+		return northingFirstCodes[i].hi >= code
+	})
+	return i < len(northingFirstCodes) && northingFirstCodes[i].lo <= code
+}
@@ -0,0 +1,53 @@
+// Package gml converts between GML 3.2 geometries and GeoJSON, and reads WFS
+// GetFeature responses into FeatureCollections.
+//
+// Elements are matched by local name, so documents using any namespace
+// prefix (or older GML 3.1 and 2 namespaces) are accepted. Besides the GML
+// 3.2 geometry elements, the GML 2 forms coordinates, outerBoundaryIs,
+// innerBoundaryIs, MultiLineString, and MultiPolygon are read.
+//
+// Coordinates are converted to longitude/latitude (or easting/northing)
+// order based on the srsName in effect. Names in URN or http URI form for
+// EPSG coordinate reference systems whose first axis is latitude or
+// northing, such as urn:ogc:def:crs:EPSG::4326 and the ETRS89 grid
+// urn:ogc:def:crs:EPSG::3035, are swapped; these systems are looked up in a
+// table of EPSG codes. Short forms such as EPSG:4326 are taken to be
+// longitude first, as WFS servers conventionally write them. Coordinates are
+// never reprojected.
+package gml
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Namespace is the GML 3.2 namespace.
+const Namespace = "http://www.opengis.net/gml/3.2"
+
+// CRS84 names WGS84 with longitude first, which is the default srsName for
+// written geometries.
+const CRS84 = "http://www.opengis.net/def/crs/OGC/1.3/CRS84"
+
+// northingFirst reports whether srsName declares a coordinate reference
+// system whose first axis is latitude or northing.
+func northingFirst(srsName string) bool {
+	s := strings.ToLower(strings.TrimSpace(srsName))
+	var code string
+	switch {
+	case strings.HasPrefix(s, "urn:ogc:def:crs:epsg:"):
+		// urn:ogc:def:crs:EPSG:[version]:code
+		code = s[strings.LastIndex(s, ":")+1:]
+	case strings.HasPrefix(s, "urn:x-ogc:def:crs:epsg:"):
+		code = s[strings.LastIndex(s, ":")+1:]
+	case strings.HasPrefix(s, "http://www.opengis.net/def/crs/epsg/"),
+		strings.HasPrefix(s, "https://www.opengis.net/def/crs/epsg/"):
+		code = s[strings.LastIndex(s, "/")+1:]
+	default:
+		return false
+	}
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return false
+	}
+	return northingFirstCode(n)
+}
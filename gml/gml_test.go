@@ -0,0 +1,46 @@
+package gml
+
+import "testing"
+
+func TestNorthingFirst(t *testing.T) {
+	// The table is searched, so its ranges must be in order.
+	for i, r := range northingFirstCodes {
+		if r.lo > r.hi || i > 0 && northingFirstCodes[i-1].hi >= r.lo {
+			t.Errorf("range %v is out of order", r)
+		}
+	}
+	cases := []struct {
+		srsName  string
+		expected bool
+	}{
+		{"urn:ogc:def:crs:EPSG::4326", true},
+		{"urn:ogc:def:crs:EPSG:6.6:4326", true},
+		{"urn:x-ogc:def:crs:EPSG:4258", true},
+		{"http://www.opengis.net/def/crs/EPSG/0/4258", true},
+		{"urn:ogc:def:crs:EPSG::4979", true},
+		{"urn:ogc:def:crs:EPSG::6318", true},
+		{"urn:ogc:def:crs:EPSG::7844", true},
+		{"urn:ogc:def:crs:EPSG::3035", true},
+		{"urn:ogc:def:crs:EPSG::2180", true},
+		{"urn:ogc:def:crs:EPSG::31466", true},
+		{"urn:ogc:def:crs:EPSG::31469", true},
+		{"urn:ogc:def:crs:EPSG::3006", true},
+		// Geocentric systems.
+		{"urn:ogc:def:crs:EPSG::4328", false},
+		{"urn:ogc:def:crs:EPSG::4936", false},
+		{"urn:ogc:def:crs:EPSG::4978", false},
+		// Projected systems with easting first.
+		{"urn:ogc:def:crs:EPSG::25833", false},
+		{"urn:ogc:def:crs:EPSG::3857", false},
+		{"urn:ogc:def:crs:EPSG::4087", false},
+		{"urn:ogc:def:crs:EPSG::27700", false},
+		{"EPSG:4326", false},
+		{CRS84, false},
+		{"urn:ogc:def:crs:EPSG::x", false},
+	}
+	for _, c := range cases {
+		if actual := northingFirst(c.srsName); actual != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.srsName, c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package gml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/coords"
+	"github.com/bsidhom/geojson/internal/xmltree"
+)
+
+// MarshalOptions controls how geometries are written. The zero value is
+// valid.
+type MarshalOptions struct {
+	// SRSName is written on the outermost element. If empty, CRS84 is
+	// used. Coordinates are written latitude or northing first if SRSName
+	// requires it.
+	SRSName string
+	// ID, if set, is written as the gml:id of the outermost element.
+	ID string
+}
+
+// Marshal writes g as a GML 3.2 geometry element with the gml prefix bound
+// to Namespace.
+//
+// MultiLineStrings are written as MultiCurves, MultiPolygons as
+// MultiSurfaces, and GeometryCollections as MultiGeometries. An srsDimension
+// of 3 is written if every position has an elevation; otherwise elevations
+// are dropped.
+func Marshal(g geojson.Geometry, opts *MarshalOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &MarshalOptions{}
+	}
+	srsName := opts.SRSName
+	if srsName == "" {
+		srsName = CRS84
+	}
+	e := &encoder{swap: northingFirst(srsName), dim: 2}
+	if coords.HasElevation(g) {
+		e.dim = 3
+	}
+	n, err := e.geometry(g)
+	if err != nil {
+		return nil, fmt.Errorf("gml: %v", err)
+	}
+	n.Attrs = append([]xml.Attr{
+		{Name: xml.Name{Local: "xmlns:gml"}, Value: Namespace},
+		{Name: xml.Name{Local: "srsName"}, Value: srsName},
+	}, n.Attrs...)
+	if e.dim == 3 {
+		n.Attrs = append(n.Attrs, xml.Attr{Name: xml.Name{Local: "srsDimension"}, Value: "3"})
+	}
+	if opts.ID != "" {
+		n.Attrs = append(n.Attrs, xml.Attr{Name: xml.Name{Local: "gml:id"}, Value: opts.ID})
+	}
+	b, err := xml.Marshal(n)
+	if err != nil {
+		return nil, fmt.Errorf("gml: %v", err)
+	}
+	return b, nil
+}
+
+type encoder struct {
+	swap bool
+	dim  int
+}
+
+func (e *encoder) geometry(g geojson.Geometry) (*xmltree.Node, error) {
+	var n xmltree.Node
+	switch t := g.(type) {
+	case *geojson.Point:
+		n = element("Point", xmltree.TextElement("gml:pos", e.positions([]geojson.Point{*t})))
+	case *geojson.MultiPoint:
+		n = element("MultiPoint")
+		for _, p := range t.Points {
+			point := element("Point", xmltree.TextElement("gml:pos", e.positions([]geojson.Point{p})))
+			n.Nodes = append(n.Nodes, element("pointMember", point))
+		}
+	case *geojson.LineString:
+		n = e.lineString(t)
+	case *geojson.MultiLineString:
+		n = element("MultiCurve")
+		for i := range t.Lines {
+			n.Nodes = append(n.Nodes, element("curveMember", e.lineString(&t.Lines[i])))
+		}
+	case *geojson.Polygon:
+		n = e.polygon(t)
+	case *geojson.MultiPolygon:
+		n = element("MultiSurface")
+		for i := range t.Polygons {
+			n.Nodes = append(n.Nodes, element("surfaceMember", e.polygon(&t.Polygons[i])))
+		}
+	case *geojson.GeometryCollection:
+		n = element("MultiGeometry")
+		for i, child := range t.Geometries {
+			c, err := e.geometry(child)
+			if err != nil {
+				return nil, fmt.Errorf("GeometryCollection: geometry %d: %v", i, err)
+			}
+			n.Nodes = append(n.Nodes, element("geometryMember", *c))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported geometry type: %T", t)
+	}
+	return &n, nil
+}
+
+func (e *encoder) lineString(ls *geojson.LineString) xmltree.Node {
+	return element("LineString", xmltree.TextElement("gml:posList", e.positions(ls.Points)))
+}
+
+func (e *encoder) polygon(p *geojson.Polygon) xmltree.Node {
+	n := element("Polygon")
+	for i, ring := range p.Rings {
+		name := "interior"
+		if i == 0 {
+			name = "exterior"
+		}
+		lr := element("LinearRing", xmltree.TextElement("gml:posList", e.positions(ring.Points)))
+		n.Nodes = append(n.Nodes, element(name, lr))
+	}
+	return n
+}
+
+func (e *encoder) positions(points []geojson.Point) string {
+	values := make([]string, 0, len(points)*e.dim)
+	for _, p := range points {
+		a, b := p.X, p.Y
+		if e.swap {
+			a, b = b, a
+		}
+		values = append(values, formatFloat(a), formatFloat(b))
+		if e.dim == 3 {
+			values = append(values, formatFloat(p.Elevation))
+		}
+	}
+	return strings.Join(values, " ")
+}
+
+// element returns a gml-prefixed element. The prefix is written as part of
+// the local name since encoding/xml cannot bind prefixes itself.
+func element(name string, children ...xmltree.Node) xmltree.Node {
+	return xmltree.Element("gml:"+name, children...)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
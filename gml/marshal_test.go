@@ -0,0 +1,100 @@
+package gml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestRoundTrip_Marshal(t *testing.T) {
+	square := geojson.LineString{
+		Points: []geojson.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}, {X: 0, Y: 0}},
+	}
+	hole := geojson.LineString{
+		Points: []geojson.Point{{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 1}, {X: 1, Y: 1}},
+	}
+	geometries := []geojson.Geometry{
+		&geojson.Point{X: 13.4, Y: 52.5},
+		&geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+		&geojson.MultiPoint{Points: []geojson.Point{{X: 1, Y: 2}, {X: 3, Y: 4}}},
+		&geojson.LineString{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1.25, Y: -1}}},
+		&geojson.MultiLineString{Lines: []geojson.LineString{
+			{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+			{Points: []geojson.Point{{X: 2, Y: 2}, {X: 3, Y: 3}}},
+		}},
+		&geojson.Polygon{Rings: []geojson.LineString{square, hole}},
+		&geojson.MultiPolygon{Polygons: []geojson.Polygon{
+			{Rings: []geojson.LineString{square}},
+			{Rings: []geojson.LineString{hole}},
+		}},
+		&geojson.GeometryCollection{Geometries: []geojson.Geometry{
+			&geojson.Point{X: 1, Y: 2},
+			&geojson.Polygon{Rings: []geojson.LineString{square}},
+		}},
+	}
+	for _, srsName := range []string{"", "urn:ogc:def:crs:EPSG::4326"} {
+		for i, g := range geometries {
+			b, err := Marshal(g, &MarshalOptions{SRSName: srsName})
+			if err != nil {
+				t.Errorf("%q case %d: failed to marshal: %v", srsName, i, err)
+				continue
+			}
+			result, err := Unmarshal(b)
+			if err != nil {
+				t.Errorf("%q case %d: failed to unmarshal %s: %v", srsName, i, b, err)
+				continue
+			}
+			if !reflect.DeepEqual(result, g) {
+				t.Errorf("%q case %d: expected %#v, got %#v", srsName, i, g, result)
+			}
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	cases := []struct {
+		g        geojson.Geometry
+		opts     *MarshalOptions
+		expected string
+	}{
+		{
+			&geojson.Point{X: 13.4, Y: 52.5},
+			nil,
+			`<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2" srsName="http://www.opengis.net/def/crs/OGC/1.3/CRS84"><gml:pos>13.4 52.5</gml:pos></gml:Point>`,
+		},
+		{
+			&geojson.LineString{Points: []geojson.Point{
+				{X: 13.4, Y: 52.5, Elevation: 30, HasElevation: true},
+				{X: 13.5, Y: 52.6, Elevation: 35, HasElevation: true},
+			}},
+			&MarshalOptions{SRSName: "urn:ogc:def:crs:EPSG::4326", ID: "l1"},
+			`<gml:LineString xmlns:gml="http://www.opengis.net/gml/3.2" srsName="urn:ogc:def:crs:EPSG::4326" srsDimension="3" gml:id="l1"><gml:posList>52.5 13.4 30 52.6 13.5 35</gml:posList></gml:LineString>`,
+		},
+	}
+	for i, c := range cases {
+		b, err := Marshal(c.g, c.opts)
+		if err != nil {
+			t.Errorf("case %d: failed to marshal: %v", i, err)
+			continue
+		}
+		if string(b) != c.expected {
+			t.Errorf("case %d: expected %s, got %s", i, c.expected, b)
+		}
+	}
+}
+
+func TestMarshal_MixedElevation(t *testing.T) {
+	g := &geojson.LineString{Points: []geojson.Point{
+		{X: 0, Y: 0, Elevation: 1, HasElevation: true},
+		{X: 1, Y: 1},
+	}}
+	b, err := Marshal(g, nil)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	if strings.Contains(string(b), "srsDimension") || !strings.Contains(string(b), ">0 0 1 1<") {
+		t.Errorf("expected 2D output, got %s", b)
+	}
+}
@@ -0,0 +1,523 @@
+package gml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/xmltree"
+)
+
+// Unmarshal parses a single GML geometry element.
+func Unmarshal(b []byte) (geojson.Geometry, error) {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, fmt.Errorf("gml: %v", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return DecodeElement(d, &start)
+		}
+	}
+}
+
+// DecodeElement decodes the geometry element that begins with start, for
+// use by formats that embed GML geometries in their own documents.
+func DecodeElement(d *xml.Decoder, start *xml.StartElement) (geojson.Geometry, error) {
+	var n xmltree.Node
+	err := d.DecodeElement(&n, start)
+	if err != nil {
+		return nil, fmt.Errorf("gml: %v", err)
+	}
+	g, err := decodeGeometry(&n, context{})
+	if err != nil {
+		return nil, fmt.Errorf("gml: %v", err)
+	}
+	return g, nil
+}
+
+// context carries the srsName and srsDimension attributes inherited from
+// enclosing elements.
+type context struct {
+	srsName string
+	dim     int
+}
+
+func (c context) enter(n *xmltree.Node) (context, error) {
+	if s, ok := n.Attr("srsName"); ok {
+		c.srsName = s
+	}
+	if s, ok := n.Attr("srsDimension"); ok {
+		dim, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || dim < 1 {
+			return c, fmt.Errorf("invalid srsDimension %q", s)
+		}
+		c.dim = dim
+	}
+	return c, nil
+}
+
+func isGeometry(name string) bool {
+	switch name {
+	case "Point", "LineString", "LinearRing", "Curve", "Polygon", "Surface", "Envelope",
+		"MultiPoint", "MultiCurve", "MultiLineString", "MultiSurface", "MultiPolygon",
+		"MultiGeometry":
+		return true
+	}
+	return false
+}
+
+func decodeGeometry(n *xmltree.Node, c context) (geojson.Geometry, error) {
+	c, err := c.enter(n)
+	if err != nil {
+		return nil, err
+	}
+	name := n.XMLName.Local
+	switch name {
+	case "Point":
+		points, err := decodePositions(n, c)
+		if err != nil {
+			return nil, fmt.Errorf("Point: %v", err)
+		}
+		if len(points) != 1 {
+			return nil, fmt.Errorf("Point: must have exactly 1 position, got %d", len(points))
+		}
+		return &points[0], nil
+	case "LineString", "Curve":
+		ls, err := decodeLine(n, c)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		return ls, nil
+	case "LinearRing":
+		ring, err := decodeRing(n, c)
+		if err != nil {
+			return nil, fmt.Errorf("LinearRing: %v", err)
+		}
+		return &geojson.Polygon{Rings: []geojson.LineString{*ring}}, nil
+	case "Polygon":
+		p, err := decodePolygon(n, c)
+		if err != nil {
+			return nil, fmt.Errorf("Polygon: %v", err)
+		}
+		return p, nil
+	case "Surface":
+		polygons, err := decodeSurface(n, c)
+		if err != nil {
+			return nil, fmt.Errorf("Surface: %v", err)
+		}
+		if len(polygons) == 1 {
+			return &polygons[0], nil
+		}
+		return &geojson.MultiPolygon{Polygons: polygons}, nil
+	case "Envelope":
+		p, err := decodeEnvelope(n, c)
+		if err != nil {
+			return nil, fmt.Errorf("Envelope: %v", err)
+		}
+		return p, nil
+	case "MultiPoint":
+		m := &geojson.MultiPoint{Points: []geojson.Point{}}
+		for i, member := range members(n, "pointMember", "pointMembers") {
+			g, err := decodeGeometry(member, c)
+			if err != nil {
+				return nil, fmt.Errorf("MultiPoint: member %d: %v", i, err)
+			}
+			p, ok := g.(*geojson.Point)
+			if !ok {
+				return nil, fmt.Errorf("MultiPoint: member %d: expected Point, got %s", i, member.XMLName.Local)
+			}
+			m.Points = append(m.Points, *p)
+		}
+		return m, nil
+	case "MultiCurve", "MultiLineString":
+		m := &geojson.MultiLineString{Lines: []geojson.LineString{}}
+		for i, member := range members(n, "curveMember", "curveMembers", "lineStringMember") {
+			g, err := decodeGeometry(member, c)
+			if err != nil {
+				return nil, fmt.Errorf("%s: member %d: %v", name, i, err)
+			}
+			ls, ok := g.(*geojson.LineString)
+			if !ok {
+				return nil, fmt.Errorf("%s: member %d: expected a curve, got %s", name, i, member.XMLName.Local)
+			}
+			m.Lines = append(m.Lines, *ls)
+		}
+		return m, nil
+	case "MultiSurface", "MultiPolygon":
+		m := &geojson.MultiPolygon{Polygons: []geojson.Polygon{}}
+		for i, member := range members(n, "surfaceMember", "surfaceMembers", "polygonMember") {
+			g, err := decodeGeometry(member, c)
+			if err != nil {
+				return nil, fmt.Errorf("%s: member %d: %v", name, i, err)
+			}
+			switch t := g.(type) {
+			case *geojson.Polygon:
+				m.Polygons = append(m.Polygons, *t)
+			case *geojson.MultiPolygon:
+				m.Polygons = append(m.Polygons, t.Polygons...)
+			default:
+				return nil, fmt.Errorf("%s: member %d: expected a surface, got %s", name, i, member.XMLName.Local)
+			}
+		}
+		return m, nil
+	case "MultiGeometry":
+		gc := &geojson.GeometryCollection{Geometries: []geojson.Geometry{}}
+		for i, member := range members(n, "geometryMember", "geometryMembers") {
+			g, err := decodeGeometry(member, c)
+			if err != nil {
+				return nil, fmt.Errorf("MultiGeometry: member %d: %v", i, err)
+			}
+			gc.Geometries = append(gc.Geometries, g)
+		}
+		return gc, nil
+	}
+	return nil, fmt.Errorf("unsupported geometry: %s", name)
+}
+
+// members returns the geometry elements held by the named member properties
+// of n. Singular properties such as pointMember hold one geometry and plural
+// ones such as pointMembers hold several.
+func members(n *xmltree.Node, names ...string) []*xmltree.Node {
+	var result []*xmltree.Node
+	for i := range n.Nodes {
+		property := &n.Nodes[i]
+		if !hasName(property, names) {
+			continue
+		}
+		for j := range property.Nodes {
+			if isGeometry(property.Nodes[j].XMLName.Local) {
+				result = append(result, &property.Nodes[j])
+			}
+		}
+	}
+	return result
+}
+
+func hasName(n *xmltree.Node, names []string) bool {
+	for _, name := range names {
+		if n.XMLName.Local == name {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeLine decodes a LineString, or a Curve whose segments are all
+// LineStringSegments. The segments of a Curve are joined end to end.
+func decodeLine(n *xmltree.Node, c context) (*geojson.LineString, error) {
+	var points []geojson.Point
+	if n.XMLName.Local == "Curve" {
+		segments := n.Child("segments")
+		if segments == nil {
+			return nil, fmt.Errorf("missing segments")
+		}
+		for i := range segments.Nodes {
+			segment := &segments.Nodes[i]
+			if segment.XMLName.Local != "LineStringSegment" {
+				return nil, fmt.Errorf("unsupported segment: %s", segment.XMLName.Local)
+			}
+			sc, err := c.enter(segment)
+			if err != nil {
+				return nil, err
+			}
+			p, err := decodePositions(segment, sc)
+			if err != nil {
+				return nil, fmt.Errorf("segment %d: %v", i, err)
+			}
+			if len(points) > 0 && len(p) > 0 && points[len(points)-1] == p[0] {
+				p = p[1:]
+			}
+			points = append(points, p...)
+		}
+	} else {
+		var err error
+		points, err = decodePositions(n, c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(points) < 2 {
+		return nil, fmt.Errorf("must have at least 2 points, got %d", len(points))
+	}
+	return &geojson.LineString{Points: points}, nil
+}
+
+func decodePolygon(n *xmltree.Node, c context) (*geojson.Polygon, error) {
+	exterior := n.Child("exterior")
+	if exterior == nil {
+		exterior = n.Child("outerBoundaryIs")
+	}
+	if exterior == nil {
+		return nil, fmt.Errorf("missing exterior")
+	}
+	boundaries := []*xmltree.Node{exterior}
+	boundaries = append(boundaries, n.Children("interior")...)
+	boundaries = append(boundaries, n.Children("innerBoundaryIs")...)
+	rings := make([]geojson.LineString, len(boundaries))
+	for i, boundary := range boundaries {
+		var ring *xmltree.Node
+		for j := range boundary.Nodes {
+			switch boundary.Nodes[j].XMLName.Local {
+			case "LinearRing", "Ring":
+				ring = &boundary.Nodes[j]
+			}
+		}
+		if ring == nil {
+			return nil, fmt.Errorf("boundary %d: missing LinearRing", i)
+		}
+		ls, err := decodeRing(ring, c)
+		if err != nil {
+			return nil, fmt.Errorf("boundary %d: %v", i, err)
+		}
+		rings[i] = *ls
+	}
+	return &geojson.Polygon{Rings: rings}, nil
+}
+
+// decodeSurface decodes the PolygonPatches of a Surface.
+func decodeSurface(n *xmltree.Node, c context) ([]geojson.Polygon, error) {
+	patches := n.Child("patches")
+	if patches == nil {
+		return nil, fmt.Errorf("missing patches")
+	}
+	var polygons []geojson.Polygon
+	for i := range patches.Nodes {
+		patch := &patches.Nodes[i]
+		if patch.XMLName.Local != "PolygonPatch" {
+			return nil, fmt.Errorf("unsupported patch: %s", patch.XMLName.Local)
+		}
+		pc, err := c.enter(patch)
+		if err != nil {
+			return nil, err
+		}
+		p, err := decodePolygon(patch, pc)
+		if err != nil {
+			return nil, fmt.Errorf("patch %d: %v", i, err)
+		}
+		polygons = append(polygons, *p)
+	}
+	if len(polygons) == 0 {
+		return nil, fmt.Errorf("no patches")
+	}
+	return polygons, nil
+}
+
+// decodeRing decodes a LinearRing, or a Ring made of curve members.
+func decodeRing(n *xmltree.Node, c context) (*geojson.LineString, error) {
+	c, err := c.enter(n)
+	if err != nil {
+		return nil, err
+	}
+	var points []geojson.Point
+	if n.XMLName.Local == "Ring" {
+		for i, member := range members(n, "curveMember") {
+			mc, err := c.enter(member)
+			if err != nil {
+				return nil, err
+			}
+			ls, err := decodeLine(member, mc)
+			if err != nil {
+				return nil, fmt.Errorf("curve %d: %v", i, err)
+			}
+			p := ls.Points
+			if len(points) > 0 && points[len(points)-1] == p[0] {
+				p = p[1:]
+			}
+			points = append(points, p...)
+		}
+	} else {
+		points, err = decodePositions(n, c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(points) < 4 {
+		return nil, fmt.Errorf("linear ring requires at least 4 points, got %d", len(points))
+	}
+	if points[0] != points[len(points)-1] {
+		return nil, fmt.Errorf("linear ring is not closed")
+	}
+	return &geojson.LineString{Points: points}, nil
+}
+
+func decodeEnvelope(n *xmltree.Node, c context) (*geojson.Polygon, error) {
+	var corners [2]geojson.Point
+	for i, name := range []string{"lowerCorner", "upperCorner"} {
+		corner := n.Child(name)
+		if corner == nil {
+			return nil, fmt.Errorf("missing %s", name)
+		}
+		cc, err := c.enter(corner)
+		if err != nil {
+			return nil, err
+		}
+		points, err := parsePositions(corner.Text(), cc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		if len(points) != 1 {
+			return nil, fmt.Errorf("%s: must have exactly 1 position, got %d", name, len(points))
+		}
+		corners[i] = geojson.Point{X: points[0].X, Y: points[0].Y}
+	}
+	lo, hi := corners[0], corners[1]
+	return &geojson.Polygon{Rings: []geojson.LineString{{Points: []geojson.Point{
+		lo, {X: hi.X, Y: lo.Y}, hi, {X: lo.X, Y: hi.Y}, lo,
+	}}}}, nil
+}
+
+// decodePositions reads the positions held directly by n, from a posList,
+// from a sequence of pos or pointProperty elements, or from a GML 2
+// coordinates element.
+func decodePositions(n *xmltree.Node, c context) ([]geojson.Point, error) {
+	if list := n.Child("posList"); list != nil {
+		lc, err := c.enter(list)
+		if err != nil {
+			return nil, err
+		}
+		return parsePositions(list.Text(), lc)
+	}
+	if coords := n.Child("coordinates"); coords != nil {
+		return parseCoordinates(coords, c)
+	}
+	var points []geojson.Point
+	for i := range n.Nodes {
+		child := &n.Nodes[i]
+		switch child.XMLName.Local {
+		case "pos":
+			pc, err := c.enter(child)
+			if err != nil {
+				return nil, err
+			}
+			p, err := parsePositions(child.Text(), pc)
+			if err != nil {
+				return nil, fmt.Errorf("position %d: %v", len(points), err)
+			}
+			if len(p) != 1 {
+				return nil, fmt.Errorf("position %d: expected 1 position, got %d", len(points), len(p))
+			}
+			points = append(points, p[0])
+		case "pointProperty", "pointRep":
+			point := child.Child("Point")
+			if point == nil {
+				return nil, fmt.Errorf("position %d: missing Point", len(points))
+			}
+			g, err := decodeGeometry(point, c)
+			if err != nil {
+				return nil, fmt.Errorf("position %d: %v", len(points), err)
+			}
+			points = append(points, *g.(*geojson.Point))
+		}
+	}
+	if points == nil {
+		return nil, fmt.Errorf("missing positions")
+	}
+	return points, nil
+}
+
+// parsePositions parses whitespace-separated ordinates in groups of the
+// context's dimension, which defaults to 2. Only the first three ordinates
+// of each position are kept.
+func parsePositions(s string, c context) ([]geojson.Point, error) {
+	fields := strings.Fields(s)
+	dim := c.dim
+	if dim == 0 {
+		dim = 2
+		if len(fields) == 3 {
+			// A lone position without srsDimension, such as a pos, has
+			// as many ordinates as it has values.
+			dim = 3
+		}
+	}
+	if dim < 2 {
+		return nil, fmt.Errorf("unsupported srsDimension %d", dim)
+	}
+	if len(fields)%dim != 0 {
+		return nil, fmt.Errorf("%d ordinates is not a multiple of srsDimension %d", len(fields), dim)
+	}
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	swap := northingFirst(c.srsName)
+	points := make([]geojson.Point, len(values)/dim)
+	for i := range points {
+		v := values[i*dim:]
+		points[i] = position(v[0], v[1], swap)
+		if dim >= 3 {
+			points[i].Elevation = v[2]
+			points[i].HasElevation = true
+		}
+	}
+	return points, nil
+}
+
+// parseCoordinates parses a GML 2 coordinates element, honoring its
+// decimal, cs, and ts attributes.
+func parseCoordinates(n *xmltree.Node, c context) ([]geojson.Point, error) {
+	decimal, cs, ts := ".", ",", " "
+	if s, ok := n.Attr("decimal"); ok && s != "" {
+		decimal = s
+	}
+	if s, ok := n.Attr("cs"); ok && s != "" {
+		cs = s
+	}
+	if s, ok := n.Attr("ts"); ok && s != "" {
+		ts = s
+	}
+	var tuples []string
+	if strings.TrimSpace(ts) == "" {
+		tuples = strings.Fields(n.Content)
+	} else {
+		for _, t := range strings.Split(n.Content, ts) {
+			if t = strings.TrimSpace(t); t != "" {
+				tuples = append(tuples, t)
+			}
+		}
+	}
+	swap := northingFirst(c.srsName)
+	points := make([]geojson.Point, len(tuples))
+	for i, tuple := range tuples {
+		fields := strings.Split(tuple, cs)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("coordinate %d: must have 2-3 values, got %q", i, tuple)
+		}
+		var values [3]float64
+		for j, field := range fields {
+			field = strings.TrimSpace(field)
+			if decimal != "." {
+				field = strings.Replace(field, decimal, ".", 1)
+			}
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, fmt.Errorf("coordinate %d: %v", i, err)
+			}
+			values[j] = v
+		}
+		points[i] = position(values[0], values[1], swap)
+		if len(fields) == 3 {
+			points[i].Elevation = values[2]
+			points[i].HasElevation = true
+		}
+	}
+	if len(points) == 0 {
+		return nil, fmt.Errorf("missing positions")
+	}
+	return points, nil
+}
+
+func position(a, b float64, swap bool) geojson.Point {
+	if swap {
+		return geojson.Point{X: b, Y: a}
+	}
+	return geojson.Point{X: a, Y: b}
+}
@@ -0,0 +1,172 @@
+package gml
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestUnmarshal(t *testing.T) {
+	square := geojson.LineString{
+		Points: []geojson.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}, {X: 0, Y: 0}},
+	}
+	hole := geojson.LineString{
+		Points: []geojson.Point{{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 1}, {X: 1, Y: 1}},
+	}
+	cases := []struct {
+		s        string
+		expected geojson.Geometry
+	}{
+		{
+			`<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2" gml:id="p1"><gml:pos>1.5 2</gml:pos></gml:Point>`,
+			&geojson.Point{X: 1.5, Y: 2},
+		},
+		{
+			// EPSG URNs for geographic systems are latitude first.
+			`<gml:Point srsName="urn:ogc:def:crs:EPSG::4326"><gml:pos>52.5 13.4</gml:pos></gml:Point>`,
+			&geojson.Point{X: 13.4, Y: 52.5},
+		},
+		{
+			`<gml:Point srsName="http://www.opengis.net/def/crs/EPSG/0/4258"><gml:pos>52.5 13.4</gml:pos></gml:Point>`,
+			&geojson.Point{X: 13.4, Y: 52.5},
+		},
+		{
+			// Short EPSG codes and projected systems are not swapped.
+			`<gml:Point srsName="EPSG:4326"><gml:pos>13.4 52.5</gml:pos></gml:Point>`,
+			&geojson.Point{X: 13.4, Y: 52.5},
+		},
+		{
+			`<gml:Point srsName="urn:ogc:def:crs:EPSG::25833"><gml:pos>390000 5820000</gml:pos></gml:Point>`,
+			&geojson.Point{X: 390000, Y: 5820000},
+		},
+		{
+			// Projected systems with northing first are swapped.
+			`<gml:Point srsName="urn:ogc:def:crs:EPSG::3035"><gml:pos>3260000 4540000</gml:pos></gml:Point>`,
+			&geojson.Point{X: 4540000, Y: 3260000},
+		},
+		{
+			`<gml:Point srsName="http://www.opengis.net/def/crs/EPSG/0/2180"><gml:pos>480000 630000</gml:pos></gml:Point>`,
+			&geojson.Point{X: 630000, Y: 480000},
+		},
+		{
+			// Geocentric systems are not.
+			`<gml:Point srsName="urn:ogc:def:crs:EPSG::4936"><gml:pos>3800000 880000</gml:pos></gml:Point>`,
+			&geojson.Point{X: 3800000, Y: 880000},
+		},
+		{
+			`<gml:Point srsDimension="3"><gml:pos>1 2 3</gml:pos></gml:Point>`,
+			&geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+		},
+		{
+			`<gml:LineString><gml:posList srsDimension="3">0 0 1 1 1 2 2 0 3</gml:posList></gml:LineString>`,
+			&geojson.LineString{Points: []geojson.Point{
+				{X: 0, Y: 0, Elevation: 1, HasElevation: true},
+				{X: 1, Y: 1, Elevation: 2, HasElevation: true},
+				{X: 2, Y: 0, Elevation: 3, HasElevation: true},
+			}},
+		},
+		{
+			`<gml:LineString><gml:pos>0 0</gml:pos><gml:pos>1 1</gml:pos></gml:LineString>`,
+			&geojson.LineString{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+		},
+		{
+			`<gml:Curve><gml:segments>
+			   <gml:LineStringSegment><gml:posList>0 0 1 1</gml:posList></gml:LineStringSegment>
+			   <gml:LineStringSegment><gml:posList>1 1 2 0</gml:posList></gml:LineStringSegment>
+			 </gml:segments></gml:Curve>`,
+			&geojson.LineString{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 0}}},
+		},
+		{
+			`<gml:Polygon>
+			   <gml:exterior><gml:LinearRing><gml:posList>0 0 4 0 4 4 0 4 0 0</gml:posList></gml:LinearRing></gml:exterior>
+			   <gml:interior><gml:LinearRing><gml:posList>1 1 1 2 2 2 2 1 1 1</gml:posList></gml:LinearRing></gml:interior>
+			 </gml:Polygon>`,
+			&geojson.Polygon{Rings: []geojson.LineString{square, hole}},
+		},
+		{
+			// Swapping the axes of a latitude-first ring leaves it
+			// describing the same path on the map.
+			`<gml:Polygon srsName="urn:ogc:def:crs:EPSG::4326">
+			   <gml:exterior><gml:LinearRing><gml:posList>0 0 0 4 4 4 4 0 0 0</gml:posList></gml:LinearRing></gml:exterior>
+			 </gml:Polygon>`,
+			&geojson.Polygon{Rings: []geojson.LineString{square}},
+		},
+		{
+			`<gml:Polygon>
+			   <gml:outerBoundaryIs><gml:LinearRing><gml:coordinates>0,0 4,0 4,4 0,4 0,0</gml:coordinates></gml:LinearRing></gml:outerBoundaryIs>
+			 </gml:Polygon>`,
+			&geojson.Polygon{Rings: []geojson.LineString{square}},
+		},
+		{
+			`<gml:Envelope><gml:lowerCorner>0 0</gml:lowerCorner><gml:upperCorner>4 4</gml:upperCorner></gml:Envelope>`,
+			&geojson.Polygon{Rings: []geojson.LineString{square}},
+		},
+		{
+			`<gml:MultiPoint>
+			   <gml:pointMember><gml:Point><gml:pos>1 2</gml:pos></gml:Point></gml:pointMember>
+			   <gml:pointMembers><gml:Point><gml:pos>3 4</gml:pos></gml:Point><gml:Point><gml:pos>5 6</gml:pos></gml:Point></gml:pointMembers>
+			 </gml:MultiPoint>`,
+			&geojson.MultiPoint{Points: []geojson.Point{{X: 1, Y: 2}, {X: 3, Y: 4}, {X: 5, Y: 6}}},
+		},
+		{
+			`<gml:MultiCurve srsName="urn:ogc:def:crs:EPSG::4326">
+			   <gml:curveMember><gml:LineString><gml:posList>0 1 2 3</gml:posList></gml:LineString></gml:curveMember>
+			 </gml:MultiCurve>`,
+			&geojson.MultiLineString{Lines: []geojson.LineString{
+				{Points: []geojson.Point{{X: 1, Y: 0}, {X: 3, Y: 2}}},
+			}},
+		},
+		{
+			`<gml:MultiSurface>
+			   <gml:surfaceMember><gml:Polygon><gml:exterior><gml:LinearRing><gml:posList>0 0 4 0 4 4 0 4 0 0</gml:posList></gml:LinearRing></gml:exterior></gml:Polygon></gml:surfaceMember>
+			   <gml:surfaceMember><gml:Surface><gml:patches><gml:PolygonPatch><gml:exterior><gml:LinearRing><gml:posList>1 1 1 2 2 2 2 1 1 1</gml:posList></gml:LinearRing></gml:exterior></gml:PolygonPatch></gml:patches></gml:Surface></gml:surfaceMember>
+			 </gml:MultiSurface>`,
+			&geojson.MultiPolygon{Polygons: []geojson.Polygon{
+				{Rings: []geojson.LineString{square}},
+				{Rings: []geojson.LineString{hole}},
+			}},
+		},
+		{
+			`<gml:MultiGeometry>
+			   <gml:geometryMember><gml:Point><gml:pos>1 2</gml:pos></gml:Point></gml:geometryMember>
+			   <gml:geometryMember><gml:LineString><gml:posList>0 0 1 1</gml:posList></gml:LineString></gml:geometryMember>
+			 </gml:MultiGeometry>`,
+			&geojson.GeometryCollection{Geometries: []geojson.Geometry{
+				&geojson.Point{X: 1, Y: 2},
+				&geojson.LineString{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+			}},
+		},
+	}
+	for i, c := range cases {
+		g, err := Unmarshal([]byte(c.s))
+		if err != nil {
+			t.Errorf("case %d: failed to unmarshal: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(g, c.expected) {
+			t.Errorf("case %d: expected %#v, got %#v", i, c.expected, g)
+		}
+	}
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	cases := []string{
+		``,
+		`<gml:Point></gml:Point>`,
+		`<gml:Point><gml:pos>1 x</gml:pos></gml:Point>`,
+		`<gml:LineString><gml:posList>0 0 1</gml:posList></gml:LineString>`,
+		`<gml:LineString><gml:posList>0 0</gml:posList></gml:LineString>`,
+		`<gml:LineString><gml:posList srsDimension="0">0 0 1 1</gml:posList></gml:LineString>`,
+		`<gml:Polygon><gml:exterior><gml:LinearRing><gml:posList>0 0 1 0 1 1 0 1</gml:posList></gml:LinearRing></gml:exterior></gml:Polygon>`,
+		`<gml:Polygon><gml:interior><gml:LinearRing><gml:posList>0 0 1 0 1 1 0 0</gml:posList></gml:LinearRing></gml:interior></gml:Polygon>`,
+		`<gml:Curve><gml:segments><gml:Arc><gml:posList>0 0 1 1 2 0</gml:posList></gml:Arc></gml:segments></gml:Curve>`,
+		`<gml:Solid/>`,
+	}
+	for i, c := range cases {
+		_, err := Unmarshal([]byte(c))
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
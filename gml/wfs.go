@@ -0,0 +1,148 @@
+package gml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/xmltree"
+)
+
+// Decode reads a WFS GetFeature response and returns its features as a
+// FeatureCollection. Features are collected in document order from the
+// member elements of WFS 2.0 and the featureMember and featureMembers
+// elements of earlier versions, including those of nested
+// FeatureCollections.
+//
+// The gml:id (or GML 2 fid) of each feature becomes its Feature ID. The
+// first property holding a geometry becomes the Feature geometry; any other
+// geometry properties and boundedBy are dropped. Other properties are
+// stored by local name: simple values as strings, nil values as nil, and
+// complex values as nested maps, with repeated elements collected into
+// slices.
+func Decode(r io.Reader) (*geojson.FeatureCollection, error) {
+	d := xml.NewDecoder(r)
+	fc := &geojson.FeatureCollection{}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("gml: %v", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || !isMember(start.Name.Local) {
+			continue
+		}
+		var n xmltree.Node
+		err = d.DecodeElement(&n, &start)
+		if err != nil {
+			return nil, fmt.Errorf("gml: %v", err)
+		}
+		err = decodeMember(&n, fc)
+		if err != nil {
+			return nil, fmt.Errorf("gml: %v", err)
+		}
+	}
+	return fc, nil
+}
+
+func isMember(name string) bool {
+	switch name {
+	case "member", "featureMember", "featureMembers":
+		return true
+	}
+	return false
+}
+
+func decodeMember(n *xmltree.Node, fc *geojson.FeatureCollection) error {
+	for i := range n.Nodes {
+		child := &n.Nodes[i]
+		if child.XMLName.Local == "FeatureCollection" {
+			for j := range child.Nodes {
+				if !isMember(child.Nodes[j].XMLName.Local) {
+					continue
+				}
+				err := decodeMember(&child.Nodes[j], fc)
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		f, err := decodeFeature(child)
+		if err != nil {
+			return fmt.Errorf("feature %d: %v", len(fc.Features), err)
+		}
+		fc.Features = append(fc.Features, *f)
+	}
+	return nil
+}
+
+func decodeFeature(n *xmltree.Node) (*geojson.Feature, error) {
+	f := &geojson.Feature{Properties: map[string]interface{}{}}
+	if id, ok := n.Attr("id"); ok {
+		f.ID = id
+	} else if id, ok := n.Attr("fid"); ok {
+		f.ID = id
+	}
+	for i := range n.Nodes {
+		child := &n.Nodes[i]
+		name := child.XMLName.Local
+		if name == "boundedBy" {
+			continue
+		}
+		if g := geometryChild(child); g != nil {
+			if f.Geometry != nil {
+				continue
+			}
+			geometry, err := decodeGeometry(g, context{})
+			if err != nil {
+				return nil, fmt.Errorf("property %q: %v", name, err)
+			}
+			f.Geometry = geometry
+			continue
+		}
+		addValue(f.Properties, name, propertyValue(child))
+	}
+	return f, nil
+}
+
+// geometryChild returns the geometry held by a property element, or nil if
+// it does not hold one.
+func geometryChild(n *xmltree.Node) *xmltree.Node {
+	if len(n.Nodes) == 1 && isGeometry(n.Nodes[0].XMLName.Local) {
+		return &n.Nodes[0]
+	}
+	return nil
+}
+
+func propertyValue(n *xmltree.Node) interface{} {
+	if v, _ := n.Attr("nil"); v == "true" {
+		return nil
+	}
+	if len(n.Nodes) == 0 {
+		return n.Text()
+	}
+	m := map[string]interface{}{}
+	for i := range n.Nodes {
+		addValue(m, n.Nodes[i].XMLName.Local, propertyValue(&n.Nodes[i]))
+	}
+	return m
+}
+
+// addValue stores v under k, collecting repeated keys into a slice.
+func addValue(m map[string]interface{}, k string, v interface{}) {
+	existing, ok := m[k]
+	if !ok {
+		m[k] = v
+		return
+	}
+	if s, ok := existing.([]interface{}); ok {
+		m[k] = append(s, v)
+		return
+	}
+	m[k] = []interface{}{existing, v}
+}
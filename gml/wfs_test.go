@@ -0,0 +1,127 @@
+package gml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestDecode(t *testing.T) {
+	s := `<?xml version="1.0" encoding="UTF-8"?>
+<wfs:FeatureCollection xmlns:wfs="http://www.opengis.net/wfs/2.0"
+    xmlns:gml="http://www.opengis.net/gml/3.2"
+    xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+    xmlns:app="http://example.com/app"
+    numberMatched="3" numberReturned="3">
+  <wfs:boundedBy>
+    <gml:Envelope srsName="urn:ogc:def:crs:EPSG::4326">
+      <gml:lowerCorner>52 13</gml:lowerCorner>
+      <gml:upperCorner>53 14</gml:upperCorner>
+    </gml:Envelope>
+  </wfs:boundedBy>
+  <wfs:member>
+    <app:station gml:id="station.1">
+      <gml:boundedBy>
+        <gml:Envelope><gml:lowerCorner>52.5 13.4</gml:lowerCorner><gml:upperCorner>52.5 13.4</gml:upperCorner></gml:Envelope>
+      </gml:boundedBy>
+      <app:name>Alexanderplatz</app:name>
+      <app:elevation xsi:nil="true"/>
+      <app:location>
+        <gml:Point gml:id="station.1.geom" srsName="urn:ogc:def:crs:EPSG::4326">
+          <gml:pos>52.5219 13.4132</gml:pos>
+        </gml:Point>
+      </app:location>
+      <app:operator>
+        <app:name>BVG</app:name>
+        <app:line>U2</app:line>
+        <app:line>U5</app:line>
+      </app:operator>
+    </app:station>
+  </wfs:member>
+  <wfs:member>
+    <wfs:FeatureCollection>
+      <wfs:member>
+        <app:station gml:id="station.2">
+          <app:name>Zoo</app:name>
+        </app:station>
+      </wfs:member>
+    </wfs:FeatureCollection>
+  </wfs:member>
+</wfs:FeatureCollection>`
+	fc, err := Decode(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	expected := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				ID:       "station.1",
+				Geometry: &geojson.Point{X: 13.4132, Y: 52.5219},
+				Properties: map[string]interface{}{
+					"name":      "Alexanderplatz",
+					"elevation": nil,
+					"operator": map[string]interface{}{
+						"name": "BVG",
+						"line": []interface{}{"U2", "U5"},
+					},
+				},
+			},
+			{
+				ID:         "station.2",
+				Properties: map[string]interface{}{"name": "Zoo"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(fc, expected) {
+		t.Errorf("expected %#v, got %#v", expected, fc)
+	}
+}
+
+func TestDecode_FeatureMembers(t *testing.T) {
+	s := `<wfs:FeatureCollection xmlns:wfs="http://www.opengis.net/wfs" xmlns:gml="http://www.opengis.net/gml">
+  <gml:featureMember>
+    <app:road xmlns:app="http://example.com/app" fid="road.1">
+      <app:geom>
+        <gml:LineString srsName="EPSG:4326"><gml:coordinates>0,0 1,1</gml:coordinates></gml:LineString>
+      </app:geom>
+    </app:road>
+  </gml:featureMember>
+  <gml:featureMembers>
+    <app:road xmlns:app="http://example.com/app" gml:id="road.2"><app:lanes>2</app:lanes></app:road>
+    <app:road xmlns:app="http://example.com/app" gml:id="road.3"><app:lanes>4</app:lanes></app:road>
+  </gml:featureMembers>
+</wfs:FeatureCollection>`
+	fc, err := Decode(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	expected := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				ID:         "road.1",
+				Geometry:   &geojson.LineString{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+				Properties: map[string]interface{}{},
+			},
+			{ID: "road.2", Properties: map[string]interface{}{"lanes": "2"}},
+			{ID: "road.3", Properties: map[string]interface{}{"lanes": "4"}},
+		},
+	}
+	if !reflect.DeepEqual(fc, expected) {
+		t.Errorf("expected %#v, got %#v", expected, fc)
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	cases := []string{
+		`<wfs:FeatureCollection><wfs:member>`,
+		`<wfs:FeatureCollection><wfs:member><app:a><app:geom><gml:Point><gml:pos>1</gml:pos></gml:Point></app:geom></app:a></wfs:member></wfs:FeatureCollection>`,
+	}
+	for i, c := range cases {
+		_, err := Decode(strings.NewReader(c))
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package geojson
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// ConvexHull returns the smallest convex polygon that covers the positions
+// of o, which may be a Geometry, a Feature, or a FeatureCollection. For
+// features, the positions of their geometries are used.
+//
+// ConvexHull and the other hulls are computed in the plane, like Relate,
+// and return polygons with a single counter-clockwise ring, as RFC 7946
+// requires. They return false if the positions do not enclose an area,
+// because there are fewer than 3 of them or they all lie on a line.
+func ConvexHull(o Object) (*Polygon, bool) {
+	hull := convexHull(hullPositions(o))
+	if len(hull) < 3 {
+		return nil, false
+	}
+	return &Polygon{Rings: []LineString{closedRing(hull)}}, true
+}
+
+// hullPositions returns the distinct positions of o in order.
+func hullPositions(o Object) []xy {
+	seen := map[xy]bool{}
+	var ps []xy
+	add := func(p Point) {
+		if q := pos(p); !seen[q] {
+			seen[q] = true
+			ps = append(ps, q)
+		}
+	}
+	switch t := o.(type) {
+	case *FeatureCollection:
+		for _, f := range t.Features {
+			if f.Geometry != nil {
+				walkPositions(f.Geometry, add)
+			}
+		}
+	case *Feature:
+		if t.Geometry != nil {
+			walkPositions(t.Geometry, add)
+		}
+	case Geometry:
+		walkPositions(t, add)
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i].less(ps[j]) })
+	return ps
+}
+
+// convexHull returns the vertices of the convex hull of ps, which must be
+// distinct and in order, counter-clockwise and without collinear vertices.
+// If ps all lie on a line, it returns the ends of the line.
+func convexHull(ps []xy) []xy {
+	if len(ps) < 3 {
+		return append([]xy(nil), ps...)
+	}
+	// Andrew's monotone chain: the lower hull from left to right, then the
+	// upper hull from right to left.
+	hull := make([]xy, 0, 2*len(ps))
+	for _, p := range ps {
+		for len(hull) >= 2 && orient(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for i := len(ps) - 2; i >= 0; i-- {
+		p := ps[i]
+		for len(hull) >= lower && orient(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	return hull[:len(hull)-1]
+}
+
+// ConcaveHullOptions controls the shape of the polygon returned by
+// ConcaveHull. The zero value uses a concavity of 2.
+type ConcaveHullOptions struct {
+	// Concavity is the ratio of the length of an edge of the hull to the
+	// distance of a position from it above which the edge is replaced by two
+	// edges through the position. Smaller values give more detailed hulls,
+	// and an infinite value gives the convex hull.
+	Concavity float64
+	// Edges no longer than LengthThreshold are not replaced.
+	LengthThreshold float64
+}
+
+// ConcaveHull returns a polygon that covers the positions of o, like
+// ConvexHull, but follows them more closely. Starting from the convex hull,
+// each edge is replaced by two edges through the nearest position inside
+// the hull that is close enough to it, as long as the polygon stays simple
+// and covers every position, until no edge can be replaced. The result may
+// have collinear vertices. This takes time roughly quadratic in the number
+// of positions. See ConvexHull for details.
+func ConcaveHull(o Object, opts *ConcaveHullOptions) (*Polygon, bool) {
+	if opts == nil {
+		opts = &ConcaveHullOptions{}
+	}
+	concavity := opts.Concavity
+	if concavity == 0 {
+		concavity = 2
+	}
+	ps := hullPositions(o)
+	hull := convexHull(ps)
+	if len(hull) < 3 {
+		return nil, false
+	}
+	onHull := map[xy]bool{}
+	nodes := make([]*hullNode, len(hull))
+	for i, p := range hull {
+		onHull[p] = true
+		nodes[i] = &hullNode{p: p}
+	}
+	for i, n := range nodes {
+		n.next = nodes[(i+1)%len(nodes)]
+		n.next.prev = n
+	}
+	c := &concaveHull{start: nodes[0]}
+	for _, p := range ps {
+		if !onHull[p] {
+			c.inner = append(c.inner, p)
+		}
+	}
+
+	// Each node in the queue stands for the edge that starts there.
+	queue := nodes
+	for len(queue) > 0 {
+		a := queue[0]
+		queue = queue[1:]
+		b := a.next
+		length := math.Hypot(b.p.x-a.p.x, b.p.y-a.p.y)
+		if length <= opts.LengthThreshold {
+			continue
+		}
+		i := c.candidate(a, length/concavity)
+		if i < 0 {
+			continue
+		}
+		n := &hullNode{p: c.inner[i], prev: a, next: b}
+		a.next, b.prev = n, n
+		c.inner[i] = c.inner[len(c.inner)-1]
+		c.inner = c.inner[:len(c.inner)-1]
+		queue = append(queue, a, n)
+	}
+
+	var ring []xy
+	for n := c.start; ; {
+		ring = append(ring, n.p)
+		if n = n.next; n == c.start {
+			break
+		}
+	}
+	return &Polygon{Rings: []LineString{closedRing(ring)}}, true
+}
+
+// A hullNode is a vertex of a concave hull.
+type hullNode struct {
+	p          xy
+	prev, next *hullNode
+}
+
+// A concaveHull is a concave hull being refined.
+type concaveHull struct {
+	start *hullNode
+	// Positions inside the hull.
+	inner []xy
+}
+
+// candidate returns the index of the inner position through which to
+// replace the edge from a, or -1 if there is none. The position must be
+// within maxDist of the edge and closer to it than to the neighbouring
+// edges, and replacing the edge must not leave other positions outside
+// the hull or make edges cross.
+func (c *concaveHull) candidate(a *hullNode, maxDist float64) int {
+	b := a.next
+	type candidate struct {
+		i int
+		d float64
+	}
+	var cs []candidate
+	for i, q := range c.inner {
+		if d := xyDistance(q, a.p, b.p); d < maxDist {
+			cs = append(cs, candidate{i, d})
+		}
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].d < cs[j].d })
+	for _, cand := range cs {
+		q := c.inner[cand.i]
+		if cand.d >= xyDistance(q, a.prev.p, a.p) || cand.d >= xyDistance(q, b.p, b.next.p) {
+			continue
+		}
+		if c.coversOthers(a.p, b.p, q) || c.crosses(a, q) {
+			continue
+		}
+		return cand.i
+	}
+	return -1
+}
+
+// coversOthers reports whether an inner position other than p lies in the
+// triangle abp, excluding the edges ap and pb that remain on the hull.
+func (c *concaveHull) coversOthers(a, b, p xy) bool {
+	for _, q := range c.inner {
+		if orient(a, b, q) >= 0 && orient(b, p, q) > 0 && orient(p, a, q) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// crosses reports whether the edges from a to q and from q to a.next would
+// meet any other edge of the hull other than at their shared ends.
+func (c *concaveHull) crosses(a *hullNode, q xy) bool {
+	s := &segment{a: a.p, b: q}
+	t := &segment{a: q, b: a.next.p}
+	for n := c.start; ; {
+		if n != a {
+			e := &segment{a: n.p, b: n.next.p}
+			if improperlyMeet(s, e) || improperlyMeet(t, e) {
+				return true
+			}
+		}
+		if n = n.next; n == c.start {
+			return false
+		}
+	}
+}
+
+// xyDistance returns the distance from p to the segment from a to b.
+func xyDistance(p, a, b xy) float64 {
+	return segmentDistance(Point{X: p.x, Y: p.y}, Point{X: a.x, Y: a.y}, Point{X: b.x, Y: b.y})
+}
+
+// MinimumRotatedRectangle returns the rectangle of least area, in any
+// orientation, that covers the positions of o. See ConvexHull for details.
+func MinimumRotatedRectangle(o Object) (*Polygon, bool) {
+	hull := convexHull(hullPositions(o))
+	if len(hull) < 3 {
+		return nil, false
+	}
+	// The best rectangle has a side along an edge of the hull.
+	var best []xy
+	area := math.Inf(1)
+	for i, a := range hull {
+		b := hull[(i+1)%len(hull)]
+		l := math.Hypot(b.x-a.x, b.y-a.y)
+		ux, uy := (b.x-a.x)/l, (b.y-a.y)/l
+		// Measure along the edge and to its left, where the hull lies.
+		minU, maxU, maxV := math.Inf(1), math.Inf(-1), 0.0
+		for _, p := range hull {
+			dx, dy := p.x-a.x, p.y-a.y
+			u, v := dx*ux+dy*uy, dy*ux-dx*uy
+			minU, maxU, maxV = math.Min(minU, u), math.Max(maxU, u), math.Max(maxV, v)
+		}
+		if r := (maxU - minU) * maxV; r < area {
+			corner := func(u, v float64) xy {
+				return xy{a.x + u*ux - v*uy, a.y + u*uy + v*ux}
+			}
+			best = []xy{corner(minU, 0), corner(maxU, 0), corner(maxU, maxV), corner(minU, maxV)}
+			area = r
+		}
+	}
+	return &Polygon{Rings: []LineString{closedRing(best)}}, true
+}
+
+// MinimumBoundingCircle returns a regular polygon that covers the smallest
+// circle covering the positions of o, with quadrantSegments sides for each
+// quarter of the circle, or 8 if quadrantSegments is not positive. The
+// sides of the polygon touch the circle. Unlike the other hulls, the
+// positions may lie on a line, and it only returns false if there are fewer
+// than 2 of them. See ConvexHull for details.
+func MinimumBoundingCircle(o Object, quadrantSegments int) (*Polygon, bool) {
+	ps := hullPositions(o)
+	if len(ps) < 2 {
+		return nil, false
+	}
+	if quadrantSegments <= 0 {
+		quadrantSegments = 8
+	}
+	// Only vertices of the convex hull can lie on the circle.
+	center, radius := boundingCircle(convexHull(ps))
+	n := 4 * quadrantSegments
+	radius /= math.Cos(math.Pi / float64(n))
+	ring := make([]xy, n)
+	for i := range ring {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+		ring[i] = xy{center.x + radius*cos, center.y + radius*sin}
+	}
+	return &Polygon{Rings: []LineString{closedRing(ring)}}, true
+}
+
+// boundingCircle returns the center and radius of the smallest circle
+// covering ps, which must not contain 3 collinear positions, using Welzl's
+// algorithm. The positions are shuffled, with a fixed seed so that the
+// result is repeatable, which makes the expected running time linear.
+func boundingCircle(ps []xy) (xy, float64) {
+	ps = append([]xy(nil), ps...)
+	r := rand.New(rand.NewSource(1))
+	r.Shuffle(len(ps), func(i, j int) { ps[i], ps[j] = ps[j], ps[i] })
+	center, radius := ps[0], 0.0
+	covers := func(p xy) bool {
+		return math.Hypot(p.x-center.x, p.y-center.y) <= radius*(1+1e-12)
+	}
+	for i := 1; i < len(ps); i++ {
+		if covers(ps[i]) {
+			continue
+		}
+		center, radius = ps[i], 0
+		for j := 0; j < i; j++ {
+			if covers(ps[j]) {
+				continue
+			}
+			center = xy{(ps[i].x + ps[j].x) / 2, (ps[i].y + ps[j].y) / 2}
+			radius = math.Hypot(ps[i].x-center.x, ps[i].y-center.y)
+			for k := 0; k < j; k++ {
+				if !covers(ps[k]) {
+					center = circumcenter(ps[i], ps[j], ps[k])
+					radius = math.Hypot(ps[i].x-center.x, ps[i].y-center.y)
+				}
+			}
+		}
+	}
+	return center, radius
+}
+
+// circumcenter returns the center of the circle through a, b, and c, which
+// must not be collinear.
+func circumcenter(a, b, c xy) xy {
+	bx, by := b.x-a.x, b.y-a.y
+	cx, cy := c.x-a.x, c.y-a.y
+	d := 2 * (bx*cy - by*cx)
+	b2, c2 := bx*bx+by*by, cx*cx+cy*cy
+	return xy{a.x + (cy*b2-by*c2)/d, a.y + (bx*c2-cx*b2)/d}
+}
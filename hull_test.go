@@ -0,0 +1,200 @@
+package geojson
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestConvexHull(t *testing.T) {
+	square := &Polygon{Rings: []LineString{{Points: []Point{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 0, Y: 2}, {X: 0, Y: 0}}}}}
+	cases := []struct {
+		o        Object
+		expected *Polygon
+		ok       bool
+	}{
+		{&MultiPoint{Points: []Point{{X: 1, Y: 1}, {X: 2, Y: 2}, {X: 0, Y: 2}, {X: 1, Y: 0}, {X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 1}}}, square, true},
+		// Clockwise input.
+		{&LineString{Points: []Point{{X: 0, Y: 0}, {X: 0, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 0}, {X: 0, Y: 0}}}, square, true},
+		{&FeatureCollection{Features: []Feature{
+			{Geometry: &Point{X: 0, Y: 0, Elevation: 5, HasElevation: true}},
+			{Geometry: &LineString{Points: []Point{{X: 2, Y: 0}, {X: 2, Y: 2}}}},
+			{},
+			{Geometry: &Point{X: 0, Y: 2}},
+		}}, square, true},
+		{&Feature{Geometry: square}, square, true},
+		{&MultiPoint{Points: []Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}}}, nil, false},
+		{&Point{X: 1, Y: 1}, nil, false},
+		{&GeometryCollection{}, nil, false},
+		{&FeatureCollection{}, nil, false},
+	}
+	for i, c := range cases {
+		p, ok := ConvexHull(c.o)
+		if ok != c.ok || !reflect.DeepEqual(p, c.expected) {
+			t.Errorf("case %d: expected (%v, %v), got (%v, %v)", i, c.expected, c.ok, p, ok)
+		}
+	}
+}
+
+func TestConcaveHull(t *testing.T) {
+	// A C shape, open to the right.
+	var c MultiPoint
+	for x := 0; x <= 10; x++ {
+		for y := 0; y <= 10; y++ {
+			if x < 3 || y < 3 || y > 7 {
+				c.Points = append(c.Points, Point{X: float64(x), Y: float64(y)})
+			}
+		}
+	}
+	p, ok := ConcaveHull(&c, &ConcaveHullOptions{Concavity: 0.5})
+	if !ok {
+		t.Fatal("expected a hull")
+	}
+	checkHull(t, "C", &c, p)
+	// The hull follows the C exactly: three bars of area 20 that overlap in
+	// two squares of area 4.
+	if area := planarArea(&MultiPolygon{Polygons: []Polygon{*p}}); math.Abs(area-52) > 1e-9 {
+		t.Errorf("expected area 52, got %v (%v)", area, p)
+	}
+	// Less concave hulls do not reach into the notch.
+	if p, _ := ConcaveHull(&c, nil); planarArea(&MultiPolygon{Polygons: []Polygon{*p}}) != 100 {
+		t.Errorf("expected the convex hull, got %v", p)
+	}
+
+	convex, _ := ConvexHull(&c)
+	for _, opts := range []*ConcaveHullOptions{{Concavity: math.Inf(1)}, {LengthThreshold: 100}} {
+		if p, _ := ConcaveHull(&c, opts); !reflect.DeepEqual(p, convex) {
+			t.Errorf("%+v: expected the convex hull %v, got %v", opts, convex, p)
+		}
+	}
+	if _, ok := ConcaveHull(&MultiPoint{Points: []Point{{X: 0, Y: 0}, {X: 1, Y: 1}}}, nil); ok {
+		t.Error("expected no hull")
+	}
+
+	// Random clouds, including positions on a coarse grid, which are often
+	// collinear.
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		var m MultiPoint
+		for j := 0; j < 10+r.Intn(200); j++ {
+			x, y := r.NormFloat64(), r.NormFloat64()
+			if i%2 == 0 {
+				x, y = math.Round(x*4), math.Round(y*4)
+			}
+			m.Points = append(m.Points, Point{X: x, Y: y})
+		}
+		for _, concavity := range []float64{0.5, 1, 2, 3} {
+			p, ok := ConcaveHull(&m, &ConcaveHullOptions{Concavity: concavity})
+			if !ok {
+				t.Fatalf("cloud %d: expected a hull", i)
+			}
+			checkHull(t, "random", &m, p)
+		}
+	}
+}
+
+// checkHull checks that p is a simple, counter-clockwise polygon that
+// covers the positions of g.
+func checkHull(t *testing.T, name string, g Geometry, p *Polygon) {
+	t.Helper()
+	points := p.Rings[0].Points
+	if len(p.Rings) != 1 || len(points) < 4 || points[0] != points[len(points)-1] {
+		t.Fatalf("%s: invalid polygon %v", name, p)
+	}
+	if a, _, _ := ringCentroid(points); a <= 0 {
+		t.Errorf("%s: expected a counter-clockwise ring, got %v", name, points)
+	}
+	for i := 1; i < len(points); i++ {
+		s := &segment{a: pos(points[i-1]), b: pos(points[i])}
+		for j := i + 1; j < len(points); j++ {
+			e := &segment{a: pos(points[j-1]), b: pos(points[j])}
+			if improperlyMeet(s, e) || j > i+1 && !(i == 1 && j == len(points)-1) && len(intersect(s, e)) > 0 {
+				t.Errorf("%s: edges %v and %v meet in %v", name, s, e, points)
+			}
+		}
+	}
+	walkPositions(g, func(q Point) {
+		if !p.Covers(q) {
+			t.Errorf("%s: %v is not covered by %v", name, q, points)
+		}
+	})
+}
+
+func TestMinimumRotatedRectangle(t *testing.T) {
+	// A 4 by 1 rectangle rotated by 30 degrees, with positions inside.
+	sin, cos := math.Sincos(math.Pi / 6)
+	rotate := func(x, y float64) Point { return Point{X: x*cos - y*sin, Y: x*sin + y*cos} }
+	m := &MultiPoint{Points: []Point{rotate(0, 0), rotate(4, 0), rotate(4, 1), rotate(0, 1), rotate(1, 0.5), rotate(3, 0.2)}}
+	p, ok := MinimumRotatedRectangle(m)
+	if !ok {
+		t.Fatal("expected a rectangle")
+	}
+	if area := planarArea(&MultiPolygon{Polygons: []Polygon{*p}}); math.Abs(area-4) > 1e-12 {
+		t.Errorf("expected area 4, got %v", area)
+	}
+	for _, corner := range m.Points[:4] {
+		found := false
+		for _, q := range p.Rings[0].Points {
+			found = found || math.Hypot(q.X-corner.X, q.Y-corner.Y) < 1e-12
+		}
+		if !found {
+			t.Errorf("expected a corner at %v, got %v", corner, p.Rings[0].Points)
+		}
+	}
+
+	// Rectangles that are aligned with the axes are exact.
+	aligned := &MultiPoint{Points: []Point{{X: 3, Y: 2}, {X: 0, Y: 0}, {X: 1, Y: 1}, {X: 3, Y: 0}, {X: 0, Y: 2}, {X: 2, Y: 2}}}
+	expected := &Polygon{Rings: []LineString{{Points: []Point{{X: 0, Y: 0}, {X: 3, Y: 0}, {X: 3, Y: 2}, {X: 0, Y: 2}, {X: 0, Y: 0}}}}}
+	if p, _ := MinimumRotatedRectangle(aligned); !reflect.DeepEqual(p, expected) {
+		t.Errorf("expected %v, got %v", expected, p)
+	}
+	if _, ok := MinimumRotatedRectangle(&LineString{Points: []Point{{X: 0, Y: 0}, {X: 1, Y: 1}}}); ok {
+		t.Error("expected no rectangle")
+	}
+}
+
+func TestMinimumBoundingCircle(t *testing.T) {
+	cases := []struct {
+		g      Geometry
+		center Point
+		radius float64
+	}{
+		{&MultiPoint{Points: []Point{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: -1}, {X: 1, Y: 0.5}}}, Point{X: 1, Y: 0}, 1},
+		{&LineString{Points: []Point{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 4, Y: 0}}}, Point{X: 2, Y: 0}, 2},
+		// An obtuse triangle fits in the circle on its longest side.
+		{&MultiPoint{Points: []Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 2, Y: 0.5}}}, Point{X: 2, Y: 0}, 2},
+		// An acute triangle needs its circumcircle.
+		{&MultiPoint{Points: []Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 2, Y: 3}}}, Point{X: 2, Y: 5.0 / 6}, 13.0 / 6},
+	}
+	for i, c := range cases {
+		for _, segments := range []int{0, 1, 16} {
+			p, ok := MinimumBoundingCircle(c.g, segments)
+			if !ok {
+				t.Fatalf("case %d: expected a circle", i)
+			}
+			n := 4 * segments
+			if segments == 0 {
+				n = 32
+			}
+			points := p.Rings[0].Points
+			if len(points) != n+1 || points[0] != points[n] {
+				t.Fatalf("case %d: expected a closed ring of %d sides, got %v", i, n, points)
+			}
+			for _, q := range points[:n] {
+				d := math.Hypot(q.X-c.center.X, q.Y-c.center.Y) * math.Cos(math.Pi/float64(n))
+				if math.Abs(d-c.radius) > 1e-12 {
+					t.Errorf("case %d: expected %v to be %v from %v, got %v", i, q, c.radius, c.center, d)
+				}
+			}
+			walkPositions(c.g, func(q Point) {
+				if !p.Covers(q) {
+					t.Errorf("case %d: %v is not covered by %v", i, q, points)
+				}
+			})
+		}
+	}
+	if _, ok := MinimumBoundingCircle(&MultiPoint{Points: []Point{{X: 1, Y: 1}, {X: 1, Y: 1}}}, 0); ok {
+		t.Error("expected no circle")
+	}
+}
@@ -0,0 +1,53 @@
+// Package coords walks the positions of geometries for the format packages.
+package coords
+
+import "github.com/bsidhom/geojson"
+
+// Each calls fn for every position in g, descending into geometry
+// collections. fn may modify the position.
+func Each(g geojson.Geometry, fn func(p *geojson.Point)) {
+	line := func(points []geojson.Point) {
+		for i := range points {
+			fn(&points[i])
+		}
+	}
+	switch t := g.(type) {
+	case *geojson.Point:
+		fn(t)
+	case *geojson.MultiPoint:
+		line(t.Points)
+	case *geojson.LineString:
+		line(t.Points)
+	case *geojson.MultiLineString:
+		for _, ls := range t.Lines {
+			line(ls.Points)
+		}
+	case *geojson.Polygon:
+		for _, ring := range t.Rings {
+			line(ring.Points)
+		}
+	case *geojson.MultiPolygon:
+		for _, p := range t.Polygons {
+			for _, ring := range p.Rings {
+				line(ring.Points)
+			}
+		}
+	case *geojson.GeometryCollection:
+		for _, child := range t.Geometries {
+			Each(child, fn)
+		}
+	}
+}
+
+// HasElevation reports whether gs have at least one position and every
+// position has an elevation. Nil geometries are skipped.
+func HasElevation(gs ...geojson.Geometry) bool {
+	var seen, missing bool
+	for _, g := range gs {
+		Each(g, func(p *geojson.Point) {
+			seen = true
+			missing = missing || !p.HasElevation
+		})
+	}
+	return seen && !missing
+}
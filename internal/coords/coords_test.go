@@ -0,0 +1,53 @@
+package coords
+
+import (
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestEach(t *testing.T) {
+	g := &geojson.GeometryCollection{Geometries: []geojson.Geometry{
+		&geojson.Point{X: 1, Y: 2},
+		&geojson.MultiPolygon{Polygons: []geojson.Polygon{
+			{Rings: []geojson.LineString{{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0}}}}},
+		}},
+		&geojson.GeometryCollection{Geometries: []geojson.Geometry{
+			&geojson.LineString{Points: []geojson.Point{{X: 5, Y: 5}, {X: 6, Y: 6}}},
+		}},
+	}}
+	n := 0
+	Each(g, func(p *geojson.Point) {
+		n++
+		p.X++
+	})
+	if n != 7 {
+		t.Errorf("expected 7 positions, got %d", n)
+	}
+	if x := g.Geometries[2].(*geojson.GeometryCollection).Geometries[0].(*geojson.LineString).Points[1].X; x != 7 {
+		t.Errorf("expected positions to be modified in place, got X = %v", x)
+	}
+}
+
+func TestHasElevation(t *testing.T) {
+	z := geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true}
+	flat := geojson.Point{X: 1, Y: 2}
+	cases := []struct {
+		gs       []geojson.Geometry
+		expected bool
+	}{
+		{nil, false},
+		{[]geojson.Geometry{nil}, false},
+		{[]geojson.Geometry{&geojson.MultiPoint{}}, false},
+		{[]geojson.Geometry{&z}, true},
+		{[]geojson.Geometry{&flat}, false},
+		{[]geojson.Geometry{&z, nil, &geojson.LineString{Points: []geojson.Point{z, z}}}, true},
+		{[]geojson.Geometry{&z, &geojson.LineString{Points: []geojson.Point{z, flat}}}, false},
+		{[]geojson.Geometry{&geojson.GeometryCollection{Geometries: []geojson.Geometry{&z, &flat}}}, false},
+	}
+	for i, c := range cases {
+		if actual := HasElevation(c.gs...); actual != c.expected {
+			t.Errorf("case %d: expected %v, got %v", i, c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,476 @@
+// Package flatbuf implements just enough of the FlatBuffers binary format
+// to read and write the tables of formats such as FlatGeobuf and Arrow
+// without depending on generated code.
+//
+// Buffers are written front to back: each table is preceded by its vtable
+// and followed by the strings, vectors, and tables it references. Since
+// FlatBuffers offsets to referenced objects are unsigned, referenced objects
+// must come after their referrers, which this layout guarantees.
+package flatbuf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// An Object is anything that can be referenced by offset from a table.
+type Object interface {
+	// write appends the object to b and returns its position.
+	write(b *builder) int
+}
+
+type builder struct {
+	buf []byte
+}
+
+func (b *builder) pad(alignment int) {
+	for len(b.buf)%alignment != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+// padBefore pads so that after writing n bytes the position is aligned.
+func (b *builder) padBefore(n, alignment int) {
+	for (len(b.buf)+n)%alignment != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+func (b *builder) uint32At(pos int, v uint32) {
+	binary.LittleEndian.PutUint32(b.buf[pos:], v)
+}
+
+// Build serializes root as a complete FlatBuffer.
+func Build(root *Table) []byte {
+	b := &builder{buf: make([]byte, 4)}
+	pos := root.write(b)
+	b.uint32At(0, uint32(pos))
+	return b.buf
+}
+
+// A Table is a table under construction. Fields are addressed by their
+// schema index.
+type Table struct {
+	fields map[int]field
+}
+
+type field struct {
+	// Little-endian encoding of a scalar field. Nil for offset fields.
+	scalar []byte
+	// Referenced object for offset fields.
+	ref Object
+}
+
+// NewTable returns an empty Table.
+func NewTable() *Table {
+	return &Table{fields: map[int]field{}}
+}
+
+func (t *Table) Uint8(i int, v uint8) {
+	t.fields[i] = field{scalar: []byte{v}}
+}
+
+func (t *Table) Bool(i int, v bool) {
+	if v {
+		t.Uint8(i, 1)
+	} else {
+		t.Uint8(i, 0)
+	}
+}
+
+func (t *Table) Uint16(i int, v uint16) {
+	s := make([]byte, 2)
+	binary.LittleEndian.PutUint16(s, v)
+	t.fields[i] = field{scalar: s}
+}
+
+func (t *Table) Int32(i int, v int32) {
+	s := make([]byte, 4)
+	binary.LittleEndian.PutUint32(s, uint32(v))
+	t.fields[i] = field{scalar: s}
+}
+
+func (t *Table) Uint64(i int, v uint64) {
+	s := make([]byte, 8)
+	binary.LittleEndian.PutUint64(s, v)
+	t.fields[i] = field{scalar: s}
+}
+
+func (t *Table) Ref(i int, o Object) {
+	t.fields[i] = field{ref: o}
+}
+
+func (t *Table) String(i int, s string) {
+	if s != "" {
+		t.Ref(i, String(s))
+	}
+}
+
+func (t *Table) write(b *builder) int {
+	numFields := 0
+	var indices []int
+	for i := range t.fields {
+		indices = append(indices, i)
+		if i+1 > numFields {
+			numFields = i + 1
+		}
+	}
+	// Lay out fields by decreasing size so that each is naturally aligned
+	// relative to the 8-aligned table start.
+	sort.Slice(indices, func(a, c int) bool {
+		sa, sc := t.fields[indices[a]].size(), t.fields[indices[c]].size()
+		if sa != sc {
+			return sa > sc
+		}
+		return indices[a] < indices[c]
+	})
+	offsets := make([]int, numFields)
+	size := 4
+	for _, i := range indices {
+		s := t.fields[i].size()
+		for size%s != 0 {
+			size++
+		}
+		offsets[i] = size
+		size += s
+	}
+	for size%4 != 0 {
+		size++
+	}
+
+	vtableSize := 4 + 2*numFields
+	b.pad(2)
+	b.padBefore(vtableSize, 8)
+	vtable := len(b.buf)
+	b.buf = append(b.buf, make([]byte, vtableSize)...)
+	binary.LittleEndian.PutUint16(b.buf[vtable:], uint16(vtableSize))
+	binary.LittleEndian.PutUint16(b.buf[vtable+2:], uint16(size))
+	for i, off := range offsets {
+		binary.LittleEndian.PutUint16(b.buf[vtable+4+2*i:], uint16(off))
+	}
+
+	table := len(b.buf)
+	b.buf = append(b.buf, make([]byte, size)...)
+	binary.LittleEndian.PutUint32(b.buf[table:], uint32(int32(table-vtable)))
+	for _, i := range indices {
+		if s := t.fields[i].scalar; s != nil {
+			copy(b.buf[table+offsets[i]:], s)
+		}
+	}
+	// Referenced objects follow the table, in field order.
+	sort.Ints(indices)
+	for _, i := range indices {
+		if ref := t.fields[i].ref; ref != nil {
+			at := table + offsets[i]
+			pos := ref.write(b)
+			b.uint32At(at, uint32(pos-at))
+		}
+	}
+	return table
+}
+
+func (f field) size() int {
+	if f.ref != nil {
+		return 4
+	}
+	return len(f.scalar)
+}
+
+// A String is a string that is written even if it is empty, for required
+// fields. Table.String omits empty strings.
+type String string
+
+func (s String) write(b *builder) int {
+	b.pad(4)
+	pos := len(b.buf)
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(s)))
+	b.buf = append(b.buf, n[:]...)
+	b.buf = append(b.buf, s...)
+	b.buf = append(b.buf, 0)
+	return pos
+}
+
+// A Scalars is a vector of fixed-size scalars, already encoded.
+type Scalars struct {
+	elemSize  int
+	alignment int
+	data      []byte
+}
+
+func Float64Vector(values []float64) *Scalars {
+	data := make([]byte, 8*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(data[8*i:], math.Float64bits(v))
+	}
+	return &Scalars{elemSize: 8, alignment: 8, data: data}
+}
+
+func Uint32Vector(values []uint32) *Scalars {
+	data := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(data[4*i:], v)
+	}
+	return &Scalars{elemSize: 4, alignment: 4, data: data}
+}
+
+func ByteVector(data []byte) *Scalars {
+	return &Scalars{elemSize: 1, alignment: 1, data: data}
+}
+
+// StructVector returns a vector of structs of the given size, already
+// encoded into data. Structs are assumed to need 8-byte alignment.
+func StructVector(size int, data []byte) *Scalars {
+	return &Scalars{elemSize: size, alignment: 8, data: data}
+}
+
+func (v *Scalars) write(b *builder) int {
+	alignment := v.alignment
+	if alignment < 4 {
+		alignment = 4
+	}
+	b.pad(4)
+	b.padBefore(4, alignment)
+	pos := len(b.buf)
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(v.data)/v.elemSize))
+	b.buf = append(b.buf, n[:]...)
+	b.buf = append(b.buf, v.data...)
+	return pos
+}
+
+// A Tables is a vector of tables.
+type Tables []*Table
+
+func (v Tables) write(b *builder) int {
+	b.pad(4)
+	pos := len(b.buf)
+	b.buf = append(b.buf, make([]byte, 4+4*len(v))...)
+	b.uint32At(pos, uint32(len(v)))
+	for i, t := range v {
+		at := pos + 4 + 4*i
+		p := t.write(b)
+		b.uint32At(at, uint32(p-at))
+	}
+	return pos
+}
+
+// A Reader reads FlatBuffer tables from a buffer. Out-of-bounds accesses
+// are recorded and yield zero values, so callers can check Err once after
+// decoding a whole structure.
+type Reader struct {
+	buf []byte
+	err error
+}
+
+// NewReader returns a Reader for the FlatBuffer b.
+func NewReader(b []byte) *Reader {
+	return &Reader{buf: b}
+}
+
+// Err returns the first out-of-bounds access, if any.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+func (r *Reader) check(pos, n int) bool {
+	if r.err != nil {
+		return false
+	}
+	if pos < 0 || n < 0 || pos+n > len(r.buf) || pos+n < pos {
+		r.err = fmt.Errorf("flatbuffer offset %d (+%d) out of range", pos, n)
+		return false
+	}
+	return true
+}
+
+func (r *Reader) u8(pos int) uint8 {
+	if !r.check(pos, 1) {
+		return 0
+	}
+	return r.buf[pos]
+}
+
+func (r *Reader) u16(pos int) uint16 {
+	if !r.check(pos, 2) {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(r.buf[pos:])
+}
+
+func (r *Reader) u32(pos int) uint32 {
+	if !r.check(pos, 4) {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(r.buf[pos:])
+}
+
+func (r *Reader) u64(pos int) uint64 {
+	if !r.check(pos, 8) {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(r.buf[pos:])
+}
+
+// Root returns the root table of the buffer.
+func (r *Reader) Root() TableRef {
+	return TableRef{r: r, pos: int(r.u32(0))}
+}
+
+// A TableRef locates a table within a buffer.
+type TableRef struct {
+	r   *Reader
+	pos int
+}
+
+// Err returns the first out-of-bounds access in the buffer of t, if any.
+func (t TableRef) Err() error {
+	return t.r.err
+}
+
+// field returns the absolute position of field i, or 0 if it is absent.
+func (t TableRef) field(i int) int {
+	vtable := t.pos - int(int32(t.r.u32(t.pos)))
+	vtableSize := int(t.r.u16(vtable))
+	if 4+2*i+2 > vtableSize {
+		return 0
+	}
+	off := int(t.r.u16(vtable + 4 + 2*i))
+	if off == 0 {
+		return 0
+	}
+	return t.pos + off
+}
+
+func (t TableRef) Uint8(i int, def uint8) uint8 {
+	if p := t.field(i); p != 0 {
+		return t.r.u8(p)
+	}
+	return def
+}
+
+func (t TableRef) Bool(i int, def bool) bool {
+	if p := t.field(i); p != 0 {
+		return t.r.u8(p) != 0
+	}
+	return def
+}
+
+func (t TableRef) Uint16(i int, def uint16) uint16 {
+	if p := t.field(i); p != 0 {
+		return t.r.u16(p)
+	}
+	return def
+}
+
+func (t TableRef) Int32(i int, def int32) int32 {
+	if p := t.field(i); p != 0 {
+		return int32(t.r.u32(p))
+	}
+	return def
+}
+
+func (t TableRef) Uint64(i int, def uint64) uint64 {
+	if p := t.field(i); p != 0 {
+		return t.r.u64(p)
+	}
+	return def
+}
+
+// deref follows the offset stored in field i.
+func (t TableRef) deref(i int) int {
+	p := t.field(i)
+	if p == 0 {
+		return 0
+	}
+	return p + int(t.r.u32(p))
+}
+
+func (t TableRef) String(i int) string {
+	p := t.deref(i)
+	if p == 0 {
+		return ""
+	}
+	n := int(t.r.u32(p))
+	if !t.r.check(p+4, n) {
+		return ""
+	}
+	return string(t.r.buf[p+4 : p+4+n])
+}
+
+// vector returns the position of the first element of the vector in field i
+// and its length, after verifying that it fits in the buffer.
+func (t TableRef) vector(i, elemSize int) (int, int) {
+	p := t.deref(i)
+	if p == 0 {
+		return 0, 0
+	}
+	n := int(t.r.u32(p))
+	if !t.r.check(p+4, n*elemSize) {
+		return 0, 0
+	}
+	return p + 4, n
+}
+
+func (t TableRef) Bytes(i int) []byte {
+	p, n := t.vector(i, 1)
+	if n == 0 {
+		return nil
+	}
+	return t.r.buf[p : p+n]
+}
+
+func (t TableRef) Float64s(i int) []float64 {
+	p, n := t.vector(i, 8)
+	if n == 0 {
+		return nil
+	}
+	values := make([]float64, n)
+	for j := range values {
+		values[j] = math.Float64frombits(binary.LittleEndian.Uint64(t.r.buf[p+8*j:]))
+	}
+	return values
+}
+
+func (t TableRef) Uint32s(i int) []uint32 {
+	p, n := t.vector(i, 4)
+	if n == 0 {
+		return nil
+	}
+	values := make([]uint32, n)
+	for j := range values {
+		values[j] = binary.LittleEndian.Uint32(t.r.buf[p+4*j:])
+	}
+	return values
+}
+
+func (t TableRef) Table(i int) (TableRef, bool) {
+	p := t.deref(i)
+	if p == 0 {
+		return TableRef{}, false
+	}
+	return TableRef{r: t.r, pos: p}, true
+}
+
+// Structs returns the encoded elements of a vector of structs of the given
+// size.
+func (t TableRef) Structs(i, size int) [][]byte {
+	p, n := t.vector(i, size)
+	structs := make([][]byte, n)
+	for j := range structs {
+		structs[j] = t.r.buf[p+size*j : p+size*(j+1)]
+	}
+	return structs
+}
+
+func (t TableRef) Tables(i int) []TableRef {
+	p, n := t.vector(i, 4)
+	tables := make([]TableRef, n)
+	for j := range tables {
+		at := p + 4*j
+		tables[j] = TableRef{r: t.r, pos: at + int(t.r.u32(at))}
+	}
+	return tables
+}
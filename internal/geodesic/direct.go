@@ -0,0 +1,69 @@
+package geodesic
+
+import "math"
+
+// Direct returns the end point and the azimuth at the end point of the
+// geodesic that starts at lat1, lon1 with azimuth azi1 and has length s12.
+// The returned longitude lies in (-180, 180].
+func (e *Ellipsoid) Direct(lat1, lon1, azi1, s12 float64) (lat2, lon2, azi2 float64) {
+	salp1, calp1 := sincosd(angRound(azi1))
+	sbet1, cbet1 := sincosd(angRound(lat1))
+	sbet1 *= e.f1
+	sbet1, cbet1 = norm(sbet1, cbet1)
+	cbet1 = math.Max(tiny, cbet1)
+
+	salp0 := salp1 * cbet1
+	calp0 := math.Hypot(calp1, salp1*sbet1)
+	ssig1 := sbet1
+	somg1 := salp0 * sbet1
+	csig1 := 1.0
+	if sbet1 != 0 || calp1 != 0 {
+		csig1 = cbet1 * calp1
+	}
+	comg1 := csig1
+	ssig1, csig1 = norm(ssig1, csig1)
+
+	k2 := sq(calp0) * e.ep2
+	eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+	a1m1 := a1m1f(eps)
+	var c1a [nC1 + 1]float64
+	c1f(eps, c1a[:])
+	b11 := sinCosSeries(true, ssig1, csig1, c1a[:])
+	s, c := math.Sin(b11), math.Cos(b11)
+	stau1 := ssig1*c + csig1*s
+	ctau1 := csig1*c - ssig1*s
+	var c1pa [nC1p + 1]float64
+	c1pf(eps, c1pa[:])
+	a3c := -e.f * salp0 * e.a3f(eps)
+	var c3a [nC3]float64
+	e.c3f(eps, c3a[:])
+	b31 := sinCosSeries(true, ssig1, csig1, c3a[:])
+
+	// Find the arc length on the auxiliary sphere.
+	tau12 := s12 / (e.b * (1 + a1m1))
+	s, c = math.Sin(tau12), math.Cos(tau12)
+	b12 := -sinCosSeries(true, stau1*c+ctau1*s, ctau1*c-stau1*s, c1pa[:])
+	sig12 := tau12 - (b12 - b11)
+	ssig12, csig12 := math.Sin(sig12), math.Cos(sig12)
+	ssig2 := ssig1*csig12 + csig1*ssig12
+	csig2 := csig1*csig12 - ssig1*ssig12
+
+	sbet2 := calp0 * ssig2
+	cbet2 := math.Hypot(salp0, calp0*csig2)
+	if cbet2 == 0 {
+		// The end point is at a pole.
+		cbet2, csig2 = tiny, tiny
+	}
+	salp2 := salp0
+	calp2 := calp0 * csig2
+
+	somg2 := salp0 * ssig2
+	comg2 := csig2
+	omg12 := math.Atan2(somg2*comg1-comg2*somg1, comg2*comg1+somg2*somg1)
+	lam12 := omg12 + a3c*(sig12+(sinCosSeries(true, ssig2, csig2, c3a[:])-b31))
+	lon12 := lam12 * 180 / math.Pi
+	lon2 = angNormalize(angNormalize(lon1) + angNormalize(lon12))
+	lat2 = atan2d(sbet2, e.f1*cbet2)
+	azi2 = atan2d(salp2, calp2)
+	return lat2, lon2, azi2
+}
@@ -0,0 +1,683 @@
+// Package geodesic solves geodesic problems on an ellipsoid of revolution.
+// It is a port of the series expansions used by GeographicLib (C. F. F.
+// Karney, "Algorithms for geodesics", J. Geodesy 87, 43-55, 2013), which
+// are accurate to about 15 nanometres on the WGS84 ellipsoid.
+//
+// Only oblate ellipsoids and spheres are supported. Angles are in degrees
+// and lengths in the units of the equatorial radius.
+package geodesic
+
+import "math"
+
+// Orders of the series expansions.
+const (
+	nA1  = 6
+	nC1  = 6
+	nC1p = 6
+	nA2  = 6
+	nC2  = 6
+	nA3  = 6
+	nC3  = 6
+	nC3x = nC3 * (nC3 - 1) / 2
+	nC4  = 6
+	nC4x = nC4 * (nC4 + 1) / 2
+)
+
+const (
+	maxit1 = 20
+	maxit2 = maxit1 + 53 + 10
+)
+
+var (
+	tiny    = math.Sqrt(math.SmallestNonzeroFloat64 * (1 << 52))
+	tol0    = math.Nextafter(1, 2) - 1
+	tol1    = 200 * tol0
+	tol2    = math.Sqrt(tol0)
+	tolb    = tol0 * tol2
+	xthresh = 1000 * tol2
+)
+
+// An Ellipsoid is an ellipsoid of revolution.
+type Ellipsoid struct {
+	a, f      float64
+	f1, e2    float64
+	ep2, n, b float64
+	c2, etol2 float64
+	a3x       [nA3]float64
+	c3x       [nC3x]float64
+	c4x       [nC4x]float64
+}
+
+// WGS84 is the ellipsoid of the World Geodetic System 1984.
+var WGS84 = New(6378137, 1/298.257223563)
+
+// New returns the ellipsoid with equatorial radius a and flattening f,
+// which must not be negative.
+func New(a, f float64) *Ellipsoid {
+	e := &Ellipsoid{a: a, f: f}
+	e.f1 = 1 - f
+	e.e2 = f * (2 - f)
+	e.ep2 = e.e2 / sq(e.f1)
+	e.n = f / (2 - f)
+	e.b = a * e.f1
+	// Square of the authalic radius.
+	if e.e2 == 0 {
+		e.c2 = sq(a)
+	} else {
+		e.c2 = (sq(a) + sq(e.b)*math.Atanh(math.Sqrt(e.e2))/math.Sqrt(e.e2)) / 2
+	}
+	e.etol2 = 0.1 * tol2 / math.Sqrt(math.Max(0.001, math.Abs(f))*math.Min(1, 1-f/2)/2)
+	e.a3coeff()
+	e.c3coeff()
+	e.c4coeff()
+	return e
+}
+
+// Area returns the total surface area of e.
+func (e *Ellipsoid) Area() float64 {
+	return 4 * math.Pi * e.c2
+}
+
+func a1m1f(eps float64) float64 {
+	coeff := []float64{1, 4, 64, 0, 256}
+	m := nA1 / 2
+	t := polyval(m, coeff, 0, sq(eps)) / coeff[m+1]
+	return (t + eps) / (1 - eps)
+}
+
+func c1f(eps float64, c []float64) {
+	coeff := []float64{
+		-1, 6, -16, 32,
+		-9, 64, -128, 2048,
+		9, -16, 768,
+		3, -5, 512,
+		-7, 1280,
+		-7, 2048,
+	}
+	series(nC1, coeff, eps, c)
+}
+
+func c1pf(eps float64, c []float64) {
+	coeff := []float64{
+		205, -432, 768, 1536,
+		4005, -4736, 3840, 12288,
+		-225, 116, 384,
+		-7173, 2695, 7680,
+		3467, 7680,
+		38081, 61440,
+	}
+	series(nC1p, coeff, eps, c)
+}
+
+func a2m1f(eps float64) float64 {
+	coeff := []float64{-11, -28, -192, 0, 256}
+	m := nA2 / 2
+	t := polyval(m, coeff, 0, sq(eps)) / coeff[m+1]
+	return (t - eps) / (1 + eps)
+}
+
+func c2f(eps float64, c []float64) {
+	coeff := []float64{
+		1, 2, 16, 32,
+		35, 64, 384, 2048,
+		15, 80, 768,
+		7, 35, 512,
+		63, 1280,
+		77, 2048,
+	}
+	series(nC2, coeff, eps, c)
+}
+
+// series evaluates the coefficients c[1..n] of a Fourier series in eps.
+func series(n int, coeff []float64, eps float64, c []float64) {
+	eps2 := sq(eps)
+	d := eps
+	o := 0
+	for l := 1; l <= n; l++ {
+		m := (n - l) / 2
+		c[l] = d * polyval(m, coeff, o, eps2) / coeff[o+m+1]
+		o += m + 2
+		d *= eps
+	}
+}
+
+func (e *Ellipsoid) a3coeff() {
+	coeff := []float64{
+		-3, 128,
+		-2, -3, 64,
+		-1, -3, -1, 16,
+		3, -1, -2, 8,
+		1, -1, 2,
+		1, 1,
+	}
+	o, k := 0, 0
+	for j := nA3 - 1; j >= 0; j-- {
+		m := nA3 - j - 1
+		if j < m {
+			m = j
+		}
+		e.a3x[k] = polyval(m, coeff, o, e.n) / coeff[o+m+1]
+		k++
+		o += m + 2
+	}
+}
+
+func (e *Ellipsoid) c3coeff() {
+	coeff := []float64{
+		3, 128,
+		2, 5, 128,
+		-1, 3, 3, 64,
+		-1, 0, 1, 8,
+		-1, 1, 4,
+		5, 256,
+		-3, 3, 128,
+		-2, -3, 1, 64,
+		1, -3, 2, 32,
+		7, 512,
+		-10, 9, 384,
+		5, -9, 5, 192,
+		7, 512,
+		-14, 7, 512,
+		21, 2560,
+	}
+	o, k := 0, 0
+	for l := 1; l < nC3; l++ {
+		for j := nC3 - 1; j >= l; j-- {
+			m := nC3 - j - 1
+			if j < m {
+				m = j
+			}
+			e.c3x[k] = polyval(m, coeff, o, e.n) / coeff[o+m+1]
+			k++
+			o += m + 2
+		}
+	}
+}
+
+func (e *Ellipsoid) c4coeff() {
+	coeff := []float64{
+		97, 15015,
+		1088, 156, 45045,
+		-224, -4784, 1573, 45045,
+		-10656, 14144, -4576, -858, 45045,
+		64, 624, -4576, 6864, -3003, 15015,
+		100, 208, 572, 3432, -12012, 30030, 45045,
+		1, 9009,
+		-2944, 468, 135135,
+		5792, 1040, -1287, 135135,
+		5952, -11648, 9152, -2574, 135135,
+		-64, -624, 4576, -6864, 3003, 135135,
+		8, 10725,
+		1856, -936, 225225,
+		-8448, 4992, -1144, 225225,
+		-1440, 4160, -4576, 1716, 225225,
+		-136, 63063,
+		1024, -208, 105105,
+		3584, -3328, 1144, 315315,
+		-128, 135135,
+		-2560, 832, 405405,
+		128, 99099,
+	}
+	o, k := 0, 0
+	for l := 0; l < nC4; l++ {
+		for j := nC4 - 1; j >= l; j-- {
+			m := nC4 - j - 1
+			e.c4x[k] = polyval(m, coeff, o, e.n) / coeff[o+m+1]
+			k++
+			o += m + 2
+		}
+	}
+}
+
+func (e *Ellipsoid) a3f(eps float64) float64 {
+	return polyval(nA3-1, e.a3x[:], 0, eps)
+}
+
+func (e *Ellipsoid) c3f(eps float64, c []float64) {
+	mult := 1.0
+	o := 0
+	for l := 1; l < nC3; l++ {
+		m := nC3 - l - 1
+		mult *= eps
+		c[l] = mult * polyval(m, e.c3x[:], o, eps)
+		o += m + 1
+	}
+}
+
+func (e *Ellipsoid) c4f(eps float64, c []float64) {
+	mult := 1.0
+	o := 0
+	for l := 0; l < nC4; l++ {
+		m := nC4 - l - 1
+		c[l] = mult * polyval(m, e.c4x[:], o, eps)
+		o += m + 1
+		mult *= eps
+	}
+}
+
+// sinCosSeries evaluates a sine series (if sinp is set) or a cosine series
+// with coefficients c using Clenshaw summation.
+func sinCosSeries(sinp bool, sinx, cosx float64, c []float64) float64 {
+	k := len(c)
+	n := k
+	if sinp {
+		n--
+	}
+	ar := 2 * (cosx - sinx) * (cosx + sinx)
+	var y0, y1 float64
+	if n&1 != 0 {
+		k--
+		y0 = c[k]
+	}
+	for n /= 2; n > 0; n-- {
+		k--
+		y1 = ar*y0 - y1 + c[k]
+		k--
+		y0 = ar*y1 - y0 + c[k]
+	}
+	if sinp {
+		return 2 * sinx * cosx * y0
+	}
+	return cosx * (y0 - y1)
+}
+
+// lengths returns the distance (if distance is set) and reduced length of
+// a geodesic, both scaled by the polar radius, along with the coefficient
+// m0 of the reduced length.
+func (e *Ellipsoid) lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2 float64, distance bool, c1a, c2a []float64) (s12b, m12b, m0 float64) {
+	a1 := a1m1f(eps)
+	c1f(eps, c1a)
+	a2 := a2m1f(eps)
+	c2f(eps, c2a)
+	m0x := a1 - a2
+	a1++
+	a2++
+	var j12 float64
+	if distance {
+		b1 := sinCosSeries(true, ssig2, csig2, c1a) - sinCosSeries(true, ssig1, csig1, c1a)
+		s12b = a1 * (sig12 + b1)
+		b2 := sinCosSeries(true, ssig2, csig2, c2a) - sinCosSeries(true, ssig1, csig1, c2a)
+		j12 = m0x*sig12 + (a1*b1 - a2*b2)
+	} else {
+		for l := 1; l <= nC2; l++ {
+			c2a[l] = a1*c1a[l] - a2*c2a[l]
+		}
+		j12 = m0x*sig12 + (sinCosSeries(true, ssig2, csig2, c2a) - sinCosSeries(true, ssig1, csig1, c2a))
+	}
+	m12b = dn2*(csig1*ssig2) - dn1*(ssig1*csig2) - csig1*csig2*j12
+	return s12b, m12b, m0x
+}
+
+// astroid solves k^4 + 2 k^3 - (x^2 + y^2 - 1) k^2 - 2 y^2 k - y^2 = 0 for
+// its positive root.
+func astroid(x, y float64) float64 {
+	p := sq(x)
+	q := sq(y)
+	r := (p + q - 1) / 6
+	if q == 0 && r <= 0 {
+		return 0
+	}
+	s := p * q / 4
+	r2 := sq(r)
+	r3 := r * r2
+	disc := s * (s + 2*r3)
+	u := r
+	if disc >= 0 {
+		t3 := s + r3
+		if t3 < 0 {
+			t3 -= math.Sqrt(disc)
+		} else {
+			t3 += math.Sqrt(disc)
+		}
+		t := math.Cbrt(t3)
+		if t != 0 {
+			u += t + r2/t
+		}
+	} else {
+		ang := math.Atan2(math.Sqrt(-disc), -(s + r3))
+		u += 2 * r * math.Cos(ang/3)
+	}
+	v := math.Sqrt(sq(u) + q)
+	var uv float64
+	if u < 0 {
+		uv = q / (v - u)
+	} else {
+		uv = u + v
+	}
+	w := (uv - q) / (2 * v)
+	return uv / (math.Sqrt(uv+sq(w)) + w)
+}
+
+// inverseStart returns a starting guess for the azimuth of the inverse
+// problem. If sig12 is non-negative, the geodesic is short enough that the
+// guess is the solution.
+func (e *Ellipsoid) inverseStart(sbet1, cbet1, dn1, sbet2, cbet2, dn2, lam12, slam12, clam12 float64) (sig12, salp1, calp1, salp2, calp2, dnm float64) {
+	sig12 = -1
+	sbet12 := sbet2*cbet1 - cbet2*sbet1
+	cbet12 := cbet2*cbet1 + sbet2*sbet1
+	sbet12a := sbet2*cbet1 + cbet2*sbet1
+	shortline := cbet12 >= 0 && sbet12 < 0.5 && cbet2*lam12 < 0.5
+	var somg12, comg12 float64
+	if shortline {
+		sbetm2 := sq(sbet1 + sbet2)
+		sbetm2 /= sbetm2 + sq(cbet1+cbet2)
+		dnm = math.Sqrt(1 + e.ep2*sbetm2)
+		omg12 := lam12 / (e.f1 * dnm)
+		somg12, comg12 = math.Sin(omg12), math.Cos(omg12)
+	} else {
+		somg12, comg12 = slam12, clam12
+	}
+	salp1 = cbet2 * somg12
+	if comg12 >= 0 {
+		calp1 = sbet12 + cbet2*sbet1*sq(somg12)/(1+comg12)
+	} else {
+		calp1 = sbet12a - cbet2*sbet1*sq(somg12)/(1-comg12)
+	}
+	ssig12 := math.Hypot(salp1, calp1)
+	csig12 := sbet1*sbet2 + cbet1*cbet2*comg12
+
+	if shortline && ssig12 < e.etol2 {
+		// Really short geodesic.
+		salp2 = cbet1 * somg12
+		if comg12 >= 0 {
+			calp2 = sbet12 - cbet1*sbet2*sq(somg12)/(1+comg12)
+		} else {
+			calp2 = sbet12 - cbet1*sbet2*(1-comg12)
+		}
+		salp2, calp2 = norm(salp2, calp2)
+		sig12 = math.Atan2(ssig12, csig12)
+	} else if math.Abs(e.n) >= 0.1 || csig12 >= 0 || ssig12 >= 6*math.Abs(e.n)*math.Pi*sq(cbet1) {
+		// Nothing to do; the zeroth order spherical guess is good enough.
+	} else {
+		// Nearly antipodal points.
+		lam12x := math.Atan2(-slam12, -clam12)
+		k2 := sq(sbet1) * e.ep2
+		eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+		lamscale := e.f * cbet1 * e.a3f(eps) * math.Pi
+		betscale := lamscale * cbet1
+		x := lam12x / lamscale
+		y := sbet12a / betscale
+		if y > -tol1 && x > -1-xthresh {
+			salp1 = math.Min(1, -x)
+			calp1 = -math.Sqrt(1 - sq(salp1))
+		} else {
+			k := astroid(x, y)
+			omg12a := lamscale * (-x * k / (1 + k))
+			somg12, comg12 = math.Sin(omg12a), -math.Cos(omg12a)
+			salp1 = cbet2 * somg12
+			calp1 = sbet12a - cbet2*sbet1*sq(somg12)/(1-comg12)
+		}
+	}
+	if !(salp1 <= 0) {
+		salp1, calp1 = norm(salp1, calp1)
+	} else {
+		salp1, calp1 = 1, 0
+	}
+	return sig12, salp1, calp1, salp2, calp2, dnm
+}
+
+// lambda12 returns the longitude difference reached by the geodesic with
+// the starting azimuth alp1, along with its derivative if diffp is set.
+func (e *Ellipsoid) lambda12(sbet1, cbet1, dn1, sbet2, cbet2, dn2, salp1, calp1, slam120, clam120 float64, diffp bool, c1a, c2a, c3a []float64) (lam12, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, domg12, dlam12 float64) {
+	if sbet1 == 0 && calp1 == 0 {
+		// Break the degeneracy of equatorial lines.
+		calp1 = -tiny
+	}
+	salp0 := salp1 * cbet1
+	calp0 := math.Hypot(calp1, salp1*sbet1)
+	ssig1 = sbet1
+	somg1 := salp0 * sbet1
+	csig1 = calp1 * cbet1
+	comg1 := csig1
+	ssig1, csig1 = norm(ssig1, csig1)
+
+	if cbet2 != cbet1 {
+		salp2 = salp0 / cbet2
+	} else {
+		salp2 = salp1
+	}
+	if cbet2 != cbet1 || math.Abs(sbet2) != -sbet1 {
+		var d float64
+		if cbet1 < -sbet1 {
+			d = (cbet2 - cbet1) * (cbet1 + cbet2)
+		} else {
+			d = (sbet1 - sbet2) * (sbet1 + sbet2)
+		}
+		calp2 = math.Sqrt(sq(calp1*cbet1)+d) / cbet2
+	} else {
+		calp2 = math.Abs(calp1)
+	}
+	ssig2 = sbet2
+	somg2 := salp0 * sbet2
+	csig2 = calp2 * cbet2
+	comg2 := csig2
+	ssig2, csig2 = norm(ssig2, csig2)
+
+	sig12 = math.Atan2(math.Max(0, csig1*ssig2-ssig1*csig2), csig1*csig2+ssig1*ssig2)
+	somg12 := math.Max(0, comg1*somg2-somg1*comg2)
+	comg12 := comg1*comg2 + somg1*somg2
+	eta := math.Atan2(somg12*clam120-comg12*slam120, comg12*clam120+somg12*slam120)
+	k2 := sq(calp0) * e.ep2
+	eps = k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+	e.c3f(eps, c3a)
+	b312 := sinCosSeries(true, ssig2, csig2, c3a) - sinCosSeries(true, ssig1, csig1, c3a)
+	domg12 = -e.f * e.a3f(eps) * salp0 * (sig12 + b312)
+	lam12 = eta + domg12
+
+	dlam12 = math.NaN()
+	if diffp {
+		if calp2 == 0 {
+			dlam12 = -2 * e.f1 * dn1 / sbet1
+		} else {
+			_, dlam12, _ = e.lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2, false, c1a, c2a)
+			dlam12 *= e.f1 / (calp2 * cbet2)
+		}
+	}
+	return lam12, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, domg12, dlam12
+}
+
+// Inverse returns the length of the shortest geodesic between two points
+// along with its azimuths at each end, measured clockwise from north.
+func (e *Ellipsoid) Inverse(lat1, lon1, lat2, lon2 float64) (s12, azi1, azi2 float64) {
+	s12, salp1, calp1, salp2, calp2, _ := e.inverse(lat1, lon1, lat2, lon2, false)
+	return s12, atan2d(salp1, calp1), atan2d(salp2, calp2)
+}
+
+// inverse solves the inverse problem, also computing the area between the
+// geodesic and the equator if area is set.
+func (e *Ellipsoid) inverse(lat1, lon1, lat2, lon2 float64, area bool) (s12, salp1, calp1, salp2, calp2, s12Area float64) {
+	lon12, lon12s := angDiff(lon1, lon2)
+	lonsign := math.Copysign(1, lon12)
+	lon12 = lonsign * angRound(lon12)
+	lon12s = angRound((180 - lon12) - lonsign*lon12s)
+	lam12 := lon12 * math.Pi / 180
+	var slam12, clam12 float64
+	if lon12 > 90 {
+		slam12, clam12 = sincosd(lon12s)
+		clam12 = -clam12
+	} else {
+		slam12, clam12 = sincosd(lon12)
+	}
+
+	lat1 = angRound(lat1)
+	lat2 = angRound(lat2)
+	// Swap the points so that the first has the larger latitude magnitude,
+	// then make its latitude non-positive.
+	swapp := 1.0
+	if math.Abs(lat1) < math.Abs(lat2) {
+		swapp = -1
+		lonsign = -lonsign
+		lat1, lat2 = lat2, lat1
+	}
+	latsign := math.Copysign(1, -lat1)
+	lat1 *= latsign
+	lat2 *= latsign
+
+	sbet1, cbet1 := sincosd(lat1)
+	sbet1 *= e.f1
+	sbet1, cbet1 = norm(sbet1, cbet1)
+	cbet1 = math.Max(tiny, cbet1)
+	sbet2, cbet2 := sincosd(lat2)
+	sbet2 *= e.f1
+	sbet2, cbet2 = norm(sbet2, cbet2)
+	cbet2 = math.Max(tiny, cbet2)
+	if cbet1 < -sbet1 {
+		if cbet2 == cbet1 {
+			sbet2 = math.Copysign(sbet1, sbet2)
+		}
+	} else if math.Abs(sbet2) == -sbet1 {
+		cbet2 = cbet1
+	}
+	dn1 := math.Sqrt(1 + e.ep2*sq(sbet1))
+	dn2 := math.Sqrt(1 + e.ep2*sq(sbet2))
+
+	var c1a [nC1 + 1]float64
+	var c2a [nC2 + 1]float64
+	var c3a [nC3]float64
+	var sig12, s12x, omg12 float64
+	somg12, comg12 := math.NaN(), math.NaN()
+
+	meridian := lat1 == -90 || slam12 == 0
+	if meridian {
+		// The geodesic runs along a meridian, or is the shorter of the two
+		// paths over a pole.
+		calp1, salp1 = clam12, slam12
+		calp2, salp2 = 1, 0
+		ssig1, csig1 := sbet1, calp1*cbet1
+		ssig2, csig2 := sbet2, calp2*cbet2
+		sig12 = math.Atan2(math.Max(0, csig1*ssig2-ssig1*csig2), csig1*csig2+ssig1*ssig2)
+		var m12x float64
+		s12x, m12x, _ = e.lengths(e.n, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2, true, c1a[:], c2a[:])
+		// A negative reduced length means the meridian is not the shortest
+		// path, which happens for nearly antipodal points on an oblate
+		// ellipsoid.
+		if sig12 < 1 || m12x >= 0 {
+			if sig12 < 3*tiny || (sig12 < tol0 && (s12x < 0 || m12x < 0)) {
+				sig12, s12x = 0, 0
+			}
+			s12x *= e.b
+		} else {
+			meridian = false
+		}
+	}
+
+	if !meridian && sbet1 == 0 && (e.f <= 0 || lon12s >= e.f*180) {
+		// The geodesic runs along the equator.
+		calp1, calp2 = 0, 0
+		salp1, salp2 = 1, 1
+		s12x = e.a * lam12
+		sig12 = lam12 / e.f1
+		omg12 = sig12
+	} else if !meridian {
+		var dnm float64
+		sig12, salp1, calp1, salp2, calp2, dnm = e.inverseStart(sbet1, cbet1, dn1, sbet2, cbet2, dn2, lam12, slam12, clam12)
+		if sig12 >= 0 {
+			s12x = sig12 * e.b * dnm
+			omg12 = lam12 / (e.f1 * dnm)
+		} else {
+			// Solve for the starting azimuth with Newton's method, falling
+			// back to bisection.
+			var ssig1, csig1, ssig2, csig2, eps, domg12 float64
+			numit := 0
+			tripn, tripb := false, false
+			salp1a, calp1a := tiny, 1.0
+			salp1b, calp1b := tiny, -1.0
+			for ; numit < maxit2; numit++ {
+				var v, dv float64
+				v, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, domg12, dv = e.lambda12(sbet1, cbet1, dn1, sbet2, cbet2, dn2, salp1, calp1, slam12, clam12, numit < maxit1, c1a[:], c2a[:], c3a[:])
+				limit := 1.0
+				if tripn {
+					limit = 8
+				}
+				if tripb || !(math.Abs(v) >= limit*tol0) {
+					break
+				}
+				// Update the bracket.
+				if v > 0 && (numit > maxit1 || calp1/salp1 > calp1b/salp1b) {
+					salp1b, calp1b = salp1, calp1
+				} else if v < 0 && (numit > maxit1 || calp1/salp1 < calp1a/salp1a) {
+					salp1a, calp1a = salp1, calp1
+				}
+				if numit < maxit1 && dv > 0 {
+					dalp1 := -v / dv
+					if math.Abs(dalp1) < math.Pi {
+						sdalp1, cdalp1 := math.Sin(dalp1), math.Cos(dalp1)
+						nsalp1 := salp1*cdalp1 + calp1*sdalp1
+						if nsalp1 > 0 {
+							calp1 = calp1*cdalp1 - salp1*sdalp1
+							salp1 = nsalp1
+							salp1, calp1 = norm(salp1, calp1)
+							tripn = math.Abs(v) <= 16*tol0
+							continue
+						}
+					}
+				}
+				salp1 = (salp1a + salp1b) / 2
+				calp1 = (calp1a + calp1b) / 2
+				salp1, calp1 = norm(salp1, calp1)
+				tripn = false
+				tripb = math.Abs(salp1a-salp1)+(calp1a-calp1) < tolb ||
+					math.Abs(salp1-salp1b)+(calp1-calp1b) < tolb
+			}
+			s12x, _, _ = e.lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2, true, c1a[:], c2a[:])
+			s12x *= e.b
+			if area {
+				sdomg12, cdomg12 := math.Sin(domg12), math.Cos(domg12)
+				somg12 = slam12*cdomg12 - clam12*sdomg12
+				comg12 = clam12*cdomg12 + slam12*sdomg12
+			}
+		}
+	}
+	s12 = 0 + s12x
+
+	if area {
+		salp0 := salp1 * cbet1
+		calp0 := math.Hypot(calp1, salp1*sbet1)
+		if calp0 != 0 && salp0 != 0 {
+			ssig1, csig1 := norm(sbet1, calp1*cbet1)
+			ssig2, csig2 := norm(sbet2, calp2*cbet2)
+			k2 := sq(calp0) * e.ep2
+			eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+			a4 := sq(e.a) * calp0 * salp0 * e.e2
+			var c4a [nC4]float64
+			e.c4f(eps, c4a[:])
+			b41 := sinCosSeries(false, ssig1, csig1, c4a[:])
+			b42 := sinCosSeries(false, ssig2, csig2, c4a[:])
+			s12Area = a4 * (b42 - b41)
+		}
+		if !meridian && math.IsNaN(somg12) {
+			somg12, comg12 = math.Sin(omg12), math.Cos(omg12)
+		}
+		var alp12 float64
+		if !meridian && comg12 > -0.7071 && sbet2-sbet1 < 1.75 {
+			// Use the tan(Gamma/2) = tan(omg12/2) * (tan(bet1/2) +
+			// tan(bet2/2)) / (1 + tan(bet1/2) * tan(bet2/2)) formula for
+			// short lines.
+			domg12 := 1 + comg12
+			dbet1 := 1 + cbet1
+			dbet2 := 1 + cbet2
+			alp12 = 2 * math.Atan2(somg12*(sbet1*dbet2+sbet2*dbet1), domg12*(sbet1*sbet2+dbet1*dbet2))
+		} else {
+			salp12 := salp2*calp1 - calp2*salp1
+			calp12 := calp2*calp1 + salp2*salp1
+			if salp12 == 0 && calp12 < 0 {
+				salp12 = tiny * calp1
+				calp12 = -1
+			}
+			alp12 = math.Atan2(salp12, calp12)
+		}
+		s12Area += e.c2 * alp12
+		s12Area *= swapp * lonsign * latsign
+		s12Area += 0
+	}
+
+	if swapp < 0 {
+		salp1, salp2 = salp2, salp1
+		calp1, calp2 = calp2, calp1
+	}
+	salp1 *= swapp * lonsign
+	calp1 *= swapp * latsign
+	salp2 *= swapp * lonsign
+	calp2 *= swapp * latsign
+	return s12, salp1, calp1, salp2, calp2, s12Area
+}
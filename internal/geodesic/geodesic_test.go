@@ -0,0 +1,96 @@
+package geodesic
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInverse(t *testing.T) {
+	// Reference values from GeographicLib.
+	cases := []struct {
+		lat1, lon1, lat2, lon2 float64
+		s12, azi1, azi2        float64
+	}{
+		{40.6, -73.8, 51.6, -0.5, 5551759.400319, 51.198882845580, 107.821776735514},
+		{-41.32, 174.81, 40.96, -5.50, 19959679.267353, 161.067669986151, 18.825195123256},
+		{10, 20, 10, 20, 0, 180, 180},
+		{-90, 0, 90, 0, 20003931.458625, 0, 0},
+		// Nearly antipodal points on the equator, for which the shortest
+		// path leaves the equator.
+		{0, 0, 0.5, 179.5, 19936288.578965, 25.671872868279, 154.327085469954},
+	}
+	for i, c := range cases {
+		s12, azi1, azi2 := WGS84.Inverse(c.lat1, c.lon1, c.lat2, c.lon2)
+		if math.Abs(s12-c.s12) > 1e-6 || math.Abs(azi1-c.azi1) > 1e-9 || math.Abs(azi2-c.azi2) > 1e-9 {
+			t.Errorf("case %d: expected (%v, %v, %v), got (%v, %v, %v)", i, c.s12, c.azi1, c.azi2, s12, azi1, azi2)
+		}
+	}
+}
+
+func TestDirect(t *testing.T) {
+	cases := []struct {
+		lat1, lon1, azi1, s12 float64
+		lat2, lon2, azi2      float64
+	}{
+		{-32.06, 115.74, 225, 20000e3, 32.11195529, -63.95925278, -45.03243531},
+		{40.6, -73.8, 51.198882845580, 5551759.400319, 51.6, -0.5, 107.821776735514},
+	}
+	for i, c := range cases {
+		lat2, lon2, azi2 := WGS84.Direct(c.lat1, c.lon1, c.azi1, c.s12)
+		if math.Abs(lat2-c.lat2) > 1e-8 || math.Abs(lon2-c.lon2) > 1e-8 || math.Abs(azi2-c.azi2) > 1e-5 {
+			t.Errorf("case %d: expected (%v, %v, %v), got (%v, %v, %v)", i, c.lat2, c.lon2, c.azi2, lat2, lon2, azi2)
+		}
+	}
+}
+
+func TestRing(t *testing.T) {
+	// A quadrilateral that crosses the antimeridian, wound both ways.
+	lats := []float64{-10, -10, 20, 30}
+	lons := []float64{170, -160, -165, 175}
+	perimeter, area := WGS84.Ring(lats, lons)
+	rlats := []float64{30, 20, -10, -10}
+	rlons := []float64{175, -165, -160, 170}
+	rperimeter, rarea := WGS84.Ring(rlats, rlons)
+	if area <= 0 || math.Abs(area+rarea) > 1 || math.Abs(perimeter-rperimeter) > 1e-6 {
+		t.Errorf("expected opposite areas and equal perimeters, got (%v, %v) and (%v, %v)", perimeter, area, rperimeter, rarea)
+	}
+
+	// A ring around the north pole at 60 degrees north encloses nearly all
+	// of the polar cap, which has an area of 2 pi R^2 (1 - sin(60)) on a
+	// sphere.
+	s := Sphere{R: 6371008.8}
+	lats, lons = nil, nil
+	for lon := -180.0; lon < 180; lon++ {
+		lats = append(lats, 60)
+		lons = append(lons, lon)
+	}
+	_, area = s.Ring(lats, lons)
+	// Great circles between the vertices bulge poleward, so the area is
+	// slightly less than that of the cap.
+	cap := 2 * math.Pi * s.R * s.R * (1 - math.Sin(math.Pi/3))
+	if area <= 0.999*cap || area >= cap {
+		t.Errorf("expected area a little under %v, got %v", cap, area)
+	}
+	_, earea := WGS84.Ring(lats, lons)
+	if math.Abs(earea-area)/area > 1e-2 {
+		t.Errorf("expected ellipsoidal area near %v, got %v", area, earea)
+	}
+}
+
+func TestInverse_Antipodal(t *testing.T) {
+	// Nearly antipodal points whose starting guess comes from the astroid
+	// equation. Solving the direct problem must recover the second point.
+	cases := [][4]float64{
+		{0, 0, 0.2, 179.8},
+		{0, 0, 0.5, 179.7},
+		{-30, 0, 30.3, 179.6},
+		{0.1, 0, -0.4, -179.9},
+	}
+	for i, c := range cases {
+		s12, azi1, _ := WGS84.Inverse(c[0], c[1], c[2], c[3])
+		lat2, lon2, _ := WGS84.Direct(c[0], c[1], azi1, s12)
+		if math.IsNaN(s12) || math.Abs(lat2-c[2]) > 1e-8 || math.Abs(AngNormalize(lon2-c[3])) > 1e-8 {
+			t.Errorf("case %d: expected (%v, %v), got (%v, %v) for distance %v", i, c[2], c[3], lat2, lon2, s12)
+		}
+	}
+}
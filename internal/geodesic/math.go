@@ -0,0 +1,173 @@
+package geodesic
+
+import "math"
+
+// The helpers below are careful about exact multiples of 90 degrees and
+// signed zeros so that results along meridians and the equator are exact.
+
+func sq(x float64) float64 {
+	return x * x
+}
+
+// polyval evaluates the polynomial of degree n whose coefficients start at
+// p[s], highest degree first.
+func polyval(n int, p []float64, s int, x float64) float64 {
+	if n < 0 {
+		return 0
+	}
+	y := p[s]
+	for ; n > 0; n-- {
+		s++
+		y = y*x + p[s]
+	}
+	return y
+}
+
+// sum returns the sum of u and v and the rounding error of that sum.
+func sum(u, v float64) (float64, float64) {
+	s := u + v
+	up := s - v
+	vpp := s - up
+	up -= u
+	vpp -= v
+	return s, -(up + vpp)
+}
+
+// angRound rounds tiny angles so that they do not cause underflow.
+func angRound(x float64) float64 {
+	const z = 1.0 / 16
+	y := math.Abs(x)
+	if y < z {
+		y = z - (z - y)
+	}
+	if x == 0 {
+		return 0
+	}
+	if x < 0 {
+		return -y
+	}
+	return y
+}
+
+// remainder returns x reduced to [-y/2, y/2).
+func remainder(x, y float64) float64 {
+	z := math.Mod(x, y)
+	if z < -y/2 {
+		z += y
+	} else if z >= y/2 {
+		z -= y
+	}
+	return z
+}
+
+// angNormalize reduces an angle in degrees to (-180, 180].
+func angNormalize(x float64) float64 {
+	y := remainder(x, 360)
+	if y == -180 {
+		return 180
+	}
+	return y
+}
+
+// AngNormalize reduces an angle in degrees to (-180, 180].
+func AngNormalize(x float64) float64 {
+	return angNormalize(x)
+}
+
+// angDiff returns y - x reduced to [-180, 180] along with its rounding
+// error.
+func angDiff(x, y float64) (float64, float64) {
+	d, t := sum(remainder(-x, 360), remainder(y, 360))
+	d, t = sum(remainder(d, 360), t)
+	if d == 0 || math.Abs(d) == 180 {
+		if t == 0 {
+			d = math.Copysign(d, y-x)
+		} else {
+			d = math.Copysign(d, -t)
+		}
+	}
+	return d, t
+}
+
+// sincosd returns the sine and cosine of x degrees, exact for multiples of
+// 90 degrees.
+func sincosd(x float64) (float64, float64) {
+	r := math.Mod(x, 360)
+	q := 0
+	if !math.IsNaN(r) {
+		q = int(math.Round(r / 90))
+	}
+	r = (r - 90*float64(q)) * math.Pi / 180
+	s, c := math.Sin(r), math.Cos(r)
+	switch q & 3 {
+	case 1:
+		s, c = c, -s
+	case 2:
+		s, c = -s, -c
+	case 3:
+		s, c = -c, s
+	}
+	c += 0
+	if s == 0 {
+		s = math.Copysign(s, x)
+	}
+	return s, c
+}
+
+// atan2d returns atan2(y, x) in degrees, exact for multiples of 90 degrees.
+func atan2d(y, x float64) float64 {
+	q := 0
+	if math.Abs(y) > math.Abs(x) {
+		q = 2
+		x, y = y, x
+	}
+	if x < 0 {
+		q++
+		x = -x
+	}
+	ang := math.Atan2(y, x) * 180 / math.Pi
+	switch q {
+	case 1:
+		ang = math.Copysign(180, y) - ang
+	case 2:
+		ang = 90 - ang
+	case 3:
+		ang = -90 + ang
+	}
+	return ang
+}
+
+func norm(x, y float64) (float64, float64) {
+	r := math.Hypot(x, y)
+	return x / r, y / r
+}
+
+// An accumulator sums values with twice the precision of float64.
+type accumulator struct {
+	s, t float64
+}
+
+func (a *accumulator) add(y float64) {
+	var u float64
+	y, u = sum(y, a.t)
+	a.s, a.t = sum(y, a.s)
+	if a.s == 0 {
+		a.s = u
+	} else {
+		a.t += u
+	}
+}
+
+func (a *accumulator) sum() float64 {
+	return a.s
+}
+
+func (a *accumulator) negate() {
+	a.s = -a.s
+	a.t = -a.t
+}
+
+func (a *accumulator) remainder(y float64) {
+	a.s = remainder(a.s, y)
+	a.add(0)
+}
@@ -0,0 +1,123 @@
+package geodesic
+
+import "math"
+
+// transit counts the crossings of the prime meridian, in the direction of
+// increasing longitude, by the edge from lon1 to lon2.
+func transit(lon1, lon2 float64) int {
+	lon12, _ := angDiff(lon1, lon2)
+	lon1 = angNormalize(lon1)
+	lon2 = angNormalize(lon2)
+	switch {
+	case lon12 > 0 && ((lon1 < 0 && lon2 >= 0) || (lon1 > 0 && lon2 == 0)):
+		return 1
+	case lon12 < 0 && lon1 >= 0 && lon2 < 0:
+		return -1
+	}
+	return 0
+}
+
+// reduceArea reduces the sum of the signed areas between each edge of a
+// ring and the equator to the area enclosed by the ring, which is positive
+// if the ring is counter-clockwise and lies in (-total/2, total/2].
+func reduceArea(area *accumulator, total float64, crossings int) float64 {
+	area.remainder(total)
+	// An odd number of crossings means the ring encircles a pole.
+	if crossings&1 != 0 {
+		if area.sum() < 0 {
+			area.add(total / 2)
+		} else {
+			area.add(-total / 2)
+		}
+	}
+	area.negate()
+	if area.sum() > total/2 {
+		area.add(-total)
+	} else if area.sum() <= -total/2 {
+		area.add(total)
+	}
+	return 0 + area.sum()
+}
+
+// Ring returns the perimeter and signed area of the ring with the given
+// vertices, which are joined by geodesics. The ring is closed implicitly,
+// so repeating the first vertex at the end has no effect. The area is
+// positive for counter-clockwise rings.
+func (e *Ellipsoid) Ring(lats, lons []float64) (perimeter, area float64) {
+	if len(lats) < 2 {
+		return 0, 0
+	}
+	var p, a accumulator
+	crossings := 0
+	n := len(lats)
+	for i := 0; i < n; i++ {
+		j := (i + 1) % n
+		s12, _, _, _, _, s12Area := e.inverse(lats[i], lons[i], lats[j], lons[j], true)
+		p.add(s12)
+		a.add(s12Area)
+		crossings += transit(lons[i], lons[j])
+	}
+	return p.sum(), reduceArea(&a, e.Area(), crossings)
+}
+
+// Line returns the length of the line with the given vertices, which are
+// joined by geodesics.
+func (e *Ellipsoid) Line(lats, lons []float64) float64 {
+	var p accumulator
+	for i := 1; i < len(lats); i++ {
+		s12, _, _ := e.Inverse(lats[i-1], lons[i-1], lats[i], lons[i])
+		p.add(s12)
+	}
+	return p.sum()
+}
+
+// A Sphere measures great circles on a sphere, which is faster but less
+// accurate than measuring geodesics on an Ellipsoid.
+type Sphere struct {
+	R float64
+}
+
+// Distance returns the great-circle distance between two points using the
+// haversine formula.
+func (s Sphere) Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dphi := phi2 - phi1
+	dlam := (lon2 - lon1) * math.Pi / 180
+	h := sq(math.Sin(dphi/2)) + math.Cos(phi1)*math.Cos(phi2)*sq(math.Sin(dlam/2))
+	return 2 * s.R * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Ring returns the perimeter and signed area of the ring with the given
+// vertices, which are joined by great circles, as with Ellipsoid.Ring.
+func (s Sphere) Ring(lats, lons []float64) (perimeter, area float64) {
+	if len(lats) < 2 {
+		return 0, 0
+	}
+	var p, a accumulator
+	crossings := 0
+	n := len(lats)
+	for i := 0; i < n; i++ {
+		j := (i + 1) % n
+		p.add(s.Distance(lats[i], lons[i], lats[j], lons[j]))
+		// The area between the edge and the equator, with the same sign
+		// convention as Ellipsoid.inverse.
+		dlam, _ := angDiff(lons[i], lons[j])
+		t1 := math.Tan(lats[i] * math.Pi / 360)
+		t2 := math.Tan(lats[j] * math.Pi / 360)
+		excess := 2 * math.Atan2(math.Tan(dlam*math.Pi/360)*(t1+t2), 1+t1*t2)
+		a.add(sq(s.R) * excess)
+		crossings += transit(lons[i], lons[j])
+	}
+	return p.sum(), reduceArea(&a, 4*math.Pi*sq(s.R), crossings)
+}
+
+// Line returns the length of the line with the given vertices, which are
+// joined by great circles.
+func (s Sphere) Line(lats, lons []float64) float64 {
+	var p accumulator
+	for i := 1; i < len(lats); i++ {
+		p.add(s.Distance(lats[i-1], lons[i-1], lats[i], lons[i]))
+	}
+	return p.sum()
+}
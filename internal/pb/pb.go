@@ -0,0 +1,384 @@
+// Package pb implements the Protocol Buffers wire format for the binary
+// formats in this module that are defined by .proto schemas. It deals only in
+// field numbers and wire types; each format maps its own messages onto it.
+package pb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Wire types.
+const (
+	Varint  = 0
+	Fixed64 = 1
+	Bytes   = 2
+	Fixed32 = 5
+)
+
+// EncodeZigZag maps signed integers to unsigned integers so that values of
+// small magnitude have small encodings.
+func EncodeZigZag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+// DecodeZigZag inverts EncodeZigZag.
+func DecodeZigZag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// An Encoder appends fields to a message.
+type Encoder struct {
+	buf []byte
+}
+
+// Bytes returns the encoded message.
+func (e *Encoder) Bytes() []byte {
+	return e.buf
+}
+
+// Len returns the length of the encoded message.
+func (e *Encoder) Len() int {
+	return len(e.buf)
+}
+
+func (e *Encoder) tag(field, wireType int) {
+	e.buf = AppendVarint(e.buf, uint64(field)<<3|uint64(wireType))
+}
+
+// Uint64 writes a varint field.
+func (e *Encoder) Uint64(field int, v uint64) {
+	e.tag(field, Varint)
+	e.buf = AppendVarint(e.buf, v)
+}
+
+// Int64 writes a varint field holding a two's complement value.
+func (e *Encoder) Int64(field int, v int64) {
+	e.Uint64(field, uint64(v))
+}
+
+// Sint64 writes a zigzag-encoded varint field.
+func (e *Encoder) Sint64(field int, v int64) {
+	e.Uint64(field, EncodeZigZag(v))
+}
+
+// Bool writes a varint field holding 0 or 1.
+func (e *Encoder) Bool(field int, v bool) {
+	if v {
+		e.Uint64(field, 1)
+	} else {
+		e.Uint64(field, 0)
+	}
+}
+
+// Double writes a fixed 64-bit floating point field.
+func (e *Encoder) Double(field int, v float64) {
+	e.tag(field, Fixed64)
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+	e.buf = append(e.buf, b[:]...)
+}
+
+// Float writes a fixed 32-bit floating point field.
+func (e *Encoder) Float(field int, v float32) {
+	e.tag(field, Fixed32)
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], math.Float32bits(v))
+	e.buf = append(e.buf, b[:]...)
+}
+
+// Embedded writes a length-delimited field, such as an embedded message or
+// raw bytes.
+func (e *Encoder) Embedded(field int, b []byte) {
+	e.tag(field, Bytes)
+	e.buf = AppendVarint(e.buf, uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// String writes a length-delimited string field.
+func (e *Encoder) String(field int, s string) {
+	e.tag(field, Bytes)
+	e.buf = AppendVarint(e.buf, uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+// PackedUint64 writes a packed repeated varint field. Nothing is written for
+// an empty slice.
+func (e *Encoder) PackedUint64(field int, values []uint64) {
+	if len(values) == 0 {
+		return
+	}
+	var b []byte
+	for _, v := range values {
+		b = AppendVarint(b, v)
+	}
+	e.Embedded(field, b)
+}
+
+// PackedUint32 writes a packed repeated varint field.
+func (e *Encoder) PackedUint32(field int, values []uint32) {
+	if len(values) == 0 {
+		return
+	}
+	var b []byte
+	for _, v := range values {
+		b = AppendVarint(b, uint64(v))
+	}
+	e.Embedded(field, b)
+}
+
+// PackedSint64 writes a packed repeated zigzag varint field.
+func (e *Encoder) PackedSint64(field int, values []int64) {
+	if len(values) == 0 {
+		return
+	}
+	var b []byte
+	for _, v := range values {
+		b = AppendVarint(b, EncodeZigZag(v))
+	}
+	e.Embedded(field, b)
+}
+
+// AppendVarint appends the varint encoding of v to b.
+func AppendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+// A Decoder iterates over the fields of a message. Malformed input stops
+// iteration and is reported by Err; value accessors then return zero values.
+type Decoder struct {
+	buf      []byte
+	pos      int
+	field    int
+	wireType int
+	// Whether the current field's value has been read.
+	consumed bool
+	err      error
+}
+
+// NewDecoder returns a Decoder over the message b.
+func NewDecoder(b []byte) *Decoder {
+	return &Decoder{buf: b, consumed: true}
+}
+
+// Next advances to the next field. It returns false at the end of the
+// message or on error. Unread field values are skipped automatically.
+func (d *Decoder) Next() bool {
+	if d.err != nil {
+		return false
+	}
+	if !d.consumed {
+		d.Skip()
+		if d.err != nil {
+			return false
+		}
+	}
+	if d.pos >= len(d.buf) {
+		return false
+	}
+	tag := d.varint()
+	if d.err != nil {
+		return false
+	}
+	d.field = int(tag >> 3)
+	d.wireType = int(tag & 7)
+	if d.field == 0 {
+		d.err = fmt.Errorf("pb: invalid field number 0")
+		return false
+	}
+	d.consumed = false
+	return true
+}
+
+// Field returns the number of the current field.
+func (d *Decoder) Field() int {
+	return d.field
+}
+
+// WireType returns the wire type of the current field.
+func (d *Decoder) WireType() int {
+	return d.wireType
+}
+
+// Err returns the first error encountered.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+func (d *Decoder) varint() uint64 {
+	var v uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		if d.pos >= len(d.buf) {
+			d.err = fmt.Errorf("pb: truncated varint")
+			return 0
+		}
+		c := d.buf[d.pos]
+		d.pos++
+		v |= uint64(c&0x7f) << shift
+		if c < 0x80 {
+			return v
+		}
+	}
+	d.err = fmt.Errorf("pb: varint overflow")
+	return 0
+}
+
+func (d *Decoder) take(n int) []byte {
+	if n < 0 || d.pos+n > len(d.buf) || d.pos+n < d.pos {
+		d.err = fmt.Errorf("pb: truncated field %d", d.field)
+		return nil
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+// expect verifies the current field's wire type before reading its value.
+func (d *Decoder) expect(wireType int) bool {
+	if d.err != nil {
+		return false
+	}
+	if d.consumed {
+		d.err = fmt.Errorf("pb: field %d read twice", d.field)
+		return false
+	}
+	if d.wireType != wireType {
+		d.err = fmt.Errorf("pb: field %d has wire type %d, expected %d", d.field, d.wireType, wireType)
+		return false
+	}
+	d.consumed = true
+	return true
+}
+
+// Uint64 reads a varint field.
+func (d *Decoder) Uint64() uint64 {
+	if !d.expect(Varint) {
+		return 0
+	}
+	return d.varint()
+}
+
+// Int64 reads a varint field holding a two's complement value.
+func (d *Decoder) Int64() int64 {
+	return int64(d.Uint64())
+}
+
+// Sint64 reads a zigzag-encoded varint field.
+func (d *Decoder) Sint64() int64 {
+	return DecodeZigZag(d.Uint64())
+}
+
+// Bool reads a varint field as a boolean.
+func (d *Decoder) Bool() bool {
+	return d.Uint64() != 0
+}
+
+// Double reads a fixed 64-bit floating point field.
+func (d *Decoder) Double() float64 {
+	if !d.expect(Fixed64) {
+		return 0
+	}
+	b := d.take(8)
+	if b == nil {
+		return 0
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
+
+// Float reads a fixed 32-bit floating point field.
+func (d *Decoder) Float() float32 {
+	if !d.expect(Fixed32) {
+		return 0
+	}
+	b := d.take(4)
+	if b == nil {
+		return 0
+	}
+	return math.Float32frombits(binary.LittleEndian.Uint32(b))
+}
+
+// Embedded reads a length-delimited field. The result aliases the message
+// buffer.
+func (d *Decoder) Embedded() []byte {
+	if !d.expect(Bytes) {
+		return nil
+	}
+	n := d.varint()
+	if d.err != nil {
+		return nil
+	}
+	if n > uint64(len(d.buf)) {
+		d.err = fmt.Errorf("pb: truncated field %d", d.field)
+		return nil
+	}
+	return d.take(int(n))
+}
+
+// String reads a length-delimited field as a string.
+func (d *Decoder) String() string {
+	return string(d.Embedded())
+}
+
+// Uint64s reads a repeated varint field, accepting both packed and unpacked
+// encodings, and appends its values to dst.
+func (d *Decoder) Uint64s(dst []uint64) []uint64 {
+	if d.wireType == Varint {
+		v := d.Uint64()
+		if d.err != nil {
+			return dst
+		}
+		return append(dst, v)
+	}
+	b := d.Embedded()
+	inner := &Decoder{buf: b}
+	for inner.pos < len(inner.buf) {
+		v := inner.varint()
+		if inner.err != nil {
+			d.err = inner.err
+			return dst
+		}
+		dst = append(dst, v)
+	}
+	return dst
+}
+
+// Sint64s reads a repeated zigzag varint field, accepting both packed and
+// unpacked encodings, and appends its values to dst.
+func (d *Decoder) Sint64s(dst []int64) []int64 {
+	for _, v := range d.Uint64s(nil) {
+		dst = append(dst, DecodeZigZag(v))
+	}
+	return dst
+}
+
+// Skip discards the current field's value.
+func (d *Decoder) Skip() {
+	if d.err != nil || d.consumed {
+		return
+	}
+	d.consumed = true
+	switch d.wireType {
+	case Varint:
+		d.varint()
+	case Fixed64:
+		d.take(8)
+	case Bytes:
+		n := d.varint()
+		if d.err == nil {
+			if n > uint64(len(d.buf)) {
+				d.err = fmt.Errorf("pb: truncated field %d", d.field)
+				return
+			}
+			d.take(int(n))
+		}
+	case Fixed32:
+		d.take(4)
+	default:
+		d.err = fmt.Errorf("pb: unsupported wire type %d", d.wireType)
+	}
+}
@@ -0,0 +1,92 @@
+package pb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	var inner Encoder
+	inner.String(1, "hello")
+
+	var e Encoder
+	e.Uint64(1, 300)
+	e.Int64(2, -1)
+	e.Sint64(3, -2)
+	e.Bool(4, true)
+	e.Double(5, 1.5)
+	e.Float(6, -0.25)
+	e.Embedded(7, inner.Bytes())
+	e.PackedUint64(8, []uint64{1, 2, 1 << 40})
+	e.Uint64(8, 7)
+	e.PackedSint64(9, []int64{-1, 1, -300})
+	e.Uint64(99, 5)
+
+	d := NewDecoder(e.Bytes())
+	var (
+		u       uint64
+		i, s    int64
+		b       bool
+		f64     float64
+		f32     float32
+		str     string
+		packed  []uint64
+		spacked []int64
+	)
+	for d.Next() {
+		switch d.Field() {
+		case 1:
+			u = d.Uint64()
+		case 2:
+			i = d.Int64()
+		case 3:
+			s = d.Sint64()
+		case 4:
+			b = d.Bool()
+		case 5:
+			f64 = d.Double()
+		case 6:
+			f32 = d.Float()
+		case 7:
+			id := NewDecoder(d.Embedded())
+			for id.Next() {
+				str = id.String()
+			}
+		case 8:
+			packed = d.Uint64s(packed)
+		case 9:
+			spacked = d.Sint64s(spacked)
+		}
+	}
+	if d.Err() != nil {
+		t.Fatalf("decode failed: %v", d.Err())
+	}
+	if u != 300 || i != -1 || s != -2 || !b || f64 != 1.5 || f32 != -0.25 || str != "hello" {
+		t.Errorf("unexpected scalars: %v %v %v %v %v %v %q", u, i, s, b, f64, f32, str)
+	}
+	if !reflect.DeepEqual(packed, []uint64{1, 2, 1 << 40, 7}) {
+		t.Errorf("unexpected packed values: %v", packed)
+	}
+	if !reflect.DeepEqual(spacked, []int64{-1, 1, -300}) {
+		t.Errorf("unexpected packed signed values: %v", spacked)
+	}
+}
+
+func TestDecoder_Invalid(t *testing.T) {
+	cases := [][]byte{
+		{0x08},
+		{0x08, 0x80},
+		{0x12, 0x05, 'a'},
+		{0x00, 0x01},
+		{0x0b},
+		{0x09, 0x01, 0x02},
+	}
+	for i, c := range cases {
+		d := NewDecoder(c)
+		for d.Next() {
+		}
+		if d.Err() == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
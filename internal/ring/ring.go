@@ -0,0 +1,206 @@
+// Package ring groups loose rings into polygons for formats that leave it to
+// readers to do so. Most such formats wind outer rings clockwise and holes
+// counter-clockwise, the opposite of RFC 7946; others give no winding at all
+// and rings must be grouped by containment alone.
+package ring
+
+import (
+	"fmt"
+
+	"github.com/bsidhom/geojson"
+)
+
+// Assemble groups rings into polygons. Clockwise rings start new polygons
+// and counter-clockwise rings are holes belonging to the smallest outer ring
+// that contains them, so that a hole in an island in a lake belongs to the
+// island. Ring orientation is then reversed to match RFC 7946. A single
+// polygon is returned as a Polygon; several are returned as a MultiPolygon.
+func Assemble(parts [][]geojson.Point) (geojson.Geometry, error) {
+	var outers []geojson.Polygon
+	var holes [][]geojson.Point
+	for i, part := range parts {
+		ring := closeRing(part)
+		if len(ring) < 4 {
+			return nil, fmt.Errorf("ring %d requires at least 4 points, got %d", i, len(ring))
+		}
+		if SignedArea(ring) > 0 {
+			holes = append(holes, ring)
+			continue
+		}
+		outers = append(outers, geojson.Polygon{
+			Rings: []geojson.LineString{{Points: reversed(ring)}},
+		})
+	}
+
+	for _, hole := range holes {
+		owner := -1
+		var area float64
+		for i := range outers {
+			outer := outers[i].Rings[0].Points
+			if a := SignedArea(outer); ringContains(outer, hole) && (owner < 0 || a < area) {
+				owner, area = i, a
+			}
+		}
+		if owner < 0 {
+			// Some writers ignore the winding rules. A hole that lies
+			// outside of every outer ring is most likely an outer ring
+			// itself, so keep its (already counter-clockwise) winding.
+			outers = append(outers, geojson.Polygon{
+				Rings: []geojson.LineString{{Points: hole}},
+			})
+			continue
+		}
+		outers[owner].Rings = append(outers[owner].Rings, geojson.LineString{Points: reversed(hole)})
+	}
+
+	if len(outers) == 1 {
+		return &outers[0], nil
+	}
+	return &geojson.MultiPolygon{Polygons: outers}, nil
+}
+
+// Nest groups closed rings into polygons by containment alone. Rings
+// inside an even number of other rings are outer rings, and every other
+// ring is a hole of the outer ring directly enclosing it. Rings are wound to
+// match RFC 7946 regardless of their input winding.
+func Nest(rings [][]geojson.Point) []geojson.Polygon {
+	depths := make([]int, len(rings))
+	for i := range rings {
+		for j := range rings {
+			if i != j && ringContains(rings[j], rings[i]) {
+				depths[i]++
+			}
+		}
+	}
+	var polygons []geojson.Polygon
+	owners := make([]int, len(rings))
+	for i, ring := range rings {
+		if depths[i]%2 != 0 {
+			continue
+		}
+		owners[i] = len(polygons)
+		polygons = append(polygons, geojson.Polygon{
+			Rings: []geojson.LineString{{Points: wound(ring, true)}},
+		})
+	}
+	for i, ring := range rings {
+		if depths[i]%2 == 0 {
+			continue
+		}
+		for j := range rings {
+			if depths[j] == depths[i]-1 && ringContains(rings[j], ring) {
+				p := &polygons[owners[j]]
+				p.Rings = append(p.Rings, geojson.LineString{Points: wound(ring, false)})
+				break
+			}
+		}
+	}
+	return polygons
+}
+
+// wound returns ring wound counter-clockwise if ccw is set, and clockwise
+// otherwise.
+func wound(ring []geojson.Point, ccw bool) []geojson.Point {
+	if (SignedArea(ring) > 0) != ccw {
+		return reversed(ring)
+	}
+	return ring
+}
+
+// Rewind returns a copy of p with its outer ring wound counter-clockwise and
+// its holes clockwise, as RFC 7946 requires, for formats that do not fix a
+// winding.
+func Rewind(p *geojson.Polygon) *geojson.Polygon {
+	r := &geojson.Polygon{Rings: make([]geojson.LineString, len(p.Rings))}
+	for i, ring := range p.Rings {
+		r.Rings[i] = geojson.LineString{Points: wound(ring.Points, i == 0)}
+	}
+	return r
+}
+
+// Flatten flattens polygons into a list of rings, winding outer rings
+// clockwise and holes counter-clockwise regardless of their input winding.
+func Flatten(polygons []geojson.Polygon) [][]geojson.Point {
+	var parts [][]geojson.Point
+	for _, p := range polygons {
+		for i, ring := range p.Rings {
+			points := ring.Points
+			clockwise := SignedArea(points) < 0
+			if (i == 0) != clockwise {
+				points = reversed(points)
+			}
+			parts = append(parts, points)
+		}
+	}
+	return parts
+}
+
+// closeRing returns ring with its first point appended if it is not already
+// closed.
+func closeRing(ring []geojson.Point) []geojson.Point {
+	if len(ring) > 0 && ring[0] != ring[len(ring)-1] {
+		return append(ring[:len(ring):len(ring)], ring[0])
+	}
+	return ring
+}
+
+// SignedArea returns twice the planar signed area of ring, which is positive
+// for counter-clockwise rings.
+func SignedArea(ring []geojson.Point) float64 {
+	var sum float64
+	for i := 0; i+1 < len(ring); i++ {
+		sum += ring[i].X*ring[i+1].Y - ring[i+1].X*ring[i].Y
+	}
+	return sum
+}
+
+// ringContains reports whether inner lies inside outer. Since valid rings do
+// not cross, it is enough to test a single vertex of inner that is not on
+// outer's boundary.
+func ringContains(outer, inner []geojson.Point) bool {
+	for _, p := range inner {
+		inside, onBoundary := pointInRing(p, outer)
+		if !onBoundary {
+			return inside
+		}
+	}
+	return false
+}
+
+// pointInRing tests p against ring using the even-odd rule.
+func pointInRing(p geojson.Point, ring []geojson.Point) (inside, onBoundary bool) {
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[j], ring[i]
+		cross := (b.X-a.X)*(p.Y-a.Y) - (b.Y-a.Y)*(p.X-a.X)
+		if cross == 0 && p.X >= minFloat(a.X, b.X) && p.X <= maxFloat(a.X, b.X) &&
+			p.Y >= minFloat(a.Y, b.Y) && p.Y <= maxFloat(a.Y, b.Y) {
+			return false, true
+		}
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside, false
+}
+
+func reversed(points []geojson.Point) []geojson.Point {
+	r := make([]geojson.Point, len(points))
+	for i, p := range points {
+		r[len(points)-1-i] = p
+	}
+	return r
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
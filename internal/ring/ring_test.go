@@ -0,0 +1,120 @@
+package ring
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+// cw returns a clockwise square ring, as Esri and shapefile outer rings are
+// wound.
+func cw(x0, y0, x1, y1 float64) []geojson.Point {
+	return []geojson.Point{{X: x0, Y: y0}, {X: x0, Y: y1}, {X: x1, Y: y1}, {X: x1, Y: y0}, {X: x0, Y: y0}}
+}
+
+// ccw returns a counter-clockwise square ring.
+func ccw(x0, y0, x1, y1 float64) []geojson.Point {
+	return []geojson.Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}, {X: x0, Y: y0}}
+}
+
+func polygon(rings ...[]geojson.Point) geojson.Polygon {
+	p := geojson.Polygon{}
+	for _, r := range rings {
+		p.Rings = append(p.Rings, geojson.LineString{Points: r})
+	}
+	return p
+}
+
+func TestAssemble(t *testing.T) {
+	single := polygon(ccw(0, 0, 10, 10))
+	holed := polygon(ccw(0, 0, 10, 10), cw(2, 2, 8, 8))
+	cases := []struct {
+		parts    [][]geojson.Point
+		expected geojson.Geometry
+	}{
+		{[][]geojson.Point{cw(0, 0, 10, 10)}, &single},
+		// Unclosed rings are closed.
+		{[][]geojson.Point{cw(0, 0, 10, 10)[:4]}, &single},
+		{[][]geojson.Point{cw(0, 0, 10, 10), ccw(2, 2, 8, 8)}, &holed},
+		// Holes may come before their outer ring.
+		{[][]geojson.Point{ccw(2, 2, 8, 8), cw(0, 0, 10, 10)}, &holed},
+		{
+			[][]geojson.Point{cw(0, 0, 1, 1), cw(5, 5, 6, 6)},
+			&geojson.MultiPolygon{Polygons: []geojson.Polygon{polygon(ccw(0, 0, 1, 1)), polygon(ccw(5, 5, 6, 6))}},
+		},
+		// A pond on an island in a lake belongs to the island.
+		{
+			[][]geojson.Point{cw(0, 0, 10, 10), ccw(1, 1, 9, 9), cw(2, 2, 8, 8), ccw(3, 3, 7, 7)},
+			&geojson.MultiPolygon{Polygons: []geojson.Polygon{
+				polygon(ccw(0, 0, 10, 10), cw(1, 1, 9, 9)),
+				polygon(ccw(2, 2, 8, 8), cw(3, 3, 7, 7)),
+			}},
+		},
+		{
+			[][]geojson.Point{ccw(3, 3, 7, 7), cw(2, 2, 8, 8), ccw(1, 1, 9, 9), cw(0, 0, 10, 10)},
+			&geojson.MultiPolygon{Polygons: []geojson.Polygon{
+				polygon(ccw(2, 2, 8, 8), cw(3, 3, 7, 7)),
+				polygon(ccw(0, 0, 10, 10), cw(1, 1, 9, 9)),
+			}},
+		},
+		// A counter-clockwise ring outside every outer ring is an outer ring
+		// wound the wrong way.
+		{
+			[][]geojson.Point{cw(0, 0, 1, 1), ccw(5, 5, 6, 6)},
+			&geojson.MultiPolygon{Polygons: []geojson.Polygon{polygon(ccw(0, 0, 1, 1)), polygon(ccw(5, 5, 6, 6))}},
+		},
+	}
+	for i, c := range cases {
+		g, err := Assemble(c.parts)
+		if err != nil {
+			t.Errorf("case %d: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(g, c.expected) {
+			t.Errorf("case %d: expected %v, got %v", i, c.expected, g)
+		}
+	}
+
+	if _, err := Assemble([][]geojson.Point{{{X: 0, Y: 0}, {X: 1, Y: 1}}}); err == nil {
+		t.Error("expected an error for a ring with too few points")
+	}
+}
+
+func TestNest(t *testing.T) {
+	// Windings are ignored.
+	rings := [][]geojson.Point{ccw(3, 3, 7, 7), ccw(0, 0, 10, 10), cw(2, 2, 8, 8), ccw(1, 1, 9, 9), cw(20, 20, 21, 21)}
+	expected := []geojson.Polygon{
+		polygon(ccw(0, 0, 10, 10), cw(1, 1, 9, 9)),
+		polygon(ccw(2, 2, 8, 8), cw(3, 3, 7, 7)),
+		polygon(ccw(20, 20, 21, 21)),
+	}
+	if polygons := Nest(rings); !reflect.DeepEqual(polygons, expected) {
+		t.Errorf("expected %v, got %v", expected, polygons)
+	}
+}
+
+func TestRewind(t *testing.T) {
+	p := polygon(cw(0, 0, 10, 10), ccw(2, 2, 4, 4), cw(6, 6, 8, 8))
+	expected := polygon(ccw(0, 0, 10, 10), cw(2, 2, 4, 4), cw(6, 6, 8, 8))
+	if r := Rewind(&p); !reflect.DeepEqual(*r, expected) {
+		t.Errorf("expected %v, got %v", expected, *r)
+	}
+	if !reflect.DeepEqual(p.Rings[0].Points, cw(0, 0, 10, 10)) {
+		t.Errorf("expected the input to be unchanged, got %v", p)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	polygons := []geojson.Polygon{
+		polygon(ccw(0, 0, 10, 10), ccw(2, 2, 8, 8)),
+		polygon(cw(20, 20, 21, 21)),
+	}
+	expected := [][]geojson.Point{cw(0, 0, 10, 10), ccw(2, 2, 8, 8), cw(20, 20, 21, 21)}
+	if parts := Flatten(polygons); !reflect.DeepEqual(parts, expected) {
+		t.Errorf("expected %v, got %v", expected, parts)
+	}
+	if SignedArea(ccw(0, 0, 2, 3)) != 12 || SignedArea(cw(0, 0, 2, 3)) != -12 {
+		t.Errorf("expected signed areas of 12 and -12, got %v and %v", SignedArea(ccw(0, 0, 2, 3)), SignedArea(cw(0, 0, 2, 3)))
+	}
+}
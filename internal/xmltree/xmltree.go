@@ -0,0 +1,65 @@
+// Package xmltree decodes XML elements into generic trees. Formats such as
+// KML and GML allow elements to appear in many orders and at many depths, so
+// it is simpler to decode into a tree and walk it than to enumerate every
+// possible layout with struct tags.
+package xmltree
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// A Node is a generic XML element.
+type Node struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",chardata"`
+	Nodes   []Node     `xml:",any"`
+}
+
+// Child returns the first direct child with the given local name, or nil if
+// there is none.
+func (n *Node) Child(name string) *Node {
+	for i := range n.Nodes {
+		if n.Nodes[i].XMLName.Local == name {
+			return &n.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// Children returns all direct children with the given local name.
+func (n *Node) Children(name string) []*Node {
+	var result []*Node
+	for i := range n.Nodes {
+		if n.Nodes[i].XMLName.Local == name {
+			result = append(result, &n.Nodes[i])
+		}
+	}
+	return result
+}
+
+// Attr returns the value of the named attribute, ignoring its namespace.
+func (n *Node) Attr(name string) (string, bool) {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// Text returns the trimmed character data of n.
+func (n *Node) Text() string {
+	return strings.TrimSpace(n.Content)
+}
+
+// Element returns an element with the given children.
+func Element(name string, children ...Node) Node {
+	return Node{XMLName: xml.Name{Local: name}, Nodes: children}
+}
+
+// TextElement returns an element containing only character data.
+func TextElement(name, content string) Node {
+	return Node{XMLName: xml.Name{Local: name}, Content: content}
+}
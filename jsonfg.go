@@ -0,0 +1,633 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bsidhom/geojson/wire"
+)
+
+var _ Geometry = &Polyhedron{}
+var _ Geometry = &MultiPolyhedron{}
+var _ Geometry = &Prism{}
+var _ Geometry = &MultiPrism{}
+var _ Geometry = &CircularString{}
+var _ Geometry = &CompoundCurve{}
+var _ Geometry = &CurvePolygon{}
+var _ Geometry = &MultiCurve{}
+var _ Geometry = &MultiSurface{}
+
+// The geometry types below are defined by JSON-FG (OGC Features and
+// Geometries JSON). They may only be used as the Place of a Feature, which
+// is written as JSON-FG rather than plain GeoJSON.
+
+// A Polyhedron is a solid bounded by shells, each of which is a set of
+// polygonal faces. The first shell is the outer boundary and any others are
+// voids. All positions must have an elevation.
+type Polyhedron struct {
+	Shells [][]Polygon
+}
+
+func (*Polyhedron) isObject() {}
+
+func (*Polyhedron) isGeometry() {}
+
+// A MultiPolyhedron is a collection of Polyhedrons.
+type MultiPolyhedron struct {
+	Polyhedra []Polyhedron
+}
+
+func (*MultiPolyhedron) isObject() {}
+
+func (*MultiPolyhedron) isGeometry() {}
+
+// A Prism is a solid formed by extruding a two-dimensional base geometry
+// from the Lower to the Upper elevation.
+type Prism struct {
+	// Base may be a Point, LineString, Polygon, MultiPoint, MultiLineString,
+	// MultiPolygon, CircularString, CompoundCurve, CurvePolygon, MultiCurve,
+	// or MultiSurface.
+	Base  Geometry
+	Lower float64
+	Upper float64
+}
+
+func (*Prism) isObject() {}
+
+func (*Prism) isGeometry() {}
+
+// A MultiPrism is a collection of Prisms.
+type MultiPrism struct {
+	Prisms []Prism
+}
+
+func (*MultiPrism) isObject() {}
+
+func (*MultiPrism) isGeometry() {}
+
+// A CircularString is a sequence of circular arcs. Each arc is defined by
+// three points, the last of which starts the next arc, so there must be an
+// odd number of at least 3 points.
+type CircularString struct {
+	Points []Point
+}
+
+func (*CircularString) isObject() {}
+
+func (*CircularString) isGeometry() {}
+
+// A CompoundCurve is a contiguous path made of LineStrings and
+// CircularStrings, each starting where the previous one ends.
+type CompoundCurve struct {
+	Curves []Geometry
+}
+
+func (*CompoundCurve) isObject() {}
+
+func (*CompoundCurve) isGeometry() {}
+
+// A CurvePolygon is a polygon whose rings may be LineStrings,
+// CircularStrings, or CompoundCurves. As with Polygon, the first ring is
+// the exterior and the others are holes.
+type CurvePolygon struct {
+	Rings []Geometry
+}
+
+func (*CurvePolygon) isObject() {}
+
+func (*CurvePolygon) isGeometry() {}
+
+// A MultiCurve is a collection of LineStrings, CircularStrings, and
+// CompoundCurves.
+type MultiCurve struct {
+	Curves []Geometry
+}
+
+func (*MultiCurve) isObject() {}
+
+func (*MultiCurve) isGeometry() {}
+
+// A MultiSurface is a collection of Polygons and CurvePolygons.
+type MultiSurface struct {
+	Surfaces []Geometry
+}
+
+func (*MultiSurface) isObject() {}
+
+func (*MultiSurface) isGeometry() {}
+
+// A Time is the temporal extent of a JSON-FG feature: an instant, an
+// interval, or both.
+type Time struct {
+	// Instant at which the feature exists, or the zero time if it has none.
+	Instant time.Time
+	// Start and End bound the interval during which the feature exists if
+	// HasInterval is set. A zero time leaves that end unbounded.
+	Start       time.Time
+	End         time.Time
+	HasInterval bool
+	// Whether the values are calendar dates rather than timestamps. Dates
+	// are represented as midnight UTC. Documents that mix dates and
+	// timestamps are read as timestamps.
+	Dates bool
+}
+
+// A CoordRefSys identifies the coordinate reference system of the Place of
+// a feature.
+type CoordRefSys struct {
+	// URI or safe CURIE (such as "[EPSG:4326]") of the reference system.
+	Href string
+	// Coordinate epoch of a dynamic reference system as a decimal year, or 0
+	// if there is none.
+	Epoch float64
+	// Components of a compound reference system. If set, Href and Epoch are
+	// unused.
+	Components []CoordRefSys
+}
+
+// Formats of JSON-FG time values.
+const (
+	dateFormat = "2006-01-02"
+	unbounded  = ".."
+)
+
+func (t *Time) unmarshalFrom(w *wire.Time) error {
+	*t = Time{}
+	dates, timestamps := 0, 0
+	parseDate := func(s string) (time.Time, error) {
+		dates++
+		return time.Parse(dateFormat, s)
+	}
+	parseTimestamp := func(s string) (time.Time, error) {
+		timestamps++
+		return time.Parse(time.RFC3339Nano, s)
+	}
+	// Interval values may be either dates or timestamps.
+	parse := func(s string) (time.Time, error) {
+		if len(s) == len(dateFormat) {
+			return parseDate(s)
+		}
+		return parseTimestamp(s)
+	}
+	var err error
+	if w.Date != "" {
+		t.Instant, err = parseDate(w.Date)
+		if err != nil {
+			return fmt.Errorf("unmarshal Time: %v", err)
+		}
+	}
+	// A timestamp is more precise than a date, so it takes precedence.
+	if w.Timestamp != "" {
+		dates = 0
+		t.Instant, err = parseTimestamp(w.Timestamp)
+		if err != nil {
+			return fmt.Errorf("unmarshal Time: %v", err)
+		}
+	}
+	if w.Interval != nil {
+		if len(w.Interval) != 2 {
+			return fmt.Errorf("unmarshal Time: interval must have 2 values, got %d", len(w.Interval))
+		}
+		ends := []*time.Time{&t.Start, &t.End}
+		for i, s := range w.Interval {
+			if s == unbounded {
+				continue
+			}
+			*ends[i], err = parse(s)
+			if err != nil {
+				return fmt.Errorf("unmarshal Time: %v", err)
+			}
+		}
+		if !t.Start.IsZero() && !t.End.IsZero() && t.End.Before(t.Start) {
+			return fmt.Errorf("unmarshal Time: interval ends before it starts")
+		}
+		t.HasInterval = true
+	}
+	// Values are only kept as dates if there are no timestamps, in which case
+	// dates are treated as timestamps at midnight UTC.
+	t.Dates = dates > 0 && timestamps == 0
+	return nil
+}
+
+func (t *Time) toWire() *wire.Time {
+	format := func(v time.Time) string {
+		if t.Dates {
+			return v.UTC().Format(dateFormat)
+		}
+		return v.UTC().Format(time.RFC3339Nano)
+	}
+	w := &wire.Time{}
+	if !t.Instant.IsZero() {
+		if t.Dates {
+			w.Date = format(t.Instant)
+		} else {
+			w.Timestamp = format(t.Instant)
+		}
+	}
+	if t.HasInterval {
+		w.Interval = []string{unbounded, unbounded}
+		for i, v := range []time.Time{t.Start, t.End} {
+			if !v.IsZero() {
+				w.Interval[i] = format(v)
+			}
+		}
+	}
+	return w
+}
+
+func coordRefSysFromWire(w *wire.CoordRefSys) *CoordRefSys {
+	if w == nil {
+		return nil
+	}
+	c := &CoordRefSys{Href: w.Href, Epoch: w.Epoch}
+	if w.Components != nil {
+		c.Components = make([]CoordRefSys, len(w.Components))
+		for i := range w.Components {
+			c.Components[i] = *coordRefSysFromWire(&w.Components[i])
+		}
+	}
+	return c
+}
+
+func (c *CoordRefSys) toWire() *wire.CoordRefSys {
+	if c == nil {
+		return nil
+	}
+	w := &wire.CoordRefSys{Href: c.Href, Epoch: c.Epoch}
+	if c.Components != nil {
+		w.Components = make([]wire.CoordRefSys, len(c.Components))
+		for i := range c.Components {
+			w.Components[i] = *c.Components[i].toWire()
+		}
+	}
+	return w
+}
+
+// isJSONFG reports whether f uses any JSON-FG member.
+func (f *Feature) isJSONFG() bool {
+	return f.Place != nil || f.Time != nil || len(f.FeatureType) > 0 || f.CoordRefSys != nil
+}
+
+// isJSONFG reports whether f or any of its features uses a JSON-FG member.
+func (f *FeatureCollection) isJSONFG() bool {
+	if len(f.FeatureType) > 0 || f.CoordRefSys != nil {
+		return true
+	}
+	for i := range f.Features {
+		if f.Features[i].isJSONFG() {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Polyhedron) UnmarshalJSON(b []byte) error {
+	var w wire.Polyhedron
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	return p.unmarshalFrom(w.Coordinates)
+}
+
+func (p *Polyhedron) unmarshalFrom(coords [][][][][]float64) error {
+	*p = Polyhedron{}
+	if len(coords) == 0 {
+		return fmt.Errorf("unmarshal Polyhedron: must have at least 1 shell")
+	}
+	shells := make([][]Polygon, len(coords))
+	for i, shellCoords := range coords {
+		if len(shellCoords) == 0 {
+			return fmt.Errorf("unmarshal Polyhedron: shell %d has no faces", i)
+		}
+		shells[i] = make([]Polygon, len(shellCoords))
+		for j, faceCoords := range shellCoords {
+			err := shells[i][j].unmarshalFrom(faceCoords)
+			if err != nil {
+				return fmt.Errorf("unmarshal Polyhedron: %v", err)
+			}
+			for _, ring := range shells[i][j].Rings {
+				for _, point := range ring.Points {
+					if !point.HasElevation {
+						return fmt.Errorf("unmarshal Polyhedron: positions must have an elevation")
+					}
+				}
+			}
+		}
+	}
+	p.Shells = shells
+	return nil
+}
+
+func (m *MultiPolyhedron) UnmarshalJSON(b []byte) error {
+	var w wire.MultiPolyhedron
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	return m.unmarshalFrom(w.Coordinates)
+}
+
+func (m *MultiPolyhedron) unmarshalFrom(coords [][][][][][]float64) error {
+	*m = MultiPolyhedron{}
+	if len(coords) == 0 {
+		return nil
+	}
+	polyhedra := make([]Polyhedron, len(coords))
+	for i, polyhedronCoords := range coords {
+		err := polyhedra[i].unmarshalFrom(polyhedronCoords)
+		if err != nil {
+			return fmt.Errorf("unmarshal MultiPolyhedron: %v", err)
+		}
+	}
+	m.Polyhedra = polyhedra
+	return nil
+}
+
+func (p *Prism) UnmarshalJSON(b []byte) error {
+	var w wire.Prism
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	return p.unmarshalFrom(&w)
+}
+
+func (p *Prism) unmarshalFrom(w *wire.Prism) error {
+	*p = Prism{}
+	base, err := unmarshalGeometry(w.Base)
+	if err != nil {
+		return fmt.Errorf("unmarshal Prism: %v", err)
+	}
+	switch base.(type) {
+	case *Point, *LineString, *Polygon, *MultiPoint, *MultiLineString, *MultiPolygon,
+		*CircularString, *CompoundCurve, *CurvePolygon, *MultiCurve, *MultiSurface:
+	default:
+		return fmt.Errorf("unmarshal Prism: invalid base type: %T", base)
+	}
+	if w.Lower > w.Upper {
+		return fmt.Errorf("unmarshal Prism: lower limit %v is above upper limit %v", w.Lower, w.Upper)
+	}
+	p.Base = base
+	p.Lower = w.Lower
+	p.Upper = w.Upper
+	return nil
+}
+
+func (m *MultiPrism) UnmarshalJSON(b []byte) error {
+	var w wire.MultiPrism
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	return m.unmarshalFrom(w.Prisms)
+}
+
+func (m *MultiPrism) unmarshalFrom(prisms []wire.Prism) error {
+	*m = MultiPrism{}
+	if len(prisms) == 0 {
+		return nil
+	}
+	ps := make([]Prism, len(prisms))
+	for i := range prisms {
+		err := ps[i].unmarshalFrom(&prisms[i])
+		if err != nil {
+			return fmt.Errorf("unmarshal MultiPrism: %v", err)
+		}
+	}
+	m.Prisms = ps
+	return nil
+}
+
+func (c *CircularString) UnmarshalJSON(b []byte) error {
+	var w wire.CircularString
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	return c.unmarshalFrom(w.Coordinates)
+}
+
+func (c *CircularString) unmarshalFrom(coords [][]float64) error {
+	*c = CircularString{}
+	numPoints := len(coords)
+	if numPoints < 3 || numPoints%2 == 0 {
+		return fmt.Errorf("unmarshal CircularString: must have an odd number of at least 3 points, got %d", numPoints)
+	}
+	points := make([]Point, numPoints)
+	for i, pointCoords := range coords {
+		err := points[i].unmarshalFrom(pointCoords)
+		if err != nil {
+			return fmt.Errorf("unmarshal CircularString: %v", err)
+		}
+	}
+	c.Points = points
+	return nil
+}
+
+// curveEnds returns the first and last points of a LineString,
+// CircularString, or CompoundCurve.
+func curveEnds(g Geometry) (Point, Point, bool) {
+	switch t := g.(type) {
+	case *LineString:
+		return t.Points[0], t.Points[len(t.Points)-1], true
+	case *CircularString:
+		return t.Points[0], t.Points[len(t.Points)-1], true
+	case *CompoundCurve:
+		first, _, _ := curveEnds(t.Curves[0])
+		_, last, _ := curveEnds(t.Curves[len(t.Curves)-1])
+		return first, last, true
+	}
+	return Point{}, Point{}, false
+}
+
+func (c *CompoundCurve) UnmarshalJSON(b []byte) error {
+	var w wire.CompoundCurve
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	return c.unmarshalFrom(w.Geometries)
+}
+
+func (c *CompoundCurve) unmarshalFrom(geometries []wire.Geometry) error {
+	*c = CompoundCurve{}
+	if len(geometries) == 0 {
+		return fmt.Errorf("unmarshal CompoundCurve: must have at least 1 curve")
+	}
+	curves := make([]Geometry, len(geometries))
+	for i, geometry := range geometries {
+		g, err := unmarshalGeometry(geometry)
+		if err != nil {
+			return fmt.Errorf("unmarshal CompoundCurve: curve %d: %v", i, err)
+		}
+		switch g.(type) {
+		case *LineString, *CircularString:
+		default:
+			return fmt.Errorf("unmarshal CompoundCurve: curve %d has invalid type %T", i, g)
+		}
+		if i > 0 {
+			_, end, _ := curveEnds(curves[i-1])
+			start, _, _ := curveEnds(g)
+			if start != end {
+				return fmt.Errorf("unmarshal CompoundCurve: curve %d does not start at the end of curve %d", i, i-1)
+			}
+		}
+		curves[i] = g
+	}
+	c.Curves = curves
+	return nil
+}
+
+func (c *CurvePolygon) UnmarshalJSON(b []byte) error {
+	var w wire.CurvePolygon
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	return c.unmarshalFrom(w.Geometries)
+}
+
+func (c *CurvePolygon) unmarshalFrom(geometries []wire.Geometry) error {
+	*c = CurvePolygon{}
+	if len(geometries) == 0 {
+		return fmt.Errorf("unmarshal CurvePolygon: must have at least 1 ring")
+	}
+	rings := make([]Geometry, len(geometries))
+	for i, geometry := range geometries {
+		g, err := unmarshalGeometry(geometry)
+		if err != nil {
+			return fmt.Errorf("unmarshal CurvePolygon: ring %d: %v", i, err)
+		}
+		if ls, ok := g.(*LineString); ok && len(ls.Points) < 4 {
+			return fmt.Errorf("unmarshal CurvePolygon: each linear ring requires at least 4 points, ring %d has %d", i, len(ls.Points))
+		}
+		first, last, ok := curveEnds(g)
+		if !ok {
+			return fmt.Errorf("unmarshal CurvePolygon: ring %d has invalid type %T", i, g)
+		}
+		if first != last {
+			return fmt.Errorf("unmarshal CurvePolygon: ring %d is not closed", i)
+		}
+		rings[i] = g
+	}
+	c.Rings = rings
+	return nil
+}
+
+func (m *MultiCurve) UnmarshalJSON(b []byte) error {
+	var w wire.MultiCurve
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	return m.unmarshalFrom(w.Geometries)
+}
+
+func (m *MultiCurve) unmarshalFrom(geometries []wire.Geometry) error {
+	*m = MultiCurve{}
+	if len(geometries) == 0 {
+		return nil
+	}
+	curves := make([]Geometry, len(geometries))
+	for i, geometry := range geometries {
+		g, err := unmarshalGeometry(geometry)
+		if err != nil {
+			return fmt.Errorf("unmarshal MultiCurve: curve %d: %v", i, err)
+		}
+		if _, _, ok := curveEnds(g); !ok {
+			return fmt.Errorf("unmarshal MultiCurve: curve %d has invalid type %T", i, g)
+		}
+		curves[i] = g
+	}
+	m.Curves = curves
+	return nil
+}
+
+func (m *MultiSurface) UnmarshalJSON(b []byte) error {
+	var w wire.MultiSurface
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	return m.unmarshalFrom(w.Geometries)
+}
+
+func (m *MultiSurface) unmarshalFrom(geometries []wire.Geometry) error {
+	*m = MultiSurface{}
+	if len(geometries) == 0 {
+		return nil
+	}
+	surfaces := make([]Geometry, len(geometries))
+	for i, geometry := range geometries {
+		g, err := unmarshalGeometry(geometry)
+		if err != nil {
+			return fmt.Errorf("unmarshal MultiSurface: surface %d: %v", i, err)
+		}
+		switch g.(type) {
+		case *Polygon, *CurvePolygon:
+		default:
+			return fmt.Errorf("unmarshal MultiSurface: surface %d has invalid type %T", i, g)
+		}
+		surfaces[i] = g
+	}
+	m.Surfaces = surfaces
+	return nil
+}
+
+// jsonfgGeometryToWire converts the JSON-FG geometry types, returning nil
+// for any other type.
+func jsonfgGeometryToWire(g Geometry) wire.Geometry {
+	geometries := func(gs []Geometry) []wire.Geometry {
+		ws := make([]wire.Geometry, len(gs))
+		for i, child := range gs {
+			ws[i] = geometryToWire(child)
+		}
+		return ws
+	}
+	switch t := g.(type) {
+	case *Polyhedron:
+		return &wire.Polyhedron{Coordinates: t.coordinates()}
+	case *MultiPolyhedron:
+		coords := make([][][][][][]float64, len(t.Polyhedra))
+		for i := range t.Polyhedra {
+			coords[i] = t.Polyhedra[i].coordinates()
+		}
+		return &wire.MultiPolyhedron{Coordinates: coords}
+	case *Prism:
+		return t.toWire()
+	case *MultiPrism:
+		prisms := make([]wire.Prism, len(t.Prisms))
+		for i := range t.Prisms {
+			prisms[i] = *t.Prisms[i].toWire()
+		}
+		return &wire.MultiPrism{Prisms: prisms}
+	case *CircularString:
+		return &wire.CircularString{Coordinates: pointCoordinates(t.Points)}
+	case *CompoundCurve:
+		return &wire.CompoundCurve{Geometries: geometries(t.Curves)}
+	case *CurvePolygon:
+		return &wire.CurvePolygon{Geometries: geometries(t.Rings)}
+	case *MultiCurve:
+		return &wire.MultiCurve{Geometries: geometries(t.Curves)}
+	case *MultiSurface:
+		return &wire.MultiSurface{Geometries: geometries(t.Surfaces)}
+	}
+	return nil
+}
+
+func (p *Polyhedron) coordinates() [][][][][]float64 {
+	coords := make([][][][][]float64, len(p.Shells))
+	for i, shell := range p.Shells {
+		coords[i] = make([][][][]float64, len(shell))
+		for j := range shell {
+			coords[i][j] = shell[j].coordinates()
+		}
+	}
+	return coords
+}
+
+func (p *Prism) toWire() *wire.Prism {
+	return &wire.Prism{Base: geometryToWire(p.Base), Lower: p.Lower, Upper: p.Upper}
+}
@@ -0,0 +1,204 @@
+package geojson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bsidhom/geojson/wire"
+)
+
+func TestUnmarshalJSON_JSONFG(t *testing.T) {
+	cases := []struct {
+		s        string
+		expected Object
+	}{
+		{
+			s: `{
+  "type": "FeatureCollection",
+  "conformsTo": ["http://www.opengis.net/spec/json-fg-1/0.2/conf/core"],
+  "featureType": "Building",
+  "coordRefSys": "[EPSG:5555]",
+  "features": [
+    {
+      "type": "Feature",
+      "time": {"interval": ["2014-04-24", ".."]},
+      "place": {
+        "type": "Polyhedron",
+        "coordinates": [[[[[0,0,0],[1,0,0],[1,1,0],[0,0,0]]]]]
+      },
+      "geometry": null,
+      "properties": null
+    }
+  ]
+}`,
+			expected: &FeatureCollection{
+				FeatureType: []string{"Building"},
+				CoordRefSys: &CoordRefSys{Href: "[EPSG:5555]"},
+				Features: []Feature{
+					{
+						Time: &Time{
+							Start:       time.Date(2014, 4, 24, 0, 0, 0, 0, time.UTC),
+							HasInterval: true,
+							Dates:       true,
+						},
+						Place: &Polyhedron{
+							Shells: [][]Polygon{{{Rings: []LineString{{Points: []Point{
+								{X: 0, Y: 0, Elevation: 0, HasElevation: true},
+								{X: 1, Y: 0, Elevation: 0, HasElevation: true},
+								{X: 1, Y: 1, Elevation: 0, HasElevation: true},
+								{X: 0, Y: 0, Elevation: 0, HasElevation: true},
+							}}}}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			s: `{
+  "type": "Feature",
+  "conformsTo": ["[ogc-json-fg-1-0.2:core]"],
+  "time": {"date": "2020-05-01", "timestamp": "2020-05-01T10:00:00.5+02:00"},
+  "place": {
+    "type": "Prism",
+    "base": {"type": "CurvePolygon", "geometries": [
+      {"type": "CompoundCurve", "geometries": [
+        {"type": "CircularString", "coordinates": [[0,0],[1,1],[2,0]]},
+        {"type": "LineString", "coordinates": [[2,0],[0,0]]}
+      ]}
+    ]},
+    "lower": 2,
+    "upper": 10
+  },
+  "geometry": {"type": "Point", "coordinates": [1, 0.5]},
+  "properties": null
+}`,
+			expected: &Feature{
+				Time: &Time{
+					Instant: time.Date(2020, 5, 1, 8, 0, 0, 5e8, time.UTC),
+				},
+				Place: &Prism{
+					Base: &CurvePolygon{Rings: []Geometry{
+						&CompoundCurve{Curves: []Geometry{
+							&CircularString{Points: []Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 0}}},
+							&LineString{Points: []Point{{X: 2, Y: 0}, {X: 0, Y: 0}}},
+						}},
+					}},
+					Lower: 2,
+					Upper: 10,
+				},
+				Geometry: &Point{X: 1, Y: 0.5},
+			},
+		},
+	}
+	for i, c := range cases {
+		var obj Wrapper
+		err := json.Unmarshal([]byte(c.s), &obj)
+		if err != nil {
+			t.Errorf("error unmarshaling case %d: %v", i, err)
+			continue
+		}
+		// Compare instants rather than locations.
+		if f, ok := obj.Value.(*Feature); ok && f.Time != nil {
+			f.Time.Instant = f.Time.Instant.UTC()
+		}
+		if !reflect.DeepEqual(obj.Value, c.expected) {
+			t.Errorf("for case %d expected %#v, got %#v", i, c.expected, obj.Value)
+		}
+	}
+}
+
+func TestUnmarshalJSON_JSONFGInvalid(t *testing.T) {
+	feature := func(member string) string {
+		return `{"type":"Feature","conformsTo":["` + wire.JSONFGCore + `"],` + member + `,"geometry":null,"properties":null}`
+	}
+	cases := []string{
+		feature(`"time":{"date":"2020-13-01"}`),
+		feature(`"time":{"timestamp":"2020-01-01"}`),
+		feature(`"time":{"interval":[".."]}`),
+		feature(`"time":{"interval":["2020-01-02","2020-01-01"]}`),
+		// Polyhedron positions require an elevation.
+		feature(`"place":{"type":"Polyhedron","coordinates":[[[[[0,0],[1,0],[1,1],[0,0]]]]]}`),
+		feature(`"place":{"type":"Prism","base":{"type":"GeometryCollection","geometries":[]},"upper":1}`),
+		feature(`"place":{"type":"Prism","base":{"type":"Point","coordinates":[0,0]},"lower":2,"upper":1}`),
+		feature(`"place":{"type":"CircularString","coordinates":[[0,0],[1,1],[2,0],[3,1]]}`),
+		// Curves of a CompoundCurve must be contiguous.
+		feature(`"place":{"type":"CompoundCurve","geometries":[{"type":"LineString","coordinates":[[0,0],[1,1]]},{"type":"LineString","coordinates":[[2,2],[3,3]]}]}`),
+		feature(`"place":{"type":"CurvePolygon","geometries":[{"type":"CircularString","coordinates":[[0,0],[1,1],[2,0]]}]}`),
+		feature(`"place":{"type":"MultiCurve","geometries":[{"type":"Point","coordinates":[0,0]}]}`),
+		feature(`"place":{"type":"MultiSurface","geometries":[{"type":"LineString","coordinates":[[0,0],[1,1]]}]}`),
+	}
+	for i, c := range cases {
+		var obj Wrapper
+		if err := json.Unmarshal([]byte(c), &obj); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestRoundTrip_ToWire_JSONFG(t *testing.T) {
+	arc := &CircularString{Points: []Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 0}}}
+	closed := &CompoundCurve{Curves: []Geometry{
+		arc,
+		&LineString{Points: []Point{{X: 2, Y: 0}, {X: 0, Y: 0}}},
+	}}
+	ring := LineString{Points: []Point{
+		{X: 0, Y: 0, HasElevation: true},
+		{X: 1, Y: 0, HasElevation: true},
+		{X: 0, Y: 1, HasElevation: true},
+		{X: 0, Y: 0, HasElevation: true},
+	}}
+	cases := []Object{
+		&MultiPolyhedron{Polyhedra: []Polyhedron{{Shells: [][]Polygon{{{Rings: []LineString{ring}}}}}}},
+		&MultiPrism{Prisms: []Prism{{Base: &Point{X: 1, Y: 2}, Lower: 1, Upper: 3}}},
+		&MultiCurve{Curves: []Geometry{arc, closed}},
+		&MultiSurface{Surfaces: []Geometry{&CurvePolygon{Rings: []Geometry{closed}}}},
+		&Feature{
+			Place: &Point{X: 1, Y: 2},
+			Time: &Time{
+				Instant:     time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC),
+				End:         time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+				HasInterval: true,
+			},
+			CoordRefSys: &CoordRefSys{Components: []CoordRefSys{{Href: "[EPSG:25832]"}, {Href: "[EPSG:7837]", Epoch: 2016.5}}},
+		},
+		&FeatureCollection{
+			FeatureType: []string{"a", "b"},
+			Features: []Feature{
+				{Time: &Time{Instant: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Dates: true}},
+			},
+		},
+	}
+	for i, c := range cases {
+		got, err := FromWire(ToWire(c))
+		if err != nil {
+			t.Errorf("case %d: failed to convert %T: %v", i, c, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c) {
+			t.Errorf("case %d: expected %#v, got %#v", i, c, got)
+		}
+	}
+}
+
+func TestToWire_JSONFG(t *testing.T) {
+	// JSON-FG members are only recognized in documents that declare
+	// conformance, so ToWire adds it when they are used.
+	f := &Feature{Geometry: &Point{X: 1, Y: 2}, Place: &Point{X: 3, Y: 4}}
+	w := ToWire(f).(*wire.Feature)
+	if !reflect.DeepEqual(w.ConformsTo, []string{wire.JSONFGCore}) {
+		t.Errorf("expected feature to conform to %s, got %v", wire.JSONFGCore, w.ConformsTo)
+	}
+	fc := ToWire(&FeatureCollection{Features: []Feature{*f}}).(*wire.FeatureCollection)
+	if !reflect.DeepEqual(fc.ConformsTo, []string{wire.JSONFGCore}) {
+		t.Errorf("expected collection to conform to %s, got %v", wire.JSONFGCore, fc.ConformsTo)
+	}
+	if fc.Features[0].ConformsTo != nil {
+		t.Errorf("expected no conformance on nested feature, got %v", fc.Features[0].ConformsTo)
+	}
+	plain := ToWire(&Feature{Geometry: &Point{X: 1, Y: 2}}).(*wire.Feature)
+	if plain.ConformsTo != nil {
+		t.Errorf("expected no conformance on plain feature, got %v", plain.ConformsTo)
+	}
+}
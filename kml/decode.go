@@ -0,0 +1,312 @@
+// Package kml converts between KML 2.2 documents and GeoJSON
+// FeatureCollections.
+//
+// Only the vector content of Placemarks is preserved: geometries, names,
+// descriptions, and ExtendedData. Styles, overlays, and other presentation
+// elements are ignored on input and never written on output.
+package kml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/ring"
+	"github.com/bsidhom/geojson/internal/xmltree"
+)
+
+// Decode reads a KML document and returns its Placemarks as a
+// FeatureCollection. Placemarks are collected in document order from any
+// depth of nested Documents and Folders.
+//
+// The name and description of each Placemark are stored under the "name" and
+// "description" properties. ExtendedData values (both Data and SchemaData
+// forms) are stored as string properties. Placemark ids become Feature IDs.
+//
+// KML altitudes are stored as Point elevations regardless of altitudeMode.
+// MultiGeometry elements are decoded as GeometryCollections. The gx:Track and
+// gx:MultiTrack extensions are decoded as LineStrings and MultiLineStrings of
+// their gx:coord positions; their timestamps are dropped. KML does not fix
+// the winding of polygon rings, so they are rewound to wind outer rings
+// counter-clockwise and holes clockwise, as RFC 7946 requires.
+func Decode(r io.Reader) (*geojson.FeatureCollection, error) {
+	d := xml.NewDecoder(r)
+	fc := &geojson.FeatureCollection{}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("kml: %v", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "Placemark" {
+			continue
+		}
+		var n xmltree.Node
+		err = d.DecodeElement(&n, &start)
+		if err != nil {
+			return nil, fmt.Errorf("kml: %v", err)
+		}
+		f, err := decodePlacemark(&n)
+		if err != nil {
+			return nil, fmt.Errorf("kml: placemark %d: %v", len(fc.Features), err)
+		}
+		fc.Features = append(fc.Features, *f)
+	}
+	return fc, nil
+}
+
+func decodePlacemark(n *xmltree.Node) (*geojson.Feature, error) {
+	f := &geojson.Feature{
+		Properties: map[string]interface{}{},
+	}
+	if id, ok := n.Attr("id"); ok {
+		f.ID = id
+	}
+	for i := range n.Nodes {
+		child := &n.Nodes[i]
+		switch child.XMLName.Local {
+		case "name", "description":
+			f.Properties[child.XMLName.Local] = child.Text()
+		case "ExtendedData":
+			decodeExtendedData(child, f.Properties)
+		default:
+			if !isGeometry(child.XMLName.Local) {
+				continue
+			}
+			if f.Geometry != nil {
+				return nil, fmt.Errorf("multiple geometries")
+			}
+			g, err := decodeGeometry(child)
+			if err != nil {
+				return nil, err
+			}
+			f.Geometry = g
+		}
+	}
+	return f, nil
+}
+
+func decodeExtendedData(n *xmltree.Node, properties map[string]interface{}) {
+	for _, data := range n.Children("Data") {
+		name, ok := data.Attr("name")
+		if !ok {
+			continue
+		}
+		value := ""
+		if v := data.Child("value"); v != nil {
+			value = v.Text()
+		}
+		properties[name] = value
+	}
+	for _, schemaData := range n.Children("SchemaData") {
+		for _, data := range schemaData.Children("SimpleData") {
+			name, ok := data.Attr("name")
+			if !ok {
+				continue
+			}
+			properties[name] = data.Text()
+		}
+	}
+}
+
+func isGeometry(name string) bool {
+	switch name {
+	case "Point", "LineString", "LinearRing", "Polygon", "MultiGeometry", "Track", "MultiTrack":
+		return true
+	}
+	return false
+}
+
+func decodeGeometry(n *xmltree.Node) (geojson.Geometry, error) {
+	switch n.XMLName.Local {
+	case "Point":
+		points, err := decodeCoordinates(n)
+		if err != nil {
+			return nil, fmt.Errorf("Point: %v", err)
+		}
+		if len(points) != 1 {
+			return nil, fmt.Errorf("Point: must have exactly 1 coordinate, got %d", len(points))
+		}
+		return &points[0], nil
+	case "LineString":
+		points, err := decodeCoordinates(n)
+		if err != nil {
+			return nil, fmt.Errorf("LineString: %v", err)
+		}
+		if len(points) < 2 {
+			return nil, fmt.Errorf("LineString: must have at least 2 points, got %d", len(points))
+		}
+		return &geojson.LineString{Points: points}, nil
+	case "LinearRing":
+		// A bare LinearRing is a valid KML geometry. It is closed by
+		// definition, so it is treated as a Polygon without holes.
+		r, err := decodeRing(n)
+		if err != nil {
+			return nil, fmt.Errorf("LinearRing: %v", err)
+		}
+		return ring.Rewind(&geojson.Polygon{Rings: []geojson.LineString{*r}}), nil
+	case "Polygon":
+		p, err := decodePolygon(n)
+		if err != nil {
+			return nil, fmt.Errorf("Polygon: %v", err)
+		}
+		return p, nil
+	case "Track":
+		ls, err := decodeTrack(n)
+		if err != nil {
+			return nil, fmt.Errorf("Track: %v", err)
+		}
+		return ls, nil
+	case "MultiTrack":
+		mls := &geojson.MultiLineString{}
+		for _, track := range n.Children("Track") {
+			ls, err := decodeTrack(track)
+			if err != nil {
+				return nil, fmt.Errorf("MultiTrack: track %d: %v", len(mls.Lines), err)
+			}
+			mls.Lines = append(mls.Lines, *ls)
+		}
+		return mls, nil
+	case "MultiGeometry":
+		gc := &geojson.GeometryCollection{}
+		for i := range n.Nodes {
+			child := &n.Nodes[i]
+			if !isGeometry(child.XMLName.Local) {
+				continue
+			}
+			g, err := decodeGeometry(child)
+			if err != nil {
+				return nil, fmt.Errorf("MultiGeometry: geometry %d: %v", len(gc.Geometries), err)
+			}
+			gc.Geometries = append(gc.Geometries, g)
+		}
+		return gc, nil
+	}
+	return nil, fmt.Errorf("unsupported geometry: %s", n.XMLName.Local)
+}
+
+func decodePolygon(n *xmltree.Node) (*geojson.Polygon, error) {
+	outer := n.Child("outerBoundaryIs")
+	if outer == nil {
+		return nil, fmt.Errorf("missing outerBoundaryIs")
+	}
+	boundaries := append([]*xmltree.Node{outer}, n.Children("innerBoundaryIs")...)
+	var rings []geojson.LineString
+	for i, boundary := range boundaries {
+		// Some producers put every hole in a single innerBoundaryIs.
+		lrs := boundary.Children("LinearRing")
+		if len(lrs) == 0 {
+			return nil, fmt.Errorf("boundary %d: missing LinearRing", i)
+		}
+		if i == 0 {
+			lrs = lrs[:1]
+		}
+		for _, lr := range lrs {
+			r, err := decodeRing(lr)
+			if err != nil {
+				return nil, fmt.Errorf("boundary %d: %v", i, err)
+			}
+			rings = append(rings, *r)
+		}
+	}
+	return ring.Rewind(&geojson.Polygon{Rings: rings}), nil
+}
+
+// decodeRing decodes a LinearRing. Rings that are not explicitly closed are
+// closed by repeating their first point, which many KML producers omit.
+func decodeRing(n *xmltree.Node) (*geojson.LineString, error) {
+	points, err := decodeCoordinates(n)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) > 0 && points[0] != points[len(points)-1] {
+		points = append(points, points[0])
+	}
+	if len(points) < 4 {
+		return nil, fmt.Errorf("linear ring requires at least 4 points, got %d", len(points))
+	}
+	return &geojson.LineString{Points: points}, nil
+}
+
+// decodeCoordinates parses the coordinates child of n. KML coordinates are
+// whitespace-separated tuples of comma-separated longitude, latitude, and
+// optional altitude.
+func decodeCoordinates(n *xmltree.Node) ([]geojson.Point, error) {
+	c := n.Child("coordinates")
+	if c == nil {
+		return nil, fmt.Errorf("missing coordinates")
+	}
+	tuples := splitTuples(c.Content)
+	points := make([]geojson.Point, len(tuples))
+	for i, tuple := range tuples {
+		fields := strings.Split(tuple, ",")
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("coordinate %d: must have 2-3 values, got %q", i, tuple)
+		}
+		var values [3]float64
+		for j, field := range fields {
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, fmt.Errorf("coordinate %d: %v", i, err)
+			}
+			values[j] = v
+		}
+		points[i] = geojson.Point{X: values[0], Y: values[1]}
+		if len(fields) == 3 {
+			points[i].Elevation = values[2]
+			points[i].HasElevation = true
+		}
+	}
+	return points, nil
+}
+
+// splitTuples splits coordinates into tuples at whitespace. Many producers
+// write a space after the commas within a tuple, so whitespace next to a
+// comma does not end a tuple.
+func splitTuples(s string) []string {
+	var tuples []string
+	for _, field := range strings.Fields(s) {
+		if n := len(tuples); n > 0 && (strings.HasSuffix(tuples[n-1], ",") || strings.HasPrefix(field, ",")) {
+			tuples[n-1] += field
+			continue
+		}
+		tuples = append(tuples, field)
+	}
+	return tuples
+}
+
+// decodeTrack parses the gx:coord children of a gx:Track, whose positions
+// are space-separated longitude, latitude, and optional altitude.
+func decodeTrack(n *xmltree.Node) (*geojson.LineString, error) {
+	coords := n.Children("coord")
+	ls := &geojson.LineString{Points: make([]geojson.Point, len(coords))}
+	for i, c := range coords {
+		fields := strings.Fields(c.Content)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("coord %d: must have 2-3 values, got %q", i, c.Text())
+		}
+		var values [3]float64
+		for j, field := range fields {
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, fmt.Errorf("coord %d: %v", i, err)
+			}
+			values[j] = v
+		}
+		ls.Points[i] = geojson.Point{X: values[0], Y: values[1]}
+		if len(fields) == 3 {
+			ls.Points[i].Elevation = values[2]
+			ls.Points[i].HasElevation = true
+		}
+	}
+	if len(ls.Points) < 2 {
+		return nil, fmt.Errorf("must have at least 2 points, got %d", len(ls.Points))
+	}
+	return ls, nil
+}
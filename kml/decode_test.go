@@ -0,0 +1,214 @@
+package kml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestDecode(t *testing.T) {
+	s := `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2" xmlns:gx="http://www.google.com/kml/ext/2.2">
+  <Document>
+    <name>Test</name>
+    <Placemark id="p1">
+      <name>Point</name>
+      <description><![CDATA[<b>bold</b>]]></description>
+      <Point>
+        <coordinates>-122.08,37.42,12</coordinates>
+      </Point>
+    </Placemark>
+    <Folder>
+      <name>Outer</name>
+      <Folder>
+        <name>Inner</name>
+        <Placemark>
+          <ExtendedData>
+            <Data name="kind"><value>road</value></Data>
+            <SchemaData schemaUrl="#s">
+              <SimpleData name="lanes">2</SimpleData>
+            </SchemaData>
+          </ExtendedData>
+          <LineString>
+            <tessellate>1</tessellate>
+            <coordinates>
+              0,0 1,1
+              2,0
+            </coordinates>
+          </LineString>
+        </Placemark>
+      </Folder>
+      <Placemark>
+        <Polygon>
+          <outerBoundaryIs>
+            <LinearRing><coordinates>0,0 4,0 4,4 0,4 0,0</coordinates></LinearRing>
+          </outerBoundaryIs>
+          <innerBoundaryIs>
+            <LinearRing><coordinates>1,1 1,2 2,2 2,1</coordinates></LinearRing>
+            <LinearRing><coordinates>3,3 3,3.5 3.5,3.5 3.5,3</coordinates></LinearRing>
+          </innerBoundaryIs>
+        </Polygon>
+      </Placemark>
+    </Folder>
+    <Placemark>
+      <MultiGeometry>
+        <Point><coordinates>1,2</coordinates></Point>
+        <MultiGeometry>
+          <Point><coordinates>3,4</coordinates></Point>
+        </MultiGeometry>
+      </MultiGeometry>
+    </Placemark>
+    <Placemark>
+      <LineString><coordinates>1, 2 3 ,4, 5   6,7</coordinates></LineString>
+    </Placemark>
+    <Placemark>
+      <gx:Track>
+        <when>2020-01-01T00:00:00Z</when>
+        <when>2020-01-01T00:01:00Z</when>
+        <gx:coord>1 2 3</gx:coord>
+        <gx:coord>4 5 6</gx:coord>
+      </gx:Track>
+    </Placemark>
+    <Placemark>
+      <gx:MultiTrack>
+        <gx:Track><gx:coord>0 0</gx:coord><gx:coord>1 1</gx:coord></gx:Track>
+        <gx:Track><gx:coord>2 2</gx:coord><gx:coord>3 3</gx:coord></gx:Track>
+      </gx:MultiTrack>
+    </Placemark>
+  </Document>
+</kml>`
+	expected := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				ID:       "p1",
+				Geometry: &geojson.Point{X: -122.08, Y: 37.42, Elevation: 12, HasElevation: true},
+				Properties: map[string]interface{}{
+					"name":        "Point",
+					"description": "<b>bold</b>",
+				},
+			},
+			{
+				Geometry: &geojson.LineString{
+					Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 0}},
+				},
+				Properties: map[string]interface{}{
+					"kind":  "road",
+					"lanes": "2",
+				},
+			},
+			{
+				Geometry: &geojson.Polygon{
+					Rings: []geojson.LineString{
+						{Points: []geojson.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}, {X: 0, Y: 0}}},
+						{Points: []geojson.Point{{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 1}, {X: 1, Y: 1}}},
+						{Points: []geojson.Point{{X: 3, Y: 3}, {X: 3, Y: 3.5}, {X: 3.5, Y: 3.5}, {X: 3.5, Y: 3}, {X: 3, Y: 3}}},
+					},
+				},
+				Properties: map[string]interface{}{},
+			},
+			{
+				Geometry: &geojson.GeometryCollection{
+					Geometries: []geojson.Geometry{
+						&geojson.Point{X: 1, Y: 2},
+						&geojson.GeometryCollection{
+							Geometries: []geojson.Geometry{
+								&geojson.Point{X: 3, Y: 4},
+							},
+						},
+					},
+				},
+				Properties: map[string]interface{}{},
+			},
+			{
+				Geometry: &geojson.LineString{
+					Points: []geojson.Point{{X: 1, Y: 2}, {X: 3, Y: 4, Elevation: 5, HasElevation: true}, {X: 6, Y: 7}},
+				},
+				Properties: map[string]interface{}{},
+			},
+			{
+				Geometry: &geojson.LineString{
+					Points: []geojson.Point{
+						{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+						{X: 4, Y: 5, Elevation: 6, HasElevation: true},
+					},
+				},
+				Properties: map[string]interface{}{},
+			},
+			{
+				Geometry: &geojson.MultiLineString{
+					Lines: []geojson.LineString{
+						{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+						{Points: []geojson.Point{{X: 2, Y: 2}, {X: 3, Y: 3}}},
+					},
+				},
+				Properties: map[string]interface{}{},
+			},
+		},
+	}
+	fc, err := Decode(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if !reflect.DeepEqual(fc, expected) {
+		t.Errorf("expected %#v, got %#v", expected, fc)
+	}
+}
+
+func TestDecode_Winding(t *testing.T) {
+	// A clockwise outer ring and a counter-clockwise hole are rewound, and
+	// so is a clockwise bare LinearRing.
+	square := geojson.LineString{
+		Points: []geojson.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}, {X: 0, Y: 0}},
+	}
+	cases := []struct {
+		s        string
+		expected geojson.Geometry
+	}{
+		{
+			`<kml><Placemark><Polygon>
+  <outerBoundaryIs><LinearRing><coordinates>0,0 0,4 4,4 4,0 0,0</coordinates></LinearRing></outerBoundaryIs>
+  <innerBoundaryIs><LinearRing><coordinates>1,1 2,1 2,2 1,2 1,1</coordinates></LinearRing></innerBoundaryIs>
+</Polygon></Placemark></kml>`,
+			&geojson.Polygon{
+				Rings: []geojson.LineString{
+					square,
+					{Points: []geojson.Point{{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 1}, {X: 1, Y: 1}}},
+				},
+			},
+		},
+		{
+			`<kml><Placemark><LinearRing><coordinates>0,0 0,4 4,4 4,0 0,0</coordinates></LinearRing></Placemark></kml>`,
+			&geojson.Polygon{Rings: []geojson.LineString{square}},
+		},
+	}
+	for i, c := range cases {
+		fc, err := Decode(strings.NewReader(c.s))
+		if err != nil {
+			t.Errorf("case %d: failed to decode: %v", i, err)
+			continue
+		}
+		if g := fc.Features[0].Geometry; !reflect.DeepEqual(g, c.expected) {
+			t.Errorf("case %d: expected %v, got %v", i, c.expected, g)
+		}
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	cases := []string{
+		`<kml><Placemark><Point><coordinates>1</coordinates></Point></Placemark></kml>`,
+		`<kml><Placemark><Point><coordinates>1,a</coordinates></Point></Placemark></kml>`,
+		`<kml><Placemark><LineString><coordinates>1,2</coordinates></LineString></Placemark></kml>`,
+		`<kml><Placemark><Polygon><innerBoundaryIs/></Polygon></Placemark></kml>`,
+		`<kml><Placemark><Polygon><outerBoundaryIs><LinearRing><coordinates>0,0 1,1 0,0</coordinates></LinearRing></outerBoundaryIs></Polygon></Placemark></kml>`,
+		`<kml><Placemark><Polygon><outerBoundaryIs><LinearRing><coordinates>0,0 1,0 1,1 0,0</coordinates></LinearRing></outerBoundaryIs><innerBoundaryIs/></Polygon></Placemark></kml>`,
+		`<kml><Placemark>`,
+	}
+	for i, c := range cases {
+		_, err := Decode(strings.NewReader(c))
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
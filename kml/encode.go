@@ -0,0 +1,171 @@
+package kml
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/xmltree"
+)
+
+// Namespace is the XML namespace of KML 2.2 documents.
+const Namespace = "http://www.opengis.net/kml/2.2"
+
+// Encode writes fc to w as a KML 2.2 document containing one Placemark per
+// Feature.
+//
+// String "name" and "description" properties become the corresponding
+// Placemark elements. All other properties are written as ExtendedData; string
+// values are written verbatim and any other values are written as JSON.
+// Elevations are written as altitudes without an altitudeMode, so they are
+// interpreted by KML clients as clamped to the ground.
+func Encode(w io.Writer, fc *geojson.FeatureCollection) error {
+	doc := xmltree.Element("Document")
+	for i := range fc.Features {
+		p, err := encodePlacemark(&fc.Features[i])
+		if err != nil {
+			return fmt.Errorf("kml: feature %d: %v", i, err)
+		}
+		doc.Nodes = append(doc.Nodes, *p)
+	}
+	root := xmltree.Element("kml", doc)
+	// Declare the namespace as a plain attribute. Setting it on the element
+	// name instead causes encoding/xml to reset the namespace on every child.
+	root.Attrs = []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: Namespace}}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	err = e.Encode(&root)
+	if err != nil {
+		return fmt.Errorf("kml: %v", err)
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+func encodePlacemark(f *geojson.Feature) (*xmltree.Node, error) {
+	p := xmltree.Element("Placemark")
+	if f.ID != "" {
+		p.Attrs = []xml.Attr{{Name: xml.Name{Local: "id"}, Value: f.ID}}
+	}
+
+	var keys []string
+	for k := range f.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	extended := xmltree.Element("ExtendedData")
+	for _, k := range keys {
+		v := f.Properties[k]
+		if s, ok := v.(string); ok && (k == "name" || k == "description") {
+			p.Nodes = append(p.Nodes, xmltree.TextElement(k, s))
+			continue
+		}
+		s, err := propertyString(v)
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %v", k, err)
+		}
+		data := xmltree.Element("Data", xmltree.TextElement("value", s))
+		data.Attrs = []xml.Attr{{Name: xml.Name{Local: "name"}, Value: k}}
+		extended.Nodes = append(extended.Nodes, data)
+	}
+	if len(extended.Nodes) > 0 {
+		p.Nodes = append(p.Nodes, extended)
+	}
+
+	if f.Geometry != nil {
+		g, err := encodeGeometry(f.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		p.Nodes = append(p.Nodes, *g)
+	}
+	return &p, nil
+}
+
+func propertyString(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func encodeGeometry(g geojson.Geometry) (*xmltree.Node, error) {
+	var n xmltree.Node
+	switch t := g.(type) {
+	case *geojson.Point:
+		n = xmltree.Element("Point", coordinates([]geojson.Point{*t}))
+	case *geojson.MultiPoint:
+		n = xmltree.Element("MultiGeometry")
+		for _, p := range t.Points {
+			n.Nodes = append(n.Nodes, xmltree.Element("Point", coordinates([]geojson.Point{p})))
+		}
+	case *geojson.LineString:
+		n = xmltree.Element("LineString", coordinates(t.Points))
+	case *geojson.MultiLineString:
+		n = xmltree.Element("MultiGeometry")
+		for _, ls := range t.Lines {
+			n.Nodes = append(n.Nodes, xmltree.Element("LineString", coordinates(ls.Points)))
+		}
+	case *geojson.Polygon:
+		n = encodePolygon(t)
+	case *geojson.MultiPolygon:
+		n = xmltree.Element("MultiGeometry")
+		for i := range t.Polygons {
+			n.Nodes = append(n.Nodes, encodePolygon(&t.Polygons[i]))
+		}
+	case *geojson.GeometryCollection:
+		n = xmltree.Element("MultiGeometry")
+		for i, child := range t.Geometries {
+			c, err := encodeGeometry(child)
+			if err != nil {
+				return nil, fmt.Errorf("GeometryCollection: geometry %d: %v", i, err)
+			}
+			n.Nodes = append(n.Nodes, *c)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported geometry type: %T", t)
+	}
+	return &n, nil
+}
+
+func encodePolygon(p *geojson.Polygon) xmltree.Node {
+	n := xmltree.Element("Polygon")
+	for i, ring := range p.Rings {
+		name := "innerBoundaryIs"
+		if i == 0 {
+			name = "outerBoundaryIs"
+		}
+		n.Nodes = append(n.Nodes, xmltree.Element(name, xmltree.Element("LinearRing", coordinates(ring.Points))))
+	}
+	return n
+}
+
+func coordinates(points []geojson.Point) xmltree.Node {
+	tuples := make([]string, len(points))
+	for i, p := range points {
+		s := formatFloat(p.X) + "," + formatFloat(p.Y)
+		if p.HasElevation {
+			s += "," + formatFloat(p.Elevation)
+		}
+		tuples[i] = s
+	}
+	return xmltree.TextElement("coordinates", strings.Join(tuples, " "))
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,104 @@
+package kml
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+// MultiPoint, MultiLineString and MultiPolygon are all written as KML
+// MultiGeometry and therefore come back as GeometryCollections, so only types
+// that survive a round trip unchanged are checked here.
+func TestRoundTrip_Encode(t *testing.T) {
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				ID:       "a",
+				Geometry: &geojson.Point{X: 1.5, Y: -2.25, Elevation: 100, HasElevation: true},
+				Properties: map[string]interface{}{
+					"name":        "A & B",
+					"description": "<p>html</p>",
+					"kind":        "marker",
+				},
+			},
+			{
+				Geometry: &geojson.LineString{
+					Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}},
+				},
+				Properties: map[string]interface{}{},
+			},
+			{
+				Geometry: &geojson.GeometryCollection{
+					Geometries: []geojson.Geometry{
+						&geojson.Polygon{
+							Rings: []geojson.LineString{
+								{Points: []geojson.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 0}}},
+								{Points: []geojson.Point{{X: 1, Y: 1}, {X: 2, Y: 2}, {X: 3, Y: 2}, {X: 1, Y: 1}}},
+							},
+						},
+						&geojson.Point{X: 5, Y: 5},
+					},
+				},
+				Properties: map[string]interface{}{},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	err := Encode(&buf, fc)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, fc) {
+		t.Errorf("round trip failed: expected %#v, got %#v", fc, decoded)
+	}
+}
+
+func TestEncode_Properties(t *testing.T) {
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				Geometry: &geojson.MultiPoint{
+					Points: []geojson.Point{{X: 1, Y: 2}, {X: 3, Y: 4}},
+				},
+				Properties: map[string]interface{}{
+					"count":  float64(3),
+					"nested": map[string]interface{}{"a": true},
+				},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	err := Encode(&buf, fc)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	expected := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				Geometry: &geojson.GeometryCollection{
+					Geometries: []geojson.Geometry{
+						&geojson.Point{X: 1, Y: 2},
+						&geojson.Point{X: 3, Y: 4},
+					},
+				},
+				Properties: map[string]interface{}{
+					"count":  "3",
+					"nested": `{"a":true}`,
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("expected %#v, got %#v", expected, decoded)
+	}
+}
@@ -0,0 +1,122 @@
+package geojson
+
+import "github.com/bsidhom/geojson/wire"
+
+// A CRS is a coordinate reference system declared by the "crs" member of a
+// GeoJSON 2008 object. A CRS with an empty Type represents an explicit null
+// "crs", which means that the reference system is unknown.
+type CRS struct {
+	// Either wire.CRSName or wire.CRSLink.
+	Type string
+	// Name of a named CRS, such as "urn:ogc:def:crs:EPSG::3857".
+	Name string
+	// URI and optional format of a linked CRS.
+	Href     string
+	LinkType string
+}
+
+// EPSG returns the EPSG code of c, or false if c does not refer to one. See
+// wire.CRS.EPSG for the recognized forms.
+func (c *CRS) EPSG() (int, bool) {
+	return c.toWire().EPSG()
+}
+
+// IsCRS84 reports whether c refers to WGS84 longitude/latitude coordinates.
+func (c *CRS) IsCRS84() bool {
+	return c.toWire().IsCRS84()
+}
+
+func (c *CRS) String() string {
+	return c.toWire().String()
+}
+
+func crsFromWire(w *wire.CRS) *CRS {
+	if w == nil {
+		return nil
+	}
+	return &CRS{Type: w.Type, Name: w.Name, Href: w.Href, LinkType: w.LinkType}
+}
+
+func (c *CRS) toWire() *wire.CRS {
+	if c == nil {
+		return nil
+	}
+	return &wire.CRS{Type: c.Type, Name: c.Name, Href: c.Href, LinkType: c.LinkType}
+}
+
+// UnmarshalLegacy decodes a GeoJSON object that may use the "crs" member of
+// GeoJSON 2008, keeping the CRS of FeatureCollections and Features. opts may
+// be nil, in which case any reference system is accepted.
+//
+// Geometries have no CRS field, so the CRS declared by a geometry is kept
+// on the object that holds it. The CRS of a Feature's geometry becomes the
+// CRS of the Feature, replacing any the Feature declares, since it is the
+// one that applies to the Feature's coordinates. For a bare geometry, the
+// CRS is returned as crs. A geometry within a GeometryCollection contributes
+// its CRS only if the collection declares none; GeoJSON 2008 asks that a
+// CRS not be overridden by children. For FeatureCollections and Features,
+// crs is their CRS field.
+func UnmarshalLegacy(b []byte, opts *wire.LegacyOptions) (obj Object, crs *CRS, err error) {
+	w, err := wire.UnmarshalLegacy(b, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	var c *wire.CRS
+	switch t := w.(type) {
+	case *wire.FeatureCollection:
+		for i := range t.Features {
+			liftCRS(&t.Features[i])
+		}
+		c = t.CRS
+	case *wire.Feature:
+		liftCRS(t)
+		c = t.CRS
+	case wire.Geometry:
+		c = geometryCRS(t)
+	}
+	obj, err = FromWire(w)
+	if err != nil {
+		return nil, nil, err
+	}
+	return obj, crsFromWire(c), nil
+}
+
+// liftCRS moves the CRS of the geometry of f, if any, onto f.
+func liftCRS(f *wire.Feature) {
+	if f.Geometry == nil {
+		return
+	}
+	if c := geometryCRS(f.Geometry); c != nil {
+		f.CRS = c
+	}
+}
+
+// geometryCRS returns the CRS declared by g or, if g is a
+// GeometryCollection that declares none, by the first of its geometries
+// that does.
+func geometryCRS(g wire.Geometry) *wire.CRS {
+	switch t := g.(type) {
+	case *wire.GeometryCollection:
+		if t.CRS != nil {
+			return t.CRS
+		}
+		for _, child := range t.Geometries {
+			if c := geometryCRS(child); c != nil {
+				return c
+			}
+		}
+	case *wire.MultiPolygon:
+		return t.CRS
+	case *wire.Polygon:
+		return t.CRS
+	case *wire.MultiLineString:
+		return t.CRS
+	case *wire.LineString:
+		return t.CRS
+	case *wire.MultiPoint:
+		return t.CRS
+	case *wire.Point:
+		return t.CRS
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package geojson
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson/wire"
+)
+
+func TestUnmarshalLegacy(t *testing.T) {
+	s := `{
+  "type": "FeatureCollection",
+  "crs": {"type": "name", "properties": {"name": "EPSG:27700"}},
+  "features": [
+    {"type": "Feature", "geometry": {"type": "Point", "coordinates": [530000, 180000]}, "properties": null}
+  ]
+}`
+	obj, crs, err := UnmarshalLegacy([]byte(s), nil)
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	expected := &FeatureCollection{
+		CRS: &CRS{Type: wire.CRSName, Name: "EPSG:27700"},
+		Features: []Feature{
+			{Geometry: &Point{X: 530000, Y: 180000}},
+		},
+	}
+	if !reflect.DeepEqual(obj, expected) {
+		t.Errorf("expected %#v, got %#v", expected, obj)
+	}
+	fc := obj.(*FeatureCollection)
+	if !reflect.DeepEqual(crs, fc.CRS) {
+		t.Errorf("expected the collection's CRS %v, got %v", fc.CRS, crs)
+	}
+	if code, ok := fc.CRS.EPSG(); !ok || code != 27700 {
+		t.Errorf("expected EPSG code 27700, got (%d, %v)", code, ok)
+	}
+	if got, err := FromWire(ToWire(fc)); err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("round trip failed: expected %#v, got %#v (%v)", expected, got, err)
+	}
+
+	if _, _, err := UnmarshalLegacy([]byte(s), &wire.LegacyOptions{RequireCRS84: true}); err == nil {
+		t.Errorf("expected error for projected coordinates")
+	}
+}
+
+func TestUnmarshalLegacy_GeometryCRS(t *testing.T) {
+	bng := &CRS{Type: wire.CRSName, Name: "EPSG:27700"}
+	itm := &CRS{Type: wire.CRSName, Name: "EPSG:2157"}
+	cases := []struct {
+		s        string
+		expected Object
+		crs      *CRS
+	}{
+		{
+			`{"type": "Point", "coordinates": [530000, 180000], "crs": {"type": "name", "properties": {"name": "EPSG:27700"}}}`,
+			&Point{X: 530000, Y: 180000},
+			bng,
+		},
+		{
+			`{"type": "Point", "coordinates": [1, 2]}`,
+			&Point{X: 1, Y: 2},
+			nil,
+		},
+		{
+			`{"type": "GeometryCollection", "geometries": [
+				{"type": "Point", "coordinates": [1, 2]},
+				{"type": "Point", "coordinates": [3, 4], "crs": {"type": "name", "properties": {"name": "EPSG:27700"}}}
+			]}`,
+			&GeometryCollection{Geometries: []Geometry{&Point{X: 1, Y: 2}, &Point{X: 3, Y: 4}}},
+			bng,
+		},
+		// The geometry's CRS applies to the feature's coordinates.
+		{
+			`{"type": "Feature", "properties": null,
+			  "geometry": {"type": "Point", "coordinates": [530000, 180000], "crs": {"type": "name", "properties": {"name": "EPSG:27700"}}}}`,
+			&Feature{Geometry: &Point{X: 530000, Y: 180000}, CRS: bng},
+			bng,
+		},
+		{
+			`{"type": "Feature", "properties": null, "crs": {"type": "name", "properties": {"name": "EPSG:2157"}},
+			  "geometry": {"type": "Point", "coordinates": [530000, 180000], "crs": {"type": "name", "properties": {"name": "EPSG:27700"}}}}`,
+			&Feature{Geometry: &Point{X: 530000, Y: 180000}, CRS: bng},
+			bng,
+		},
+		{
+			`{"type": "FeatureCollection", "crs": {"type": "name", "properties": {"name": "EPSG:2157"}}, "features": [
+				{"type": "Feature", "properties": null, "geometry": {"type": "Point", "coordinates": [1, 2]}},
+				{"type": "Feature", "properties": null,
+				 "geometry": {"type": "Point", "coordinates": [530000, 180000], "crs": {"type": "name", "properties": {"name": "EPSG:27700"}}}}
+			]}`,
+			&FeatureCollection{CRS: itm, Features: []Feature{
+				{Geometry: &Point{X: 1, Y: 2}},
+				{Geometry: &Point{X: 530000, Y: 180000}, CRS: bng},
+			}},
+			itm,
+		},
+	}
+	for i, c := range cases {
+		obj, crs, err := UnmarshalLegacy([]byte(c.s), nil)
+		if err != nil {
+			t.Errorf("case %d: failed to unmarshal: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(obj, c.expected) || !reflect.DeepEqual(crs, c.crs) {
+			t.Errorf("case %d: expected (%#v, %v), got (%#v, %v)", i, c.expected, c.crs, obj, crs)
+		}
+	}
+}
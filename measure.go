@@ -0,0 +1,128 @@
+package geojson
+
+import (
+	"math"
+
+	"github.com/bsidhom/geojson/internal/geodesic"
+)
+
+// MeanRadius is the mean radius of the WGS84 ellipsoid in metres, which is
+// used for spherical measurements.
+const MeanRadius = 6371008.8
+
+// MeasureOptions controls how Area, Length, and Perimeter measure
+// geometries. The zero value measures geodesics on the WGS84 ellipsoid,
+// which is accurate to within nanometres.
+type MeasureOptions struct {
+	// Whether to measure great circles on a sphere of radius MeanRadius
+	// instead. This is several times faster, but errors grow to about 1%
+	// for areas near the poles.
+	Spherical bool
+}
+
+// A surface measures lines and rings of latitude/longitude vertices.
+type surface interface {
+	Line(lats, lons []float64) float64
+	Ring(lats, lons []float64) (perimeter, area float64)
+}
+
+func (opts *MeasureOptions) surface() surface {
+	if opts != nil && opts.Spherical {
+		return geodesic.Sphere{R: MeanRadius}
+	}
+	return geodesic.WGS84
+}
+
+// Area returns the area of g in square metres. Only Polygons and
+// MultiPolygons have an area, so g may also be a GeometryCollection
+// containing them. Holes are subtracted from the area of their polygon.
+//
+// Rings are assumed to enclose less than half of the Earth. Area does not
+// rely on the winding documented on Polygon to tell which side of a ring is
+// enclosed: a ring wound the wrong way measures the same as its rewound
+// form, not the rest of the Earth. The JSON-FG geometry types measure 0,
+// since they are not necessarily in WGS84.
+func Area(g Geometry, opts *MeasureOptions) float64 {
+	s := opts.surface()
+	var area float64
+	walkPolygons(g, func(p *Polygon) {
+		for i, ring := range p.Rings {
+			_, a := s.Ring(latLons(ring.Points))
+			if i == 0 {
+				area += math.Abs(a)
+			} else {
+				area -= math.Abs(a)
+			}
+		}
+	})
+	return area
+}
+
+// Length returns the length in metres of the LineStrings and
+// MultiLineStrings in g, which may also be a GeometryCollection containing
+// them. Other geometries have no length; see Perimeter for polygons.
+func Length(g Geometry, opts *MeasureOptions) float64 {
+	s := opts.surface()
+	var length float64
+	walkLines(g, func(ls *LineString) {
+		length += s.Line(latLons(ls.Points))
+	})
+	return length
+}
+
+// Perimeter returns the length in metres of the rings, including holes, of
+// the Polygons and MultiPolygons in g, which may also be a
+// GeometryCollection containing them.
+func Perimeter(g Geometry, opts *MeasureOptions) float64 {
+	s := opts.surface()
+	var perimeter float64
+	walkPolygons(g, func(p *Polygon) {
+		for _, ring := range p.Rings {
+			perimeter += s.Line(latLons(ring.Points))
+		}
+	})
+	return perimeter
+}
+
+// walkPolygons calls f for each polygon within g.
+func walkPolygons(g Geometry, f func(p *Polygon)) {
+	switch t := g.(type) {
+	case *Polygon:
+		f(t)
+	case *MultiPolygon:
+		for i := range t.Polygons {
+			f(&t.Polygons[i])
+		}
+	case *GeometryCollection:
+		for _, child := range t.Geometries {
+			walkPolygons(child, f)
+		}
+	}
+}
+
+// walkLines calls f for each LineString within g, excluding polygon rings.
+func walkLines(g Geometry, f func(ls *LineString)) {
+	switch t := g.(type) {
+	case *LineString:
+		f(t)
+	case *MultiLineString:
+		for i := range t.Lines {
+			f(&t.Lines[i])
+		}
+	case *GeometryCollection:
+		for _, child := range t.Geometries {
+			walkLines(child, f)
+		}
+	}
+}
+
+// latLons splits points into their latitudes and longitudes.
+func latLons(points []Point) (lats, lons []float64) {
+	lats = make([]float64, len(points))
+	lons = make([]float64, len(points))
+	for i, p := range points {
+		lats[i] = p.Y
+		lons[i] = p.X
+	}
+	return lats, lons
+}
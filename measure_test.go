@@ -0,0 +1,89 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMeasure(t *testing.T) {
+	square := func(x0, y0, x1, y1 float64) LineString {
+		return LineString{Points: []Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}, {X: x0, Y: y0}}}
+	}
+	// An octant bounded by the equator and two meridians has exactly an
+	// eighth of the area of the ellipsoid.
+	octant := &Polygon{Rings: []LineString{{Points: []Point{{X: 0, Y: 0}, {X: 90, Y: 0}, {X: 0, Y: 90}, {X: 0, Y: 0}}}}}
+	antarctica := &Polygon{Rings: []LineString{{Points: []Point{
+		{X: -58, Y: -63.1}, {X: -74, Y: -72.9}, {X: -102, Y: -71.9}, {X: -102, Y: -74.9},
+		{X: -131, Y: -74.3}, {X: -163, Y: -77.5}, {X: 163, Y: -77.4}, {X: 172, Y: -71.7},
+		{X: 140, Y: -65.9}, {X: 113, Y: -65.7}, {X: 88, Y: -66.6}, {X: 59, Y: -66.9},
+		{X: 25, Y: -69.8}, {X: -4, Y: -70.0}, {X: -14, Y: -71.0}, {X: -33, Y: -77.3},
+		{X: -46, Y: -77.9}, {X: -61, Y: -74.7}, {X: -58, Y: -63.1},
+	}}}}
+	holed := &Polygon{Rings: []LineString{square(0, 0, 2, 2), square(0.5, 0.5, 1.5, 1.5)}}
+	flight := &LineString{Points: []Point{{X: -73.8, Y: 40.6}, {X: -0.5, Y: 51.6}}}
+	cases := []struct {
+		g                       Geometry
+		area, length, perimeter float64
+		tolerance               float64
+	}{
+		{g: &Point{X: 1, Y: 2}},
+		{g: octant, area: 510065621724088.5 / 8, perimeter: 2*10001965.729 + 10018754.171, tolerance: 1e-9},
+		// Reference values from GeographicLib.
+		{g: antarctica, area: 13662703680020.1, perimeter: 16831067.893, tolerance: 1e-9},
+		{g: flight, length: 5551759.400, tolerance: 1e-9},
+		{
+			g:         &MultiPolygon{Polygons: []Polygon{*holed, *holed}},
+			area:      2 * (Area(&Polygon{Rings: holed.Rings[:1]}, nil) - Area(&Polygon{Rings: holed.Rings[1:]}, nil)),
+			perimeter: 2 * (Perimeter(&Polygon{Rings: holed.Rings[:1]}, nil) + Perimeter(&Polygon{Rings: holed.Rings[1:]}, nil)),
+			tolerance: 1e-12,
+		},
+		{
+			g:         &GeometryCollection{Geometries: []Geometry{octant, flight, &MultiLineString{Lines: []LineString{*flight}}}},
+			area:      510065621724088.5 / 8,
+			length:    2 * 5551759.400,
+			perimeter: 2*10001965.729 + 10018754.171,
+			tolerance: 1e-9,
+		},
+	}
+	within := func(got, expected, tolerance float64) bool {
+		return math.Abs(got-expected) <= tolerance*math.Max(1, math.Abs(expected))
+	}
+	for i, c := range cases {
+		if got := Area(c.g, nil); !within(got, c.area, c.tolerance) {
+			t.Errorf("case %d: expected area %v, got %v", i, c.area, got)
+		}
+		if got := Length(c.g, nil); !within(got, c.length, c.tolerance) {
+			t.Errorf("case %d: expected length %v, got %v", i, c.length, got)
+		}
+		if got := Perimeter(c.g, nil); !within(got, c.perimeter, c.tolerance) {
+			t.Errorf("case %d: expected perimeter %v, got %v", i, c.perimeter, got)
+		}
+		// Spherical measurements are within 1% of the ellipsoidal ones.
+		opts := &MeasureOptions{Spherical: true}
+		if got := Area(c.g, opts); !within(got, c.area, 1e-2) {
+			t.Errorf("case %d: expected spherical area near %v, got %v", i, c.area, got)
+		}
+		if got := Length(c.g, opts); !within(got, c.length, 1e-2) {
+			t.Errorf("case %d: expected spherical length near %v, got %v", i, c.length, got)
+		}
+		if got := Perimeter(c.g, opts); !within(got, c.perimeter, 1e-2) {
+			t.Errorf("case %d: expected spherical perimeter near %v, got %v", i, c.perimeter, got)
+		}
+	}
+}
+
+func TestArea_Winding(t *testing.T) {
+	ring := []Point{{X: 179, Y: -1}, {X: -179, Y: -1}, {X: -179, Y: 1}, {X: 179, Y: 1}, {X: 179, Y: -1}}
+	reversed := make([]Point, len(ring))
+	for i := range ring {
+		reversed[i] = ring[len(ring)-1-i]
+	}
+	for _, opts := range []*MeasureOptions{nil, {Spherical: true}} {
+		a := Area(&Polygon{Rings: []LineString{{Points: ring}}}, opts)
+		b := Area(&Polygon{Rings: []LineString{{Points: reversed}}}, opts)
+		// A 2 by 2 degree square at the equator, across the antimeridian.
+		if a != b || a < 4.9e10 || a > 5e10 {
+			t.Errorf("expected equal areas of about 4.95e10 m^2, got %v and %v", a, b)
+		}
+	}
+}
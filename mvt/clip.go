@@ -0,0 +1,130 @@
+package mvt
+
+// A box is a clipping rectangle in tile coordinates.
+type box struct {
+	min, max float64
+}
+
+func (b box) contains(c xy) bool {
+	return c.x >= b.min && c.x <= b.max && c.y >= b.min && c.y <= b.max
+}
+
+// clipLine clips a polyline to b, which may split it into several pieces.
+func clipLine(line []xy, b box) [][]xy {
+	var pieces [][]xy
+	var current []xy
+	for i := 0; i+1 < len(line); i++ {
+		p, q, ok := clipSegment(line[i], line[i+1], b)
+		if !ok {
+			if len(current) > 0 {
+				pieces = append(pieces, current)
+				current = nil
+			}
+			continue
+		}
+		if len(current) == 0 {
+			current = append(current, p)
+		} else if current[len(current)-1] != p {
+			// The segment re-entered the box somewhere else.
+			pieces = append(pieces, current)
+			current = []xy{p}
+		}
+		current = append(current, q)
+		if q != line[i+1] {
+			// The segment left the box.
+			pieces = append(pieces, current)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		pieces = append(pieces, current)
+	}
+	return pieces
+}
+
+// clipSegment clips the segment pq to b using the Liang-Barsky algorithm.
+func clipSegment(p, q xy, b box) (xy, xy, bool) {
+	t0, t1 := 0.0, 1.0
+	dx, dy := q.x-p.x, q.y-p.y
+	for _, edge := range [4][2]float64{
+		{-dx, p.x - b.min},
+		{dx, b.max - p.x},
+		{-dy, p.y - b.min},
+		{dy, b.max - p.y},
+	} {
+		pe, qe := edge[0], edge[1]
+		if pe == 0 {
+			if qe < 0 {
+				return xy{}, xy{}, false
+			}
+			continue
+		}
+		r := qe / pe
+		if pe < 0 {
+			if r > t1 {
+				return xy{}, xy{}, false
+			}
+			if r > t0 {
+				t0 = r
+			}
+		} else {
+			if r < t0 {
+				return xy{}, xy{}, false
+			}
+			if r < t1 {
+				t1 = r
+			}
+		}
+	}
+	start, end := p, q
+	if t0 > 0 {
+		start = xy{p.x + t0*dx, p.y + t0*dy}
+	}
+	if t1 < 1 {
+		end = xy{p.x + t1*dx, p.y + t1*dy}
+	}
+	return start, end, true
+}
+
+// clipRing clips a closed ring (without a repeated final point) to b using
+// the Sutherland-Hodgman algorithm. Parts of the ring outside b collapse onto
+// its edges, which is harmless for rendering.
+func clipRing(ring []xy, b box) []xy {
+	type edge struct {
+		inside    func(xy) bool
+		intersect func(p, q xy) xy
+	}
+	lerpX := func(p, q xy, x float64) xy {
+		return xy{x, p.y + (q.y-p.y)*(x-p.x)/(q.x-p.x)}
+	}
+	lerpY := func(p, q xy, y float64) xy {
+		return xy{p.x + (q.x-p.x)*(y-p.y)/(q.y-p.y), y}
+	}
+	edges := []edge{
+		{func(c xy) bool { return c.x >= b.min }, func(p, q xy) xy { return lerpX(p, q, b.min) }},
+		{func(c xy) bool { return c.x <= b.max }, func(p, q xy) xy { return lerpX(p, q, b.max) }},
+		{func(c xy) bool { return c.y >= b.min }, func(p, q xy) xy { return lerpY(p, q, b.min) }},
+		{func(c xy) bool { return c.y <= b.max }, func(p, q xy) xy { return lerpY(p, q, b.max) }},
+	}
+	out := ring
+	for _, e := range edges {
+		in := out
+		out = nil
+		for i, q := range in {
+			p := in[(i+len(in)-1)%len(in)]
+			switch {
+			case e.inside(q):
+				if !e.inside(p) {
+					out = append(out, e.intersect(p, q))
+				}
+				out = append(out, q)
+			case e.inside(p):
+				out = append(out, e.intersect(p, q))
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+package mvt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClipLine(t *testing.T) {
+	b := box{0, 10}
+	cases := []struct {
+		line     []xy
+		expected [][]xy
+	}{
+		{
+			line:     []xy{{1, 1}, {5, 5}},
+			expected: [][]xy{{{1, 1}, {5, 5}}},
+		},
+		{
+			line:     []xy{{-5, 5}, {15, 5}},
+			expected: [][]xy{{{0, 5}, {10, 5}}},
+		},
+		{
+			line:     []xy{{5, 5}, {15, 5}, {15, 6}, {5, 6}},
+			expected: [][]xy{{{5, 5}, {10, 5}}, {{10, 6}, {5, 6}}},
+		},
+		{
+			line:     []xy{{-5, -5}, {-1, 20}},
+			expected: nil,
+		},
+	}
+	for i, c := range cases {
+		got := clipLine(c.line, b)
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("case %d: expected %v, got %v", i, c.expected, got)
+		}
+	}
+}
+
+func TestClipRing(t *testing.T) {
+	b := box{0, 10}
+	cases := []struct {
+		ring     []xy
+		expected float64
+	}{
+		{
+			ring:     []xy{{1, 1}, {5, 1}, {5, 5}},
+			expected: 16,
+		},
+		{
+			ring:     []xy{{-10, -10}, {20, -10}, {20, 20}, {-10, 20}},
+			expected: 200,
+		},
+		{
+			ring:     []xy{{5, 5}, {15, 5}, {15, 15}, {5, 15}},
+			expected: 50,
+		},
+		{
+			ring:     []xy{{20, 20}, {30, 20}, {30, 30}},
+			expected: 0,
+		},
+	}
+	for i, c := range cases {
+		got := ringArea(clipRing(c.ring, b))
+		if got != c.expected {
+			t.Errorf("case %d: expected doubled area %v, got %v", i, c.expected, got)
+		}
+	}
+}
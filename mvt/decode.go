@@ -0,0 +1,267 @@
+package mvt
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/pb"
+)
+
+// Decode decodes the tile t into one FeatureCollection per layer, with
+// geometries in WGS84.
+//
+// Numeric values of every tile value type are decoded as float64, matching
+// encoding/json. Feature IDs are formatted as decimal strings. Multi-part
+// geometries are decoded as MultiPoints, MultiLineStrings, or MultiPolygons;
+// single parts are decoded as the corresponding simple types. Polygon rings
+// are classified as exteriors or holes by their winding, as the
+// specification requires.
+func Decode(b []byte, t Tile) ([]Layer, error) {
+	var layers []Layer
+	d := pb.NewDecoder(b)
+	for d.Next() {
+		if d.Field() != tileLayers {
+			continue
+		}
+		l, err := decodeLayer(d.Embedded(), t)
+		if err != nil {
+			return nil, fmt.Errorf("mvt: layer %d: %v", len(layers), err)
+		}
+		layers = append(layers, *l)
+	}
+	if err := d.Err(); err != nil {
+		return nil, fmt.Errorf("mvt: %v", err)
+	}
+	return layers, nil
+}
+
+type rawFeature struct {
+	id       uint64
+	hasID    bool
+	tags     []uint64
+	geomType uint64
+	geometry []uint64
+}
+
+func decodeLayer(b []byte, t Tile) (*Layer, error) {
+	l := &Layer{Extent: DefaultExtent, Features: &geojson.FeatureCollection{}}
+	var keys []string
+	var values []interface{}
+	var features []rawFeature
+	d := pb.NewDecoder(b)
+	for d.Next() {
+		switch d.Field() {
+		case layerName:
+			l.Name = d.String()
+		case layerExtent:
+			l.Extent = uint32(d.Uint64())
+		case layerKeys:
+			keys = append(keys, d.String())
+		case layerValues:
+			v, err := decodeValue(d.Embedded())
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		case layerFeatures:
+			f, err := decodeRawFeature(d.Embedded())
+			if err != nil {
+				return nil, err
+			}
+			features = append(features, *f)
+		}
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	if l.Extent == 0 {
+		return nil, fmt.Errorf("invalid extent 0")
+	}
+
+	for i, raw := range features {
+		f := geojson.Feature{Properties: map[string]interface{}{}}
+		if raw.hasID {
+			f.ID = strconv.FormatUint(raw.id, 10)
+		}
+		if len(raw.tags)%2 != 0 {
+			return nil, fmt.Errorf("feature %d: odd number of tags", i)
+		}
+		for j := 0; j < len(raw.tags); j += 2 {
+			k, v := raw.tags[j], raw.tags[j+1]
+			if k >= uint64(len(keys)) || v >= uint64(len(values)) {
+				return nil, fmt.Errorf("feature %d: tag index out of range", i)
+			}
+			f.Properties[keys[k]] = values[v]
+		}
+		g, err := decodeGeometry(raw.geomType, raw.geometry, t, float64(l.Extent))
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %v", i, err)
+		}
+		f.Geometry = g
+		l.Features.Features = append(l.Features.Features, f)
+	}
+	return l, nil
+}
+
+func decodeRawFeature(b []byte) (*rawFeature, error) {
+	f := &rawFeature{}
+	d := pb.NewDecoder(b)
+	for d.Next() {
+		switch d.Field() {
+		case featureID:
+			f.id = d.Uint64()
+			f.hasID = true
+		case featureTags:
+			f.tags = d.Uint64s(f.tags)
+		case featureType:
+			f.geomType = d.Uint64()
+		case featureGeometry:
+			f.geometry = d.Uint64s(f.geometry)
+		}
+	}
+	return f, d.Err()
+}
+
+func decodeValue(b []byte) (interface{}, error) {
+	var v interface{}
+	d := pb.NewDecoder(b)
+	for d.Next() {
+		switch d.Field() {
+		case valueString:
+			v = d.String()
+		case valueFloat:
+			v = float64(d.Float())
+		case valueDouble:
+			v = d.Double()
+		case valueInt:
+			v = float64(d.Int64())
+		case valueUint:
+			v = float64(d.Uint64())
+		case valueSint:
+			v = float64(d.Sint64())
+		case valueBool:
+			v = d.Bool()
+		}
+	}
+	return v, d.Err()
+}
+
+// decodeCommands interprets a command stream as a sequence of paths in tile
+// coordinates. Each MoveTo starts a new path. ClosePath is implied by the
+// geometry type, so it is only validated.
+func decodeCommands(commands []uint64) ([][]xy, error) {
+	var paths [][]xy
+	var cursor xy
+	for i := 0; i < len(commands); {
+		c := commands[i]
+		i++
+		id, count := int(c&0x7), c>>3
+		switch id {
+		case cmdMoveTo, cmdLineTo:
+			if count > uint64(len(commands)-i)/2 {
+				return nil, fmt.Errorf("command %d: truncated parameters", i-1)
+			}
+			if id == cmdLineTo && len(paths) == 0 {
+				return nil, fmt.Errorf("command %d: LineTo before MoveTo", i-1)
+			}
+			for j := uint64(0); j < count; j++ {
+				cursor.x += float64(pb.DecodeZigZag(commands[i]))
+				cursor.y += float64(pb.DecodeZigZag(commands[i+1]))
+				i += 2
+				if id == cmdMoveTo {
+					paths = append(paths, nil)
+				}
+				paths[len(paths)-1] = append(paths[len(paths)-1], cursor)
+			}
+		case cmdClosePath:
+			if len(paths) == 0 {
+				return nil, fmt.Errorf("command %d: ClosePath before MoveTo", i-1)
+			}
+		default:
+			return nil, fmt.Errorf("command %d: unknown command %d", i-1, id)
+		}
+	}
+	return paths, nil
+}
+
+func decodeGeometry(geomType uint64, commands []uint64, t Tile, extent float64) (geojson.Geometry, error) {
+	paths, err := decodeCommands(commands)
+	if err != nil {
+		return nil, err
+	}
+	unproject := func(path []xy) []geojson.Point {
+		points := make([]geojson.Point, len(path))
+		for i, c := range path {
+			points[i] = t.unproject(c, extent)
+		}
+		return points
+	}
+
+	switch geomType {
+	case geomUnknown:
+		return nil, nil
+	case geomPoint:
+		var points []geojson.Point
+		for _, path := range paths {
+			points = append(points, unproject(path)...)
+		}
+		switch len(points) {
+		case 0:
+			return nil, nil
+		case 1:
+			return &points[0], nil
+		}
+		return &geojson.MultiPoint{Points: points}, nil
+	case geomLineString:
+		var lines []geojson.LineString
+		for _, path := range paths {
+			if len(path) >= 2 {
+				lines = append(lines, geojson.LineString{Points: unproject(path)})
+			}
+		}
+		switch len(lines) {
+		case 0:
+			return nil, nil
+		case 1:
+			return &lines[0], nil
+		}
+		return &geojson.MultiLineString{Lines: lines}, nil
+	case geomPolygon:
+		var polygons []geojson.Polygon
+		for _, path := range paths {
+			area := ringArea(path)
+			if len(path) < 3 || area == 0 {
+				continue
+			}
+			points := unproject(append(path, path[0]))
+			// Tile exteriors are clockwise on screen, which is the opposite
+			// of RFC 7946, so every ring is reversed. A leading hole is
+			// invalid; it is salvaged as an exterior by leaving it as is.
+			if area > 0 || len(polygons) == 0 {
+				if area > 0 {
+					reversePoints(points)
+				}
+				polygons = append(polygons, geojson.Polygon{})
+			} else {
+				reversePoints(points)
+			}
+			p := &polygons[len(polygons)-1]
+			p.Rings = append(p.Rings, geojson.LineString{Points: points})
+		}
+		switch len(polygons) {
+		case 0:
+			return nil, nil
+		case 1:
+			return &polygons[0], nil
+		}
+		return &geojson.MultiPolygon{Polygons: polygons}, nil
+	}
+	return nil, fmt.Errorf("unknown geometry type %d", geomType)
+}
+
+func reversePoints(points []geojson.Point) {
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+}
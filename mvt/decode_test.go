@@ -0,0 +1,68 @@
+package mvt
+
+import (
+	"testing"
+
+	"github.com/bsidhom/geojson/internal/pb"
+)
+
+func TestDecode_Invalid(t *testing.T) {
+	layer := func(feature []byte) []byte {
+		var l pb.Encoder
+		l.String(layerName, "l")
+		l.String(layerKeys, "k")
+		var v pb.Encoder
+		v.String(valueString, "v")
+		l.Embedded(layerValues, v.Bytes())
+		l.Embedded(layerFeatures, feature)
+		var tile pb.Encoder
+		tile.Embedded(tileLayers, l.Bytes())
+		return tile.Bytes()
+	}
+	feature := func(geomType uint64, tags, geometry []uint32) []byte {
+		var f pb.Encoder
+		f.PackedUint32(featureTags, tags)
+		f.Uint64(featureType, geomType)
+		f.PackedUint32(featureGeometry, geometry)
+		return f.Bytes()
+	}
+	cases := [][]byte{
+		{0x1a, 0x05},
+		layer(feature(geomPoint, []uint32{0}, []uint32{9, 2, 2})),
+		layer(feature(geomPoint, []uint32{0, 1}, []uint32{9, 2, 2})),
+		layer(feature(geomPoint, nil, []uint32{9, 2})),
+		layer(feature(geomLineString, nil, []uint32{18, 2, 2})),
+		layer(feature(geomPolygon, nil, []uint32{15})),
+		layer(feature(geomPoint, nil, []uint32{11, 2, 2})),
+		layer(feature(9, nil, []uint32{9, 2, 2})),
+	}
+	for i, c := range cases {
+		_, err := Decode(c, Tile{})
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestDecodeCommands(t *testing.T) {
+	// A polygon with a hole, then a second polygon, from section 4.3.5.3 of
+	// the specification.
+	commands := []uint64{
+		9, 0, 0, 26, 20, 0, 0, 20, 19, 0, 15,
+		9, 22, 2, 26, 18, 0, 0, 18, 17, 0, 15,
+		9, 4, 13, 26, 0, 8, 8, 0, 0, 7, 15,
+	}
+	paths, err := decodeCommands(commands)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if len(paths) != 3 {
+		t.Fatalf("expected 3 rings, got %d", len(paths))
+	}
+	expectedAreas := []float64{200, 162, -32}
+	for i, path := range paths {
+		if a := ringArea(path); a != expectedAreas[i] {
+			t.Errorf("ring %d: expected doubled area %v, got %v", i, expectedAreas[i], a)
+		}
+	}
+}
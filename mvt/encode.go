@@ -0,0 +1,422 @@
+package mvt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/pb"
+)
+
+// Geometry types.
+const (
+	geomUnknown    = 0
+	geomPoint      = 1
+	geomLineString = 2
+	geomPolygon    = 3
+)
+
+// Commands.
+const (
+	cmdMoveTo    = 1
+	cmdLineTo    = 2
+	cmdClosePath = 7
+)
+
+// Field numbers of the Tile, Layer, Feature, and Value messages.
+const (
+	tileLayers = 3
+
+	layerName     = 1
+	layerFeatures = 2
+	layerKeys     = 3
+	layerValues   = 4
+	layerExtent   = 5
+	layerVersion  = 15
+
+	featureID       = 1
+	featureTags     = 2
+	featureType     = 3
+	featureGeometry = 4
+
+	valueString = 1
+	valueFloat  = 2
+	valueDouble = 3
+	valueInt    = 4
+	valueUint   = 5
+	valueSint   = 6
+	valueBool   = 7
+)
+
+const version = 2
+
+// EncodeOptions controls tile encoding.
+type EncodeOptions struct {
+	// Width, in tile units, of the margin around the tile within which
+	// geometries are kept. Geometries are clipped to the tile plus this
+	// buffer so that lines and polygon edges render seamlessly across tile
+	// boundaries.
+	Buffer uint32
+}
+
+// Encode encodes layers as the tile t. If opts is nil, the zero
+// EncodeOptions are used.
+//
+// Geometries are projected to Web Mercator, clipped, and snapped to the
+// integer tile grid. Parts that collapse to nothing are dropped, and features
+// left without geometry are omitted. Polygon rings are rewound as required by
+// the specification, so input winding does not matter. GeometryCollections
+// are split into one tile feature per member, each carrying the collection's
+// properties.
+//
+// Feature IDs are written only if they parse as unsigned integers.
+// String, boolean, and numeric properties map to the corresponding tile value
+// types; other non-nil values are written as JSON strings and nil values are
+// omitted.
+func Encode(t Tile, layers []Layer, opts *EncodeOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &EncodeOptions{}
+	}
+	var tile pb.Encoder
+	for i := range layers {
+		b, err := encodeLayer(t, &layers[i], opts)
+		if err != nil {
+			return nil, fmt.Errorf("mvt: layer %q: %v", layers[i].Name, err)
+		}
+		tile.Embedded(tileLayers, b)
+	}
+	return tile.Bytes(), nil
+}
+
+type valueKey struct {
+	kind int
+	s    string
+	f    float64
+	u    uint64
+	i    int64
+	b    bool
+}
+
+type layerEncoder struct {
+	tile   Tile
+	extent float64
+	clip   box
+	keys   []string
+	keyIdx map[string]uint32
+	values []valueKey
+	valIdx map[valueKey]uint32
+	layer  pb.Encoder
+}
+
+func encodeLayer(t Tile, l *Layer, opts *EncodeOptions) ([]byte, error) {
+	extent := l.Extent
+	if extent == 0 {
+		extent = DefaultExtent
+	}
+	e := &layerEncoder{
+		tile:   t,
+		extent: float64(extent),
+		clip:   box{-float64(opts.Buffer), float64(extent) + float64(opts.Buffer)},
+		keyIdx: map[string]uint32{},
+		valIdx: map[valueKey]uint32{},
+	}
+	e.layer.Uint64(layerVersion, version)
+	e.layer.String(layerName, l.Name)
+	e.layer.Uint64(layerExtent, uint64(extent))
+	if l.Features != nil {
+		for i := range l.Features.Features {
+			f := &l.Features.Features[i]
+			tags, err := e.tags(f.Properties)
+			if err != nil {
+				return nil, fmt.Errorf("feature %d: %v", i, err)
+			}
+			err = e.feature(f, f.Geometry, tags)
+			if err != nil {
+				return nil, fmt.Errorf("feature %d: %v", i, err)
+			}
+		}
+	}
+	for _, k := range e.keys {
+		e.layer.String(layerKeys, k)
+	}
+	for _, v := range e.values {
+		e.layer.Embedded(layerValues, encodeValue(v))
+	}
+	return e.layer.Bytes(), nil
+}
+
+// feature encodes f with geometry g, recursing into GeometryCollections.
+func (e *layerEncoder) feature(f *geojson.Feature, g geojson.Geometry, tags []uint32) error {
+	if gc, ok := g.(*geojson.GeometryCollection); ok {
+		for _, child := range gc.Geometries {
+			err := e.feature(f, child, tags)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	geomType, commands, err := e.geometry(g)
+	if err != nil {
+		return err
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+	var fe pb.Encoder
+	if id, err := strconv.ParseUint(f.ID, 10, 64); err == nil {
+		fe.Uint64(featureID, id)
+	}
+	fe.PackedUint32(featureTags, tags)
+	fe.Uint64(featureType, uint64(geomType))
+	fe.PackedUint32(featureGeometry, commands)
+	e.layer.Embedded(layerFeatures, fe.Bytes())
+	return nil
+}
+
+func (e *layerEncoder) tags(properties map[string]interface{}) ([]uint32, error) {
+	keys := make([]string, 0, len(properties))
+	for k, v := range properties {
+		if v != nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var tags []uint32
+	for _, k := range keys {
+		v, err := toValueKey(properties[k])
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %v", k, err)
+		}
+		ki, ok := e.keyIdx[k]
+		if !ok {
+			ki = uint32(len(e.keys))
+			e.keyIdx[k] = ki
+			e.keys = append(e.keys, k)
+		}
+		vi, ok := e.valIdx[v]
+		if !ok {
+			vi = uint32(len(e.values))
+			e.valIdx[v] = vi
+			e.values = append(e.values, v)
+		}
+		tags = append(tags, ki, vi)
+	}
+	return tags, nil
+}
+
+func toValueKey(v interface{}) (valueKey, error) {
+	switch t := v.(type) {
+	case string:
+		return valueKey{kind: valueString, s: t}, nil
+	case bool:
+		return valueKey{kind: valueBool, b: t}, nil
+	case float64:
+		if t == math.Trunc(t) && math.Abs(t) < 1<<63 {
+			if t >= 0 {
+				return valueKey{kind: valueUint, u: uint64(t)}, nil
+			}
+			return valueKey{kind: valueSint, i: int64(t)}, nil
+		}
+		return valueKey{kind: valueDouble, f: t}, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return valueKey{}, err
+	}
+	return valueKey{kind: valueString, s: string(b)}, nil
+}
+
+func encodeValue(v valueKey) []byte {
+	var e pb.Encoder
+	switch v.kind {
+	case valueString:
+		e.String(valueString, v.s)
+	case valueBool:
+		e.Bool(valueBool, v.b)
+	case valueUint:
+		e.Uint64(valueUint, v.u)
+	case valueSint:
+		e.Sint64(valueSint, v.i)
+	case valueDouble:
+		e.Double(valueDouble, v.f)
+	}
+	return e.Bytes()
+}
+
+// geometry projects, clips, and encodes g as tile commands.
+func (e *layerEncoder) geometry(g geojson.Geometry) (int, []uint32, error) {
+	switch t := g.(type) {
+	case nil:
+		return geomUnknown, nil, nil
+	case *geojson.Point:
+		return geomPoint, e.points([]geojson.Point{*t}), nil
+	case *geojson.MultiPoint:
+		return geomPoint, e.points(t.Points), nil
+	case *geojson.LineString:
+		return geomLineString, e.lines([]geojson.LineString{*t}), nil
+	case *geojson.MultiLineString:
+		return geomLineString, e.lines(t.Lines), nil
+	case *geojson.Polygon:
+		return geomPolygon, e.polygons([]geojson.Polygon{*t}), nil
+	case *geojson.MultiPolygon:
+		return geomPolygon, e.polygons(t.Polygons), nil
+	}
+	return geomUnknown, nil, fmt.Errorf("unsupported geometry type: %T", g)
+}
+
+func (e *layerEncoder) projectAll(points []geojson.Point) []xy {
+	result := make([]xy, len(points))
+	for i, p := range points {
+		result[i] = e.tile.project(p, e.extent)
+	}
+	return result
+}
+
+func (e *layerEncoder) points(points []geojson.Point) []uint32 {
+	var kept []xy
+	for _, c := range e.projectAll(points) {
+		if e.clip.contains(c) {
+			kept = append(kept, snap(c))
+		}
+	}
+	return encodePoints(kept)
+}
+
+func (e *layerEncoder) lines(lines []geojson.LineString) []uint32 {
+	var pieces [][]xy
+	for _, ls := range lines {
+		for _, piece := range clipLine(e.projectAll(ls.Points), e.clip) {
+			piece = snapPath(piece)
+			if len(piece) >= 2 {
+				pieces = append(pieces, piece)
+			}
+		}
+	}
+	return encodeLines(pieces)
+}
+
+func (e *layerEncoder) polygons(polygons []geojson.Polygon) []uint32 {
+	var rings [][]xy
+	for _, p := range polygons {
+		for i, ring := range p.Rings {
+			points := e.projectAll(ring.Points)
+			if len(points) > 0 {
+				// Drop the closing point; MVT rings are closed implicitly.
+				points = points[:len(points)-1]
+			}
+			points = snapRing(clipRing(points, e.clip))
+			area := ringArea(points)
+			if len(points) < 3 || area == 0 {
+				if i == 0 {
+					// Without an exterior ring, the holes mean nothing.
+					break
+				}
+				continue
+			}
+			// Exterior rings must have positive area and holes negative.
+			if (i == 0) != (area > 0) {
+				reverse(points)
+			}
+			rings = append(rings, points)
+		}
+	}
+	return encodeRings(rings)
+}
+
+func snap(c xy) xy {
+	return xy{math.Round(c.x), math.Round(c.y)}
+}
+
+// snapPath snaps a path to the integer grid, removing consecutive duplicate
+// positions.
+func snapPath(path []xy) []xy {
+	var result []xy
+	for _, c := range path {
+		s := snap(c)
+		if len(result) == 0 || result[len(result)-1] != s {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// snapRing is like snapPath but also treats the ring as closed.
+func snapRing(ring []xy) []xy {
+	result := snapPath(ring)
+	for len(result) > 1 && result[0] == result[len(result)-1] {
+		result = result[:len(result)-1]
+	}
+	return result
+}
+
+func reverse(points []xy) {
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+}
+
+// A commandWriter encodes geometry commands, tracking the cursor position
+// so that parameters can be delta-encoded.
+type commandWriter struct {
+	commands []uint32
+	cursor   xy
+}
+
+func command(id, count int) uint32 {
+	return uint32(id&0x7) | uint32(count)<<3
+}
+
+func (w *commandWriter) command(id, count int) {
+	w.commands = append(w.commands, command(id, count))
+}
+
+// position writes the delta-encoded parameters for c.
+func (w *commandWriter) position(c xy) {
+	dx := int64(c.x - w.cursor.x)
+	dy := int64(c.y - w.cursor.y)
+	w.commands = append(w.commands, uint32(pb.EncodeZigZag(dx)), uint32(pb.EncodeZigZag(dy)))
+	w.cursor = c
+}
+
+func encodePoints(points []xy) []uint32 {
+	if len(points) == 0 {
+		return nil
+	}
+	w := &commandWriter{}
+	w.command(cmdMoveTo, len(points))
+	for _, c := range points {
+		w.position(c)
+	}
+	return w.commands
+}
+
+func encodeLines(lines [][]xy) []uint32 {
+	w := &commandWriter{}
+	for _, line := range lines {
+		w.command(cmdMoveTo, 1)
+		w.position(line[0])
+		w.command(cmdLineTo, len(line)-1)
+		for _, c := range line[1:] {
+			w.position(c)
+		}
+	}
+	return w.commands
+}
+
+func encodeRings(rings [][]xy) []uint32 {
+	w := &commandWriter{}
+	for _, ring := range rings {
+		w.command(cmdMoveTo, 1)
+		w.position(ring[0])
+		w.command(cmdLineTo, len(ring)-1)
+		for _, c := range ring[1:] {
+			w.position(c)
+		}
+		w.command(cmdClosePath, 1)
+	}
+	return w.commands
+}
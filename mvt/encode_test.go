@@ -0,0 +1,298 @@
+package mvt
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+// Examples from section 4.3.5 of the specification.
+func TestEncodeCommands(t *testing.T) {
+	cases := []struct {
+		commands []uint32
+		expected []uint32
+	}{
+		{
+			commands: encodePoints([]xy{{25, 17}}),
+			expected: []uint32{9, 50, 34},
+		},
+		{
+			commands: encodePoints([]xy{{5, 7}, {3, 2}}),
+			expected: []uint32{17, 10, 14, 3, 9},
+		},
+		{
+			commands: encodeLines([][]xy{{{2, 2}, {2, 10}, {10, 10}}}),
+			expected: []uint32{9, 4, 4, 18, 0, 16, 16, 0},
+		},
+		{
+			commands: encodeLines([][]xy{
+				{{2, 2}, {2, 10}, {10, 10}},
+				{{1, 1}, {3, 5}},
+			}),
+			expected: []uint32{9, 4, 4, 18, 0, 16, 16, 0, 9, 17, 17, 10, 4, 8},
+		},
+		{
+			commands: encodeRings([][]xy{{{3, 6}, {8, 12}, {20, 34}}}),
+			expected: []uint32{9, 6, 12, 18, 10, 12, 24, 44, 15},
+		},
+	}
+	for i, c := range cases {
+		if !reflect.DeepEqual(c.commands, c.expected) {
+			t.Errorf("case %d: expected %v, got %v", i, c.expected, c.commands)
+		}
+	}
+}
+
+func TestProject(t *testing.T) {
+	tile := Tile{Z: 1, X: 1, Y: 0}
+	cases := []struct {
+		p        geojson.Point
+		expected xy
+	}{
+		{geojson.Point{X: 0, Y: 0}, xy{0, 4096}},
+		{geojson.Point{X: 90, Y: 0}, xy{2048, 4096}},
+		{geojson.Point{X: 180, Y: maxLatitude}, xy{4096, 0}},
+	}
+	for i, c := range cases {
+		got := tile.project(c.p, DefaultExtent)
+		if math.Abs(got.x-c.expected.x) > 1e-6 || math.Abs(got.y-c.expected.y) > 1e-6 {
+			t.Errorf("case %d: expected %v, got %v", i, c.expected, got)
+		}
+		back := tile.unproject(got, DefaultExtent)
+		if math.Abs(back.X-c.p.X) > 1e-9 || math.Abs(back.Y-c.p.Y) > 1e-9 {
+			t.Errorf("case %d: expected %v after unprojecting, got %v", i, c.p, back)
+		}
+	}
+}
+
+// approxEqual compares geometries, allowing coordinates to differ by up to
+// tolerance degrees and polygon rings to start at any vertex.
+func approxEqual(a, b geojson.Geometry, tolerance float64) bool {
+	// paths flattens a geometry, dropping the closing point of each ring.
+	paths := func(g geojson.Geometry) [][]geojson.Point {
+		var result [][]geojson.Point
+		switch t := g.(type) {
+		case *geojson.Point:
+			result = append(result, []geojson.Point{*t})
+		case *geojson.MultiPoint:
+			result = append(result, t.Points)
+		case *geojson.LineString:
+			result = append(result, t.Points)
+		case *geojson.MultiLineString:
+			for _, ls := range t.Lines {
+				result = append(result, ls.Points)
+			}
+		case *geojson.Polygon:
+			for _, r := range t.Rings {
+				result = append(result, r.Points[:len(r.Points)-1])
+			}
+		case *geojson.MultiPolygon:
+			for _, p := range t.Polygons {
+				for _, r := range p.Rings {
+					result = append(result, r.Points[:len(r.Points)-1])
+				}
+			}
+		}
+		return result
+	}
+	near := func(p, q geojson.Point) bool {
+		return math.Abs(p.X-q.X) <= tolerance && math.Abs(p.Y-q.Y) <= tolerance
+	}
+	// matches reports whether q equals p rotated by offset.
+	matches := func(p, q []geojson.Point, offset int) bool {
+		for i := range p {
+			if !near(p[i], q[(i+offset)%len(q)]) {
+				return false
+			}
+		}
+		return true
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+	_, isPolygon := a.(*geojson.Polygon)
+	_, isMultiPolygon := a.(*geojson.MultiPolygon)
+	rotate := isPolygon || isMultiPolygon
+	pa, pb := paths(a), paths(b)
+	if len(pa) != len(pb) {
+		return false
+	}
+	for i := range pa {
+		if len(pa[i]) != len(pb[i]) {
+			return false
+		}
+		found := false
+		for offset := 0; offset < len(pa[i]) && !found; offset++ {
+			found = matches(pa[i], pb[i], offset)
+			if !rotate {
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRoundTrip_Encode(t *testing.T) {
+	// Tile 10/163/396 covers roughly -122.7..-122.3 longitude and
+	// 37.4..37.7 latitude.
+	tile := Tile{Z: 10, X: 163, Y: 396}
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				ID:       "42",
+				Geometry: &geojson.Point{X: -122.5, Y: 37.6},
+				Properties: map[string]interface{}{
+					"name":   "a",
+					"rank":   float64(3),
+					"delta":  float64(-3),
+					"ratio":  0.25,
+					"public": true,
+					"skip":   nil,
+				},
+			},
+			{
+				Geometry: &geojson.MultiLineString{
+					Lines: []geojson.LineString{
+						{Points: []geojson.Point{{X: -122.6, Y: 37.5}, {X: -122.5, Y: 37.55}}},
+						{Points: []geojson.Point{{X: -122.45, Y: 37.5}, {X: -122.4, Y: 37.45}}},
+					},
+				},
+				Properties: map[string]interface{}{"name": "a"},
+			},
+			{
+				Geometry: &geojson.Polygon{
+					Rings: []geojson.LineString{
+						{Points: []geojson.Point{
+							{X: -122.6, Y: 37.5}, {X: -122.4, Y: 37.5}, {X: -122.4, Y: 37.6},
+							{X: -122.6, Y: 37.6}, {X: -122.6, Y: 37.5},
+						}},
+						{Points: []geojson.Point{
+							{X: -122.55, Y: 37.52}, {X: -122.55, Y: 37.58}, {X: -122.45, Y: 37.58},
+							{X: -122.45, Y: 37.52}, {X: -122.55, Y: 37.52},
+						}},
+					},
+				},
+				Properties: map[string]interface{}{"tags": []interface{}{"x"}},
+			},
+		},
+	}
+	b, err := Encode(tile, []Layer{{Name: "roads", Features: fc}}, nil)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	layers, err := Decode(b, tile)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if len(layers) != 1 || layers[0].Name != "roads" || layers[0].Extent != DefaultExtent {
+		t.Fatalf("unexpected layers %#v", layers)
+	}
+	got := layers[0].Features.Features
+	if len(got) != len(fc.Features) {
+		t.Fatalf("expected %d features, got %d", len(fc.Features), len(got))
+	}
+	// One tile unit at zoom 10 is well under 1e-4 degrees.
+	const tolerance = 1e-4
+	for i := range got {
+		if !approxEqual(got[i].Geometry, fc.Features[i].Geometry, tolerance) {
+			t.Errorf("feature %d: expected geometry %#v, got %#v", i, fc.Features[i].Geometry, got[i].Geometry)
+		}
+	}
+	if got[0].ID != "42" {
+		t.Errorf("expected ID 42, got %q", got[0].ID)
+	}
+	expectedProperties := []map[string]interface{}{
+		{"name": "a", "rank": float64(3), "delta": float64(-3), "ratio": 0.25, "public": true},
+		{"name": "a"},
+		{"tags": `["x"]`},
+	}
+	for i, expected := range expectedProperties {
+		if !reflect.DeepEqual(got[i].Properties, expected) {
+			t.Errorf("feature %d: expected properties %#v, got %#v", i, expected, got[i].Properties)
+		}
+	}
+}
+
+func TestEncode_Winding(t *testing.T) {
+	tile := Tile{Z: 0}
+	// Clockwise exterior and counter-clockwise hole: both wound the wrong
+	// way for RFC 7946.
+	polygon := &geojson.Polygon{
+		Rings: []geojson.LineString{
+			{Points: []geojson.Point{{X: -10, Y: -10}, {X: -10, Y: 10}, {X: 10, Y: 10}, {X: 10, Y: -10}, {X: -10, Y: -10}}},
+			{Points: []geojson.Point{{X: -5, Y: -5}, {X: 5, Y: -5}, {X: 5, Y: 5}, {X: -5, Y: 5}, {X: -5, Y: -5}}},
+		},
+	}
+	fc := &geojson.FeatureCollection{Features: []geojson.Feature{{Geometry: polygon}}}
+	b, err := Encode(tile, []Layer{{Name: "l", Features: fc}}, nil)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	layers, err := Decode(b, tile)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	got, ok := layers[0].Features.Features[0].Geometry.(*geojson.Polygon)
+	if !ok || len(got.Rings) != 2 {
+		t.Fatalf("expected Polygon with hole, got %#v", layers[0].Features.Features[0].Geometry)
+	}
+	area := func(points []geojson.Point) float64 {
+		var sum float64
+		for i := 0; i+1 < len(points); i++ {
+			sum += points[i].X*points[i+1].Y - points[i+1].X*points[i].Y
+		}
+		return sum
+	}
+	if area(got.Rings[0].Points) <= 0 {
+		t.Errorf("expected counter-clockwise exterior")
+	}
+	if area(got.Rings[1].Points) >= 0 {
+		t.Errorf("expected clockwise hole")
+	}
+}
+
+func TestEncode_Clip(t *testing.T) {
+	tile := Tile{Z: 1, X: 1, Y: 0}
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			// Entirely in another tile.
+			{Geometry: &geojson.Point{X: -90, Y: 45}},
+			// Crosses into the tile.
+			{Geometry: &geojson.LineString{Points: []geojson.Point{{X: -90, Y: 45}, {X: 90, Y: 45}}}},
+			// Split into a GeometryCollection with one member outside.
+			{
+				Geometry: &geojson.GeometryCollection{
+					Geometries: []geojson.Geometry{
+						&geojson.Point{X: 90, Y: 45},
+						&geojson.Point{X: -90, Y: -45},
+					},
+				},
+			},
+		},
+	}
+	b, err := Encode(tile, []Layer{{Name: "l", Features: fc}}, &EncodeOptions{Buffer: 64})
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	layers, err := Decode(b, tile)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	got := layers[0].Features.Features
+	if len(got) != 2 {
+		t.Fatalf("expected 2 features, got %#v", got)
+	}
+	ls, ok := got[0].Geometry.(*geojson.LineString)
+	if !ok {
+		t.Fatalf("expected LineString, got %#v", got[0].Geometry)
+	}
+	// The buffer is 64/4096 of a tile, which spans 180 degrees.
+	if start := ls.Points[0].X; math.Abs(start-(-64.0/4096*180)) > 0.1 {
+		t.Errorf("expected line to be clipped at the buffer edge, starts at %v", start)
+	}
+}
@@ -0,0 +1,80 @@
+// Package mvt encodes and decodes Mapbox Vector Tiles (version 2.1) as
+// GeoJSON features.
+//
+// Geometries are projected between WGS84 longitude/latitude and the integer
+// coordinate space of a single tile in the Web Mercator tiling scheme, which
+// is identified by its zoom level and column and row numbers. Elevations are
+// discarded because vector tiles are two-dimensional.
+//
+// See https://github.com/mapbox/vector-tile-spec for the specification.
+package mvt
+
+import (
+	"math"
+
+	"github.com/bsidhom/geojson"
+)
+
+// DefaultExtent is the number of integer units spanning a tile along each
+// axis when a layer does not specify its own extent.
+const DefaultExtent = 4096
+
+// maxLatitude is the latitude at which Web Mercator tiles end.
+const maxLatitude = 85.0511287798066
+
+// A Tile identifies a tile by zoom level Z, column X, and row Y, with row 0 at
+// the top (north) of the map.
+type Tile struct {
+	Z, X, Y uint32
+}
+
+// A Layer is a named set of features within a tile.
+type Layer struct {
+	Name string
+	// Number of integer units spanning the tile. If zero, DefaultExtent is
+	// used when encoding.
+	Extent   uint32
+	Features *geojson.FeatureCollection
+}
+
+// xy is a position in tile coordinates, where (0, 0) is the top-left corner
+// of the tile and (extent, extent) is the bottom-right corner.
+type xy struct {
+	x, y float64
+}
+
+// project converts p to tile coordinates.
+func (t Tile) project(p geojson.Point, extent float64) xy {
+	n := math.Exp2(float64(t.Z))
+	lat := math.Max(-maxLatitude, math.Min(maxLatitude, p.Y))
+	sin := math.Sin(lat * math.Pi / 180)
+	wx := (p.X + 180) / 360
+	wy := 0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)
+	return xy{
+		x: (wx*n - float64(t.X)) * extent,
+		y: (wy*n - float64(t.Y)) * extent,
+	}
+}
+
+// unproject converts tile coordinates to longitude and latitude.
+func (t Tile) unproject(c xy, extent float64) geojson.Point {
+	n := math.Exp2(float64(t.Z))
+	wx := (c.x/extent + float64(t.X)) / n
+	wy := (c.y/extent + float64(t.Y)) / n
+	return geojson.Point{
+		X: wx*360 - 180,
+		Y: math.Atan(math.Sinh(math.Pi*(1-2*wy))) * 180 / math.Pi,
+	}
+}
+
+// ringArea returns twice the signed area of a ring in tile coordinates. The
+// ring need not be explicitly closed. Since the y axis points down, rings
+// that appear clockwise on screen have positive area.
+func ringArea(ring []xy) float64 {
+	var sum float64
+	for i := range ring {
+		j := (i + 1) % len(ring)
+		sum += ring[i].x*ring[j].y - ring[j].x*ring[i].y
+	}
+	return sum
+}
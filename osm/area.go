@@ -0,0 +1,78 @@
+package osm
+
+// areaKeys lists the keys that make a closed way an area, following the
+// widely used osm-polygon-features rules. A nil entry means any value
+// other than "no" makes an area. Otherwise the entry either lists the only
+// values that do (included) or the values that do not (excluded).
+var areaKeys = map[string]*areaRule{
+	"building":         nil,
+	"highway":          included("services", "rest_area", "escape", "elevator"),
+	"natural":          excluded("coastline", "cliff", "ridge", "arete", "tree_row"),
+	"landuse":          nil,
+	"waterway":         included("riverbank", "dock", "boatyard", "dam"),
+	"amenity":          nil,
+	"leisure":          nil,
+	"barrier":          included("city_wall", "ditch", "hedge", "retaining_wall", "wall", "spikes"),
+	"railway":          included("station", "turntable", "roundhouse", "platform"),
+	"area":             nil,
+	"boundary":         nil,
+	"man_made":         excluded("cutline", "embankment", "pipeline"),
+	"power":            included("plant", "substation", "generator", "transformer"),
+	"place":            nil,
+	"shop":             nil,
+	"aeroway":          excluded("taxiway"),
+	"tourism":          nil,
+	"historic":         nil,
+	"public_transport": nil,
+	"office":           nil,
+	"building:part":    nil,
+	"military":         nil,
+	"ruins":            nil,
+	"area:highway":     nil,
+	"craft":            nil,
+	"golf":             nil,
+	"indoor":           nil,
+}
+
+type areaRule struct {
+	values   map[string]bool
+	included bool
+}
+
+func included(values ...string) *areaRule {
+	return &areaRule{values: set(values), included: true}
+}
+
+func excluded(values ...string) *areaRule {
+	return &areaRule{values: set(values)}
+}
+
+func set(values []string) map[string]bool {
+	m := make(map[string]bool, len(values))
+	for _, v := range values {
+		m[v] = true
+	}
+	return m
+}
+
+// IsArea reports whether a closed way with the given tags describes an area
+// rather than a closed line. An explicit area=yes or area=no tag takes
+// precedence over the other tags.
+func IsArea(tags map[string]string) bool {
+	switch tags["area"] {
+	case "yes":
+		return true
+	case "no":
+		return false
+	}
+	for k, v := range tags {
+		rule, ok := areaKeys[k]
+		if !ok || v == "no" {
+			continue
+		}
+		if rule == nil || rule.values[v] == rule.included {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,285 @@
+package osm
+
+import (
+	"fmt"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/ring"
+)
+
+// An Assembler resolves a stream of elements into Features. Nodes must be
+// added before the ways that use them, and ways before the relations that
+// use them, which is the order of .osm and .osm.pbf files.
+type Assembler struct {
+	nodes NodeStore
+	ways  map[int64]wayNodes
+	// retain lists the ways to hold for later relations, or is nil to hold
+	// every way.
+	retain map[int64]bool
+}
+
+// wayNodes is what an Assembler holds of a way for later relations.
+type wayNodes struct {
+	nodes     []int64
+	locations []geojson.Point
+}
+
+// NewAssembler returns an Assembler that keeps node locations in store. If
+// store is nil, a MemoryStore is used.
+//
+// By default every way is held in memory in case a later relation uses it.
+// For large inputs, use RetainWays to hold only the ways that will be
+// needed.
+func NewAssembler(store NodeStore) *Assembler {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Assembler{nodes: store, ways: map[int64]wayNodes{}}
+}
+
+// RetainWays limits the ways held for later relations to those in ids,
+// such as those collected by MemberWays.
+func (a *Assembler) RetainWays(ids map[int64]bool) {
+	a.retain = ids
+}
+
+// MemberWays adds the IDs of the ways that make up r to ids if r is
+// converted into a Feature.
+func MemberWays(r *Relation, ids map[int64]bool) {
+	if !isMultipolygon(r.Tags) {
+		return
+	}
+	for _, m := range r.Members {
+		if isRingMember(&m) {
+			ids[m.Ref] = true
+		}
+	}
+}
+
+// Add adds e and returns the Feature it resolves into, or nil if it does
+// not resolve into one. Errors are only returned by the NodeStore.
+func (a *Assembler) Add(e Element) (*geojson.Feature, error) {
+	var f *geojson.Feature
+	var err error
+	switch t := e.(type) {
+	case *Node:
+		f, err = a.node(t)
+	case *Way:
+		f, err = a.way(t)
+	case *Relation:
+		f, err = a.relation(t)
+	default:
+		return nil, fmt.Errorf("osm: unsupported element %T", e)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("osm: %s %d: %v", e.Type(), elementID(e), err)
+	}
+	return f, nil
+}
+
+func elementID(e Element) int64 {
+	switch t := e.(type) {
+	case *Node:
+		return t.ID
+	case *Way:
+		return t.ID
+	case *Relation:
+		return t.ID
+	}
+	return 0
+}
+
+// assemble resolves every element of d into a FeatureCollection, in the
+// order nodes, ways, relations.
+func assemble(d *data) (*geojson.FeatureCollection, error) {
+	a := NewAssembler(nil)
+	fc := &geojson.FeatureCollection{}
+	add := func(e Element) error {
+		f, err := a.Add(e)
+		if f != nil {
+			fc.Features = append(fc.Features, *f)
+		}
+		return err
+	}
+	for i := range d.nodes {
+		if err := add(&d.nodes[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i := range d.ways {
+		if err := add(&d.ways[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i := range d.relations {
+		if err := add(&d.relations[i]); err != nil {
+			return nil, err
+		}
+	}
+	return fc, nil
+}
+
+func (a *Assembler) node(n *Node) (*geojson.Feature, error) {
+	p := geojson.Point{X: n.Lon, Y: n.Lat}
+	err := a.nodes.Put(n.ID, p)
+	if err != nil || len(n.Tags) == 0 {
+		return nil, err
+	}
+	return &geojson.Feature{
+		ID:         featureID(NodeType, n.ID),
+		Geometry:   &p,
+		Properties: properties(n.Tags),
+	}, nil
+}
+
+func (a *Assembler) way(w *Way) (*geojson.Feature, error) {
+	if a.retain == nil || a.retain[w.ID] {
+		a.ways[w.ID] = wayNodes{nodes: w.Nodes, locations: w.Locations}
+	}
+	if len(w.Tags) == 0 {
+		return nil, nil
+	}
+	points, err := a.wayPoints(w.Nodes, w.Locations)
+	if err != nil || len(points) < 2 {
+		return nil, err
+	}
+	f := &geojson.Feature{
+		ID:         featureID(WayType, w.ID),
+		Properties: properties(w.Tags),
+	}
+	closed := len(w.Nodes) >= 4 && w.Nodes[0] == w.Nodes[len(w.Nodes)-1]
+	if closed && IsArea(w.Tags) {
+		f.Geometry = &ring.Nest([][]geojson.Point{points})[0]
+	} else {
+		f.Geometry = &geojson.LineString{Points: points}
+	}
+	return f, nil
+}
+
+// wayPoints resolves the locations of nodes, preferring their inline
+// locations if given. It returns nil if any location is unknown.
+func (a *Assembler) wayPoints(nodes []int64, locations []geojson.Point) ([]geojson.Point, error) {
+	if len(locations) == len(nodes) {
+		return locations, nil
+	}
+	points := make([]geojson.Point, len(nodes))
+	for i, id := range nodes {
+		p, ok, err := a.nodes.Get(id)
+		if err != nil || !ok {
+			return nil, err
+		}
+		points[i] = p
+	}
+	return points, nil
+}
+
+func isMultipolygon(tags map[string]string) bool {
+	switch tags["type"] {
+	case "multipolygon", "boundary":
+		return true
+	}
+	return false
+}
+
+func isRingMember(m *Member) bool {
+	if m.Type != WayType {
+		return false
+	}
+	switch m.Role {
+	case "outer", "inner", "":
+		return true
+	}
+	return false
+}
+
+func (a *Assembler) relation(r *Relation) (*geojson.Feature, error) {
+	if !isMultipolygon(r.Tags) {
+		return nil, nil
+	}
+	var lines [][]geojson.Point
+	for i := range r.Members {
+		m := &r.Members[i]
+		if !isRingMember(m) {
+			continue
+		}
+		var points []geojson.Point
+		if w, ok := a.ways[m.Ref]; ok {
+			var err error
+			points, err = a.wayPoints(w.nodes, w.locations)
+			if err != nil || points == nil {
+				return nil, err
+			}
+		} else if m.Locations != nil {
+			points = m.Locations
+		} else {
+			return nil, nil
+		}
+		if len(points) >= 2 {
+			lines = append(lines, points)
+		}
+	}
+	rings, ok := stitch(lines)
+	if !ok || len(rings) == 0 {
+		return nil, nil
+	}
+	return &geojson.Feature{
+		ID:         featureID(RelationType, r.ID),
+		Geometry:   &geojson.MultiPolygon{Polygons: ring.Nest(rings)},
+		Properties: properties(r.Tags),
+	}, nil
+}
+
+// stitch joins lines end to end into closed rings, reversing lines as
+// needed. It reports false if some lines cannot be closed.
+func stitch(lines [][]geojson.Point) ([][]geojson.Point, bool) {
+	// ends maps each endpoint to the lines that start or end there.
+	ends := map[geojson.Point][]int{}
+	for i, line := range lines {
+		first, last := line[0], line[len(line)-1]
+		ends[first] = append(ends[first], i)
+		if last != first {
+			ends[last] = append(ends[last], i)
+		}
+	}
+	used := make([]bool, len(lines))
+	var rings [][]geojson.Point
+	for i, line := range lines {
+		if used[i] {
+			continue
+		}
+		used[i] = true
+		r := append([]geojson.Point(nil), line...)
+		for r[0] != r[len(r)-1] {
+			end := r[len(r)-1]
+			next := -1
+			for _, j := range ends[end] {
+				if !used[j] {
+					next = j
+					break
+				}
+			}
+			if next < 0 {
+				return nil, false
+			}
+			used[next] = true
+			points := lines[next]
+			if points[0] != end {
+				points = reversed(points)
+			}
+			r = append(r, points[1:]...)
+		}
+		if len(r) < 4 {
+			return nil, false
+		}
+		rings = append(rings, r)
+	}
+	return rings, true
+}
+
+func reversed(points []geojson.Point) []geojson.Point {
+	r := make([]geojson.Point, len(points))
+	for i, p := range points {
+		r[len(points)-1-i] = p
+	}
+	return r
+}
@@ -0,0 +1,130 @@
+package osm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func square(id int64, x, y, size float64) []Node {
+	return []Node{
+		{ID: id, Lon: x, Lat: y},
+		{ID: id + 1, Lon: x + size, Lat: y},
+		{ID: id + 2, Lon: x + size, Lat: y + size},
+		{ID: id + 3, Lon: x, Lat: y + size},
+	}
+}
+
+func TestAssemble_Multipolygon(t *testing.T) {
+	var d data
+	d.nodes = append(d.nodes, square(1, 0, 0, 10)...)
+	d.nodes = append(d.nodes, square(11, 2, 2, 6)...)
+	d.nodes = append(d.nodes, square(21, 4, 4, 2)...)
+	d.nodes = append(d.nodes, square(31, 20, 0, 1)...)
+	d.ways = []Way{
+		// The outer ring is split in two, with the second half reversed.
+		{ID: 100, Nodes: []int64{1, 2, 3}},
+		{ID: 101, Nodes: []int64{1, 4, 3}},
+		// A hole, wound counter-clockwise.
+		{ID: 102, Nodes: []int64{11, 12, 13, 14, 11}},
+		// An island inside the hole.
+		{ID: 103, Nodes: []int64{21, 22, 23, 24, 21}},
+		// A separate outer ring, with a tagged way of its own.
+		{ID: 104, Nodes: []int64{31, 32, 33, 34, 31}, Tags: map[string]string{"natural": "wood"}},
+	}
+	d.relations = []Relation{
+		{
+			ID: 200,
+			Members: []Member{
+				{Type: WayType, Ref: 100, Role: "outer"},
+				{Type: WayType, Ref: 102, Role: "inner"},
+				{Type: WayType, Ref: 101, Role: "outer"},
+				{Type: WayType, Ref: 103, Role: "outer"},
+				{Type: WayType, Ref: 104, Role: ""},
+				{Type: NodeType, Ref: 1, Role: "label"},
+			},
+			Tags: map[string]string{"type": "multipolygon", "landuse": "forest"},
+		},
+		{
+			// Incomplete relations are skipped.
+			ID:      201,
+			Members: []Member{{Type: WayType, Ref: 999, Role: "outer"}},
+			Tags:    map[string]string{"type": "multipolygon"},
+		},
+		{
+			// Unclosed rings are skipped.
+			ID:      202,
+			Members: []Member{{Type: WayType, Ref: 100, Role: "outer"}},
+			Tags:    map[string]string{"type": "multipolygon"},
+		},
+		{
+			ID:      203,
+			Members: []Member{{Type: WayType, Ref: 104, Role: "outer"}},
+			Tags:    map[string]string{"type": "route"},
+		},
+	}
+	fc, err := assemble(&d)
+	if err != nil {
+		t.Fatalf("failed to assemble: %v", err)
+	}
+
+	ring := func(points ...geojson.Point) geojson.LineString {
+		return geojson.LineString{Points: points}
+	}
+	expected := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				ID: "way/104",
+				Geometry: &geojson.Polygon{Rings: []geojson.LineString{
+					ring(geojson.Point{X: 20, Y: 0}, geojson.Point{X: 21, Y: 0}, geojson.Point{X: 21, Y: 1}, geojson.Point{X: 20, Y: 1}, geojson.Point{X: 20, Y: 0}),
+				}},
+				Properties: map[string]interface{}{"natural": "wood"},
+			},
+			{
+				ID: "relation/200",
+				Geometry: &geojson.MultiPolygon{Polygons: []geojson.Polygon{
+					{Rings: []geojson.LineString{
+						ring(geojson.Point{X: 0, Y: 0}, geojson.Point{X: 10, Y: 0}, geojson.Point{X: 10, Y: 10}, geojson.Point{X: 0, Y: 10}, geojson.Point{X: 0, Y: 0}),
+						ring(geojson.Point{X: 2, Y: 2}, geojson.Point{X: 2, Y: 8}, geojson.Point{X: 8, Y: 8}, geojson.Point{X: 8, Y: 2}, geojson.Point{X: 2, Y: 2}),
+					}},
+					{Rings: []geojson.LineString{
+						ring(geojson.Point{X: 4, Y: 4}, geojson.Point{X: 6, Y: 4}, geojson.Point{X: 6, Y: 6}, geojson.Point{X: 4, Y: 6}, geojson.Point{X: 4, Y: 4}),
+					}},
+					{Rings: []geojson.LineString{
+						ring(geojson.Point{X: 20, Y: 0}, geojson.Point{X: 21, Y: 0}, geojson.Point{X: 21, Y: 1}, geojson.Point{X: 20, Y: 1}, geojson.Point{X: 20, Y: 0}),
+					}},
+				}},
+				Properties: map[string]interface{}{"type": "multipolygon", "landuse": "forest"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(fc, expected) {
+		t.Errorf("expected %#v, got %#v", expected, fc)
+	}
+}
+
+func TestIsArea(t *testing.T) {
+	cases := []struct {
+		tags     map[string]string
+		expected bool
+	}{
+		{map[string]string{"building": "yes"}, true},
+		{map[string]string{"building": "no"}, false},
+		{map[string]string{"highway": "residential"}, false},
+		{map[string]string{"highway": "pedestrian", "area": "yes"}, true},
+		{map[string]string{"highway": "services"}, true},
+		{map[string]string{"natural": "wood"}, true},
+		{map[string]string{"natural": "coastline"}, false},
+		{map[string]string{"barrier": "fence"}, false},
+		{map[string]string{"barrier": "hedge"}, true},
+		{map[string]string{"leisure": "park", "area": "no"}, false},
+		{map[string]string{"name": "Loop"}, false},
+		{nil, false},
+	}
+	for i, c := range cases {
+		if got := IsArea(c.tags); got != c.expected {
+			t.Errorf("case %d: expected %v for %v, got %v", i, c.expected, c.tags, got)
+		}
+	}
+}
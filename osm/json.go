@@ -0,0 +1,85 @@
+package osm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/bsidhom/geojson"
+)
+
+type jsonLocation struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type jsonElement struct {
+	Type    string            `json:"type"`
+	ID      int64             `json:"id"`
+	Lat     float64           `json:"lat"`
+	Lon     float64           `json:"lon"`
+	Tags    map[string]string `json:"tags"`
+	Nodes   []int64           `json:"nodes"`
+	Members []struct {
+		Type     string          `json:"type"`
+		Ref      int64           `json:"ref"`
+		Role     string          `json:"role"`
+		Geometry []*jsonLocation `json:"geometry"`
+	} `json:"members"`
+	Geometry []*jsonLocation `json:"geometry"`
+}
+
+// DecodeJSON reads the JSON output of the Overpass API and resolves its
+// elements into a FeatureCollection. Elements may appear in any order, and
+// other element types, such as Overpass areas, are ignored.
+//
+// Inline geometries from "out geom" output are used for ways and relation
+// members whose nodes or ways are not part of the output. Inline
+// geometries that are incomplete because they were clipped to a bounding
+// box are ignored.
+func DecodeJSON(r io.Reader) (*geojson.FeatureCollection, error) {
+	var doc struct {
+		Elements []jsonElement `json:"elements"`
+	}
+	err := json.NewDecoder(r).Decode(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("osm: %v", err)
+	}
+	var all data
+	for _, e := range doc.Elements {
+		switch e.Type {
+		case "node":
+			all.nodes = append(all.nodes, Node{ID: e.ID, Lon: e.Lon, Lat: e.Lat, Tags: e.Tags})
+		case "way":
+			all.ways = append(all.ways, Way{ID: e.ID, Nodes: e.Nodes, Tags: e.Tags, Locations: jsonGeometry(e.Geometry)})
+		case "relation":
+			r := Relation{ID: e.ID, Tags: e.Tags}
+			for _, m := range e.Members {
+				r.Members = append(r.Members, Member{
+					Type:      ElementType(m.Type),
+					Ref:       m.Ref,
+					Role:      m.Role,
+					Locations: jsonGeometry(m.Geometry),
+				})
+			}
+			all.relations = append(all.relations, r)
+		}
+	}
+	return assemble(&all)
+}
+
+// jsonGeometry converts an inline geometry, returning nil if any of its
+// locations are missing.
+func jsonGeometry(locations []*jsonLocation) []geojson.Point {
+	if len(locations) == 0 {
+		return nil
+	}
+	points := make([]geojson.Point, len(locations))
+	for i, l := range locations {
+		if l == nil {
+			return nil
+		}
+		points[i] = geojson.Point{X: l.Lon, Y: l.Lat}
+	}
+	return points
+}
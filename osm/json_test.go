@@ -0,0 +1,101 @@
+package osm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	// The nodes follow the ways that use them, as in the output of a
+	// recurse-down query.
+	s := `{
+  "version": 0.6,
+  "elements": [
+    {"type": "way", "id": 20, "nodes": [1, 2, 3], "tags": {"waterway": "stream"}},
+    {"type": "way", "id": 21, "nodes": [7, 8, 9, 7], "tags": {"leisure": "park"},
+     "geometry": [{"lat": 0, "lon": 0}, {"lat": 0, "lon": 1}, {"lat": 1, "lon": 1}, {"lat": 0, "lon": 0}]},
+    {"type": "way", "id": 22, "nodes": [7, 8], "tags": {"highway": "path"},
+     "geometry": [{"lat": 0, "lon": 0}, null]},
+    {"type": "node", "id": 1, "lat": 1, "lon": 2},
+    {"type": "node", "id": 2, "lat": 3, "lon": 4},
+    {"type": "node", "id": 3, "lat": 5, "lon": 6, "tags": {"natural": "spring"}},
+    {"type": "area", "id": 3600000001, "tags": {"name": "Nowhere"}}
+  ]
+}`
+	fc, err := DecodeJSON(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	expected := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				ID:         "node/3",
+				Geometry:   &geojson.Point{X: 6, Y: 5},
+				Properties: map[string]interface{}{"natural": "spring"},
+			},
+			{
+				ID: "way/20",
+				Geometry: &geojson.LineString{Points: []geojson.Point{
+					{X: 2, Y: 1}, {X: 4, Y: 3}, {X: 6, Y: 5},
+				}},
+				Properties: map[string]interface{}{"waterway": "stream"},
+			},
+			{
+				ID: "way/21",
+				Geometry: &geojson.Polygon{Rings: []geojson.LineString{{Points: []geojson.Point{
+					{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0},
+				}}}},
+				Properties: map[string]interface{}{"leisure": "park"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(fc, expected) {
+		t.Errorf("expected %#v, got %#v", expected, fc)
+	}
+}
+
+func TestDecodeJSON_MemberGeometry(t *testing.T) {
+	s := `{"elements": [
+  {"type": "relation", "id": 30, "tags": {"type": "multipolygon", "natural": "water"}, "members": [
+    {"type": "way", "ref": 1, "role": "outer", "geometry": [{"lat": 0, "lon": 0}, {"lat": 0, "lon": 2}, {"lat": 2, "lon": 2}]},
+    {"type": "way", "ref": 2, "role": "outer", "geometry": [{"lat": 0, "lon": 0}, {"lat": 2, "lon": 0}, {"lat": 2, "lon": 2}]}
+  ]}
+]}`
+	fc, err := DecodeJSON(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	expected := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				ID: "relation/30",
+				Geometry: &geojson.MultiPolygon{Polygons: []geojson.Polygon{
+					{Rings: []geojson.LineString{{Points: []geojson.Point{
+						{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 0, Y: 2}, {X: 0, Y: 0},
+					}}}},
+				}},
+				Properties: map[string]interface{}{"type": "multipolygon", "natural": "water"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(fc, expected) {
+		t.Errorf("expected %#v, got %#v", expected, fc)
+	}
+}
+
+func TestDecodeJSON_Invalid(t *testing.T) {
+	cases := []string{
+		``,
+		`{"elements": {}}`,
+		`{"elements": [{"type": "node", "id": "1"}]}`,
+	}
+	for i, c := range cases {
+		_, err := DecodeJSON(strings.NewReader(c))
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
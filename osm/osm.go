@@ -0,0 +1,103 @@
+// Package osm converts OpenStreetMap data into GeoJSON FeatureCollections.
+// The OSM XML format of .osm files, the JSON output of the Overpass API, and
+// the .osm.pbf binary format are read.
+//
+// Nodes, ways, and relations are resolved into Features as follows:
+//   - Tagged nodes become Points.
+//   - Tagged ways become LineStrings, or Polygons if they are closed and
+//     their tags describe an area (see IsArea).
+//   - Relations of type multipolygon or boundary become MultiPolygons. Their
+//     member ways are stitched end to end into rings, which are grouped into
+//     polygons by containment; member roles are not relied upon.
+//
+// Other relations and untagged elements are not converted. Elements that
+// refer to nodes or ways missing from the input, as is common in bounding
+// box extracts, are skipped. Feature IDs take the form "node/1", "way/2", or
+// "relation/3", and tags are stored as string properties.
+//
+// Large .osm.pbf files can be streamed with a PBFReader and an Assembler,
+// or with ScanPBF, which keeps node locations in a NodeStore of the
+// caller's choosing.
+package osm
+
+import (
+	"strconv"
+
+	"github.com/bsidhom/geojson"
+)
+
+// An ElementType identifies the kind of an OSM element.
+type ElementType string
+
+const (
+	NodeType     ElementType = "node"
+	WayType      ElementType = "way"
+	RelationType ElementType = "relation"
+)
+
+// An Element is a *Node, *Way, or *Relation.
+type Element interface {
+	Type() ElementType
+}
+
+// A Node is a point with a location.
+type Node struct {
+	ID       int64
+	Lon, Lat float64
+	Tags     map[string]string
+}
+
+// A Way is an ordered list of nodes.
+type Way struct {
+	ID    int64
+	Nodes []int64
+	Tags  map[string]string
+	// Locations optionally holds the locations of Nodes, as given by
+	// Overpass "out geom" output and by PBF files with locations on ways.
+	Locations []geojson.Point
+}
+
+// A Member is an element referenced by a Relation.
+type Member struct {
+	Type ElementType
+	Ref  int64
+	Role string
+	// Locations optionally holds the node locations of a way member, as
+	// given by Overpass "out geom" output.
+	Locations []geojson.Point
+}
+
+// A Relation is an ordered list of elements with roles.
+type Relation struct {
+	ID      int64
+	Members []Member
+	Tags    map[string]string
+}
+
+// Type returns NodeType.
+func (*Node) Type() ElementType { return NodeType }
+
+// Type returns WayType.
+func (*Way) Type() ElementType { return WayType }
+
+// Type returns RelationType.
+func (*Relation) Type() ElementType { return RelationType }
+
+// data holds every element of a document, by type and in document order.
+type data struct {
+	nodes     []Node
+	ways      []Way
+	relations []Relation
+}
+
+func featureID(t ElementType, id int64) string {
+	return string(t) + "/" + strconv.FormatInt(id, 10)
+}
+
+func properties(tags map[string]string) map[string]interface{} {
+	p := make(map[string]interface{}, len(tags))
+	for k, v := range tags {
+		p[k] = v
+	}
+	return p
+}
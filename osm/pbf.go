@@ -0,0 +1,650 @@
+package osm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/pb"
+)
+
+// Limits from the .osm.pbf specification.
+const (
+	maxBlobHeaderSize = 64 * 1024
+	maxBlobSize       = 32 * 1024 * 1024
+)
+
+// Field numbers of the fileformat.proto and osmformat.proto messages.
+const (
+	blobHeaderType     = 1
+	blobHeaderDataSize = 3
+
+	blobRaw       = 1
+	blobRawSize   = 2
+	blobZlibData  = 3
+	blobLzmaData  = 4
+	blobBzip2Data = 5
+	blobLz4Data   = 6
+	blobZstdData  = 7
+
+	headerRequiredFeatures = 4
+
+	blockStringTable = 1
+	blockGroups      = 2
+	blockGranularity = 17
+	blockLatOffset   = 19
+	blockLonOffset   = 20
+
+	stringTableS = 1
+
+	groupNodes     = 1
+	groupDense     = 2
+	groupWays      = 3
+	groupRelations = 4
+
+	primitiveID   = 1
+	primitiveKeys = 2
+	primitiveVals = 3
+
+	nodeLat = 8
+	nodeLon = 9
+
+	denseID       = 1
+	denseLat      = 8
+	denseLon      = 9
+	denseKeysVals = 10
+
+	wayRefs = 8
+	wayLat  = 9
+	wayLon  = 10
+
+	relationRolesSID = 8
+	relationMemIDs   = 9
+	relationTypes    = 10
+)
+
+// supportedFeatures lists the required features that PBFReader
+// understands.
+var supportedFeatures = map[string]bool{
+	"OsmSchema-V0.6": true,
+	"DenseNodes":     true,
+}
+
+// A PBFReader reads elements from an .osm.pbf file one block at a time.
+// Only raw and zlib-compressed blocks are supported.
+type PBFReader struct {
+	r       io.Reader
+	pending []Element
+	// relationsOnly skips the nodes and ways of every block.
+	relationsOnly bool
+}
+
+// NewPBFReader reads the header of the .osm.pbf file r and returns a
+// PBFReader for its elements. It returns an error if the file requires
+// features that PBFReader does not support, such as history.
+func NewPBFReader(r io.Reader) (*PBFReader, error) {
+	p := &PBFReader{r: r}
+	typ, b, err := p.readBlob()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("osm: %v", err)
+	}
+	if typ != "OSMHeader" {
+		return nil, fmt.Errorf("osm: expected OSMHeader block, got %q", typ)
+	}
+	d := pb.NewDecoder(b)
+	for d.Next() {
+		if d.Field() != headerRequiredFeatures {
+			continue
+		}
+		feature := d.String()
+		if d.Err() == nil && !supportedFeatures[feature] {
+			return nil, fmt.Errorf("osm: unsupported required feature %q", feature)
+		}
+	}
+	if err := d.Err(); err != nil {
+		return nil, fmt.Errorf("osm: header: %v", err)
+	}
+	return p, nil
+}
+
+// Next returns the next element of the file, or io.EOF at the end.
+func (p *PBFReader) Next() (Element, error) {
+	for len(p.pending) == 0 {
+		typ, b, err := p.readBlob()
+		if err != nil {
+			if err != io.EOF {
+				err = fmt.Errorf("osm: %v", err)
+			}
+			return nil, err
+		}
+		if typ != "OSMData" {
+			continue
+		}
+		p.pending, err = p.decodeBlock(b)
+		if err != nil {
+			return nil, fmt.Errorf("osm: %v", err)
+		}
+	}
+	e := p.pending[0]
+	p.pending = p.pending[1:]
+	return e, nil
+}
+
+// readBlob reads the next blob and returns its type and uncompressed
+// contents. It returns io.EOF only at a clean end of file.
+func (p *PBFReader) readBlob() (string, []byte, error) {
+	var size [4]byte
+	_, err := io.ReadFull(p.r, size[:])
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return "", nil, fmt.Errorf("truncated blob header size")
+		}
+		return "", nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > maxBlobHeaderSize {
+		return "", nil, fmt.Errorf("blob header too large: %d bytes", n)
+	}
+	header, err := p.read(int(n))
+	if err != nil {
+		return "", nil, err
+	}
+	var typ string
+	var dataSize uint64
+	d := pb.NewDecoder(header)
+	for d.Next() {
+		switch d.Field() {
+		case blobHeaderType:
+			typ = d.String()
+		case blobHeaderDataSize:
+			dataSize = d.Uint64()
+		}
+	}
+	if err := d.Err(); err != nil {
+		return "", nil, fmt.Errorf("blob header: %v", err)
+	}
+	if dataSize > maxBlobSize {
+		return "", nil, fmt.Errorf("blob too large: %d bytes", dataSize)
+	}
+	blob, err := p.read(int(dataSize))
+	if err != nil {
+		return "", nil, err
+	}
+	b, err := decodeBlob(blob)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s blob: %v", typ, err)
+	}
+	return typ, b, nil
+}
+
+func (p *PBFReader) read(n int) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := io.ReadFull(p.r, b)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("truncated blob")
+	}
+	return b, err
+}
+
+func decodeBlob(b []byte) ([]byte, error) {
+	var raw, compressed []byte
+	var rawSize uint64
+	d := pb.NewDecoder(b)
+	for d.Next() {
+		switch d.Field() {
+		case blobRaw:
+			raw = d.Embedded()
+		case blobRawSize:
+			rawSize = d.Uint64()
+		case blobZlibData:
+			compressed = d.Embedded()
+		case blobLzmaData, blobBzip2Data, blobLz4Data, blobZstdData:
+			return nil, fmt.Errorf("unsupported compression (field %d)", d.Field())
+		}
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	if raw != nil {
+		return raw, nil
+	}
+	if compressed == nil {
+		return nil, fmt.Errorf("missing data")
+	}
+	if rawSize > maxBlobSize {
+		return nil, fmt.Errorf("uncompressed size too large: %d bytes", rawSize)
+	}
+	z, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	out, err := ioutil.ReadAll(io.LimitReader(z, maxBlobSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > maxBlobSize {
+		return nil, fmt.Errorf("uncompressed data too large")
+	}
+	return out, nil
+}
+
+// block holds the decoding parameters of a PrimitiveBlock.
+type block struct {
+	strings     []string
+	granularity int64
+	latOffset   int64
+	lonOffset   int64
+}
+
+func (b *block) string(i uint64) (string, error) {
+	if i >= uint64(len(b.strings)) {
+		return "", fmt.Errorf("string index %d out of range", i)
+	}
+	return b.strings[i], nil
+}
+
+func (b *block) tags(keys, vals []uint64) (map[string]string, error) {
+	if len(keys) != len(vals) {
+		return nil, fmt.Errorf("%d keys but %d values", len(keys), len(vals))
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	tags := make(map[string]string, len(keys))
+	for i := range keys {
+		k, err := b.string(keys[i])
+		if err != nil {
+			return nil, err
+		}
+		v, err := b.string(vals[i])
+		if err != nil {
+			return nil, err
+		}
+		tags[k] = v
+	}
+	return tags, nil
+}
+
+// location converts fixed-point coordinates to degrees. Dividing the exact
+// nanodegree value gives the closest float64 to the decimal value.
+func (b *block) location(lat, lon int64) geojson.Point {
+	return geojson.Point{
+		X: float64(b.lonOffset+b.granularity*lon) / 1e9,
+		Y: float64(b.latOffset+b.granularity*lat) / 1e9,
+	}
+}
+
+func (p *PBFReader) decodeBlock(data []byte) ([]Element, error) {
+	blk := &block{granularity: 100}
+	var groups [][]byte
+	d := pb.NewDecoder(data)
+	for d.Next() {
+		switch d.Field() {
+		case blockStringTable:
+			st := pb.NewDecoder(d.Embedded())
+			for st.Next() {
+				if st.Field() == stringTableS {
+					blk.strings = append(blk.strings, st.String())
+				}
+			}
+			if err := st.Err(); err != nil {
+				return nil, fmt.Errorf("string table: %v", err)
+			}
+		case blockGroups:
+			groups = append(groups, d.Embedded())
+		case blockGranularity:
+			blk.granularity = d.Int64()
+		case blockLatOffset:
+			blk.latOffset = d.Int64()
+		case blockLonOffset:
+			blk.lonOffset = d.Int64()
+		}
+	}
+	if err := d.Err(); err != nil {
+		return nil, fmt.Errorf("block: %v", err)
+	}
+
+	var elements []Element
+	for _, group := range groups {
+		d := pb.NewDecoder(group)
+		for d.Next() {
+			var err error
+			switch d.Field() {
+			case groupNodes:
+				if p.relationsOnly {
+					continue
+				}
+				var n *Node
+				n, err = blk.decodeNode(d.Embedded())
+				if n != nil {
+					elements = append(elements, n)
+				}
+			case groupDense:
+				if p.relationsOnly {
+					continue
+				}
+				elements, err = blk.decodeDense(d.Embedded(), elements)
+			case groupWays:
+				if p.relationsOnly {
+					continue
+				}
+				var w *Way
+				w, err = blk.decodeWay(d.Embedded())
+				if w != nil {
+					elements = append(elements, w)
+				}
+			case groupRelations:
+				var r *Relation
+				r, err = blk.decodeRelation(d.Embedded())
+				if r != nil {
+					elements = append(elements, r)
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := d.Err(); err != nil {
+			return nil, fmt.Errorf("group: %v", err)
+		}
+	}
+	return elements, nil
+}
+
+func (b *block) decodeNode(data []byte) (*Node, error) {
+	n := &Node{}
+	var keys, vals []uint64
+	var lat, lon int64
+	d := pb.NewDecoder(data)
+	for d.Next() {
+		switch d.Field() {
+		case primitiveID:
+			n.ID = d.Sint64()
+		case primitiveKeys:
+			keys = d.Uint64s(keys)
+		case primitiveVals:
+			vals = d.Uint64s(vals)
+		case nodeLat:
+			lat = d.Sint64()
+		case nodeLon:
+			lon = d.Sint64()
+		}
+	}
+	if err := d.Err(); err != nil {
+		return nil, fmt.Errorf("node: %v", err)
+	}
+	p := b.location(lat, lon)
+	n.Lon, n.Lat = p.X, p.Y
+	var err error
+	n.Tags, err = b.tags(keys, vals)
+	if err != nil {
+		return nil, fmt.Errorf("node %d: %v", n.ID, err)
+	}
+	return n, nil
+}
+
+// decodeDense decodes a DenseNodes message, whose IDs and coordinates are
+// delta coded and whose tags are a single list of key and value indexes
+// with each node's tags terminated by 0.
+func (b *block) decodeDense(data []byte, elements []Element) ([]Element, error) {
+	var ids, lats, lons []int64
+	var keysVals []uint64
+	d := pb.NewDecoder(data)
+	for d.Next() {
+		switch d.Field() {
+		case denseID:
+			ids = d.Sint64s(ids)
+		case denseLat:
+			lats = d.Sint64s(lats)
+		case denseLon:
+			lons = d.Sint64s(lons)
+		case denseKeysVals:
+			keysVals = d.Uint64s(keysVals)
+		}
+	}
+	if err := d.Err(); err != nil {
+		return nil, fmt.Errorf("dense nodes: %v", err)
+	}
+	if len(lats) != len(ids) || len(lons) != len(ids) {
+		return nil, fmt.Errorf("dense nodes: %d IDs but %d latitudes and %d longitudes", len(ids), len(lats), len(lons))
+	}
+	var id, lat, lon int64
+	kv := 0
+	for i := range ids {
+		id += ids[i]
+		lat += lats[i]
+		lon += lons[i]
+		p := b.location(lat, lon)
+		n := &Node{ID: id, Lon: p.X, Lat: p.Y}
+		// Blocks without any tags may omit keys_vals entirely.
+		if len(keysVals) > 0 {
+			for {
+				if kv >= len(keysVals) {
+					return nil, fmt.Errorf("node %d: unterminated tags", id)
+				}
+				if keysVals[kv] == 0 {
+					kv++
+					break
+				}
+				if kv+1 >= len(keysVals) {
+					return nil, fmt.Errorf("node %d: key without value", id)
+				}
+				k, err := b.string(keysVals[kv])
+				if err != nil {
+					return nil, fmt.Errorf("node %d: %v", id, err)
+				}
+				v, err := b.string(keysVals[kv+1])
+				if err != nil {
+					return nil, fmt.Errorf("node %d: %v", id, err)
+				}
+				if n.Tags == nil {
+					n.Tags = map[string]string{}
+				}
+				n.Tags[k] = v
+				kv += 2
+			}
+		}
+		elements = append(elements, n)
+	}
+	return elements, nil
+}
+
+func (b *block) decodeWay(data []byte) (*Way, error) {
+	w := &Way{}
+	var keys, vals []uint64
+	var refs, lats, lons []int64
+	d := pb.NewDecoder(data)
+	for d.Next() {
+		switch d.Field() {
+		case primitiveID:
+			w.ID = d.Int64()
+		case primitiveKeys:
+			keys = d.Uint64s(keys)
+		case primitiveVals:
+			vals = d.Uint64s(vals)
+		case wayRefs:
+			refs = d.Sint64s(refs)
+		case wayLat:
+			lats = d.Sint64s(lats)
+		case wayLon:
+			lons = d.Sint64s(lons)
+		}
+	}
+	if err := d.Err(); err != nil {
+		return nil, fmt.Errorf("way: %v", err)
+	}
+	var err error
+	w.Tags, err = b.tags(keys, vals)
+	if err != nil {
+		return nil, fmt.Errorf("way %d: %v", w.ID, err)
+	}
+	w.Nodes = make([]int64, len(refs))
+	var ref int64
+	for i, delta := range refs {
+		ref += delta
+		w.Nodes[i] = ref
+	}
+	if len(lats) > 0 {
+		if len(lats) != len(refs) || len(lons) != len(refs) {
+			return nil, fmt.Errorf("way %d: %d refs but %d latitudes and %d longitudes", w.ID, len(refs), len(lats), len(lons))
+		}
+		w.Locations = make([]geojson.Point, len(refs))
+		var lat, lon int64
+		for i := range refs {
+			lat += lats[i]
+			lon += lons[i]
+			w.Locations[i] = b.location(lat, lon)
+		}
+	}
+	return w, nil
+}
+
+func (b *block) decodeRelation(data []byte) (*Relation, error) {
+	r := &Relation{}
+	var keys, vals, roles, types []uint64
+	var memIDs []int64
+	d := pb.NewDecoder(data)
+	for d.Next() {
+		switch d.Field() {
+		case primitiveID:
+			r.ID = d.Int64()
+		case primitiveKeys:
+			keys = d.Uint64s(keys)
+		case primitiveVals:
+			vals = d.Uint64s(vals)
+		case relationRolesSID:
+			roles = d.Uint64s(roles)
+		case relationMemIDs:
+			memIDs = d.Sint64s(memIDs)
+		case relationTypes:
+			types = d.Uint64s(types)
+		}
+	}
+	if err := d.Err(); err != nil {
+		return nil, fmt.Errorf("relation: %v", err)
+	}
+	var err error
+	r.Tags, err = b.tags(keys, vals)
+	if err != nil {
+		return nil, fmt.Errorf("relation %d: %v", r.ID, err)
+	}
+	if len(roles) != len(memIDs) || len(types) != len(memIDs) {
+		return nil, fmt.Errorf("relation %d: %d members but %d roles and %d types", r.ID, len(memIDs), len(roles), len(types))
+	}
+	r.Members = make([]Member, len(memIDs))
+	var ref int64
+	for i := range memIDs {
+		ref += memIDs[i]
+		role, err := b.string(roles[i])
+		if err != nil {
+			return nil, fmt.Errorf("relation %d: %v", r.ID, err)
+		}
+		m := Member{Ref: ref, Role: role}
+		switch types[i] {
+		case 0:
+			m.Type = NodeType
+		case 1:
+			m.Type = WayType
+		case 2:
+			m.Type = RelationType
+		default:
+			return nil, fmt.Errorf("relation %d: unknown member type %d", r.ID, types[i])
+		}
+		r.Members[i] = m
+	}
+	return r, nil
+}
+
+// DecodePBF reads an .osm.pbf file and resolves its elements into a
+// FeatureCollection, holding everything in memory. Use ScanPBF for large
+// files.
+func DecodePBF(r io.Reader) (*geojson.FeatureCollection, error) {
+	p, err := NewPBFReader(r)
+	if err != nil {
+		return nil, err
+	}
+	a := NewAssembler(nil)
+	fc := &geojson.FeatureCollection{}
+	for {
+		e, err := p.Next()
+		if err == io.EOF {
+			return fc, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		f, err := a.Add(e)
+		if err != nil {
+			return nil, err
+		}
+		if f != nil {
+			fc.Features = append(fc.Features, *f)
+		}
+	}
+}
+
+// ScanPBF reads an .osm.pbf file and calls fn with each Feature as it is
+// resolved, stopping at the first error from fn. Node locations are kept in
+// store, or in a MemoryStore if store is nil.
+//
+// The file is read twice: first to find the ways used by relations, and
+// then to assemble Features, holding only those ways in memory. Combined
+// with a FileStore, this keeps memory use modest even for large countries.
+func ScanPBF(r io.ReadSeeker, store NodeStore, fn func(*geojson.Feature) error) error {
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("osm: %v", err)
+	}
+	p, err := NewPBFReader(r)
+	if err != nil {
+		return err
+	}
+	p.relationsOnly = true
+	ways := map[int64]bool{}
+	for {
+		e, err := p.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		MemberWays(e.(*Relation), ways)
+	}
+
+	_, err = r.Seek(start, io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("osm: %v", err)
+	}
+	p, err = NewPBFReader(r)
+	if err != nil {
+		return err
+	}
+	a := NewAssembler(store)
+	a.RetainWays(ways)
+	for {
+		e, err := p.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		f, err := a.Add(e)
+		if err != nil {
+			return err
+		}
+		if f != nil {
+			if err := fn(f); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package osm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/pb"
+)
+
+// appendBlob appends a blob of the given type to b, compressing it with
+// zlib if compress is set.
+func appendBlob(b []byte, typ string, data []byte, compress bool) []byte {
+	var blob pb.Encoder
+	if compress {
+		var z bytes.Buffer
+		w := zlib.NewWriter(&z)
+		w.Write(data)
+		w.Close()
+		blob.Uint64(blobRawSize, uint64(len(data)))
+		blob.Embedded(blobZlibData, z.Bytes())
+	} else {
+		blob.Embedded(blobRaw, data)
+	}
+	var header pb.Encoder
+	header.String(blobHeaderType, typ)
+	header.Uint64(blobHeaderDataSize, uint64(blob.Len()))
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(header.Len()))
+	b = append(b, size[:]...)
+	b = append(b, header.Bytes()...)
+	return append(b, blob.Bytes()...)
+}
+
+func headerBlock(features ...string) []byte {
+	var e pb.Encoder
+	for _, f := range features {
+		e.String(headerRequiredFeatures, f)
+	}
+	return e.Bytes()
+}
+
+// deltas returns the delta coding of values.
+func deltas(values ...int64) []int64 {
+	result := make([]int64, len(values))
+	var prev int64
+	for i, v := range values {
+		result[i] = v - prev
+		prev = v
+	}
+	return result
+}
+
+func testPBF() []byte {
+	strings := []string{"", "building", "yes", "amenity", "cafe", "type", "multipolygon", "outer", "natural", "water"}
+	var st pb.Encoder
+	for _, s := range strings {
+		st.String(stringTableS, s)
+	}
+
+	// Coordinates are in units of the default granularity, 100
+	// nanodegrees.
+	var dense pb.Encoder
+	dense.PackedSint64(denseID, deltas(1, 2, 3, 4))
+	dense.PackedSint64(denseLat, deltas(0, 0, 40000000, 40000000))
+	dense.PackedSint64(denseLon, deltas(0, 40000000, 40000000, 0))
+	dense.PackedUint64(denseKeysVals, []uint64{0, 0, 0, 3, 4, 0})
+	var nodes pb.Encoder
+	nodes.Embedded(groupDense, dense.Bytes())
+	var node pb.Encoder
+	node.Sint64(primitiveID, 5)
+	node.PackedUint32(primitiveKeys, []uint32{3})
+	node.PackedUint32(primitiveVals, []uint32{4})
+	node.Sint64(nodeLat, 134132000)
+	node.Sint64(nodeLon, 525219000)
+	nodes.Embedded(groupNodes, node.Bytes())
+
+	var ways pb.Encoder
+	var building pb.Encoder
+	building.Int64(primitiveID, 10)
+	building.PackedUint32(primitiveKeys, []uint32{1})
+	building.PackedUint32(primitiveVals, []uint32{2})
+	building.PackedSint64(wayRefs, deltas(1, 2, 3, 4, 1))
+	ways.Embedded(groupWays, building.Bytes())
+	var half1, half2 pb.Encoder
+	half1.Int64(primitiveID, 11)
+	half1.PackedSint64(wayRefs, deltas(1, 2, 3))
+	ways.Embedded(groupWays, half1.Bytes())
+	half2.Int64(primitiveID, 12)
+	half2.PackedSint64(wayRefs, deltas(3, 4, 1))
+	// Locations on ways.
+	half2.PackedSint64(wayLat, deltas(40000000, 40000000, 0))
+	half2.PackedSint64(wayLon, deltas(40000000, 0, 0))
+	ways.Embedded(groupWays, half2.Bytes())
+
+	var relations pb.Encoder
+	var relation pb.Encoder
+	relation.Int64(primitiveID, 20)
+	relation.PackedUint32(primitiveKeys, []uint32{5, 8})
+	relation.PackedUint32(primitiveVals, []uint32{6, 9})
+	relation.PackedUint32(relationRolesSID, []uint32{7, 7})
+	relation.PackedSint64(relationMemIDs, deltas(11, 12))
+	relation.PackedUint64(relationTypes, []uint64{1, 1})
+	relations.Embedded(groupRelations, relation.Bytes())
+
+	var block1 pb.Encoder
+	block1.Embedded(blockStringTable, st.Bytes())
+	block1.Embedded(blockGroups, nodes.Bytes())
+	var block2 pb.Encoder
+	block2.Embedded(blockStringTable, st.Bytes())
+	block2.Embedded(blockGroups, ways.Bytes())
+	block2.Embedded(blockGroups, relations.Bytes())
+
+	var b []byte
+	b = appendBlob(b, "OSMHeader", headerBlock("OsmSchema-V0.6", "DenseNodes"), false)
+	b = appendBlob(b, "OSMData", block1.Bytes(), true)
+	b = appendBlob(b, "OSMData", block2.Bytes(), false)
+	return b
+}
+
+func TestPBFReader(t *testing.T) {
+	p, err := NewPBFReader(bytes.NewReader(testPBF()))
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	var elements []Element
+	for {
+		e, err := p.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read: %v", err)
+		}
+		elements = append(elements, e)
+	}
+	expected := []Element{
+		&Node{ID: 1, Lon: 0, Lat: 0},
+		&Node{ID: 2, Lon: 4, Lat: 0},
+		&Node{ID: 3, Lon: 4, Lat: 4},
+		&Node{ID: 4, Lon: 0, Lat: 4, Tags: map[string]string{"amenity": "cafe"}},
+		&Node{ID: 5, Lon: 52.5219, Lat: 13.4132, Tags: map[string]string{"amenity": "cafe"}},
+		&Way{ID: 10, Nodes: []int64{1, 2, 3, 4, 1}, Tags: map[string]string{"building": "yes"}},
+		&Way{ID: 11, Nodes: []int64{1, 2, 3}},
+		&Way{
+			ID:        12,
+			Nodes:     []int64{3, 4, 1},
+			Locations: []geojson.Point{{X: 4, Y: 4}, {X: 0, Y: 4}, {X: 0, Y: 0}},
+		},
+		&Relation{
+			ID: 20,
+			Members: []Member{
+				{Type: WayType, Ref: 11, Role: "outer"},
+				{Type: WayType, Ref: 12, Role: "outer"},
+			},
+			Tags: map[string]string{"type": "multipolygon", "natural": "water"},
+		},
+	}
+	if !reflect.DeepEqual(elements, expected) {
+		t.Errorf("expected %#v, got %#v", expected, elements)
+	}
+}
+
+func TestDecodePBF(t *testing.T) {
+	fc, err := DecodePBF(bytes.NewReader(testPBF()))
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	square := []geojson.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}, {X: 0, Y: 0}}
+	expected := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				ID:         "node/4",
+				Geometry:   &geojson.Point{X: 0, Y: 4},
+				Properties: map[string]interface{}{"amenity": "cafe"},
+			},
+			{
+				ID:         "node/5",
+				Geometry:   &geojson.Point{X: 52.5219, Y: 13.4132},
+				Properties: map[string]interface{}{"amenity": "cafe"},
+			},
+			{
+				ID:         "way/10",
+				Geometry:   &geojson.Polygon{Rings: []geojson.LineString{{Points: square}}},
+				Properties: map[string]interface{}{"building": "yes"},
+			},
+			{
+				ID: "relation/20",
+				Geometry: &geojson.MultiPolygon{Polygons: []geojson.Polygon{
+					{Rings: []geojson.LineString{{Points: square}}},
+				}},
+				Properties: map[string]interface{}{"type": "multipolygon", "natural": "water"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(fc, expected) {
+		t.Errorf("expected %#v, got %#v", expected, fc)
+	}
+
+	// ScanPBF resolves the same features with only the member ways
+	// retained.
+	var scanned []geojson.Feature
+	err = ScanPBF(bytes.NewReader(testPBF()), NewMemoryStore(), func(f *geojson.Feature) error {
+		scanned = append(scanned, *f)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to scan: %v", err)
+	}
+	if !reflect.DeepEqual(scanned, expected.Features) {
+		t.Errorf("expected %#v, got %#v", expected.Features, scanned)
+	}
+}
+
+func TestNewPBFReader_Invalid(t *testing.T) {
+	valid := testPBF()
+	cases := [][]byte{
+		nil,
+		valid[:10],
+		appendBlob(nil, "OSMData", nil, false),
+		appendBlob(nil, "OSMHeader", headerBlock("OsmSchema-V0.6", "HistoricalInformation"), false),
+	}
+	for i, c := range cases {
+		_, err := NewPBFReader(bytes.NewReader(c))
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+
+	_, err := DecodePBF(bytes.NewReader(valid[:len(valid)-5]))
+	if err == nil {
+		t.Errorf("expected error for truncated file")
+	}
+}
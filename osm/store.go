@@ -0,0 +1,105 @@
+package osm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/bsidhom/geojson"
+)
+
+// A NodeStore holds node locations until the ways that use them are
+// assembled.
+type NodeStore interface {
+	// Put records the location of a node.
+	Put(id int64, p geojson.Point) error
+	// Get returns the location of a node, reporting false if it is not
+	// known.
+	Get(id int64) (geojson.Point, bool, error)
+}
+
+// A MemoryStore is a NodeStore backed by a map. It is the fastest store,
+// but takes tens of bytes per node.
+type MemoryStore map[int64]geojson.Point
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() MemoryStore {
+	return MemoryStore{}
+}
+
+// Put implements NodeStore.
+func (s MemoryStore) Put(id int64, p geojson.Point) error {
+	s[id] = p
+	return nil
+}
+
+// Get implements NodeStore.
+func (s MemoryStore) Get(id int64) (geojson.Point, bool, error) {
+	p, ok := s[id]
+	return p, ok, nil
+}
+
+// A FileStore is a NodeStore that keeps locations in a file as an array
+// indexed by node ID, at 8 bytes per ID. Since node IDs are dense, this is
+// the most compact store for whole-planet or country-scale data. The file
+// should support holes, as temporary files on most file systems do, so
+// that it takes no space for IDs that are absent from the input.
+//
+// Locations are rounded to 1e-7 degrees, the precision that OSM itself
+// keeps. Negative node IDs cannot be stored.
+type FileStore struct {
+	f interface {
+		io.ReaderAt
+		io.WriterAt
+	}
+}
+
+// NewFileStore returns a FileStore backed by f, which is usually an empty
+// temporary *os.File.
+func NewFileStore(f interface {
+	io.ReaderAt
+	io.WriterAt
+}) *FileStore {
+	return &FileStore{f: f}
+}
+
+// fileStoreBias is added to fixed-point latitudes so that an all-zero
+// entry, as found in holes, never holds a valid location.
+const fileStoreBias = 1000000000
+
+// Put implements NodeStore.
+func (s *FileStore) Put(id int64, p geojson.Point) error {
+	if id < 0 {
+		return fmt.Errorf("node %d: negative IDs cannot be stored", id)
+	}
+	var buf [8]byte
+	binary.LittleEndian.PutUint32(buf[0:], uint32(int32(math.Round(p.X*1e7))))
+	binary.LittleEndian.PutUint32(buf[4:], uint32(int32(math.Round(p.Y*1e7))+fileStoreBias))
+	_, err := s.f.WriteAt(buf[:], id*8)
+	return err
+}
+
+// Get implements NodeStore.
+func (s *FileStore) Get(id int64) (geojson.Point, bool, error) {
+	if id < 0 {
+		return geojson.Point{}, false, nil
+	}
+	var buf [8]byte
+	n, err := s.f.ReadAt(buf[:], id*8)
+	if n < len(buf) {
+		if err == io.EOF {
+			err = nil
+		}
+		return geojson.Point{}, false, err
+	}
+	lat := int32(binary.LittleEndian.Uint32(buf[4:]))
+	if lat == 0 {
+		return geojson.Point{}, false, nil
+	}
+	lon := int32(binary.LittleEndian.Uint32(buf[0:]))
+	return geojson.Point{
+		X: float64(lon) / 1e7,
+		Y: float64(lat-fileStoreBias) / 1e7,
+	}, true, nil
+}
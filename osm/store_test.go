@@ -0,0 +1,50 @@
+package osm
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestFileStore(t *testing.T) {
+	f, err := ioutil.TempFile("", "nodes")
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	s := NewFileStore(f)
+	points := map[int64]geojson.Point{
+		1:        {X: 0, Y: 0},
+		7:        {X: -180, Y: -90},
+		8:        {X: 13.4132, Y: 52.5219},
+		50000000: {X: 179.9999999, Y: 90},
+	}
+	for id, p := range points {
+		if err := s.Put(id, p); err != nil {
+			t.Fatalf("failed to put %d: %v", id, err)
+		}
+	}
+	for id, expected := range points {
+		p, ok, err := s.Get(id)
+		if err != nil || !ok {
+			t.Errorf("failed to get %d: %v, %v", id, ok, err)
+			continue
+		}
+		if p != expected {
+			t.Errorf("node %d: expected %v, got %v", id, expected, p)
+		}
+	}
+	for _, id := range []int64{0, 2, 60000000, -1} {
+		_, ok, err := s.Get(id)
+		if err != nil || ok {
+			t.Errorf("expected node %d to be missing, got %v, %v", id, ok, err)
+		}
+	}
+	if err := s.Put(-1, geojson.Point{}); err == nil {
+		t.Errorf("expected error for negative ID")
+	}
+}
@@ -0,0 +1,106 @@
+package osm
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/bsidhom/geojson"
+)
+
+type xmlTag struct {
+	K string `xml:"k,attr"`
+	V string `xml:"v,attr"`
+}
+
+type xmlNode struct {
+	ID      int64    `xml:"id,attr"`
+	Lat     float64  `xml:"lat,attr"`
+	Lon     float64  `xml:"lon,attr"`
+	Visible string   `xml:"visible,attr"`
+	Tags    []xmlTag `xml:"tag"`
+}
+
+type xmlWay struct {
+	ID      int64  `xml:"id,attr"`
+	Visible string `xml:"visible,attr"`
+	Nds     []struct {
+		Ref int64 `xml:"ref,attr"`
+	} `xml:"nd"`
+	Tags []xmlTag `xml:"tag"`
+}
+
+type xmlRelation struct {
+	ID      int64  `xml:"id,attr"`
+	Visible string `xml:"visible,attr"`
+	Members []struct {
+		Type string `xml:"type,attr"`
+		Ref  int64  `xml:"ref,attr"`
+		Role string `xml:"role,attr"`
+	} `xml:"member"`
+	Tags []xmlTag `xml:"tag"`
+}
+
+// Decode reads an OSM XML document, such as a .osm file or the XML output
+// of the Overpass API, and resolves its elements into a FeatureCollection.
+// Elements marked visible="false" are ignored.
+func Decode(r io.Reader) (*geojson.FeatureCollection, error) {
+	d := xml.NewDecoder(r)
+	var all data
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("osm: %v", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "node":
+			var n xmlNode
+			err = d.DecodeElement(&n, &start)
+			if err == nil && n.Visible != "false" {
+				all.nodes = append(all.nodes, Node{ID: n.ID, Lon: n.Lon, Lat: n.Lat, Tags: xmlTags(n.Tags)})
+			}
+		case "way":
+			var w xmlWay
+			err = d.DecodeElement(&w, &start)
+			if err == nil && w.Visible != "false" {
+				refs := make([]int64, len(w.Nds))
+				for i, nd := range w.Nds {
+					refs[i] = nd.Ref
+				}
+				all.ways = append(all.ways, Way{ID: w.ID, Nodes: refs, Tags: xmlTags(w.Tags)})
+			}
+		case "relation":
+			var x xmlRelation
+			err = d.DecodeElement(&x, &start)
+			if err == nil && x.Visible != "false" {
+				r := Relation{ID: x.ID, Tags: xmlTags(x.Tags)}
+				for _, m := range x.Members {
+					r.Members = append(r.Members, Member{Type: ElementType(m.Type), Ref: m.Ref, Role: m.Role})
+				}
+				all.relations = append(all.relations, r)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("osm: %s: %v", start.Name.Local, err)
+		}
+	}
+	return assemble(&all)
+}
+
+func xmlTags(tags []xmlTag) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(tags))
+	for _, t := range tags {
+		m[t.K] = t.V
+	}
+	return m
+}
@@ -0,0 +1,94 @@
+package osm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestDecode(t *testing.T) {
+	s := `<?xml version="1.0" encoding="UTF-8"?>
+<osm version="0.6" generator="test">
+  <bounds minlat="0" minlon="0" maxlat="10" maxlon="10"/>
+  <node id="1" lat="0" lon="0"/>
+  <node id="2" lat="0" lon="4"/>
+  <node id="3" lat="4" lon="4"/>
+  <node id="4" lat="4" lon="0"/>
+  <node id="5" lat="5" lon="5">
+    <tag k="amenity" v="cafe"/>
+    <tag k="name" v="Corner"/>
+  </node>
+  <node id="6" lat="6" lon="6" visible="false">
+    <tag k="amenity" v="bench"/>
+  </node>
+  <way id="10">
+    <nd ref="1"/><nd ref="4"/><nd ref="3"/><nd ref="2"/><nd ref="1"/>
+    <tag k="building" v="yes"/>
+  </way>
+  <way id="11">
+    <nd ref="1"/><nd ref="2"/><nd ref="3"/><nd ref="4"/><nd ref="1"/>
+    <tag k="highway" v="residential"/>
+  </way>
+  <way id="12">
+    <nd ref="1"/><nd ref="2"/><nd ref="3"/>
+    <tag k="highway" v="footway"/>
+  </way>
+  <way id="13">
+    <nd ref="1"/><nd ref="99"/>
+    <tag k="highway" v="footway"/>
+  </way>
+</osm>`
+	fc, err := Decode(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	expected := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				ID:         "node/5",
+				Geometry:   &geojson.Point{X: 5, Y: 5},
+				Properties: map[string]interface{}{"amenity": "cafe", "name": "Corner"},
+			},
+			{
+				// The clockwise way is rewound counter-clockwise.
+				ID: "way/10",
+				Geometry: &geojson.Polygon{Rings: []geojson.LineString{{Points: []geojson.Point{
+					{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}, {X: 0, Y: 0},
+				}}}},
+				Properties: map[string]interface{}{"building": "yes"},
+			},
+			{
+				ID: "way/11",
+				Geometry: &geojson.LineString{Points: []geojson.Point{
+					{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}, {X: 0, Y: 0},
+				}},
+				Properties: map[string]interface{}{"highway": "residential"},
+			},
+			{
+				ID: "way/12",
+				Geometry: &geojson.LineString{Points: []geojson.Point{
+					{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4},
+				}},
+				Properties: map[string]interface{}{"highway": "footway"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(fc, expected) {
+		t.Errorf("expected %#v, got %#v", expected, fc)
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	cases := []string{
+		`<osm><node id="x" lat="0" lon="0"/></osm>`,
+		`<osm><way id="1"><nd ref="1"/></osm>`,
+	}
+	for i, c := range cases {
+		_, err := Decode(strings.NewReader(c))
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
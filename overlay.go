@@ -0,0 +1,222 @@
+package geojson
+
+import (
+	"math"
+	"sort"
+)
+
+// Union returns the polygons covering the positions in a or b.
+//
+// Union and the other overlay operations only consider the polygons of
+// their arguments, which may be Polygons, MultiPolygons, or
+// GeometryCollections containing them, and ignore any points or lines. The
+// polygons of each argument may share edges, as the parcels of a coverage
+// do, or overlap, in which case they are dissolved into their union. They
+// compute in the plane, like Relate, and return polygons whose exterior
+// rings are counter-clockwise and whose holes are clockwise, as RFC 7946
+// requires. Shared edges are merged, and polygons in the result that meet
+// only at single points remain separate.
+// The result has no polygons if it is empty.
+func Union(a, b Geometry) *MultiPolygon {
+	return overlay(a, b, func(inA, inB bool) bool { return inA || inB })
+}
+
+// Intersection returns the polygons covering the positions in both a and b.
+// Parts of a and b that meet in lines or points are not included. See Union
+// for details.
+func Intersection(a, b Geometry) *MultiPolygon {
+	return overlay(a, b, func(inA, inB bool) bool { return inA && inB })
+}
+
+// Difference returns the polygons covering the positions in a but not in b.
+// See Union for details.
+func Difference(a, b Geometry) *MultiPolygon {
+	return overlay(a, b, func(inA, inB bool) bool { return inA && !inB })
+}
+
+// SymDifference returns the polygons covering the positions in either a or
+// b but not both, which is also known as xor. See Union for details.
+func SymDifference(a, b Geometry) *MultiPolygon {
+	return overlay(a, b, func(inA, inB bool) bool { return inA != inB })
+}
+
+// polygonal returns the polygons of g.
+func polygonal(g Geometry) *MultiPolygon {
+	m := &MultiPolygon{}
+	walkPolygons(g, func(p *Polygon) {
+		m.Polygons = append(m.Polygons, *p)
+	})
+	return m
+}
+
+// A dirEdge is an edge of the result of an overlay, directed so that the
+// result lies to its left.
+type dirEdge struct {
+	from, to xy
+	angle    float64
+	used     bool
+}
+
+// overlay returns the polygons covering the positions for which in returns
+// true, given whether they are in the interior of a and b.
+func overlay(a, b Geometry, in func(inA, inB bool) bool) *MultiPolygon {
+	ar := newArrangement(polygonal(a), polygonal(b))
+	var edges []*dirEdge
+	out := map[xy][]*dirEdge{}
+	for _, e := range ar.edges {
+		if !e.onRing[0] && !e.onRing[1] {
+			continue
+		}
+		la, ra := ar.sideLocations(0, e)
+		lb, rb := ar.sideLocations(1, e)
+		left := in(la == Interior, lb == Interior)
+		right := in(ra == Interior, rb == Interior)
+		if left == right {
+			continue
+		}
+		d := &dirEdge{from: e.a, to: e.b}
+		if right {
+			d.from, d.to = e.b, e.a
+		}
+		d.angle = math.Atan2(d.to.y-d.from.y, d.to.x-d.from.x)
+		edges = append(edges, d)
+		out[d.from] = append(out[d.from], d)
+	}
+	// Sort for deterministic output, since map iteration is random.
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].from != edges[j].from {
+			return edges[i].from.less(edges[j].from)
+		}
+		return edges[i].to.less(edges[j].to)
+	})
+
+	var shells, holes [][]xy
+	for _, start := range edges {
+		if start.used {
+			continue
+		}
+		ring := traceRing(start, out)
+		switch a := signedArea(ring); {
+		case a > 0:
+			shells = append(shells, ring)
+		case a < 0:
+			holes = append(holes, ring)
+		}
+	}
+	return assemble(shells, holes)
+}
+
+// traceRing follows the edges from start around the face to their left,
+// marking them as used, and returns the vertices of the face's ring
+// without repeating the first. It returns nil if the edges do not close,
+// which the snapping of positions in the arrangement prevents.
+func traceRing(start *dirEdge, out map[xy][]*dirEdge) []xy {
+	var ring []xy
+	for e := start; ; {
+		e.used = true
+		ring = append(ring, e.from)
+		// Take the outgoing edge that turns the most to the left, which is
+		// the first one clockwise from the reverse of e.
+		back := math.Atan2(e.from.y-e.to.y, e.from.x-e.to.x)
+		var next *dirEdge
+		best := math.Inf(1)
+		for _, c := range out[e.to] {
+			turn := back - c.angle
+			for turn <= 0 {
+				turn += 2 * math.Pi
+			}
+			for turn > 2*math.Pi {
+				turn -= 2 * math.Pi
+			}
+			if turn < best {
+				next, best = c, turn
+			}
+		}
+		switch {
+		case next == start:
+			return simplifyRing(ring)
+		case next == nil || next.used:
+			return nil
+		}
+		e = next
+	}
+}
+
+// simplifyRing removes vertices where ring does not turn.
+func simplifyRing(ring []xy) []xy {
+	for changed := true; changed && len(ring) >= 3; {
+		changed = false
+		var r []xy
+		for i, p := range ring {
+			prev := ring[(i+len(ring)-1)%len(ring)]
+			next := ring[(i+1)%len(ring)]
+			if orient(prev, p, next) == 0 && (p.x-prev.x)*(next.x-p.x)+(p.y-prev.y)*(next.y-p.y) > 0 {
+				changed = true
+				continue
+			}
+			r = append(r, p)
+		}
+		ring = r
+	}
+	return ring
+}
+
+// signedArea returns twice the signed area of ring, which is positive if
+// ring is counter-clockwise.
+func signedArea(ring []xy) float64 {
+	var sum float64
+	for i, p := range ring {
+		q := ring[(i+1)%len(ring)]
+		sum += p.x*q.y - q.x*p.y
+	}
+	return sum
+}
+
+// assemble puts each hole in the smallest shell that contains it.
+func assemble(shells, holes [][]xy) *MultiPolygon {
+	m := &MultiPolygon{}
+	for _, shell := range shells {
+		m.Polygons = append(m.Polygons, Polygon{Rings: []LineString{closedRing(shell)}})
+	}
+	for _, hole := range holes {
+		best := -1
+		var area float64
+		for i, shell := range shells {
+			p := &Polygon{Rings: m.Polygons[i].Rings[:1]}
+			if !holeWithin(hole, p) {
+				continue
+			}
+			if a := signedArea(shell); best < 0 || a < area {
+				best, area = i, a
+			}
+		}
+		if best >= 0 {
+			m.Polygons[best].Rings = append(m.Polygons[best].Rings, closedRing(hole))
+		}
+	}
+	return m
+}
+
+// holeWithin reports whether hole lies within the shell of p. Holes may
+// touch their shell, so this tests the vertices and edge midpoints of hole
+// until one is not on the shell.
+func holeWithin(hole []xy, p *Polygon) bool {
+	for i, v := range hole {
+		w := hole[(i+1)%len(hole)]
+		for _, q := range []xy{v, {(v.x + w.x) / 2, (v.y + w.y) / 2}} {
+			if l := p.Locate(Point{X: q.x, Y: q.y}); l != Boundary {
+				return l == Interior
+			}
+		}
+	}
+	return false
+}
+
+// closedRing returns ring as a LineString that repeats its first position.
+func closedRing(ring []xy) LineString {
+	points := make([]Point, 0, len(ring)+1)
+	for _, p := range ring {
+		points = append(points, Point{X: p.x, Y: p.y})
+	}
+	return LineString{Points: append(points, points[0])}
+}
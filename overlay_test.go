@@ -0,0 +1,204 @@
+package geojson_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/wkt"
+)
+
+func TestOverlay(t *testing.T) {
+	type op func(a, b geojson.Geometry) *geojson.MultiPolygon
+	const (
+		overlapping = "POLYGON((5 5, 15 5, 15 15, 5 15, 5 5))"
+		adjacent    = "POLYGON((10 0, 20 0, 20 10, 10 10, 10 0))"
+		// Polygons within one argument that share an edge.
+		pairMP   = "MULTIPOLYGON(((0 0, 10 0, 10 10, 0 10, 0 0)), ((10 0, 20 0, 20 10, 10 10, 10 0)))"
+		pair     = "POLYGON((0 0, 20 0, 20 10, 0 10, 0 0))"
+		far      = "POLYGON((50 50, 60 50, 60 60, 50 60, 50 50))"
+		straddle = "POLYGON((5 2, 15 2, 15 8, 5 8, 5 2))"
+	)
+	cases := []struct {
+		name     string
+		op       op
+		a, b     string
+		expected string
+	}{
+		{"Union", geojson.Union, square, overlapping, "POLYGON((0 0, 10 0, 10 5, 15 5, 15 15, 5 15, 5 10, 0 10, 0 0))"},
+		{"Intersection", geojson.Intersection, square, overlapping, "POLYGON((5 5, 10 5, 10 10, 5 10, 5 5))"},
+		{"Difference", geojson.Difference, square, overlapping, "POLYGON((0 0, 10 0, 10 5, 5 5, 5 10, 0 10, 0 0))"},
+		{"SymDifference", geojson.SymDifference, square, overlapping, "MULTIPOLYGON(((0 0, 10 0, 10 5, 5 5, 5 10, 0 10, 0 0)), ((10 5, 15 5, 15 15, 5 15, 5 10, 10 10, 10 5)))"},
+		// Shared edges.
+		{"Union", geojson.Union, square, adjacent, "POLYGON((0 0, 20 0, 20 10, 0 10, 0 0))"},
+		{"Intersection", geojson.Intersection, square, adjacent, "GEOMETRYCOLLECTION EMPTY"},
+		{"Union", geojson.Union, square, square, square},
+		{"Difference", geojson.Difference, square, square, "GEOMETRYCOLLECTION EMPTY"},
+		// Holes.
+		{"Difference", geojson.Difference, square, hole, holed},
+		{"Union", geojson.Union, holed, hole, square},
+		{"Intersection", geojson.Intersection, holed, "POLYGON((-5 -5, 5 -5, 5 5, -5 5, -5 -5))", "POLYGON((0 0, 5 0, 5 2, 2 2, 2 5, 0 5, 0 0))"},
+		{"Union", geojson.Union, holed, "POLYGON((4 4, 6 4, 6 6, 4 6, 4 4))", "MULTIPOLYGON(((0 0, 10 0, 10 10, 0 10, 0 0), (2 2, 2 8, 8 8, 8 2, 2 2)), ((4 4, 6 4, 6 6, 4 6, 4 4)))"},
+		// Polygons that meet at a point stay separate.
+		{"Union", geojson.Union, square, "POLYGON((10 10, 20 10, 20 20, 10 20, 10 10))", "MULTIPOLYGON(((0 0, 10 0, 10 10, 0 10, 0 0)), ((10 10, 20 10, 20 20, 10 20, 10 10)))"},
+		// Shared edges within one argument are interior.
+		{"Union", geojson.Union, pairGC, far, "MULTIPOLYGON(((0 0, 20 0, 20 10, 0 10, 0 0)), ((50 50, 60 50, 60 60, 50 60, 50 50)))"},
+		{"Union", geojson.Union, far, pairMP, "MULTIPOLYGON(((0 0, 20 0, 20 10, 0 10, 0 0)), ((50 50, 60 50, 60 60, 50 60, 50 50)))"},
+		{"Intersection", geojson.Intersection, pairGC, pair, pair},
+		{"Intersection", geojson.Intersection, pairMP, straddle, straddle},
+		{"Difference", geojson.Difference, pairGC, far, pair},
+		{"Difference", geojson.Difference, straddle, pairMP, "GEOMETRYCOLLECTION EMPTY"},
+		{"Difference", geojson.Difference, pairGC, straddle, "POLYGON((0 0, 20 0, 20 10, 0 10, 0 0), (5 2, 5 8, 15 8, 15 2, 5 2))"},
+		{"SymDifference", geojson.SymDifference, pairMP, pair, "GEOMETRYCOLLECTION EMPTY"},
+		{"Union", geojson.Union, pairGC, pairMP, pair},
+		// Overlapping polygons within one argument are dissolved.
+		{"Union", geojson.Union, overlapGC, "MULTIPOLYGON EMPTY", "POLYGON((0 0, 15 0, 15 10, 0 10, 0 0))"},
+		{"Union", geojson.Union, "MULTIPOLYGON(((0 0, 10 0, 10 10, 0 10, 0 0)), ((5 0, 15 0, 15 10, 5 10, 5 0)))", far, "MULTIPOLYGON(((0 0, 15 0, 15 10, 0 10, 0 0)), ((50 50, 60 50, 60 60, 50 60, 50 50)))"},
+		{"Intersection", geojson.Intersection, overlapGC, straddle, straddle},
+		{"Difference", geojson.Difference, overlapGC, "POLYGON((5 0, 10 0, 10 10, 5 10, 5 0))", "MULTIPOLYGON(((0 0, 5 0, 5 10, 0 10, 0 0)), ((10 0, 15 0, 15 10, 10 10, 10 0)))"},
+		{"SymDifference", geojson.SymDifference, overlapGC, square, "POLYGON((10 0, 15 0, 15 10, 10 10, 10 0))"},
+		{"Union", geojson.Union, "GEOMETRYCOLLECTION(POLYGON((0 0, 10 0, 10 10, 0 10, 0 0)), POLYGON((2 2, 8 2, 8 8, 2 8, 2 2)))", "MULTIPOLYGON EMPTY", square},
+		{"Union", geojson.Union, "GEOMETRYCOLLECTION(POLYGON((0 0, 10 0, 10 10, 0 10, 0 0), (2 2, 2 8, 8 8, 8 2, 2 2)), POLYGON((1 1, 9 1, 9 9, 1 9, 1 1)))", "MULTIPOLYGON EMPTY", square},
+		// Degenerate polygons, points, and lines are ignored.
+		{"Union", geojson.Union, square, "POLYGON((0 0, 10 10, 20 20, 0 0))", square},
+		{"Union", geojson.Union, "GEOMETRYCOLLECTION(POINT(30 30), LINESTRING(0 0, 20 20))", square, square},
+	}
+	for i, c := range cases {
+		result := c.op(mustWKT(t, c.a), mustWKT(t, c.b))
+		expected := mustWKT(t, c.expected)
+		if !geojson.Equals(result, expected) {
+			s, _ := wkt.Marshal(result)
+			t.Errorf("case %d: expected %s(%s, %s) to be %s, got %s", i, c.name, c.a, c.b, c.expected, s)
+		}
+		checkWinding(t, i, result)
+	}
+
+	// Triangles that cross at rounded positions, so that the result can
+	// only be compared approximately.
+	x := geojson.Intersection(mustWKT(t, "POLYGON((0 0, 10 0, 0 10, 0 0))"), mustWKT(t, "POLYGON((1 1, 9 2, 2 9, 1 1))"))
+	checkWinding(t, -1, x)
+	if len(x.Polygons) != 1 || len(x.Polygons[0].Rings[0].Points) != 4 {
+		t.Errorf("expected a triangle, got %#v", x)
+	} else if c, _ := geojson.Centroid(x); math.Abs(c.X-(1+73.0/9+17.0/9)/3) > 1e-12 || math.Abs(c.Y-c.X) > 1e-12 {
+		t.Errorf("expected a triangle with vertices (1, 1), (73/9, 17/9), and (17/9, 73/9), got %#v", x)
+	}
+
+	// Merged edges do not keep the vertices where they were split.
+	u := geojson.Union(mustWKT(t, square), mustWKT(t, adjacent))
+	if len(u.Polygons) != 1 || len(u.Polygons[0].Rings[0].Points) != 5 {
+		t.Errorf("expected a rectangle, got %#v", u)
+	}
+}
+
+func TestOverlay_Random(t *testing.T) {
+	// Star-shaped polygons with random vertices, and rectangles with
+	// integer corners that often share edges and vertices.
+	r := rand.New(rand.NewSource(1))
+	star := func(cx, cy float64) *geojson.Polygon {
+		n := 3 + r.Intn(20)
+		var points []geojson.Point
+		for i := 0; i < n; i++ {
+			a := 2 * math.Pi * float64(i) / float64(n)
+			d := 1 + 4*r.Float64()
+			points = append(points, geojson.Point{X: cx + d*math.Cos(a), Y: cy + d*math.Sin(a)})
+		}
+		return &geojson.Polygon{Rings: []geojson.LineString{{Points: append(points, points[0])}}}
+	}
+	rect := func() *geojson.Polygon {
+		x, y := float64(r.Intn(5)), float64(r.Intn(5))
+		w, h := float64(1+r.Intn(5)), float64(1+r.Intn(5))
+		return &geojson.Polygon{Rings: []geojson.LineString{{Points: []geojson.Point{
+			{X: x, Y: y}, {X: x + w, Y: y}, {X: x + w, Y: y + h}, {X: x, Y: y + h}, {X: x, Y: y},
+		}}}}
+	}
+	for i := 0; i < 500; i++ {
+		a, b := star(0, 0), star(4*r.Float64(), 4*r.Float64())
+		if i%2 == 0 {
+			a, b = rect(), rect()
+		}
+		areaA := planarArea(&geojson.MultiPolygon{Polygons: []geojson.Polygon{*a}})
+		areaB := planarArea(&geojson.MultiPolygon{Polygons: []geojson.Polygon{*b}})
+		union := planarArea(geojson.Union(a, b))
+		intersection := planarArea(geojson.Intersection(a, b))
+		difference := planarArea(geojson.Difference(a, b))
+		symDifference := planarArea(geojson.SymDifference(a, b))
+		tolerance := 1e-9 * (areaA + areaB)
+		if math.Abs(union+intersection-areaA-areaB) > tolerance ||
+			math.Abs(difference+intersection-areaA) > tolerance ||
+			math.Abs(symDifference+intersection-union) > tolerance {
+			t.Errorf("case %d: inconsistent areas for %v and %v", i, a.Rings[0].Points, b.Rings[0].Points)
+		}
+	}
+}
+
+// planarArea returns the area of m, treating coordinates as planar.
+func planarArea(m *geojson.MultiPolygon) float64 {
+	var area float64
+	for _, p := range m.Polygons {
+		for _, ring := range p.Rings {
+			points := ring.Points
+			for k := 1; k < len(points); k++ {
+				area += (points[k-1].X*points[k].Y - points[k].X*points[k-1].Y) / 2
+			}
+		}
+	}
+	return area
+}
+
+// checkWinding checks that the rings of m are closed and follow the right
+// hand rule of RFC 7946.
+func checkWinding(t *testing.T, i int, m *geojson.MultiPolygon) {
+	for _, p := range m.Polygons {
+		for j, ring := range p.Rings {
+			points := ring.Points
+			if len(points) < 4 || points[0] != points[len(points)-1] {
+				t.Errorf("case %d: expected a closed ring, got %v", i, points)
+				continue
+			}
+			var area float64
+			for k := 1; k < len(points); k++ {
+				area += points[k-1].X*points[k].Y - points[k].X*points[k-1].Y
+			}
+			if (j == 0) != (area > 0) {
+				t.Errorf("case %d: ring %d has the wrong winding: %v", i, j, points)
+			}
+		}
+	}
+}
+
+func TestOverlay_Collinear(t *testing.T) {
+	// A rectangle and a triangle with an edge along half of the rectangle's
+	// end, rotated so that the shared edges are only nearly collinear after
+	// rounding, as the pieces of a buffer are.
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		l := 10 + 1000*r.Float64()
+		sin, cos := math.Sincos(2 * math.Pi * r.Float64())
+		cx, cy := 100*r.Float64(), 100*r.Float64()
+		polygon := func(ps ...[2]float64) *geojson.Polygon {
+			var points []geojson.Point
+			for _, p := range append(ps, ps[0]) {
+				points = append(points, geojson.Point{X: cx + p[0]*cos - p[1]*sin, Y: cy + p[0]*sin + p[1]*cos})
+			}
+			return &geojson.Polygon{Rings: []geojson.LineString{{Points: points}}}
+		}
+		a := polygon([2]float64{0, -20}, [2]float64{l, -20}, [2]float64{l, 20}, [2]float64{0, 20})
+		// The triangle lies outside the rectangle on even trials and inside
+		// it on odd ones.
+		x := l + 15
+		if i%2 == 1 {
+			x = l - 15
+		}
+		b := polygon([2]float64{l, 0}, [2]float64{l, 20}, [2]float64{x, 10 * r.Float64()})
+		areaA := math.Abs(planarArea(&geojson.MultiPolygon{Polygons: []geojson.Polygon{*a}}))
+		areaB := math.Abs(planarArea(&geojson.MultiPolygon{Polygons: []geojson.Polygon{*b}}))
+		union := geojson.Union(a, b)
+		intersection := geojson.Intersection(a, b)
+		checkWinding(t, i, union)
+		checkWinding(t, i, intersection)
+		if d := planarArea(union) + planarArea(intersection) - areaA - areaB; math.Abs(d) > 1e-9*(areaA+areaB) {
+			t.Errorf("case %d: areas differ by %v for %v and %v", i, d, a.Rings[0].Points, b.Rings[0].Points)
+		}
+	}
+}
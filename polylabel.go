@@ -0,0 +1,159 @@
+package geojson
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Polylabel returns the pole of inaccessibility of the polygons of g: the
+// interior point farthest from their boundaries, which is the best place
+// for a label on an irregular polygon. It uses the polylabel algorithm
+// (https://github.com/mapbox/polylabel), which refines a grid of cells until
+// the result is within precision of the true pole, in coordinate units.
+// Finer precisions take longer, particularly for polygons with long parallel
+// edges, where many points are nearly as good as the pole. A precision that
+// is not positive is treated as a thousandth of the size of the polygon.
+//
+// Like Centroid, Polylabel treats coordinates as planar. It returns false if
+// g has no polygons with area.
+func Polylabel(g Geometry, precision float64) (Point, bool) {
+	var best Point
+	d := math.Inf(-1)
+	walkPolygons(g, func(p *Polygon) {
+		if q, dq, ok := polylabel(p, precision); ok && dq > d {
+			best, d = q, dq
+		}
+	})
+	return best, !math.IsInf(d, -1)
+}
+
+// polylabel returns the pole of inaccessibility of p and its distance from
+// the boundary of p.
+func polylabel(p *Polygon, precision float64) (Point, float64, bool) {
+	if len(p.Rings) == 0 || len(p.Rings[0].Points) == 0 {
+		return Point{}, 0, false
+	}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, q := range p.Rings[0].Points {
+		minX = math.Min(minX, q.X)
+		minY = math.Min(minY, q.Y)
+		maxX = math.Max(maxX, q.X)
+		maxY = math.Max(maxY, q.Y)
+	}
+	size := math.Min(maxX-minX, maxY-minY)
+	if size == 0 {
+		return Point{}, 0, false
+	}
+	if !(precision > 0) {
+		precision = size / 1000
+	}
+
+	// Cover the polygon with square cells.
+	var cells cellQueue
+	h := size / 2
+	for x := minX; x < maxX; x += size {
+		for y := minY; y < maxY; y += size {
+			heap.Push(&cells, newCell(x+h, y+h, h, p))
+		}
+	}
+	// Start from the better of the centroid and the center of the bounding
+	// box, which handles rectangles and thin polygons well.
+	best := newCell((minX+maxX)/2, (minY+maxY)/2, 0, p)
+	if c, ok := Centroid(p); ok {
+		if cc := newCell(c.X, c.Y, 0, p); cc.d > best.d {
+			best = cc
+		}
+	}
+	for cells.Len() > 0 {
+		c := heap.Pop(&cells).(*cell)
+		if c.d > best.d {
+			best = c
+		}
+		// Skip cells that cannot contain a better point.
+		if c.max-best.d <= precision {
+			continue
+		}
+		h := c.h / 2
+		heap.Push(&cells, newCell(c.x-h, c.y-h, h, p))
+		heap.Push(&cells, newCell(c.x+h, c.y-h, h, p))
+		heap.Push(&cells, newCell(c.x-h, c.y+h, h, p))
+		heap.Push(&cells, newCell(c.x+h, c.y+h, h, p))
+	}
+	if best.d <= 0 {
+		// A coarse precision can miss a thin polygon entirely.
+		q, _, ok := interiorPoint(p)
+		if !ok {
+			return Point{}, 0, false
+		}
+		return q, boundaryDistance(q, p), true
+	}
+	return Point{X: best.x, Y: best.y}, best.d, true
+}
+
+// A cell is a square with center x, y and half-size h.
+type cell struct {
+	x, y, h float64
+	// Signed distance from the center to the polygon boundary, which is
+	// positive inside the polygon, and the largest possible distance within
+	// the cell.
+	d, max float64
+}
+
+func newCell(x, y, h float64, p *Polygon) *cell {
+	d := boundaryDistance(Point{X: x, Y: y}, p)
+	return &cell{x: x, y: y, h: h, d: d, max: d + h*math.Sqrt2}
+}
+
+// cellQueue is a max-heap of cells ordered by their largest possible
+// distance.
+type cellQueue []*cell
+
+func (q cellQueue) Len() int            { return len(q) }
+func (q cellQueue) Less(i, j int) bool  { return q[i].max > q[j].max }
+func (q cellQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *cellQueue) Push(x interface{}) { *q = append(*q, x.(*cell)) }
+
+func (q *cellQueue) Pop() interface{} {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// boundaryDistance returns the distance from pt to the nearest ring of p,
+// which is negative if pt lies outside p.
+func boundaryDistance(pt Point, p *Polygon) float64 {
+	inside := false
+	d := math.Inf(1)
+	for _, ring := range p.Rings {
+		points := ring.Points
+		for i, j := 0, len(points)-1; i < len(points); j, i = i, i+1 {
+			a, b := points[j], points[i]
+			if (a.Y > pt.Y) != (b.Y > pt.Y) && pt.X < (b.X-a.X)*(pt.Y-a.Y)/(b.Y-a.Y)+a.X {
+				inside = !inside
+			}
+			d = math.Min(d, segmentDistance(pt, a, b))
+		}
+	}
+	if !inside {
+		return -d
+	}
+	return d
+}
+
+// segmentDistance returns the distance from p to the segment from a to b.
+func segmentDistance(p, a, b Point) float64 {
+	x, y := a.X, a.Y
+	dx, dy := b.X-x, b.Y-y
+	if dx != 0 || dy != 0 {
+		t := ((p.X-x)*dx + (p.Y-y)*dy) / (dx*dx + dy*dy)
+		if t > 1 {
+			x, y = b.X, b.Y
+		} else if t > 0 {
+			x += dx * t
+			y += dy * t
+		}
+	}
+	return math.Hypot(p.X-x, p.Y-y)
+}
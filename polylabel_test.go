@@ -0,0 +1,45 @@
+package geojson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPolylabel(t *testing.T) {
+	square := func(x0, y0, x1, y1 float64) LineString {
+		return LineString{Points: []Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}, {X: x0, Y: y0}}}
+	}
+	// The pole of this holed square is equidistant from the outer corner
+	// and the corner of the hole.
+	corner := (10 + 6*math.Sqrt2) / (1 + math.Sqrt2)
+	cases := []struct {
+		g        Geometry
+		expected Point
+		ok       bool
+	}{
+		{&Polygon{Rings: []LineString{square(0, 0, 2, 2)}}, Point{X: 1, Y: 1}, true},
+		{&Polygon{Rings: []LineString{square(0, 0, 10, 10), square(1, 1, 6, 6)}}, Point{X: corner, Y: corner}, true},
+		{&MultiPolygon{Polygons: []Polygon{{Rings: []LineString{square(0, 0, 1, 1)}}, {Rings: []LineString{square(2, 0, 6, 4)}}}}, Point{X: 4, Y: 2}, true},
+		{&LineString{Points: []Point{{X: 0, Y: 0}, {X: 1, Y: 1}}}, Point{}, false},
+		{&Polygon{Rings: []LineString{{Points: []Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 0}}}}}, Point{}, false},
+	}
+	for i, c := range cases {
+		p, ok := Polylabel(c.g, 1e-6)
+		if ok != c.ok || math.Abs(p.X-c.expected.X) > 1e-4 || math.Abs(p.Y-c.expected.Y) > 1e-4 {
+			t.Errorf("case %d: expected (%#v, %v), got (%#v, %v)", i, c.expected, c.ok, p, ok)
+		}
+	}
+
+	// Many points of a strip are equally far from its boundary.
+	strip := &Polygon{Rings: []LineString{square(0, 0, 100, 2)}}
+	p, ok := Polylabel(strip, 0)
+	if !ok || math.Abs(p.Y-1) > 2e-3 {
+		t.Errorf("expected a point on the middle of the strip, got (%#v, %v)", p, ok)
+	}
+	// A coarse precision still finds an interior point.
+	holed := &Polygon{Rings: []LineString{square(0, 0, 10, 10), square(1, 1, 9, 9)}}
+	p, ok = Polylabel(holed, 5)
+	if !ok || boundaryDistance(p, holed) <= 0 {
+		t.Errorf("expected an interior point, got (%#v, %v)", p, ok)
+	}
+}
@@ -0,0 +1,203 @@
+package geojson
+
+import "strings"
+
+// An IntersectionMatrix is a Dimensionally Extended 9-Intersection Model
+// (DE-9IM) matrix, which describes how two geometries a and b relate. Each
+// entry is the dimension of the intersection of the interior, boundary, or
+// exterior of a with that of b, or -1 if the intersection is empty. Entries
+// are indexed by Location; see At.
+//
+// The boundary of a polygon is its rings, the boundary of a line is its
+// ends, unless the line is closed, and a point has no boundary. Where lines
+// in a multi-geometry end at the same position, that position is on the
+// boundary only if an odd number of lines end there.
+type IntersectionMatrix [3][3]int
+
+// index maps locations to the conventional order of matrix rows and
+// columns: interior, boundary, exterior.
+func index(l Location) int {
+	return int(Interior - l)
+}
+
+// At returns the dimension of the intersection of location la of a with
+// location lb of b, or -1 if it is empty.
+func (m IntersectionMatrix) At(la, lb Location) int {
+	return m[index(la)][index(lb)]
+}
+
+func (m *IntersectionMatrix) set(la, lb Location, dim int) {
+	if i, j := index(la), index(lb); dim > m[i][j] {
+		m[i][j] = dim
+	}
+}
+
+// String returns the conventional form of m, such as "212101212", with F
+// standing for an empty intersection.
+func (m IntersectionMatrix) String() string {
+	var b strings.Builder
+	for _, row := range m {
+		for _, dim := range row {
+			if dim < 0 {
+				b.WriteByte('F')
+			} else {
+				b.WriteByte(byte('0' + dim))
+			}
+		}
+	}
+	return b.String()
+}
+
+// Matches reports whether m matches pattern, which is a string of nine
+// characters in the order of String. Each character is 'T' for a non-empty
+// intersection, 'F' for an empty one, '0', '1', or '2' for an intersection
+// of that dimension, or '*' for any intersection.
+func (m IntersectionMatrix) Matches(pattern string) bool {
+	if len(pattern) != 9 {
+		return false
+	}
+	for i, c := range pattern {
+		dim := m[i/3][i%3]
+		switch c {
+		case '*':
+		case 'T', 't':
+			if dim < 0 {
+				return false
+			}
+		case 'F', 'f':
+			if dim >= 0 {
+				return false
+			}
+		case '0', '1', '2':
+			if dim != int(c-'0') {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Relate returns the DE-9IM matrix of a and b.
+//
+// Geometries are related in the plane, treating longitude and latitude as
+// Cartesian coordinates, so edges are straight lines in degrees rather than
+// geodesics. Positions are compared exactly, except that the positions
+// where edges cross are rounded, and that positions closer than a
+// trillionth of the largest coordinate to each other or to an edge are
+// taken to meet, so that rounding does not separate them. Polygons must be
+// valid: rings must not cross, and the polygons of a MultiPolygon must not
+// overlap. Polygons within a GeometryCollection may overlap, and a position
+// in any of them, including on a ring that another of them covers, is in
+// the interior of the collection. The JSON-FG geometry types are ignored.
+func Relate(a, b Geometry) IntersectionMatrix {
+	m, _ := relate(a, b)
+	return m
+}
+
+// relate returns the DE-9IM matrix of a and b and their dimensions.
+func relate(a, b Geometry) (IntersectionMatrix, [2]int) {
+	ar := newArrangement(a, b)
+	m := IntersectionMatrix{{-1, -1, -1}, {-1, -1, -1}, {-1, -1, 2}}
+	for p, n := range ar.nodes {
+		m.set(ar.nodeLocation(0, p, n), ar.nodeLocation(1, p, n), 0)
+	}
+	for _, e := range ar.edges {
+		m.set(ar.edgeLocation(0, e), ar.edgeLocation(1, e), 1)
+		if !e.onRing[0] && !e.onRing[1] {
+			continue
+		}
+		la, ra := ar.sideLocations(0, e)
+		lb, rb := ar.sideLocations(1, e)
+		m.set(la, lb, 2)
+		m.set(ra, rb, 2)
+	}
+	return m, ar.dims
+}
+
+// Intersects reports whether a and b have at least one position in common.
+func Intersects(a, b Geometry) bool {
+	return !Disjoint(a, b)
+}
+
+// Disjoint reports whether a and b have no position in common.
+func Disjoint(a, b Geometry) bool {
+	return Relate(a, b).Matches("FF*FF****")
+}
+
+// Touches reports whether a and b meet only at their boundaries. Points
+// cannot touch each other, since they have no boundary.
+func Touches(a, b Geometry) bool {
+	m, dims := relate(a, b)
+	if dims[0] == 0 && dims[1] == 0 {
+		return false
+	}
+	return m.Matches("FT*******") || m.Matches("F**T*****") || m.Matches("F***T****")
+}
+
+// Crosses reports whether the interiors of a and b meet in a geometry of
+// lower dimension than the larger of them, such as a line that passes
+// through a polygon or two lines that cross at a point, and neither
+// contains the other.
+func Crosses(a, b Geometry) bool {
+	m, dims := relate(a, b)
+	switch {
+	case dims[0] < dims[1]:
+		return m.Matches("T*T******")
+	case dims[0] > dims[1]:
+		return m.Matches("T*****T**")
+	case dims[0] == 1:
+		return m.Matches("0********")
+	}
+	return false
+}
+
+// Within reports whether a lies in b without lying entirely on its
+// boundary.
+func Within(a, b Geometry) bool {
+	return Relate(a, b).Matches("T*F**F***")
+}
+
+// Contains reports whether b lies in a without lying entirely on its
+// boundary.
+func Contains(a, b Geometry) bool {
+	return Relate(a, b).Matches("T*****FF*")
+}
+
+// Overlaps reports whether a and b have the same dimension and their
+// interiors meet in that dimension, but neither covers the other.
+func Overlaps(a, b Geometry) bool {
+	m, dims := relate(a, b)
+	switch {
+	case dims[0] != dims[1]:
+		return false
+	case dims[0] == 1:
+		return m.Matches("1*T***T**")
+	}
+	return m.Matches("T*T***T**")
+}
+
+// Covers reports whether every position of b is a position of a. Unlike
+// Contains, b may lie entirely on the boundary of a.
+func Covers(a, b Geometry) bool {
+	m := Relate(a, b)
+	return m.Matches("T*****FF*") || m.Matches("*T****FF*") ||
+		m.Matches("***T**FF*") || m.Matches("****T*FF*")
+}
+
+// CoveredBy reports whether every position of a is a position of b.
+func CoveredBy(a, b Geometry) bool {
+	return Covers(b, a)
+}
+
+// Equals reports whether a and b are topologically equal: they cover the
+// same positions, regardless of their structure or the order of their
+// vertices. Empty geometries are equal.
+func Equals(a, b Geometry) bool {
+	m, dims := relate(a, b)
+	if dims[0] < 0 && dims[1] < 0 {
+		return true
+	}
+	return dims[0] == dims[1] && m.Matches("T*F**FFF*")
+}
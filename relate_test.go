@@ -0,0 +1,172 @@
+package geojson_test
+
+import (
+	"testing"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/wkt"
+)
+
+func mustWKT(t *testing.T, s string) geojson.Geometry {
+	g, err := wkt.Unmarshal(s)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", s, err)
+	}
+	return g
+}
+
+const (
+	square    = "POLYGON((0 0, 10 0, 10 10, 0 10, 0 0))"
+	holed     = "POLYGON((0 0, 10 0, 10 10, 0 10, 0 0), (2 2, 2 8, 8 8, 8 2, 2 2))"
+	hole      = "POLYGON((2 2, 8 2, 8 8, 2 8, 2 2))"
+	diagonals = "LINESTRING(0 0, 10 10)"
+	pairGC    = "GEOMETRYCOLLECTION(POLYGON((0 0, 10 0, 10 10, 0 10, 0 0)), POLYGON((10 0, 20 0, 20 10, 10 10, 10 0)))"
+	overlapGC = "GEOMETRYCOLLECTION(POLYGON((0 0, 10 0, 10 10, 0 10, 0 0)), POLYGON((5 0, 15 0, 15 10, 5 10, 5 0)))"
+)
+
+func TestRelate(t *testing.T) {
+	// Cases from the JTS relate tests.
+	cases := []struct {
+		a, b     string
+		expected string
+	}{
+		// Points and points.
+		{"POINT(20 20)", "POINT(20 20)", "0FFFFFFF2"},
+		{"POINT(20 20)", "POINT(20 30)", "FF0FFF0F2"},
+		{"MULTIPOINT((40 40), (20 20))", "MULTIPOINT((20 20), (30 30))", "0F0FFF0F2"},
+		// Points and lines.
+		{"POINT(0 0)", "LINESTRING(0 0, 10 0)", "F0FFFF102"},
+		{"POINT(5 0)", "LINESTRING(0 0, 10 0)", "0FFFFF102"},
+		{"POINT(0 0)", "LINESTRING(0 0, 10 0, 10 10, 0 0)", "0FFFFF1F2"},
+		{"POINT(20 20)", "LINESTRING(0 0, 10 0)", "FF0FFF102"},
+		// Points and polygons.
+		{"POINT(5 5)", square, "0FFFFF212"},
+		{"POINT(0 5)", square, "F0FFFF212"},
+		{"POINT(15 5)", square, "FF0FFF212"},
+		{"POINT(5 5)", holed, "FF0FFF212"},
+		// Lines and lines.
+		{diagonals, "LINESTRING(0 10, 10 0)", "0F1FF0102"},
+		{"LINESTRING(0 0, 10 0)", "LINESTRING(10 0, 0 0)", "1FFF0FFF2"},
+		{"LINESTRING(0 0, 10 0)", "LINESTRING(5 0, 15 0)", "1010F0102"},
+		{"LINESTRING(0 0, 10 0)", "LINESTRING(2 0, 5 0)", "101FF0FF2"},
+		{"LINESTRING(0 0, 10 0)", "LINESTRING(10 0, 20 10)", "FF1F00102"},
+		{"LINESTRING(0 0, 10 0)", "LINESTRING(5 0, 5 10)", "F01FF0102"},
+		{"MULTILINESTRING((0 0, 10 0), (10 0, 20 0))", "POINT(10 0)", "0F1FF0FF2"},
+		// Lines and polygons.
+		{"LINESTRING(2 2, 8 8)", square, "1FF0FF212"},
+		{"LINESTRING(-5 5, 15 5)", square, "101FF0212"},
+		{"LINESTRING(0 0, 10 0)", square, "F1FF0F212"},
+		{"LINESTRING(-5 5, 0 5)", square, "FF1F00212"},
+		{"LINESTRING(0 2, 0 8)", square, "F1FF0F212"},
+		{diagonals, holed, "101F0F212"},
+		// Polygons and polygons.
+		{square, "POLYGON((20 20, 30 20, 30 30, 20 30, 20 20))", "FF2FF1212"},
+		{square, "POLYGON((10 10, 10 0, 0 0, 0 10, 10 10))", "2FFF1FFF2"},
+		{square, "POLYGON((2 2, 5 2, 5 5, 2 5, 2 2))", "212FF1FF2"},
+		{square, "POLYGON((10 0, 20 0, 20 10, 10 10, 10 0))", "FF2F11212"},
+		{square, "POLYGON((10 10, 20 10, 20 20, 10 20, 10 10))", "FF2F01212"},
+		{square, "POLYGON((5 5, 15 5, 15 15, 5 15, 5 5))", "212101212"},
+		{square, "POLYGON((0 0, 5 0, 5 5, 0 5, 0 0))", "212F11FF2"},
+		// Edges that cross at rounded positions.
+		{"POLYGON((0 0, 10 0, 0 10, 0 0))", "POLYGON((1 1, 9 2, 2 9, 1 1))", "212101212"},
+		{holed, hole, "FF2F112F2"},
+		{holed, "POLYGON((4 4, 6 4, 6 6, 4 6, 4 4))", "FF2FF1212"},
+		// Collections.
+		{"GEOMETRYCOLLECTION(POLYGON((0 0, 10 0, 10 10, 0 10, 0 0)), POINT(5 5))", square, "2FFF1FFF2"},
+		{"GEOMETRYCOLLECTION(POINT(5 5), LINESTRING(20 0, 30 0))", square, "0F1FF0212"},
+		{"GEOMETRYCOLLECTION EMPTY", square, "FFFFFF212"},
+		// Polygons that share an edge, like the parcels of a coverage, are
+		// interior along it.
+		{pairGC, "POLYGON((5 2, 15 2, 15 8, 5 8, 5 2))", "212FF1FF2"},
+		{"MULTIPOLYGON(((0 0, 10 0, 10 10, 0 10, 0 0)), ((10 0, 20 0, 20 10, 10 10, 10 0)))", "POLYGON((5 2, 15 2, 15 8, 5 8, 5 2))", "212FF1FF2"},
+		{pairGC, "POLYGON((0 0, 20 0, 20 10, 0 10, 0 0))", "2FFF1FFF2"},
+		{"LINESTRING(10 2, 10 8)", pairGC, "1FF0FF212"},
+		{"POINT(10 10)", pairGC, "F0FFFF212"},
+		{"POINT(10 5)", pairGC, "0FFFFF212"},
+		// The point is off the line only due to rounding, since 3 * 0.1 is
+		// not 0.3 in floating point.
+		{"POINT(0.1 0.3)", "LINESTRING(0 0, 1 3)", "0FFFFF102"},
+		// Overlapping polygons in a collection are interior where another
+		// covers their rings.
+		{overlapGC, "POLYGON((0 0, 15 0, 15 10, 0 10, 0 0))", "2FFF1FFF2"},
+		{"LINESTRING(7 2, 7 8)", overlapGC, "1FF0FF212"},
+		{"POINT(5 10)", overlapGC, "F0FFFF212"},
+	}
+	for i, c := range cases {
+		a, b := mustWKT(t, c.a), mustWKT(t, c.b)
+		if m := geojson.Relate(a, b); m.String() != c.expected {
+			t.Errorf("case %d: expected %s, got %s", i, c.expected, m)
+		}
+		// The matrix of b and a is the transpose.
+		m := geojson.Relate(b, a)
+		for _, la := range []geojson.Location{geojson.Interior, geojson.Boundary, geojson.Exterior} {
+			for _, lb := range []geojson.Location{geojson.Interior, geojson.Boundary, geojson.Exterior} {
+				if m.At(lb, la) != geojson.Relate(a, b).At(la, lb) {
+					t.Errorf("case %d: expected transpose of %s, got %s", i, c.expected, m)
+				}
+			}
+		}
+	}
+}
+
+func TestIntersectionMatrix_Matches(t *testing.T) {
+	m := geojson.IntersectionMatrix{{2, 1, 2}, {1, 0, 1}, {2, 1, 2}}
+	for _, pattern := range []string{"212101212", "T*T***T**", "2********"} {
+		if !m.Matches(pattern) {
+			t.Errorf("expected %s to match %s", m, pattern)
+		}
+	}
+	for _, pattern := range []string{"FF*FF****", "1********", "212101", "X********"} {
+		if m.Matches(pattern) {
+			t.Errorf("expected %s not to match %s", m, pattern)
+		}
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	type predicate func(a, b geojson.Geometry) bool
+	cases := []struct {
+		name     string
+		p        predicate
+		a, b     string
+		expected bool
+	}{
+		{"Intersects", geojson.Intersects, square, "POINT(5 5)", true},
+		{"Intersects", geojson.Intersects, holed, "POINT(5 5)", false},
+		{"Disjoint", geojson.Disjoint, holed, "POINT(5 5)", true},
+		{"Touches", geojson.Touches, square, "POLYGON((10 0, 20 0, 20 10, 10 10, 10 0))", true},
+		{"Touches", geojson.Touches, square, "POLYGON((5 5, 15 5, 15 15, 5 15, 5 5))", false},
+		{"Touches", geojson.Touches, "POINT(0 0)", "POINT(0 0)", false},
+		{"Touches", geojson.Touches, "POINT(0 0)", "LINESTRING(0 0, 1 1)", true},
+		{"Crosses", geojson.Crosses, "LINESTRING(-5 5, 15 5)", square, true},
+		{"Crosses", geojson.Crosses, square, "LINESTRING(-5 5, 15 5)", true},
+		{"Crosses", geojson.Crosses, diagonals, "LINESTRING(0 10, 10 0)", true},
+		{"Crosses", geojson.Crosses, "LINESTRING(0 0, 10 0)", "LINESTRING(5 0, 15 0)", false},
+		{"Crosses", geojson.Crosses, "LINESTRING(2 2, 8 8)", square, false},
+		{"Within", geojson.Within, "POLYGON((2 2, 5 2, 5 5, 2 5, 2 2))", square, true},
+		{"Within", geojson.Within, "LINESTRING(0 0, 10 0)", square, false},
+		{"Contains", geojson.Contains, square, "LINESTRING(2 2, 8 8)", true},
+		{"Contains", geojson.Contains, square, "LINESTRING(0 0, 10 0)", false},
+		{"Contains", geojson.Contains, holed, hole, false},
+		{"Covers", geojson.Covers, square, "LINESTRING(0 0, 10 0)", true},
+		{"Covers", geojson.Covers, square, "POINT(0 0)", true},
+		{"Covers", geojson.Covers, square, "POINT(11 0)", false},
+		{"CoveredBy", geojson.CoveredBy, "LINESTRING(0 0, 10 0)", square, true},
+		{"Overlaps", geojson.Overlaps, square, "POLYGON((5 5, 15 5, 15 15, 5 15, 5 5))", true},
+		{"Overlaps", geojson.Overlaps, square, "POLYGON((2 2, 5 2, 5 5, 2 5, 2 2))", false},
+		{"Overlaps", geojson.Overlaps, "LINESTRING(0 0, 10 0)", "LINESTRING(5 0, 15 0)", true},
+		{"Overlaps", geojson.Overlaps, diagonals, "LINESTRING(0 10, 10 0)", false},
+		{"Overlaps", geojson.Overlaps, square, "LINESTRING(-5 5, 15 5)", false},
+		{"Equals", geojson.Equals, square, "POLYGON((10 10, 10 0, 0 0, 0 10, 10 10))", true},
+		{"Equals", geojson.Equals, "LINESTRING(0 0, 5 0, 10 0)", "MULTILINESTRING((10 0, 4 0), (4 0, 0 0))", true},
+		{"Equals", geojson.Equals, "GEOMETRYCOLLECTION(POLYGON((0 0, 10 0, 10 10, 0 10, 0 0)), POINT(5 5))", square, true},
+		{"Equals", geojson.Equals, "GEOMETRYCOLLECTION EMPTY", "MULTIPOINT EMPTY", true},
+		{"Equals", geojson.Equals, overlapGC, "POLYGON((0 0, 15 0, 15 10, 0 10, 0 0))", true},
+		{"Equals", geojson.Equals, holed, square, false},
+	}
+	for i, c := range cases {
+		if actual := c.p(mustWKT(t, c.a), mustWKT(t, c.b)); actual != c.expected {
+			t.Errorf("case %d: expected %s(%s, %s) to be %v", i, c.name, c.a, c.b, c.expected)
+		}
+	}
+}
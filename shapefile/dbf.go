@@ -0,0 +1,374 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	dbfVersion         = 0x03
+	dbfHeaderSize      = 32
+	dbfFieldSize       = 32
+	dbfHeaderEnd       = 0x0d
+	dbfEOF             = 0x1a
+	dbfDeleted         = '*'
+	maxFieldNameLength = 10
+	maxCharLength      = 254
+)
+
+// A dbfTable holds decoded .dbf contents.
+type dbfTable struct {
+	fields  []Field
+	records []map[string]interface{}
+	// Whether each record is marked as deleted.
+	deleted []bool
+}
+
+func parseDBF(b []byte) (*dbfTable, error) {
+	if len(b) < dbfHeaderSize {
+		return nil, fmt.Errorf("header too short: %d bytes", len(b))
+	}
+	numRecords := int(binary.LittleEndian.Uint32(b[4:]))
+	headerLength := int(binary.LittleEndian.Uint16(b[8:]))
+	recordLength := int(binary.LittleEndian.Uint16(b[10:]))
+	if headerLength > len(b) || headerLength < dbfHeaderSize+1 {
+		return nil, fmt.Errorf("invalid header length %d", headerLength)
+	}
+
+	t := &dbfTable{}
+	offset := 1
+	for pos := dbfHeaderSize; pos+dbfFieldSize <= headerLength && b[pos] != dbfHeaderEnd; pos += dbfFieldSize {
+		d := b[pos : pos+dbfFieldSize]
+		name := string(d[:maxFieldNameLength+1])
+		if i := strings.IndexByte(name, 0); i >= 0 {
+			name = name[:i]
+		}
+		f := Field{
+			Name:     strings.TrimSpace(name),
+			Type:     d[11],
+			Length:   int(d[16]),
+			Decimals: int(d[17]),
+		}
+		t.fields = append(t.fields, f)
+		offset += f.Length
+	}
+	if offset > recordLength {
+		return nil, fmt.Errorf("fields span %d bytes but records are %d bytes", offset, recordLength)
+	}
+
+	for i := 0; i < numRecords; i++ {
+		start := headerLength + i*recordLength
+		if start < len(b) && b[start] == dbfEOF {
+			break
+		}
+		if start+recordLength > len(b) {
+			return nil, fmt.Errorf("record %d truncated", i)
+		}
+		r := b[start : start+recordLength]
+		record := make(map[string]interface{}, len(t.fields))
+		pos := 1
+		for _, f := range t.fields {
+			v, err := parseValue(&f, r[pos:pos+f.Length])
+			if err != nil {
+				return nil, fmt.Errorf("record %d: field %q: %v", i, f.Name, err)
+			}
+			record[f.Name] = v
+			pos += f.Length
+		}
+		t.records = append(t.records, record)
+		t.deleted = append(t.deleted, r[0] == dbfDeleted)
+	}
+	return t, nil
+}
+
+// parseValue decodes a single field value. Blank numeric, logical, and date
+// values decode as nil.
+func parseValue(f *Field, raw []byte) (interface{}, error) {
+	s := strings.TrimSpace(string(bytes.TrimRight(raw, "\x00")))
+	switch f.Type {
+	case 'C':
+		return s, nil
+	case 'N', 'F':
+		if s == "" || strings.Trim(s, "*") == "" {
+			return nil, nil
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	case 'L':
+		switch s {
+		case "T", "t", "Y", "y":
+			return true, nil
+		case "F", "f", "N", "n":
+			return false, nil
+		}
+		return nil, nil
+	case 'D':
+		if len(s) != 8 {
+			return nil, nil
+		}
+		return s[0:4] + "-" + s[4:6] + "-" + s[6:8], nil
+	}
+	// Unknown types (e.g., memo references) are passed through as text.
+	return s, nil
+}
+
+// inferFields chooses a column for each property name present in records.
+// Properties whose non-nil values are all numbers become numeric columns,
+// all booleans become logical columns, and anything else becomes character
+// columns holding strings or JSON. Since field names may be truncated, the
+// original property name of each field is returned alongside it.
+func inferFields(records []map[string]interface{}) ([]Field, []string, error) {
+	kinds := map[string]byte{}
+	for _, r := range records {
+		for k, v := range r {
+			var kind byte
+			switch v.(type) {
+			case nil:
+				if _, ok := kinds[k]; !ok {
+					kinds[k] = 0
+				}
+				continue
+			case float64:
+				kind = 'N'
+			case bool:
+				kind = 'L'
+			default:
+				kind = 'C'
+			}
+			if prev, ok := kinds[k]; ok && prev != 0 && prev != kind {
+				kind = 'C'
+			}
+			kinds[k] = kind
+		}
+	}
+
+	names := make([]string, 0, len(kinds))
+	for k := range kinds {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	used := map[string]string{}
+	fields := make([]Field, len(names))
+	for i, k := range names {
+		name := k
+		if len(name) > maxFieldNameLength {
+			name = name[:maxFieldNameLength]
+		}
+		if prev, ok := used[name]; ok {
+			return nil, nil, fmt.Errorf("properties %q and %q both map to field %q", prev, k, name)
+		}
+		used[name] = k
+		f := Field{Name: name, Type: kinds[k]}
+		switch f.Type {
+		case 'N':
+			f.Type, f.Length, f.Decimals = numericLayout(records, k)
+		case 'L':
+			f.Length = 1
+		default:
+			f.Type = 'C'
+			f.Length = 1
+			for _, r := range records {
+				s, err := characterValue(r[k])
+				if err != nil {
+					return nil, nil, fmt.Errorf("property %q: %v", k, err)
+				}
+				if len(s) > f.Length {
+					f.Length = len(s)
+				}
+			}
+			if f.Length > maxCharLength {
+				f.Length = maxCharLength
+			}
+		}
+		fields[i] = f
+	}
+	return fields, names, nil
+}
+
+// maxIntegerDigits is the longest integer part, including its sign, written
+// in fixed-point form.
+const maxIntegerDigits = 19
+
+// numericLayout picks a type, width, and decimal count wide enough for every
+// value of property k: room for a sign, the longest integer part, and, if any
+// value has a fraction, a point and the longest fraction. If an integer part
+// is too long, an 'F' field is used instead, in which such values are
+// written in exponent form.
+func numericLayout(records []map[string]interface{}, k string) (typ byte, length, decimals int) {
+	for _, r := range records {
+		v, ok := r[k].(float64)
+		if !ok {
+			continue
+		}
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if i := strings.IndexByte(s, '.'); i >= 0 && len(s)-i-1 > decimals {
+			decimals = len(s) - i - 1
+		}
+	}
+	if decimals > 15 {
+		decimals = 15
+	}
+	typ = 'N'
+	sign, digits := 0, 1
+	var exponent []float64
+	for _, r := range records {
+		v, ok := r[k].(float64)
+		if !ok {
+			continue
+		}
+		// Rounding to the decimal count may carry into the integer part.
+		s := strconv.FormatFloat(v, 'f', decimals, 64)
+		if i := strings.IndexByte(s, '.'); i >= 0 {
+			s = s[:i]
+		}
+		if len(s) > maxIntegerDigits {
+			typ = 'F'
+			exponent = append(exponent, v)
+			continue
+		}
+		if s[0] == '-' {
+			sign = 1
+			s = s[1:]
+		}
+		if len(s) > digits {
+			digits = len(s)
+		}
+	}
+	length = sign + digits
+	if decimals > 0 {
+		length += 1 + decimals
+	}
+	for _, v := range exponent {
+		if s := strconv.FormatFloat(v, 'e', -1, 64); len(s) > length {
+			length = len(s)
+		}
+	}
+	return typ, length, decimals
+}
+
+func characterValue(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// encodeDBF encodes records with the given fields. Record values are looked
+// up by the property names in keys, which parallel fields.
+func encodeDBF(fields []Field, keys []string, records []map[string]interface{}) ([]byte, error) {
+	recordLength := 1
+	for _, f := range fields {
+		recordLength += f.Length
+	}
+	headerLength := dbfHeaderSize + dbfFieldSize*len(fields) + 1
+	if headerLength > math.MaxUint16 || recordLength > math.MaxUint16 {
+		return nil, fmt.Errorf("too many fields")
+	}
+
+	b := make([]byte, dbfHeaderSize, headerLength+recordLength*len(records)+1)
+	now := time.Now().UTC()
+	b[0] = dbfVersion
+	b[1] = byte(now.Year() - 1900)
+	b[2] = byte(now.Month())
+	b[3] = byte(now.Day())
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(records)))
+	binary.LittleEndian.PutUint16(b[8:], uint16(headerLength))
+	binary.LittleEndian.PutUint16(b[10:], uint16(recordLength))
+	for _, f := range fields {
+		var d [dbfFieldSize]byte
+		copy(d[:maxFieldNameLength], f.Name)
+		d[11] = f.Type
+		d[16] = byte(f.Length)
+		d[17] = byte(f.Decimals)
+		b = append(b, d[:]...)
+	}
+	b = append(b, dbfHeaderEnd)
+
+	for i, r := range records {
+		b = append(b, ' ')
+		for j, f := range fields {
+			s, err := formatValue(&f, r[keys[j]])
+			if err != nil {
+				return nil, fmt.Errorf("record %d: field %q: %v", i, f.Name, err)
+			}
+			b = append(b, s...)
+		}
+	}
+	return append(b, dbfEOF), nil
+}
+
+// formatValue formats v to exactly f.Length bytes.
+func formatValue(f *Field, v interface{}) (string, error) {
+	var s string
+	switch f.Type {
+	case 'N', 'F':
+		switch t := v.(type) {
+		case nil:
+		case float64:
+			s = strconv.FormatFloat(t, 'f', f.Decimals, 64)
+			if len(s) > f.Length && f.Type == 'F' {
+				s = strconv.FormatFloat(t, 'e', -1, 64)
+			}
+			if len(s) > f.Length {
+				return "", fmt.Errorf("value %v does not fit in %d bytes", t, f.Length)
+			}
+		default:
+			return "", fmt.Errorf("expected number, got %T", v)
+		}
+		return strings.Repeat(" ", f.Length-len(s)) + s, nil
+	case 'L':
+		switch t := v.(type) {
+		case nil:
+			s = "?"
+		case bool:
+			s = "F"
+			if t {
+				s = "T"
+			}
+		default:
+			return "", fmt.Errorf("expected bool, got %T", v)
+		}
+	case 'D':
+		t, ok := v.(string)
+		if v != nil && !ok {
+			return "", fmt.Errorf("expected date string, got %T", v)
+		}
+		s = strings.Replace(t, "-", "", -1)
+	default:
+		var err error
+		s, err = characterValue(v)
+		if err != nil {
+			return "", err
+		}
+	}
+	if len(s) > f.Length {
+		// Truncate, taking care not to split a UTF-8 sequence.
+		s = s[:f.Length]
+		for len(s) > 0 {
+			r, size := utf8.DecodeLastRuneInString(s)
+			if r != utf8.RuneError || size != 1 {
+				break
+			}
+			s = s[:len(s)-1]
+		}
+	}
+	return s + strings.Repeat(" ", f.Length-len(s)), nil
+}
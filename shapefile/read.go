@@ -0,0 +1,259 @@
+package shapefile
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bsidhom/geojson"
+)
+
+// A Source holds the component files of a shapefile for reading.
+type Source struct {
+	// Geometry file. Required.
+	SHP io.Reader
+	// Index file. Optional; if present, records are located through the
+	// index rather than by scanning.
+	SHX io.Reader
+	// Attribute file. Optional.
+	DBF io.Reader
+	// Projection file. Optional.
+	PRJ io.Reader
+}
+
+// Read decodes a shapefile into a FeatureCollection with one Feature per
+// record. Records marked as deleted in the .dbf file are skipped.
+func Read(src *Source) (*geojson.FeatureCollection, *Metadata, error) {
+	if src.SHP == nil {
+		return nil, nil, fmt.Errorf("shapefile: missing .shp file")
+	}
+	shp, err := ioutil.ReadAll(src.SHP)
+	if err != nil {
+		return nil, nil, fmt.Errorf("shapefile: %v", err)
+	}
+	h, err := parseHeader(shp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("shapefile: .shp: %v", err)
+	}
+	meta := &Metadata{ShapeType: h.shapeType}
+
+	var offsets []int
+	if src.SHX != nil {
+		shx, err := ioutil.ReadAll(src.SHX)
+		if err != nil {
+			return nil, nil, fmt.Errorf("shapefile: %v", err)
+		}
+		offsets, err = parseIndex(shx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("shapefile: .shx: %v", err)
+		}
+	} else {
+		offsets = scanRecords(shp, h)
+	}
+
+	var table *dbfTable
+	if src.DBF != nil {
+		dbf, err := ioutil.ReadAll(src.DBF)
+		if err != nil {
+			return nil, nil, fmt.Errorf("shapefile: %v", err)
+		}
+		table, err = parseDBF(dbf)
+		if err != nil {
+			return nil, nil, fmt.Errorf("shapefile: .dbf: %v", err)
+		}
+		if len(table.records) != len(offsets) {
+			return nil, nil, fmt.Errorf("shapefile: .shp has %d records but .dbf has %d", len(offsets), len(table.records))
+		}
+		meta.Fields = table.fields
+	}
+
+	if src.PRJ != nil {
+		prj, err := ioutil.ReadAll(src.PRJ)
+		if err != nil {
+			return nil, nil, fmt.Errorf("shapefile: %v", err)
+		}
+		meta.Projection = strings.TrimSpace(string(prj))
+	}
+
+	fc := &geojson.FeatureCollection{}
+	for i, offset := range offsets {
+		if table != nil && table.deleted[i] {
+			continue
+		}
+		content, err := recordContent(shp, offset)
+		if err != nil {
+			return nil, nil, fmt.Errorf("shapefile: record %d: %v", i, err)
+		}
+		g, err := decodeShape(content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("shapefile: record %d: %v", i, err)
+		}
+		f := geojson.Feature{Geometry: g, Properties: map[string]interface{}{}}
+		if table != nil {
+			f.Properties = table.records[i]
+		}
+		fc.Features = append(fc.Features, f)
+	}
+	return fc, meta, nil
+}
+
+// parseIndex returns the byte offsets of all records listed in a .shx file.
+func parseIndex(shx []byte) ([]int, error) {
+	_, err := parseHeader(shx)
+	if err != nil {
+		return nil, err
+	}
+	n := (len(shx) - headerSize) / 8
+	offsets := make([]int, n)
+	for i := range offsets {
+		offsets[i] = int(binary.BigEndian.Uint32(shx[headerSize+8*i:])) * 2
+	}
+	return offsets, nil
+}
+
+// scanRecords returns the byte offsets of all records in a .shp file by
+// walking their length prefixes.
+func scanRecords(shp []byte, h *header) []int {
+	end := h.length
+	if end > len(shp) {
+		end = len(shp)
+	}
+	var offsets []int
+	for pos := headerSize; pos+8 <= end; {
+		offsets = append(offsets, pos)
+		length := int(binary.BigEndian.Uint32(shp[pos+4:])) * 2
+		pos += 8 + length
+	}
+	return offsets
+}
+
+// recordContent returns the content of the record at offset, without its
+// 8-byte record header.
+func recordContent(shp []byte, offset int) ([]byte, error) {
+	if offset < headerSize || offset+8 > len(shp) {
+		return nil, fmt.Errorf("offset %d out of range", offset)
+	}
+	length := int(binary.BigEndian.Uint32(shp[offset+4:])) * 2
+	start := offset + 8
+	if start+length > len(shp) {
+		return nil, fmt.Errorf("record extends past end of file")
+	}
+	return shp[start : start+length], nil
+}
+
+// ReadFile reads the shapefile at name, which may be given with or without
+// its .shp extension. Sibling .shx, .dbf, and .prj files are used if they
+// exist. Extensions are matched in lower and upper case.
+func ReadFile(name string) (*geojson.FeatureCollection, *Metadata, error) {
+	base := name
+	if ext := filepath.Ext(name); strings.EqualFold(ext, ".shp") {
+		base = strings.TrimSuffix(name, ext)
+	}
+	open := func(ext string, required bool) (*os.File, error) {
+		for _, e := range []string{ext, strings.ToUpper(ext)} {
+			f, err := os.Open(base + e)
+			if err == nil {
+				return f, nil
+			}
+			if !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+		if required {
+			return nil, fmt.Errorf("shapefile: %s%s does not exist", base, ext)
+		}
+		return nil, nil
+	}
+
+	src := &Source{}
+	var files []*os.File
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	for _, c := range []struct {
+		ext      string
+		required bool
+		r        *io.Reader
+	}{
+		{".shp", true, &src.SHP},
+		{".shx", false, &src.SHX},
+		{".dbf", false, &src.DBF},
+		{".prj", false, &src.PRJ},
+	} {
+		f, err := open(c.ext, c.required)
+		if err != nil {
+			return nil, nil, err
+		}
+		if f != nil {
+			files = append(files, f)
+			*c.r = f
+		}
+	}
+	return Read(src)
+}
+
+// ReadZip reads a shapefile from a zip archive. The layer is the base name
+// of the .shp file within the archive, without extension. If layer is empty,
+// the archive must contain exactly one .shp file.
+func ReadZip(r io.ReaderAt, size int64, layer string) (*geojson.FeatureCollection, *Metadata, error) {
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, nil, fmt.Errorf("shapefile: %v", err)
+	}
+	byName := map[string]*zip.File{}
+	var layers []string
+	for _, f := range z.File {
+		ext := strings.ToLower(path.Ext(f.Name))
+		base := strings.TrimSuffix(f.Name, path.Ext(f.Name))
+		byName[base+ext] = f
+		if ext == ".shp" && !strings.HasPrefix(path.Base(f.Name), "._") {
+			layers = append(layers, base)
+		}
+	}
+	if layer == "" {
+		if len(layers) != 1 {
+			return nil, nil, fmt.Errorf("shapefile: archive contains %d .shp files, expected 1", len(layers))
+		}
+		layer = layers[0]
+	}
+
+	src := &Source{}
+	var closers []io.Closer
+	defer func() {
+		for _, c := range closers {
+			c.Close()
+		}
+	}()
+	for _, c := range []struct {
+		ext string
+		r   *io.Reader
+	}{
+		{".shp", &src.SHP},
+		{".shx", &src.SHX},
+		{".dbf", &src.DBF},
+		{".prj", &src.PRJ},
+	} {
+		f, ok := byName[layer+c.ext]
+		if !ok {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, nil, fmt.Errorf("shapefile: %v", err)
+		}
+		closers = append(closers, rc)
+		*c.r = rc
+	}
+	if src.SHP == nil {
+		return nil, nil, fmt.Errorf("shapefile: layer %q not found in archive", layer)
+	}
+	return Read(src)
+}
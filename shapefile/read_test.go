@@ -0,0 +1,260 @@
+package shapefile
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+// buildSHP assembles a .shp file from raw record contents.
+func buildSHP(t ShapeType, contents ...[]byte) []byte {
+	b := make([]byte, headerSize)
+	for i, c := range contents {
+		var rh [8]byte
+		binary.BigEndian.PutUint32(rh[0:], uint32(i+1))
+		binary.BigEndian.PutUint32(rh[4:], uint32(len(c)/2))
+		b = append(b, rh[:]...)
+		b = append(b, c...)
+	}
+	h := &header{length: len(b), shapeType: t}
+	copy(b, h.appendTo(nil))
+	return b
+}
+
+func appendXY(b []byte, coords ...float64) []byte {
+	for _, c := range coords {
+		b = appendFloat64(b, c)
+	}
+	return b
+}
+
+func TestDecodeShape(t *testing.T) {
+	// Outer ring clockwise, hole counter-clockwise, followed by a second
+	// clockwise outer ring.
+	polygon := appendInt32(nil, int32(Polygon))
+	polygon = appendXY(polygon, 0, 0, 20, 4)
+	polygon = appendInt32(polygon, 3)
+	polygon = appendInt32(polygon, 15)
+	polygon = appendInt32(polygon, 0)
+	polygon = appendInt32(polygon, 5)
+	polygon = appendInt32(polygon, 10)
+	polygon = appendXY(polygon, 0, 0, 0, 4, 4, 4, 4, 0, 0, 0)
+	polygon = appendXY(polygon, 1, 1, 2, 1, 2, 2, 1, 2, 1, 1)
+	polygon = appendXY(polygon, 10, 0, 10, 1, 11, 1, 11, 0, 10, 0)
+
+	// A PolyLineM with a single part; M values are dropped.
+	lineM := appendInt32(nil, int32(PolyLineM))
+	lineM = appendXY(lineM, 0, 0, 1, 1)
+	lineM = appendInt32(lineM, 1)
+	lineM = appendInt32(lineM, 2)
+	lineM = appendInt32(lineM, 0)
+	lineM = appendXY(lineM, 0, 0, 1, 1)
+	lineM = appendXY(lineM, 5, 6, 5, 6)
+
+	// A PolygonZ with a single clockwise ring.
+	polygonZ := appendInt32(nil, int32(PolygonZ))
+	polygonZ = appendXY(polygonZ, 0, 0, 1, 1)
+	polygonZ = appendInt32(polygonZ, 1)
+	polygonZ = appendInt32(polygonZ, 4)
+	polygonZ = appendInt32(polygonZ, 0)
+	polygonZ = appendXY(polygonZ, 0, 0, 0, 1, 1, 0, 0, 0)
+	polygonZ = appendXY(polygonZ, 1, 3)
+	polygonZ = appendXY(polygonZ, 1, 2, 3, 1)
+
+	pointM := appendInt32(nil, int32(PointM))
+	pointM = appendXY(pointM, 7, 8, 9)
+
+	cases := []struct {
+		b        []byte
+		expected geojson.Geometry
+	}{
+		{
+			b: polygon,
+			expected: &geojson.MultiPolygon{
+				Polygons: []geojson.Polygon{
+					{
+						Rings: []geojson.LineString{
+							{Points: []geojson.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}, {X: 0, Y: 0}}},
+							{Points: []geojson.Point{{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 1}, {X: 1, Y: 1}}},
+						},
+					},
+					{
+						Rings: []geojson.LineString{
+							{Points: []geojson.Point{{X: 10, Y: 0}, {X: 11, Y: 0}, {X: 11, Y: 1}, {X: 10, Y: 1}, {X: 10, Y: 0}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			b: lineM,
+			expected: &geojson.LineString{
+				Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}},
+			},
+		},
+		{
+			b: polygonZ,
+			expected: &geojson.Polygon{
+				Rings: []geojson.LineString{
+					{
+						Points: []geojson.Point{
+							{X: 0, Y: 0, Elevation: 1, HasElevation: true},
+							{X: 1, Y: 0, Elevation: 3, HasElevation: true},
+							{X: 0, Y: 1, Elevation: 2, HasElevation: true},
+							{X: 0, Y: 0, Elevation: 1, HasElevation: true},
+						},
+					},
+				},
+			},
+		},
+		{
+			b:        pointM,
+			expected: &geojson.Point{X: 7, Y: 8},
+		},
+		{
+			b:        appendInt32(nil, int32(Null)),
+			expected: nil,
+		},
+	}
+	for i, c := range cases {
+		g, err := decodeShape(c.b)
+		if err != nil {
+			t.Errorf("error decoding case %d: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(g, c.expected) {
+			t.Errorf("case %d failed: expected %#v, got %#v", i, c.expected, g)
+		}
+	}
+}
+
+func TestDecodeShape_Invalid(t *testing.T) {
+	truncated := appendInt32(nil, int32(PolyLine))
+	truncated = appendXY(truncated, 0, 0, 1, 1)
+	truncated = appendInt32(truncated, 1)
+	truncated = appendInt32(truncated, 100)
+	cases := [][]byte{
+		{},
+		appendInt32(nil, int32(Point)),
+		appendInt32(nil, int32(MultiPatch)),
+		truncated,
+	}
+	for i, c := range cases {
+		_, err := decodeShape(c)
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestRead_WithoutIndex(t *testing.T) {
+	shp := buildSHP(Point,
+		appendXY(appendInt32(nil, int32(Point)), 1, 2),
+		appendInt32(nil, int32(Null)),
+	)
+	fc, meta, err := Read(&Source{
+		SHP: bytes.NewReader(shp),
+		PRJ: bytes.NewReader([]byte("GEOGCS[\"WGS 84\"]\n")),
+	})
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	expected := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{Geometry: &geojson.Point{X: 1, Y: 2}, Properties: map[string]interface{}{}},
+			{Properties: map[string]interface{}{}},
+		},
+	}
+	if !reflect.DeepEqual(fc, expected) {
+		t.Errorf("expected %#v, got %#v", expected, fc)
+	}
+	expectedMeta := &Metadata{ShapeType: Point, Projection: `GEOGCS["WGS 84"]`}
+	if !reflect.DeepEqual(meta, expectedMeta) {
+		t.Errorf("expected %#v, got %#v", expectedMeta, meta)
+	}
+}
+
+func TestReadZip(t *testing.T) {
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				Geometry:   &geojson.Point{X: 1, Y: 2},
+				Properties: map[string]interface{}{"name": "a"},
+			},
+		},
+	}
+	var shp, shx, dbf bytes.Buffer
+	err := Write(&Sink{SHP: &shp, SHX: &shx, DBF: &dbf}, fc, nil)
+	if err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, b := range map[string][]byte{
+		"data/roads.SHP": shp.Bytes(),
+		"data/roads.shx": shx.Bytes(),
+		"data/roads.dbf": dbf.Bytes(),
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+		w.Write(b)
+	}
+	err = zw.Close()
+	if err != nil {
+		t.Fatalf("failed to close archive: %v", err)
+	}
+
+	got, _, err := ReadZip(bytes.NewReader(archive.Bytes()), int64(archive.Len()), "")
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+	if !reflect.DeepEqual(got, fc) {
+		t.Errorf("expected %#v, got %#v", fc, got)
+	}
+	_, _, err = ReadZip(bytes.NewReader(archive.Bytes()), int64(archive.Len()), "rivers")
+	if err == nil {
+		t.Errorf("expected error for missing layer")
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shapefile")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				Geometry:   &geojson.MultiPoint{Points: []geojson.Point{{X: 1, Y: 2}, {X: 3, Y: 4}}},
+				Properties: map[string]interface{}{"n": float64(1)},
+			},
+		},
+	}
+	meta := &Metadata{Projection: `GEOGCS["WGS 84"]`}
+	name := filepath.Join(dir, "points.shp")
+	err = WriteFile(name, fc, meta)
+	if err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	got, gotMeta, err := ReadFile(filepath.Join(dir, "points"))
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if !reflect.DeepEqual(got, fc) {
+		t.Errorf("expected %#v, got %#v", fc, got)
+	}
+	if gotMeta.Projection != meta.Projection {
+		t.Errorf("expected projection %q, got %q", meta.Projection, gotMeta.Projection)
+	}
+}
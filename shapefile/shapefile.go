@@ -0,0 +1,119 @@
+// Package shapefile reads and writes ESRI Shapefiles as GeoJSON features.
+//
+// A shapefile is a set of sibling files sharing a base name: the .shp file
+// holds geometries, the .shx file indexes them, the .dbf file holds one
+// attribute record per geometry, and the optional .prj file describes the
+// coordinate reference system as WKT. Attributes become Feature properties.
+// The .prj text is not interpreted; it is passed through as Metadata.
+//
+// Shapefile polygons wind their outer rings clockwise and their holes
+// counter-clockwise. This package reverses both when reading so that
+// polygons follow the RFC 7946 convention documented on geojson.Polygon, and
+// reverses them back when writing.
+//
+// M (measure) values have no GeoJSON equivalent. They are discarded when
+// reading. Records of the M and Z shape types are written with an M range
+// and M values of "no data", since the format requires them.
+package shapefile
+
+import "fmt"
+
+// A ShapeType identifies the kind of geometry stored in a shapefile.
+type ShapeType int32
+
+// Shape types defined by the ESRI Shapefile Technical Description.
+const (
+	Null        ShapeType = 0
+	Point       ShapeType = 1
+	PolyLine    ShapeType = 3
+	Polygon     ShapeType = 5
+	MultiPoint  ShapeType = 8
+	PointZ      ShapeType = 11
+	PolyLineZ   ShapeType = 13
+	PolygonZ    ShapeType = 15
+	MultiPointZ ShapeType = 18
+	PointM      ShapeType = 21
+	PolyLineM   ShapeType = 23
+	PolygonM    ShapeType = 25
+	MultiPointM ShapeType = 28
+	MultiPatch  ShapeType = 31
+)
+
+var shapeTypeNames = map[ShapeType]string{
+	Null:        "Null",
+	Point:       "Point",
+	PolyLine:    "PolyLine",
+	Polygon:     "Polygon",
+	MultiPoint:  "MultiPoint",
+	PointZ:      "PointZ",
+	PolyLineZ:   "PolyLineZ",
+	PolygonZ:    "PolygonZ",
+	MultiPointZ: "MultiPointZ",
+	PointM:      "PointM",
+	PolyLineM:   "PolyLineM",
+	PolygonM:    "PolygonM",
+	MultiPointM: "MultiPointM",
+	MultiPatch:  "MultiPatch",
+}
+
+func (t ShapeType) String() string {
+	if name, ok := shapeTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("ShapeType(%d)", int32(t))
+}
+
+// base returns the 2D shape type underlying t, e.g., Polygon for PolygonZ.
+func (t ShapeType) base() ShapeType {
+	switch t {
+	case PointZ, PointM:
+		return Point
+	case PolyLineZ, PolyLineM:
+		return PolyLine
+	case PolygonZ, PolygonM:
+		return Polygon
+	case MultiPointZ, MultiPointM:
+		return MultiPoint
+	}
+	return t
+}
+
+// hasZ reports whether records of type t carry Z values.
+func (t ShapeType) hasZ() bool {
+	switch t {
+	case PointZ, PolyLineZ, PolygonZ, MultiPointZ, MultiPatch:
+		return true
+	}
+	return false
+}
+
+// hasM reports whether records of type t may carry M values.
+func (t ShapeType) hasM() bool {
+	return t.hasZ() || t.base() != t
+}
+
+// Metadata describes a shapefile as a whole.
+type Metadata struct {
+	// Shape type declared in the .shp header. Individual records are either
+	// of this type or Null.
+	ShapeType ShapeType
+	// Attribute fields, in .dbf column order.
+	Fields []Field
+	// Contents of the .prj file, if any. This is usually an ESRI flavor of
+	// WKT describing the coordinate reference system.
+	Projection string
+}
+
+// A Field describes a .dbf attribute column.
+type Field struct {
+	// Column name. At most 10 bytes.
+	Name string
+	// dBASE type code: 'C' (character), 'N' or 'F' (numeric), 'L'
+	// (logical), or 'D' (date). Values of 'F' columns that do not fit in
+	// fixed-point form are written in exponent form.
+	Type byte
+	// Width of the column in bytes.
+	Length int
+	// Number of digits after the decimal point, for numeric columns.
+	Decimals int
+}
@@ -0,0 +1,352 @@
+package shapefile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/ring"
+)
+
+const (
+	fileCode   = 9994
+	version    = 1000
+	headerSize = 100
+)
+
+// header is the 100-byte header shared by .shp and .shx files.
+type header struct {
+	// File length in bytes.
+	length    int
+	shapeType ShapeType
+	// Xmin, Ymin, Xmax, Ymax, Zmin, Zmax, Mmin, Mmax.
+	bbox [8]float64
+}
+
+func parseHeader(b []byte) (*header, error) {
+	if len(b) < headerSize {
+		return nil, fmt.Errorf("header too short: %d bytes", len(b))
+	}
+	if code := binary.BigEndian.Uint32(b[0:]); code != fileCode {
+		return nil, fmt.Errorf("invalid file code %d", code)
+	}
+	if v := binary.LittleEndian.Uint32(b[28:]); v != version {
+		return nil, fmt.Errorf("unsupported version %d", v)
+	}
+	h := &header{
+		length:    int(binary.BigEndian.Uint32(b[24:])) * 2,
+		shapeType: ShapeType(binary.LittleEndian.Uint32(b[32:])),
+	}
+	for i := range h.bbox {
+		h.bbox[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[36+8*i:]))
+	}
+	return h, nil
+}
+
+func (h *header) appendTo(b []byte) []byte {
+	var buf [headerSize]byte
+	binary.BigEndian.PutUint32(buf[0:], fileCode)
+	binary.BigEndian.PutUint32(buf[24:], uint32(h.length/2))
+	binary.LittleEndian.PutUint32(buf[28:], version)
+	binary.LittleEndian.PutUint32(buf[32:], uint32(h.shapeType))
+	for i, v := range h.bbox {
+		binary.LittleEndian.PutUint64(buf[36+8*i:], math.Float64bits(v))
+	}
+	return append(b, buf[:]...)
+}
+
+// A cursor decodes little-endian values from a shape record, remembering the
+// first out-of-bounds read.
+type cursor struct {
+	b   []byte
+	pos int
+	err error
+}
+
+func (c *cursor) take(n int) []byte {
+	if c.err != nil {
+		return nil
+	}
+	if n < 0 || c.pos+n > len(c.b) {
+		c.err = fmt.Errorf("record truncated at byte %d", c.pos)
+		return nil
+	}
+	b := c.b[c.pos : c.pos+n]
+	c.pos += n
+	return b
+}
+
+func (c *cursor) int32() int {
+	b := c.take(4)
+	if b == nil {
+		return 0
+	}
+	return int(int32(binary.LittleEndian.Uint32(b)))
+}
+
+func (c *cursor) float64() float64 {
+	b := c.take(8)
+	if b == nil {
+		return 0
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
+
+func (c *cursor) skip(n int) {
+	c.take(n)
+}
+
+// decodeShape decodes the content of a single .shp record. It returns a nil
+// Geometry for Null shapes.
+func decodeShape(b []byte) (geojson.Geometry, error) {
+	c := &cursor{b: b}
+	t := ShapeType(c.int32())
+	if c.err != nil {
+		return nil, c.err
+	}
+	var g geojson.Geometry
+	var err error
+	switch t.base() {
+	case Null:
+		return nil, nil
+	case Point:
+		p := &geojson.Point{X: c.float64(), Y: c.float64()}
+		if t.hasZ() {
+			p.Elevation = c.float64()
+			p.HasElevation = true
+		}
+		// Trailing M values are ignored.
+		g = p
+	case MultiPoint:
+		c.skip(32)
+		n := c.int32()
+		points := readPoints(c, n, t.hasZ())
+		g = &geojson.MultiPoint{Points: points}
+	case PolyLine, Polygon:
+		g, err = decodeParts(c, t)
+	default:
+		return nil, fmt.Errorf("unsupported shape type %v", t)
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return g, err
+}
+
+func decodeParts(c *cursor, t ShapeType) (geojson.Geometry, error) {
+	c.skip(32)
+	numParts := c.int32()
+	numPoints := c.int32()
+	if c.err != nil {
+		return nil, c.err
+	}
+	if numParts < 0 || numPoints < 0 || numParts*4 > len(c.b) || numPoints*16 > len(c.b) {
+		return nil, fmt.Errorf("invalid part count %d or point count %d", numParts, numPoints)
+	}
+	starts := make([]int, numParts)
+	for i := range starts {
+		starts[i] = c.int32()
+	}
+	points := readPoints(c, numPoints, t.hasZ())
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	parts := make([][]geojson.Point, numParts)
+	for i, start := range starts {
+		end := numPoints
+		if i+1 < numParts {
+			end = starts[i+1]
+		}
+		if start < 0 || start > end || end > numPoints {
+			return nil, fmt.Errorf("invalid part %d bounds [%d, %d)", i, start, end)
+		}
+		parts[i] = points[start:end]
+	}
+
+	if t.base() == Polygon {
+		return ring.Assemble(parts)
+	}
+	lines := make([]geojson.LineString, numParts)
+	for i, part := range parts {
+		if len(part) < 2 {
+			return nil, fmt.Errorf("part %d must have at least 2 points, got %d", i, len(part))
+		}
+		lines[i].Points = part
+	}
+	if len(lines) == 1 {
+		return &lines[0], nil
+	}
+	return &geojson.MultiLineString{Lines: lines}, nil
+}
+
+// readPoints reads n XY pairs followed, if hasZ is set, by a Z range and n Z
+// values. Any M values that follow are left unread.
+func readPoints(c *cursor, n int, hasZ bool) []geojson.Point {
+	if n < 0 || n*16 > len(c.b) {
+		c.err = fmt.Errorf("invalid point count %d", n)
+		return nil
+	}
+	points := make([]geojson.Point, n)
+	for i := range points {
+		points[i].X = c.float64()
+		points[i].Y = c.float64()
+	}
+	if hasZ {
+		c.skip(16)
+		for i := range points {
+			points[i].Elevation = c.float64()
+			points[i].HasElevation = true
+		}
+	}
+	return points
+}
+
+// encodeShape encodes g as the content of a .shp record of type t. A nil g
+// is encoded as a Null shape.
+func encodeShape(g geojson.Geometry, t ShapeType) ([]byte, error) {
+	var b []byte
+	if g == nil {
+		return appendInt32(b, int32(Null)), nil
+	}
+	b = appendInt32(b, int32(t))
+	switch t.base() {
+	case Point:
+		p, ok := g.(*geojson.Point)
+		if !ok {
+			return nil, fmt.Errorf("expected Point, got %T", g)
+		}
+		b = appendFloat64(b, p.X)
+		b = appendFloat64(b, p.Y)
+		if t.hasZ() {
+			b = appendFloat64(b, p.Elevation)
+		}
+		if t.hasM() {
+			// An M value is required in PointZ and PointM records. Values
+			// less than -1e38 mean "no data".
+			b = appendFloat64(b, noData)
+		}
+		return b, nil
+	case MultiPoint:
+		m, ok := g.(*geojson.MultiPoint)
+		if !ok {
+			return nil, fmt.Errorf("expected MultiPoint, got %T", g)
+		}
+		b = appendBBox(b, m.Points)
+		b = appendInt32(b, int32(len(m.Points)))
+		return appendPoints(b, m.Points, t), nil
+	case PolyLine:
+		var parts [][]geojson.Point
+		switch v := g.(type) {
+		case *geojson.LineString:
+			parts = [][]geojson.Point{v.Points}
+		case *geojson.MultiLineString:
+			for _, ls := range v.Lines {
+				parts = append(parts, ls.Points)
+			}
+		default:
+			return nil, fmt.Errorf("expected LineString or MultiLineString, got %T", g)
+		}
+		return appendParts(b, parts, t), nil
+	case Polygon:
+		var polygons []geojson.Polygon
+		switch v := g.(type) {
+		case *geojson.Polygon:
+			polygons = []geojson.Polygon{*v}
+		case *geojson.MultiPolygon:
+			polygons = v.Polygons
+		default:
+			return nil, fmt.Errorf("expected Polygon or MultiPolygon, got %T", g)
+		}
+		return appendParts(b, ring.Flatten(polygons), t), nil
+	}
+	return nil, fmt.Errorf("unsupported shape type %v", t)
+}
+
+// noData is the threshold below which M values are treated as missing.
+const noData = -1e39
+
+func appendParts(b []byte, parts [][]geojson.Point, t ShapeType) []byte {
+	var all []geojson.Point
+	for _, part := range parts {
+		all = append(all, part...)
+	}
+	b = appendBBox(b, all)
+	b = appendInt32(b, int32(len(parts)))
+	b = appendInt32(b, int32(len(all)))
+	start := 0
+	for _, part := range parts {
+		b = appendInt32(b, int32(start))
+		start += len(part)
+	}
+	return appendPoints(b, all, t)
+}
+
+// appendPoints appends the positions of a record of type t, followed by
+// their Z values and M values if t requires them. GeoJSON has no M values,
+// so they are all written as "no data".
+func appendPoints(b []byte, points []geojson.Point, t ShapeType) []byte {
+	for _, p := range points {
+		b = appendFloat64(b, p.X)
+		b = appendFloat64(b, p.Y)
+	}
+	if t.hasZ() {
+		zmin, zmax := zRange(points)
+		b = appendFloat64(b, zmin)
+		b = appendFloat64(b, zmax)
+		for _, p := range points {
+			b = appendFloat64(b, p.Elevation)
+		}
+	}
+	if t.hasM() {
+		b = appendFloat64(b, noData)
+		b = appendFloat64(b, noData)
+		for range points {
+			b = appendFloat64(b, noData)
+		}
+	}
+	return b
+}
+
+func appendBBox(b []byte, points []geojson.Point) []byte {
+	bbox := [4]float64{}
+	for i, p := range points {
+		if i == 0 {
+			bbox = [4]float64{p.X, p.Y, p.X, p.Y}
+			continue
+		}
+		bbox[0] = math.Min(bbox[0], p.X)
+		bbox[1] = math.Min(bbox[1], p.Y)
+		bbox[2] = math.Max(bbox[2], p.X)
+		bbox[3] = math.Max(bbox[3], p.Y)
+	}
+	for _, v := range bbox {
+		b = appendFloat64(b, v)
+	}
+	return b
+}
+
+func zRange(points []geojson.Point) (float64, float64) {
+	if len(points) == 0 {
+		return 0, 0
+	}
+	zmin, zmax := points[0].Elevation, points[0].Elevation
+	for _, p := range points[1:] {
+		zmin = math.Min(zmin, p.Elevation)
+		zmax = math.Max(zmax, p.Elevation)
+	}
+	return zmin, zmax
+}
+
+func appendInt32(b []byte, v int32) []byte {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], uint32(v))
+	return append(b, buf[:]...)
+}
+
+func appendFloat64(b []byte, v float64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+	return append(b, buf[:]...)
+}
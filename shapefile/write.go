@@ -0,0 +1,253 @@
+package shapefile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bsidhom/geojson"
+)
+
+// A Sink holds the component files of a shapefile for writing.
+type Sink struct {
+	// Geometry file. Required.
+	SHP io.Writer
+	// Index file. Required.
+	SHX io.Writer
+	// Attribute file. Required.
+	DBF io.Writer
+	// Projection file. Optional; only written if Metadata.Projection is set.
+	PRJ io.Writer
+}
+
+// Write encodes fc as a shapefile. The metadata is optional. If it is nil or
+// its ShapeType is Null, the shape type is inferred from the features'
+// geometries; a Z type is chosen if any point has an elevation. GeoJSON has
+// no M values, so records of M and Z types carry "no data" M values. If its
+// Fields are nil, they are inferred from the features' properties. Property
+// names longer than 10 bytes are truncated, and numbers too large for
+// fixed-point form are written in exponent form.
+//
+// All geometries must belong to the same shape family: Points, MultiPoints,
+// LineStrings and MultiLineStrings, or Polygons and MultiPolygons.
+// GeometryCollections cannot be represented. Features without geometry are
+// written as Null shapes.
+func Write(dst *Sink, fc *geojson.FeatureCollection, meta *Metadata) error {
+	if meta == nil {
+		meta = &Metadata{}
+	}
+	t := meta.ShapeType
+	if t == Null {
+		var err error
+		t, err = inferShapeType(fc)
+		if err != nil {
+			return fmt.Errorf("shapefile: %v", err)
+		}
+	}
+
+	records := make([]map[string]interface{}, len(fc.Features))
+	for i, f := range fc.Features {
+		records[i] = f.Properties
+	}
+	fields := meta.Fields
+	var keys []string
+	if fields == nil {
+		var err error
+		fields, keys, err = inferFields(records)
+		if err != nil {
+			return fmt.Errorf("shapefile: %v", err)
+		}
+	} else {
+		for _, f := range fields {
+			keys = append(keys, f.Name)
+		}
+	}
+	dbf, err := encodeDBF(fields, keys, records)
+	if err != nil {
+		return fmt.Errorf("shapefile: .dbf: %v", err)
+	}
+
+	shp := make([]byte, headerSize)
+	shx := make([]byte, headerSize)
+	h := &header{shapeType: t}
+	var all []geojson.Point
+	for i, f := range fc.Features {
+		content, err := encodeShape(f.Geometry, t)
+		if err != nil {
+			return fmt.Errorf("shapefile: feature %d: %v", i, err)
+		}
+		var rh [8]byte
+		binary.BigEndian.PutUint32(rh[0:], uint32(i+1))
+		binary.BigEndian.PutUint32(rh[4:], uint32(len(content)/2))
+		var ix [8]byte
+		binary.BigEndian.PutUint32(ix[0:], uint32(len(shp)/2))
+		binary.BigEndian.PutUint32(ix[4:], uint32(len(content)/2))
+		shx = append(shx, ix[:]...)
+		shp = append(shp, rh[:]...)
+		shp = append(shp, content...)
+		all = appendPositions(all, f.Geometry)
+	}
+	h.bbox = fileBBox(all)
+
+	h.length = len(shp)
+	copy(shp, h.appendTo(nil))
+	h.length = len(shx)
+	copy(shx, h.appendTo(nil))
+
+	for _, w := range []struct {
+		w    io.Writer
+		b    []byte
+		name string
+	}{
+		{dst.SHP, shp, ".shp"},
+		{dst.SHX, shx, ".shx"},
+		{dst.DBF, dbf, ".dbf"},
+	} {
+		if w.w == nil {
+			return fmt.Errorf("shapefile: missing %s writer", w.name)
+		}
+		_, err := w.w.Write(w.b)
+		if err != nil {
+			return fmt.Errorf("shapefile: %v", err)
+		}
+	}
+	if dst.PRJ != nil && meta.Projection != "" {
+		_, err := io.WriteString(dst.PRJ, meta.Projection)
+		if err != nil {
+			return fmt.Errorf("shapefile: %v", err)
+		}
+	}
+	return nil
+}
+
+// WriteFile writes fc as a shapefile named name, which may be given with or
+// without its .shp extension. The .shx and .dbf files are always written and
+// the .prj file is written if the metadata has a projection.
+func WriteFile(name string, fc *geojson.FeatureCollection, meta *Metadata) (err error) {
+	base := name
+	if ext := filepath.Ext(name); strings.EqualFold(ext, ".shp") {
+		base = strings.TrimSuffix(name, ext)
+	}
+	dst := &Sink{}
+	targets := []struct {
+		ext string
+		w   *io.Writer
+	}{
+		{".shp", &dst.SHP},
+		{".shx", &dst.SHX},
+		{".dbf", &dst.DBF},
+	}
+	if meta != nil && meta.Projection != "" {
+		targets = append(targets, struct {
+			ext string
+			w   *io.Writer
+		}{".prj", &dst.PRJ})
+	}
+	for _, target := range targets {
+		f, err := os.Create(base + target.ext)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			cerr := f.Close()
+			if err == nil {
+				err = cerr
+			}
+		}()
+		*target.w = f
+	}
+	return Write(dst, fc, meta)
+}
+
+// inferShapeType picks the shape type able to hold every geometry in fc.
+func inferShapeType(fc *geojson.FeatureCollection) (ShapeType, error) {
+	t := Null
+	hasZ := false
+	for i, f := range fc.Features {
+		var ft ShapeType
+		switch f.Geometry.(type) {
+		case nil:
+			continue
+		case *geojson.Point:
+			ft = Point
+		case *geojson.MultiPoint:
+			ft = MultiPoint
+		case *geojson.LineString, *geojson.MultiLineString:
+			ft = PolyLine
+		case *geojson.Polygon, *geojson.MultiPolygon:
+			ft = Polygon
+		default:
+			return Null, fmt.Errorf("feature %d: unsupported geometry type %T", i, f.Geometry)
+		}
+		if t != Null && t != ft {
+			return Null, fmt.Errorf("feature %d: %v geometry cannot be mixed with %v geometry", i, ft, t)
+		}
+		t = ft
+		for _, p := range appendPositions(nil, f.Geometry) {
+			hasZ = hasZ || p.HasElevation
+		}
+	}
+	if hasZ {
+		switch t {
+		case Point:
+			t = PointZ
+		case MultiPoint:
+			t = MultiPointZ
+		case PolyLine:
+			t = PolyLineZ
+		case Polygon:
+			t = PolygonZ
+		}
+	}
+	return t, nil
+}
+
+// appendPositions appends every position of g to points.
+func appendPositions(points []geojson.Point, g geojson.Geometry) []geojson.Point {
+	switch t := g.(type) {
+	case *geojson.Point:
+		points = append(points, *t)
+	case *geojson.MultiPoint:
+		points = append(points, t.Points...)
+	case *geojson.LineString:
+		points = append(points, t.Points...)
+	case *geojson.MultiLineString:
+		for _, ls := range t.Lines {
+			points = append(points, ls.Points...)
+		}
+	case *geojson.Polygon:
+		for _, ring := range t.Rings {
+			points = append(points, ring.Points...)
+		}
+	case *geojson.MultiPolygon:
+		for _, p := range t.Polygons {
+			for _, ring := range p.Rings {
+				points = append(points, ring.Points...)
+			}
+		}
+	}
+	return points
+}
+
+func fileBBox(points []geojson.Point) [8]float64 {
+	var bbox [8]float64
+	if len(points) == 0 {
+		return bbox
+	}
+	bbox[0], bbox[2] = math.Inf(1), math.Inf(-1)
+	bbox[1], bbox[3] = math.Inf(1), math.Inf(-1)
+	bbox[4], bbox[5] = math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		bbox[0] = math.Min(bbox[0], p.X)
+		bbox[1] = math.Min(bbox[1], p.Y)
+		bbox[2] = math.Max(bbox[2], p.X)
+		bbox[3] = math.Max(bbox[3], p.Y)
+		bbox[4] = math.Min(bbox[4], p.Elevation)
+		bbox[5] = math.Max(bbox[5], p.Elevation)
+	}
+	return bbox
+}
@@ -0,0 +1,231 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/ring"
+)
+
+func TestRoundTrip_Write(t *testing.T) {
+	square := geojson.LineString{
+		Points: []geojson.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}, {X: 0, Y: 0}},
+	}
+	hole := geojson.LineString{
+		Points: []geojson.Point{{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 1}, {X: 1, Y: 1}},
+	}
+	cases := []*geojson.FeatureCollection{
+		{
+			Features: []geojson.Feature{
+				{
+					Geometry: &geojson.Polygon{Rings: []geojson.LineString{square, hole}},
+					Properties: map[string]interface{}{
+						"name":       "Parcel",
+						"area":       15.25,
+						"count":      float64(-3),
+						"registered": true,
+						"tags":       "",
+					},
+				},
+				{
+					Geometry: &geojson.MultiPolygon{
+						Polygons: []geojson.Polygon{
+							{Rings: []geojson.LineString{square}},
+							{Rings: []geojson.LineString{{
+								Points: []geojson.Point{{X: 10, Y: 0}, {X: 11, Y: 0}, {X: 11, Y: 1}, {X: 10, Y: 0}},
+							}}},
+						},
+					},
+					Properties: map[string]interface{}{
+						"name":       "Müller",
+						"area":       nil,
+						"count":      float64(12),
+						"registered": false,
+						"tags":       "[\"a\"]",
+					},
+				},
+				{
+					Properties: map[string]interface{}{
+						"name":       "",
+						"area":       float64(1),
+						"count":      nil,
+						"registered": nil,
+						"tags":       "",
+					},
+				},
+			},
+		},
+		{
+			Features: []geojson.Feature{
+				{
+					Geometry: &geojson.MultiLineString{
+						Lines: []geojson.LineString{
+							{Points: []geojson.Point{
+								{X: 0, Y: 0, Elevation: 1, HasElevation: true},
+								{X: 1, Y: 1, Elevation: 2, HasElevation: true},
+							}},
+							{Points: []geojson.Point{
+								{X: 2, Y: 2, Elevation: 3, HasElevation: true},
+								{X: 3, Y: 3, Elevation: 4, HasElevation: true},
+							}},
+						},
+					},
+					Properties: map[string]interface{}{},
+				},
+			},
+		},
+		{
+			Features: []geojson.Feature{
+				{
+					Geometry:   &geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+					Properties: map[string]interface{}{},
+				},
+			},
+		},
+	}
+	for i, c := range cases {
+		var shp, shx, dbf bytes.Buffer
+		err := Write(&Sink{SHP: &shp, SHX: &shx, DBF: &dbf}, c, nil)
+		if err != nil {
+			t.Errorf("failed to write case %d: %v", i, err)
+			continue
+		}
+		fc, _, err := Read(&Source{SHP: &shp, SHX: &shx, DBF: &dbf})
+		if err != nil {
+			t.Errorf("failed to read case %d: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(fc, c) {
+			t.Errorf("round trip %d failed: expected %#v, got %#v", i, c, fc)
+		}
+	}
+}
+
+func TestWrite_Winding(t *testing.T) {
+	// A counter-clockwise RFC 7946 ring must be written clockwise.
+	fc := &geojson.FeatureCollection{
+		Features: []geojson.Feature{
+			{
+				Geometry: &geojson.Polygon{Rings: []geojson.LineString{{
+					Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0}},
+				}}},
+			},
+		},
+	}
+	var shp, shx, dbf bytes.Buffer
+	err := Write(&Sink{SHP: &shp, SHX: &shx, DBF: &dbf}, fc, nil)
+	if err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	content, err := recordContent(shp.Bytes(), headerSize)
+	if err != nil {
+		t.Fatalf("failed to read record: %v", err)
+	}
+	c := &cursor{b: content}
+	c.skip(4 + 32 + 4 + 4 + 4)
+	points := readPoints(c, 4, false)
+	if ring.SignedArea(points) >= 0 {
+		t.Errorf("expected clockwise ring, got %v", points)
+	}
+}
+
+func TestWrite_M(t *testing.T) {
+	// M records carry an M range and an M value for every position, which
+	// are written as "no data".
+	line := []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 0}}
+	cases := []struct {
+		t      ShapeType
+		g      geojson.Geometry
+		length int
+	}{
+		{PointM, &geojson.Point{X: 1, Y: 2}, 4 + 16 + 8},
+		{MultiPointM, &geojson.MultiPoint{Points: line}, 4 + 32 + 4 + 3*16 + 16 + 3*8},
+		{PolyLineM, &geojson.LineString{Points: line}, 4 + 32 + 4 + 4 + 4 + 3*16 + 16 + 3*8},
+		{PolygonM, &geojson.Polygon{Rings: []geojson.LineString{{Points: append(line, line[0])}}}, 4 + 32 + 4 + 4 + 4 + 4*16 + 16 + 4*8},
+		{PointZ, &geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true}, 4 + 16 + 8 + 8},
+		{PolyLineZ, &geojson.LineString{Points: line}, 4 + 32 + 4 + 4 + 4 + 3*16 + 2*(16+3*8)},
+	}
+	for _, c := range cases {
+		fc := &geojson.FeatureCollection{Features: []geojson.Feature{{Geometry: c.g}}}
+		var shp, shx, dbf bytes.Buffer
+		err := Write(&Sink{SHP: &shp, SHX: &shx, DBF: &dbf}, fc, &Metadata{ShapeType: c.t})
+		if err != nil {
+			t.Errorf("%v: failed to write: %v", c.t, err)
+			continue
+		}
+		content, err := recordContent(shp.Bytes(), headerSize)
+		if err != nil {
+			t.Errorf("%v: failed to read record: %v", c.t, err)
+			continue
+		}
+		if len(content) != c.length {
+			t.Errorf("%v: expected %d bytes, got %d", c.t, c.length, len(content))
+		}
+		if m := content[len(content)-8:]; math.Float64frombits(binary.LittleEndian.Uint64(m)) != noData {
+			t.Errorf("%v: expected trailing M value to be no data, got % x", c.t, m)
+		}
+		if _, _, err := Read(&Source{SHP: &shp, SHX: &shx, DBF: &dbf}); err != nil {
+			t.Errorf("%v: failed to read: %v", c.t, err)
+		}
+	}
+}
+
+func TestWrite_NumericLayout(t *testing.T) {
+	// A column mixing large integers, fractions, and negative values must be
+	// wide enough for the integer part, the sign, and the decimals at once.
+	// Values too large for fixed-point form are written in exponent form.
+	values := []float64{12345, 0.125, -7.5, 1e19, -2.5e300}
+	fc := &geojson.FeatureCollection{}
+	for _, v := range values {
+		fc.Features = append(fc.Features, geojson.Feature{
+			Geometry:   &geojson.Point{X: v, Y: 0},
+			Properties: map[string]interface{}{"v": v},
+		})
+	}
+	var shp, shx, dbf bytes.Buffer
+	err := Write(&Sink{SHP: &shp, SHX: &shx, DBF: &dbf}, fc, nil)
+	if err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	got, _, err := Read(&Source{SHP: &shp, SHX: &shx, DBF: &dbf})
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	for i, f := range got.Features {
+		if f.Properties["v"] != values[i] {
+			t.Errorf("feature %d: expected %v, got %#v", i, values[i], f.Properties["v"])
+		}
+	}
+}
+
+func TestWrite_Invalid(t *testing.T) {
+	cases := []*geojson.FeatureCollection{
+		{
+			Features: []geojson.Feature{
+				{Geometry: &geojson.Point{}},
+				{Geometry: &geojson.LineString{Points: []geojson.Point{{}, {}}}},
+			},
+		},
+		{
+			Features: []geojson.Feature{
+				{Geometry: &geojson.GeometryCollection{}},
+			},
+		},
+		{
+			Features: []geojson.Feature{
+				{Properties: map[string]interface{}{"population_2020": 1.0, "population_2021": 2.0}},
+			},
+		},
+	}
+	for i, c := range cases {
+		var shp, shx, dbf bytes.Buffer
+		err := Write(&Sink{SHP: &shp, SHX: &shx, DBF: &dbf}, c, nil)
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package geojson
+
+import (
+	"container/heap"
+	"math"
+)
+
+// A SimplifyMethod is an algorithm used by Simplify.
+type SimplifyMethod int
+
+const (
+	// DouglasPeucker keeps the vertices that lie farther than the tolerance
+	// from the simplified line. It preserves spikes and other extremes.
+	DouglasPeucker SimplifyMethod = iota
+	// VisvalingamWhyatt repeatedly removes the vertex that forms the smallest
+	// triangle with its neighbours, until every triangle has an area of at
+	// least the square of the tolerance. It tends to give smoother shapes.
+	VisvalingamWhyatt
+)
+
+// SimplifyOptions controls how Simplify simplifies geometries. The zero
+// value uses DouglasPeucker.
+type SimplifyOptions struct {
+	Method SimplifyMethod
+}
+
+// Simplify returns a copy of o with fewer vertices in its lines and polygon
+// rings. o may be a Geometry, a Feature, or a FeatureCollection, and the
+// result has the same type. The geometries and places of features are
+// simplified, and their other members are copied.
+//
+// The tolerance is in the units of the coordinates, usually degrees, and
+// distances are measured in the plane. The ends of each line are kept, and
+// polygon rings keep at least 4 positions and stay closed, as Unmarshal
+// requires, so a ring never collapses even if it is smaller than the
+// tolerance. Lines and rings that are too short to simplify are copied. The
+// rings of a polygon are simplified independently, so they may cross in the
+// result; see SimplifyTopology for simplifying shared borders. Points and
+// the JSON-FG geometry types are unchanged.
+func Simplify(o Object, tolerance float64, opts *SimplifyOptions) Object {
+	if opts == nil {
+		opts = &SimplifyOptions{}
+	}
+	s := &simplifier{tolerance: tolerance, method: opts.Method}
+	switch t := o.(type) {
+	case *FeatureCollection:
+		fc := *t
+		fc.Features = make([]Feature, len(t.Features))
+		for i := range t.Features {
+			fc.Features[i] = s.feature(&t.Features[i])
+		}
+		return &fc
+	case *Feature:
+		f := s.feature(t)
+		return &f
+	case Geometry:
+		return s.geometry(t)
+	}
+	return o
+}
+
+// A simplifier simplifies geometries.
+type simplifier struct {
+	tolerance float64
+	method    SimplifyMethod
+}
+
+func (s *simplifier) feature(f *Feature) Feature {
+	r := *f
+	if f.Geometry != nil {
+		r.Geometry = s.geometry(f.Geometry)
+	}
+	if f.Place != nil {
+		r.Place = s.geometry(f.Place)
+	}
+	return r
+}
+
+func (s *simplifier) geometry(g Geometry) Geometry {
+	return mapLines(g, s.line, s.ring)
+}
+
+// mapLines returns a copy of g with line applied to the positions of each
+// LineString and ring applied to those of each polygon ring. The JSON-FG
+// geometry types are returned unchanged.
+func mapLines(g Geometry, line, ring func(ps []Point) []Point) Geometry {
+	rings := func(ls []LineString) []LineString {
+		r := make([]LineString, len(ls))
+		for i, l := range ls {
+			r[i] = LineString{Points: ring(l.Points)}
+		}
+		return r
+	}
+	switch t := g.(type) {
+	case *Point:
+		p := *t
+		return &p
+	case *MultiPoint:
+		return &MultiPoint{Points: append([]Point(nil), t.Points...)}
+	case *LineString:
+		return &LineString{Points: line(t.Points)}
+	case *MultiLineString:
+		r := &MultiLineString{Lines: make([]LineString, len(t.Lines))}
+		for i, l := range t.Lines {
+			r.Lines[i] = LineString{Points: line(l.Points)}
+		}
+		return r
+	case *Polygon:
+		return &Polygon{Rings: rings(t.Rings)}
+	case *MultiPolygon:
+		r := &MultiPolygon{Polygons: make([]Polygon, len(t.Polygons))}
+		for i, p := range t.Polygons {
+			r.Polygons[i] = Polygon{Rings: rings(p.Rings)}
+		}
+		return r
+	case *GeometryCollection:
+		r := &GeometryCollection{Geometries: make([]Geometry, len(t.Geometries))}
+		for i, child := range t.Geometries {
+			r.Geometries[i] = mapLines(child, line, ring)
+		}
+		return r
+	}
+	return g
+}
+
+// line simplifies the positions of a LineString, keeping its ends.
+func (s *simplifier) line(ps []Point) []Point {
+	if len(ps) <= 2 {
+		return append([]Point(nil), ps...)
+	}
+	return kept(ps, s.keepLine(ps))
+}
+
+// keepLine marks the positions of the line ps to keep, which must number at
+// least 2.
+func (s *simplifier) keepLine(ps []Point) []bool {
+	keep := make([]bool, len(ps))
+	keep[0], keep[len(ps)-1] = true, true
+	if s.method == VisvalingamWhyatt {
+		s.visvalingam(ps, keep, false)
+	} else {
+		s.douglasPeucker(ps, keep, 0, len(ps)-1)
+	}
+	return keep
+}
+
+// ring simplifies the positions of a closed polygon ring, keeping at least
+// 4 of them.
+func (s *simplifier) ring(ps []Point) []Point {
+	n := len(ps)
+	if n <= 4 || ps[0] != ps[n-1] {
+		return append([]Point(nil), ps...)
+	}
+	r := kept(ps[:n-1], s.keepRing(ps)[:n-1])
+	return append(r, r[0])
+}
+
+// keepRing marks the positions of the closed ring ps to keep, which must
+// number more than 4. At least 3 positions before the closing one are
+// marked, but the first may not be, so the result must be closed again.
+func (s *simplifier) keepRing(ps []Point) []bool {
+	n := len(ps)
+	keep := make([]bool, n)
+	if s.method == VisvalingamWhyatt {
+		// Treat the ring as a cycle without its closing position.
+		s.visvalingam(ps[:n-1], keep[:n-1], true)
+		return keep
+	}
+	// Split the ring at the vertex farthest from its first, so that each
+	// half is a line with distinct ends, and make sure a third vertex
+	// survives.
+	far, best := 0, -1.0
+	for i := 1; i < n-1; i++ {
+		if d := math.Hypot(ps[i].X-ps[0].X, ps[i].Y-ps[0].Y); d > best {
+			far, best = i, d
+		}
+	}
+	keep[0], keep[far], keep[n-1] = true, true, true
+	s.douglasPeucker(ps, keep, 0, far)
+	s.douglasPeucker(ps, keep, far, n-1)
+	third, best := -1, -1.0
+	for i := 1; i < n-1; i++ {
+		if keep[i] && i != far {
+			third = -1
+			break
+		}
+		if d := segmentDistance(ps[i], ps[0], ps[far]); i != far && d > best {
+			third, best = i, d
+		}
+	}
+	if third >= 0 {
+		keep[third] = true
+	}
+	return keep
+}
+
+// kept returns the positions of ps that are marked in keep.
+func kept(ps []Point, keep []bool) []Point {
+	var r []Point
+	for i, p := range ps {
+		if keep[i] {
+			r = append(r, p)
+		}
+	}
+	return r
+}
+
+// douglasPeucker marks the vertices of ps between lo and hi that must be
+// kept. It uses a stack rather than recursion, since the depth can approach
+// the number of vertices.
+func (s *simplifier) douglasPeucker(ps []Point, keep []bool, lo, hi int) {
+	stack := [][2]int{{lo, hi}}
+	for len(stack) > 0 {
+		span := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		lo, hi := span[0], span[1]
+		far, best := -1, s.tolerance
+		for i := lo + 1; i < hi; i++ {
+			if d := segmentDistance(ps[i], ps[lo], ps[hi]); d > best {
+				far, best = i, d
+			}
+		}
+		if far < 0 {
+			continue
+		}
+		keep[far] = true
+		stack = append(stack, [2]int{lo, far}, [2]int{far, hi})
+	}
+}
+
+// visvalingam marks the vertices of ps that must be kept. If cyclic is
+// true, ps is a ring without its closing position, and at least 3 vertices
+// are kept; otherwise the ends of ps are kept.
+func (s *simplifier) visvalingam(ps []Point, keep []bool, cyclic bool) {
+	n := len(ps)
+	prev := make([]int, n)
+	next := make([]int, n)
+	for i := range ps {
+		prev[i], next[i] = i-1, i+1
+		keep[i] = true
+	}
+	if cyclic {
+		prev[0], next[n-1] = n-1, 0
+	}
+	var q vertexQueue
+	vertices := make([]*vertex, n)
+	for i := range ps {
+		if !cyclic && (i == 0 || i == n-1) {
+			continue
+		}
+		vertices[i] = &vertex{i: i, area: triangleArea(ps[prev[i]], ps[i], ps[next[i]])}
+		heap.Push(&q, vertices[i])
+	}
+	threshold := s.tolerance * s.tolerance
+	left := n
+	for q.Len() > 0 && !(cyclic && left <= 3) {
+		v := heap.Pop(&q).(*vertex)
+		if v.area >= threshold {
+			break
+		}
+		keep[v.i] = false
+		left--
+		p, nx := prev[v.i], next[v.i]
+		next[p], prev[nx] = nx, p
+		// Neighbours never get a smaller area than the vertex removed before
+		// them, so that vertices are removed in order of significance.
+		for _, j := range []int{p, nx} {
+			if w := vertices[j]; w != nil {
+				w.area = math.Max(v.area, triangleArea(ps[prev[j]], ps[j], ps[next[j]]))
+				heap.Fix(&q, w.index)
+			}
+		}
+	}
+}
+
+// triangleArea returns the area of the triangle abc.
+func triangleArea(a, b, c Point) float64 {
+	return math.Abs((b.X-a.X)*(c.Y-a.Y)-(c.X-a.X)*(b.Y-a.Y)) / 2
+}
+
+// A vertex is a candidate for removal by visvalingam.
+type vertex struct {
+	// Index of the vertex in the line, and of the vertex in the queue.
+	i, index int
+	area     float64
+}
+
+// vertexQueue is a min-heap of vertices ordered by area.
+type vertexQueue []*vertex
+
+func (q vertexQueue) Len() int           { return len(q) }
+func (q vertexQueue) Less(i, j int) bool { return q[i].area < q[j].area }
+
+func (q vertexQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *vertexQueue) Push(x interface{}) {
+	v := x.(*vertex)
+	v.index = len(*q)
+	*q = append(*q, v)
+}
+
+func (q *vertexQueue) Pop() interface{} {
+	old := *q
+	v := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return v
+}
@@ -0,0 +1,89 @@
+package geojson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	line := []Point{{X: 0, Y: 0}, {X: 1, Y: 0.2}, {X: 2, Y: -0.1}, {X: 3, Y: 0}, {X: 4, Y: 2}, {X: 5, Y: 0}}
+	square := []Point{
+		{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 1}, {X: 2, Y: 2},
+		{X: 1, Y: 2}, {X: 0, Y: 2}, {X: 0, Y: 1}, {X: 0, Y: 0},
+	}
+	small := []Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0.5, Y: 1.1}, {X: 0, Y: 1}, {X: 0, Y: 0}}
+	cases := []struct {
+		g         Geometry
+		tolerance float64
+		method    SimplifyMethod
+		expected  Geometry
+	}{
+		{&LineString{Points: line}, 0.6, DouglasPeucker, &LineString{Points: []Point{line[0], line[3], line[4], line[5]}}},
+		{&LineString{Points: line}, 0.6, VisvalingamWhyatt, &LineString{Points: []Point{line[0], line[3], line[4], line[5]}}},
+		{&LineString{Points: line}, 0.5, VisvalingamWhyatt, &LineString{Points: []Point{line[0], line[1], line[3], line[4], line[5]}}},
+		{&LineString{Points: line}, 0.1, DouglasPeucker, &LineString{Points: line}},
+		{&LineString{Points: line}, 100, DouglasPeucker, &LineString{Points: []Point{line[0], line[5]}}},
+		{
+			&MultiLineString{Lines: []LineString{{Points: line}, {Points: line[:2]}}}, 100, VisvalingamWhyatt,
+			&MultiLineString{Lines: []LineString{{Points: []Point{line[0], line[5]}}, {Points: line[:2]}}},
+		},
+		{&Polygon{Rings: []LineString{{Points: square}}}, 0.1, DouglasPeucker, &Polygon{Rings: []LineString{{Points: []Point{square[0], square[2], square[4], square[6], square[0]}}}}},
+		{&Polygon{Rings: []LineString{{Points: square}}}, 0.1, VisvalingamWhyatt, &Polygon{Rings: []LineString{{Points: []Point{square[0], square[2], square[4], square[6], square[0]}}}}},
+		// Rings never collapse.
+		{&Polygon{Rings: []LineString{{Points: small}}}, 100, DouglasPeucker, &Polygon{Rings: []LineString{{Points: []Point{small[0], small[1], small[2], small[0]}}}}},
+		{
+			&GeometryCollection{Geometries: []Geometry{&Point{X: 1, Y: 2}, &MultiPolygon{Polygons: []Polygon{{Rings: []LineString{{Points: square}, {Points: small}}}}}}}, 0.1, DouglasPeucker,
+			&GeometryCollection{Geometries: []Geometry{&Point{X: 1, Y: 2}, &MultiPolygon{Polygons: []Polygon{{Rings: []LineString{{Points: []Point{square[0], square[2], square[4], square[6], square[0]}}, {Points: small}}}}}}},
+		},
+	}
+	for i, c := range cases {
+		actual := Simplify(c.g, c.tolerance, &SimplifyOptions{Method: c.method})
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("case %d: expected %#v, got %#v", i, c.expected, actual)
+		}
+	}
+}
+
+func TestSimplify_ValidRings(t *testing.T) {
+	// Rings keep at least 4 positions and stay closed, whatever the method
+	// and tolerance.
+	var ring []Point
+	for i := 0; i < 50; i++ {
+		ring = append(ring, Point{X: float64(i % 7), Y: float64(i*i%11) / 3})
+	}
+	ring = append(ring, ring[0])
+	for _, method := range []SimplifyMethod{DouglasPeucker, VisvalingamWhyatt} {
+		for _, tolerance := range []float64{0, 0.1, 1, 10, 1000} {
+			p := Simplify(&Polygon{Rings: []LineString{{Points: ring}}}, tolerance, &SimplifyOptions{Method: method}).(*Polygon)
+			points := p.Rings[0].Points
+			if len(points) < 4 || points[0] != points[len(points)-1] {
+				t.Errorf("method %d, tolerance %v: invalid ring %v", method, tolerance, points)
+			}
+		}
+	}
+}
+
+func TestSimplify_Features(t *testing.T) {
+	line := []Point{{X: 0, Y: 0, Elevation: 1, HasElevation: true}, {X: 1, Y: 0.01}, {X: 2, Y: 0, Elevation: 3, HasElevation: true}}
+	fc := &FeatureCollection{Features: []Feature{
+		{Geometry: &LineString{Points: line}, Properties: map[string]interface{}{"name": "a"}, ID: "1"},
+		{Geometry: &Point{X: 5, Y: 5}},
+	}}
+	actual := Simplify(fc, 0.1, nil).(*FeatureCollection)
+	expected := &FeatureCollection{Features: []Feature{
+		{Geometry: &LineString{Points: []Point{line[0], line[2]}}, Properties: map[string]interface{}{"name": "a"}, ID: "1"},
+		{Geometry: &Point{X: 5, Y: 5}},
+	}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+	// The input is not modified.
+	if len(fc.Features[0].Geometry.(*LineString).Points) != 3 {
+		t.Errorf("input was modified: %#v", fc.Features[0].Geometry)
+	}
+
+	f := Simplify(&fc.Features[0], 0.001, nil).(*Feature)
+	if !reflect.DeepEqual(f, &fc.Features[0]) {
+		t.Errorf("expected %#v, got %#v", &fc.Features[0], f)
+	}
+}
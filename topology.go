@@ -0,0 +1,659 @@
+package geojson
+
+import (
+	"math"
+	"sort"
+)
+
+// This file builds the planar arrangement of two geometries: their segments
+// are split wherever they meet, so that the resulting nodes and edges each
+// have a single location relative to either geometry. Relate and the
+// overlay operations are computed from the arrangement.
+
+// An xy is a position used as a map key.
+type xy struct {
+	x, y float64
+}
+
+func (p xy) less(q xy) bool {
+	return p.x < q.x || p.x == q.x && p.y < q.y
+}
+
+// orient returns a positive number if c lies to the left of the line from a
+// to b, a negative number if it lies to the right, and 0 if it lies on the
+// line.
+func orient(a, b, c xy) float64 {
+	return (b.x-a.x)*(c.y-a.y) - (b.y-a.y)*(c.x-a.x)
+}
+
+// Kinds of segments.
+const (
+	pointSegment = iota
+	lineSegment
+	ringSegment
+)
+
+// A segment is a part of one of the two geometries of an arrangement. Points
+// are segments of zero length.
+type segment struct {
+	a, b xy
+	// Which geometry the segment belongs to.
+	geom int
+	kind int
+	// For lines, whether a starts the line and b ends it.
+	first, last bool
+	// For rings, whether the interior of the polygon lies to the left, and
+	// the index of the polygon in the areas of the arrangement.
+	interiorLeft bool
+	area         int
+	// Positions where other segments meet this one, at which it is split.
+	splits []xy
+	// Whether the segment has not yet been compared with the others.
+	fresh bool
+}
+
+func (s *segment) minX() float64 { return math.Min(s.a.x, s.b.x) }
+func (s *segment) maxX() float64 { return math.Max(s.a.x, s.b.x) }
+func (s *segment) minY() float64 { return math.Min(s.a.y, s.b.y) }
+func (s *segment) maxY() float64 { return math.Max(s.a.y, s.b.y) }
+
+// contains reports whether p, which must be collinear with s, lies on s.
+func (s *segment) contains(p xy) bool {
+	return p.x >= s.minX() && p.x <= s.maxX() && p.y >= s.minY() && p.y <= s.maxY()
+}
+
+// A topoNode records which parts of each geometry meet at a node.
+type topoNode struct {
+	ring [2]bool
+	// Whether an edge at the node is on the boundary of the union of the
+	// polygons of a geometry. Nodes on rings without such edges are where
+	// polygons of the geometry meet or overlap, in the interior of their
+	// union.
+	boundary     [2]bool
+	lineInterior [2]bool
+	// Number of line ends at the node, which is on the boundary of the
+	// lines if the number is odd.
+	lineEnds [2]int
+	point    [2]bool
+}
+
+// A topoEdge records which parts of each geometry run along an edge from a
+// to b, where a is less than b.
+type topoEdge struct {
+	a, b xy
+	// The rings that run along the edge.
+	rings [2][]edgeRing
+	// Whether any ring runs along the edge.
+	onRing [2]bool
+	line   [2]bool
+	// For edges on rings, whether the faces to the left and right of the
+	// edge are in the interior of the polygons of each geometry.
+	left, right [2]bool
+}
+
+// An edgeRing records that a ring of the polygon with index area runs along
+// an edge, with its interior to the left if side is 1 and to the right if
+// side is -1.
+type edgeRing struct {
+	area, side int
+}
+
+func (e *topoEdge) mid() xy {
+	return xy{(e.a.x + e.b.x) / 2, (e.a.y + e.b.y) / 2}
+}
+
+// An arrangement is the planar arrangement of two geometries.
+type arrangement struct {
+	segments []segment
+	nodes    map[xy]*topoNode
+	edges    map[[2]xy]*topoEdge
+	// The polygons of each geometry, for locating positions off their
+	// boundaries.
+	areas [2][]*PreparedPolygon
+	// The dimension of each geometry, or -1 if it is empty.
+	dims [2]int
+}
+
+func newArrangement(a, b Geometry) *arrangement {
+	ar := &arrangement{
+		nodes: map[xy]*topoNode{},
+		edges: map[[2]xy]*topoEdge{},
+		dims:  [2]int{-1, -1},
+	}
+	ar.add(0, a)
+	ar.add(1, b)
+	ar.node()
+	for i := range ar.segments {
+		s := &ar.segments[i]
+		ar.mark(s, s.a)
+		ar.mark(s, s.b)
+		if s.kind != pointSegment {
+			ar.addEdge(s, s.a, s.b)
+		}
+	}
+	for _, e := range ar.edges {
+		for geom := range e.onRing {
+			if !e.onRing[geom] {
+				continue
+			}
+			ar.label(geom, e)
+			if ar.edgeLocation(geom, e) == Boundary {
+				ar.nodes[e.a].boundary[geom] = true
+				ar.nodes[e.b].boundary[geom] = true
+			}
+		}
+	}
+	return ar
+}
+
+// add adds the segments of g to the arrangement.
+func (ar *arrangement) add(geom int, g Geometry) {
+	dim := func(d int) {
+		if d > ar.dims[geom] {
+			ar.dims[geom] = d
+		}
+	}
+	switch t := g.(type) {
+	case *Point:
+		ar.addPoint(geom, *t)
+		dim(0)
+	case *MultiPoint:
+		for _, p := range t.Points {
+			ar.addPoint(geom, p)
+			dim(0)
+		}
+	case *LineString:
+		ar.addLine(geom, t.Points)
+		if len(t.Points) > 0 {
+			dim(1)
+		}
+	case *MultiLineString:
+		for _, ls := range t.Lines {
+			ar.add(geom, &ls)
+		}
+	case *Polygon:
+		if ar.addPolygon(geom, t) {
+			dim(2)
+		}
+	case *MultiPolygon:
+		for i := range t.Polygons {
+			ar.add(geom, &t.Polygons[i])
+		}
+	case *GeometryCollection:
+		for _, child := range t.Geometries {
+			ar.add(geom, child)
+		}
+	}
+}
+
+func (ar *arrangement) addPoint(geom int, p Point) {
+	q := xy{p.X, p.Y}
+	ar.segments = append(ar.segments, segment{a: q, b: q, geom: geom, kind: pointSegment})
+}
+
+// distinct returns the positions of points without repeats.
+func distinct(points []Point) []xy {
+	var r []xy
+	for _, p := range points {
+		q := xy{p.X, p.Y}
+		if len(r) == 0 || r[len(r)-1] != q {
+			r = append(r, q)
+		}
+	}
+	return r
+}
+
+func (ar *arrangement) addLine(geom int, points []Point) {
+	ps := distinct(points)
+	if len(ps) == 1 {
+		ar.segments = append(ar.segments, segment{a: ps[0], b: ps[0], geom: geom, kind: pointSegment})
+		return
+	}
+	for i := 1; i < len(ps); i++ {
+		ar.segments = append(ar.segments, segment{
+			a: ps[i-1], b: ps[i], geom: geom, kind: lineSegment,
+			first: i == 1, last: i == len(ps)-1,
+		})
+	}
+}
+
+// addPolygon adds the rings of p and reports whether p has an area.
+// Polygons without area are added as lines.
+func (ar *arrangement) addPolygon(geom int, p *Polygon) bool {
+	if len(p.Rings) == 0 {
+		return false
+	}
+	if a, _, _ := ringCentroid(p.Rings[0].Points); a == 0 {
+		for _, ring := range p.Rings {
+			ar.addLine(geom, ring.Points)
+		}
+		return len(p.Rings[0].Points) > 0
+	}
+	for i, ring := range p.Rings {
+		ps := distinct(ring.Points)
+		if len(ps) > 1 && ps[0] == ps[len(ps)-1] {
+			ps = ps[:len(ps)-1]
+		}
+		if len(ps) < 3 {
+			continue
+		}
+		a, _, _ := ringCentroid(ring.Points)
+		interiorLeft := (a > 0) == (i == 0)
+		for j := range ps {
+			ar.segments = append(ar.segments, segment{
+				a: ps[j], b: ps[(j+1)%len(ps)], geom: geom, kind: ringSegment,
+				interiorLeft: interiorLeft, area: len(ar.areas[geom]),
+			})
+		}
+	}
+	ar.areas[geom] = append(ar.areas[geom], NewPreparedPolygon(p))
+	return true
+}
+
+// mark records that the segment s passes through or ends at the node p.
+func (ar *arrangement) mark(s *segment, p xy) {
+	n := ar.nodes[p]
+	if n == nil {
+		n = &topoNode{}
+		ar.nodes[p] = n
+	}
+	switch s.kind {
+	case pointSegment:
+		n.point[s.geom] = true
+	case lineSegment:
+		if p == s.a && s.first {
+			n.lineEnds[s.geom]++
+		} else if p == s.b && s.last {
+			n.lineEnds[s.geom]++
+		} else {
+			n.lineInterior[s.geom] = true
+		}
+	case ringSegment:
+		n.ring[s.geom] = true
+	}
+}
+
+// snapTolerance is the distance, relative to the largest coordinate of an
+// arrangement, within which positions are taken to coincide or to lie on a
+// segment. It is far larger than the rounding errors of rotating or
+// offsetting a position, and far smaller than any meaningful distance.
+const snapTolerance = 1e-12
+
+// maxNodingRounds limits how often the segments are split again after the
+// splits have bent them.
+const maxNodingRounds = 8
+
+// tolerance returns the snapping distance for the segments.
+func (ar *arrangement) tolerance() float64 {
+	var m float64
+	for _, s := range ar.segments {
+		m = math.Max(m, math.Max(math.Max(math.Abs(s.a.x), math.Abs(s.a.y)), math.Max(math.Abs(s.b.x), math.Abs(s.b.y))))
+	}
+	return m * snapTolerance
+}
+
+// node splits the segments into pieces wherever they meet. Positions that
+// are only apart due to rounding would otherwise leave slivers between
+// segments that are collinear, or cross each other again once split, so the
+// ends of segments within the tolerance of each other are first merged, and
+// segments are split at the ends of others that lie within the tolerance of
+// them. Since splitting bends segments slightly, it is repeated until no
+// more splits are found.
+func (ar *arrangement) node() {
+	tol := ar.tolerance()
+	ar.snap(tol)
+	for i := range ar.segments {
+		ar.segments[i].fresh = true
+	}
+	for round := 0; round < maxNodingRounds && ar.findSplits(tol); round++ {
+		ar.split()
+	}
+}
+
+// snap moves the ends of segments that lie within tol of each other to the
+// same position, and drops the segments that collapse.
+func (ar *arrangement) snap(tol float64) {
+	if tol == 0 {
+		return
+	}
+	ps := make([]xy, 0, 2*len(ar.segments))
+	for _, s := range ar.segments {
+		ps = append(ps, s.a, s.b)
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i].less(ps[j]) })
+	// Sweep from left to right, keeping the first of each cluster, and
+	// record the positions that move.
+	to := map[xy]xy{}
+	var kept []xy
+	start := 0
+	for i, p := range ps {
+		if i > 0 && p == ps[i-1] {
+			continue
+		}
+		for start < len(kept) && kept[start].x < p.x-tol {
+			start++
+		}
+		moved := false
+		for _, q := range kept[start:] {
+			if math.Hypot(p.x-q.x, p.y-q.y) <= tol {
+				to[p], moved = q, true
+				break
+			}
+		}
+		if !moved {
+			kept = append(kept, p)
+		}
+	}
+	if len(to) == 0 {
+		return
+	}
+	var segments []segment
+	for i, s := range ar.segments {
+		if q, ok := to[s.a]; ok {
+			s.a = q
+		}
+		if q, ok := to[s.b]; ok {
+			s.b = q
+		}
+		if s.a == s.b && s.kind != pointSegment {
+			if s.kind != lineSegment {
+				continue
+			}
+			if s.first && s.last {
+				s.kind = pointSegment
+				segments = append(segments, s)
+				continue
+			}
+			// Pass the ends of the line on to the neighbouring segments.
+			if s.first && i+1 < len(ar.segments) {
+				ar.segments[i+1].first = true
+			}
+			if n := len(segments); s.last && n > 0 && segments[n-1].kind == lineSegment && segments[n-1].b == s.a {
+				segments[n-1].last = true
+			}
+			continue
+		}
+		segments = append(segments, s)
+	}
+	ar.segments = segments
+}
+
+// findSplits finds the positions where segments meet, sweeping from left to
+// right so that only segments that overlap horizontally are compared, and
+// reports whether any were found. Segments that were not split since they
+// were last compared cannot meet.
+func (ar *arrangement) findSplits(tol float64) bool {
+	order := make([]int, len(ar.segments))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return ar.segments[order[i]].minX() < ar.segments[order[j]].minX()
+	})
+	found := false
+	for i, si := range order {
+		s := &ar.segments[si]
+		for _, ti := range order[i+1:] {
+			t := &ar.segments[ti]
+			if t.minX() > s.maxX()+tol {
+				break
+			}
+			if !s.fresh && !t.fresh || t.minY() > s.maxY()+tol || t.maxY() < s.minY()-tol {
+				continue
+			}
+			for _, p := range intersectWithin(s, t, tol) {
+				found = s.split(p) || found
+				found = t.split(p) || found
+			}
+		}
+	}
+	return found
+}
+
+// split records that s must be split at p, unless s is a point or p is one
+// of its ends, and reports whether it must.
+func (s *segment) split(p xy) bool {
+	if s.kind == pointSegment || p == s.a || p == s.b {
+		return false
+	}
+	s.splits = append(s.splits, p)
+	return true
+}
+
+// near reports whether p, which is not an end of s, lies on s or within tol
+// of its interior.
+func (s *segment) near(p xy, tol float64) bool {
+	if p == s.a || p == s.b {
+		return false
+	}
+	if orient(s.a, s.b, p) == 0 && s.contains(p) {
+		return true
+	}
+	dx, dy := s.b.x-s.a.x, s.b.y-s.a.y
+	l2 := dx*dx + dy*dy
+	if tol == 0 || l2 == 0 {
+		return false
+	}
+	u := ((p.x-s.a.x)*dx + (p.y-s.a.y)*dy) / l2
+	return u > 0 && u < 1 && math.Hypot(s.a.x+u*dx-p.x, s.a.y+u*dy-p.y) <= tol
+}
+
+// intersect returns the positions where s and t meet: the ends of either
+// that are ends of the other or lie on it, which include the ends of their
+// overlap if they are collinear, or else the position where they cross.
+func intersect(s, t *segment) []xy {
+	return intersectWithin(s, t, 0)
+}
+
+// intersectWithin is like intersect, but also takes the ends of either
+// segment that lie within tol of the other to meet it.
+func intersectWithin(s, t *segment, tol float64) []xy {
+	var r []xy
+	add := func(p xy) {
+		for _, q := range r {
+			if p == q {
+				return
+			}
+		}
+		r = append(r, p)
+	}
+	for _, p := range []xy{s.a, s.b} {
+		if p == t.a || p == t.b || t.near(p, tol) {
+			add(p)
+		}
+	}
+	for _, p := range []xy{t.a, t.b} {
+		if s.near(p, tol) {
+			add(p)
+		}
+	}
+	if len(r) > 0 {
+		return r
+	}
+	d1 := orient(t.a, t.b, s.a)
+	d2 := orient(t.a, t.b, s.b)
+	d3 := orient(s.a, s.b, t.a)
+	d4 := orient(s.a, s.b, t.b)
+	if !(d1 > 0 && d2 < 0 || d1 < 0 && d2 > 0) || !(d3 > 0 && d4 < 0 || d3 < 0 && d4 > 0) {
+		return nil
+	}
+	// The segments cross properly.
+	dx, dy := s.b.x-s.a.x, s.b.y-s.a.y
+	u := ((t.a.x-s.a.x)*(t.b.y-t.a.y) - (t.a.y-s.a.y)*(t.b.x-t.a.x)) /
+		(dx*(t.b.y-t.a.y) - dy*(t.b.x-t.a.x))
+	p := xy{s.a.x + u*dx, s.a.y + u*dy}
+	// Keep the rounded position within both segments.
+	p.x = math.Max(math.Max(s.minX(), t.minX()), math.Min(p.x, math.Min(s.maxX(), t.maxX())))
+	p.y = math.Max(math.Max(s.minY(), t.minY()), math.Min(p.y, math.Min(s.maxY(), t.maxY())))
+	return []xy{p}
+}
+
+// split divides the segments into pieces at the positions where they meet.
+// Only the first and last pieces of a line keep its ends.
+func (ar *arrangement) split() {
+	var segments []segment
+	for _, s := range ar.segments {
+		if len(s.splits) == 0 {
+			s.fresh = false
+			segments = append(segments, s)
+			continue
+		}
+		dx, dy := s.b.x-s.a.x, s.b.y-s.a.y
+		sort.Slice(s.splits, func(i, j int) bool {
+			return (s.splits[i].x-s.a.x)*dx+(s.splits[i].y-s.a.y)*dy < (s.splits[j].x-s.a.x)*dx+(s.splits[j].y-s.a.y)*dy
+		})
+		ps := []xy{s.a}
+		for _, p := range append(s.splits, s.b) {
+			if p != ps[len(ps)-1] {
+				ps = append(ps, p)
+			}
+		}
+		for j := 1; j < len(ps); j++ {
+			piece := s
+			piece.a, piece.b, piece.splits, piece.fresh = ps[j-1], ps[j], nil, true
+			piece.first = s.first && j == 1
+			piece.last = s.last && j == len(ps)-1
+			segments = append(segments, piece)
+		}
+	}
+	ar.segments = segments
+}
+
+// addEdge records that the segment s runs from p to q.
+func (ar *arrangement) addEdge(s *segment, p, q xy) {
+	forward := p.less(q)
+	key := [2]xy{p, q}
+	if !forward {
+		key = [2]xy{q, p}
+	}
+	e := ar.edges[key]
+	if e == nil {
+		e = &topoEdge{a: key[0], b: key[1]}
+		ar.edges[key] = e
+	}
+	switch s.kind {
+	case lineSegment:
+		e.line[s.geom] = true
+	case ringSegment:
+		e.onRing[s.geom] = true
+		side := -1
+		if s.interiorLeft == forward {
+			side = 1
+		}
+		e.rings[s.geom] = append(e.rings[s.geom], edgeRing{area: s.area, side: side})
+	}
+}
+
+// label records whether the faces on either side of e, which lies on a
+// ring of geometry geom, are in the interior of its polygons. A side is in
+// the interior if a polygon whose ring runs along e lies on that side, or
+// if another polygon of the geometry covers e.
+func (ar *arrangement) label(geom int, e *topoEdge) {
+	rings := e.rings[geom]
+	var left, right bool
+	for i, r := range rings {
+		// Sum the sides over the rings of each polygon, since a shell and a
+		// hole of the same polygon may share the edge.
+		side, first := 0, true
+		for j, q := range rings {
+			if q.area == r.area {
+				side += q.side
+				first = first && j >= i
+			}
+		}
+		if first {
+			left = left || side > 0
+			right = right || side < 0
+		}
+	}
+	if !left || !right {
+		mid := e.mid()
+	areas:
+		for i, pp := range ar.areas[geom] {
+			for _, r := range rings {
+				if r.area == i {
+					continue areas
+				}
+			}
+			if pp.Locate(Point{X: mid.x, Y: mid.y}) == Interior {
+				left, right = true, true
+				break
+			}
+		}
+	}
+	e.left[geom], e.right[geom] = left, right
+}
+
+// areaLocation returns the location of p relative to the polygons of
+// geometry geom.
+func (ar *arrangement) areaLocation(geom int, p xy) Location {
+	l := Exterior
+	for _, pp := range ar.areas[geom] {
+		switch pp.Locate(Point{X: p.x, Y: p.y}) {
+		case Interior:
+			return Interior
+		case Boundary:
+			l = Boundary
+		}
+	}
+	return l
+}
+
+// nodeLocation returns the location of the node n at p relative to geometry
+// geom. Lines have the "mod 2" boundary of the OGC specification: the ends
+// of a closed line are in its interior.
+func (ar *arrangement) nodeLocation(geom int, p xy, n *topoNode) Location {
+	if n.boundary[geom] {
+		return Boundary
+	}
+	if n.ring[geom] {
+		return Interior
+	}
+	if l := ar.areaLocation(geom, p); l != Exterior {
+		return l
+	}
+	if n.lineEnds[geom]%2 == 1 {
+		return Boundary
+	}
+	if n.lineEnds[geom] > 0 || n.lineInterior[geom] || n.point[geom] {
+		return Interior
+	}
+	return Exterior
+}
+
+// edgeLocation returns the location of the interior of e relative to
+// geometry geom.
+func (ar *arrangement) edgeLocation(geom int, e *topoEdge) Location {
+	if e.onRing[geom] {
+		if e.left[geom] && e.right[geom] {
+			return Interior
+		}
+		return Boundary
+	}
+	if l := ar.areaLocation(geom, e.mid()); l != Exterior || !e.line[geom] {
+		return l
+	}
+	return Interior
+}
+
+// sideLocations returns the locations of the faces to the left and right of
+// e relative to the polygons of geometry geom.
+func (ar *arrangement) sideLocations(geom int, e *topoEdge) (left, right Location) {
+	if e.onRing[geom] {
+		return location(e.left[geom]), location(e.right[geom])
+	}
+	l := ar.areaLocation(geom, e.mid())
+	if l == Boundary {
+		// The edge is too close to the boundary to tell.
+		l = Exterior
+	}
+	return l, l
+}
+
+// location returns Interior if in is set and Exterior otherwise.
+func location(in bool) Location {
+	if in {
+		return Interior
+	}
+	return Exterior
+}
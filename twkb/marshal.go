@@ -0,0 +1,279 @@
+package twkb
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/pb"
+)
+
+// MarshalOptions controls encoding.
+type MarshalOptions struct {
+	// Number of decimal digits kept for X and Y, from -8 to 7. Negative
+	// values round to tens, hundreds, and so on.
+	Precision int
+	// Number of decimal digits kept for elevations, from 0 to 7.
+	ZPrecision int
+	// Whether to write bounding boxes.
+	BBox bool
+	// Whether to write the size of each geometry, which lets readers skip
+	// over geometries without decoding them.
+	Size bool
+	// IDs of the parts of a multi-geometry or the members of a
+	// GeometryCollection. If not nil, there must be exactly one per part.
+	IDs []int64
+}
+
+// Marshal encodes g as TWKB. If opts is nil, the zero MarshalOptions are
+// used, which round coordinates to integers.
+//
+// A geometry is written with elevations only if every one of its points has
+// an elevation. Members of a GeometryCollection are encoded independently,
+// so each may or may not have elevations.
+func Marshal(g geojson.Geometry, opts *MarshalOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &MarshalOptions{}
+	}
+	if opts.Precision < minPrecision || opts.Precision > maxPrecision {
+		return nil, fmt.Errorf("twkb: precision %d out of range", opts.Precision)
+	}
+	if opts.ZPrecision < 0 || opts.ZPrecision > maxZPrecision {
+		return nil, fmt.Errorf("twkb: Z precision %d out of range", opts.ZPrecision)
+	}
+	b, _, err := marshalGeometry(g, opts, opts.IDs)
+	if err != nil {
+		return nil, fmt.Errorf("twkb: %v", err)
+	}
+	return b, nil
+}
+
+// marshalGeometry encodes g, also returning the writer used for its body so
+// that collections can combine the bounds of their members.
+func marshalGeometry(g geojson.Geometry, opts *MarshalOptions, ids []int64) ([]byte, *writer, error) {
+	w := &writer{
+		scale:  math.Pow10(opts.Precision),
+		zScale: math.Pow10(opts.ZPrecision),
+	}
+	var geomType int
+	var parts int
+	switch t := g.(type) {
+	case *geojson.Point:
+		geomType, parts = typePoint, 1
+		w.z = t.HasElevation
+		w.point(t)
+	case *geojson.LineString:
+		geomType, parts = typeLineString, 1
+		w.z = allElevated(t.Points)
+		if len(t.Points) > 0 {
+			w.points(t.Points)
+		}
+	case *geojson.Polygon:
+		geomType, parts = typePolygon, 1
+		w.z = polygonElevated(t)
+		if len(t.Rings) > 0 {
+			w.polygon(t)
+		}
+	case *geojson.MultiPoint:
+		geomType, parts = typeMultiPoint, len(t.Points)
+		w.z = allElevated(t.Points)
+		if parts > 0 {
+			w.count(parts)
+			w.ids(ids)
+			for i := range t.Points {
+				w.point(&t.Points[i])
+			}
+		}
+	case *geojson.MultiLineString:
+		geomType, parts = typeMultiLineString, len(t.Lines)
+		w.z = parts > 0
+		for _, ls := range t.Lines {
+			w.z = w.z && allElevated(ls.Points)
+		}
+		if parts > 0 {
+			w.count(parts)
+			w.ids(ids)
+			for _, ls := range t.Lines {
+				w.points(ls.Points)
+			}
+		}
+	case *geojson.MultiPolygon:
+		geomType, parts = typeMultiPolygon, len(t.Polygons)
+		w.z = parts > 0
+		for i := range t.Polygons {
+			w.z = w.z && polygonElevated(&t.Polygons[i])
+		}
+		if parts > 0 {
+			w.count(parts)
+			w.ids(ids)
+			for i := range t.Polygons {
+				w.polygon(&t.Polygons[i])
+			}
+		}
+	case *geojson.GeometryCollection:
+		geomType, parts = typeGeometryCollection, len(t.Geometries)
+		if parts > 0 {
+			w.count(parts)
+			w.ids(ids)
+			for i, child := range t.Geometries {
+				b, cw, err := marshalGeometry(child, opts, nil)
+				if err != nil {
+					return nil, nil, fmt.Errorf("GeometryCollection: geometry %d: %v", i, err)
+				}
+				w.body = append(w.body, b...)
+				// Collections are two-dimensional, so their bounds are too.
+				if cw.seen {
+					for j := 0; j < 2; j++ {
+						w.bound(j, cw.min[j], cw.max[j])
+					}
+					w.seen = true
+				}
+			}
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported geometry type: %T", g)
+	}
+	if w.err != nil {
+		return nil, nil, w.err
+	}
+	empty := len(w.body) == 0
+	if ids != nil {
+		if geomType < typeMultiPoint {
+			return nil, nil, fmt.Errorf("ID list given for single %T", g)
+		}
+		if len(ids) != parts {
+			return nil, nil, fmt.Errorf("got %d IDs for %d parts", len(ids), parts)
+		}
+	}
+
+	b := []byte{byte(geomType) | byte(pb.EncodeZigZag(int64(opts.Precision)))<<4}
+	var flags byte
+	if opts.BBox && w.seen {
+		flags |= flagBBox
+	}
+	if opts.Size {
+		flags |= flagSize
+	}
+	if ids != nil && !empty {
+		flags |= flagIDList
+	}
+	if w.z {
+		flags |= flagExtendedPrecision
+	}
+	if empty {
+		flags |= flagEmpty
+	}
+	b = append(b, flags)
+	if w.z {
+		b = append(b, flagZ|byte(opts.ZPrecision)<<2)
+	}
+
+	var rest []byte
+	if flags&flagBBox != 0 {
+		for j := 0; j < w.dims(); j++ {
+			rest = pb.AppendVarint(rest, pb.EncodeZigZag(w.min[j]))
+			rest = pb.AppendVarint(rest, pb.EncodeZigZag(w.max[j]-w.min[j]))
+		}
+	}
+	rest = append(rest, w.body...)
+	if opts.Size {
+		b = pb.AppendVarint(b, uint64(len(rest)))
+	}
+	return append(b, rest...), w, nil
+}
+
+// A writer encodes the body of a single geometry, tracking the previous
+// position for delta encoding and the bounds of all positions.
+type writer struct {
+	scale, zScale float64
+	z             bool
+	body          []byte
+	last          [3]int64
+	min, max      [3]int64
+	seen          bool
+	err           error
+}
+
+func (w *writer) dims() int {
+	if w.z {
+		return 3
+	}
+	return 2
+}
+
+func (w *writer) count(n int) {
+	w.body = pb.AppendVarint(w.body, uint64(n))
+}
+
+func (w *writer) ids(ids []int64) {
+	for _, id := range ids {
+		w.body = pb.AppendVarint(w.body, pb.EncodeZigZag(id))
+	}
+}
+
+func (w *writer) scaled(v, scale float64) int64 {
+	s := math.Round(v * scale)
+	if math.IsNaN(s) || math.Abs(s) >= 1<<63 {
+		if w.err == nil {
+			w.err = fmt.Errorf("coordinate %v out of range", v)
+		}
+		return 0
+	}
+	return int64(s)
+}
+
+func (w *writer) point(p *geojson.Point) {
+	c := [3]int64{w.scaled(p.X, w.scale), w.scaled(p.Y, w.scale)}
+	if w.z {
+		c[2] = w.scaled(p.Elevation, w.zScale)
+	}
+	for j := 0; j < w.dims(); j++ {
+		w.body = pb.AppendVarint(w.body, pb.EncodeZigZag(c[j]-w.last[j]))
+		w.last[j] = c[j]
+		w.bound(j, c[j], c[j])
+	}
+	w.seen = true
+}
+
+// bound extends the bounds along dimension j to include lo through hi.
+func (w *writer) bound(j int, lo, hi int64) {
+	if !w.seen || lo < w.min[j] {
+		w.min[j] = lo
+	}
+	if !w.seen || hi > w.max[j] {
+		w.max[j] = hi
+	}
+}
+
+// points writes a counted list of positions.
+func (w *writer) points(points []geojson.Point) {
+	w.count(len(points))
+	for i := range points {
+		w.point(&points[i])
+	}
+}
+
+func (w *writer) polygon(p *geojson.Polygon) {
+	w.count(len(p.Rings))
+	for _, ring := range p.Rings {
+		w.points(ring.Points)
+	}
+}
+
+func allElevated(points []geojson.Point) bool {
+	for _, p := range points {
+		if !p.HasElevation {
+			return false
+		}
+	}
+	return len(points) > 0
+}
+
+func polygonElevated(p *geojson.Polygon) bool {
+	for _, ring := range p.Rings {
+		if !allElevated(ring.Points) {
+			return false
+		}
+	}
+	return len(p.Rings) > 0
+}
@@ -0,0 +1,126 @@
+package twkb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestMarshal(t *testing.T) {
+	cases := []struct {
+		g        geojson.Geometry
+		opts     *MarshalOptions
+		expected []byte
+	}{
+		{
+			g:        &geojson.Point{X: 1, Y: 2},
+			expected: []byte{0x01, 0x00, 0x02, 0x04},
+		},
+		{
+			g:        &geojson.LineString{Points: []geojson.Point{{X: 1, Y: 1}, {X: 5, Y: 5}}},
+			expected: []byte{0x02, 0x00, 0x02, 0x02, 0x02, 0x08, 0x08},
+		},
+		{
+			g:        &geojson.Point{X: 1.5, Y: -2.25},
+			opts:     &MarshalOptions{Precision: 2},
+			expected: []byte{0x41, 0x00, 0xac, 0x02, 0xc1, 0x03},
+		},
+		{
+			g:        &geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+			opts:     &MarshalOptions{ZPrecision: 1},
+			expected: []byte{0x01, 0x08, 0x05, 0x02, 0x04, 0x3c},
+		},
+		{
+			g:    &geojson.MultiPoint{Points: []geojson.Point{{X: 1, Y: 1}, {X: 2, Y: 2}}},
+			opts: &MarshalOptions{BBox: true, Size: true, IDs: []int64{5, 6}},
+			expected: []byte{
+				0x04, 0x07, 0x0b,
+				0x02, 0x02, 0x02, 0x02,
+				0x02, 0x0a, 0x0c,
+				0x02, 0x02, 0x02, 0x02,
+			},
+		},
+		{
+			g:        &geojson.MultiPolygon{},
+			opts:     &MarshalOptions{BBox: true},
+			expected: []byte{0x06, 0x10},
+		},
+	}
+	for i, c := range cases {
+		got, err := Marshal(c.g, c.opts)
+		if err != nil {
+			t.Errorf("case %d: failed to marshal: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("case %d: expected % x, got % x", i, c.expected, got)
+		}
+	}
+}
+
+func TestMarshal_Invalid(t *testing.T) {
+	point := &geojson.Point{X: 1, Y: 2}
+	multi := &geojson.MultiPoint{Points: []geojson.Point{*point}}
+	cases := []struct {
+		g    geojson.Geometry
+		opts *MarshalOptions
+	}{
+		{point, &MarshalOptions{Precision: 8}},
+		{point, &MarshalOptions{Precision: -9}},
+		{point, &MarshalOptions{ZPrecision: -1}},
+		{point, &MarshalOptions{IDs: []int64{1}}},
+		{multi, &MarshalOptions{IDs: []int64{1, 2}}},
+		{&geojson.Point{X: 1e300}, &MarshalOptions{Precision: 7}},
+	}
+	for i, c := range cases {
+		_, err := Marshal(c.g, c.opts)
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestRoundTrip_Marshal(t *testing.T) {
+	square := func(x, y float64) geojson.LineString {
+		return geojson.LineString{Points: []geojson.Point{
+			{X: x, Y: y}, {X: x + 1, Y: y}, {X: x + 1, Y: y + 1}, {X: x, Y: y + 1}, {X: x, Y: y},
+		}}
+	}
+	opts := &MarshalOptions{Precision: 3, ZPrecision: 2, BBox: true, Size: true}
+	cases := []geojson.Geometry{
+		&geojson.Point{X: -122.419, Y: 37.775},
+		&geojson.Point{X: 1.5, Y: 2.5, Elevation: 10.25, HasElevation: true},
+		&geojson.LineString{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1.001, Y: -2}}},
+		&geojson.Polygon{Rings: []geojson.LineString{square(0, 0), square(0.25, 0.25)}},
+		&geojson.MultiPoint{Points: []geojson.Point{{X: 1, Y: 2}, {X: 3, Y: 4}}},
+		&geojson.MultiLineString{Lines: []geojson.LineString{square(0, 0), square(5, 5)}},
+		&geojson.MultiPolygon{Polygons: []geojson.Polygon{
+			{Rings: []geojson.LineString{square(0, 0)}},
+			{Rings: []geojson.LineString{square(10, 10), square(10.5, 10.5)}},
+		}},
+		&geojson.GeometryCollection{Geometries: []geojson.Geometry{
+			&geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+			&geojson.LineString{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+			&geojson.MultiPoint{},
+		}},
+		&geojson.MultiPoint{},
+		&geojson.MultiLineString{},
+		&geojson.GeometryCollection{},
+	}
+	for i, c := range cases {
+		b, err := Marshal(c, opts)
+		if err != nil {
+			t.Errorf("case %d: failed to marshal: %v", i, err)
+			continue
+		}
+		got, err := Unmarshal(b)
+		if err != nil {
+			t.Errorf("case %d: failed to unmarshal: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c) {
+			t.Errorf("case %d: expected %#v, got %#v", i, c, got)
+		}
+	}
+}
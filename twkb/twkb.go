@@ -0,0 +1,65 @@
+// Package twkb converts between geometries and Tiny Well-Known Binary
+// (TWKB).
+//
+// TWKB stores coordinates as varint-encoded deltas of integers scaled by a
+// power of 10, so values are rounded to the chosen number of decimal digits.
+// Measures (M values) are not represented by the geojson types; they are
+// discarded when decoding and never written.
+//
+// Empty MultiPoints, MultiLineStrings, MultiPolygons, and GeometryCollections
+// are supported. The geojson types cannot represent empty Points,
+// LineStrings, or Polygons, so decoding them is an error.
+//
+// See https://github.com/TWKB/Specification for the format definition.
+package twkb
+
+import "github.com/bsidhom/geojson"
+
+// Geometry types.
+const (
+	typePoint              = 1
+	typeLineString         = 2
+	typePolygon            = 3
+	typeMultiPoint         = 4
+	typeMultiLineString    = 5
+	typeMultiPolygon       = 6
+	typeGeometryCollection = 7
+)
+
+// Metadata header flags.
+const (
+	flagBBox              = 1 << 0
+	flagSize              = 1 << 1
+	flagIDList            = 1 << 2
+	flagExtendedPrecision = 1 << 3
+	flagEmpty             = 1 << 4
+)
+
+// Extended precision flags.
+const (
+	flagZ = 1 << 0
+	flagM = 1 << 1
+)
+
+// Ranges of the precision fields.
+const (
+	minPrecision  = -8
+	maxPrecision  = 7
+	maxZPrecision = 7
+)
+
+// A Geometry is a decoded TWKB geometry along with the optional parts of its
+// header.
+type Geometry struct {
+	Geometry geojson.Geometry
+	// IDs of the parts of a multi-geometry or the members of a
+	// GeometryCollection, if an ID list was present.
+	IDs []int64
+	// Bounding box in GeoJSON order (minimums for each dimension followed by
+	// maximums), if present. Measures are excluded.
+	BBox []float64
+	// Number of decimal digits kept for X and Y, which may be negative.
+	Precision int
+	// Number of decimal digits kept for elevations, if there are any.
+	ZPrecision int
+}
@@ -0,0 +1,276 @@
+package twkb
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/bsidhom/geojson"
+	"github.com/bsidhom/geojson/internal/pb"
+)
+
+// Unmarshal decodes a single TWKB geometry, which must span all of b.
+func Unmarshal(b []byte) (geojson.Geometry, error) {
+	g, n, err := Decode(b)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(b) {
+		return nil, fmt.Errorf("twkb: %d trailing bytes", len(b)-n)
+	}
+	return g.Geometry, nil
+}
+
+// Decode decodes the TWKB geometry at the start of b, returning it along
+// with its header and the number of bytes it occupied. Use this to read
+// concatenated geometries or to recover ID lists and bounding boxes.
+func Decode(b []byte) (*Geometry, int, error) {
+	r := &reader{buf: b}
+	g := r.geometry()
+	if r.err != nil {
+		return nil, 0, fmt.Errorf("twkb: %v", r.err)
+	}
+	return g, r.pos, nil
+}
+
+// A reader decodes TWKB. The first error encountered is kept in err, after
+// which all reads return zero values.
+type reader struct {
+	buf []byte
+	pos int
+	err error
+}
+
+func (r *reader) fail(format string, args ...interface{}) {
+	if r.err == nil {
+		r.err = fmt.Errorf(format, args...)
+	}
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if r.pos >= len(r.buf) {
+		r.fail("unexpected end of input at byte %d", r.pos)
+		return 0
+	}
+	c := r.buf[r.pos]
+	r.pos++
+	return c
+}
+
+func (r *reader) uvarint() uint64 {
+	var v uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		c := r.byte()
+		if r.err != nil {
+			return 0
+		}
+		v |= uint64(c&0x7f) << shift
+		if c < 0x80 {
+			return v
+		}
+	}
+	r.fail("varint overflow at byte %d", r.pos)
+	return 0
+}
+
+func (r *reader) varint() int64 {
+	return pb.DecodeZigZag(r.uvarint())
+}
+
+// count reads a number of elements, each of which occupies at least min
+// bytes, rejecting counts that cannot fit in the remaining input.
+func (r *reader) count(min int) int {
+	n := r.uvarint()
+	if r.err == nil && n > uint64(len(r.buf)-r.pos)/uint64(min) {
+		r.fail("count %d exceeds input", n)
+		return 0
+	}
+	return int(n)
+}
+
+// A geometryReader decodes the body of a single geometry.
+type geometryReader struct {
+	*reader
+	dims          int
+	scale, zScale float64
+	last          [4]int64
+}
+
+func (r *reader) geometry() *Geometry {
+	header := r.byte()
+	flags := r.byte()
+	if r.err != nil {
+		return nil
+	}
+	geomType := int(header & 0x0f)
+	g := &Geometry{Precision: int(pb.DecodeZigZag(uint64(header >> 4)))}
+	gr := &geometryReader{reader: r, dims: 2}
+	hasZ := false
+	if flags&flagExtendedPrecision != 0 {
+		ext := r.byte()
+		if ext&flagZ != 0 {
+			hasZ = true
+			gr.dims++
+			g.ZPrecision = int(ext>>2) & 0x7
+		}
+		if ext&flagM != 0 {
+			gr.dims++
+		}
+	}
+	gr.scale = math.Pow10(g.Precision)
+	gr.zScale = math.Pow10(g.ZPrecision)
+	end := -1
+	if flags&flagSize != 0 {
+		size := r.uvarint()
+		if r.err == nil && size > uint64(len(r.buf)-r.pos) {
+			r.fail("size %d exceeds input", size)
+		}
+		end = r.pos + int(size)
+	}
+	if flags&flagBBox != 0 {
+		var min, max []float64
+		for j := 0; j < gr.dims; j++ {
+			lo := r.varint()
+			hi := lo + r.varint()
+			switch {
+			case j < 2:
+				min = append(min, float64(lo)/gr.scale)
+				max = append(max, float64(hi)/gr.scale)
+			case j == 2 && hasZ:
+				min = append(min, float64(lo)/gr.zScale)
+				max = append(max, float64(hi)/gr.zScale)
+			}
+		}
+		g.BBox = append(min, max...)
+	}
+	if r.err != nil {
+		return nil
+	}
+
+	empty := flags&flagEmpty != 0
+	hasIDs := flags&flagIDList != 0
+	switch geomType {
+	case typePoint:
+		if empty {
+			r.fail("empty Point is not supported")
+			break
+		}
+		p := gr.point(hasZ)
+		g.Geometry = &p
+	case typeLineString:
+		if empty {
+			r.fail("empty LineString is not supported")
+			break
+		}
+		g.Geometry = &geojson.LineString{Points: gr.points(hasZ)}
+	case typePolygon:
+		if empty {
+			r.fail("empty Polygon is not supported")
+			break
+		}
+		p := gr.polygon(hasZ)
+		g.Geometry = &p
+	case typeMultiPoint:
+		m := &geojson.MultiPoint{}
+		if !empty {
+			n := r.count(gr.dims)
+			g.IDs = gr.ids(hasIDs, n)
+			m.Points = make([]geojson.Point, n)
+			for i := range m.Points {
+				m.Points[i] = gr.point(hasZ)
+			}
+		}
+		g.Geometry = m
+	case typeMultiLineString:
+		m := &geojson.MultiLineString{}
+		if !empty {
+			n := r.count(1)
+			g.IDs = gr.ids(hasIDs, n)
+			m.Lines = make([]geojson.LineString, n)
+			for i := range m.Lines {
+				m.Lines[i].Points = gr.points(hasZ)
+			}
+		}
+		g.Geometry = m
+	case typeMultiPolygon:
+		m := &geojson.MultiPolygon{}
+		if !empty {
+			n := r.count(1)
+			g.IDs = gr.ids(hasIDs, n)
+			m.Polygons = make([]geojson.Polygon, n)
+			for i := range m.Polygons {
+				m.Polygons[i] = gr.polygon(hasZ)
+			}
+		}
+		g.Geometry = m
+	case typeGeometryCollection:
+		gc := &geojson.GeometryCollection{}
+		if !empty {
+			n := r.count(2)
+			g.IDs = gr.ids(hasIDs, n)
+			gc.Geometries = make([]geojson.Geometry, n)
+			for i := range gc.Geometries {
+				child := r.geometry()
+				if r.err != nil {
+					r.err = fmt.Errorf("GeometryCollection: geometry %d: %v", i, r.err)
+					break
+				}
+				gc.Geometries[i] = child.Geometry
+			}
+		}
+		g.Geometry = gc
+	default:
+		r.fail("unknown geometry type %d", geomType)
+	}
+	if r.err != nil {
+		return nil
+	}
+	if end >= 0 && r.pos != end {
+		r.fail("geometry size %d does not match contents", end)
+		return nil
+	}
+	return g
+}
+
+func (r *geometryReader) ids(present bool, n int) []int64 {
+	if !present {
+		return nil
+	}
+	ids := make([]int64, n)
+	for i := range ids {
+		ids[i] = r.varint()
+	}
+	return ids
+}
+
+func (r *geometryReader) point(hasZ bool) geojson.Point {
+	var c [4]int64
+	for j := 0; j < r.dims; j++ {
+		r.last[j] += r.varint()
+		c[j] = r.last[j]
+	}
+	p := geojson.Point{X: float64(c[0]) / r.scale, Y: float64(c[1]) / r.scale}
+	if hasZ {
+		p.Elevation = float64(c[2]) / r.zScale
+		p.HasElevation = true
+	}
+	return p
+}
+
+func (r *geometryReader) points(hasZ bool) []geojson.Point {
+	points := make([]geojson.Point, r.count(r.dims))
+	for i := range points {
+		points[i] = r.point(hasZ)
+	}
+	return points
+}
+
+func (r *geometryReader) polygon(hasZ bool) geojson.Polygon {
+	rings := make([]geojson.LineString, r.count(1))
+	for i := range rings {
+		rings[i].Points = r.points(hasZ)
+	}
+	return geojson.Polygon{Rings: rings}
+}
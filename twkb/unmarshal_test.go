@@ -0,0 +1,98 @@
+package twkb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestDecode(t *testing.T) {
+	cases := []struct {
+		b        []byte
+		expected *Geometry
+	}{
+		{
+			// A Point with an M value, which is dropped.
+			b: []byte{0x01, 0x08, 0x02, 0x02, 0x04, 0x06},
+			expected: &Geometry{
+				Geometry: &geojson.Point{X: 1, Y: 2},
+			},
+		},
+		{
+			b: []byte{
+				0x04, 0x07, 0x0b,
+				0x02, 0x02, 0x02, 0x02,
+				0x02, 0x0a, 0x0c,
+				0x02, 0x02, 0x02, 0x02,
+			},
+			expected: &Geometry{
+				Geometry: &geojson.MultiPoint{Points: []geojson.Point{{X: 1, Y: 1}, {X: 2, Y: 2}}},
+				IDs:      []int64{5, 6},
+				BBox:     []float64{1, 1, 2, 2},
+			},
+		},
+		{
+			// Precision -1 with a Z bounding box at precision 1.
+			b: []byte{0x11, 0x09, 0x05, 0x02, 0x00, 0x04, 0x00, 0x14, 0x00, 0x02, 0x04, 0x14},
+			expected: &Geometry{
+				Geometry:   &geojson.Point{X: 10, Y: 20, Elevation: 1, HasElevation: true},
+				BBox:       []float64{10, 20, 1, 10, 20, 1},
+				Precision:  -1,
+				ZPrecision: 1,
+			},
+		},
+	}
+	for i, c := range cases {
+		got, n, err := Decode(c.b)
+		if err != nil {
+			t.Errorf("case %d: failed to decode: %v", i, err)
+			continue
+		}
+		if n != len(c.b) {
+			t.Errorf("case %d: expected to consume %d bytes, consumed %d", i, len(c.b), n)
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("case %d: expected %#v, got %#v", i, c.expected, got)
+		}
+	}
+}
+
+func TestDecode_Concatenated(t *testing.T) {
+	b := []byte{0x01, 0x00, 0x02, 0x04, 0x01, 0x00, 0x06, 0x08}
+	var got []geojson.Geometry
+	for len(b) > 0 {
+		g, n, err := Decode(b)
+		if err != nil {
+			t.Fatalf("failed to decode: %v", err)
+		}
+		got = append(got, g.Geometry)
+		b = b[n:]
+	}
+	expected := []geojson.Geometry{&geojson.Point{X: 1, Y: 2}, &geojson.Point{X: 3, Y: 4}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %#v, got %#v", expected, got)
+	}
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	cases := [][]byte{
+		nil,
+		{0x01},
+		{0x01, 0x00, 0x02},
+		{0x01, 0x10},
+		{0x02, 0x10},
+		{0x03, 0x10},
+		{0x08, 0x00},
+		{0x02, 0x00, 0x7f, 0x02, 0x02},
+		{0x01, 0x02, 0x09, 0x02, 0x04},
+		{0x01, 0x00, 0x02, 0x04, 0x00},
+		{0x01, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0x02},
+	}
+	for i, c := range cases {
+		_, err := Unmarshal(c)
+		if err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
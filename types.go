@@ -39,6 +39,10 @@ type Object interface {
 // - LineString
 // - MultiPoint
 // - Point
+//
+// The JSON-FG geometry types (Polyhedron, MultiPolyhedron, Prism, MultiPrism,
+// CircularString, CompoundCurve, CurvePolygon, MultiCurve, and MultiSurface)
+// are also Geometries, but may only be used as the Place of a Feature.
 type Geometry interface {
 	Object
 	isGeometry()
@@ -48,6 +52,13 @@ type Geometry interface {
 type FeatureCollection struct {
 	// The features contained in this collection.
 	Features []Feature
+	// JSON-FG feature types and coordinate reference system shared by all
+	// features. Optional.
+	FeatureType []string
+	CoordRefSys *CoordRefSys
+	// Reference system declared by a GeoJSON 2008 "crs" member. This is only
+	// set by UnmarshalLegacy.
+	CRS *CRS
 }
 
 func (*FeatureCollection) isObject() {}
@@ -62,6 +73,16 @@ type Feature struct {
 	// ID associated with this feature. Optional. For best compatibility, this
 	// should go under properties.
 	ID string
+	// JSON-FG members. All are optional. Place is the feature's geometry in
+	// the reference system given by CoordRefSys, and may be any Geometry.
+	// Setting any of these causes the feature to be written as JSON-FG.
+	Place       Geometry
+	Time        *Time
+	FeatureType []string
+	CoordRefSys *CoordRefSys
+	// Reference system declared by a GeoJSON 2008 "crs" member. This is only
+	// set by UnmarshalLegacy.
+	CRS *CRS
 }
 
 func (*Feature) isObject() {}
@@ -103,6 +124,8 @@ func (*MultiPolygon) isGeometry() {}
 //
 // This library does not make any attempt to be backward-compatible with
 // GeoJSON 2008, which did not enforce handedness in enclosing linear rings.
+// The only GeoJSON 2008 feature supported is the "crs" member, which must be
+// requested with UnmarshalLegacy.
 type Polygon struct {
 	// Linear rings that constitute this Polygon. Each LineString must consist
 	// of at least 4 positions.
@@ -24,30 +24,11 @@ func (w *Wrapper) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
-	switch t := wireWrapper.Value.(type) {
-	case *wire.FeatureCollection:
-		f := &FeatureCollection{}
-		err := f.unmarshalFrom(t)
-		if err != nil {
-			return err
-		}
-		w.Value = f
-	case *wire.Feature:
-		f := &Feature{}
-		err := f.unmarshalFrom(t)
-		if err != nil {
-			return err
-		}
-		w.Value = f
-	case wire.Geometry:
-		result, err := unmarshalGeometry(t)
-		if err != nil {
-			return err
-		}
-		w.Value = result
-	default:
-		return fmt.Errorf("invalid wire object type: %T", t)
+	obj, err := FromWire(wireWrapper.Value)
+	if err != nil {
+		return err
 	}
+	w.Value = obj
 	return nil
 }
 
@@ -74,6 +55,9 @@ func (f *FeatureCollection) unmarshalFrom(w *wire.FeatureCollection) error {
 	}
 
 	f.Features = features
+	f.FeatureType = w.FeatureType
+	f.CoordRefSys = coordRefSysFromWire(w.CoordRefSys)
+	f.CRS = crsFromWire(w.CRS)
 	return nil
 }
 
@@ -88,14 +72,32 @@ func (f *Feature) UnmarshalJSON(b []byte) error {
 
 func (f *Feature) unmarshalFrom(w *wire.Feature) error {
 	*f = Feature{}
-	g, err := unmarshalGeometry(w.Geometry)
-	if err != nil {
-		return err
+	var err error
+	if w.Geometry != nil {
+		f.Geometry, err = unmarshalGeometry(w.Geometry)
+		if err != nil {
+			return err
+		}
+	}
+	if w.Place != nil {
+		f.Place, err = unmarshalGeometry(w.Place)
+		if err != nil {
+			return fmt.Errorf("unmarshal Feature: place: %v", err)
+		}
+	}
+	if w.Time != nil {
+		f.Time = &Time{}
+		err = f.Time.unmarshalFrom(w.Time)
+		if err != nil {
+			return err
+		}
 	}
 
-	f.Geometry = g
 	f.Properties = w.Properties
 	f.ID = w.ID
+	f.FeatureType = w.FeatureType
+	f.CoordRefSys = coordRefSysFromWire(w.CoordRefSys)
+	f.CRS = crsFromWire(w.CRS)
 	return nil
 }
 
@@ -351,6 +353,69 @@ func unmarshalGeometry(g wire.Geometry) (Geometry, error) {
 			return nil, err
 		}
 		result = p
+	case *wire.Polyhedron:
+		p := &Polyhedron{}
+		err := p.unmarshalFrom(t.Coordinates)
+		if err != nil {
+			return nil, err
+		}
+		result = p
+	case *wire.MultiPolyhedron:
+		m := &MultiPolyhedron{}
+		err := m.unmarshalFrom(t.Coordinates)
+		if err != nil {
+			return nil, err
+		}
+		result = m
+	case *wire.Prism:
+		p := &Prism{}
+		err := p.unmarshalFrom(t)
+		if err != nil {
+			return nil, err
+		}
+		result = p
+	case *wire.MultiPrism:
+		m := &MultiPrism{}
+		err := m.unmarshalFrom(t.Prisms)
+		if err != nil {
+			return nil, err
+		}
+		result = m
+	case *wire.CircularString:
+		c := &CircularString{}
+		err := c.unmarshalFrom(t.Coordinates)
+		if err != nil {
+			return nil, err
+		}
+		result = c
+	case *wire.CompoundCurve:
+		c := &CompoundCurve{}
+		err := c.unmarshalFrom(t.Geometries)
+		if err != nil {
+			return nil, err
+		}
+		result = c
+	case *wire.CurvePolygon:
+		c := &CurvePolygon{}
+		err := c.unmarshalFrom(t.Geometries)
+		if err != nil {
+			return nil, err
+		}
+		result = c
+	case *wire.MultiCurve:
+		m := &MultiCurve{}
+		err := m.unmarshalFrom(t.Geometries)
+		if err != nil {
+			return nil, err
+		}
+		result = m
+	case *wire.MultiSurface:
+		m := &MultiSurface{}
+		err := m.unmarshalFrom(t.Geometries)
+		if err != nil {
+			return nil, err
+		}
+		result = m
 	default:
 		return nil, fmt.Errorf("invalid wire geometry type: %T", t)
 	}
@@ -0,0 +1,147 @@
+package geojson
+
+import (
+	"fmt"
+
+	"github.com/bsidhom/geojson/wire"
+)
+
+// FromWire converts a low-level object into its high-level equivalent,
+// applying the same validation as JSON deserialization. Bounding boxes and
+// foreign members are discarded, as is the CRS of geometries.
+func FromWire(obj wire.Object) (Object, error) {
+	switch t := obj.(type) {
+	case *wire.FeatureCollection:
+		f := &FeatureCollection{}
+		err := f.unmarshalFrom(t)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	case *wire.Feature:
+		f := &Feature{}
+		err := f.unmarshalFrom(t)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	case wire.Geometry:
+		return unmarshalGeometry(t)
+	}
+	return nil, fmt.Errorf("invalid wire object type: %T", obj)
+}
+
+// ToWire converts obj into its low-level equivalent, which can be serialized.
+// It returns nil if obj is nil.
+func ToWire(obj Object) wire.Object {
+	switch t := obj.(type) {
+	case *FeatureCollection:
+		return t.toWire()
+	case *Feature:
+		w := t.toWire()
+		if t.isJSONFG() {
+			w.ConformsTo = []string{wire.JSONFGCore}
+		}
+		return w
+	case Geometry:
+		// All wire geometries are also wire objects.
+		w, _ := geometryToWire(t).(wire.Object)
+		return w
+	}
+	return nil
+}
+
+func (f *FeatureCollection) toWire() *wire.FeatureCollection {
+	features := make([]wire.Feature, len(f.Features))
+	for i := range f.Features {
+		features[i] = *f.Features[i].toWire()
+	}
+	w := &wire.FeatureCollection{
+		Features:    features,
+		FeatureType: f.FeatureType,
+		CoordRefSys: f.CoordRefSys.toWire(),
+		CRS:         f.CRS.toWire(),
+	}
+	if f.isJSONFG() {
+		w.ConformsTo = []string{wire.JSONFGCore}
+	}
+	return w
+}
+
+// toWire converts f without a JSON-FG conformance declaration, which belongs
+// to the enclosing FeatureCollection if there is one. ToWire adds it for
+// standalone features.
+func (f *Feature) toWire() *wire.Feature {
+	w := &wire.Feature{
+		Geometry:    geometryToWire(f.Geometry),
+		Properties:  f.Properties,
+		ID:          f.ID,
+		FeatureType: f.FeatureType,
+		CoordRefSys: f.CoordRefSys.toWire(),
+		Place:       geometryToWire(f.Place),
+		CRS:         f.CRS.toWire(),
+	}
+	if f.Time != nil {
+		w.Time = f.Time.toWire()
+	}
+	return w
+}
+
+func geometryToWire(g Geometry) wire.Geometry {
+	switch t := g.(type) {
+	case *GeometryCollection:
+		geometries := make([]wire.Geometry, len(t.Geometries))
+		for i, child := range t.Geometries {
+			geometries[i] = geometryToWire(child)
+		}
+		return &wire.GeometryCollection{Geometries: geometries}
+	case *MultiPolygon:
+		coords := make([][][][]float64, len(t.Polygons))
+		for i := range t.Polygons {
+			coords[i] = t.Polygons[i].coordinates()
+		}
+		return &wire.MultiPolygon{Coordinates: coords}
+	case *Polygon:
+		return &wire.Polygon{Coordinates: t.coordinates()}
+	case *MultiLineString:
+		coords := make([][][]float64, len(t.Lines))
+		for i := range t.Lines {
+			coords[i] = t.Lines[i].coordinates()
+		}
+		return &wire.MultiLineString{Coordinates: coords}
+	case *LineString:
+		return &wire.LineString{Coordinates: t.coordinates()}
+	case *MultiPoint:
+		return &wire.MultiPoint{Coordinates: pointCoordinates(t.Points)}
+	case *Point:
+		return &wire.Point{Coordinates: t.coordinates()}
+	}
+	return jsonfgGeometryToWire(g)
+}
+
+func (p *Polygon) coordinates() [][][]float64 {
+	coords := make([][][]float64, len(p.Rings))
+	for i := range p.Rings {
+		coords[i] = p.Rings[i].coordinates()
+	}
+	return coords
+}
+
+func (ls *LineString) coordinates() [][]float64 {
+	return pointCoordinates(ls.Points)
+}
+
+func pointCoordinates(points []Point) [][]float64 {
+	coords := make([][]float64, len(points))
+	for i := range points {
+		coords[i] = points[i].coordinates()
+	}
+	return coords
+}
+
+func (p *Point) coordinates() []float64 {
+	if p.HasElevation {
+		return []float64{p.X, p.Y, p.Elevation}
+	}
+	return []float64{p.X, p.Y}
+}
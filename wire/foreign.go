@@ -0,0 +1,78 @@
+package wire
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Members defined by RFC 7946 for each kind of object. All other members are
+// foreign members.
+var (
+	featureCollectionMembers  = []string{"type", "bbox", "features"}
+	featureMembers            = []string{"type", "bbox", "geometry", "properties", "id"}
+	geometryCollectionMembers = []string{"type", "bbox", "geometries"}
+	coordinatesMembers        = []string{"type", "bbox", "coordinates"}
+)
+
+func isMember(name string, members []string) bool {
+	for _, m := range members {
+		if name == m {
+			return true
+		}
+	}
+	return false
+}
+
+// appendForeignMembers adds the foreign members to the JSON object b.
+// Entries that collide with the given standard members are ignored so that
+// they cannot produce duplicate keys.
+func appendForeignMembers(b []byte, foreign map[string]interface{}, members []string) ([]byte, error) {
+	extra := map[string]interface{}{}
+	for k, v := range foreign {
+		if !isMember(k, members) {
+			extra[k] = v
+		}
+	}
+	if len(extra) == 0 {
+		return b, nil
+	}
+	fb, err := json.Marshal(extra)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 2 || b[len(b)-1] != '}' {
+		return nil, fmt.Errorf("invalid JSON object: %s", b)
+	}
+	result := make([]byte, 0, len(b)+len(fb))
+	result = append(result, b[:len(b)-1]...)
+	if len(b) > 2 {
+		result = append(result, ',')
+	}
+	return append(result, fb[1:]...), nil
+}
+
+// foreignMembers returns the members of the JSON object b that are not
+// among the given standard members, or nil if there are none.
+func foreignMembers(b []byte, members []string) (map[string]interface{}, error) {
+	var raw map[string]json.RawMessage
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return nil, err
+	}
+	var foreign map[string]interface{}
+	for k, v := range raw {
+		if isMember(k, members) {
+			continue
+		}
+		var value interface{}
+		err := json.Unmarshal(v, &value)
+		if err != nil {
+			return nil, err
+		}
+		if foreign == nil {
+			foreign = map[string]interface{}{}
+		}
+		foreign[k] = value
+	}
+	return foreign, nil
+}
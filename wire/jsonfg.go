@@ -0,0 +1,710 @@
+package wire
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+var _ json.Marshaler = (*CoordRefSys)(nil)
+var _ json.Marshaler = (*Time)(nil)
+var _ json.Unmarshaler = (*CoordRefSys)(nil)
+var _ json.Unmarshaler = (*Time)(nil)
+
+// JSON-FG (OGC Features and Geometries JSON) extends GeoJSON with a "place"
+// member holding geometries in other coordinate reference systems, a "time"
+// member, and "coordRefSys", "featureType", and "conformsTo" members. See
+// https://docs.ogc.org/DRAFTS/21-045.html for the specification.
+//
+// JSON-FG members are only recognized in documents that declare conformance
+// to JSON-FG in "conformsTo", either on the feature itself or on its
+// enclosing FeatureCollection. In other documents they remain foreign
+// members, so plain GeoJSON decodes exactly as before.
+
+// JSONFGCore is the JSON-FG core conformance class, declared in the
+// conformsTo member of JSON-FG documents.
+const JSONFGCore = "http://www.opengis.net/spec/json-fg-1/0.2/conf/core"
+
+// Prefixes of JSON-FG conformance classes, as URIs and as safe CURIEs.
+var jsonfgPrefixes = []string{
+	"http://www.opengis.net/spec/json-fg-1/",
+	"https://www.opengis.net/spec/json-fg-1/",
+	"[ogc-json-fg-1-",
+}
+
+// isJSONFG reports whether conformsTo declares a JSON-FG conformance class.
+func isJSONFG(conformsTo []string) bool {
+	for _, c := range conformsTo {
+		for _, prefix := range jsonfgPrefixes {
+			if strings.HasPrefix(c, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// JSON-FG geometry types. These are only valid in the place member of a
+// feature or nested within other JSON-FG geometries.
+const (
+	polyhedronType      = "Polyhedron"
+	multiPolyhedronType = "MultiPolyhedron"
+	prismType           = "Prism"
+	multiPrismType      = "MultiPrism"
+	circularStringType  = "CircularString"
+	compoundCurveType   = "CompoundCurve"
+	curvePolygonType    = "CurvePolygon"
+	multiCurveType      = "MultiCurve"
+	multiSurfaceType    = "MultiSurface"
+)
+
+// Members defined by JSON-FG. Those of features and feature collections are
+// recognized in addition to the RFC 7946 members in JSON-FG documents.
+var (
+	jsonfgFeatureMembers    = []string{"conformsTo", "featureType", "coordRefSys", "time", "place"}
+	jsonfgCollectionMembers = []string{"conformsTo", "featureType", "coordRefSys"}
+	prismMembers            = []string{"type", "bbox", "base", "lower", "upper"}
+	multiPrismMembers       = []string{"type", "bbox", "prisms"}
+	timeMembers             = []string{"date", "timestamp", "interval"}
+)
+
+// checkGeoJSON returns an error if g uses a JSON-FG geometry type, which may
+// not appear in the geometry member of a feature.
+func checkGeoJSON(g Geometry) error {
+	switch t := g.(type) {
+	case *GeometryCollection:
+		for _, child := range t.Geometries {
+			if err := checkGeoJSON(child); err != nil {
+				return err
+			}
+		}
+	case *Polyhedron, *MultiPolyhedron, *Prism, *MultiPrism, *CircularString,
+		*CompoundCurve, *CurvePolygon, *MultiCurve, *MultiSurface:
+		return fmt.Errorf("JSON-FG geometry type %T is only allowed in place", t)
+	}
+	return nil
+}
+
+// A CoordRefSys identifies the coordinate reference system of a JSON-FG
+// place. It is written as a plain URI unless it has an epoch or components.
+type CoordRefSys struct {
+	// Href is the URI or safe CURIE (such as "[EPSG:4326]") of the CRS.
+	Href string
+	// Epoch is the coordinate epoch of a dynamic CRS as a decimal year, or
+	// 0 if there is none.
+	Epoch float64
+	// Components of a compound CRS. If set, Href and Epoch are unused.
+	Components []CoordRefSys
+}
+
+type crsReference struct {
+	Type  string  `json:"type"`
+	Href  string  `json:"href"`
+	Epoch float64 `json:"epoch,omitempty"`
+}
+
+func (c *CoordRefSys) MarshalJSON() ([]byte, error) {
+	switch {
+	case c.Components != nil:
+		return json.Marshal(c.Components)
+	case c.Epoch != 0:
+		return json.Marshal(crsReference{Type: "Reference", Href: c.Href, Epoch: c.Epoch})
+	}
+	return json.Marshal(c.Href)
+}
+
+func (c *CoordRefSys) UnmarshalJSON(b []byte) error {
+	*c = CoordRefSys{}
+	b = []byte(strings.TrimSpace(string(b)))
+	if len(b) == 0 {
+		return fmt.Errorf("empty coordRefSys")
+	}
+	switch b[0] {
+	case '"':
+		return json.Unmarshal(b, &c.Href)
+	case '[':
+		var components []CoordRefSys
+		err := json.Unmarshal(b, &components)
+		if err != nil {
+			return err
+		}
+		c.Components = components
+		return nil
+	}
+	var ref crsReference
+	err := json.Unmarshal(b, &ref)
+	if err != nil {
+		return err
+	}
+	if ref.Type != "Reference" {
+		return fmt.Errorf("invalid coordRefSys type: %q", ref.Type)
+	}
+	c.Href = ref.Href
+	c.Epoch = ref.Epoch
+	return nil
+}
+
+// A Time is the temporal extent of a JSON-FG feature. Values are kept as
+// written: dates as "YYYY-MM-DD", timestamps as RFC 3339 date-times, and
+// unbounded interval ends as "..".
+type Time struct {
+	Date           string                 `json:"date,omitempty"`
+	Timestamp      string                 `json:"timestamp,omitempty"`
+	Interval       []string               `json:"interval,omitempty"`
+	ForeignMembers map[string]interface{} `json:"-"`
+}
+
+func (t *Time) MarshalJSON() ([]byte, error) {
+	type WireType Time
+	b, err := json.Marshal((*WireType)(t))
+	if err != nil {
+		return nil, err
+	}
+	return appendForeignMembers(b, t.ForeignMembers, timeMembers)
+}
+
+func (t *Time) UnmarshalJSON(b []byte) error {
+	type WireType Time
+	var w WireType
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	w.ForeignMembers, err = foreignMembers(b, timeMembers)
+	if err != nil {
+		return err
+	}
+	*t = Time(w)
+	return nil
+}
+
+// unmarshalFeatureType decodes a featureType member, which is either a
+// string or an array of strings.
+func unmarshalFeatureType(b json.RawMessage) ([]string, error) {
+	if len(b) == 0 || string(b) == "null" {
+		return nil, nil
+	}
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		return []string{s}, nil
+	}
+	var types []string
+	err := json.Unmarshal(b, &types)
+	if err != nil {
+		return nil, fmt.Errorf("invalid featureType: %v", err)
+	}
+	return types, nil
+}
+
+// featureTypeValue returns the JSON value of a featureType member.
+func featureTypeValue(types []string) interface{} {
+	if len(types) == 1 {
+		return types[0]
+	}
+	return types
+}
+
+// conformsTo decodes a conformsTo member, returning nil if it is not an
+// array of strings.
+func conformsTo(b json.RawMessage) []string {
+	var classes []string
+	if json.Unmarshal(b, &classes) != nil {
+		return nil
+	}
+	return classes
+}
+
+// jsonfgMembers returns the JSON-FG members of a feature to write. Features
+// in JSON-FG documents always have place and time members, which may be
+// null; other members are only written if set.
+func (f *Feature) jsonfgMembers(jsonfg bool) map[string]interface{} {
+	members := map[string]interface{}{}
+	if len(f.ConformsTo) > 0 {
+		members["conformsTo"] = f.ConformsTo
+	}
+	if len(f.FeatureType) > 0 {
+		members["featureType"] = featureTypeValue(f.FeatureType)
+	}
+	if f.CoordRefSys != nil {
+		members["coordRefSys"] = f.CoordRefSys
+	}
+	if jsonfg || f.Time != nil {
+		members["time"] = f.Time
+	}
+	if jsonfg || f.Place != nil {
+		members["place"] = f.Place
+	}
+	return members
+}
+
+func (f *FeatureCollection) jsonfgMembers() map[string]interface{} {
+	members := map[string]interface{}{}
+	if len(f.ConformsTo) > 0 {
+		members["conformsTo"] = f.ConformsTo
+	}
+	if len(f.FeatureType) > 0 {
+		members["featureType"] = featureTypeValue(f.FeatureType)
+	}
+	if f.CoordRefSys != nil {
+		members["coordRefSys"] = f.CoordRefSys
+	}
+	return members
+}
+
+// withMembers returns the standard members plus the keys of extra.
+func withMembers(members []string, extra map[string]interface{}) []string {
+	result := append([]string{}, members...)
+	for k := range extra {
+		result = append(result, k)
+	}
+	return result
+}
+
+type Polyhedron struct {
+	BBox           []float64              `json:"bbox,omitempty"`
+	Coordinates    [][][][][]float64      `json:"coordinates"`
+	ForeignMembers map[string]interface{} `json:"-"`
+}
+
+func (p *Polyhedron) isObject() {}
+
+func (p *Polyhedron) isGeometry() {}
+
+func (p *Polyhedron) String() string {
+	return fmt.Sprintf("%v", *p)
+}
+
+func (p *Polyhedron) GoString() string {
+	return fmt.Sprintf("%#v", *p)
+}
+
+type MultiPolyhedron struct {
+	BBox           []float64              `json:"bbox,omitempty"`
+	Coordinates    [][][][][][]float64    `json:"coordinates"`
+	ForeignMembers map[string]interface{} `json:"-"`
+}
+
+func (m *MultiPolyhedron) isObject() {}
+
+func (m *MultiPolyhedron) isGeometry() {}
+
+func (m *MultiPolyhedron) String() string {
+	return fmt.Sprintf("%v", *m)
+}
+
+func (m *MultiPolyhedron) GoString() string {
+	return fmt.Sprintf("%#v", *m)
+}
+
+// A Prism extrudes its base geometry vertically from Lower to Upper.
+type Prism struct {
+	BBox           []float64              `json:"bbox,omitempty"`
+	Base           Geometry               `json:"base"`
+	Lower          float64                `json:"lower,omitempty"`
+	Upper          float64                `json:"upper"`
+	ForeignMembers map[string]interface{} `json:"-"`
+}
+
+func (p *Prism) isObject() {}
+
+func (p *Prism) isGeometry() {}
+
+func (p *Prism) String() string {
+	return fmt.Sprintf("%v", *p)
+}
+
+func (p *Prism) GoString() string {
+	return fmt.Sprintf("%#v", *p)
+}
+
+type MultiPrism struct {
+	BBox           []float64              `json:"bbox,omitempty"`
+	Prisms         []Prism                `json:"prisms"`
+	ForeignMembers map[string]interface{} `json:"-"`
+}
+
+func (m *MultiPrism) isObject() {}
+
+func (m *MultiPrism) isGeometry() {}
+
+func (m *MultiPrism) String() string {
+	return fmt.Sprintf("%v", *m)
+}
+
+func (m *MultiPrism) GoString() string {
+	return fmt.Sprintf("%#v", *m)
+}
+
+// A CircularString is a sequence of circular arcs, each defined by three
+// positions of which the last is the first of the next arc.
+type CircularString struct {
+	BBox           []float64              `json:"bbox,omitempty"`
+	Coordinates    [][]float64            `json:"coordinates"`
+	ForeignMembers map[string]interface{} `json:"-"`
+}
+
+func (c *CircularString) isObject() {}
+
+func (c *CircularString) isGeometry() {}
+
+func (c *CircularString) String() string {
+	return fmt.Sprintf("%v", *c)
+}
+
+func (c *CircularString) GoString() string {
+	return fmt.Sprintf("%#v", *c)
+}
+
+// A CompoundCurve joins LineStrings and CircularStrings end to end.
+type CompoundCurve struct {
+	BBox           []float64              `json:"bbox,omitempty"`
+	Geometries     []Geometry             `json:"geometries"`
+	ForeignMembers map[string]interface{} `json:"-"`
+}
+
+func (c *CompoundCurve) isObject() {}
+
+func (c *CompoundCurve) isGeometry() {}
+
+func (c *CompoundCurve) String() string {
+	return fmt.Sprintf("%v", *c)
+}
+
+func (c *CompoundCurve) GoString() string {
+	return fmt.Sprintf("%#v", *c)
+}
+
+// A CurvePolygon is a polygon whose rings may be curves.
+type CurvePolygon struct {
+	BBox           []float64              `json:"bbox,omitempty"`
+	Geometries     []Geometry             `json:"geometries"`
+	ForeignMembers map[string]interface{} `json:"-"`
+}
+
+func (c *CurvePolygon) isObject() {}
+
+func (c *CurvePolygon) isGeometry() {}
+
+func (c *CurvePolygon) String() string {
+	return fmt.Sprintf("%v", *c)
+}
+
+func (c *CurvePolygon) GoString() string {
+	return fmt.Sprintf("%#v", *c)
+}
+
+type MultiCurve struct {
+	BBox           []float64              `json:"bbox,omitempty"`
+	Geometries     []Geometry             `json:"geometries"`
+	ForeignMembers map[string]interface{} `json:"-"`
+}
+
+func (m *MultiCurve) isObject() {}
+
+func (m *MultiCurve) isGeometry() {}
+
+func (m *MultiCurve) String() string {
+	return fmt.Sprintf("%v", *m)
+}
+
+func (m *MultiCurve) GoString() string {
+	return fmt.Sprintf("%#v", *m)
+}
+
+type MultiSurface struct {
+	BBox           []float64              `json:"bbox,omitempty"`
+	Geometries     []Geometry             `json:"geometries"`
+	ForeignMembers map[string]interface{} `json:"-"`
+}
+
+func (m *MultiSurface) isObject() {}
+
+func (m *MultiSurface) isGeometry() {}
+
+func (m *MultiSurface) String() string {
+	return fmt.Sprintf("%v", *m)
+}
+
+func (m *MultiSurface) GoString() string {
+	return fmt.Sprintf("%#v", *m)
+}
+
+// The JSON-FG geometry types marshal like their RFC 7946 counterparts.
+
+func (p *Polyhedron) MarshalJSON() ([]byte, error) {
+	type WireType Polyhedron
+	type t struct {
+		Type string `json:"type"`
+		*WireType
+	}
+	b, err := json.Marshal(t{Type: polyhedronType, WireType: (*WireType)(p)})
+	if err != nil {
+		return nil, err
+	}
+	return appendForeignMembers(b, p.ForeignMembers, coordinatesMembers)
+}
+
+func (m *MultiPolyhedron) MarshalJSON() ([]byte, error) {
+	type WireType MultiPolyhedron
+	type t struct {
+		Type string `json:"type"`
+		*WireType
+	}
+	b, err := json.Marshal(t{Type: multiPolyhedronType, WireType: (*WireType)(m)})
+	if err != nil {
+		return nil, err
+	}
+	return appendForeignMembers(b, m.ForeignMembers, coordinatesMembers)
+}
+
+func (p *Prism) MarshalJSON() ([]byte, error) {
+	type WireType Prism
+	type t struct {
+		Type string `json:"type"`
+		*WireType
+	}
+	b, err := json.Marshal(t{Type: prismType, WireType: (*WireType)(p)})
+	if err != nil {
+		return nil, err
+	}
+	return appendForeignMembers(b, p.ForeignMembers, prismMembers)
+}
+
+func (m *MultiPrism) MarshalJSON() ([]byte, error) {
+	type WireType MultiPrism
+	type t struct {
+		Type string `json:"type"`
+		*WireType
+	}
+	b, err := json.Marshal(t{Type: multiPrismType, WireType: (*WireType)(m)})
+	if err != nil {
+		return nil, err
+	}
+	return appendForeignMembers(b, m.ForeignMembers, multiPrismMembers)
+}
+
+func (c *CircularString) MarshalJSON() ([]byte, error) {
+	type WireType CircularString
+	type t struct {
+		Type string `json:"type"`
+		*WireType
+	}
+	b, err := json.Marshal(t{Type: circularStringType, WireType: (*WireType)(c)})
+	if err != nil {
+		return nil, err
+	}
+	return appendForeignMembers(b, c.ForeignMembers, coordinatesMembers)
+}
+
+func (c *CompoundCurve) MarshalJSON() ([]byte, error) {
+	type WireType CompoundCurve
+	type t struct {
+		Type string `json:"type"`
+		*WireType
+	}
+	b, err := json.Marshal(t{Type: compoundCurveType, WireType: (*WireType)(c)})
+	if err != nil {
+		return nil, err
+	}
+	return appendForeignMembers(b, c.ForeignMembers, geometryCollectionMembers)
+}
+
+func (c *CurvePolygon) MarshalJSON() ([]byte, error) {
+	type WireType CurvePolygon
+	type t struct {
+		Type string `json:"type"`
+		*WireType
+	}
+	b, err := json.Marshal(t{Type: curvePolygonType, WireType: (*WireType)(c)})
+	if err != nil {
+		return nil, err
+	}
+	return appendForeignMembers(b, c.ForeignMembers, geometryCollectionMembers)
+}
+
+func (m *MultiCurve) MarshalJSON() ([]byte, error) {
+	type WireType MultiCurve
+	type t struct {
+		Type string `json:"type"`
+		*WireType
+	}
+	b, err := json.Marshal(t{Type: multiCurveType, WireType: (*WireType)(m)})
+	if err != nil {
+		return nil, err
+	}
+	return appendForeignMembers(b, m.ForeignMembers, geometryCollectionMembers)
+}
+
+func (m *MultiSurface) MarshalJSON() ([]byte, error) {
+	type WireType MultiSurface
+	type t struct {
+		Type string `json:"type"`
+		*WireType
+	}
+	b, err := json.Marshal(t{Type: multiSurfaceType, WireType: (*WireType)(m)})
+	if err != nil {
+		return nil, err
+	}
+	return appendForeignMembers(b, m.ForeignMembers, geometryCollectionMembers)
+}
+
+func (p *Polyhedron) UnmarshalJSON(b []byte) error {
+	type WireType Polyhedron
+	var w WireType
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	w.ForeignMembers, err = foreignMembers(b, coordinatesMembers)
+	if err != nil {
+		return err
+	}
+	*p = Polyhedron(w)
+	return nil
+}
+
+func (m *MultiPolyhedron) UnmarshalJSON(b []byte) error {
+	type WireType MultiPolyhedron
+	var w WireType
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	w.ForeignMembers, err = foreignMembers(b, coordinatesMembers)
+	if err != nil {
+		return err
+	}
+	*m = MultiPolyhedron(w)
+	return nil
+}
+
+func (p *Prism) UnmarshalJSON(b []byte) error {
+	type WireType struct {
+		BBox  []float64       `json:"bbox"`
+		Base  json.RawMessage `json:"base"`
+		Lower float64         `json:"lower"`
+		Upper *float64        `json:"upper"`
+	}
+	var w WireType
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	if w.Upper == nil {
+		return fmt.Errorf("Prism has no upper limit")
+	}
+	base, err := unmarshalGeometry(w.Base)
+	if err != nil {
+		return err
+	}
+	if base == nil {
+		return fmt.Errorf("Prism has no base")
+	}
+	foreign, err := foreignMembers(b, prismMembers)
+	if err != nil {
+		return err
+	}
+
+	p.BBox = w.BBox
+	p.Base = base
+	p.Lower = w.Lower
+	p.Upper = *w.Upper
+	p.ForeignMembers = foreign
+	return nil
+}
+
+func (m *MultiPrism) UnmarshalJSON(b []byte) error {
+	type WireType MultiPrism
+	var w WireType
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	w.ForeignMembers, err = foreignMembers(b, multiPrismMembers)
+	if err != nil {
+		return err
+	}
+	*m = MultiPrism(w)
+	return nil
+}
+
+func (c *CircularString) UnmarshalJSON(b []byte) error {
+	type WireType CircularString
+	var w WireType
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	w.ForeignMembers, err = foreignMembers(b, coordinatesMembers)
+	if err != nil {
+		return err
+	}
+	*c = CircularString(w)
+	return nil
+}
+
+// unmarshalGeometries decodes the geometries member shared by
+// GeometryCollections and the JSON-FG curve and surface types.
+func unmarshalGeometries(b []byte) ([]Geometry, []float64, map[string]interface{}, error) {
+	type WireType struct {
+		BBox       []float64         `json:"bbox"`
+		Geometries []json.RawMessage `json:"geometries"`
+	}
+	var w WireType
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	geometries := make([]Geometry, len(w.Geometries))
+	for i, raw := range w.Geometries {
+		g, err := unmarshalGeometry(raw)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if g == nil {
+			return nil, nil, nil, fmt.Errorf("null geometry at index %d", i)
+		}
+		geometries[i] = g
+	}
+	foreign, err := foreignMembers(b, geometryCollectionMembers)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return geometries, w.BBox, foreign, nil
+}
+
+func (c *CompoundCurve) UnmarshalJSON(b []byte) error {
+	geometries, bbox, foreign, err := unmarshalGeometries(b)
+	if err != nil {
+		return err
+	}
+	*c = CompoundCurve{BBox: bbox, Geometries: geometries, ForeignMembers: foreign}
+	return nil
+}
+
+func (c *CurvePolygon) UnmarshalJSON(b []byte) error {
+	geometries, bbox, foreign, err := unmarshalGeometries(b)
+	if err != nil {
+		return err
+	}
+	*c = CurvePolygon{BBox: bbox, Geometries: geometries, ForeignMembers: foreign}
+	return nil
+}
+
+func (m *MultiCurve) UnmarshalJSON(b []byte) error {
+	geometries, bbox, foreign, err := unmarshalGeometries(b)
+	if err != nil {
+		return err
+	}
+	*m = MultiCurve{BBox: bbox, Geometries: geometries, ForeignMembers: foreign}
+	return nil
+}
+
+func (m *MultiSurface) UnmarshalJSON(b []byte) error {
+	geometries, bbox, foreign, err := unmarshalGeometries(b)
+	if err != nil {
+		return err
+	}
+	*m = MultiSurface{BBox: bbox, Geometries: geometries, ForeignMembers: foreign}
+	return nil
+}
@@ -0,0 +1,242 @@
+package wire
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalJSON_JSONFG(t *testing.T) {
+	cases := []struct {
+		s        string
+		expected Object
+	}{
+		{
+			s: `{
+  "type": "FeatureCollection",
+  "conformsTo": ["http://www.opengis.net/spec/json-fg-1/0.2/conf/core"],
+  "featureType": "Building",
+  "coordRefSys": "http://www.opengis.net/def/crs/EPSG/0/5555",
+  "features": [
+    {
+      "type": "Feature",
+      "id": "b1",
+      "time": {"interval": ["2014-04-24", ".."]},
+      "place": {
+        "type": "Polyhedron",
+        "coordinates": [[[[[0,0,0],[1,0,0],[1,1,0],[0,0,0]]], [[[0,0,0],[1,1,0],[0,0,1],[0,0,0]]]]]
+      },
+      "geometry": {"type": "Point", "coordinates": [7, 51]},
+      "properties": null
+    },
+    {
+      "type": "Feature",
+      "featureType": ["Building", "Landmark"],
+      "coordRefSys": {"type": "Reference", "href": "[EPSG:4326]", "epoch": 2017.23},
+      "time": null,
+      "place": null,
+      "geometry": null,
+      "properties": {}
+    }
+  ]
+}`,
+			expected: &FeatureCollection{
+				ConformsTo:  []string{JSONFGCore},
+				FeatureType: []string{"Building"},
+				CoordRefSys: &CoordRefSys{Href: "http://www.opengis.net/def/crs/EPSG/0/5555"},
+				Features: []Feature{
+					{
+						ID:   "b1",
+						Time: &Time{Interval: []string{"2014-04-24", ".."}},
+						Place: &Polyhedron{
+							Coordinates: [][][][][]float64{{
+								{{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 0, 0}}},
+								{{{0, 0, 0}, {1, 1, 0}, {0, 0, 1}, {0, 0, 0}}},
+							}},
+						},
+						Geometry: &Point{Coordinates: []float64{7, 51}},
+					},
+					{
+						FeatureType: []string{"Building", "Landmark"},
+						CoordRefSys: &CoordRefSys{Href: "[EPSG:4326]", Epoch: 2017.23},
+						Properties:  map[string]interface{}{},
+					},
+				},
+			},
+		},
+		{
+			// Without a JSON-FG conformance class, JSON-FG members are
+			// foreign members.
+			s: `{"type":"Feature","time":"noon","place":"here","conformsTo":["x"],"geometry":null,"properties":null}`,
+			expected: &Feature{
+				ForeignMembers: map[string]interface{}{
+					"time":       "noon",
+					"place":      "here",
+					"conformsTo": []interface{}{"x"},
+				},
+			},
+		},
+		{
+			s: `{
+  "type": "Feature",
+  "conformsTo": ["[ogc-json-fg-1-0.2:core]"],
+  "time": {"date": "2020-05-01", "timestamp": "2020-05-01T10:00:00Z", "note": "approx"},
+  "coordRefSys": ["http://www.opengis.net/def/crs/EPSG/0/25832", "http://www.opengis.net/def/crs/EPSG/0/7837"],
+  "place": {
+    "type": "Prism",
+    "base": {"type": "CurvePolygon", "geometries": [
+      {"type": "CompoundCurve", "geometries": [
+        {"type": "CircularString", "coordinates": [[0,0],[1,1],[2,0]]},
+        {"type": "LineString", "coordinates": [[2,0],[0,0]]}
+      ]}
+    ]},
+    "upper": 10
+  },
+  "geometry": {"type": "Point", "coordinates": [1, 0.5]},
+  "properties": null
+}`,
+			expected: &Feature{
+				ConformsTo: []string{"[ogc-json-fg-1-0.2:core]"},
+				Time: &Time{
+					Date:           "2020-05-01",
+					Timestamp:      "2020-05-01T10:00:00Z",
+					ForeignMembers: map[string]interface{}{"note": "approx"},
+				},
+				CoordRefSys: &CoordRefSys{Components: []CoordRefSys{
+					{Href: "http://www.opengis.net/def/crs/EPSG/0/25832"},
+					{Href: "http://www.opengis.net/def/crs/EPSG/0/7837"},
+				}},
+				Place: &Prism{
+					Base: &CurvePolygon{Geometries: []Geometry{
+						&CompoundCurve{Geometries: []Geometry{
+							&CircularString{Coordinates: [][]float64{{0, 0}, {1, 1}, {2, 0}}},
+							&LineString{Coordinates: [][]float64{{2, 0}, {0, 0}}},
+						}},
+					}},
+					Upper: 10,
+				},
+				Geometry: &Point{Coordinates: []float64{1, 0.5}},
+			},
+		},
+	}
+	for i, c := range cases {
+		var obj Wrapper
+		err := json.Unmarshal([]byte(c.s), &obj)
+		if err != nil {
+			t.Errorf("error unmarshaling case %d: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(obj.Value, c.expected) {
+			t.Errorf("for case %d expected %#v, got %#v", i, c.expected, obj.Value)
+		}
+	}
+}
+
+func TestUnmarshalJSON_JSONFGInvalid(t *testing.T) {
+	cases := []string{
+		// JSON-FG geometry types are not allowed in geometry.
+		`{"type":"Feature","geometry":{"type":"CircularString","coordinates":[[0,0],[1,1],[2,0]]},"properties":null}`,
+		`{"type":"Feature","geometry":{"type":"GeometryCollection","geometries":[{"type":"MultiCurve","geometries":[]}]},"properties":null}`,
+		`{"type":"Feature","conformsTo":["` + JSONFGCore + `"],"time":"noon","geometry":null,"properties":null}`,
+		`{"type":"Feature","conformsTo":["` + JSONFGCore + `"],"featureType":3,"geometry":null,"properties":null}`,
+		`{"type":"Feature","conformsTo":["` + JSONFGCore + `"],"coordRefSys":{"type":"Name"},"geometry":null,"properties":null}`,
+		`{"type":"Prism","base":{"type":"Point","coordinates":[0,0]}}`,
+		`{"type":"Prism","upper":1}`,
+	}
+	for i, c := range cases {
+		var obj Wrapper
+		if err := json.Unmarshal([]byte(c), &obj); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestRoundTrip_MarshalJSON_JSONFG(t *testing.T) {
+	cases := []Object{
+		&MultiPolyhedron{
+			Coordinates: [][][][][][]float64{{{{{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 0}}}}}},
+		},
+		&MultiPrism{
+			Prisms: []Prism{
+				{Base: &Point{Coordinates: []float64{0, 0}}, Lower: 2, Upper: 3},
+			},
+		},
+		&MultiCurve{
+			Geometries: []Geometry{&CircularString{Coordinates: [][]float64{{0, 0}, {1, 1}, {2, 0}}}},
+		},
+		&MultiSurface{
+			Geometries: []Geometry{&Polygon{Coordinates: [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}},
+			BBox:       []float64{0, 0, 1, 1},
+		},
+		&FeatureCollection{
+			ConformsTo:  []string{JSONFGCore},
+			FeatureType: []string{"a", "b"},
+			Features: []Feature{
+				{
+					Time:           &Time{Timestamp: "2020-01-01T00:00:00Z"},
+					CoordRefSys:    &CoordRefSys{Href: "[EPSG:3857]", Epoch: 2020.5},
+					Place:          &Point{Coordinates: []float64{1, 2}},
+					Geometry:       &Point{Coordinates: []float64{3, 4}},
+					ForeignMembers: map[string]interface{}{"extra": true},
+				},
+			},
+		},
+	}
+	for i, c := range cases {
+		b, err := json.Marshal(c)
+		if err != nil {
+			t.Errorf("failed to serialize case %d (%T): %v", i, c, err)
+			continue
+		}
+		var obj Wrapper
+		err = json.Unmarshal(b, &obj)
+		if err != nil {
+			t.Errorf("failed to deserialize case %d (%T): %v", i, c, err)
+			continue
+		}
+		if !reflect.DeepEqual(c, obj.Value) {
+			t.Errorf("round trip %d (%T) failed: expected %#v, got %#v", i, c, c, obj.Value)
+		}
+	}
+}
+
+func TestMarshalJSON_JSONFG(t *testing.T) {
+	fc := &FeatureCollection{
+		ConformsTo: []string{JSONFGCore},
+		Features: []Feature{
+			{ForeignMembers: map[string]interface{}{"place": "shadowed", "name": "kept"}},
+		},
+	}
+	b, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatalf("failed to serialize: %v", err)
+	}
+	var m map[string]interface{}
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		t.Fatalf("failed to deserialize %s: %v", b, err)
+	}
+	// Features of JSON-FG documents always have place and time members.
+	expected := map[string]interface{}{
+		"type":       "FeatureCollection",
+		"conformsTo": []interface{}{JSONFGCore},
+		"features": []interface{}{
+			map[string]interface{}{
+				"type":       "Feature",
+				"geometry":   nil,
+				"properties": nil,
+				"place":      nil,
+				"time":       nil,
+				"name":       "kept",
+			},
+		},
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %#v, got %#v", expected, m)
+	}
+
+	f := &Feature{Geometry: &CircularString{Coordinates: [][]float64{{0, 0}, {1, 1}, {2, 0}}}}
+	if _, err := json.Marshal(f); err == nil {
+		t.Errorf("expected error marshaling a JSON-FG geometry type in geometry")
+	}
+}
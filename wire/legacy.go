@@ -0,0 +1,311 @@
+package wire
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var _ json.Marshaler = (*CRS)(nil)
+var _ json.Unmarshaler = (*CRS)(nil)
+
+// Kinds of GeoJSON 2008 CRS objects.
+const (
+	CRSName = "name"
+	CRSLink = "link"
+)
+
+// crsMember is the GeoJSON 2008 member that declares a coordinate reference
+// system. RFC 7946 removed it in favor of always using CRS84.
+const crsMember = "crs"
+
+// crs84Names are the names that GeoJSON 2008 documents commonly use for
+// WGS84 longitude/latitude coordinates.
+var crs84Names = []string{
+	"urn:ogc:def:crs:ogc:1.3:crs84",
+	"urn:ogc:def:crs:ogc::crs84",
+	"http://www.opengis.net/def/crs/ogc/1.3/crs84",
+	"https://www.opengis.net/def/crs/ogc/1.3/crs84",
+	"ogc:crs84",
+	"crs84",
+}
+
+// A CRS is a coordinate reference system declared by the "crs" member of a
+// GeoJSON 2008 object. A CRS with an empty Type represents an explicit null
+// "crs", which means that the reference system is unknown.
+type CRS struct {
+	// Either CRSName or CRSLink.
+	Type string
+	// Name of a named CRS, such as "urn:ogc:def:crs:EPSG::3857".
+	Name string
+	// URI and optional format (such as "proj4" or "ogcwkt") of a linked CRS.
+	Href     string
+	LinkType string
+}
+
+// EPSG returns the EPSG code of c, which is recognized in names and links
+// of the forms "EPSG:3857", "urn:ogc:def:crs:EPSG::3857",
+// "http://www.opengis.net/def/crs/EPSG/0/3857", and
+// "http://spatialreference.org/ref/epsg/3857/". It returns false if c does
+// not refer to an EPSG code.
+func (c *CRS) EPSG() (int, bool) {
+	s := strings.ToLower(c.Name)
+	if c.Type == CRSLink {
+		s = strings.ToLower(c.Href)
+	}
+	var parts []string
+	if strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") {
+		for _, part := range strings.Split(s, "/") {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+	} else {
+		parts = strings.Split(s, ":")
+	}
+	for i, part := range parts {
+		if part != "epsg" {
+			continue
+		}
+		rest := parts[i+1:]
+		// Skip the empty or numeric version in URNs and OGC URLs, such as
+		// the "6.6" in "urn:ogc:def:crs:EPSG:6.6:4326".
+		if len(rest) >= 2 && isCode(rest[1]) {
+			rest = rest[1:]
+		}
+		if len(rest) > 0 && isCode(rest[0]) {
+			code, err := strconv.Atoi(rest[0])
+			return code, err == nil
+		}
+		return 0, false
+	}
+	return 0, false
+}
+
+func isCode(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsCRS84 reports whether c refers to WGS84 longitude/latitude coordinates,
+// which are the only coordinates allowed by RFC 7946. EPSG:4326 is accepted
+// as well, since GeoJSON 2008 documents conventionally use it with
+// longitude-first coordinates.
+func (c *CRS) IsCRS84() bool {
+	if code, ok := c.EPSG(); ok {
+		return code == 4326
+	}
+	name := strings.ToLower(c.Name)
+	if c.Type == CRSLink {
+		name = strings.ToLower(c.Href)
+	}
+	for _, n := range crs84Names {
+		if name == n {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CRS) String() string {
+	switch c.Type {
+	case CRSName:
+		return c.Name
+	case CRSLink:
+		return c.Href
+	}
+	return "unknown CRS"
+}
+
+func (c *CRS) MarshalJSON() ([]byte, error) {
+	type properties struct {
+		Name string `json:"name,omitempty"`
+		Href string `json:"href,omitempty"`
+		Type string `json:"type,omitempty"`
+	}
+	type t struct {
+		Type       string     `json:"type"`
+		Properties properties `json:"properties"`
+	}
+	switch c.Type {
+	case "":
+		return []byte("null"), nil
+	case CRSName:
+		return json.Marshal(t{Type: c.Type, Properties: properties{Name: c.Name}})
+	case CRSLink:
+		return json.Marshal(t{Type: c.Type, Properties: properties{Href: c.Href, Type: c.LinkType}})
+	}
+	return nil, fmt.Errorf("invalid crs type: %q", c.Type)
+}
+
+func (c *CRS) UnmarshalJSON(b []byte) error {
+	*c = CRS{}
+	if string(b) == "null" {
+		return nil
+	}
+	var w struct {
+		Type       string `json:"type"`
+		Properties struct {
+			Name string `json:"name"`
+			Href string `json:"href"`
+			Type string `json:"type"`
+		} `json:"properties"`
+	}
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return fmt.Errorf("invalid crs: %v", err)
+	}
+	switch w.Type {
+	case CRSName:
+		if w.Properties.Name == "" {
+			return fmt.Errorf("invalid crs: named crs requires a name")
+		}
+		c.Name = w.Properties.Name
+	case CRSLink:
+		if w.Properties.Href == "" {
+			return fmt.Errorf("invalid crs: linked crs requires an href")
+		}
+		c.Href = w.Properties.Href
+		c.LinkType = w.Properties.Type
+	default:
+		return fmt.Errorf("invalid crs type: %q", w.Type)
+	}
+	c.Type = w.Type
+	return nil
+}
+
+// appendLegacyMembers adds the crs member, if any, followed by the foreign
+// members to the JSON object b. A foreign "crs" member is dropped in favor of
+// crs.
+func appendLegacyMembers(b []byte, crs *CRS, foreign map[string]interface{}, members []string) ([]byte, error) {
+	if crs != nil {
+		legacy := map[string]interface{}{crsMember: crs}
+		var err error
+		b, err = appendForeignMembers(b, legacy, members)
+		if err != nil {
+			return nil, err
+		}
+		members = withMembers(members, legacy)
+	}
+	return appendForeignMembers(b, foreign, members)
+}
+
+// LegacyOptions controls how UnmarshalLegacy treats declared reference
+// systems. The zero value accepts any reference system.
+type LegacyOptions struct {
+	// Warn is called for each object that declares a CRS other than CRS84,
+	// if it is set.
+	Warn func(crs *CRS)
+	// Whether a CRS other than CRS84 is an error. Coordinates in other
+	// reference systems are not valid RFC 7946 coordinates.
+	RequireCRS84 bool
+}
+
+// UnmarshalLegacy decodes a GeoJSON object that may use the "crs" member of
+// GeoJSON 2008. Unlike json.Unmarshal, which keeps "crs" as a foreign member,
+// it parses "crs" into the CRS field of each object that declares one. opts
+// may be nil.
+func UnmarshalLegacy(b []byte, opts *LegacyOptions) (Object, error) {
+	if opts == nil {
+		opts = &LegacyOptions{}
+	}
+	var w Wrapper
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return nil, err
+	}
+	err = takeCRS(w.Value, opts)
+	if err != nil {
+		return nil, err
+	}
+	return w.Value, nil
+}
+
+// takeCRS moves the "crs" foreign member of obj and its children into
+// their CRS fields.
+func takeCRS(obj Object, opts *LegacyOptions) error {
+	var foreign *map[string]interface{}
+	var crs **CRS
+	var children []Object
+	switch t := obj.(type) {
+	case *FeatureCollection:
+		foreign, crs = &t.ForeignMembers, &t.CRS
+		for i := range t.Features {
+			children = append(children, &t.Features[i])
+		}
+	case *Feature:
+		foreign, crs = &t.ForeignMembers, &t.CRS
+		if t.Geometry != nil {
+			children = append(children, t.Geometry.(Object))
+		}
+	case *GeometryCollection:
+		foreign, crs = &t.ForeignMembers, &t.CRS
+		for _, g := range t.Geometries {
+			children = append(children, g.(Object))
+		}
+	case *MultiPolygon:
+		foreign, crs = &t.ForeignMembers, &t.CRS
+	case *Polygon:
+		foreign, crs = &t.ForeignMembers, &t.CRS
+	case *MultiLineString:
+		foreign, crs = &t.ForeignMembers, &t.CRS
+	case *LineString:
+		foreign, crs = &t.ForeignMembers, &t.CRS
+	case *MultiPoint:
+		foreign, crs = &t.ForeignMembers, &t.CRS
+	case *Point:
+		foreign, crs = &t.ForeignMembers, &t.CRS
+	default:
+		// JSON-FG geometries use coordRefSys instead.
+		return nil
+	}
+	if v, ok := (*foreign)[crsMember]; ok {
+		c, err := parseCRS(v, opts)
+		if err != nil {
+			return err
+		}
+		delete(*foreign, crsMember)
+		if len(*foreign) == 0 {
+			*foreign = nil
+		}
+		*crs = c
+	}
+	for _, child := range children {
+		err := takeCRS(child, opts)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseCRS decodes the value of a "crs" foreign member and checks it
+// against opts.
+func parseCRS(v interface{}, opts *LegacyOptions) (*CRS, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	c := &CRS{}
+	err = json.Unmarshal(b, c)
+	if err != nil {
+		return nil, err
+	}
+	if !c.IsCRS84() {
+		if opts.Warn != nil {
+			opts.Warn(c)
+		}
+		if opts.RequireCRS84 {
+			return nil, fmt.Errorf("coordinates are not in CRS84: %v", c)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,123 @@
+package wire
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalLegacy(t *testing.T) {
+	s := `{
+  "type": "FeatureCollection",
+  "crs": {"type": "name", "properties": {"name": "urn:ogc:def:crs:EPSG::3857"}},
+  "note": "kept",
+  "features": [
+    {
+      "type": "Feature",
+      "crs": {"type": "link", "properties": {"href": "http://spatialreference.org/ref/epsg/27700/proj4/", "type": "proj4"}},
+      "geometry": {"type": "Point", "coordinates": [1, 2], "crs": null},
+      "properties": null
+    }
+  ]
+}`
+	var warnings []string
+	opts := &LegacyOptions{Warn: func(crs *CRS) { warnings = append(warnings, crs.String()) }}
+	obj, err := UnmarshalLegacy([]byte(s), opts)
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	expected := &FeatureCollection{
+		CRS:            &CRS{Type: CRSName, Name: "urn:ogc:def:crs:EPSG::3857"},
+		ForeignMembers: map[string]interface{}{"note": "kept"},
+		Features: []Feature{
+			{
+				CRS: &CRS{Type: CRSLink, Href: "http://spatialreference.org/ref/epsg/27700/proj4/", LinkType: "proj4"},
+				Geometry: &Point{
+					Coordinates: []float64{1, 2},
+					CRS:         &CRS{},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(obj, expected) {
+		t.Errorf("expected %#v, got %#v", expected, obj)
+	}
+	expectedWarnings := []string{"urn:ogc:def:crs:EPSG::3857", "http://spatialreference.org/ref/epsg/27700/proj4/", "unknown CRS"}
+	if !reflect.DeepEqual(warnings, expectedWarnings) {
+		t.Errorf("expected warnings %v, got %v", expectedWarnings, warnings)
+	}
+
+	// The crs member survives a round trip.
+	b, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	again, err := UnmarshalLegacy(b, nil)
+	if err != nil {
+		t.Fatalf("failed to unmarshal %s: %v", b, err)
+	}
+	if !reflect.DeepEqual(again, expected) {
+		t.Errorf("round trip failed: expected %#v, got %#v", expected, again)
+	}
+
+	// Without legacy mode, crs is a foreign member.
+	var w Wrapper
+	err = json.Unmarshal([]byte(s), &w)
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	fc := w.Value.(*FeatureCollection)
+	if fc.CRS != nil || fc.ForeignMembers["crs"] == nil {
+		t.Errorf("expected crs to be a foreign member, got %#v", fc)
+	}
+}
+
+func TestUnmarshalLegacy_RequireCRS84(t *testing.T) {
+	opts := &LegacyOptions{RequireCRS84: true}
+	accepted := []string{
+		`{"type":"Point","coordinates":[1,2]}`,
+		`{"type":"Point","coordinates":[1,2],"crs":{"type":"name","properties":{"name":"urn:ogc:def:crs:OGC:1.3:CRS84"}}}`,
+		`{"type":"Point","coordinates":[1,2],"crs":{"type":"name","properties":{"name":"EPSG:4326"}}}`,
+	}
+	for i, s := range accepted {
+		if _, err := UnmarshalLegacy([]byte(s), opts); err != nil {
+			t.Errorf("case %d: unexpected error: %v", i, err)
+		}
+	}
+	rejected := []string{
+		`{"type":"Point","coordinates":[1,2],"crs":null}`,
+		`{"type":"Point","coordinates":[1,2],"crs":{"type":"name","properties":{"name":"EPSG:3857"}}}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2],"crs":{"type":"name","properties":{"name":"EPSG:2263"}}}]}`,
+		// Malformed crs members are rejected even without RequireCRS84.
+		`{"type":"Point","coordinates":[1,2],"crs":{"type":"EPSG","properties":{"code":3857}}}`,
+		`{"type":"Point","coordinates":[1,2],"crs":{"type":"name","properties":{}}}`,
+	}
+	for i, s := range rejected {
+		if _, err := UnmarshalLegacy([]byte(s), opts); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestCRS_EPSG(t *testing.T) {
+	cases := []struct {
+		crs      CRS
+		code     int
+		expected bool
+	}{
+		{CRS{Type: CRSName, Name: "EPSG:3857"}, 3857, true},
+		{CRS{Type: CRSName, Name: "urn:ogc:def:crs:EPSG::27700"}, 27700, true},
+		{CRS{Type: CRSName, Name: "urn:ogc:def:crs:EPSG:6.6:4326"}, 4326, true},
+		{CRS{Type: CRSName, Name: "http://www.opengis.net/def/crs/EPSG/0/2056"}, 2056, true},
+		{CRS{Type: CRSLink, Href: "http://spatialreference.org/ref/epsg/32633/ogcwkt/"}, 32633, true},
+		{CRS{Type: CRSName, Name: "urn:ogc:def:crs:OGC:1.3:CRS84"}, 0, false},
+		{CRS{Type: CRSName, Name: "EPSG:abc"}, 0, false},
+		{CRS{}, 0, false},
+	}
+	for i, c := range cases {
+		code, ok := c.crs.EPSG()
+		if code != c.code || ok != c.expected {
+			t.Errorf("case %d: expected (%d, %v), got (%d, %v)", i, c.code, c.expected, code, ok)
+		}
+	}
+}
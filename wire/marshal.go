@@ -13,19 +13,54 @@ var _ json.Marshaler = (*MultiPoint)(nil)
 var _ json.Marshaler = (*Point)(nil)
 
 func (f *FeatureCollection) MarshalJSON() ([]byte, error) {
+	// Features are marshaled here so that they know whether they are part
+	// of a JSON-FG document.
+	jsonfg := isJSONFG(f.ConformsTo)
+	var features []json.RawMessage
+	if f.Features != nil {
+		features = make([]json.RawMessage, len(f.Features))
+	}
+	for i := range f.Features {
+		b, err := f.Features[i].marshal(jsonfg)
+		if err != nil {
+			return nil, err
+		}
+		features[i] = b
+	}
 	type WireType FeatureCollection
 	type t struct {
 		Type string `json:"type"`
 		*WireType
+		Features []json.RawMessage `json:"features"`
 	}
 	v := t{
 		Type:     featureCollectionType,
 		WireType: (*WireType)(f),
+		Features: features,
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
 	}
-	return json.Marshal(v)
+	fg := f.jsonfgMembers()
+	b, err = appendForeignMembers(b, fg, featureCollectionMembers)
+	if err != nil {
+		return nil, err
+	}
+	return appendLegacyMembers(b, f.CRS, f.ForeignMembers, withMembers(featureCollectionMembers, fg))
 }
 
 func (f *Feature) MarshalJSON() ([]byte, error) {
+	return f.marshal(false)
+}
+
+// marshal encodes f, writing the members required by JSON-FG if jsonfg is
+// set or f declares conformance itself.
+func (f *Feature) marshal(jsonfg bool) ([]byte, error) {
+	err := checkGeoJSON(f.Geometry)
+	if err != nil {
+		return nil, err
+	}
 	type WireType Feature
 	type t struct {
 		Type string `json:"type"`
@@ -35,7 +70,16 @@ func (f *Feature) MarshalJSON() ([]byte, error) {
 		Type:     featureType,
 		WireType: (*WireType)(f),
 	}
-	return json.Marshal(v)
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fg := f.jsonfgMembers(jsonfg || isJSONFG(f.ConformsTo))
+	b, err = appendForeignMembers(b, fg, featureMembers)
+	if err != nil {
+		return nil, err
+	}
+	return appendLegacyMembers(b, f.CRS, f.ForeignMembers, withMembers(featureMembers, fg))
 }
 
 // All geometry types use the same logic for marshaling. Unfortunately, without
@@ -51,7 +95,11 @@ func (g *GeometryCollection) MarshalJSON() ([]byte, error) {
 		Type:     geometryCollectionType,
 		WireType: (*WireType)(g),
 	}
-	return json.Marshal(v)
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return appendLegacyMembers(b, g.CRS, g.ForeignMembers, geometryCollectionMembers)
 }
 
 func (m *MultiPolygon) MarshalJSON() ([]byte, error) {
@@ -64,7 +112,11 @@ func (m *MultiPolygon) MarshalJSON() ([]byte, error) {
 		Type:     multiPolygonType,
 		WireType: (*WireType)(m),
 	}
-	return json.Marshal(v)
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return appendLegacyMembers(b, m.CRS, m.ForeignMembers, coordinatesMembers)
 }
 
 func (p *Polygon) MarshalJSON() ([]byte, error) {
@@ -77,7 +129,11 @@ func (p *Polygon) MarshalJSON() ([]byte, error) {
 		Type:     polygonType,
 		WireType: (*WireType)(p),
 	}
-	return json.Marshal(v)
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return appendLegacyMembers(b, p.CRS, p.ForeignMembers, coordinatesMembers)
 }
 
 func (m *MultiLineString) MarshalJSON() ([]byte, error) {
@@ -90,7 +146,11 @@ func (m *MultiLineString) MarshalJSON() ([]byte, error) {
 		Type:     multiLineStringType,
 		WireType: (*WireType)(m),
 	}
-	return json.Marshal(v)
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return appendLegacyMembers(b, m.CRS, m.ForeignMembers, coordinatesMembers)
 }
 
 func (ls *LineString) MarshalJSON() ([]byte, error) {
@@ -103,7 +163,11 @@ func (ls *LineString) MarshalJSON() ([]byte, error) {
 		Type:     lineStringType,
 		WireType: (*WireType)(ls),
 	}
-	return json.Marshal(v)
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return appendLegacyMembers(b, ls.CRS, ls.ForeignMembers, coordinatesMembers)
 }
 
 func (m *MultiPoint) MarshalJSON() ([]byte, error) {
@@ -116,7 +180,11 @@ func (m *MultiPoint) MarshalJSON() ([]byte, error) {
 		Type:     multiPointType,
 		WireType: (*WireType)(m),
 	}
-	return json.Marshal(v)
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return appendLegacyMembers(b, m.CRS, m.ForeignMembers, coordinatesMembers)
 }
 
 func (p *Point) MarshalJSON() ([]byte, error) {
@@ -129,5 +197,9 @@ func (p *Point) MarshalJSON() ([]byte, error) {
 		Type:     pointType,
 		WireType: (*WireType)(p),
 	}
-	return json.Marshal(v)
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return appendLegacyMembers(b, p.CRS, p.ForeignMembers, coordinatesMembers)
 }
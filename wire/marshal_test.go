@@ -86,6 +86,14 @@ func TestRoundTrip_MarshalJSON(t *testing.T) {
 			Geometry:   &Point{Coordinates: []float64{4, 5}},
 			Properties: map[string]interface{}{"name": "My Point"},
 		},
+		&Feature{
+			Geometry: &Point{
+				Coordinates:    []float64{4, 5},
+				ForeignMembers: map[string]interface{}{"accuracy": float64(3)},
+			},
+			Properties:     map[string]interface{}{"name": "My Point"},
+			ForeignMembers: map[string]interface{}{"title": "Example", "links": []interface{}{"a"}},
+		},
 		&FeatureCollection{
 			Features: []Feature{
 				{
@@ -120,5 +128,33 @@ func TestRoundTrip_MarshalJSON(t *testing.T) {
 	}
 }
 
+func TestMarshalJSON_ForeignMembers(t *testing.T) {
+	p := &Point{
+		Coordinates: []float64{0, 1},
+		ForeignMembers: map[string]interface{}{
+			"type":        "Feature",
+			"coordinates": "none",
+			"name":        "origin",
+		},
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("failed to serialize: %v", err)
+	}
+	var m map[string]interface{}
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		t.Fatalf("failed to deserialize %s: %v", b, err)
+	}
+	expected := map[string]interface{}{
+		"type":        "Point",
+		"coordinates": []interface{}{float64(0), float64(1)},
+		"name":        "origin",
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %#v, got %#v", expected, m)
+	}
+}
+
 // TODO: Marshal then unmarshal into map[string]interface{} to verify that
 // omitted fields are not serialized.
@@ -27,6 +27,15 @@ var typeNames = map[string]func() Object{
 	lineStringType:         func() Object { return &LineString{} },
 	multiPointType:         func() Object { return &MultiPoint{} },
 	pointType:              func() Object { return &Point{} },
+	polyhedronType:         func() Object { return &Polyhedron{} },
+	multiPolyhedronType:    func() Object { return &MultiPolyhedron{} },
+	prismType:              func() Object { return &Prism{} },
+	multiPrismType:         func() Object { return &MultiPrism{} },
+	circularStringType:     func() Object { return &CircularString{} },
+	compoundCurveType:      func() Object { return &CompoundCurve{} },
+	curvePolygonType:       func() Object { return &CurvePolygon{} },
+	multiCurveType:         func() Object { return &MultiCurve{} },
+	multiSurfaceType:       func() Object { return &MultiSurface{} },
 }
 
 // A Wrapper holds a deserialized GeoJSON value. This special type allows for
@@ -43,12 +52,22 @@ type Wrapper struct {
 
 // An Object is any GeoJSON object type. This includes FeatureCollection,
 // Feature, and all Geometry types.
+//
+// Every type keeps the members that RFC 7946 does not define for it in its
+// ForeignMembers field, which is nil if there are none. Foreign members are
+// preserved when unmarshaling and marshaling, except for entries that would
+// shadow a standard member.
+//
+// The GeoJSON 2008 "crs" member is a foreign member unless the object is
+// decoded with UnmarshalLegacy, which moves it into the CRS field.
 type Object interface {
 	isObject()
 }
 
 // A Geometry represents any GeoJSON geometry type: GeometryCollection,
 // MultiPolygon, Polygon, MultiLineString, LineString, MultiPoint, or Point.
+// The JSON-FG geometry types are also Geometries, but are only valid in the
+// Place of a Feature.
 type Geometry interface {
 	// Geometry types must implement json.Marshaler to guarantee their custom
 	// marshalers are used.
@@ -59,6 +78,13 @@ type Geometry interface {
 type FeatureCollection struct {
 	BBox     []float64 `json:"bbox,omitempty"`
 	Features []Feature `json:"features"`
+	// JSON-FG members. Declaring a JSON-FG conformance class in ConformsTo
+	// makes the collection and its features JSON-FG documents.
+	ConformsTo     []string               `json:"-"`
+	FeatureType    []string               `json:"-"`
+	CoordRefSys    *CoordRefSys           `json:"-"`
+	CRS            *CRS                   `json:"-"`
+	ForeignMembers map[string]interface{} `json:"-"`
 }
 
 func (f *FeatureCollection) isObject() {}
@@ -76,6 +102,16 @@ type Feature struct {
 	Geometry   Geometry               `json:"geometry"`
 	Properties map[string]interface{} `json:"properties"`
 	ID         string                 `json:"id,omitempty"`
+	// JSON-FG members. ConformsTo is only set on features that are not part
+	// of a FeatureCollection. Place may hold any geometry type, in the
+	// coordinate reference system given by CoordRefSys.
+	ConformsTo     []string               `json:"-"`
+	FeatureType    []string               `json:"-"`
+	CoordRefSys    *CoordRefSys           `json:"-"`
+	Time           *Time                  `json:"-"`
+	Place          Geometry               `json:"-"`
+	CRS            *CRS                   `json:"-"`
+	ForeignMembers map[string]interface{} `json:"-"`
 }
 
 func (f *Feature) isObject() {}
@@ -89,8 +125,10 @@ func (f *Feature) GoString() string {
 }
 
 type GeometryCollection struct {
-	BBox       []float64  `json:"bbox,omitempty"`
-	Geometries []Geometry `json:"geometries"`
+	BBox           []float64              `json:"bbox,omitempty"`
+	Geometries     []Geometry             `json:"geometries"`
+	CRS            *CRS                   `json:"-"`
+	ForeignMembers map[string]interface{} `json:"-"`
 }
 
 func (g *GeometryCollection) isObject() {}
@@ -106,8 +144,10 @@ func (g *GeometryCollection) GoString() string {
 }
 
 type MultiPolygon struct {
-	BBox        []float64       `json:"bbox,omitempty"`
-	Coordinates [][][][]float64 `json:"coordinates"`
+	BBox           []float64              `json:"bbox,omitempty"`
+	Coordinates    [][][][]float64        `json:"coordinates"`
+	CRS            *CRS                   `json:"-"`
+	ForeignMembers map[string]interface{} `json:"-"`
 }
 
 func (m *MultiPolygon) isObject() {}
@@ -123,8 +163,10 @@ func (m *MultiPolygon) GoString() string {
 }
 
 type Polygon struct {
-	BBox        []float64     `json:"bbox,omitempty"`
-	Coordinates [][][]float64 `json:"coordinates"`
+	BBox           []float64              `json:"bbox,omitempty"`
+	Coordinates    [][][]float64          `json:"coordinates"`
+	CRS            *CRS                   `json:"-"`
+	ForeignMembers map[string]interface{} `json:"-"`
 }
 
 func (p *Polygon) isObject() {}
@@ -140,8 +182,10 @@ func (p *Polygon) GoString() string {
 }
 
 type MultiLineString struct {
-	BBox        []float64     `json:"bbox,omitempty"`
-	Coordinates [][][]float64 `json:"coordinates"`
+	BBox           []float64              `json:"bbox,omitempty"`
+	Coordinates    [][][]float64          `json:"coordinates"`
+	CRS            *CRS                   `json:"-"`
+	ForeignMembers map[string]interface{} `json:"-"`
 }
 
 func (m *MultiLineString) isObject() {}
@@ -157,8 +201,10 @@ func (m *MultiLineString) GoString() string {
 }
 
 type LineString struct {
-	BBox        []float64   `json:"bbox,omitempty"`
-	Coordinates [][]float64 `json:"coordinates"`
+	BBox           []float64              `json:"bbox,omitempty"`
+	Coordinates    [][]float64            `json:"coordinates"`
+	CRS            *CRS                   `json:"-"`
+	ForeignMembers map[string]interface{} `json:"-"`
 }
 
 func (ls *LineString) isObject() {}
@@ -174,8 +220,10 @@ func (ls *LineString) GoString() string {
 }
 
 type MultiPoint struct {
-	BBox        []float64   `json:"bbox,omitempty"`
-	Coordinates [][]float64 `json:"coordinates"`
+	BBox           []float64              `json:"bbox,omitempty"`
+	Coordinates    [][]float64            `json:"coordinates"`
+	CRS            *CRS                   `json:"-"`
+	ForeignMembers map[string]interface{} `json:"-"`
 }
 
 func (m *MultiPoint) isObject() {}
@@ -191,8 +239,10 @@ func (m *MultiPoint) GoString() string {
 }
 
 type Point struct {
-	BBox        []float64 `json:"bbox,omitempty"`
-	Coordinates []float64 `json:"coordinates"`
+	BBox           []float64              `json:"bbox,omitempty"`
+	Coordinates    []float64              `json:"coordinates"`
+	CRS            *CRS                   `json:"-"`
+	ForeignMembers map[string]interface{} `json:"-"`
 }
 
 func (p *Point) isObject() {}
@@ -8,6 +8,13 @@ import (
 var _ json.Unmarshaler = (*Wrapper)(nil)
 var _ json.Unmarshaler = (*Feature)(nil)
 var _ json.Unmarshaler = (*GeometryCollection)(nil)
+var _ json.Unmarshaler = (*FeatureCollection)(nil)
+var _ json.Unmarshaler = (*MultiPolygon)(nil)
+var _ json.Unmarshaler = (*Polygon)(nil)
+var _ json.Unmarshaler = (*MultiLineString)(nil)
+var _ json.Unmarshaler = (*LineString)(nil)
+var _ json.Unmarshaler = (*MultiPoint)(nil)
+var _ json.Unmarshaler = (*Point)(nil)
 
 func (obj *Wrapper) UnmarshalJSON(b []byte) error {
 	type object Wrapper
@@ -31,39 +38,106 @@ func (obj *Wrapper) UnmarshalJSON(b []byte) error {
 }
 
 func (f *Feature) UnmarshalJSON(b []byte) error {
+	return f.unmarshal(b, false)
+}
+
+// unmarshal decodes a feature, recognizing JSON-FG members if jsonfg is set
+// or the feature declares conformance itself.
+func (f *Feature) unmarshal(b []byte, jsonfg bool) error {
 	type WireType struct {
 		BBox       []float64              `json:"bbox"`
 		Geometry   json.RawMessage        `json:"geometry"`
 		Properties map[string]interface{} `json:"properties"`
 		ID         string                 `json:"id"`
+		ConformsTo json.RawMessage        `json:"conformsTo"`
 	}
 	var w WireType
 	err := json.Unmarshal(b, &w)
 	if err != nil {
 		return err
 	}
-	var obj Wrapper
-	err = json.Unmarshal(w.Geometry, &obj)
+	geometry, err := unmarshalGeometry(w.Geometry)
+	if err != nil {
+		return err
+	}
+	err = checkGeoJSON(geometry)
 	if err != nil {
 		return err
 	}
 
-	geometry, ok := obj.Value.(Geometry)
-	if !ok {
-		return fmt.Errorf("invalid non-geometry type: %T", geometry)
+	*f = Feature{}
+	members := featureMembers
+	if jsonfg || isJSONFG(conformsTo(w.ConformsTo)) {
+		type JSONFGType struct {
+			ConformsTo  []string        `json:"conformsTo"`
+			FeatureType json.RawMessage `json:"featureType"`
+			CoordRefSys *CoordRefSys    `json:"coordRefSys"`
+			Time        *Time           `json:"time"`
+			Place       json.RawMessage `json:"place"`
+		}
+		var fg JSONFGType
+		err := json.Unmarshal(b, &fg)
+		if err != nil {
+			return err
+		}
+		f.FeatureType, err = unmarshalFeatureType(fg.FeatureType)
+		if err != nil {
+			return err
+		}
+		f.Place, err = unmarshalGeometry(fg.Place)
+		if err != nil {
+			return err
+		}
+		f.ConformsTo = fg.ConformsTo
+		f.CoordRefSys = fg.CoordRefSys
+		f.Time = fg.Time
+		members = append(append([]string{}, featureMembers...), jsonfgFeatureMembers...)
+	}
+	foreign, err := foreignMembers(b, members)
+	if err != nil {
+		return err
 	}
 
 	f.BBox = w.BBox
 	f.Geometry = geometry
 	f.Properties = w.Properties
 	f.ID = w.ID
+	f.ForeignMembers = foreign
 	return nil
 }
 
 func (g *GeometryCollection) UnmarshalJSON(b []byte) error {
+	geometries, bbox, foreign, err := unmarshalGeometries(b)
+	if err != nil {
+		return err
+	}
+	*g = GeometryCollection{BBox: bbox, Geometries: geometries, ForeignMembers: foreign}
+	return nil
+}
+
+// unmarshalGeometry decodes a geometry member, which is nil if the member
+// is null or absent.
+func unmarshalGeometry(b json.RawMessage) (Geometry, error) {
+	if len(b) == 0 || string(b) == "null" {
+		return nil, nil
+	}
+	var obj Wrapper
+	err := json.Unmarshal(b, &obj)
+	if err != nil {
+		return nil, err
+	}
+	geometry, ok := obj.Value.(Geometry)
+	if !ok {
+		return nil, fmt.Errorf("invalid non-geometry type: %T", obj.Value)
+	}
+	return geometry, nil
+}
+
+func (f *FeatureCollection) UnmarshalJSON(b []byte) error {
 	type WireType struct {
 		BBox       []float64         `json:"bbox"`
-		Geometries []json.RawMessage `json:"geometries"`
+		Features   []json.RawMessage `json:"features"`
+		ConformsTo json.RawMessage   `json:"conformsTo"`
 	}
 	var w WireType
 	err := json.Unmarshal(b, &w)
@@ -71,21 +145,136 @@ func (g *GeometryCollection) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
-	geometries := make([]Geometry, len(w.Geometries))
-	for i, wireGeometry := range w.Geometries {
-		var obj Wrapper
-		err := json.Unmarshal(wireGeometry, &obj)
+	*f = FeatureCollection{}
+	jsonfg := isJSONFG(conformsTo(w.ConformsTo))
+	members := featureCollectionMembers
+	if jsonfg {
+		type JSONFGType struct {
+			ConformsTo  []string        `json:"conformsTo"`
+			FeatureType json.RawMessage `json:"featureType"`
+			CoordRefSys *CoordRefSys    `json:"coordRefSys"`
+		}
+		var fg JSONFGType
+		err := json.Unmarshal(b, &fg)
 		if err != nil {
 			return err
 		}
-		geometry, ok := obj.Value.(Geometry)
-		if !ok {
-			return fmt.Errorf("invalid non-geometry type: %T", obj.Value)
+		f.FeatureType, err = unmarshalFeatureType(fg.FeatureType)
+		if err != nil {
+			return err
+		}
+		f.ConformsTo = fg.ConformsTo
+		f.CoordRefSys = fg.CoordRefSys
+		members = append(append([]string{}, featureCollectionMembers...), jsonfgCollectionMembers...)
+	}
+	if w.Features != nil {
+		f.Features = make([]Feature, len(w.Features))
+	}
+	for i, raw := range w.Features {
+		err := f.Features[i].unmarshal(raw, jsonfg)
+		if err != nil {
+			return err
 		}
-		geometries[i] = geometry
 	}
+	foreign, err := foreignMembers(b, members)
+	if err != nil {
+		return err
+	}
+
+	f.BBox = w.BBox
+	f.ForeignMembers = foreign
+	return nil
+}
 
-	g.BBox = w.BBox
-	g.Geometries = geometries
+// The remaining types decode with the default behavior, but must also
+// collect their foreign members.
+
+func (m *MultiPolygon) UnmarshalJSON(b []byte) error {
+	type WireType MultiPolygon
+	var w WireType
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	w.ForeignMembers, err = foreignMembers(b, coordinatesMembers)
+	if err != nil {
+		return err
+	}
+	*m = MultiPolygon(w)
+	return nil
+}
+
+func (p *Polygon) UnmarshalJSON(b []byte) error {
+	type WireType Polygon
+	var w WireType
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	w.ForeignMembers, err = foreignMembers(b, coordinatesMembers)
+	if err != nil {
+		return err
+	}
+	*p = Polygon(w)
+	return nil
+}
+
+func (m *MultiLineString) UnmarshalJSON(b []byte) error {
+	type WireType MultiLineString
+	var w WireType
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	w.ForeignMembers, err = foreignMembers(b, coordinatesMembers)
+	if err != nil {
+		return err
+	}
+	*m = MultiLineString(w)
+	return nil
+}
+
+func (ls *LineString) UnmarshalJSON(b []byte) error {
+	type WireType LineString
+	var w WireType
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	w.ForeignMembers, err = foreignMembers(b, coordinatesMembers)
+	if err != nil {
+		return err
+	}
+	*ls = LineString(w)
+	return nil
+}
+
+func (m *MultiPoint) UnmarshalJSON(b []byte) error {
+	type WireType MultiPoint
+	var w WireType
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	w.ForeignMembers, err = foreignMembers(b, coordinatesMembers)
+	if err != nil {
+		return err
+	}
+	*m = MultiPoint(w)
+	return nil
+}
+
+func (p *Point) UnmarshalJSON(b []byte) error {
+	type WireType Point
+	var w WireType
+	err := json.Unmarshal(b, &w)
+	if err != nil {
+		return err
+	}
+	w.ForeignMembers, err = foreignMembers(b, coordinatesMembers)
+	if err != nil {
+		return err
+	}
+	*p = Point(w)
 	return nil
 }
@@ -290,6 +290,23 @@ func TestObject_UnmarshalJSON(t *testing.T) {
 				},
 			},
 		},
+		{
+			s: `{"type":"FeatureCollection","name":"places","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[0,1],"extra":null},"properties":null,"title":{"lang":"en"}}]}`,
+			expected: &FeatureCollection{
+				Features: []Feature{
+					{
+						Geometry: &Point{
+							Coordinates:    []float64{0, 1},
+							ForeignMembers: map[string]interface{}{"extra": nil},
+						},
+						ForeignMembers: map[string]interface{}{
+							"title": map[string]interface{}{"lang": "en"},
+						},
+					},
+				},
+				ForeignMembers: map[string]interface{}{"name": "places"},
+			},
+		},
 	}
 
 	for i, c := range cases {
@@ -0,0 +1,40 @@
+package geojson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRoundTrip_ToWire(t *testing.T) {
+	ring := LineString{Points: []Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0}}}
+	cases := []Object{
+		&Point{X: 1, Y: 2},
+		&Point{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+		&MultiPoint{Points: []Point{{X: 1, Y: 2}, {X: 3, Y: 4}}},
+		&LineString{Points: []Point{{X: 1, Y: 2}, {X: 3, Y: 4}}},
+		&MultiLineString{Lines: []LineString{{Points: []Point{{X: 1, Y: 2}, {X: 3, Y: 4}}}}},
+		&Polygon{Rings: []LineString{ring}},
+		&MultiPolygon{Polygons: []Polygon{{Rings: []LineString{ring}}}},
+		&GeometryCollection{Geometries: []Geometry{&Point{X: 1, Y: 2}, &Polygon{Rings: []LineString{ring}}}},
+		&Feature{
+			Geometry:   &Point{X: 1, Y: 2},
+			Properties: map[string]interface{}{"name": "a"},
+			ID:         "1",
+		},
+		&FeatureCollection{
+			Features: []Feature{
+				{Geometry: &LineString{Points: []Point{{X: 1, Y: 2}, {X: 3, Y: 4}}}},
+			},
+		},
+	}
+	for i, c := range cases {
+		got, err := FromWire(ToWire(c))
+		if err != nil {
+			t.Errorf("case %d: failed to convert %T: %v", i, c, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c) {
+			t.Errorf("case %d: expected %#v, got %#v", i, c, got)
+		}
+	}
+}
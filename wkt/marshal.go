@@ -0,0 +1,182 @@
+package wkt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bsidhom/geojson"
+)
+
+// Marshal formats g as WKT. A geometry is written with a Z qualifier only if
+// every one of its points has an elevation; otherwise elevations are dropped.
+func Marshal(g geojson.Geometry) (string, error) {
+	var b strings.Builder
+	err := writeGeometry(&b, g)
+	if err != nil {
+		return "", fmt.Errorf("wkt: %v", err)
+	}
+	return b.String(), nil
+}
+
+func writeGeometry(b *strings.Builder, g geojson.Geometry) error {
+	switch t := g.(type) {
+	case *geojson.Point:
+		z := t.HasElevation
+		writeTag(b, "POINT", z)
+		b.WriteByte('(')
+		writePosition(b, t, z)
+		b.WriteByte(')')
+	case *geojson.MultiPoint:
+		z := allElevated(t.Points)
+		writeTag(b, "MULTIPOINT", z)
+		if len(t.Points) == 0 {
+			b.WriteString("EMPTY")
+			return nil
+		}
+		b.WriteByte('(')
+		for i := range t.Points {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteByte('(')
+			writePosition(b, &t.Points[i], z)
+			b.WriteByte(')')
+		}
+		b.WriteByte(')')
+	case *geojson.LineString:
+		z := allElevated(t.Points)
+		writeTag(b, "LINESTRING", z)
+		writePoints(b, t.Points, z)
+	case *geojson.MultiLineString:
+		z := true
+		for _, ls := range t.Lines {
+			z = z && allElevated(ls.Points)
+		}
+		writeTag(b, "MULTILINESTRING", z && len(t.Lines) > 0)
+		if len(t.Lines) == 0 {
+			b.WriteString("EMPTY")
+			return nil
+		}
+		b.WriteByte('(')
+		for i, ls := range t.Lines {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			writePoints(b, ls.Points, z)
+		}
+		b.WriteByte(')')
+	case *geojson.Polygon:
+		z := polygonElevated(t)
+		writeTag(b, "POLYGON", z)
+		writeRings(b, t, z)
+	case *geojson.MultiPolygon:
+		z := true
+		for i := range t.Polygons {
+			z = z && polygonElevated(&t.Polygons[i])
+		}
+		writeTag(b, "MULTIPOLYGON", z && len(t.Polygons) > 0)
+		if len(t.Polygons) == 0 {
+			b.WriteString("EMPTY")
+			return nil
+		}
+		b.WriteByte('(')
+		for i := range t.Polygons {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			writeRings(b, &t.Polygons[i], z)
+		}
+		b.WriteByte(')')
+	case *geojson.GeometryCollection:
+		writeTag(b, "GEOMETRYCOLLECTION", false)
+		if len(t.Geometries) == 0 {
+			b.WriteString("EMPTY")
+			return nil
+		}
+		b.WriteByte('(')
+		for i, child := range t.Geometries {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			err := writeGeometry(b, child)
+			if err != nil {
+				return fmt.Errorf("GeometryCollection: geometry %d: %v", i, err)
+			}
+		}
+		b.WriteByte(')')
+	default:
+		return fmt.Errorf("unsupported geometry type: %T", t)
+	}
+	return nil
+}
+
+func writeTag(b *strings.Builder, tag string, z bool) {
+	b.WriteString(tag)
+	if z {
+		b.WriteString(" Z")
+	}
+	b.WriteByte(' ')
+}
+
+func writeRings(b *strings.Builder, p *geojson.Polygon, z bool) {
+	if len(p.Rings) == 0 {
+		b.WriteString("EMPTY")
+		return
+	}
+	b.WriteByte('(')
+	for i, ring := range p.Rings {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		writePoints(b, ring.Points, z)
+	}
+	b.WriteByte(')')
+}
+
+func writePoints(b *strings.Builder, points []geojson.Point, z bool) {
+	if len(points) == 0 {
+		b.WriteString("EMPTY")
+		return
+	}
+	b.WriteByte('(')
+	for i := range points {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		writePosition(b, &points[i], z)
+	}
+	b.WriteByte(')')
+}
+
+func writePosition(b *strings.Builder, p *geojson.Point, z bool) {
+	b.WriteString(formatFloat(p.X))
+	b.WriteByte(' ')
+	b.WriteString(formatFloat(p.Y))
+	if z {
+		b.WriteByte(' ')
+		b.WriteString(formatFloat(p.Elevation))
+	}
+}
+
+func allElevated(points []geojson.Point) bool {
+	for _, p := range points {
+		if !p.HasElevation {
+			return false
+		}
+	}
+	return len(points) > 0
+}
+
+func polygonElevated(p *geojson.Polygon) bool {
+	for _, ring := range p.Rings {
+		if !allElevated(ring.Points) {
+			return false
+		}
+	}
+	return len(p.Rings) > 0
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
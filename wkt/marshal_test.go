@@ -0,0 +1,102 @@
+package wkt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestMarshal(t *testing.T) {
+	cases := []struct {
+		g        geojson.Geometry
+		expected string
+	}{
+		{
+			g:        &geojson.Point{X: 1, Y: 2.5},
+			expected: "POINT (1 2.5)",
+		},
+		{
+			g:        &geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+			expected: "POINT Z (1 2 3)",
+		},
+		{
+			g: &geojson.LineString{
+				Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1, Elevation: 1, HasElevation: true}},
+			},
+			expected: "LINESTRING (0 0, 1 1)",
+		},
+		{
+			g:        &geojson.MultiPolygon{},
+			expected: "MULTIPOLYGON EMPTY",
+		},
+		{
+			g:        &geojson.LineString{},
+			expected: "LINESTRING EMPTY",
+		},
+		{
+			g:        &geojson.Polygon{},
+			expected: "POLYGON EMPTY",
+		},
+		{
+			g: &geojson.MultiLineString{
+				Lines: []geojson.LineString{{}, {Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}}},
+			},
+			expected: "MULTILINESTRING (EMPTY, (0 0, 1 1))",
+		},
+	}
+	for i, c := range cases {
+		s, err := Marshal(c.g)
+		if err != nil {
+			t.Errorf("error marshaling case %d: %v", i, err)
+			continue
+		}
+		if s != c.expected {
+			t.Errorf("case %d failed: expected %q, got %q", i, c.expected, s)
+		}
+	}
+}
+
+func TestRoundTrip_Marshal(t *testing.T) {
+	ring := geojson.LineString{
+		Points: []geojson.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 0}},
+	}
+	cases := []geojson.Geometry{
+		&geojson.MultiPoint{Points: []geojson.Point{{X: 1, Y: 2}, {X: 3, Y: 4}}},
+		&geojson.MultiLineString{
+			Lines: []geojson.LineString{
+				{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+			},
+		},
+		&geojson.Polygon{Rings: []geojson.LineString{ring, ring}},
+		&geojson.MultiPolygon{
+			Polygons: []geojson.Polygon{{Rings: []geojson.LineString{ring}}},
+		},
+		&geojson.GeometryCollection{
+			Geometries: []geojson.Geometry{
+				&geojson.Point{X: 0.1, Y: 0.2, Elevation: -3, HasElevation: true},
+				&geojson.GeometryCollection{},
+			},
+		},
+		&geojson.LineString{},
+		&geojson.Polygon{},
+		&geojson.GeometryCollection{
+			Geometries: []geojson.Geometry{&geojson.LineString{}, &geojson.Polygon{}},
+		},
+	}
+	for i, c := range cases {
+		s, err := Marshal(c)
+		if err != nil {
+			t.Errorf("failed to marshal case %d (%T): %v", i, c, err)
+			continue
+		}
+		g, err := Unmarshal(s)
+		if err != nil {
+			t.Errorf("failed to unmarshal case %d (%T): %v", i, c, err)
+			continue
+		}
+		if !reflect.DeepEqual(g, c) {
+			t.Errorf("round trip %d (%T) failed: expected %#v, got %#v", i, c, c, g)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+// Package wkt converts between the Well-Known Text representation of
+// geometries and GeoJSON geometries.
+//
+// Z coordinates are mapped to Point elevations. M coordinates have no GeoJSON
+// equivalent and are discarded when parsing.
+package wkt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bsidhom/geojson"
+)
+
+// Unmarshal parses a WKT geometry. Keywords are case-insensitive.
+//
+// Empty Points cannot be represented by the geojson types and are rejected.
+// Other empty geometries, including empty LineStrings and Polygons, are
+// returned as zero values, as Marshal writes them.
+func Unmarshal(s string) (geojson.Geometry, error) {
+	p := &parser{tokens: tokenize(s)}
+	g, err := p.geometry()
+	if err != nil {
+		return nil, fmt.Errorf("wkt: %v", err)
+	}
+	if tok := p.peek(); tok != "" {
+		return nil, fmt.Errorf("wkt: unexpected trailing token %q", tok)
+	}
+	return g, nil
+}
+
+// tokenize splits s into words, numbers, and punctuation.
+func tokenize(s string) []string {
+	var tokens []string
+	start := -1
+	for i, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == ',':
+			if start >= 0 {
+				tokens = append(tokens, s[start:i])
+				start = -1
+			}
+			tokens = append(tokens, string(r))
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if start >= 0 {
+				tokens = append(tokens, s[start:i])
+				start = -1
+			}
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+	// Number of ordinates per position, including any M ordinate.
+	dims int
+	// Whether the position includes an M ordinate.
+	hasM bool
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() string {
+	tok := p.peek()
+	if tok != "" {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(tok string) error {
+	got := p.next()
+	if got != tok {
+		if got == "" {
+			return fmt.Errorf("expected %q, got end of input", tok)
+		}
+		return fmt.Errorf("expected %q, got %q", tok, got)
+	}
+	return nil
+}
+
+// dimension consumes an optional Z, M, or ZM qualifier.
+func (p *parser) dimension() {
+	if p.setDimension(strings.ToUpper(p.peek())) {
+		p.pos++
+		return
+	}
+	// Dimension is inferred from the first position.
+	p.dims, p.hasM = 0, false
+}
+
+// setDimension applies a Z, M, or ZM qualifier and reports whether q was a
+// valid qualifier.
+func (p *parser) setDimension(q string) bool {
+	switch q {
+	case "Z":
+		p.dims, p.hasM = 3, false
+	case "M":
+		p.dims, p.hasM = 3, true
+	case "ZM":
+		p.dims, p.hasM = 4, true
+	default:
+		return false
+	}
+	return true
+}
+
+// empty consumes an EMPTY keyword if present.
+func (p *parser) empty() bool {
+	if strings.ToUpper(p.peek()) == "EMPTY" {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) geometry() (geojson.Geometry, error) {
+	tag := strings.ToUpper(p.next())
+	if tag == "" {
+		return nil, fmt.Errorf("unexpected end of input")
+	}
+	qualifier := ""
+	if !isTag(tag) {
+		// Some producers glue the qualifier onto the tag, as in POINTZ.
+		for _, q := range []string{"ZM", "Z", "M"} {
+			base := strings.TrimSuffix(tag, q)
+			if base != tag && isTag(base) {
+				tag, qualifier = base, q
+				break
+			}
+		}
+	}
+	if !isTag(tag) {
+		return nil, fmt.Errorf("unknown geometry type %q", tag)
+	}
+	if qualifier != "" {
+		p.setDimension(qualifier)
+	} else {
+		p.dimension()
+	}
+	empty := p.empty()
+	switch tag {
+	case "POINT":
+		if empty {
+			return nil, fmt.Errorf("empty Point is not supported")
+		}
+		err := p.expect("(")
+		if err != nil {
+			return nil, err
+		}
+		point, err := p.position()
+		if err != nil {
+			return nil, err
+		}
+		return &point, p.expect(")")
+	case "LINESTRING":
+		if empty {
+			return &geojson.LineString{}, nil
+		}
+		ls, err := p.lineString()
+		if err != nil {
+			return nil, err
+		}
+		return ls, nil
+	case "POLYGON":
+		if empty {
+			return &geojson.Polygon{}, nil
+		}
+		return p.polygon()
+	case "MULTIPOINT":
+		m := &geojson.MultiPoint{}
+		if empty {
+			return m, nil
+		}
+		err := p.list(func() error {
+			// Both MULTIPOINT (1 2, 3 4) and MULTIPOINT ((1 2), (3 4)) are
+			// common.
+			wrapped := p.peek() == "("
+			if wrapped {
+				p.pos++
+			}
+			point, err := p.position()
+			if err != nil {
+				return err
+			}
+			m.Points = append(m.Points, point)
+			if wrapped {
+				return p.expect(")")
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	case "MULTILINESTRING":
+		m := &geojson.MultiLineString{}
+		if empty {
+			return m, nil
+		}
+		err := p.list(func() error {
+			ls, err := p.lineString()
+			if err != nil {
+				return err
+			}
+			m.Lines = append(m.Lines, *ls)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	case "MULTIPOLYGON":
+		m := &geojson.MultiPolygon{}
+		if empty {
+			return m, nil
+		}
+		err := p.list(func() error {
+			polygon, err := p.polygon()
+			if err != nil {
+				return err
+			}
+			m.Polygons = append(m.Polygons, *polygon)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	case "GEOMETRYCOLLECTION":
+		gc := &geojson.GeometryCollection{}
+		if empty {
+			return gc, nil
+		}
+		err := p.list(func() error {
+			g, err := p.geometry()
+			if err != nil {
+				return err
+			}
+			gc.Geometries = append(gc.Geometries, g)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return gc, nil
+	}
+	return nil, fmt.Errorf("unknown geometry type %q", tag)
+}
+
+func isTag(tag string) bool {
+	switch tag {
+	case "POINT", "LINESTRING", "POLYGON", "MULTIPOINT", "MULTILINESTRING", "MULTIPOLYGON", "GEOMETRYCOLLECTION":
+		return true
+	}
+	return false
+}
+
+// list parses a parenthesized, comma-separated list, calling item for each
+// element.
+func (p *parser) list(item func() error) error {
+	err := p.expect("(")
+	if err != nil {
+		return err
+	}
+	for {
+		err := item()
+		if err != nil {
+			return err
+		}
+		if p.peek() != "," {
+			break
+		}
+		p.pos++
+	}
+	return p.expect(")")
+}
+
+func (p *parser) points() ([]geojson.Point, error) {
+	var points []geojson.Point
+	err := p.list(func() error {
+		point, err := p.position()
+		if err != nil {
+			return err
+		}
+		points = append(points, point)
+		return nil
+	})
+	return points, err
+}
+
+func (p *parser) lineString() (*geojson.LineString, error) {
+	if p.empty() {
+		// A member of a MultiLineString.
+		return &geojson.LineString{}, nil
+	}
+	points, err := p.points()
+	if err != nil {
+		return nil, err
+	}
+	if len(points) < 2 {
+		return nil, fmt.Errorf("LineString must have at least 2 points, got %d", len(points))
+	}
+	return &geojson.LineString{Points: points}, nil
+}
+
+func (p *parser) polygon() (*geojson.Polygon, error) {
+	polygon := &geojson.Polygon{}
+	if p.empty() {
+		// A member of a MultiPolygon.
+		return polygon, nil
+	}
+	err := p.list(func() error {
+		points, err := p.points()
+		if err != nil {
+			return err
+		}
+		i := len(polygon.Rings)
+		if len(points) < 4 {
+			return fmt.Errorf("Polygon ring %d requires at least 4 points, got %d", i, len(points))
+		}
+		if points[0] != points[len(points)-1] {
+			return fmt.Errorf("Polygon ring %d is not closed", i)
+		}
+		polygon.Rings = append(polygon.Rings, geojson.LineString{Points: points})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return polygon, nil
+}
+
+// position parses a single whitespace-separated coordinate tuple.
+func (p *parser) position() (geojson.Point, error) {
+	var values []float64
+	for {
+		tok := p.peek()
+		if tok == "" || tok == "," || tok == ")" || tok == "(" {
+			break
+		}
+		v, err := strconv.ParseFloat(tok, 64)
+		if err != nil {
+			return geojson.Point{}, fmt.Errorf("invalid coordinate %q", tok)
+		}
+		values = append(values, v)
+		p.pos++
+	}
+	if p.dims == 0 {
+		// Untagged positions are XY, XYZ, or XYZM.
+		p.dims = len(values)
+		p.hasM = p.dims == 4
+	}
+	if len(values) != p.dims || p.dims < 2 || p.dims > 4 {
+		return geojson.Point{}, fmt.Errorf("expected %d ordinates, got %d", p.dims, len(values))
+	}
+	point := geojson.Point{X: values[0], Y: values[1]}
+	if p.dims == 4 || (p.dims == 3 && !p.hasM) {
+		point.Elevation = values[2]
+		point.HasElevation = true
+	}
+	return point, nil
+}
@@ -0,0 +1,167 @@
+package wkt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bsidhom/geojson"
+)
+
+func TestUnmarshal(t *testing.T) {
+	square := geojson.LineString{
+		Points: []geojson.Point{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}, {X: 0, Y: 0}},
+	}
+	cases := []struct {
+		s        string
+		expected geojson.Geometry
+	}{
+		{
+			s:        "POINT (1 2)",
+			expected: &geojson.Point{X: 1, Y: 2},
+		},
+		{
+			s:        "point(1.5 -2e3)",
+			expected: &geojson.Point{X: 1.5, Y: -2000},
+		},
+		{
+			s:        "POINT Z (1 2 3)",
+			expected: &geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+		},
+		{
+			s:        "POINTZ(1 2 3)",
+			expected: &geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+		},
+		{
+			s:        "POINT (1 2 3)",
+			expected: &geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+		},
+		{
+			s:        "POINT M (1 2 3)",
+			expected: &geojson.Point{X: 1, Y: 2},
+		},
+		{
+			s:        "POINT ZM (1 2 3 4)",
+			expected: &geojson.Point{X: 1, Y: 2, Elevation: 3, HasElevation: true},
+		},
+		{
+			s: "LINESTRING (0 0, 1 1, 2 0)",
+			expected: &geojson.LineString{
+				Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 0}},
+			},
+		},
+		{
+			s: "POLYGON ((0 0, 4 0, 4 4, 0 4, 0 0), (1 1, 1 2, 2 2, 1 1))",
+			expected: &geojson.Polygon{
+				Rings: []geojson.LineString{
+					square,
+					{Points: []geojson.Point{{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 1, Y: 1}}},
+				},
+			},
+		},
+		{
+			s: "MULTIPOINT (1 2, 3 4)",
+			expected: &geojson.MultiPoint{
+				Points: []geojson.Point{{X: 1, Y: 2}, {X: 3, Y: 4}},
+			},
+		},
+		{
+			s: "MULTIPOINT ((1 2), (3 4))",
+			expected: &geojson.MultiPoint{
+				Points: []geojson.Point{{X: 1, Y: 2}, {X: 3, Y: 4}},
+			},
+		},
+		{
+			s:        "MULTIPOINT EMPTY",
+			expected: &geojson.MultiPoint{},
+		},
+		{
+			s: "MULTILINESTRING ((0 0, 1 1), (2 2, 3 3))",
+			expected: &geojson.MultiLineString{
+				Lines: []geojson.LineString{
+					{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+					{Points: []geojson.Point{{X: 2, Y: 2}, {X: 3, Y: 3}}},
+				},
+			},
+		},
+		{
+			s: "MULTIPOLYGON (((0 0, 4 0, 4 4, 0 4, 0 0)))",
+			expected: &geojson.MultiPolygon{
+				Polygons: []geojson.Polygon{{Rings: []geojson.LineString{square}}},
+			},
+		},
+		{
+			s: "GEOMETRYCOLLECTION (POINT (1 2), LINESTRING Z (0 0 1, 1 1 2))",
+			expected: &geojson.GeometryCollection{
+				Geometries: []geojson.Geometry{
+					&geojson.Point{X: 1, Y: 2},
+					&geojson.LineString{
+						Points: []geojson.Point{
+							{X: 0, Y: 0, Elevation: 1, HasElevation: true},
+							{X: 1, Y: 1, Elevation: 2, HasElevation: true},
+						},
+					},
+				},
+			},
+		},
+		{
+			s:        "GEOMETRYCOLLECTION EMPTY",
+			expected: &geojson.GeometryCollection{},
+		},
+		{
+			s:        "LINESTRING EMPTY",
+			expected: &geojson.LineString{},
+		},
+		{
+			s:        "POLYGON Z EMPTY",
+			expected: &geojson.Polygon{},
+		},
+		{
+			s: "MULTILINESTRING (EMPTY, (0 0, 1 1))",
+			expected: &geojson.MultiLineString{
+				Lines: []geojson.LineString{
+					{},
+					{Points: []geojson.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+				},
+			},
+		},
+		{
+			s: "MULTIPOLYGON (EMPTY)",
+			expected: &geojson.MultiPolygon{
+				Polygons: []geojson.Polygon{{}},
+			},
+		},
+	}
+	for i, c := range cases {
+		g, err := Unmarshal(c.s)
+		if err != nil {
+			t.Errorf("error unmarshaling case %d: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(g, c.expected) {
+			t.Errorf("case %d failed: expected %#v, got %#v", i, c.expected, g)
+		}
+	}
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	cases := []string{
+		"",
+		"CIRCLE (1 2)",
+		"POINT EMPTY",
+		"POINT (1)",
+		"POINT (1 2",
+		"POINT (1 2) extra",
+		"POINT Z (1 2)",
+		"LINESTRING (1 2)",
+		"LINESTRING (1 2, 3 4 5)",
+		"POLYGON ((0 0, 1 1, 0 0))",
+		"POLYGON ((0 0, 1 0, 1 1, 0 1))",
+		"MULTIPOINT (1 a)",
+	}
+	for i, c := range cases {
+		_, err := Unmarshal(c)
+		if err == nil {
+			t.Errorf("case %d (%q): expected error", i, c)
+		}
+	}
+}